@@ -10,6 +10,7 @@ import (
 
 	"github.com/flynn-nrg/izpi/internal/config"
 	"github.com/flynn-nrg/izpi/internal/leader"
+	"github.com/flynn-nrg/izpi/internal/postprocess"
 	"github.com/flynn-nrg/izpi/internal/worker"
 
 	"github.com/alecthomas/kong"
@@ -18,34 +19,72 @@ import (
 )
 
 const (
-	programName             = "izpi"
-	defaultXSize            = "500"
-	defaultYSize            = "500"
-	defaultSamples          = "1000"
-	defaultMaxDepth         = "50"
-	defaultOutputFile       = "output.exr"
-	defaultSceneFile        = "examples/cornell_box_transparent_pyramid_spectral.pbtxt"
-	defaultDiscoveryTimeout = "3"
+	programName               = "izpi"
+	defaultXSize              = "500"
+	defaultYSize              = "500"
+	defaultSamples            = "1000"
+	defaultMaxDepth           = "50"
+	defaultRouletteDepth      = "3"
+	defaultOutputFile         = "output.exr"
+	defaultSceneFile          = "examples/cornell_box_transparent_pyramid_spectral.pbtxt"
+	defaultDiscoveryTimeout   = "3"
+	defaultMinSamples         = "16"
+	defaultNoiseThreshold     = "0.05"
+	defaultPassSamples        = "16"
+	defaultCheckpointInterval = "600"
+	defaultBootstrapSamples   = "100000"
+	defaultLargeStepProb      = "0.3"
+	defaultPhotons            = "500000"
+	defaultPhotonAlpha        = "0.7"
 )
 
 var flags struct {
-	LogLevel         string `name:"log-level" help:"The log level: error, warn, info, debug, trace." default:"info"`
-	Scene            string `type:"existingfile" name:"scene" help:"Scene file to render" default:"${defaultSceneFile}"`
-	NumWorkers       int64  `name:"num-workers" help:"Number of worker threads" default:"${defaultNumWorkers}"`
-	XSize            int64  `name:"x" help:"Output image x size" default:"${defaultXSize}"`
-	YSize            int64  `name:"y" help:"Output image y size" default:"${defaultYSize}"`
-	Samples          int64  `name:"samples" help:"Number of samples per ray" default:"${defaultSamples}"`
-	Sampler          string `name:"sampler-type" help:"Sampler function to use: spectral, colour, albedo, normal, wireframe" default:"colour"`
-	Depth            int64  `name:"max-depth" help:"Maximum depth" default:"${defaultMaxDepth}"`
-	OutputMode       string `name:"output-mode" help:"Output mode: png, exr, hdr or pfm" default:"exr"`
-	OutputFile       string `type:"file" name:"output-file" help:"Output file." default:"${defaultOutputFile}"`
-	Verbose          bool   `name:"v" help:"Print rendering progress bar" default:"true"`
-	Preview          bool   `name:"p" help:"Display rendering progress in a window" default:"true"`
-	DisplayMode      string `name:"display-mode" help:"Display mode: fyne or sdl" default:"fyne"`
-	CpuProfile       string `name:"cpu-profile" help:"Enable cpu profiling"`
-	Instrument       bool   `name:"instrument" help:"Enable instrumentation" default:"false"`
-	Role             string `name:"role" help:"Role: worker, leader or standalone" default:"standalone"`
-	DiscoveryTimeout int64  `name:"discovery-timeout" help:"Discovery timeout in seconds" default:"${defaultDiscoveryTimeout}"`
+	LogLevel             string   `name:"log-level" help:"The log level: error, warn, info, debug, trace." default:"info"`
+	Scene                string   `type:"existingfile" name:"scene" help:"Scene file to render" default:"${defaultSceneFile}"`
+	NumWorkers           int64    `name:"num-workers" help:"Number of worker threads" default:"${defaultNumWorkers}"`
+	XSize                int64    `name:"x" help:"Output image x size" default:"${defaultXSize}"`
+	YSize                int64    `name:"y" help:"Output image y size" default:"${defaultYSize}"`
+	Samples              int64    `name:"samples" help:"Number of samples per ray" default:"${defaultSamples}"`
+	SampleGenerator      string   `name:"sample-generator" help:"Generator of the numbers used to sample pixels: independent, stratified, sobol, halton or blue-noise. sobol and blue-noise work best with a power of two of samples per pixel, and blue-noise spreads the remaining noise as blue noise across neighbouring pixels" default:"independent"`
+	Seed                 uint64   `name:"seed" help:"Seed of the numbers used to sample pixels. Renders with the same seed and settings are identical, regardless of the number of workers and whether they are local or remote. Also seeds the Markov chains of the mlt samplers and the photons of the ppm samplers" default:"0"`
+	Filter               string   `name:"filter" help:"Filter used to reconstruct pixels from their samples: box, tent, gaussian, mitchell or blackman-harris. Filters wider than a pixel blend the samples of neighbouring pixels, and mitchell sharpens the image at the cost of some ringing" default:"box"`
+	FilterRadius         float64  `name:"filter-radius" help:"Radius of the reconstruction filter in pixels, or 0 to use the default of the filter: 0.5 for box, 1 for tent, 1.5 for gaussian and 2 for mitchell and blackman-harris" default:"0"`
+	Sampler              string   `name:"sampler-type" help:"Sampler function to use: spectral, colour, bdpt, spectral-bdpt, mlt, spectral-mlt, ppm, spectral-ppm, albedo, normal, wireframe. The mlt samplers run --samples mutations per pixel. The ppm samplers gather caustics from a photon map that is rebuilt and sharpened with every pass, so they always render progressively" default:"colour"`
+	Depth                int64    `name:"max-depth" help:"Maximum depth" default:"${defaultMaxDepth}"`
+	MaxDiffuseDepth      int64    `name:"max-diffuse-depth" help:"Maximum number of diffuse bounces. Direct lighting is still rendered when it is 0" default:"${defaultMaxDepth}"`
+	MaxSpecularDepth     int64    `name:"max-specular-depth" help:"Maximum number of specular reflections" default:"${defaultMaxDepth}"`
+	MaxTransmissionDepth int64    `name:"max-transmission-depth" help:"Maximum number of refractions through transparent surfaces" default:"${defaultMaxDepth}"`
+	MaxVolumeDepth       int64    `name:"max-volume-depth" help:"Maximum number of scattering events inside participating media" default:"${defaultMaxDepth}"`
+	RussianRouletteDepth int64    `name:"russian-roulette-depth" help:"Number of bounces after which paths that carry little light are terminated at random" default:"${defaultRouletteDepth}"`
+	OutputMode           string   `name:"output-mode" help:"Output mode: png, exr, multilayer-exr, hdr or pfm. multilayer-exr writes the image, AOVs, light groups and sample counts to a single file" default:"exr"`
+	OutputFile           string   `type:"file" name:"output-file" help:"Output file." default:"${defaultOutputFile}"`
+	Verbose              bool     `name:"v" help:"Print rendering progress bar" default:"true"`
+	Preview              bool     `name:"p" help:"Display rendering progress in a window" default:"true"`
+	DisplayMode          string   `name:"display-mode" help:"Display mode: fyne or sdl" default:"fyne"`
+	CpuProfile           string   `name:"cpu-profile" help:"Enable cpu profiling"`
+	Instrument           bool     `name:"instrument" help:"Enable instrumentation" default:"false"`
+	Role                 string   `name:"role" help:"Role: worker, leader or standalone" default:"standalone"`
+	DiscoveryTimeout     int64    `name:"discovery-timeout" help:"Discovery timeout in seconds" default:"${defaultDiscoveryTimeout}"`
+	Adaptive             bool     `name:"adaptive" help:"Enable adaptive sampling. --samples becomes the maximum number of samples per pixel" default:"false"`
+	MinSamples           int64    `name:"min-samples" help:"Minimum number of samples per pixel when adaptive sampling is enabled" default:"${defaultMinSamples}"`
+	NoiseThreshold       float64  `name:"noise-threshold" help:"Relative noise level at which a pixel is considered converged when adaptive sampling is enabled" default:"${defaultNoiseThreshold}"`
+	SampleHeatmap        string   `type:"file" name:"sample-heatmap" help:"Write a PNG heatmap of the number of samples taken per pixel to this file"`
+	Progressive          bool     `name:"progressive" help:"Render the whole frame in passes. Interrupting the render writes the output accumulated so far" default:"false"`
+	PassSamples          int64    `name:"pass-samples" help:"Number of samples per pixel in every progressive pass" default:"${defaultPassSamples}"`
+	Checkpoint           string   `type:"path" name:"checkpoint" help:"Periodically save the render progress to this file so that it can be resumed"`
+	CheckpointInterval   int64    `name:"checkpoint-interval" help:"Checkpoint interval in seconds" default:"${defaultCheckpointInterval}"`
+	Resume               bool     `name:"resume" help:"Resume the render saved in the checkpoint file" default:"false"`
+	TimeLimit            int64    `name:"time-limit" help:"Stop rendering after this many seconds and write the best image available. Implies --progressive. Cannot be used with --adaptive"`
+	TargetNoise          float64  `name:"target-noise" help:"Stop rendering once the estimated relative noise level of the image falls below this value. Implies --progressive. Cannot be used with --adaptive"`
+	AOVs                 []string `name:"aovs" help:"AOVs to render along with the image: albedo, normal, depth, position, uv, material_id, object_id, crypto_object, crypto_material, emission, diffuse_direct, diffuse_indirect, specular_direct, specular_indirect or all. Unless --output-mode is multilayer-exr, every AOV is written to its own EXR file and Cryptomatte layers to a single one"`
+	Denoise              bool     `name:"denoise" help:"Denoise the image using its albedo, normal and depth AOVs, which are rendered and written along with it" default:"false"`
+	DenoiseIterations    int64    `name:"denoise-iterations" help:"Number of denoising filter iterations. Every iteration doubles the filter footprint" default:"${defaultDenoiseIterations}"`
+	LightGroups          []string `name:"light-groups" help:"Emissive materials whose contribution is rendered as a separate AOV, or all for every emissive material in the scene"`
+	MLTBootstrapSamples  int64    `name:"mlt-bootstrap-samples" help:"Number of paths traced by the mlt samplers to estimate the brightness of the image" default:"${defaultBootstrapSamples}"`
+	MLTLargeStepProb     float64  `name:"mlt-large-step-probability" help:"Probability of an mlt mutation replacing the whole path rather than perturbing it" default:"${defaultLargeStepProb}"`
+	Photons              int64    `name:"photons" help:"Number of photons emitted per pass by the ppm samplers" default:"${defaultPhotons}"`
+	PhotonRadius         float64  `name:"photon-radius" help:"Initial radius of photon lookups. Derived from the size of the scene when 0" default:"0"`
+	PhotonAlpha          float64  `name:"photon-alpha" help:"Rate, between 0 and 1, at which photon lookups shrink. Lower values sharpen caustics faster at the cost of more noise" default:"${defaultPhotonAlpha}"`
 }
 
 func main() {
@@ -55,14 +94,24 @@ func main() {
 		kong.Name(programName),
 		kong.Description("A path tracer implemented in Go"),
 		kong.Vars{
-			"defaultNumWorkers":       fmt.Sprintf("%v", runtime.NumCPU()),
-			"defaultXSize":            defaultXSize,
-			"defaultYSize":            defaultYSize,
-			"defaultSamples":          defaultSamples,
-			"defaultMaxDepth":         defaultMaxDepth,
-			"defaultOutputFile":       defaultOutputFile,
-			"defaultSceneFile":        defaultSceneFile,
-			"defaultDiscoveryTimeout": defaultDiscoveryTimeout,
+			"defaultNumWorkers":         fmt.Sprintf("%v", runtime.NumCPU()),
+			"defaultXSize":              defaultXSize,
+			"defaultYSize":              defaultYSize,
+			"defaultSamples":            defaultSamples,
+			"defaultMaxDepth":           defaultMaxDepth,
+			"defaultRouletteDepth":      defaultRouletteDepth,
+			"defaultOutputFile":         defaultOutputFile,
+			"defaultSceneFile":          defaultSceneFile,
+			"defaultDiscoveryTimeout":   defaultDiscoveryTimeout,
+			"defaultMinSamples":         defaultMinSamples,
+			"defaultNoiseThreshold":     defaultNoiseThreshold,
+			"defaultPassSamples":        defaultPassSamples,
+			"defaultCheckpointInterval": defaultCheckpointInterval,
+			"defaultDenoiseIterations":  fmt.Sprintf("%v", postprocess.DefaultDenoiseIterations),
+			"defaultBootstrapSamples":   defaultBootstrapSamples,
+			"defaultLargeStepProb":      defaultLargeStepProb,
+			"defaultPhotons":            defaultPhotons,
+			"defaultPhotonAlpha":        defaultPhotonAlpha,
 		})
 
 	setupLogging(flags.LogLevel)
@@ -96,19 +145,48 @@ func main() {
 	}
 
 	cfg := &config.Config{
-		Scene:            flags.Scene,
-		NumWorkers:       flags.NumWorkers,
-		XSize:            flags.XSize,
-		YSize:            flags.YSize,
-		Samples:          flags.Samples,
-		Sampler:          flags.Sampler,
-		Depth:            flags.Depth,
-		OutputMode:       flags.OutputMode,
-		OutputFile:       flags.OutputFile,
-		Verbose:          flags.Verbose,
-		Preview:          flags.Preview,
-		DisplayMode:      flags.DisplayMode,
-		DiscoveryTimeout: flags.DiscoveryTimeout,
+		Scene:                flags.Scene,
+		NumWorkers:           flags.NumWorkers,
+		XSize:                flags.XSize,
+		YSize:                flags.YSize,
+		Samples:              flags.Samples,
+		Sampler:              flags.Sampler,
+		Depth:                flags.Depth,
+		MaxDiffuseDepth:      flags.MaxDiffuseDepth,
+		MaxSpecularDepth:     flags.MaxSpecularDepth,
+		MaxTransmissionDepth: flags.MaxTransmissionDepth,
+		MaxVolumeDepth:       flags.MaxVolumeDepth,
+		RussianRouletteDepth: flags.RussianRouletteDepth,
+		OutputMode:           flags.OutputMode,
+		OutputFile:           flags.OutputFile,
+		Verbose:              flags.Verbose,
+		Preview:              flags.Preview,
+		DisplayMode:          flags.DisplayMode,
+		DiscoveryTimeout:     flags.DiscoveryTimeout,
+		Adaptive:             flags.Adaptive,
+		MinSamples:           flags.MinSamples,
+		NoiseThreshold:       flags.NoiseThreshold,
+		SampleHeatmap:        flags.SampleHeatmap,
+		Progressive:          flags.Progressive,
+		PassSamples:          flags.PassSamples,
+		Checkpoint:           flags.Checkpoint,
+		CheckpointInterval:   flags.CheckpointInterval,
+		Resume:               flags.Resume,
+		TimeLimit:            flags.TimeLimit,
+		TargetNoise:          flags.TargetNoise,
+		AOVs:                 flags.AOVs,
+		LightGroups:          flags.LightGroups,
+		Denoise:              flags.Denoise,
+		DenoiseIterations:    flags.DenoiseIterations,
+		MLTBootstrapSamples:  flags.MLTBootstrapSamples,
+		MLTLargeStepProb:     flags.MLTLargeStepProb,
+		Photons:              flags.Photons,
+		PhotonRadius:         flags.PhotonRadius,
+		PhotonAlpha:          flags.PhotonAlpha,
+		SampleGenerator:      flags.SampleGenerator,
+		Seed:                 flags.Seed,
+		Filter:               flags.Filter,
+		FilterRadius:         flags.FilterRadius,
 	}
 
 	switch flags.Role {
@@ -0,0 +1,139 @@
+// Package adaptive implements per-pixel convergence estimation for adaptive sampling.
+package adaptive
+
+import (
+	"math"
+)
+
+const (
+	// DefaultMinSamples is the default number of samples taken before a pixel can be considered converged.
+	DefaultMinSamples = 16
+	// DefaultThreshold is the default relative standard error at which a pixel is considered converged.
+	DefaultThreshold = 0.05
+	// DefaultBatchSize is the default number of samples taken between convergence checks.
+	DefaultBatchSize = 8
+
+	// luminanceFloor avoids dividing by zero when estimating the relative error of very dark pixels.
+	luminanceFloor = 1e-3
+)
+
+// Config holds the adaptive sampling parameters.
+type Config struct {
+	// MinSamples is the minimum number of samples per pixel.
+	MinSamples int
+	// MaxSamples is the maximum number of samples per pixel.
+	MaxSamples int
+	// Threshold is the relative standard error of the pixel luminance below which sampling stops.
+	Threshold float64
+	// BatchSize is the number of samples taken between convergence checks.
+	BatchSize int
+}
+
+// NewConfig returns a new adaptive sampling configuration.
+// Invalid values are replaced with sensible defaults.
+func NewConfig(minSamples int, maxSamples int, threshold float64) *Config {
+	if maxSamples < 1 {
+		maxSamples = 1
+	}
+
+	if minSamples < 1 {
+		minSamples = DefaultMinSamples
+	}
+
+	if minSamples > maxSamples {
+		minSamples = maxSamples
+	}
+
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	return &Config{
+		MinSamples: minSamples,
+		MaxSamples: maxSamples,
+		Threshold:  threshold,
+		BatchSize:  DefaultBatchSize,
+	}
+}
+
+// Estimator tracks the running mean and variance of the luminance of a pixel using Welford's algorithm.
+type Estimator struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+// Add adds a new luminance sample to the estimator.
+func (e *Estimator) Add(luminance float64) {
+	if math.IsNaN(luminance) || math.IsInf(luminance, 0) {
+		luminance = 0
+	}
+
+	e.n++
+	delta := luminance - e.mean
+	e.mean += delta / float64(e.n)
+	e.m2 += delta * (luminance - e.mean)
+}
+
+// Count returns the number of samples added to the estimator.
+func (e *Estimator) Count() int {
+	return e.n
+}
+
+// Mean returns the mean luminance.
+func (e *Estimator) Mean() float64 {
+	return e.mean
+}
+
+// Variance returns the unbiased sample variance of the luminance.
+func (e *Estimator) Variance() float64 {
+	if e.n < 2 {
+		return 0
+	}
+
+	return e.m2 / float64(e.n-1)
+}
+
+// RelativeError returns the standard error of the mean relative to the mean luminance.
+func (e *Estimator) RelativeError() float64 {
+	if e.n < 2 {
+		return math.Inf(1)
+	}
+
+	stdErr := math.Sqrt(e.Variance() / float64(e.n))
+
+	return stdErr / math.Max(math.Abs(e.mean), luminanceFloor)
+}
+
+// Done returns whether no more samples should be taken for this pixel.
+func (e *Estimator) Done(cfg *Config) bool {
+	if e.n >= cfg.MaxSamples {
+		return true
+	}
+
+	if e.n < cfg.MinSamples {
+		return false
+	}
+
+	return e.RelativeError() < cfg.Threshold
+}
+
+// Next returns the number of samples to take before the next convergence check.
+func (e *Estimator) Next(cfg *Config) int {
+	remaining := cfg.MaxSamples - e.n
+	if e.n < cfg.MinSamples {
+		return cfg.MinSamples - e.n
+	}
+
+	batch := cfg.BatchSize
+	if batch < 1 {
+		batch = 1
+	}
+
+	return min(batch, remaining)
+}
+
+// Luminance returns the Rec. 709 luminance of a linear RGB triplet.
+func Luminance(r, g, b float64) float64 {
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
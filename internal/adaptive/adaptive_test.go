@@ -0,0 +1,114 @@
+package adaptive
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewConfig(t *testing.T) {
+	testData := []struct {
+		name       string
+		minSamples int
+		maxSamples int
+		threshold  float64
+		want       Config
+	}{
+		{
+			name:       "valid values",
+			minSamples: 8,
+			maxSamples: 256,
+			threshold:  0.05,
+			want:       Config{MinSamples: 8, MaxSamples: 256, Threshold: 0.05, BatchSize: DefaultBatchSize},
+		},
+		{
+			name:       "min larger than max",
+			minSamples: 64,
+			maxSamples: 32,
+			threshold:  0.05,
+			want:       Config{MinSamples: 32, MaxSamples: 32, Threshold: 0.05, BatchSize: DefaultBatchSize},
+		},
+		{
+			name:       "defaults",
+			maxSamples: 100,
+			want:       Config{MinSamples: DefaultMinSamples, MaxSamples: 100, Threshold: DefaultThreshold, BatchSize: DefaultBatchSize},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := NewConfig(test.minSamples, test.maxSamples, test.threshold)
+			if *got != test.want {
+				t.Errorf("NewConfig() = %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestEstimatorMeanVariance(t *testing.T) {
+	var est Estimator
+	for _, v := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		est.Add(v)
+	}
+
+	if est.Count() != 8 {
+		t.Errorf("Count() = %v, want 8", est.Count())
+	}
+
+	if math.Abs(est.Mean()-5.0) > 1e-12 {
+		t.Errorf("Mean() = %v, want 5", est.Mean())
+	}
+
+	// Unbiased variance of the data set above is 32/7.
+	if math.Abs(est.Variance()-32.0/7.0) > 1e-12 {
+		t.Errorf("Variance() = %v, want %v", est.Variance(), 32.0/7.0)
+	}
+}
+
+func TestEstimatorConvergence(t *testing.T) {
+	cfg := NewConfig(4, 1000, 0.01)
+
+	// A constant signal converges as soon as the minimum number of samples is reached.
+	var flat Estimator
+	for !flat.Done(cfg) {
+		for range flat.Next(cfg) {
+			flat.Add(0.5)
+		}
+	}
+
+	if flat.Count() != cfg.MinSamples {
+		t.Errorf("flat pixel used %v samples, want %v", flat.Count(), cfg.MinSamples)
+	}
+
+	// A very noisy signal never converges and stops at the maximum number of samples.
+	var noisy Estimator
+	i := 0
+	for !noisy.Done(cfg) {
+		for range noisy.Next(cfg) {
+			if i%10 == 0 {
+				noisy.Add(100)
+			} else {
+				noisy.Add(0)
+			}
+			i++
+		}
+	}
+
+	if noisy.Count() != cfg.MaxSamples {
+		t.Errorf("noisy pixel used %v samples, want %v", noisy.Count(), cfg.MaxSamples)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	counts := []int{0, 50, 100, 100}
+	img := Heatmap(counts, 2, 2, 100)
+
+	low := img.Float64NRGBAAt(0, 0)
+	if low.R != 0 || low.G != 0 || low.B != 0.5 {
+		t.Errorf("unexpected colour for lowest count: %+v", low)
+	}
+
+	high := img.Float64NRGBAAt(1, 1)
+	if high.R != 1 || high.G != 0 || high.B != 0 {
+		t.Errorf("unexpected colour for highest count: %+v", high)
+	}
+}
@@ -0,0 +1,62 @@
+package adaptive
+
+import (
+	"image"
+
+	"github.com/flynn-nrg/floatimage/colour"
+	"github.com/flynn-nrg/floatimage/floatimage"
+)
+
+// heatmapRamp is the colour ramp used to visualise sample counts, from fewest to most samples.
+var heatmapRamp = [][3]float64{
+	{0.0, 0.0, 0.5},
+	{0.0, 0.5, 1.0},
+	{0.0, 1.0, 0.5},
+	{1.0, 1.0, 0.0},
+	{1.0, 0.0, 0.0},
+}
+
+// Heatmap returns an image where every pixel is coloured according to the number of samples it received.
+// Counts are stored row by row and normalised against maxSamples.
+func Heatmap(counts []int, width int, height int, maxSamples int) *floatimage.Float64NRGBA {
+	img := floatimage.NewFloat64NRGBA(image.Rect(0, 0, width, height), make([]float64, width*height*4))
+
+	if maxSamples < 1 {
+		maxSamples = 1
+	}
+
+	for y := range height {
+		for x := range width {
+			i := y*width + x
+			if i >= len(counts) {
+				continue
+			}
+
+			t := float64(counts[i]) / float64(maxSamples)
+			r, g, b := rampAt(t)
+			img.Set(x, y, colour.Float64NRGBA{R: r, G: g, B: b, A: 1.0})
+		}
+	}
+
+	return img
+}
+
+func rampAt(t float64) (float64, float64, float64) {
+	if t <= 0 {
+		c := heatmapRamp[0]
+		return c[0], c[1], c[2]
+	}
+
+	if t >= 1 {
+		c := heatmapRamp[len(heatmapRamp)-1]
+		return c[0], c[1], c[2]
+	}
+
+	pos := t * float64(len(heatmapRamp)-1)
+	i := int(pos)
+	f := pos - float64(i)
+	c0 := heatmapRamp[i]
+	c1 := heatmapRamp[i+1]
+
+	return c0[0] + f*(c1[0]-c0[0]), c0[1] + f*(c1[1]-c0[1]), c0[2] + f*(c1[2]-c0[2])
+}
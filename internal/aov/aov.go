@@ -0,0 +1,251 @@
+// Package aov implements arbitrary output variables: per-pixel data such as albedo, normals or light path
+// components that are recorded while the beauty image is being rendered.
+package aov
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type identifies an AOV.
+type Type int
+
+const (
+	Albedo Type = iota
+	Normal
+	Depth
+	Position
+	UV
+	MaterialID
+	ObjectID
+	CryptoObject
+	CryptoMaterial
+	Emission
+	DiffuseDirect
+	DiffuseIndirect
+	SpecularDirect
+	SpecularIndirect
+	numTypes
+)
+
+// Light path AOVs are contiguous so that they can be stored in arrays.
+const (
+	firstLighting = Emission
+	numLighting   = int(numTypes - firstLighting)
+)
+
+// CryptomatteRanks is the number of identifiers stored per pixel by the Cryptomatte AOVs, in decreasing order of coverage.
+const CryptomatteRanks = 6
+
+var typeNames = [numTypes]string{
+	Albedo:           "albedo",
+	Normal:           "normal",
+	Depth:            "depth",
+	Position:         "position",
+	UV:               "uv",
+	MaterialID:       "material_id",
+	ObjectID:         "object_id",
+	CryptoObject:     "crypto_object",
+	CryptoMaterial:   "crypto_material",
+	Emission:         "emission",
+	DiffuseDirect:    "diffuse_direct",
+	DiffuseIndirect:  "diffuse_indirect",
+	SpecularDirect:   "specular_direct",
+	SpecularIndirect: "specular_indirect",
+}
+
+// String returns the name of the AOV.
+func (t Type) String() string {
+	if t < 0 || t >= numTypes {
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+
+	return typeNames[t]
+}
+
+// Channels returns the number of values stored per pixel.
+func (t Type) Channels() int {
+	switch t {
+	case Depth, MaterialID, ObjectID:
+		return 1
+	case UV:
+		return 2
+	case CryptoObject, CryptoMaterial:
+		// Identifier and coverage pairs.
+		return 2 * CryptomatteRanks
+	default:
+		return 3
+	}
+}
+
+// IsID returns whether the AOV holds identifiers. Identifiers are never blended: every pixel keeps the one
+// recorded by its first sample.
+func (t Type) IsID() bool {
+	return t == MaterialID || t == ObjectID
+}
+
+// IsCryptomatte returns whether the AOV holds Cryptomatte identifier and coverage pairs.
+func (t Type) IsCryptomatte() bool {
+	return t == CryptoObject || t == CryptoMaterial
+}
+
+// IsLighting returns whether the AOV holds a light path component.
+// The light path components of a pixel add up to its beauty value.
+func (t Type) IsLighting() bool {
+	return t >= firstLighting && t < numTypes
+}
+
+// IsColour returns whether the AOV holds colour values, which are subject to colour space conversion.
+func (t Type) IsColour() bool {
+	return t == Albedo || t.IsLighting()
+}
+
+// All returns every supported AOV.
+func All() []Type {
+	types := make([]Type, numTypes)
+	for i := range types {
+		types[i] = Type(i)
+	}
+
+	return types
+}
+
+// FromString returns the AOV with the given name.
+func FromString(name string) (Type, error) {
+	for i, n := range typeNames {
+		if n == name {
+			return Type(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown AOV %q", name)
+}
+
+// Parse returns the AOVs with the given names. The name "all" selects every AOV.
+// Duplicates are ignored.
+func Parse(names []string) ([]Type, error) {
+	var types []Type
+	seen := make(map[Type]bool)
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if name == "all" {
+			return All(), nil
+		}
+
+		t, err := FromString(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	return types, nil
+}
+
+// Names returns the names of the given AOVs.
+func Names(types []Type) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+
+	return names
+}
+
+// Layout describes the values stored for every pixel: the channels of each AOV followed by the
+// contribution of each light group.
+type Layout struct {
+	Types []Type
+	// LightGroups holds the names of the emissive materials whose contribution is recorded on its own.
+	LightGroups []string
+}
+
+// Empty returns whether the layout holds no values.
+func (l Layout) Empty() bool {
+	return len(l.Types) == 0 && len(l.LightGroups) == 0
+}
+
+// Stride returns the number of values stored per pixel.
+func (l Layout) Stride() int {
+	stride := 3 * len(l.LightGroups)
+	for _, t := range l.Types {
+		stride += t.Channels()
+	}
+
+	return stride
+}
+
+// Layers returns the layers of the layout in storage order.
+func (l Layout) Layers() []Layer {
+	layers := make([]Layer, 0, len(l.Types)+len(l.LightGroups))
+	offset := 0
+
+	for _, t := range l.Types {
+		layers = append(layers, Layer{Type: t, Offset: offset})
+		offset += t.Channels()
+	}
+
+	for _, g := range l.LightGroups {
+		layers = append(layers, Layer{LightGroup: g, Offset: offset})
+		offset += 3
+	}
+
+	return layers
+}
+
+// Layer is a set of channels stored for every pixel: an AOV or the contribution of a light group.
+type Layer struct {
+	// Type is the AOV stored in the layer. It is only meaningful if LightGroup is empty.
+	Type Type
+	// LightGroup is the name of the light group stored in the layer, if any.
+	LightGroup string
+	// Offset is the position of the first channel of the layer within the values of a pixel.
+	Offset int
+}
+
+// Name returns the name of the layer.
+func (l Layer) Name() string {
+	if l.LightGroup != "" {
+		return "light_group_" + l.LightGroup
+	}
+
+	return l.Type.String()
+}
+
+// Channels returns the number of values stored per pixel.
+func (l Layer) Channels() int {
+	if l.LightGroup != "" {
+		return 3
+	}
+
+	return l.Type.Channels()
+}
+
+// IsID returns whether the layer holds identifiers.
+func (l Layer) IsID() bool {
+	return l.LightGroup == "" && l.Type.IsID()
+}
+
+// IsCryptomatte returns whether the layer holds Cryptomatte identifier and coverage pairs.
+func (l Layer) IsCryptomatte() bool {
+	return l.LightGroup == "" && l.Type.IsCryptomatte()
+}
+
+// IsLighting returns whether the layer holds a light path component or a light group.
+func (l Layer) IsLighting() bool {
+	return l.LightGroup != "" || l.Type.IsLighting()
+}
+
+// IsColour returns whether the layer holds colour values.
+func (l Layer) IsColour() bool {
+	return l.LightGroup != "" || l.Type.IsColour()
+}
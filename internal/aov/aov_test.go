@@ -0,0 +1,194 @@
+package aov
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParse(t *testing.T) {
+	testData := []struct {
+		name    string
+		names   []string
+		want    []Type
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			names: nil,
+			want:  nil,
+		},
+		{
+			name:  "selection",
+			names: []string{"albedo", " depth", "diffuse_direct"},
+			want:  []Type{Albedo, Depth, DiffuseDirect},
+		},
+		{
+			name:  "duplicates",
+			names: []string{"normal", "normal"},
+			want:  []Type{Normal},
+		},
+		{
+			name:  "all",
+			names: []string{"all"},
+			want:  All(),
+		},
+		{
+			name:    "unknown",
+			names:   []string{"beauty"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.names)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLayoutLayers(t *testing.T) {
+	layout := Layout{Types: []Type{Normal, Depth, UV}, LightGroups: []string{"Key"}}
+
+	want := []Layer{
+		{Type: Normal, Offset: 0},
+		{Type: Depth, Offset: 3},
+		{Type: UV, Offset: 4},
+		{LightGroup: "Key", Offset: 6},
+	}
+	if diff := cmp.Diff(want, layout.Layers()); diff != "" {
+		t.Errorf("Layers() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := layout.Stride(); got != 9 {
+		t.Errorf("Stride() = %v, want 9", got)
+	}
+}
+
+func TestID(t *testing.T) {
+	testData := []struct {
+		name string
+		want uint32
+	}{
+		{name: "", want: 0},
+		{name: "hello", want: 0x248bfa47},
+		{name: "The quick brown fox jumps over the lazy dog", want: 0x2e4ff723},
+	}
+
+	for _, test := range testData {
+		if got := ID(test.name); got != test.want {
+			t.Errorf("ID(%q) = %#x, want %#x", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIDToFloat(t *testing.T) {
+	for _, id := range []uint32{0, 1, 0x7f800000, 0xffffffff, ID("hello")} {
+		f := IDToFloat(id)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			t.Errorf("IDToFloat(%#x) = %v", id, f)
+		}
+
+		if f != 0 && math.Abs(f) < math.SmallestNonzeroFloat32*(1<<23) {
+			t.Errorf("IDToFloat(%#x) = %v is denormal", id, f)
+		}
+	}
+}
+
+func TestPixel(t *testing.T) {
+	p := NewPixel(Layout{Types: []Type{Albedo, Depth, MaterialID, DiffuseDirect}, LightGroups: []string{"Key"}})
+
+	first := &Sample{Albedo: vec3.Vec3Impl{X: 1}, Depth: 2, MaterialID: 7}
+	first.AddLighting(DiffuseDirect, vec3.Vec3Impl{Y: 4})
+	first.AddLightGroup(ID("Key"), vec3.Vec3Impl{Z: 6})
+	first.AddLightGroup(ID("Fill"), vec3.Vec3Impl{X: 1})
+	second := &Sample{Albedo: vec3.Vec3Impl{X: 3}, Depth: 4, MaterialID: 9}
+
+	p.Add(first)
+	p.Add(second)
+
+	want := []float64{2, 0, 0, 3, IDToFloat(7), 0, 2, 0, 0, 0, 3}
+	if diff := cmp.Diff(want, p.Mean(nil)); diff != "" {
+		t.Errorf("Mean() mismatch (-want +got):\n%s", diff)
+	}
+
+	p.Reset()
+	p.Add(second)
+
+	want = []float64{3, 0, 0, 4, IDToFloat(9), 0, 0, 0, 0, 0, 0}
+	if diff := cmp.Diff(want, p.Mean(nil)); diff != "" {
+		t.Errorf("Mean() after Reset() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuffersAccumulate(t *testing.T) {
+	b := NewBuffers(Layout{Types: []Type{Depth, ObjectID}}, 2, 1)
+
+	b.Accumulate(1, 0, 0, 4, []float64{1, 5})
+	b.Accumulate(1, 0, 4, 12, []float64{3, 6})
+	// Out of bounds pixels are ignored.
+	b.Accumulate(2, 0, 0, 1, []float64{1, 1})
+
+	want := []float64{0, 0, 2.5, 5}
+	if diff := cmp.Diff(want, b.Data()); diff != "" {
+		t.Errorf("Data() mismatch (-want +got):\n%s", diff)
+	}
+
+	depth := b.Layers()[0]
+	img := b.Image(depth)
+	if got := img.Pix[4:8]; !cmp.Equal(got, []float64{2.5, 2.5, 2.5, 1}) {
+		t.Errorf("Image(depth) pixel = %v, want [2.5 2.5 2.5 1]", got)
+	}
+
+	if got := b.Channel(b.Layers()[1], 0); !cmp.Equal(got, []float64{0, 5}) {
+		t.Errorf("Channel(object_id) = %v, want [0 5]", got)
+	}
+}
+
+func TestCryptomatte(t *testing.T) {
+	layout := Layout{Types: []Type{CryptoObject, CryptoMaterial}}
+	p := NewPixel(layout)
+
+	// Three samples hit object 1, one hits object 2 and one misses the scene.
+	for _, s := range []*Sample{
+		{ObjectID: 1, MaterialID: 10},
+		{ObjectID: 2, MaterialID: 10},
+		{ObjectID: 1, MaterialID: 10},
+		{},
+		{ObjectID: 1, MaterialID: 11},
+	} {
+		p.Add(s)
+	}
+
+	padding := make([]float64, 2*(CryptomatteRanks-2))
+	object := append([]float64{IDToFloat(1), 0.6, IDToFloat(2), 0.2}, padding...)
+	material := append([]float64{IDToFloat(10), 0.6, IDToFloat(11), 0.2}, padding...)
+	if diff := cmp.Diff(append(slices.Clone(object), material...), p.Mean(nil)); diff != "" {
+		t.Errorf("Mean() mismatch (-want +got):\n%s", diff)
+	}
+
+	b := NewBuffers(layout, 1, 1)
+	b.Accumulate(0, 0, 0, 5, p.Mean(nil))
+
+	// A second pass where every sample hits object 2 and material 12.
+	p.Reset()
+	p.Add(&Sample{ObjectID: 2, MaterialID: 12})
+	b.Accumulate(0, 0, 5, 5, p.Mean(nil))
+
+	object = append([]float64{IDToFloat(2), 0.6, IDToFloat(1), 0.3}, padding...)
+	material = append([]float64{IDToFloat(12), 0.5, IDToFloat(10), 0.3, IDToFloat(11), 0.1}, padding[2:]...)
+	if diff := cmp.Diff(append(object, material...), b.Data(), cmpopts.EquateApprox(0, 1e-12)); diff != "" {
+		t.Errorf("Data() mismatch (-want +got):\n%s", diff)
+	}
+}
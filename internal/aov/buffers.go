@@ -0,0 +1,143 @@
+package aov
+
+import (
+	"image"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+)
+
+// Buffers holds the accumulated values of a set of AOVs for every pixel of an image.
+type Buffers struct {
+	layout Layout
+	layers []Layer
+	stride int
+	width  int
+	height int
+	data   []float64
+}
+
+// NewBuffers returns zeroed buffers for the values of the given layout.
+func NewBuffers(layout Layout, width int, height int) *Buffers {
+	stride := layout.Stride()
+
+	return &Buffers{
+		layout: layout,
+		layers: layout.Layers(),
+		stride: stride,
+		width:  width,
+		height: height,
+		data:   make([]float64, width*height*stride),
+	}
+}
+
+// Layout returns the layout of the values stored in the buffers.
+func (b *Buffers) Layout() Layout {
+	return b.layout
+}
+
+// Layers returns the layers stored in the buffers.
+func (b *Buffers) Layers() []Layer {
+	return b.layers
+}
+
+// Layer returns the layer holding AOV t and whether it is stored in the buffers.
+func (b *Buffers) Layer(t Type) (Layer, bool) {
+	for _, l := range b.layers {
+		if l.LightGroup == "" && l.Type == t {
+			return l, true
+		}
+	}
+
+	return Layer{}, false
+}
+
+// Stride returns the number of values stored per pixel.
+func (b *Buffers) Stride() int {
+	return b.stride
+}
+
+// Data returns the values of every pixel, stored row by row.
+func (b *Buffers) Data() []float64 {
+	return b.data
+}
+
+// Accumulate stores the mean of n new samples for the pixel at (x, y), which already holds the mean of prev samples.
+// values holds the channels of every layer in the order returned by Layers.
+func (b *Buffers) Accumulate(x, y int, prev int, n int, values []float64) {
+	if x < 0 || x >= b.width || y < 0 || y >= b.height || len(values) < b.stride {
+		return
+	}
+
+	pix := b.data[(y*b.width+x)*b.stride:]
+	if prev <= 0 || n <= 0 {
+		copy(pix, values[:b.stride])
+		return
+	}
+
+	w := float64(n) / float64(prev+n)
+	for _, l := range b.layers {
+		if l.IsID() {
+			continue
+		}
+
+		if l.IsCryptomatte() {
+			mergeCoverage(pix[l.Offset:], values[l.Offset:], 1-w, w)
+			continue
+		}
+
+		for c := l.Offset; c < l.Offset+l.Channels(); c++ {
+			pix[c] += (values[c] - pix[c]) * w
+		}
+	}
+}
+
+// ConvertXYZ converts the colour layers of a spectral render from CIE XYZ to linear ACEScg.
+// Exposure is applied to the light path layers so that they match the beauty image.
+func (b *Buffers) ConvertXYZ(exposure float64) {
+	for _, l := range b.layers {
+		if !l.IsColour() {
+			continue
+		}
+
+		scale := 1.0
+		if l.IsLighting() {
+			scale = exposure
+		}
+
+		for p := l.Offset; p < len(b.data); p += b.stride {
+			b.data[p], b.data[p+1], b.data[p+2] = spectral.XYZToACEScg(b.data[p]*scale, b.data[p+1]*scale, b.data[p+2]*scale)
+		}
+	}
+}
+
+// Channel returns channel c of layer l for every pixel, stored row by row.
+func (b *Buffers) Channel(l Layer, c int) []float64 {
+	values := make([]float64, b.width*b.height)
+	for i := range values {
+		values[i] = b.data[i*b.stride+l.Offset+c]
+	}
+
+	return values
+}
+
+// Image returns layer l as an image. Single channel layers are replicated to the red, green and blue channels
+// and two channel layers leave the blue channel empty.
+func (b *Buffers) Image(l Layer) *floatimage.Float64NRGBA {
+	img := floatimage.NewFloat64NRGBA(image.Rect(0, 0, b.width, b.height), make([]float64, b.width*b.height*4))
+	for i := range b.width * b.height {
+		src := b.data[i*b.stride+l.Offset:]
+		dst := img.Pix[i*4 : i*4+4]
+		switch l.Channels() {
+		case 1:
+			dst[0], dst[1], dst[2] = src[0], src[0], src[0]
+		case 2:
+			dst[0], dst[1] = src[0], src[1]
+		default:
+			dst[0], dst[1], dst[2] = src[0], src[1], src[2]
+		}
+		dst[3] = 1.0
+	}
+
+	return img
+}
@@ -0,0 +1,94 @@
+package aov
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// idCount is the number of samples of a pixel that hit a given identifier.
+type idCount struct {
+	id uint32
+	n  int
+}
+
+// coverage is the fraction of a pixel covered by an identifier, stored as in the Cryptomatte AOVs.
+type coverage struct {
+	id    float64
+	value float64
+}
+
+// addCount increments the number of samples that hit id. Samples that hit nothing are not recorded,
+// so the coverage of pixels along the silhouette of the scene adds up to less than 1.
+func addCount(counts []idCount, id uint32) []idCount {
+	if id == 0 {
+		return counts
+	}
+
+	for i := range counts {
+		if counts[i].id == id {
+			counts[i].n++
+			return counts
+		}
+	}
+
+	return append(counts, idCount{id: id, n: 1})
+}
+
+// appendCoverage appends the identifier and coverage pairs of the given sample counts, out of n samples, to dst.
+func appendCoverage(dst []float64, counts []idCount, n int) []float64 {
+	entries := make([]coverage, len(counts))
+	for i, c := range counts {
+		entries[i] = coverage{id: IDToFloat(c.id), value: float64(c.n) / float64(n)}
+	}
+
+	return appendRanked(dst, entries)
+}
+
+// appendRanked appends the CryptomatteRanks entries with the largest coverage to dst in decreasing order of coverage.
+// Missing ranks are filled with zeros.
+func appendRanked(dst []float64, entries []coverage) []float64 {
+	slices.SortFunc(entries, func(a, b coverage) int {
+		if c := cmp.Compare(b.value, a.value); c != 0 {
+			return c
+		}
+		// Break ties deterministically.
+		return cmp.Compare(math.Float64bits(a.id), math.Float64bits(b.id))
+	})
+
+	for i := range CryptomatteRanks {
+		if i < len(entries) {
+			dst = append(dst, entries[i].id, entries[i].value)
+		} else {
+			dst = append(dst, 0, 0)
+		}
+	}
+
+	return dst
+}
+
+// mergeCoverage replaces the identifier and coverage pairs in dst with the weighted sum of those in dst and src.
+func mergeCoverage(dst []float64, src []float64, wDst float64, wSrc float64) {
+	entries := make([]coverage, 0, 4*CryptomatteRanks)
+
+	add := func(pairs []float64, w float64) {
+		for i := 0; i+1 < len(pairs); i += 2 {
+			id, value := pairs[i], pairs[i+1]*w
+			if value == 0 {
+				continue
+			}
+
+			j := slices.IndexFunc(entries, func(e coverage) bool { return e.id == id })
+			if j < 0 {
+				entries = append(entries, coverage{id: id, value: value})
+			} else {
+				entries[j].value += value
+			}
+		}
+	}
+
+	add(dst[:2*CryptomatteRanks], wDst)
+	add(src[:2*CryptomatteRanks], wSrc)
+
+	appendRanked(dst[:0], entries)
+}
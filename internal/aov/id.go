@@ -0,0 +1,74 @@
+package aov
+
+import (
+	"math"
+	"math/bits"
+)
+
+// ID returns the identifier of a named object or material. Unnamed entities have the identifier 0.
+// Identifiers are the 32 bit MurmurHash3 of the name, which is the hash used by Cryptomatte.
+func ID(name string) uint32 {
+	if name == "" {
+		return 0
+	}
+
+	return murmur3([]byte(name), 0)
+}
+
+// IDToFloat converts an identifier to a value that survives being stored in a 32 bit float channel.
+// Identifiers that would map to a denormal, infinite or NaN value have a bit of their exponent flipped,
+// as specified by Cryptomatte.
+func IDToFloat(id uint32) float64 {
+	if exp := (id >> 23) & 0xff; exp == 0 || exp == 0xff {
+		id ^= 1 << 23
+	}
+
+	return float64(math.Float32frombits(id))
+}
+
+// murmur3 implements the 32 bit x86 variant of MurmurHash3.
+func murmur3(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	h := seed
+	n := len(data) / 4 * 4
+
+	for i := 0; i < n; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	switch len(data) - n {
+	case 3:
+		k ^= uint32(data[n+2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(data[n+1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(data[n])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+
+	return h
+}
@@ -0,0 +1,254 @@
+package aov
+
+import (
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Sample holds the AOVs recorded while tracing a single camera ray.
+type Sample struct {
+	Albedo     vec3.Vec3Impl
+	Normal     vec3.Vec3Impl
+	Position   vec3.Vec3Impl
+	Depth      float64
+	U          float64
+	V          float64
+	MaterialID uint32
+	ObjectID   uint32
+
+	lighting [numLighting]vec3.Vec3Impl
+
+	// Spectral samplers record scalar values at the wavelength of the ray, which are converted to
+	// CIE XYZ by ResolveSpectral.
+	spectralAlbedo   float64
+	spectralLighting [numLighting]float64
+
+	lightGroups []lightGroupSample
+}
+
+// lightGroupSample holds the light emitted by the surfaces with a given material that reaches the camera.
+type lightGroupSample struct {
+	id       uint32
+	colour   vec3.Vec3Impl
+	spectral float64
+}
+
+// Reset clears the sample so that it can be reused.
+func (s *Sample) Reset() {
+	*s = Sample{lightGroups: s.lightGroups[:0]}
+}
+
+// SetHit records the geometric properties of the first intersection of a camera ray.
+func (s *Sample) SetHit(r ray.Ray, rec *hitrecord.HitRecord) {
+	s.Normal = rec.Normal()
+	s.Position = rec.P()
+	s.Depth = rec.T() * r.Direction().Length()
+	s.U = rec.U()
+	s.V = rec.V()
+	s.MaterialID = rec.MaterialID()
+	s.ObjectID = rec.ObjectID()
+}
+
+// AddLighting adds v to the light path AOV t.
+func (s *Sample) AddLighting(t Type, v vec3.Vec3Impl) {
+	i := t - firstLighting
+	s.lighting[i] = vec3.Add(s.lighting[i], v)
+}
+
+// Lighting returns the value of the light path AOV t.
+func (s *Sample) Lighting(t Type) vec3.Vec3Impl {
+	return s.lighting[t-firstLighting]
+}
+
+// AddLightGroup adds v to the contribution of the light emitted by surfaces with the material id.
+func (s *Sample) AddLightGroup(id uint32, v vec3.Vec3Impl) {
+	g := s.lightGroup(id)
+	g.colour = vec3.Add(g.colour, v)
+}
+
+// AddSpectralLightGroup adds the radiance v at the wavelength of the camera ray to the contribution of the light
+// emitted by surfaces with the material id.
+func (s *Sample) AddSpectralLightGroup(id uint32, v float64) {
+	s.lightGroup(id).spectral += v
+}
+
+// lightGroup returns the contribution of the light emitted by surfaces with the material id.
+func (s *Sample) lightGroup(id uint32) *lightGroupSample {
+	for i := range s.lightGroups {
+		if s.lightGroups[i].id == id {
+			return &s.lightGroups[i]
+		}
+	}
+
+	s.lightGroups = append(s.lightGroups, lightGroupSample{id: id})
+	return &s.lightGroups[len(s.lightGroups)-1]
+}
+
+// SetSpectralAlbedo records the albedo at the wavelength of the camera ray.
+func (s *Sample) SetSpectralAlbedo(v float64) {
+	s.spectralAlbedo = v
+}
+
+// AddSpectralLighting adds the radiance v at the wavelength of the camera ray to the light path AOV t.
+func (s *Sample) AddSpectralLighting(t Type, v float64) {
+	s.spectralLighting[t-firstLighting] += v
+}
+
+// ResolveSpectral converts the spectral values to CIE XYZ using the same weights as the beauty sample.
+// albedoScale normalises the albedo so that a perfect reflector has a luminance of 1.
+func (s *Sample) ResolveSpectral(x, y, z float64, albedoScale float64) {
+	a := s.spectralAlbedo * albedoScale
+	s.Albedo = vec3.Vec3Impl{X: a * x, Y: a * y, Z: a * z}
+
+	for i, l := range s.spectralLighting {
+		s.lighting[i] = vec3.Vec3Impl{X: l * x, Y: l * y, Z: l * z}
+	}
+
+	for i := range s.lightGroups {
+		l := s.lightGroups[i].spectral
+		s.lightGroups[i].colour = vec3.Vec3Impl{X: l * x, Y: l * y, Z: l * z}
+	}
+}
+
+// values appends the channels of layer l to dst. id is the material identifier of light group layers.
+func (s *Sample) values(l Layer, id uint32, dst []float64) []float64 {
+	if l.LightGroup != "" {
+		var c vec3.Vec3Impl
+		for _, g := range s.lightGroups {
+			if g.id == id {
+				c = vec3.DeNAN(g.colour)
+				break
+			}
+		}
+
+		return append(dst, c.X, c.Y, c.Z)
+	}
+
+	switch t := l.Type; t {
+	case Albedo:
+		return append(dst, s.Albedo.X, s.Albedo.Y, s.Albedo.Z)
+	case Normal:
+		return append(dst, s.Normal.X, s.Normal.Y, s.Normal.Z)
+	case Depth:
+		return append(dst, s.Depth)
+	case Position:
+		return append(dst, s.Position.X, s.Position.Y, s.Position.Z)
+	case UV:
+		return append(dst, s.U, s.V)
+	case MaterialID:
+		return append(dst, IDToFloat(s.MaterialID))
+	case ObjectID:
+		return append(dst, IDToFloat(s.ObjectID))
+	case CryptoObject, CryptoMaterial:
+		// Coverage is accumulated by Pixel.
+		return append(dst, make([]float64, t.Channels())...)
+	default:
+		l := vec3.DeNAN(s.Lighting(t))
+		return append(dst, l.X, l.Y, l.Z)
+	}
+}
+
+// Pixel accumulates the AOV samples of a single pixel.
+type Pixel struct {
+	layers []Layer
+	ids    []uint32
+	// counts holds the number of samples that hit every identifier for Cryptomatte layers.
+	counts [][]idCount
+	sample Sample
+	sum    []float64
+	values []float64
+	n      int
+}
+
+// NewPixel returns an accumulator for the values of the given layout.
+func NewPixel(layout Layout) *Pixel {
+	layers := layout.Layers()
+	ids := make([]uint32, len(layers))
+	for i, l := range layers {
+		ids[i] = ID(l.LightGroup)
+	}
+
+	stride := layout.Stride()
+	return &Pixel{
+		layers: layers,
+		ids:    ids,
+		counts: make([][]idCount, len(layers)),
+		sum:    make([]float64, stride),
+		values: make([]float64, 0, stride),
+	}
+}
+
+// Sample returns an empty sample to be filled in and passed to Add. The sample is reused by later calls.
+func (p *Pixel) Sample() *Sample {
+	p.sample.Reset()
+	return &p.sample
+}
+
+// Reset discards the accumulated samples. A nil accumulator is valid and holds no AOVs.
+func (p *Pixel) Reset() {
+	if p == nil {
+		return
+	}
+
+	clear(p.sum)
+	for i := range p.counts {
+		p.counts[i] = p.counts[i][:0]
+	}
+	p.n = 0
+}
+
+// Add accumulates a sample.
+func (p *Pixel) Add(s *Sample) {
+	p.values = p.values[:0]
+	for i, l := range p.layers {
+		p.values = s.values(l, p.ids[i], p.values)
+	}
+
+	for j, l := range p.layers {
+		if l.IsCryptomatte() {
+			id := s.ObjectID
+			if l.Type == CryptoMaterial {
+				id = s.MaterialID
+			}
+			p.counts[j] = addCount(p.counts[j], id)
+			continue
+		}
+
+		for i := l.Offset; i < l.Offset+l.Channels(); i++ {
+			switch {
+			case !l.IsID():
+				p.sum[i] += p.values[i]
+			case p.n == 0:
+				p.sum[i] = p.values[i]
+			}
+		}
+	}
+
+	p.n++
+}
+
+// Mean appends the mean of the accumulated samples to dst. Identifiers are taken from the first sample and
+// Cryptomatte layers hold the coverage of the identifiers hit by the samples.
+func (p *Pixel) Mean(dst []float64) []float64 {
+	if p == nil {
+		return dst
+	}
+
+	for j, l := range p.layers {
+		if l.IsCryptomatte() {
+			dst = appendCoverage(dst, p.counts[j], max(p.n, 1))
+			continue
+		}
+
+		for i := l.Offset; i < l.Offset+l.Channels(); i++ {
+			if l.IsID() || p.n == 0 {
+				dst = append(dst, p.sum[i])
+			} else {
+				dst = append(dst, p.sum[i]/float64(p.n))
+			}
+		}
+	}
+
+	return dst
+}
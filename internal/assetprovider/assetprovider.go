@@ -35,6 +35,7 @@ type assetProviderServer struct {
 	scene            *pb_transport.Scene          // The main scene graph
 	textures         map[string]*texture.ImageTxt // Map of filename to texture data
 	displacementMaps map[string]*texture.ImageTxt // Map of filename to displacement map data
+	volumeFiles      map[string][]byte            // Map of filename to volume grid file contents
 	triangles        []*pb_transport.Triangle     // Slice of all triangles for streaming
 	trianglesMu      sync.RWMutex                 // Mutex for triangles access if concurrency is needed (not strictly for this simple server)
 }
@@ -51,7 +52,7 @@ type AssetProvider struct {
 // New creates a new AssetProvider, initializes its gRPC server,
 // and starts serving assets in a new goroutine.
 // It returns the listener address (target) and an error if initialization fails.
-func New(scene *pb_transport.Scene, textures map[string]*texture.ImageTxt, displacementMaps map[string]*texture.ImageTxt, triangles []*pb_transport.Triangle) (*AssetProvider, string, error) {
+func New(scene *pb_transport.Scene, textures map[string]*texture.ImageTxt, displacementMaps map[string]*texture.ImageTxt, volumeFiles map[string][]byte, triangles []*pb_transport.Triangle) (*AssetProvider, string, error) {
 	// If no port is specified, gRPC will pick a random available port.
 	// We listen on "0.0.0.0:0" to bind to all available interfaces on a random port.
 	lis, err := net.Listen("tcp", "0.0.0.0:0")
@@ -64,6 +65,7 @@ func New(scene *pb_transport.Scene, textures map[string]*texture.ImageTxt, displ
 		scene:            scene,
 		textures:         textures,
 		displacementMaps: displacementMaps,
+		volumeFiles:      volumeFiles,
 		triangles:        triangles,
 	}
 
@@ -197,6 +199,59 @@ func (s *assetProviderServer) StreamTextureFile(req *pb_transport.StreamTextureF
 	return nil
 }
 
+// StreamVolumeFile implements pb_transport.SceneTransportServiceServer.
+// It streams the contents of a volume grid file in chunks.
+func (s *assetProviderServer) StreamVolumeFile(req *pb_transport.StreamVolumeFileRequest, stream pb_transport.SceneTransportService_StreamVolumeFileServer) error {
+	log.Infof("AssetProvider: StreamVolumeFile called for '%s' (offset: %d, chunk_size: %d)",
+		req.GetFilename(), req.GetOffset(), req.GetChunkSize())
+
+	byteData, ok := s.volumeFiles[req.GetFilename()]
+	if !ok {
+		return status.Errorf(codes.NotFound, "volume file '%s' not found", req.GetFilename())
+	}
+	totalSize := uint64(len(byteData))
+
+	// Ensure offset is within bounds
+	if req.GetOffset() >= totalSize && totalSize > 0 {
+		return status.Errorf(codes.OutOfRange, "offset %d is out of range for volume file '%s' (total size %d)", req.GetOffset(), req.GetFilename(), totalSize)
+	}
+
+	// Calculate effective chunk size (use request's chunk_size or default)
+	chunkSize := req.GetChunkSize()
+	if chunkSize == 0 {
+		chunkSize = DefaultTextureChunkSize
+	}
+
+	// Start streaming from the requested offset
+	currentOffset := req.GetOffset()
+	for currentOffset < totalSize {
+		select {
+		case <-stream.Context().Done():
+			log.Warnf("AssetProvider: StreamVolumeFile for '%s' cancelled by client.", req.GetFilename())
+			return stream.Context().Err()
+		default:
+			// Continue
+		}
+
+		endOffset := min(currentOffset+uint64(chunkSize), totalSize)
+
+		resp := &pb_transport.StreamVolumeFileResponse{
+			Chunk: byteData[currentOffset:endOffset],
+			Size:  totalSize,
+		}
+
+		if err := stream.Send(resp); err != nil {
+			log.Errorf("AssetProvider: Failed to send volume chunk for '%s': %v", req.GetFilename(), err)
+			return fmt.Errorf("failed to send volume chunk: %w", err)
+		}
+		currentOffset = endOffset
+	}
+
+	log.Infof("AssetProvider: Finished streaming volume file '%s'", req.GetFilename())
+
+	return nil
+}
+
 // StreamTriangles implements pb_transport.SceneTransportServiceServer.
 // It streams triangle data in batches.
 func (s *assetProviderServer) StreamTriangles(req *pb_transport.StreamTrianglesRequest, stream pb_transport.SceneTransportService_StreamTrianglesServer) error {
@@ -11,13 +11,14 @@ import (
 
 // Camera represents a camera in the world.
 type Camera struct {
-	random          *fastrandom.LCG
 	lensRadius      float64
 	time0           float64
 	time1           float64
 	exposure        float64
+	focusDist       float64
 	u               vec3.Vec3Impl
 	v               vec3.Vec3Impl
+	w               vec3.Vec3Impl
 	origin          vec3.Vec3Impl
 	lowerLeftCorner vec3.Vec3Impl
 	horizontal      vec3.Vec3Impl
@@ -43,13 +44,14 @@ func New(lookFrom vec3.Vec3Impl, lookAt vec3.Vec3Impl, vup vec3.Vec3Impl,
 	origin := lookFrom
 
 	return &Camera{
-		random:          fastrandom.NewWithDefaults(),
 		lensRadius:      lensRadius,
 		time0:           time0,
 		time1:           time1,
 		u:               u,
 		v:               v,
+		w:               w,
 		exposure:        exposure,
+		focusDist:       focusDist,
 		lowerLeftCorner: lowerLeftCorner,
 		horizontal:      horizontal,
 		vertical:        vertical,
@@ -57,38 +59,116 @@ func New(lookFrom vec3.Vec3Impl, lookAt vec3.Vec3Impl, vup vec3.Vec3Impl,
 	}
 }
 
-// GetRay returns the ray associated for the supplied u and v.
-func (c *Camera) GetRay(s float64, t float64) *ray.RayImpl {
-	rd := vec3.ScalarMul(c.randomInUnitDisc(), c.lensRadius)
+// GetRaySampled returns the ray associated for the supplied s and t with a specific wavelength, choosing the point
+// on the lens and the time with random. Every call takes exactly three numbers from random, so that the ray changes
+// smoothly with them. A lambda of 0 leaves the wavelength unset.
+func (c *Camera) GetRaySampled(s float64, t float64, lambda float64, random *fastrandom.LCG) *ray.RayImpl {
+	// Map two uniform numbers to the unit disc while preserving area.
+	radius := math.Sqrt(random.Float64())
+	phi := 2.0 * math.Pi * random.Float64()
+	rd := vec3.Vec3Impl{X: radius * math.Cos(phi) * c.lensRadius, Y: radius * math.Sin(phi) * c.lensRadius}
 	offset := vec3.Add(vec3.ScalarMul(c.u, rd.X), vec3.ScalarMul(c.v, rd.Y))
-	time := c.time0 + c.random.Float64()*(c.time1-c.time0)
-	return ray.New(vec3.Add(c.origin, offset),
-		// lowerLeftCorner + s*horizontal + t*vertical - origin - offset
-		vec3.Sub(vec3.Add(c.lowerLeftCorner, vec3.ScalarMul(c.horizontal, s),
-			vec3.ScalarMul(c.vertical, t)), c.origin, offset), time)
-}
-
-// GetRayWithLambda returns the ray associated for the supplied u and v with a specific wavelength.
-func (c *Camera) GetRayWithLambda(s float64, t float64, lambda float64) *ray.RayImpl {
-	rd := vec3.ScalarMul(c.randomInUnitDisc(), c.lensRadius)
-	offset := vec3.Add(vec3.ScalarMul(c.u, rd.X), vec3.ScalarMul(c.v, rd.Y))
-	time := c.time0 + c.random.Float64()*(c.time1-c.time0)
+	time := c.time0 + random.Float64()*(c.time1-c.time0)
 	return ray.NewWithLambda(vec3.Add(c.origin, offset),
-		// lowerLeftCorner + s*horizontal + t*vertical - origin - offset
 		vec3.Sub(vec3.Add(c.lowerLeftCorner, vec3.ScalarMul(c.horizontal, s),
 			vec3.ScalarMul(c.vertical, t)), c.origin, offset), time, lambda)
 }
 
-func (c *Camera) randomInUnitDisc() vec3.Vec3Impl {
+// Exposure returns the exposure value associated with this camera.
+func (c *Camera) Exposure() float64 {
+	return c.exposure
+}
+
+// Importance describes how a point in the scene is seen through the camera.
+type Importance struct {
+	// S and T are the film coordinates of the point, as passed to GetRaySampled.
+	S float64
+	T float64
+	// Lens is the point chosen on the lens.
+	Lens vec3.Vec3Impl
+	// We is the importance emitted from the lens point towards the scene point.
+	We float64
+	// PDF is the density of choosing the lens point with respect to solid angle as seen from the scene point.
+	PDF float64
+}
+
+// SampleImportance chooses a point on the lens and returns how the scene point p is seen from it.
+// It returns false if p is not within the field of view.
+func (c *Camera) SampleImportance(p vec3.Vec3Impl, random *fastrandom.LCG) (Importance, bool) {
+	var rd vec3.Vec3Impl
 	for {
-		p := vec3.Sub(vec3.ScalarMul(vec3.Vec3Impl{X: c.random.Float64(), Y: c.random.Float64()}, 2.0), vec3.Vec3Impl{X: 1.0, Y: 1.0})
-		if vec3.Dot(p, p) < 1.0 {
-			return p
+		rd = vec3.Vec3Impl{X: 2.0*random.Float64() - 1.0, Y: 2.0*random.Float64() - 1.0}
+		if vec3.Dot(rd, rd) < 1.0 {
+			break
 		}
 	}
+
+	rd = vec3.ScalarMul(rd, c.lensRadius)
+	lens := vec3.Add(c.origin, vec3.ScalarMul(c.u, rd.X), vec3.ScalarMul(c.v, rd.Y))
+
+	dir := vec3.Sub(p, lens)
+	s, t, cosTheta, ok := c.film(lens, dir)
+	if !ok {
+		return Importance{}, false
+	}
+
+	return Importance{
+		S:    s,
+		T:    t,
+		Lens: lens,
+		We:   c.importance(cosTheta),
+		PDF:  dir.SquaredLength() / (cosTheta * c.lensArea()),
+	}, true
 }
 
-// Exposure returns the exposure value associated with this camera.
-func (c *Camera) Exposure() float64 {
-	return c.exposure
+// PDF returns the densities with which GetRaySampled chooses the origin of r on the lens, with respect to area,
+// and its direction, with respect to solid angle. Both are zero if r does not go through the film.
+func (c *Camera) PDF(r ray.Ray) (float64, float64) {
+	_, _, cosTheta, ok := c.film(r.Origin(), r.Direction())
+	if !ok {
+		return 0, 0
+	}
+
+	return 1.0 / c.lensArea(), 1.0 / (c.filmArea() * cosTheta * cosTheta * cosTheta)
+}
+
+// film returns the film coordinates of the ray leaving the lens point lens in the given direction,
+// along with the cosine of the angle between the ray and the viewing direction.
+func (c *Camera) film(lens vec3.Vec3Impl, dir vec3.Vec3Impl) (float64, float64, float64, bool) {
+	cosTheta := -vec3.Dot(vec3.UnitVector(dir), c.w)
+	if cosTheta <= 0 {
+		return 0, 0, 0, false
+	}
+
+	// Rays from any point on the lens converge on the focus plane, where the film is placed.
+	focus := vec3.Add(lens, vec3.ScalarMul(dir, c.focusDist/-vec3.Dot(dir, c.w)))
+	d := vec3.Sub(focus, c.lowerLeftCorner)
+	s := vec3.Dot(d, c.horizontal) / c.horizontal.SquaredLength()
+	t := vec3.Dot(d, c.vertical) / c.vertical.SquaredLength()
+	if s < 0 || s >= 1 || t < 0 || t >= 1 {
+		return 0, 0, 0, false
+	}
+
+	return s, t, cosTheta, true
+}
+
+// importance returns the importance emitted along a ray at an angle to the viewing direction with the given cosine.
+// It is normalised so that integrating it over the lens and the film, seen from the lens, gives 1.
+func (c *Camera) importance(cosTheta float64) float64 {
+	cos2 := cosTheta * cosTheta
+	return 1.0 / (c.filmArea() * c.lensArea() * cos2 * cos2)
+}
+
+// filmArea returns the area of the film scaled to a distance of 1 from the lens.
+func (c *Camera) filmArea() float64 {
+	return c.horizontal.Length() * c.vertical.Length() / (c.focusDist * c.focusDist)
+}
+
+// lensArea returns the area of the lens, or 1 for a pinhole camera.
+func (c *Camera) lensArea() float64 {
+	if c.lensRadius == 0 {
+		return 1.0
+	}
+
+	return math.Pi * c.lensRadius * c.lensRadius
 }
@@ -0,0 +1,207 @@
+// Package checkpoint implements saving and restoring the state of a render in progress.
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/flynn-nrg/izpi/internal/aov"
+	"google.golang.org/protobuf/proto"
+
+	pb_checkpoint "github.com/flynn-nrg/izpi/internal/proto/checkpoint"
+)
+
+// formatVersion is the version of the checkpoint file format.
+const formatVersion = 1
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported checkpoint version")
+	ErrMismatch           = errors.New("checkpoint does not match the render settings")
+)
+
+// State is a snapshot of a render in progress.
+type State struct {
+	// Width and Height are the image dimensions.
+	Width  int
+	Height int
+	// Samples is the number of samples per pixel requested for the whole render.
+	Samples int
+	// PassSamples is the number of samples per pixel in every progressive pass, or 0 if the render is not progressive.
+	PassSamples int
+	// SamplerType identifies the sampler used to render the image.
+	SamplerType int
+	// Adaptive is true if adaptive sampling is enabled.
+	Adaptive bool
+	// Pass is the number of passes that have been completed.
+	Pass int
+	// CompletedTiles holds the completion state of every tile in the current pass, in rendering order.
+	CompletedTiles []bool
+	// Pixels is the accumulated canvas, stored row by row as RGBA quadruplets.
+	Pixels []float64
+	// SampleCounts is the number of samples accumulated for every pixel, stored row by row.
+	SampleCounts []int
+	// AOVs holds the names of the AOVs being rendered.
+	AOVs []string
+	// LightGroups holds the names of the light groups being rendered.
+	LightGroups []string
+	// AOVPixels holds the accumulated AOV values, stored row by row with the channels of every AOV and
+	// light group in order.
+	AOVPixels []float64
+	// LightImage holds the light carried to the camera by light paths, stored row by row as RGB triplets.
+	LightImage []float64
+	// LightPaths is the number of light paths accumulated into LightImage.
+	LightPaths uint64
+	// Filter identifies the reconstruction filter that samples are splatted with, or is 0 if the samples of every
+	// pixel are averaged on their own.
+	Filter int
+	// FilterRadius is the radius of the reconstruction filter in pixels.
+	FilterRadius float64
+	// Film holds the samples weighted by the reconstruction filter, stored row by row as the weighted sums of the
+	// three channels followed by the sum of the weights.
+	Film []float64
+}
+
+// Matches returns an error if the render settings of both states differ.
+func (s *State) Matches(other *State) error {
+	switch {
+	case s.Width != other.Width || s.Height != other.Height:
+		return fmt.Errorf("%w: image size is %vx%v, want %vx%v", ErrMismatch, s.Width, s.Height, other.Width, other.Height)
+	case s.Samples != other.Samples:
+		return fmt.Errorf("%w: %v samples per pixel, want %v", ErrMismatch, s.Samples, other.Samples)
+	case s.PassSamples != other.PassSamples:
+		return fmt.Errorf("%w: %v samples per pass, want %v", ErrMismatch, s.PassSamples, other.PassSamples)
+	case s.SamplerType != other.SamplerType:
+		return fmt.Errorf("%w: different sampler type", ErrMismatch)
+	case s.Adaptive != other.Adaptive:
+		return fmt.Errorf("%w: adaptive sampling is %v, want %v", ErrMismatch, s.Adaptive, other.Adaptive)
+	case !slices.Equal(s.AOVs, other.AOVs):
+		return fmt.Errorf("%w: AOVs are %v, want %v", ErrMismatch, s.AOVs, other.AOVs)
+	case !slices.Equal(s.LightGroups, other.LightGroups):
+		return fmt.Errorf("%w: light groups are %v, want %v", ErrMismatch, s.LightGroups, other.LightGroups)
+	case s.Filter != other.Filter || s.FilterRadius != other.FilterRadius:
+		return fmt.Errorf("%w: different reconstruction filter", ErrMismatch)
+	}
+
+	return nil
+}
+
+// Save writes the state to fileName.
+// The file is replaced atomically so that a crash while saving never leaves a corrupt checkpoint behind.
+func Save(fileName string, s *State) error {
+	sampleCounts := make([]uint32, len(s.SampleCounts))
+	for i, n := range s.SampleCounts {
+		sampleCounts[i] = uint32(n)
+	}
+
+	payload, err := proto.Marshal(&pb_checkpoint.Checkpoint{
+		Version:         formatVersion,
+		Width:           uint32(s.Width),
+		Height:          uint32(s.Height),
+		SamplesPerPixel: uint32(s.Samples),
+		PassSamples:     uint32(s.PassSamples),
+		SamplerType:     uint32(s.SamplerType),
+		Adaptive:        s.Adaptive,
+		Pass:            uint32(s.Pass),
+		CompletedTiles:  s.CompletedTiles,
+		Pixels:          s.Pixels,
+		SampleCounts:    sampleCounts,
+		Aovs:            s.AOVs,
+		LightGroups:     s.LightGroups,
+		AovPixels:       s.AOVPixels,
+		LightImage:      s.LightImage,
+		LightPaths:      s.LightPaths,
+		Filter:          uint32(s.Filter),
+		FilterRadius:    s.FilterRadius,
+		Film:            s.Film,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tmpFileName := fileName + ".tmp"
+	if err := os.WriteFile(tmpFileName, payload, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmpFileName, fileName); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a state previously written with Save.
+func Load(fileName string) (*State, error) {
+	payload, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	cp := &pb_checkpoint.Checkpoint{}
+	if err := proto.Unmarshal(payload, cp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+
+	if cp.GetVersion() != formatVersion {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedVersion, cp.GetVersion())
+	}
+
+	width := int(cp.GetWidth())
+	height := int(cp.GetHeight())
+	if len(cp.GetPixels()) != width*height*4 {
+		return nil, fmt.Errorf("checkpoint holds %v pixel values, want %v", len(cp.GetPixels()), width*height*4)
+	}
+
+	var sampleCounts []int
+	if counts := cp.GetSampleCounts(); len(counts) > 0 {
+		if len(counts) != width*height {
+			return nil, fmt.Errorf("checkpoint holds %v sample counts, want %v", len(counts), width*height)
+		}
+
+		sampleCounts = make([]int, len(counts))
+		for i, n := range counts {
+			sampleCounts[i] = int(n)
+		}
+	}
+
+	aovTypes, err := aov.Parse(cp.GetAovs())
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+
+	layout := aov.Layout{Types: aovTypes, LightGroups: cp.GetLightGroups()}
+	if want := width * height * layout.Stride(); len(cp.GetAovPixels()) != want {
+		return nil, fmt.Errorf("checkpoint holds %v AOV values, want %v", len(cp.GetAovPixels()), want)
+	}
+
+	if n := len(cp.GetLightImage()); n > 0 && n != width*height*3 {
+		return nil, fmt.Errorf("checkpoint holds %v light image values, want %v", n, width*height*3)
+	}
+
+	if n := len(cp.GetFilm()); n > 0 && n != width*height*4 {
+		return nil, fmt.Errorf("checkpoint holds %v film values, want %v", n, width*height*4)
+	}
+
+	return &State{
+		Width:          width,
+		Height:         height,
+		Samples:        int(cp.GetSamplesPerPixel()),
+		PassSamples:    int(cp.GetPassSamples()),
+		SamplerType:    int(cp.GetSamplerType()),
+		Adaptive:       cp.GetAdaptive(),
+		Pass:           int(cp.GetPass()),
+		CompletedTiles: cp.GetCompletedTiles(),
+		Pixels:         cp.GetPixels(),
+		SampleCounts:   sampleCounts,
+		AOVs:           cp.GetAovs(),
+		LightGroups:    cp.GetLightGroups(),
+		AOVPixels:      cp.GetAovPixels(),
+		LightImage:     cp.GetLightImage(),
+		LightPaths:     cp.GetLightPaths(),
+		Filter:         int(cp.GetFilter()),
+		FilterRadius:   cp.GetFilterRadius(),
+		Film:           cp.GetFilm(),
+	}, nil
+}
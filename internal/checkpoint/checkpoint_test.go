@@ -0,0 +1,121 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSaveLoad(t *testing.T) {
+	want := &State{
+		Width:          2,
+		Height:         1,
+		Samples:        64,
+		PassSamples:    16,
+		SamplerType:    2,
+		Pass:           3,
+		CompletedTiles: []bool{true, false, true},
+		Pixels:         []float64{0.1, 0.2, 0.3, 1.0, 0.4, 0.5, 0.6, 1.0},
+		SampleCounts:   []int{48, 64},
+		AOVs:           []string{"depth", "uv"},
+		LightGroups:    []string{"Key"},
+		AOVPixels:      []float64{1.5, 0.1, 0.2, 1, 2, 3, 2.5, 0.3, 0.4, 4, 5, 6},
+		LightImage:     []float64{0.01, 0.02, 0.03, 0, 0, 0},
+		LightPaths:     128,
+		Filter:         4,
+		FilterRadius:   2,
+		Film:           []float64{0.2, 0.4, 0.6, 2, 0.8, 1.0, 1.2, 2},
+	}
+
+	fileName := filepath.Join(t.TempDir(), "render.ckpt")
+	if err := Save(fileName, want); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	if _, err := os.Stat(fileName + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary checkpoint file was not removed")
+	}
+
+	got, err := Load(fileName)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadInvalidPixels(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "render.ckpt")
+	if err := Save(fileName, &State{Width: 2, Height: 2, Pixels: make([]float64, 4)}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	if _, err := Load(fileName); err == nil {
+		t.Errorf("Load() succeeded with a truncated canvas")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	base := State{Width: 100, Height: 50, Samples: 64, PassSamples: 16, SamplerType: 2}
+
+	testData := []struct {
+		name   string
+		modify func(s *State)
+		want   error
+	}{
+		{
+			name:   "same settings",
+			modify: func(s *State) {},
+		},
+		{
+			name:   "different size",
+			modify: func(s *State) { s.Width = 200 },
+			want:   ErrMismatch,
+		},
+		{
+			name:   "different samples",
+			modify: func(s *State) { s.Samples = 128 },
+			want:   ErrMismatch,
+		},
+		{
+			name:   "adaptive",
+			modify: func(s *State) { s.Adaptive = true },
+			want:   ErrMismatch,
+		},
+		{
+			name:   "different AOVs",
+			modify: func(s *State) { s.AOVs = []string{"albedo"} },
+			want:   ErrMismatch,
+		},
+		{
+			name:   "different light groups",
+			modify: func(s *State) { s.LightGroups = []string{"Fill"} },
+			want:   ErrMismatch,
+		},
+		{
+			name:   "different filter",
+			modify: func(s *State) { s.Filter = 2 },
+			want:   ErrMismatch,
+		},
+		{
+			name:   "different filter radius",
+			modify: func(s *State) { s.FilterRadius = 1.5 },
+			want:   ErrMismatch,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			other := base
+			test.modify(&other)
+			if err := base.Matches(&other); !errors.Is(err, test.want) {
+				t.Errorf("Matches() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
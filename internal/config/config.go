@@ -1,17 +1,46 @@
 package config
 
 type Config struct {
-	Scene            string
-	NumWorkers       int64
-	XSize            int64
-	YSize            int64
-	Samples          int64
-	Sampler          string
-	Depth            int64
-	OutputMode       string
-	OutputFile       string
-	Verbose          bool
-	Preview          bool
-	DisplayMode      string
-	DiscoveryTimeout int64
+	Scene                string
+	NumWorkers           int64
+	XSize                int64
+	YSize                int64
+	Samples              int64
+	Sampler              string
+	Depth                int64
+	MaxDiffuseDepth      int64
+	MaxSpecularDepth     int64
+	MaxTransmissionDepth int64
+	MaxVolumeDepth       int64
+	RussianRouletteDepth int64
+	OutputMode           string
+	OutputFile           string
+	Verbose              bool
+	Preview              bool
+	DisplayMode          string
+	DiscoveryTimeout     int64
+	Adaptive             bool
+	MinSamples           int64
+	NoiseThreshold       float64
+	SampleHeatmap        string
+	Progressive          bool
+	PassSamples          int64
+	Checkpoint           string
+	CheckpointInterval   int64
+	Resume               bool
+	TimeLimit            int64
+	TargetNoise          float64
+	AOVs                 []string
+	LightGroups          []string
+	Denoise              bool
+	DenoiseIterations    int64
+	MLTBootstrapSamples  int64
+	MLTLargeStepProb     float64
+	Photons              int64
+	PhotonRadius         float64
+	PhotonAlpha          float64
+	SampleGenerator      string
+	Seed                 uint64
+	Filter               string
+	FilterRadius         float64
 }
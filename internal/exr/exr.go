@@ -0,0 +1,366 @@
+// Package exr implements a writer for multi-part OpenEXR files with 32 bit float scanline images.
+package exr
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	magic = 20000630
+
+	// File format version and flags.
+	version       = 2
+	flagLongNames = 0x400
+	flagMultiPart = 0x1000
+
+	// Channel pixel types.
+	pixelTypeFloat = 2
+
+	// Compression methods.
+	compressionZIP = 3
+
+	// Number of scanlines per chunk with ZIP compression.
+	linesPerChunk = 16
+
+	// Names longer than this require the long names flag.
+	maxShortName = 31
+)
+
+var (
+	ErrNoParts          = errors.New("no parts to write")
+	ErrInvalidSize      = errors.New("invalid image size")
+	ErrInvalidChannel   = errors.New("invalid channel")
+	ErrDuplicateChannel = errors.New("duplicate channel name")
+)
+
+// Chromaticities holds the CIE xy coordinates of the primaries and white point of an RGB colour space.
+type Chromaticities struct {
+	RedX, RedY     float32
+	GreenX, GreenY float32
+	BlueX, BlueY   float32
+	WhiteX, WhiteY float32
+}
+
+// Rec709 are the chromaticities of the Rec. 709 primaries with a D65 white point, which OpenEXR assumes when
+// a file does not specify any.
+var Rec709 = Chromaticities{
+	RedX: 0.64, RedY: 0.33,
+	GreenX: 0.30, GreenY: 0.60,
+	BlueX: 0.15, BlueY: 0.06,
+	WhiteX: 0.3127, WhiteY: 0.3290,
+}
+
+// ACEScg are the chromaticities of the ACES AP1 primaries with the ACES white point.
+var ACEScg = Chromaticities{
+	RedX: 0.713, RedY: 0.293,
+	GreenX: 0.165, GreenY: 0.830,
+	BlueX: 0.128, BlueY: 0.044,
+	WhiteX: 0.32168, WhiteY: 0.33767,
+}
+
+// Channel is a single channel of an image.
+type Channel struct {
+	// Name is the full name of the channel, including its layer prefix if any, e.g. "albedo.R".
+	Name string
+	// Pixels holds the value of every pixel, stored row by row.
+	Pixels []float32
+}
+
+// Part is an image stored in a multi-part file.
+type Part struct {
+	// Name identifies the part within the file.
+	Name string
+	// Channels holds the channels of the image in any order.
+	Channels []Channel
+	// Attributes holds additional string attributes written to the header of the part.
+	Attributes map[string]string
+}
+
+// Image describes the contents of a file. Every part has the same size and chromaticities.
+type Image struct {
+	Width  int
+	Height int
+	// Chromaticities describes the colour space of the RGB channels of every part. It is not written if nil.
+	Chromaticities *Chromaticities
+	Parts          []Part
+}
+
+// WriteFile writes img to fileName.
+func WriteFile(fileName string, img *Image) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err := Write(w, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Write writes img to w as an OpenEXR file with ZIP compression.
+// A single part is written as a regular scanline file, more than one part as a multi-part file.
+func Write(w io.Writer, img *Image) error {
+	if len(img.Parts) == 0 {
+		return ErrNoParts
+	}
+
+	if img.Width <= 0 || img.Height <= 0 {
+		return fmt.Errorf("%w: %vx%v", ErrInvalidSize, img.Width, img.Height)
+	}
+
+	multiPart := len(img.Parts) > 1
+	numChunks := (img.Height + linesPerChunk - 1) / linesPerChunk
+
+	parts := make([]Part, len(img.Parts))
+	seen := make(map[string]bool)
+	longNames := false
+	for i, p := range img.Parts {
+		channels := slices.Clone(p.Channels)
+		// Channels are stored in alphabetical order.
+		slices.SortFunc(channels, func(a, b Channel) int { return cmp.Compare(a.Name, b.Name) })
+
+		for _, c := range channels {
+			if c.Name == "" || len(c.Pixels) != img.Width*img.Height {
+				return fmt.Errorf("%w: %q in part %q", ErrInvalidChannel, c.Name, p.Name)
+			}
+
+			if seen[c.Name] {
+				return fmt.Errorf("%w: %q", ErrDuplicateChannel, c.Name)
+			}
+			seen[c.Name] = true
+
+			longNames = longNames || len(c.Name) > maxShortName
+		}
+
+		for name := range p.Attributes {
+			longNames = longNames || len(name) > maxShortName
+		}
+
+		parts[i] = Part{Name: p.Name, Channels: channels, Attributes: p.Attributes}
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(magic))
+
+	flags := uint32(version)
+	if longNames {
+		flags |= flagLongNames
+	}
+	if multiPart {
+		flags |= flagMultiPart
+	}
+	binary.Write(&buf, binary.LittleEndian, flags)
+
+	for _, p := range parts {
+		writeHeader(&buf, img, p, multiPart, numChunks)
+	}
+
+	if multiPart {
+		// An empty header terminates the list of headers.
+		buf.WriteByte(0)
+	}
+
+	// The chunks follow the offset tables of every part.
+	chunks := make([][][]byte, len(parts))
+	offset := uint64(buf.Len() + len(parts)*numChunks*8)
+	for i, p := range parts {
+		chunks[i] = make([][]byte, numChunks)
+		for c := range numChunks {
+			chunk, err := encodeChunk(img, p, i, c, multiPart)
+			if err != nil {
+				return err
+			}
+
+			binary.Write(&buf, binary.LittleEndian, offset)
+			offset += uint64(len(chunk))
+			chunks[i][c] = chunk
+		}
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	for _, part := range chunks {
+		for _, chunk := range part {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeHeader writes the header attributes of a part.
+func writeHeader(buf *bytes.Buffer, img *Image, p Part, multiPart bool, numChunks int) {
+	var channels bytes.Buffer
+	for _, c := range p.Channels {
+		channels.WriteString(c.Name)
+		channels.WriteByte(0)
+		binary.Write(&channels, binary.LittleEndian, int32(pixelTypeFloat))
+		// pLinear and reserved bytes.
+		channels.Write([]byte{0, 0, 0, 0})
+		// x and y sampling.
+		binary.Write(&channels, binary.LittleEndian, [2]int32{1, 1})
+	}
+	channels.WriteByte(0)
+
+	window := [4]int32{0, 0, int32(img.Width - 1), int32(img.Height - 1)}
+
+	writeAttribute(buf, "channels", "chlist", channels.Bytes())
+	writeAttribute(buf, "compression", "compression", []byte{compressionZIP})
+	writeAttribute(buf, "dataWindow", "box2i", encode(window))
+	writeAttribute(buf, "displayWindow", "box2i", encode(window))
+	// Increasing Y.
+	writeAttribute(buf, "lineOrder", "lineOrder", []byte{0})
+	writeAttribute(buf, "pixelAspectRatio", "float", encode(float32(1)))
+	writeAttribute(buf, "screenWindowCenter", "v2f", encode([2]float32{0, 0}))
+	writeAttribute(buf, "screenWindowWidth", "float", encode(float32(1)))
+
+	if img.Chromaticities != nil {
+		writeAttribute(buf, "chromaticities", "chromaticities", encode(*img.Chromaticities))
+	}
+
+	if multiPart {
+		writeAttribute(buf, "name", "string", []byte(p.Name))
+		writeAttribute(buf, "type", "string", []byte("scanlineimage"))
+		writeAttribute(buf, "chunkCount", "int", encode(int32(numChunks)))
+	}
+
+	names := make([]string, 0, len(p.Attributes))
+	for name := range p.Attributes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		writeAttribute(buf, name, "string", []byte(p.Attributes[name]))
+	}
+
+	// A null byte terminates the header.
+	buf.WriteByte(0)
+}
+
+// writeAttribute writes a header attribute.
+func writeAttribute(buf *bytes.Buffer, name string, typeName string, value []byte) {
+	buf.WriteString(name)
+	buf.WriteByte(0)
+	buf.WriteString(typeName)
+	buf.WriteByte(0)
+	binary.Write(buf, binary.LittleEndian, int32(len(value)))
+	buf.Write(value)
+}
+
+// encode returns the little endian encoding of v.
+func encode(v any) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, v)
+
+	return buf.Bytes()
+}
+
+// encodeChunk returns chunk c of part p, including its chunk header.
+func encodeChunk(img *Image, p Part, partNumber int, c int, multiPart bool) ([]byte, error) {
+	y0 := c * linesPerChunk
+	y1 := min(y0+linesPerChunk, img.Height)
+
+	// Scanlines store every channel in turn.
+	raw := make([]byte, 0, (y1-y0)*img.Width*len(p.Channels)*4)
+	for y := y0; y < y1; y++ {
+		for _, ch := range p.Channels {
+			for _, v := range ch.Pixels[y*img.Width : (y+1)*img.Width] {
+				raw = binary.LittleEndian.AppendUint32(raw, math.Float32bits(v))
+			}
+		}
+	}
+
+	data, err := compress(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunk bytes.Buffer
+	if multiPart {
+		binary.Write(&chunk, binary.LittleEndian, int32(partNumber))
+	}
+	binary.Write(&chunk, binary.LittleEndian, int32(y0))
+	binary.Write(&chunk, binary.LittleEndian, int32(len(data)))
+	chunk.Write(data)
+
+	return chunk.Bytes(), nil
+}
+
+// compress applies OpenEXR ZIP compression to raw. The data is returned uncompressed if compression
+// does not make it smaller, which readers detect by comparing its size to the size of the scanlines.
+func compress(raw []byte) ([]byte, error) {
+	n := len(raw)
+	tmp := make([]byte, n)
+
+	// Split the bytes of every value into two halves so that similar bytes end up together.
+	half := (n + 1) / 2
+	for i := range n {
+		if i%2 == 0 {
+			tmp[i/2] = raw[i]
+		} else {
+			tmp[half+i/2] = raw[i]
+		}
+	}
+
+	// Store the difference between consecutive bytes.
+	if n > 0 {
+		p := tmp[0]
+		for i := 1; i < n; i++ {
+			d := int(tmp[i]) - int(p) + 128 + 256
+			p = tmp[i]
+			tmp[i] = byte(d)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(tmp); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	if buf.Len() >= n {
+		return raw, nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SanitiseName returns name with the characters that have special meaning in channel names replaced,
+// so that it can be used as a layer name.
+func SanitiseName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == ' ' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,289 @@
+package exr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// readFile decodes the parts written by Write. It returns the version flags, the string attributes of
+// every part and the pixels of every channel keyed by channel name.
+func readFile(t *testing.T, data []byte, width int, height int) (uint32, []map[string]string, map[string][]float32) {
+	t.Helper()
+
+	r := bytes.NewReader(data)
+	var m, flags uint32
+	binary.Read(r, binary.LittleEndian, &m)
+	binary.Read(r, binary.LittleEndian, &flags)
+	if m != magic {
+		t.Fatalf("magic number = %v, want %v", m, magic)
+	}
+
+	readString := func() string {
+		var b []byte
+		for {
+			c, err := r.ReadByte()
+			if err != nil {
+				t.Fatalf("truncated header: %v", err)
+			}
+			if c == 0 {
+				return string(b)
+			}
+			b = append(b, c)
+		}
+	}
+
+	multiPart := flags&flagMultiPart != 0
+	var attributes []map[string]string
+	var channels [][]string
+	for {
+		attrs := make(map[string]string)
+		var names []string
+		for {
+			name := readString()
+			if name == "" {
+				break
+			}
+
+			typeName := readString()
+			var size int32
+			binary.Read(r, binary.LittleEndian, &size)
+			value := make([]byte, size)
+			io.ReadFull(r, value)
+
+			switch typeName {
+			case "string":
+				attrs[name] = string(value)
+			case "chlist":
+				for len(value) > 1 {
+					end := bytes.IndexByte(value, 0)
+					names = append(names, string(value[:end]))
+					value = value[end+17:]
+				}
+			case "chromaticities":
+				attrs[name] = "set"
+			}
+		}
+
+		attributes = append(attributes, attrs)
+		channels = append(channels, names)
+
+		if !multiPart {
+			break
+		}
+
+		// An empty header ends the list.
+		if c, _ := r.ReadByte(); c == 0 {
+			break
+		}
+		r.UnreadByte()
+	}
+
+	numChunks := (height + linesPerChunk - 1) / linesPerChunk
+	offsets := make([]uint64, len(channels)*numChunks)
+	binary.Read(r, binary.LittleEndian, offsets)
+
+	pixels := make(map[string][]float32)
+	for part, names := range channels {
+		for _, name := range names {
+			pixels[name] = make([]float32, width*height)
+		}
+
+		for c := range numChunks {
+			chunk := bytes.NewReader(data[offsets[part*numChunks+c]:])
+			if multiPart {
+				var p int32
+				binary.Read(chunk, binary.LittleEndian, &p)
+				if int(p) != part {
+					t.Fatalf("chunk belongs to part %v, want %v", p, part)
+				}
+			}
+
+			var y0, size int32
+			binary.Read(chunk, binary.LittleEndian, &y0)
+			binary.Read(chunk, binary.LittleEndian, &size)
+			packed := make([]byte, size)
+			io.ReadFull(chunk, packed)
+
+			y1 := min(int(y0)+linesPerChunk, height)
+			raw := decompress(t, packed, (y1-int(y0))*width*len(names)*4)
+
+			for y := int(y0); y < y1; y++ {
+				for _, name := range names {
+					for x := range width {
+						pixels[name][y*width+x] = math.Float32frombits(binary.LittleEndian.Uint32(raw))
+						raw = raw[4:]
+					}
+				}
+			}
+		}
+	}
+
+	return flags, attributes, pixels
+}
+
+// decompress reverses compress.
+func decompress(t *testing.T, packed []byte, n int) []byte {
+	t.Helper()
+
+	if len(packed) == n {
+		return packed
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(packed))
+	if err != nil {
+		t.Fatalf("zlib.NewReader() = %v", err)
+	}
+
+	tmp, err := io.ReadAll(zr)
+	if err != nil || len(tmp) != n {
+		t.Fatalf("decompressed %v bytes (%v), want %v", len(tmp), err, n)
+	}
+
+	for i := 1; i < n; i++ {
+		tmp[i] = byte(int(tmp[i-1]) + int(tmp[i]) - 128)
+	}
+
+	raw := make([]byte, n)
+	half := (n + 1) / 2
+	for i := range n {
+		if i%2 == 0 {
+			raw[i] = tmp[i/2]
+		} else {
+			raw[i] = tmp[half+i/2]
+		}
+	}
+
+	return raw
+}
+
+func TestWrite(t *testing.T) {
+	const width, height = 3, 20
+
+	ramp := make([]float32, width*height)
+	constant := make([]float32, width*height)
+	for i := range ramp {
+		ramp[i] = float32(i) * 0.5
+		constant[i] = 1
+	}
+
+	testData := []struct {
+		name          string
+		img           *Image
+		wantMultiPart bool
+		wantAttrs     []map[string]string
+	}{
+		{
+			name: "single part",
+			img: &Image{
+				Width:  width,
+				Height: height,
+				Parts: []Part{
+					{Name: "beauty", Channels: []Channel{{Name: "R", Pixels: ramp}, {Name: "A", Pixels: constant}}},
+				},
+			},
+			wantAttrs: []map[string]string{{}},
+		},
+		{
+			name: "multi part",
+			img: &Image{
+				Width:          width,
+				Height:         height,
+				Chromaticities: &ACEScg,
+				Parts: []Part{
+					{
+						Name:       "beauty",
+						Channels:   []Channel{{Name: "G", Pixels: ramp}, {Name: "A", Pixels: constant}},
+						Attributes: map[string]string{"colorSpace": "ACEScg"},
+					},
+					{
+						Name:     "depth",
+						Channels: []Channel{{Name: "depth.Z", Pixels: ramp}},
+					},
+				},
+			},
+			wantMultiPart: true,
+			wantAttrs: []map[string]string{
+				{"name": "beauty", "type": "scanlineimage", "colorSpace": "ACEScg", "chromaticities": "set"},
+				{"name": "depth", "type": "scanlineimage", "chromaticities": "set"},
+			},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, test.img); err != nil {
+				t.Fatalf("Write() = %v", err)
+			}
+
+			flags, attrs, pixels := readFile(t, buf.Bytes(), width, height)
+			if got := flags&flagMultiPart != 0; got != test.wantMultiPart {
+				t.Errorf("multi-part flag = %v, want %v", got, test.wantMultiPart)
+			}
+
+			if diff := cmp.Diff(test.wantAttrs, attrs); diff != "" {
+				t.Errorf("attributes mismatch (-want +got):\n%s", diff)
+			}
+
+			want := make(map[string][]float32)
+			for _, p := range test.img.Parts {
+				for _, c := range p.Channels {
+					want[c.Name] = c.Pixels
+				}
+			}
+
+			if diff := cmp.Diff(want, pixels); diff != "" {
+				t.Errorf("pixels mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	pixels := make([]float32, 4)
+
+	testData := []struct {
+		name string
+		img  *Image
+		want error
+	}{
+		{
+			name: "no parts",
+			img:  &Image{Width: 2, Height: 2},
+			want: ErrNoParts,
+		},
+		{
+			name: "empty image",
+			img:  &Image{Parts: []Part{{Name: "beauty"}}},
+			want: ErrInvalidSize,
+		},
+		{
+			name: "wrong number of pixels",
+			img:  &Image{Width: 2, Height: 3, Parts: []Part{{Name: "beauty", Channels: []Channel{{Name: "R", Pixels: pixels}}}}},
+			want: ErrInvalidChannel,
+		},
+		{
+			name: "duplicate channel",
+			img: &Image{Width: 2, Height: 2, Parts: []Part{
+				{Name: "beauty", Channels: []Channel{{Name: "R", Pixels: pixels}}},
+				{Name: "other", Channels: []Channel{{Name: "R", Pixels: pixels}}},
+			}},
+			want: ErrDuplicateChannel,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Write(io.Discard, test.img); !errors.Is(err, test.want) {
+				t.Errorf("Write() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -10,11 +10,25 @@ const (
 	defaultC uint64 = 1013904223
 )
 
+// Source is a sequence of numbers in [0, 1) that can replace the generator of an LCG.
+type Source interface {
+	Float64() float64
+}
+
+// PixelSource is a Source whose numbers depend on the sample of a pixel that they are drawn for.
+type PixelSource interface {
+	Source
+	// StartPixelSample restarts the sequence at the first number of the given sample of the pixel (x, y).
+	StartPixelSample(x, y, index int)
+}
+
 type LCG struct {
 	state uint64
 	m     uint64
 	a     uint64
 	c     uint64
+	// source, if set, provides the numbers returned by Float64 instead of the generator.
+	source Source
 }
 
 func New(seed, m, a, c uint64) *LCG {
@@ -29,6 +43,11 @@ func New(seed, m, a, c uint64) *LCG {
 func NewWithDefaults() *LCG {
 	seed := rand.Uint64()
 
+	return NewWithSeed(seed)
+}
+
+// NewWithSeed returns a new generator with the default parameters and the given seed.
+func NewWithSeed(seed uint64) *LCG {
 	return &LCG{
 		state: seed,
 		m:     defaultM,
@@ -37,11 +56,46 @@ func NewWithDefaults() *LCG {
 	}
 }
 
+// NewWithSource returns an LCG whose numbers are taken from s. It lets code that expects an LCG consume
+// sequences such as the ones replayed and mutated by Metropolis samplers.
+func NewWithSource(s Source) *LCG {
+	return &LCG{source: s}
+}
+
 // Generate a random floating point number between 0 and 1.
 func (l *LCG) Float64() float64 {
+	if l.source != nil {
+		return l.source.Float64()
+	}
 
 	/* Update the LCG state using the formula Xn+1 = (A*Xn + C) mod M */
 	l.state = (l.a*l.state + l.c) % l.m
 	/* Convert the LCG state to a floating point number between 0 and 1 */
 	return float64(l.state) / float64(l.m)
 }
+
+// StartPixelSample starts drawing the numbers of the given sample of the pixel (x, y) if the numbers are taken from
+// a PixelSource, and does nothing otherwise.
+func (l *LCG) StartPixelSample(x, y, index int) {
+	if ps, ok := l.source.(PixelSource); ok {
+		ps.StartPixelSample(x, y, index)
+	}
+}
+
+// Hash mixes the given values into a well distributed seed using the SplitMix64 finaliser.
+func Hash(values ...uint64) uint64 {
+	var h uint64
+	for _, v := range values {
+		h += v + 0x9e3779b97f4a7c15
+		h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+		h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+		h ^= h >> 31
+	}
+
+	return h
+}
+
+// HashFloat64 returns a number in [0, 1) that is uniquely determined by the given values.
+func HashFloat64(values ...uint64) float64 {
+	return float64(Hash(values...)>>11) * 0x1p-53
+}
@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"image"
+	"math"
+	"slices"
+)
+
+// filmStride is the number of values stored for every pixel of a film: the weighted sum of each of the three
+// channels of the samples followed by the sum of their weights.
+const filmStride = 4
+
+// Film accumulates the samples that fall within the radius of a filter of the pixels of a region of an image.
+// The colour of every pixel is the weighted mean of those samples. Films are not safe for concurrent use.
+type Film struct {
+	filter Filter
+	bounds image.Rectangle
+	// values holds filmStride values for every pixel of bounds, stored row by row.
+	values []float64
+}
+
+// NewFilm returns a new empty film that covers the pixels within bounds.
+func NewFilm(f Filter, bounds image.Rectangle) *Film {
+	return &Film{
+		filter: f,
+		bounds: bounds,
+		values: make([]float64, bounds.Dx()*bounds.Dy()*filmStride),
+	}
+}
+
+// Bounds returns the pixels covered by the film.
+func (fm *Film) Bounds() image.Rectangle {
+	return fm.bounds
+}
+
+// AddSample weighs c with the filter and adds it to every pixel of the film within its radius. The position of the
+// sample is given in pixels, so that the centre of pixel (x, y) is at (x + 0.5, y + 0.5).
+// Adding samples to a nil film does nothing.
+func (fm *Film) AddSample(x, y float64, c [3]float64) {
+	if fm == nil {
+		return
+	}
+
+	for _, v := range c {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return
+		}
+	}
+
+	r := fm.filter.Radius()
+	x0 := max(fm.bounds.Min.X, int(math.Ceil(x-0.5-r)))
+	x1 := min(fm.bounds.Max.X-1, int(math.Floor(x-0.5+r)))
+	y0 := max(fm.bounds.Min.Y, int(math.Ceil(y-0.5-r)))
+	y1 := min(fm.bounds.Max.Y-1, int(math.Floor(y-0.5+r)))
+
+	for py := y0; py <= y1; py++ {
+		for px := x0; px <= x1; px++ {
+			w := fm.filter.Evaluate(float64(px)+0.5-x, float64(py)+0.5-y)
+			if w == 0 {
+				continue
+			}
+
+			v := fm.values[fm.offset(px, py):]
+			v[0] += w * c[0]
+			v[1] += w * c[1]
+			v[2] += w * c[2]
+			v[3] += w
+		}
+	}
+}
+
+// Pixel returns the weighted mean of the samples of the pixel (x, y). It returns false if the pixel is outside
+// the film or the weights of its samples do not add up to a positive value, which can happen with filters that
+// have negative lobes when only a few samples have been taken.
+func (fm *Film) Pixel(x, y int) ([3]float64, bool) {
+	if !(image.Point{X: x, Y: y}).In(fm.bounds) {
+		return [3]float64{}, false
+	}
+
+	v := fm.values[fm.offset(x, y):]
+	if v[3] <= 0 {
+		return [3]float64{}, false
+	}
+
+	return [3]float64{v[0] / v[3], v[1] / v[3], v[2] / v[3]}, true
+}
+
+// Data returns a copy of the values accumulated for every pixel of the film, stored row by row.
+func (fm *Film) Data() []float64 {
+	return slices.Clone(fm.values)
+}
+
+// Merge adds the values accumulated by a film that covers bounds, as returned by its Data method. Pixels outside
+// this film are ignored.
+func (fm *Film) Merge(bounds image.Rectangle, values []float64) {
+	if len(values) != bounds.Dx()*bounds.Dy()*filmStride {
+		return
+	}
+
+	r := bounds.Intersect(fm.bounds)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		src := values[((y-bounds.Min.Y)*bounds.Dx()+r.Min.X-bounds.Min.X)*filmStride:]
+		dst := fm.values[fm.offset(r.Min.X, y):]
+		for i := range r.Dx() * filmStride {
+			dst[i] += src[i]
+		}
+	}
+}
+
+// offset returns the index of the first value of the pixel (x, y).
+func (fm *Film) offset(x, y int) int {
+	return ((y-fm.bounds.Min.Y)*fm.bounds.Dx() + x - fm.bounds.Min.X) * filmStride
+}
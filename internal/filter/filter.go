@@ -0,0 +1,198 @@
+// Package filter implements pixel reconstruction filters and the film that samples are splatted to with them.
+package filter
+
+import (
+	"math"
+)
+
+type FilterType int
+
+const (
+	InvalidFilter FilterType = iota
+	BoxFilter
+	TentFilter
+	GaussianFilter
+	MitchellFilter
+	BlackmanHarrisFilter
+)
+
+var filterMap = map[string]FilterType{
+	"box":             BoxFilter,
+	"tent":            TentFilter,
+	"gaussian":        GaussianFilter,
+	"mitchell":        MitchellFilter,
+	"blackman-harris": BlackmanHarrisFilter,
+}
+
+// StringToType returns the filter type with the given name, or InvalidFilter if there is none.
+func StringToType(s string) FilterType {
+	return filterMap[s]
+}
+
+// Filter weighs the contribution of a sample to the pixels around it.
+type Filter interface {
+	// Radius returns the distance in pixels beyond which the filter is 0 along either axis.
+	Radius() float64
+	// Evaluate returns the weight of a sample that is (dx, dy) pixels away from the centre of a pixel. Weights may
+	// be negative.
+	Evaluate(dx, dy float64) float64
+}
+
+// defaultRadius holds the radius of every type of filter when none is given.
+var defaultRadius = map[FilterType]float64{
+	BoxFilter:            0.5,
+	TentFilter:           1.0,
+	GaussianFilter:       1.5,
+	MitchellFilter:       2.0,
+	BlackmanHarrisFilter: 2.0,
+}
+
+// New returns a filter of the given type and radius, or of its default radius if radius is not positive.
+// It returns nil if the type is not valid.
+func New(t FilterType, radius float64) Filter {
+	if radius <= 0 {
+		radius = defaultRadius[t]
+	}
+
+	switch t {
+	case BoxFilter:
+		return &Box{radius: radius}
+	case TentFilter:
+		return &Tent{radius: radius}
+	case GaussianFilter:
+		return NewGaussian(radius, radius/3)
+	case MitchellFilter:
+		return NewMitchell(radius, 1.0/3.0, 1.0/3.0)
+	case BlackmanHarrisFilter:
+		return &BlackmanHarris{radius: radius}
+	default:
+		return nil
+	}
+}
+
+// IsPixelBox returns whether f gives every sample the same weight and only covers the pixel the sample falls in,
+// which is the same as averaging the samples of every pixel.
+func IsPixelBox(f Filter) bool {
+	b, ok := f.(*Box)
+	return ok && b.radius <= 0.5
+}
+
+// Box gives the same weight to every sample within its radius.
+type Box struct {
+	radius float64
+}
+
+func (b *Box) Radius() float64 {
+	return b.radius
+}
+
+func (b *Box) Evaluate(dx, dy float64) float64 {
+	if math.Abs(dx) >= b.radius || math.Abs(dy) >= b.radius {
+		return 0
+	}
+
+	return 1
+}
+
+// Tent weighs samples by their distance to the centre of the pixel, falling linearly to 0 at its radius.
+type Tent struct {
+	radius float64
+}
+
+func (t *Tent) Radius() float64 {
+	return t.radius
+}
+
+func (t *Tent) Evaluate(dx, dy float64) float64 {
+	return max(0, t.radius-math.Abs(dx)) * max(0, t.radius-math.Abs(dy))
+}
+
+// Gaussian weighs samples with a Gaussian, shifted down so that it reaches 0 at its radius.
+type Gaussian struct {
+	radius float64
+	sigma  float64
+	// edge is the value of the Gaussian at the radius.
+	edge float64
+}
+
+// NewGaussian returns a Gaussian filter with the given radius and standard deviation.
+func NewGaussian(radius float64, sigma float64) *Gaussian {
+	return &Gaussian{
+		radius: radius,
+		sigma:  sigma,
+		edge:   gaussian(radius, sigma),
+	}
+}
+
+func (g *Gaussian) Radius() float64 {
+	return g.radius
+}
+
+func (g *Gaussian) Evaluate(dx, dy float64) float64 {
+	return max(0, gaussian(dx, g.sigma)-g.edge) * max(0, gaussian(dy, g.sigma)-g.edge)
+}
+
+func gaussian(x float64, sigma float64) float64 {
+	return math.Exp(-x * x / (2 * sigma * sigma))
+}
+
+// Mitchell is the family of cubic filters described by Mitchell and Netravali in "Reconstruction Filters in
+// Computer Graphics". Its negative lobes sharpen the image, at the cost of ringing around high contrast edges.
+type Mitchell struct {
+	radius float64
+	b      float64
+	c      float64
+}
+
+// NewMitchell returns a Mitchell-Netravali filter with the given radius and parameters. b = c = 1/3 is the
+// compromise between blurring and ringing recommended by the authors.
+func NewMitchell(radius float64, b float64, c float64) *Mitchell {
+	return &Mitchell{radius: radius, b: b, c: c}
+}
+
+func (m *Mitchell) Radius() float64 {
+	return m.radius
+}
+
+func (m *Mitchell) Evaluate(dx, dy float64) float64 {
+	// The cubic is defined over [-2, 2].
+	return m.mitchell1D(2*dx/m.radius) * m.mitchell1D(2*dy/m.radius)
+}
+
+func (m *Mitchell) mitchell1D(x float64) float64 {
+	x = math.Abs(x)
+	b, c := m.b, m.c
+	switch {
+	case x <= 1:
+		return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+	case x <= 2:
+		return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+	default:
+		return 0
+	}
+}
+
+// BlackmanHarris weighs samples with the four term Blackman-Harris window, which is close to a Gaussian but falls
+// smoothly to 0 at its radius.
+type BlackmanHarris struct {
+	radius float64
+}
+
+func (bh *BlackmanHarris) Radius() float64 {
+	return bh.radius
+}
+
+func (bh *BlackmanHarris) Evaluate(dx, dy float64) float64 {
+	return bh.window(dx) * bh.window(dy)
+}
+
+func (bh *BlackmanHarris) window(x float64) float64 {
+	x = math.Abs(x)
+	if x >= bh.radius {
+		return 0
+	}
+
+	// The window spans [0, 1], with its peak at 0.5.
+	t := 2 * math.Pi * (0.5 + x/(2*bh.radius))
+	return 0.35875 - 0.48829*math.Cos(t) + 0.14128*math.Cos(2*t) - 0.01168*math.Cos(3*t)
+}
@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFilters(t *testing.T) {
+	testData := []struct {
+		name       string
+		filterType FilterType
+		radius     float64
+		wantRadius float64
+		// negativeLobe is true if the filter has negative weights within its radius.
+		negativeLobe bool
+	}{
+		{name: "box", filterType: BoxFilter, wantRadius: 0.5},
+		{name: "wide box", filterType: BoxFilter, radius: 1.5, wantRadius: 1.5},
+		{name: "tent", filterType: TentFilter, wantRadius: 1},
+		{name: "gaussian", filterType: GaussianFilter, wantRadius: 1.5},
+		{name: "mitchell", filterType: MitchellFilter, wantRadius: 2, negativeLobe: true},
+		{name: "blackman-harris", filterType: BlackmanHarrisFilter, radius: 3, wantRadius: 3},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			f := New(test.filterType, test.radius)
+			if got := f.Radius(); got != test.wantRadius {
+				t.Fatalf("Radius() = %v, want %v", got, test.wantRadius)
+			}
+
+			r := f.Radius()
+			centre := f.Evaluate(0, 0)
+			if centre <= 0 {
+				t.Fatalf("Evaluate(0, 0) = %v, want a positive weight", centre)
+			}
+
+			negative := false
+			for i := range 100 {
+				d := r * float64(i) / 100
+				w := f.Evaluate(d, 0)
+				if w > centre {
+					t.Errorf("Evaluate(%v, 0) = %v, which is larger than the weight at the centre", d, w)
+				}
+				if w != f.Evaluate(-d, 0) || w != f.Evaluate(0, d) {
+					t.Errorf("Evaluate is not symmetric at distance %v", d)
+				}
+				negative = negative || w < 0
+			}
+
+			if negative != test.negativeLobe {
+				t.Errorf("negative weights = %v, want %v", negative, test.negativeLobe)
+			}
+
+			for _, d := range []float64{r, r + 0.1, 2 * r} {
+				if w := f.Evaluate(d, 0); math.Abs(w) > 1e-12 {
+					t.Errorf("Evaluate(%v, 0) = %v, want 0 at and beyond the radius", d, w)
+				}
+			}
+		})
+	}
+}
+
+func TestFilm(t *testing.T) {
+	type sample struct {
+		x, y float64
+		c    [3]float64
+	}
+
+	samples := []sample{
+		{x: 0.5, y: 0.5, c: [3]float64{1, 2, 3}},
+		{x: 1.9, y: 2.2, c: [3]float64{4, 0, 1}},
+		{x: 3.1, y: 1.4, c: [3]float64{0.5, 0.5, 0.5}},
+		{x: 2.5, y: 3.7, c: [3]float64{2, 1, 0}},
+		{x: 2.5, y: 2.5, c: [3]float64{math.NaN(), 1, 0}},
+	}
+
+	bounds := image.Rect(0, 0, 4, 4)
+	f := New(MitchellFilter, 1.5)
+
+	// Samples are rendered in two tiles whose films overlap by the radius of the filter, which must give the
+	// same result as splatting all of them to a single film.
+	want := NewFilm(f, bounds)
+	tiles := []*Film{NewFilm(f, image.Rect(0, 0, 4, 4)), NewFilm(f, image.Rect(0, 1, 4, 4))}
+	for _, s := range samples {
+		want.AddSample(s.x, s.y, s.c)
+		tile := tiles[0]
+		if s.y >= 2 {
+			tile = tiles[1]
+		}
+		tile.AddSample(s.x, s.y, s.c)
+	}
+
+	got := NewFilm(f, bounds)
+	for _, tile := range tiles {
+		got.Merge(tile.Bounds(), tile.Data())
+	}
+
+	if diff := cmp.Diff(want.Data(), got.Data(), cmpopts.EquateApprox(0, 1e-12)); diff != "" {
+		t.Errorf("merged film mismatch (-want +got):\n%s", diff)
+	}
+
+	// A sample at the centre of a pixel and far from any other one gives the pixel its colour.
+	box := NewFilm(New(BoxFilter, 0), bounds)
+	for _, s := range samples {
+		box.AddSample(s.x, s.y, s.c)
+	}
+
+	c, ok := box.Pixel(0, 0)
+	if !ok {
+		t.Fatalf("Pixel(0, 0) has no samples")
+	}
+	if diff := cmp.Diff([3]float64{1, 2, 3}, c); diff != "" {
+		t.Errorf("Pixel(0, 0) mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, ok := box.Pixel(3, 0); ok {
+		t.Errorf("Pixel(3, 0) has samples, want none")
+	}
+
+	if _, ok := box.Pixel(4, 4); ok {
+		t.Errorf("Pixel(4, 4) is outside the film but has samples")
+	}
+}
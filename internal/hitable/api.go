@@ -19,3 +19,13 @@ type Hitable interface {
 	Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3Impl
 	IsEmitter() bool
 }
+
+// Surface is implemented by hitables whose surface can be sampled uniformly by area.
+// It is used to start light paths on emitting geometry.
+type Surface interface {
+	// Area returns the area of the surface. Wrappers around hitables that cannot be sampled return 0.
+	Area() float64
+	// SampleSurface chooses a point uniformly over the surface. It returns a hit record describing the point,
+	// with its normal facing the side that light is emitted towards, and the material of the surface.
+	SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material)
+}
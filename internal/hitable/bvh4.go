@@ -517,7 +517,7 @@ func conservativeFloat32Max(val float64) float32 {
 func NewBVH4(hitables []Hitable, time0 float64, time1 float64) *BVH4 {
 	log.Infof("Building BVH4 with %v elements", len(hitables))
 	startTime := time.Now()
-	randomFunc := fastrandom.NewWithDefaults().Float64
+	randomFunc := fastrandom.NewWithSeed(bvhSeed).Float64
 	bvh := newBVH4(hitables, randomFunc, time0, time1)
 	log.Infof("Completed BVH4 construction in %v", time.Since(startTime))
 
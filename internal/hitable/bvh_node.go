@@ -17,6 +17,10 @@ import (
 // Ensure interface compliance.
 var _ Hitable = (*BVHNode)(nil)
 
+// bvhSeed is the seed of the generator that chooses the axes along which bounding volume hierarchies are split.
+// It is fixed so that every render of a scene traverses the same hierarchy.
+const bvhSeed = 1
+
 // BVHNode represents a bounding volume hierarchy node.
 type BVHNode struct {
 	left  Hitable
@@ -29,7 +33,7 @@ type BVHNode struct {
 func NewBVH(hitables []Hitable, time0 float64, time1 float64) *BVHNode {
 	log.Infof("Building BVH with %v elements", len(hitables))
 	startTime := time.Now()
-	randomFunc := fastrandom.NewWithDefaults().Float64
+	randomFunc := fastrandom.NewWithSeed(bvhSeed).Float64
 	bvh := newBVH(hitables, randomFunc, time0, time1)
 	log.Infof("Completed BVH construction in %v", time.Since(startTime))
 	return bvh
@@ -11,6 +11,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*FlipNormals)(nil)
+var _ Surface = (*FlipNormals)(nil)
 
 // FlipNormals represents a hitable with the inverted normal.
 type FlipNormals struct {
@@ -26,7 +27,9 @@ func NewFlipNormals(hitable Hitable) *FlipNormals {
 
 func (fn *FlipNormals) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
 	if hr, mat, ok := fn.hitable.Hit(r, tMin, tMax); ok {
-		return hitrecord.New(hr.T(), hr.U(), hr.V(), hr.P(), vec3.ScalarMul(hr.Normal(), -1)), mat, true
+		rec := hitrecord.New(hr.T(), hr.U(), hr.V(), hr.P(), vec3.ScalarMul(hr.Normal(), -1))
+		rec.SetIDs(hr.ObjectID(), hr.MaterialID())
+		return rec, mat, true
 	}
 	return nil, nil, false
 }
@@ -53,3 +56,20 @@ func (fn *FlipNormals) Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3
 func (fn *FlipNormals) IsEmitter() bool {
 	return fn.hitable.IsEmitter()
 }
+
+// Area returns the area of the wrapped hitable, or 0 if it cannot be sampled.
+func (fn *FlipNormals) Area() float64 {
+	if s, ok := fn.hitable.(Surface); ok {
+		return s.Area()
+	}
+
+	return 0
+}
+
+// SampleSurface chooses a point uniformly over the wrapped hitable and inverts its normal.
+func (fn *FlipNormals) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	hr, mat := fn.hitable.(Surface).SampleSurface(random)
+	rec := hitrecord.New(hr.T(), hr.U(), hr.V(), hr.P(), vec3.ScalarMul(hr.Normal(), -1))
+	rec.SetIDs(hr.ObjectID(), hr.MaterialID())
+	return rec, mat
+}
@@ -26,6 +26,16 @@ func NewSlice(hitables []Hitable) *HitableSlice {
 	}
 }
 
+// Len returns the number of elements in the slice.
+func (hs *HitableSlice) Len() int {
+	return len(hs.hitables)
+}
+
+// Hitables returns the elements in the slice.
+func (hs *HitableSlice) Hitables() []Hitable {
+	return hs.hitables
+}
+
 // Hit computes whether a ray intersects with any of the elements in the slice.
 func (hs *HitableSlice) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
 	var rec *hitrecord.HitRecord
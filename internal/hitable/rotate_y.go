@@ -13,6 +13,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*RotateY)(nil)
+var _ Surface = (*RotateY)(nil)
 
 // RotateY represents a rotation along the Y axis.
 type RotateY struct {
@@ -104,7 +105,9 @@ func (ry *RotateY) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRec
 			Z: -ry.sinTheta*hr.Normal().X + ry.cosTheta*hr.Normal().Z,
 		}
 
-		return hitrecord.New(hr.T(), hr.U(), hr.V(), p, normal), mat, true
+		rec := hitrecord.New(hr.T(), hr.U(), hr.V(), p, normal)
+		rec.SetIDs(hr.ObjectID(), hr.MaterialID())
+		return rec, mat, true
 	}
 
 	return nil, nil, false
@@ -157,3 +160,31 @@ func (ry *RotateY) Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3Impl
 func (ry *RotateY) IsEmitter() bool {
 	return ry.hitable.IsEmitter()
 }
+
+// Area returns the area of the rotated hitable, or 0 if it cannot be sampled.
+func (ry *RotateY) Area() float64 {
+	if s, ok := ry.hitable.(Surface); ok {
+		return s.Area()
+	}
+
+	return 0
+}
+
+// SampleSurface chooses a point uniformly over the rotated hitable.
+func (ry *RotateY) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	hr, mat := ry.hitable.(Surface).SampleSurface(random)
+	p := vec3.Vec3Impl{
+		X: ry.cosTheta*hr.P().X + ry.sinTheta*hr.P().Z,
+		Y: hr.P().Y,
+		Z: -ry.sinTheta*hr.P().X + ry.cosTheta*hr.P().Z,
+	}
+	normal := vec3.Vec3Impl{
+		X: ry.cosTheta*hr.Normal().X + ry.sinTheta*hr.Normal().Z,
+		Y: hr.Normal().Y,
+		Z: -ry.sinTheta*hr.Normal().X + ry.cosTheta*hr.Normal().Z,
+	}
+
+	rec := hitrecord.New(hr.T(), hr.U(), hr.V(), p, normal)
+	rec.SetIDs(hr.ObjectID(), hr.MaterialID())
+	return rec, mat
+}
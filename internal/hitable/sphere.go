@@ -15,6 +15,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*Sphere)(nil)
+var _ Surface = (*Sphere)(nil)
 
 // Sphere represents a sphere in the 3d world.
 type Sphere struct {
@@ -24,6 +25,9 @@ type Sphere struct {
 	time1    float64
 	radius   float64
 	material material.Material
+	// Identifiers used for AOVs
+	objectID   uint32
+	materialID uint32
 }
 
 func getSphereUV(p vec3.Vec3Impl) (float64, float64) {
@@ -75,8 +79,10 @@ func (s *Sphere) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecor
 				outwardNormal = vec3.ScalarMul(outwardNormal, -1)
 			}
 			u, v := getSphereUV(outwardNormal)
-			return hitrecord.New(temp, u, v, r.PointAtParameter(temp),
-				outwardNormal), s.material, true
+			hr := hitrecord.New(temp, u, v, r.PointAtParameter(temp),
+				outwardNormal)
+			hr.SetIDs(s.objectID, s.materialID)
+			return hr, s.material, true
 		}
 
 		temp = (-b + math.Sqrt(b*b-a*c)) / a
@@ -86,14 +92,22 @@ func (s *Sphere) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecor
 				outwardNormal = vec3.ScalarMul(outwardNormal, -1)
 			}
 			u, v := getSphereUV(outwardNormal)
-			return hitrecord.New(temp, u, v,
+			hr := hitrecord.New(temp, u, v,
 				r.PointAtParameter(temp),
-				vec3.ScalarDiv(vec3.Sub(r.PointAtParameter(temp), s.center(r.Time())), s.radius)), s.material, true
+				vec3.ScalarDiv(vec3.Sub(r.PointAtParameter(temp), s.center(r.Time())), s.radius))
+			hr.SetIDs(s.objectID, s.materialID)
+			return hr, s.material, true
 		}
 	}
 	return nil, nil, false
 }
 
+// SetIDs sets the object and material identifiers reported in hit records.
+func (s *Sphere) SetIDs(objectID uint32, materialID uint32) {
+	s.objectID = objectID
+	s.materialID = materialID
+}
+
 func (s *Sphere) HitEdge(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, bool, bool) {
 	rec, _, ok := s.Hit(r, tMin, tMax)
 	if !ok {
@@ -147,3 +161,21 @@ func (s *Sphere) Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3Impl {
 func (s *Sphere) IsEmitter() bool {
 	return s.material.IsEmitter()
 }
+
+// Area returns the area of the sphere.
+func (s *Sphere) Area() float64 {
+	return 4.0 * math.Pi * s.radius * s.radius
+}
+
+// SampleSurface chooses a point uniformly over the sphere at its initial position.
+func (s *Sphere) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	z := 1.0 - 2.0*random.Float64()
+	r := math.Sqrt(math.Max(0, 1.0-z*z))
+	phi := 2.0 * math.Pi * random.Float64()
+	normal := vec3.Vec3Impl{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z}
+
+	u, v := getSphereUV(normal)
+	hr := hitrecord.New(0, u, v, vec3.Add(s.center0, vec3.ScalarMul(normal, s.radius)), normal)
+	hr.SetIDs(s.objectID, s.materialID)
+	return hr, s.material
+}
@@ -0,0 +1,77 @@
+package hitable
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestSampleSurface(t *testing.T) {
+	mat := material.NewLambertian(nil)
+
+	testData := []struct {
+		name    string
+		surface Surface
+		area    float64
+	}{
+		{
+			name:    "Triangle",
+			surface: NewTriangle(vec3.Vec3Impl{}, vec3.Vec3Impl{X: 2}, vec3.Vec3Impl{Y: 1}, mat),
+			area:    1,
+		},
+		{
+			name:    "Sphere",
+			surface: NewSphere(vec3.Vec3Impl{X: 1}, vec3.Vec3Impl{X: 1}, 0, 1, 0.5, mat),
+			area:    math.Pi,
+		},
+		{
+			name:    "XZ rectangle",
+			surface: NewXZRect(0, 2, 0, 3, 1, mat),
+			area:    6,
+		},
+		{
+			name:    "Flipped XZ rectangle",
+			surface: NewFlipNormals(NewXZRect(0, 2, 0, 3, 1, mat)),
+			area:    6,
+		},
+		{
+			name:    "Translated and rotated XY rectangle",
+			surface: NewTranslate(NewRotateY(NewXYRect(0, 1, 0, 1, 0, mat), 30), vec3.Vec3Impl{X: 1, Y: 2}),
+			area:    1,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.surface.Area(); math.Abs(got-test.area) > 1e-9 {
+				t.Errorf("Area() = %v, want %v", got, test.area)
+			}
+
+			random := fastrandom.NewWithDefaults()
+			for range 1000 {
+				rec, gotMat := test.surface.SampleSurface(random)
+				if gotMat != mat {
+					t.Fatalf("SampleSurface() material = %v, want %v", gotMat, mat)
+				}
+
+				// A ray shot back along the normal hits the surface at the sampled point, with the same normal.
+				n := rec.Normal()
+				r := ray.New(vec3.Add(rec.P(), n), vec3.ScalarMul(n, -1), 0)
+				hit, _, ok := test.surface.(Hitable).Hit(r, 0.5, 1.5)
+				if !ok {
+					t.Fatalf("SampleSurface() point %v with normal %v is not on the surface", rec.P(), n)
+				}
+				if math.Abs(hit.T()-1) > 1e-6 {
+					t.Errorf("hit distance from the sampled point = %v, want 1", hit.T())
+				}
+				if d := vec3.Sub(hit.Normal(), n).Length(); d > 1e-6 {
+					t.Errorf("SampleSurface() normal = %v, want %v", n, hit.Normal())
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*Translate)(nil)
+var _ Surface = (*Translate)(nil)
 
 // Translate represents a hitable with its associated translation.
 type Translate struct {
@@ -29,7 +30,9 @@ func NewTranslate(hitable Hitable, offset vec3.Vec3Impl) *Translate {
 func (tr *Translate) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
 	movedRay := ray.New(vec3.Sub(r.Origin(), tr.offset), r.Direction(), r.Time())
 	if hr, mat, ok := tr.hitable.Hit(movedRay, tMin, tMax); ok {
-		return hitrecord.New(hr.T(), hr.U(), hr.V(), vec3.Add(hr.P(), tr.offset), hr.Normal()), mat, true
+		rec := hitrecord.New(hr.T(), hr.U(), hr.V(), vec3.Add(hr.P(), tr.offset), hr.Normal())
+		rec.SetIDs(hr.ObjectID(), hr.MaterialID())
+		return rec, mat, true
 	}
 
 	return nil, nil, false
@@ -64,3 +67,20 @@ func (tr *Translate) Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3Im
 func (tr *Translate) IsEmitter() bool {
 	return tr.hitable.IsEmitter()
 }
+
+// Area returns the area of the translated hitable, or 0 if it cannot be sampled.
+func (tr *Translate) Area() float64 {
+	if s, ok := tr.hitable.(Surface); ok {
+		return s.Area()
+	}
+
+	return 0
+}
+
+// SampleSurface chooses a point uniformly over the translated hitable.
+func (tr *Translate) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	hr, mat := tr.hitable.(Surface).SampleSurface(random)
+	rec := hitrecord.New(hr.T(), hr.U(), hr.V(), vec3.Add(hr.P(), tr.offset), hr.Normal())
+	rec.SetIDs(hr.ObjectID(), hr.MaterialID())
+	return rec, mat
+}
@@ -15,6 +15,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*Triangle)(nil)
+var _ Surface = (*Triangle)(nil)
 
 // Triangle represents a single triangle in the 3d world.
 type Triangle struct {
@@ -49,6 +50,9 @@ type Triangle struct {
 	v2 float64
 	// Bounding box
 	bb *aabb.AABB
+	// Identifiers used for AOVs
+	objectID   uint32
+	materialID uint32
 }
 
 // NewTriangle returns a new untextured triangle.
@@ -249,7 +253,9 @@ func (tri *Triangle) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitR
 	// Handle normal mapping
 	normalMap := tri.material.NormalMap()
 	if normalMap == nil {
-		return hitrecord.New(t, uu, vv, r.PointAtParameter(t), normal), tri.material, true
+		hr := hitrecord.New(t, uu, vv, r.PointAtParameter(t), normal)
+		hr.SetIDs(tri.objectID, tri.materialID)
+		return hr, tri.material, true
 	}
 
 	// We use OpenGL normal maps.
@@ -261,7 +267,15 @@ func (tri *Triangle) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitR
 	tbn := mat3.NewTBN(tri.tangent, tri.bitangent, normal)
 	newNormal := mat3.MatrixVectorMul(tbn, normalTangentSpace).MakeUnitVector()
 
-	return hitrecord.New(t, uu, vv, r.PointAtParameter(t), newNormal), tri.material, true
+	hr := hitrecord.New(t, uu, vv, r.PointAtParameter(t), newNormal)
+	hr.SetIDs(tri.objectID, tri.materialID)
+	return hr, tri.material, true
+}
+
+// SetIDs sets the object and material identifiers reported in hit records.
+func (tri *Triangle) SetIDs(objectID uint32, materialID uint32) {
+	tri.objectID = objectID
+	tri.materialID = materialID
 }
 
 func (tri *Triangle) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
@@ -315,12 +329,13 @@ func (tri *Triangle) Random(o *vec3.Vec3Impl) *vec3.Vec3Impl {
 */
 
 func (tri *Triangle) Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3Impl {
-	t1 := random.Float64()
-	randomPoint01 := vec3.Lerp(tri.vertex0, tri.vertex1, t1)
-	t2 := random.Float64()
-	randomPoint02 := vec3.Lerp(tri.vertex0, tri.vertex2, t2)
-	t3 := random.Float64()
-	randomPoint := vec3.Lerp(randomPoint01, randomPoint02, t3)
+	// Points are distributed uniformly over the area of the triangle to match PDFValue.
+	su := math.Sqrt(random.Float64())
+	b1 := 1 - su
+	b2 := random.Float64() * su
+	randomPoint := vec3.Add(tri.vertex0, vec3.Add(
+		vec3.ScalarMul(vec3.Sub(tri.vertex1, tri.vertex0), b1),
+		vec3.ScalarMul(vec3.Sub(tri.vertex2, tri.vertex0), b2)))
 
 	return vec3.Sub(randomPoint, o)
 }
@@ -368,3 +383,29 @@ func (tri *Triangle) V2() float64 {
 func (tri *Triangle) Material() material.Material {
 	return tri.material
 }
+
+// Area returns the area of the triangle.
+func (tri *Triangle) Area() float64 {
+	return tri.area
+}
+
+// SampleSurface chooses a point uniformly over the triangle.
+func (tri *Triangle) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	su := math.Sqrt(random.Float64())
+	u := su * (1.0 - random.Float64())
+	v := su - u
+	w := 1.0 - u - v
+
+	p := vec3.Add(tri.vertex0, vec3.ScalarMul(tri.edge1, u), vec3.ScalarMul(tri.edge2, v))
+	uu := w*tri.u0 + u*tri.u1 + v*tri.u2
+	vv := w*tri.v0 + u*tri.v1 + v*tri.v2
+
+	normal := tri.normal
+	if tri.perVertexNormals {
+		normal = vec3.UnitVector(vec3.Add(vec3.ScalarMul(tri.vn0, w), vec3.ScalarMul(tri.vn1, u), vec3.ScalarMul(tri.vn2, v)))
+	}
+
+	hr := hitrecord.New(0, uu, vv, p, normal)
+	hr.SetIDs(tri.objectID, tri.materialID)
+	return hr, tri.material
+}
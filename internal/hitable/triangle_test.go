@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/flynn-nrg/izpi/internal/aabb"
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/hitrecord"
 	"github.com/flynn-nrg/izpi/internal/material"
 	"github.com/flynn-nrg/izpi/internal/ray"
@@ -132,3 +133,35 @@ func TestTriangleHit(t *testing.T) {
 		})
 	}
 }
+
+func TestTriangleRandom(t *testing.T) {
+	const n = 100000
+	tri := NewTriangle(vec3.Vec3Impl{}, vec3.Vec3Impl{X: 1}, vec3.Vec3Impl{Y: 1}, material.NewLambertian(nil))
+	origin := vec3.Vec3Impl{Z: 1}
+	random := fastrandom.New(12345, 4294967296, 1664525, 1013904223)
+
+	var centroid vec3.Vec3Impl
+	var corner int
+	for range n {
+		p := vec3.Add(origin, tri.Random(origin, random))
+		if p.X < 0 || p.Y < 0 || p.X+p.Y > 1 || math.Abs(p.Z) > 1e-9 {
+			t.Fatalf("Random() = %v, which is outside the triangle", p)
+		}
+
+		centroid = vec3.Add(centroid, vec3.ScalarDiv(p, n))
+		if p.X+p.Y < 0.5 {
+			corner++
+		}
+	}
+
+	// Uniformly distributed points match the density returned by PDFValue.
+	want := vec3.Vec3Impl{X: 1.0 / 3.0, Y: 1.0 / 3.0}
+	if d := vec3.Sub(centroid, want).Length(); d > 0.01 {
+		t.Errorf("mean of Random() = %v, want %v", centroid, want)
+	}
+
+	// The corner where x+y < 0.5 covers a quarter of the area of the triangle.
+	if got := float64(corner) / n; math.Abs(got-0.25) > 0.01 {
+		t.Errorf("fraction of points in the corner = %v, want 0.25", got)
+	}
+}
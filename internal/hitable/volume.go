@@ -0,0 +1,67 @@
+package hitable
+
+import (
+	"github.com/flynn-nrg/izpi/internal/aabb"
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ Hitable = (*Volume)(nil)
+
+// Volume represents a participating medium enclosed by a closed hitable with outward facing normals.
+// The enclosing hitable is only used as the boundary of the medium and its material is ignored.
+type Volume struct {
+	boundary Hitable
+	material *material.MediumBoundary
+}
+
+// NewVolume returns a volume filled with the given medium.
+func NewVolume(boundary Hitable, m *medium.Medium) *Volume {
+	return &Volume{
+		boundary: boundary,
+		material: material.NewMediumBoundary(m),
+	}
+}
+
+// NewConstantMedium returns a volume filled with a homogeneous medium that scatters light equally in every
+// direction, with the given density and with albedo a. The albedo is taken at the origin of the texture space.
+func NewConstantMedium(boundary Hitable, density float64, a texture.Texture) *Volume {
+	albedo := a.Value(0, 0, vec3.Vec3Impl{})
+	sigmaS := vec3.ScalarMul(albedo, density)
+	sigmaA := vec3.Sub(vec3.Vec3Impl{X: density, Y: density, Z: density}, sigmaS)
+	return NewVolume(boundary, medium.NewHomogeneous(sigmaA, sigmaS, 0))
+}
+
+func (v *Volume) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	if rec, _, ok := v.boundary.Hit(r, tMin, tMax); ok {
+		return rec, v.material, true
+	}
+
+	return nil, nil, false
+}
+
+func (v *Volume) HitEdge(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, bool, bool) {
+	return nil, false, false
+}
+
+func (v *Volume) BoundingBox(time0 float64, time1 float64) (*aabb.AABB, bool) {
+	return v.boundary.BoundingBox(time0, time1)
+}
+
+func (v *Volume) PDFValue(o vec3.Vec3Impl, d vec3.Vec3Impl) float64 {
+	return 0.0
+}
+
+func (v *Volume) Random(o vec3.Vec3Impl, _ *fastrandom.LCG) vec3.Vec3Impl {
+	return vec3.Vec3Impl{X: 1}
+}
+
+func (v *Volume) IsEmitter() bool {
+	return false
+}
@@ -12,6 +12,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*XYRect)(nil)
+var _ Surface = (*XYRect)(nil)
 
 // XYRect represents an axis aligned rectangle.
 type XYRect struct {
@@ -112,3 +113,21 @@ func (xyr *XYRect) Random(o vec3.Vec3Impl, _ *fastrandom.LCG) vec3.Vec3Impl {
 func (xyr *XYRect) IsEmitter() bool {
 	return xyr.material.IsEmitter()
 }
+
+// Area returns the area of the rectangle.
+func (xyr *XYRect) Area() float64 {
+	return (xyr.x1 - xyr.x0) * (xyr.y1 - xyr.y0)
+}
+
+// SampleSurface chooses a point uniformly over the rectangle.
+func (xyr *XYRect) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	u := random.Float64()
+	v := random.Float64()
+	p := vec3.Vec3Impl{
+		X: xyr.x0 + u*(xyr.x1-xyr.x0),
+		Y: xyr.y0 + v*(xyr.y1-xyr.y0),
+		Z: xyr.k,
+	}
+
+	return hitrecord.New(0, u, v, p, vec3.Vec3Impl{Z: 1}), xyr.material
+}
@@ -14,6 +14,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*XZRect)(nil)
+var _ Surface = (*XZRect)(nil)
 
 // XZRect represents an axis aligned rectangle.
 type XZRect struct {
@@ -128,3 +129,21 @@ func (xzr *XZRect) Random(o vec3.Vec3Impl, random *fastrandom.LCG) vec3.Vec3Impl
 func (xzr *XZRect) IsEmitter() bool {
 	return xzr.material.IsEmitter()
 }
+
+// Area returns the area of the rectangle.
+func (xzr *XZRect) Area() float64 {
+	return (xzr.x1 - xzr.x0) * (xzr.z1 - xzr.z0)
+}
+
+// SampleSurface chooses a point uniformly over the rectangle.
+func (xzr *XZRect) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	u := random.Float64()
+	v := random.Float64()
+	p := vec3.Vec3Impl{
+		X: xzr.x0 + u*(xzr.x1-xzr.x0),
+		Z: xzr.z0 + v*(xzr.z1-xzr.z0),
+		Y: xzr.k,
+	}
+
+	return hitrecord.New(0, u, v, p, vec3.Vec3Impl{Y: 1}), xzr.material
+}
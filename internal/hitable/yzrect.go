@@ -12,6 +12,7 @@ import (
 
 // Ensure interface compliance.
 var _ Hitable = (*YZRect)(nil)
+var _ Surface = (*YZRect)(nil)
 
 // YZRect represents an axis aligned rectangle.
 type YZRect struct {
@@ -112,3 +113,21 @@ func (yzr *YZRect) Random(o vec3.Vec3Impl, _ *fastrandom.LCG) vec3.Vec3Impl {
 func (yzr *YZRect) IsEmitter() bool {
 	return yzr.material.IsEmitter()
 }
+
+// Area returns the area of the rectangle.
+func (yzr *YZRect) Area() float64 {
+	return (yzr.y1 - yzr.y0) * (yzr.z1 - yzr.z0)
+}
+
+// SampleSurface chooses a point uniformly over the rectangle.
+func (yzr *YZRect) SampleSurface(random *fastrandom.LCG) (*hitrecord.HitRecord, material.Material) {
+	u := random.Float64()
+	v := random.Float64()
+	p := vec3.Vec3Impl{
+		Y: yzr.y0 + u*(yzr.y1-yzr.y0),
+		Z: yzr.z0 + v*(yzr.z1-yzr.z0),
+		X: yzr.k,
+	}
+
+	return hitrecord.New(0, u, v, p, vec3.Vec3Impl{X: 1}), yzr.material
+}
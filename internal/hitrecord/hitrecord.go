@@ -9,6 +9,9 @@ type HitRecord struct {
 	t      float64
 	p      vec3.Vec3Impl
 	normal vec3.Vec3Impl
+	// Identifiers of the object and material that were hit, used for AOVs.
+	objectID   uint32
+	materialID uint32
 }
 
 func New(t float64, u float64, v float64, p vec3.Vec3Impl, normal vec3.Vec3Impl) *HitRecord {
@@ -45,3 +48,19 @@ func (hr *HitRecord) U() float64 {
 func (hr *HitRecord) V() float64 {
 	return hr.v
 }
+
+// ObjectID returns the identifier of the object that was hit.
+func (hr *HitRecord) ObjectID() uint32 {
+	return hr.objectID
+}
+
+// MaterialID returns the identifier of the material of the object that was hit.
+func (hr *HitRecord) MaterialID() uint32 {
+	return hr.materialID
+}
+
+// SetIDs sets the object and material identifiers.
+func (hr *HitRecord) SetIDs(objectID uint32, materialID uint32) {
+	hr.objectID = objectID
+	hr.materialID = materialID
+}
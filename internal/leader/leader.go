@@ -1,25 +1,39 @@
 package leader
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"image"
 	"io"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/flynn-nrg/go-vfx/go-oiio/oiio"
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+	"github.com/flynn-nrg/izpi/internal/aov"
+	"github.com/flynn-nrg/izpi/internal/checkpoint"
 	"github.com/flynn-nrg/izpi/internal/colours"
 	"github.com/flynn-nrg/izpi/internal/config"
 	"github.com/flynn-nrg/izpi/internal/display"
+	"github.com/flynn-nrg/izpi/internal/exr"
+	"github.com/flynn-nrg/izpi/internal/filter"
+	"github.com/flynn-nrg/izpi/internal/materialx"
 	"github.com/flynn-nrg/izpi/internal/output"
 	"github.com/flynn-nrg/izpi/internal/postprocess"
 	"github.com/flynn-nrg/izpi/internal/render"
 	"github.com/flynn-nrg/izpi/internal/sampler"
 	"github.com/flynn-nrg/izpi/internal/scene"
+	"github.com/flynn-nrg/izpi/internal/sequence"
 	"github.com/flynn-nrg/izpi/internal/texture"
 	"github.com/flynn-nrg/izpi/internal/transport"
+	"github.com/flynn-nrg/izpi/internal/vdb"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 
@@ -74,12 +88,23 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 		log.Fatalf("Unknown scene file extension: %s", filepath.Ext(cfg.Scene))
 	}
 
+	// Translate the materials defined in MaterialX documents before their textures are loaded.
+	if err := materialx.Resolve(protoScene); err != nil {
+		log.Fatalf("Error loading MaterialX materials: %v", err)
+	}
+
 	// Override the colour sampler if the scene is spectral.
 	if protoScene.GetColourRepresentation() == pb_transport.ColourRepresentation_SPECTRAL && cfg.Sampler == "colour" {
 		log.Infof("Overriding colour sampler to spectral")
 		cfg.Sampler = "spectral"
 	}
 
+	// Likewise for the bidirectional, Metropolis and photon mapping samplers.
+	if protoScene.GetColourRepresentation() == pb_transport.ColourRepresentation_SPECTRAL && (cfg.Sampler == "bdpt" || cfg.Sampler == "mlt" || cfg.Sampler == "ppm") {
+		log.Infof("Overriding %v sampler to spectral-%v", cfg.Sampler, cfg.Sampler)
+		cfg.Sampler = "spectral-" + cfg.Sampler
+	}
+
 	// Load textures
 	textures := make(map[string]*texture.ImageTxt)
 	for _, t := range protoScene.GetImageTextures() {
@@ -108,7 +133,27 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 		displacementMaps[t.GetFilename()] = imageText
 	}
 
-	t := transport.NewTransport(aspectRatio, protoScene, nil, textures, displacementMaps, int(cfg.NumWorkers))
+	// Load volume grids. The files are kept as they are to be streamed to the workers.
+	volumeFiles := make(map[string][]byte)
+	volumeGrids := make(map[string][]*vdb.Grid)
+	for _, v := range protoScene.GetVolumeGrids() {
+		log.Infof("Loading volume grids %s", v.GetFilename())
+		data, err := os.ReadFile(v.GetFilename())
+		if err != nil {
+			log.Fatalf("Error loading volume grids %s: %v", v.GetFilename(), err)
+		}
+		grids, err := vdb.Read(bytes.NewReader(data))
+		if err != nil {
+			log.Fatalf("Error loading volume grids %s: %v", v.GetFilename(), err)
+		}
+		volumeFiles[v.GetFilename()] = data
+		volumeGrids[v.GetFilename()] = grids
+
+		// Update metadata.
+		v.Size = uint64(len(data))
+	}
+
+	t := transport.NewTransport(aspectRatio, protoScene, nil, textures, displacementMaps, volumeGrids, int(cfg.NumWorkers))
 	sceneData, err = t.ToScene()
 	if err != nil {
 		log.Fatalf("Error loading scene: %v", err)
@@ -121,22 +166,121 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 		protoScene.StreamTriangles = true
 	}
 
+	if sequence.StringToType(cfg.SampleGenerator) == sequence.InvalidGenerator {
+		log.Fatalf("unknown sample generator %q", cfg.SampleGenerator)
+	}
+
+	if filter.StringToType(cfg.Filter) == filter.InvalidFilter {
+		log.Fatalf("unknown reconstruction filter %q", cfg.Filter)
+	}
+
 	var remoteWorkers []*render.RemoteWorkerConfig
 
 	if !standalone {
-		remoteWorkers, err = setupWorkers(ctx, cfg, protoScene, textures, displacementMaps)
+		remoteWorkers, err = setupWorkers(ctx, cfg, protoScene, textures, displacementMaps, volumeFiles)
 		if err != nil {
 			log.Fatalf("failed to setup workers: %v", err)
 		}
 	}
 
+	lightGroups, err := resolveLightGroups(protoScene, cfg.LightGroups)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	objectNames, materialNames := sceneNames(protoScene)
+
 	// Free up resources
 	protoScene = nil
 
+	depth := sampler.Depth{
+		Max:             int(cfg.Depth),
+		Diffuse:         int(cfg.MaxDiffuseDepth),
+		Specular:        int(cfg.MaxSpecularDepth),
+		Transmission:    int(cfg.MaxTransmissionDepth),
+		Volume:          int(cfg.MaxVolumeDepth),
+		RussianRoulette: int(cfg.RussianRouletteDepth),
+	}
+
+	var adaptiveConfig *adaptive.Config
+	// Photon mapping shrinks its gather radius between progressive passes, which adaptive sampling does not render.
+	photonMapping := sampler.IsPhotonMapping(sampler.StringToType(cfg.Sampler))
+	if cfg.Adaptive && (sampler.IsMetropolis(sampler.StringToType(cfg.Sampler)) || photonMapping) {
+		log.Fatalf("Adaptive sampling is not supported by the %v sampler", cfg.Sampler)
+	}
+	if cfg.Adaptive {
+		adaptiveConfig = adaptive.NewConfig(int(cfg.MinSamples), int(cfg.Samples), cfg.NoiseThreshold)
+		log.Infof("Adaptive sampling enabled: %v to %v samples per pixel, noise threshold %v",
+			adaptiveConfig.MinSamples, adaptiveConfig.MaxSamples, adaptiveConfig.Threshold)
+	}
+
+	// Budgets are checked between progressive passes, while adaptive sampling renders every tile to convergence in
+	// a single pass, so stopping it early would leave the tiles not rendered yet black.
+	budget := cfg.TimeLimit > 0 || cfg.TargetNoise > 0
+	if budget && cfg.Adaptive {
+		log.Fatal("A render budget cannot be used with adaptive sampling, which already stops sampling every pixel once it reaches the noise threshold")
+	}
+	if cfg.Progressive && cfg.Adaptive {
+		log.Fatal("Adaptive sampling and progressive rendering cannot be used together")
+	}
+
+	// Stopping on a budget leaves the frame partially rendered unless every pass covers the whole image.
+	progressive := cfg.Progressive
+	if budget && !progressive {
+		log.Infof("Enabling progressive rendering to honour the render budget")
+		progressive = true
+	}
+
+	// Every progressive pass traces a new photon map, which is what makes caustics converge.
+	if photonMapping && !progressive {
+		log.Infof("Enabling progressive rendering to refine the photon map")
+		progressive = true
+	}
+
+	var passSamples int
+	renderCtx := ctx
+	if progressive {
+		passSamples = max(int(cfg.PassSamples), 1)
+		log.Infof("Progressive rendering enabled: %v samples per pass. Press Ctrl+C to stop and write the current result", passSamples)
+	}
+
+	aovTypes, err := aov.Parse(cfg.AOVs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The denoiser is guided by the first hit albedo, normal and depth, which are rendered as AOVs.
+	if cfg.Denoise && !sampler.SupportsAOVs(sampler.StringToType(cfg.Sampler)) {
+		log.Fatalf("Denoising is not supported by the %v sampler, which cannot render the AOVs that guide the denoiser", cfg.Sampler)
+	}
+	if cfg.Denoise {
+		for _, t := range []aov.Type{aov.Albedo, aov.Normal, aov.Depth} {
+			if !slices.Contains(aovTypes, t) {
+				aovTypes = append(aovTypes, t)
+			}
+		}
+	}
+
+	aovLayout := aov.Layout{Types: aovTypes, LightGroups: lightGroups}
+	if !aovLayout.Empty() && !sampler.SupportsAOVs(sampler.StringToType(cfg.Sampler)) {
+		log.Fatalf("AOVs are not supported by the %v sampler", cfg.Sampler)
+	}
+
+	if cfg.Resume && cfg.Checkpoint == "" {
+		log.Fatal("Resuming a render requires a checkpoint file")
+	}
+
+	// Stopping a progressive or checkpointed render still writes out the work done so far.
+	if progressive || cfg.Checkpoint != "" {
+		var stop context.CancelFunc
+		renderCtx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+	}
+
 	r := render.New(
 		sceneData,
 		int(cfg.XSize), int(cfg.YSize),
-		int(cfg.Samples), int(cfg.Depth),
+		int(cfg.Samples), depth,
 		colours.Black,
 		colours.White,
 		colours.SpectralBlack,
@@ -146,14 +290,74 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 		previewChan,
 		cfg.Preview,
 		sampler.StringToType(cfg.Sampler),
+		adaptiveConfig,
+		passSamples,
 	)
 
+	if len(aovTypes) > 0 {
+		log.Infof("Rendering AOVs: %v", strings.Join(aov.Names(aovTypes), ", "))
+	}
+	if len(lightGroups) > 0 {
+		log.Infof("Rendering light groups: %v", strings.Join(lightGroups, ", "))
+	}
+	r.EnableAOVs(aovLayout)
+
+	if sampler.IsMetropolis(sampler.StringToType(cfg.Sampler)) {
+		log.Infof("Metropolis light transport: %v bootstrap paths, large step probability %v",
+			cfg.MLTBootstrapSamples, cfg.MLTLargeStepProb)
+		r.SetMLTConfig(sampler.MLTConfig{
+			Seed:                 cfg.Seed,
+			BootstrapSamples:     int(cfg.MLTBootstrapSamples),
+			LargeStepProbability: cfg.MLTLargeStepProb,
+		})
+	}
+
+	log.Infof("Sample generator: %v, seed %v", cfg.SampleGenerator, cfg.Seed)
+	r.SetSampleGenerator(sequence.StringToType(cfg.SampleGenerator), cfg.Seed)
+
+	log.Infof("Reconstruction filter: %v, radius %v", cfg.Filter, cfg.FilterRadius)
+	r.SetFilter(filter.StringToType(cfg.Filter), cfg.FilterRadius)
+
+	if photonMapping {
+		log.Infof("Photon mapping: %v photons per pass, initial radius %v, alpha %v", cfg.Photons, cfg.PhotonRadius, cfg.PhotonAlpha)
+		r.SetPhotonConfig(sampler.PhotonConfig{
+			Seed:    cfg.Seed,
+			Photons: int(cfg.Photons),
+			Radius:  cfg.PhotonRadius,
+			Alpha:   cfg.PhotonAlpha,
+		})
+	}
+
+	if budget {
+		timeLimit := time.Duration(cfg.TimeLimit) * time.Second
+		log.Infof("Render budget: time limit %v, target noise level %v", timeLimit, cfg.TargetNoise)
+		r.SetBudget(timeLimit, cfg.TargetNoise)
+	}
+
+	if cfg.Checkpoint != "" {
+		interval := time.Duration(cfg.CheckpointInterval) * time.Second
+		log.Infof("Saving checkpoints to %s every %v", cfg.Checkpoint, interval)
+		r.EnableCheckpoints(cfg.Checkpoint, interval)
+	}
+
+	if cfg.Resume {
+		log.Infof("Resuming render from checkpoint %s", cfg.Checkpoint)
+		state, err := checkpoint.Load(cfg.Checkpoint)
+		if err != nil {
+			log.Fatalf("Failed to load checkpoint: %v", err)
+		}
+
+		if err := r.Resume(state); err != nil {
+			log.Fatalf("Failed to resume render: %v", err)
+		}
+	}
+
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 
 	// Detach the renderer as SDL needs to use the main thread for everything.
 	go func() {
-		canvas = r.Render(ctx)
+		canvas = r.Render(renderCtx)
 		wg.Done()
 	}()
 
@@ -172,6 +376,18 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 
 	wg.Wait()
 
+	if cfg.Denoise {
+		log.Infof("Denoising image")
+		denoise, err := postprocess.NewDenoise(r.AOVs(), int(cfg.DenoiseIterations))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := denoise.Apply(canvas, sceneData); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	log.Infof("Writing output to %s", cfg.OutputFile)
 
 	switch cfg.OutputMode {
@@ -201,7 +417,7 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 
 		// Use ACES writer for spectral rendering, standard writer for others
 		var out output.Output
-		if cfg.Sampler == "spectral" {
+		if sampler.IsSpectral(sampler.StringToType(cfg.Sampler)) {
 			metadata := &oiio.ACESMetadata{
 				DisplayWindow:    canvas.Bounds(),
 				DataWindow:       canvas.Bounds(),
@@ -224,6 +440,61 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 		if err != nil {
 			log.Fatal(err)
 		}
+
+	case "multilayer-exr":
+		outFileName := strings.Replace(cfg.OutputFile, "png", "exr", 1)
+
+		colourSpace := output.LinearRec709
+		if sampler.IsSpectral(sampler.StringToType(cfg.Sampler)) {
+			colourSpace = output.ACEScg
+		}
+
+		out, err := output.NewMultiLayerEXR(outFileName, colourSpace)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if aovs := r.AOVs(); aovs != nil {
+			for _, l := range output.AOVLayers(aovs, objectNames, materialNames) {
+				out.AddLayer(l)
+			}
+		}
+
+		sampleCounts := r.SampleCounts()
+		if sampleCounts == nil {
+			// Every pixel received the requested number of samples.
+			sampleCounts = make([]int, cfg.XSize*cfg.YSize)
+			for i := range sampleCounts {
+				sampleCounts[i] = int(cfg.Samples)
+			}
+		}
+		out.AddLayer(output.SampleCountLayer(sampleCounts))
+
+		err = out.Write(canvas)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if aovs := r.AOVs(); aovs != nil && cfg.OutputMode != "multilayer-exr" {
+		writeAOVs(aovs, canvas, objectNames, materialNames, cfg)
+	}
+
+	if cfg.SampleHeatmap != "" {
+		if adaptiveConfig == nil {
+			log.Warnf("Sample heatmap requested but adaptive sampling is disabled")
+		} else {
+			log.Infof("Writing sample heatmap to %s", cfg.SampleHeatmap)
+			out, err := output.NewPNG(cfg.SampleHeatmap)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = out.Write(adaptive.Heatmap(r.SampleCounts(), int(cfg.XSize), int(cfg.YSize), adaptiveConfig.MaxSamples))
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
 	}
 
 	if cfg.Preview {
@@ -231,6 +502,111 @@ func RunAsLeader(ctx context.Context, cfg *config.Config, standalone bool) {
 	}
 }
 
+// resolveLightGroups returns the names of the light groups to render. Light groups are named after emissive materials
+// and the name "all" selects every emissive material in the scene.
+func resolveLightGroups(protoScene *pb_transport.Scene, names []string) ([]string, error) {
+	var lights []string
+	for name, m := range protoScene.GetMaterials() {
+		if m.GetDiffuselight() != nil {
+			lights = append(lights, name)
+		}
+	}
+	slices.Sort(lights)
+
+	var groups []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case name == "all":
+			return lights, nil
+		case !slices.Contains(lights, name):
+			return nil, fmt.Errorf("light group %q is not an emissive material", name)
+		case !slices.Contains(groups, name):
+			groups = append(groups, name)
+		}
+	}
+
+	return groups, nil
+}
+
+// sceneNames returns the sorted names of the objects and materials in the scene.
+func sceneNames(protoScene *pb_transport.Scene) ([]string, []string) {
+	var objects []string
+	for _, t := range protoScene.GetObjects().GetTriangles() {
+		objects = append(objects, t.GetObjectName())
+	}
+	for _, s := range protoScene.GetObjects().GetSpheres() {
+		objects = append(objects, s.GetObjectName())
+	}
+	slices.Sort(objects)
+	objects = slices.Compact(objects)
+
+	materials := slices.Sorted(maps.Keys(protoScene.GetMaterials()))
+
+	return objects, materials
+}
+
+// writeAOVs writes every AOV and light group to its own EXR file named after the output file.
+// Cryptomatte layers are written along with the beauty image to a single file, as compositing applications expect.
+func writeAOVs(aovs *aov.Buffers, canvas image.Image, objectNames []string, materialNames []string, cfg *config.Config) {
+	base := strings.TrimSuffix(cfg.OutputFile, filepath.Ext(cfg.OutputFile))
+
+	for _, l := range aovs.Layers() {
+		if l.IsCryptomatte() {
+			continue
+		}
+
+		fileName := fmt.Sprintf("%s_%s.exr", base, exr.SanitiseName(l.Name()))
+		img := aovs.Image(l)
+
+		// Colour AOVs of spectral renders are in ACEScg like the beauty image.
+		var out output.Output
+		var err error
+		if sampler.IsSpectral(sampler.StringToType(cfg.Sampler)) && l.IsColour() {
+			out, err = output.NewOIIOACES(fileName, &oiio.ACESMetadata{
+				DisplayWindow:    img.Bounds(),
+				DataWindow:       img.Bounds(),
+				PixelAspectRatio: 1.0,
+				ACESVersion:      "ACES 1.3",
+			})
+		} else {
+			out, err = output.NewOIIO(fileName)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Infof("Writing %v AOV to %s", l.Name(), fileName)
+		if err := out.Write(img); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if layers := output.CryptomatteLayers(aovs, objectNames, materialNames); len(layers) > 0 {
+		colourSpace := output.LinearRec709
+		if sampler.IsSpectral(sampler.StringToType(cfg.Sampler)) {
+			colourSpace = output.ACEScg
+		}
+
+		fileName := base + "_cryptomatte.exr"
+		out, err := output.NewMultiLayerEXR(fileName, colourSpace)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, l := range layers {
+			out.AddLayer(l)
+		}
+
+		log.Infof("Writing Cryptomatte layers to %s", fileName)
+		if err := out.Write(canvas); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
 func stringToSamplerType(s string) pb_control.SamplerType {
 	switch s {
 	case "colour":
@@ -243,9 +619,59 @@ func stringToSamplerType(s string) pb_control.SamplerType {
 		return pb_control.SamplerType_WIRE_FRAME
 	case "spectral":
 		return pb_control.SamplerType_SPECTRAL
+	case "bdpt":
+		return pb_control.SamplerType_BDPT
+	case "spectral-bdpt":
+		return pb_control.SamplerType_SPECTRAL_BDPT
+	case "mlt":
+		return pb_control.SamplerType_MLT
+	case "spectral-mlt":
+		return pb_control.SamplerType_SPECTRAL_MLT
+	case "ppm":
+		return pb_control.SamplerType_PPM
+	case "spectral-ppm":
+		return pb_control.SamplerType_SPECTRAL_PPM
 	default:
 		log.Fatalf("unknown sampler type %q", s)
 	}
 
 	return pb_control.SamplerType_SAMPLER_TYPE_UNSPECIFIED
 }
+
+func stringToSampleGenerator(s string) pb_control.SampleGenerator {
+	switch s {
+	case "independent":
+		return pb_control.SampleGenerator_INDEPENDENT
+	case "stratified":
+		return pb_control.SampleGenerator_STRATIFIED
+	case "sobol":
+		return pb_control.SampleGenerator_SOBOL
+	case "halton":
+		return pb_control.SampleGenerator_HALTON
+	case "blue-noise":
+		return pb_control.SampleGenerator_BLUE_NOISE
+	default:
+		log.Fatalf("unknown sample generator %q", s)
+	}
+
+	return pb_control.SampleGenerator_SAMPLE_GENERATOR_UNSPECIFIED
+}
+
+func stringToReconstructionFilter(s string) pb_control.ReconstructionFilter {
+	switch s {
+	case "box":
+		return pb_control.ReconstructionFilter_BOX
+	case "tent":
+		return pb_control.ReconstructionFilter_TENT
+	case "gaussian":
+		return pb_control.ReconstructionFilter_GAUSSIAN
+	case "mitchell":
+		return pb_control.ReconstructionFilter_MITCHELL
+	case "blackman-harris":
+		return pb_control.ReconstructionFilter_BLACKMAN_HARRIS
+	default:
+		log.Fatalf("unknown reconstruction filter %q", s)
+	}
+
+	return pb_control.ReconstructionFilter_RECONSTRUCTION_FILTER_UNSPECIFIED
+}
@@ -19,7 +19,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func setupWorkers(ctx context.Context, cfg *config.Config, protoScene *pb_transport.Scene, textures map[string]*texture.ImageTxt, displacementMaps map[string]*texture.ImageTxt) ([]*render.RemoteWorkerConfig, error) {
+func setupWorkers(ctx context.Context, cfg *config.Config, protoScene *pb_transport.Scene, textures map[string]*texture.ImageTxt, displacementMaps map[string]*texture.ImageTxt, volumeFiles map[string][]byte) ([]*render.RemoteWorkerConfig, error) {
 	jobID := uuid.New().String()
 
 	remoteWorkers := make([]*render.RemoteWorkerConfig, 0)
@@ -45,7 +45,7 @@ func setupWorkers(ctx context.Context, cfg *config.Config, protoScene *pb_transp
 		protoScene.Objects.Triangles = nil
 	}
 
-	assetProvider, assetProviderAddress, err := assetprovider.New(protoScene, textures, displacementMaps, trianglesToStream)
+	assetProvider, assetProviderAddress, err := assetprovider.New(protoScene, textures, displacementMaps, volumeFiles, trianglesToStream)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create asset provider: %w", err)
 	}
@@ -89,7 +89,22 @@ func setupWorkers(ctx context.Context, cfg *config.Config, protoScene *pb_transp
 				Width:  uint32(cfg.XSize),
 				Height: uint32(cfg.YSize),
 			},
-			MaxDepth: uint32(cfg.Depth),
+			MaxDepth:                uint32(cfg.Depth),
+			MaxDiffuseDepth:         uint32(cfg.MaxDiffuseDepth),
+			MaxSpecularDepth:        uint32(cfg.MaxSpecularDepth),
+			MaxTransmissionDepth:    uint32(cfg.MaxTransmissionDepth),
+			MaxVolumeDepth:          uint32(cfg.MaxVolumeDepth),
+			RussianRouletteDepth:    uint32(cfg.RussianRouletteDepth),
+			MltSeed:                 cfg.Seed,
+			MltBootstrapSamples:     uint32(cfg.MLTBootstrapSamples),
+			MltLargeStepProbability: cfg.MLTLargeStepProb,
+			Photons:                 uint32(cfg.Photons),
+			PhotonRadius:            cfg.PhotonRadius,
+			PhotonAlpha:             cfg.PhotonAlpha,
+			SampleGenerator:         stringToSampleGenerator(cfg.SampleGenerator),
+			SampleSeed:              cfg.Seed,
+			Filter:                  stringToReconstructionFilter(cfg.Filter),
+			FilterRadius:            cfg.FilterRadius,
 			BackgroundColor: &pb_control.Vec3{
 				X: 0,
 				Y: 0,
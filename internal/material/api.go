@@ -29,6 +29,19 @@ type Material interface {
 	SetWorld(world SceneGeometry)
 }
 
+// BSDF is implemented by materials whose non-specular scattering cannot be described by the attenuation of their
+// scatter records times ScatteringPDF, such as those whose colour changes with the directions of the light. The
+// ScatteringPDF of these materials returns the density with which the directions of their scatter records are
+// sampled.
+type BSDF interface {
+	// BSDF returns the BSDF times the cosine of the angle between scattered and the normal, for light arriving
+	// along scattered and leaving towards the origin of r.
+	BSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) vec3.Vec3Impl
+	// SpectralBSDF returns the BSDF times the cosine of the angle between scattered and the normal at the given
+	// wavelength.
+	SpectralBSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray, lambda float64) float64
+}
+
 // nonPathLength is a stub for materials that don't need path length calculation
 type nonPathLength struct{}
 
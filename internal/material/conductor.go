@@ -0,0 +1,232 @@
+package material
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/microfacet"
+	"github.com/flynn-nrg/izpi/internal/pdf"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scatterrecord"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ Material = (*Conductor)(nil)
+var _ BSDF = (*Conductor)(nil)
+
+// rgbWavelengths are the wavelengths in nanometres at which the refractive index of conductors is sampled for the
+// red, green and blue channels of RGB renders.
+var rgbWavelengths = vec3.Vec3Impl{X: 630, Y: 532, Z: 465}
+
+// Conductor represents metals whose reflectance follows from their complex refractive index η + ik, tabulated over
+// wavelength. Smooth conductors are perfect mirrors while rough ones reflect light off GGX microfacets.
+type Conductor struct {
+	nonEmitter
+	nonPBR
+	nonPathLength
+	nonWorldSetter
+	eta *spectral.SpectralPowerDistribution
+	k   *spectral.SpectralPowerDistribution
+	// etaRGB and kRGB hold the complex refractive index at each of the RGB wavelengths.
+	etaRGB vec3.Vec3Impl
+	kRGB   vec3.Vec3Impl
+	alpha  float64
+	// film is the thin film coating the surface, if any.
+	film *ThinFilm
+}
+
+// NewConductor returns an instance of a conductor with the given complex refractive index and perceptual roughness,
+// where a roughness of 0 makes a perfect mirror.
+func NewConductor(eta *spectral.SpectralPowerDistribution, k *spectral.SpectralPowerDistribution, roughness float64) *Conductor {
+	c := &Conductor{
+		eta: eta,
+		k:   k,
+		etaRGB: vec3.Vec3Impl{
+			X: eta.Value(rgbWavelengths.X),
+			Y: eta.Value(rgbWavelengths.Y),
+			Z: eta.Value(rgbWavelengths.Z),
+		},
+		kRGB: vec3.Vec3Impl{
+			X: k.Value(rgbWavelengths.X),
+			Y: k.Value(rgbWavelengths.Y),
+			Z: k.Value(rgbWavelengths.Z),
+		},
+	}
+
+	if roughness > 0 {
+		c.alpha = math.Max(microfacet.MinAlpha, roughness*roughness)
+	}
+
+	return c
+}
+
+// SetThinFilm coats the surface of the conductor with a thin film, like the oxide layer of tempered steel or
+// anodised titanium, that colours its reflections.
+func (c *Conductor) SetThinFilm(film *ThinFilm) {
+	c.film = film
+}
+
+// Scatter computes how the ray bounces off the surface of a conductor.
+func (c *Conductor) Scatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.ScatterRecord, bool) {
+	thickness := c.filmThickness(hr.U(), hr.V(), hr.P())
+
+	if c.alpha == 0 {
+		in := vec3.UnitVector(r.Direction())
+		specular := ray.New(hr.P(), reflect(in, hr.Normal()), r.Time())
+		attenuation := c.fresnel(math.Abs(vec3.Dot(in, hr.Normal())), thickness)
+		scatterRecord := scatterrecord.New(specular, true, attenuation, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, nil)
+		return nil, scatterRecord, true
+	}
+
+	pdf := c.pdf(r, hr)
+	scattered := ray.New(hr.P(), pdf.Generate(random), r.Time())
+
+	scatterRecord := scatterrecord.New(nil, false, c.fresnel(1, thickness), vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, pdf)
+	return scattered, scatterRecord, true
+}
+
+// SpectralScatter computes how the ray bounces off the surface of a conductor at the wavelength it carries.
+func (c *Conductor) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.SpectralScatterRecord, bool) {
+	lambda := r.Lambda()
+	thickness := c.filmThickness(hr.U(), hr.V(), hr.P())
+
+	if c.alpha == 0 {
+		in := vec3.UnitVector(r.Direction())
+		cosTheta := math.Abs(vec3.Dot(in, hr.Normal()))
+		specular := ray.NewWithLambda(hr.P(), reflect(in, hr.Normal()), r.Time(), lambda)
+		scatterRecord := scatterrecord.NewSpectralScatterRecord(specular, true, c.spectralFresnel(cosTheta, lambda, thickness), lambda, nil, 0.0, 0.0, nil)
+		setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+			return c.spectralFresnel(cosTheta, lambda, thickness)
+		})
+		return nil, scatterRecord, true
+	}
+
+	pdf := c.pdf(r, hr)
+	scattered := ray.NewWithLambda(hr.P(), pdf.Generate(random), r.Time(), lambda)
+
+	scatterRecord := scatterrecord.NewSpectralScatterRecord(nil, false, c.spectralFresnel(1, lambda, thickness), lambda, nil, 0.0, 0.0, pdf)
+	setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+		return c.spectralFresnel(1, lambda, thickness)
+	})
+	return scattered, scatterRecord, true
+}
+
+// ScatteringPDF returns the density with which the directions of the scatter records of rough conductors are
+// sampled.
+func (c *Conductor) ScatteringPDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64 {
+	if c.alpha == 0 {
+		return 0
+	}
+
+	return c.pdf(r, hr).Value(scattered.Direction())
+}
+
+// BSDF returns the light reflected by a rough conductor towards the origin of r.
+func (c *Conductor) BSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) vec3.Vec3Impl {
+	if c.alpha == 0 {
+		return vec3.Vec3Impl{}
+	}
+
+	wo := vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1)
+	specular, cosD, e := c.microfacets(hr.Normal(), wo, vec3.UnitVector(scattered.Direction()))
+	thickness := c.filmThickness(hr.U(), hr.V(), hr.P())
+	f, f0 := c.fresnel(cosD, thickness), c.fresnel(1, thickness)
+
+	return vec3.Vec3Impl{
+		X: specular * compensated(f.X, f0.X, e),
+		Y: specular * compensated(f.Y, f0.Y, e),
+		Z: specular * compensated(f.Z, f0.Z, e),
+	}
+}
+
+// SpectralBSDF returns the light reflected by a rough conductor towards the origin of r at the given wavelength.
+func (c *Conductor) SpectralBSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray, lambda float64) float64 {
+	if c.alpha == 0 {
+		return 0
+	}
+
+	wo := vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1)
+	specular, cosD, e := c.microfacets(hr.Normal(), wo, vec3.UnitVector(scattered.Direction()))
+
+	thickness := c.filmThickness(hr.U(), hr.V(), hr.P())
+	return specular * compensated(c.spectralFresnel(cosD, lambda, thickness), c.spectralFresnel(1, lambda, thickness), e)
+}
+
+// pdf returns the PDF of the directions in which light arriving along r is reflected.
+func (c *Conductor) pdf(r ray.Ray, hr *hitrecord.HitRecord) pdf.PDF {
+	return pdf.NewGGX(hr.Normal(), vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1), c.alpha)
+}
+
+// microfacets returns the BRDF times the cosine of the angle between wi and the normal for light arriving along wi
+// and leaving along wo off microfacets that reflect all of it. It also returns the cosine of the angle between wo
+// and the microfacet normal, which the Fresnel reflectance depends on, and the directional albedo for wo.
+func (c *Conductor) microfacets(normal vec3.Vec3Impl, wo vec3.Vec3Impl, wi vec3.Vec3Impl) (float64, float64, float64) {
+	cosO := vec3.Dot(wo, normal)
+	cosI := vec3.Dot(wi, normal)
+	if cosO <= 0 || cosI <= 0 {
+		return 0, 1, 1
+	}
+
+	h := vec3.UnitVector(vec3.Add(wo, wi))
+	specular := microfacet.D(vec3.Dot(h, normal), c.alpha) * microfacet.G2(cosO, cosI, c.alpha) / (4 * cosO)
+
+	return specular, vec3.Dot(wo, h), microfacet.DirectionalAlbedo(cosO, c.alpha)
+}
+
+// compensated returns the Fresnel reflectance f scaled to add back the light that is lost to bounces between
+// microfacets like PBR materials do, given the reflectance f0 at normal incidence and the directional albedo e.
+func compensated(f float64, f0 float64, e float64) float64 {
+	return f * (1 + f0*(1-e)/e)
+}
+
+// filmThickness returns the thickness of the thin film coating the conductor at a point of the surface, or 0 if
+// it is uncoated.
+func (c *Conductor) filmThickness(u float64, v float64, p vec3.Vec3Impl) float64 {
+	if c.film == nil {
+		return 0
+	}
+	return c.film.Thickness(u, v, p)
+}
+
+// index returns the complex refractive index of the conductor at the given wavelength.
+func (c *Conductor) index(lambda float64) (float64, float64) {
+	return c.eta.Value(lambda), c.k.Value(lambda)
+}
+
+// fresnel returns the reflectance of each of the RGB channels for light arriving at an angle with the given cosine
+// through a thin film of the given thickness.
+func (c *Conductor) fresnel(cosTheta float64, thickness float64) vec3.Vec3Impl {
+	if c.film != nil {
+		return c.film.rgbReflectance(cosTheta, thickness, 1, c.index)
+	}
+
+	return vec3.Vec3Impl{
+		X: microfacet.FresnelConductor(cosTheta, c.etaRGB.X, c.kRGB.X),
+		Y: microfacet.FresnelConductor(cosTheta, c.etaRGB.Y, c.kRGB.Y),
+		Z: microfacet.FresnelConductor(cosTheta, c.etaRGB.Z, c.kRGB.Z),
+	}
+}
+
+// spectralFresnel returns the reflectance at the given wavelength for light arriving at an angle with the given
+// cosine through a thin film of the given thickness.
+func (c *Conductor) spectralFresnel(cosTheta float64, lambda float64, thickness float64) float64 {
+	eta, k := c.index(lambda)
+	if c.film != nil {
+		return c.film.reflectance(cosTheta, lambda, thickness, 1, eta, k)
+	}
+
+	return microfacet.FresnelConductor(cosTheta, eta, k)
+}
+
+// Albedo returns the reflectance of the conductor at normal incidence.
+func (c *Conductor) Albedo(u float64, v float64, p vec3.Vec3Impl) vec3.Vec3Impl {
+	return c.fresnel(1, c.filmThickness(u, v, p))
+}
+
+// SpectralAlbedo returns the reflectance of the conductor at normal incidence at the given wavelength.
+func (c *Conductor) SpectralAlbedo(u float64, v float64, lambda float64, p vec3.Vec3Impl) float64 {
+	return c.spectralFresnel(1, lambda, c.filmThickness(u, v, p))
+}
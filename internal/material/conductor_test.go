@@ -0,0 +1,124 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// constantSPD returns a spectrum with the same value at every wavelength.
+func constantSPD(v float64) *spectral.SpectralPowerDistribution {
+	return spectral.NewSPD([]float64{380, 750}, []float64{v, v})
+}
+
+func TestConductorSpecular(t *testing.T) {
+	testData := []struct {
+		name   string
+		eta, k float64
+		// want is the reflectance at normal incidence.
+		want float64
+	}{
+		{name: "perfect conductor", eta: 1, k: 1e4, want: 1},
+		{name: "silver", eta: 0.05, k: 3.3, want: (0.95*0.95 + 3.3*3.3) / (1.05*1.05 + 3.3*3.3)},
+		{name: "no absorption", eta: 1.5, k: 0, want: 0.04},
+	}
+
+	hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+	r := ray.NewWithLambda(vec3.Vec3Impl{Z: 1}, vec3.Vec3Impl{Z: -1}, 0, 550)
+	random := fastrandom.NewWithDefaults()
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewConductor(constantSPD(test.eta), constantSPD(test.k), 0)
+
+			_, srec, ok := c.Scatter(r, hr, random)
+			if !ok || !srec.IsSpecular() {
+				t.Fatal("Expected a specular scatter")
+			}
+			if got := srec.Attenuation(); math.Abs(got.X-test.want) > 1e-6 || got.X != got.Y || got.Y != got.Z {
+				t.Errorf("Attenuation() = %v, want %v", got, test.want)
+			}
+			if got := srec.SpecularRay().Direction(); got != (vec3.Vec3Impl{Z: 1}) {
+				t.Errorf("SpecularRay().Direction() = %v, want the mirror direction", got)
+			}
+
+			_, ssrec, ok := c.SpectralScatter(r, hr, random)
+			if !ok || !ssrec.IsSpecular() {
+				t.Fatal("Expected a specular spectral scatter")
+			}
+			if got := ssrec.Attenuation(); math.Abs(got-test.want) > 1e-6 {
+				t.Errorf("spectral Attenuation() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConductorBSDF(t *testing.T) {
+	// Gold reflects red light better than blue light.
+	goldEta := spectral.NewSPD([]float64{450, 550, 650}, []float64{1.38, 0.43, 0.14})
+	goldK := spectral.NewSPD([]float64{450, 550, 650}, []float64{1.91, 2.45, 3.70})
+
+	testData := []struct {
+		name      string
+		eta, k    *spectral.SpectralPowerDistribution
+		roughness float64
+		// wantMin and wantMax bound the fraction of the light that is reflected in the red channel.
+		wantMin, wantMax float64
+		wantTinted       bool
+	}{
+		{name: "glossy perfect conductor", eta: constantSPD(1), k: constantSPD(1e4), roughness: 0.3, wantMin: 0.98, wantMax: 1.02},
+		{name: "rough perfect conductor", eta: constantSPD(1), k: constantSPD(1e4), roughness: 1, wantMin: 0.95, wantMax: 1.02},
+		{name: "gold", eta: goldEta, k: goldK, roughness: 0.4, wantMin: 0.9, wantMax: 1.0, wantTinted: true},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewConductor(test.eta, test.k, test.roughness)
+
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+			r := ray.New(vec3.Vec3Impl{X: -1, Z: 1}, vec3.Vec3Impl{X: 1, Z: -1}, 0)
+
+			random := fastrandom.NewWithDefaults()
+			const n = 100000
+
+			// Estimate the fraction of the light arriving from every direction that is reflected along r.
+			var sum vec3.Vec3Impl
+			for range n {
+				scattered, srec, ok := c.Scatter(r, hr, random)
+				if !ok || srec.IsSpecular() {
+					t.Fatal("Expected a non-specular scatter")
+				}
+
+				pdfVal := srec.PDF().Value(scattered.Direction())
+				if got := c.ScatteringPDF(r, hr, scattered); math.Abs(got-pdfVal) > 1e-9*pdfVal {
+					t.Fatalf("ScatteringPDF() = %v, want the density of the scatter record %v", got, pdfVal)
+				}
+				if pdfVal == 0 {
+					continue
+				}
+
+				sum = vec3.Add(sum, vec3.ScalarDiv(c.BSDF(r, hr, scattered), pdfVal))
+			}
+
+			got := sum.X / n
+			if got < test.wantMin || got > test.wantMax {
+				t.Errorf("reflected fraction = %v, want it within [%v, %v]", got, test.wantMin, test.wantMax)
+			}
+			if tinted := sum.Z < 0.9*sum.X; tinted != test.wantTinted {
+				t.Errorf("reflected fractions %v, want tinted %v", vec3.ScalarDiv(sum, n), test.wantTinted)
+			}
+
+			// The spectral BSDF at the wavelength of a channel matches the RGB one.
+			scattered := ray.New(vec3.Vec3Impl{}, vec3.Vec3Impl{X: 0.8, Y: 0.2, Z: 1}, 0)
+			want := c.BSDF(r, hr, scattered)
+			if got := c.SpectralBSDF(r, hr, scattered, rgbWavelengths.X); math.Abs(got-want.X) > 1e-9 {
+				t.Errorf("SpectralBSDF() = %v, want %v", got, want.X)
+			}
+		})
+	}
+}
@@ -27,8 +27,16 @@ type Dielectric struct {
 	spectralAbsorptionCoeff       texture.SpectralTexture // Spectral absorption coefficient (for spectral rendering)
 	// World reference for path length calculation
 	world SceneGeometry
+	// priority decides which dielectric fills the space where several of them overlap.
+	priority int
+	// film is the thin film coating the surface, if any.
+	film *ThinFilm
 }
 
+// uncoated is the reflectance passed to scatterCommon for surfaces without a thin film, which split the light
+// following Schlick's approximation of the Fresnel equations.
+const uncoated = -1.0
+
 // NewDielectric returns an instance of a dielectric material.
 func NewDielectric(reIdx float64) *Dielectric {
 	return &Dielectric{
@@ -61,9 +69,59 @@ func NewSpectralColoredDielectric(spectralRefIdx texture.SpectralTexture, spectr
 	}
 }
 
+// SetPriority sets the priority of the dielectric. Where dielectrics overlap, such as where the water in a glass
+// meets the glass, the space is filled by the one with the highest priority and the surfaces of the others are
+// ignored. Dielectrics have a priority of 0 unless set.
+func (d *Dielectric) SetPriority(priority int) {
+	d.priority = priority
+}
+
+// SetThinFilm coats the surface of the dielectric with a thin film, like the soap of a bubble, that colours its
+// reflections.
+func (d *Dielectric) SetThinFilm(film *ThinFilm) {
+	d.film = film
+}
+
+// Priority returns the priority of the dielectric.
+func (d *Dielectric) Priority() int {
+	return d.priority
+}
+
+// RefractiveIndex returns the refractive index used by the colour samplers.
+func (d *Dielectric) RefractiveIndex() float64 {
+	return d.refIdx
+}
+
+// SpectralRefractiveIndex returns the refractive index at the given wavelength.
+func (d *Dielectric) SpectralRefractiveIndex(lambda float64) float64 {
+	if d.spectralRefIdx == nil {
+		return d.refIdx
+	}
+	return d.spectralRefIdx.Value(0, 0, lambda, vec3.Vec3Impl{})
+}
+
+// Absorption returns the absorption coefficients per unit of distance of the inside of the dielectric used by the
+// colour samplers.
+func (d *Dielectric) Absorption() vec3.Vec3Impl {
+	if !d.computeBeerLambertAttenuation {
+		return vec3.Vec3Impl{}
+	}
+	return d.absorptionCoeff
+}
+
+// SpectralAbsorption returns the absorption coefficient per unit of distance of the inside of the dielectric at
+// the given wavelength.
+func (d *Dielectric) SpectralAbsorption(lambda float64) float64 {
+	if d.spectralAbsorptionCoeff == nil {
+		return 0
+	}
+	return d.spectralAbsorptionCoeff.Value(0, 0, lambda, vec3.Vec3Impl{})
+}
+
 // scatterCommon contains the common scattering logic for both RGB and spectral rendering
+// The light is reflected with the probability given by filmReflectance unless it is uncoated.
 // Returns the scattered ray and a boolean indicating if it was reflected (true) or transmitted (false)
-func (d *Dielectric) scatterCommon(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG, refIdx float64) (*ray.RayImpl, bool, bool) {
+func (d *Dielectric) scatterCommon(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG, refIdx float64, filmReflectance float64) (*ray.RayImpl, bool, bool) {
 	var niOverNt float64
 	var cosine float64
 	var reflectProb float64
@@ -86,7 +144,11 @@ func (d *Dielectric) scatterCommon(r ray.Ray, hr *hitrecord.HitRecord, random *f
 	}
 
 	if refracted, ok = refract(r.Direction(), outwardNormal, niOverNt); ok {
-		reflectProb = schlick(cosine, refIdx)
+		if filmReflectance == uncoated {
+			reflectProb = schlick(cosine, refIdx)
+		} else {
+			reflectProb = filmReflectance
+		}
 	} else {
 		reflectProb = 1.0
 	}
@@ -101,6 +163,65 @@ func (d *Dielectric) scatterCommon(r ray.Ray, hr *hitrecord.HitRecord, random *f
 	return scattered, isReflected, true
 }
 
+// incidence returns the cosine of the angle at which r arrives at the surface and whether it enters the dielectric.
+func incidence(r ray.Ray, hr *hitrecord.HitRecord) (float64, bool) {
+	cosine := vec3.Dot(r.Direction(), hr.Normal()) / r.Direction().Length()
+	return math.Abs(cosine), cosine <= 0
+}
+
+// rgbFilm returns the reflectance of each of the RGB channels of the thin film coating the dielectric and their
+// average, which is the probability with which light is reflected, given the refractive index of the medium on the
+// outside. The probability is uncoated if there is no film.
+func (d *Dielectric) rgbFilm(r ray.Ray, hr *hitrecord.HitRecord, outside float64) (vec3.Vec3Impl, float64) {
+	if d.film == nil {
+		return vec3.Vec3Impl{}, uncoated
+	}
+
+	cosTheta, entering := incidence(r, hr)
+	from, to := d.refIdx, outside
+	if entering {
+		from, to = to, from
+	}
+
+	reflectance := d.film.rgbReflectance(cosTheta, d.film.Thickness(hr.U(), hr.V(), hr.P()), from, func(float64) (float64, float64) {
+		return to, 0
+	})
+	return reflectance, (reflectance.X + reflectance.Y + reflectance.Z) / 3
+}
+
+// spectralFilm returns a function that computes the reflectance of the thin film coating the dielectric at a
+// wavelength, given the refractive indices of the dielectric and of the medium on the outside at every wavelength.
+func (d *Dielectric) spectralFilm(r ray.Ray, hr *hitrecord.HitRecord, inside func(lambda float64) float64, outside func(lambda float64) float64) func(lambda float64) float64 {
+	cosTheta, entering := incidence(r, hr)
+	thickness := d.film.Thickness(hr.U(), hr.V(), hr.P())
+
+	return func(lambda float64) float64 {
+		from, to := inside(lambda), outside(lambda)
+		if entering {
+			from, to = to, from
+		}
+		return d.film.reflectance(cosTheta, lambda, thickness, from, to, 0)
+	}
+}
+
+// splitWeight returns the factor by which light is scaled when it is reflected or refracted by a surface that
+// reflects the given fraction of it, given the probability p with which the path was reflected.
+func splitWeight(reflectance float64, p float64, isReflected bool) float64 {
+	if isReflected {
+		return reflectance / p
+	}
+	return (1 - reflectance) / (1 - p)
+}
+
+// rgbSplitWeight is splitWeight for each of the RGB channels.
+func rgbSplitWeight(reflectance vec3.Vec3Impl, p float64, isReflected bool) vec3.Vec3Impl {
+	return vec3.Vec3Impl{
+		X: splitWeight(reflectance.X, p, isReflected),
+		Y: splitWeight(reflectance.Y, p, isReflected),
+		Z: splitWeight(reflectance.Z, p, isReflected),
+	}
+}
+
 // calculateBeerLambertAttenuation calculates the Beer-Lambert law attenuation
 // I = I₀ * exp(-α * d) where α is the absorption coefficient and d is the path length
 func (d *Dielectric) calculateBeerLambertAttenuation(pathLength float64, lambda float64, u, v float64, p vec3.Vec3Impl) float64 {
@@ -154,7 +275,8 @@ func (d *Dielectric) calculatePathLength(r ray.Ray, hr *hitrecord.HitRecord, sca
 
 // Scatter computes how the ray bounces off the surface of a dielectric material.
 func (d *Dielectric) Scatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.ScatterRecord, bool) {
-	scattered, isReflected, ok := d.scatterCommon(r, hr, random, d.refIdx)
+	reflectance, p := d.rgbFilm(r, hr, 1)
+	scattered, isReflected, ok := d.scatterCommon(r, hr, random, d.refIdx, p)
 	if !ok {
 		return nil, nil, false
 	}
@@ -176,6 +298,11 @@ func (d *Dielectric) Scatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastran
 		attenuation = vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}
 	}
 
+	// The film reflects some colours better than others.
+	if d.film != nil {
+		attenuation = vec3.Mul(attenuation, rgbSplitWeight(reflectance, p, isReflected))
+	}
+
 	scatterRecord := scatterrecord.New(scattered, true, attenuation, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, nil)
 	return scattered, scatterRecord, true
 }
@@ -185,17 +312,28 @@ func (d *Dielectric) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random
 	lambda := r.Lambda()
 	refIdx := d.spectralRefIdx.Value(hr.U(), hr.V(), lambda, hr.P())
 
-	scattered, isReflected, ok := d.scatterCommon(r, hr, random, refIdx)
+	p := uncoated
+	var film func(lambda float64) float64
+	if d.film != nil {
+		film = d.spectralFilm(r, hr, func(lambda float64) float64 {
+			return d.spectralRefIdx.Value(hr.U(), hr.V(), lambda, hr.P())
+		}, func(float64) float64 {
+			return 1
+		})
+		p = film(lambda)
+	}
+
+	scattered, isReflected, ok := d.scatterCommon(r, hr, random, refIdx, p)
 	if !ok {
 		return nil, nil, false
 	}
 
 	// Calculate Beer-Lambert attenuation for spectral rendering
-	var albedo float64
+	var albedo, pathLength float64
 	if !isReflected {
 		// Only apply absorption to transmitted rays, not reflected rays
 		// Use the new path length calculation with world geometry
-		pathLength := d.calculatePathLength(r, hr, scattered, d.world)
+		pathLength = d.calculatePathLength(r, hr, scattered, d.world)
 		albedo = d.calculateBeerLambertAttenuation(pathLength, lambda, hr.U(), hr.V(), hr.P())
 	} else {
 		// No absorption for reflected rays
@@ -203,6 +341,89 @@ func (d *Dielectric) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random
 	}
 
 	scatterRecord := scatterrecord.NewSpectralScatterRecord(scattered, true, albedo, lambda, nil, 0.0, 0.0, nil)
+
+	// The other wavelengths traced along r follow the same path unless the refractive index varies with the
+	// wavelength, which disperses them.
+	if wavelengths, ok := r.Wavelengths(); ok {
+		for _, l := range wavelengths[1:] {
+			if d.spectralRefIdx.Value(hr.U(), hr.V(), l, hr.P()) != refIdx {
+				scatterRecord.SetDispersive()
+				return scattered, scatterRecord, true
+			}
+		}
+	}
+	if film != nil {
+		// The film reflects some wavelengths better than the one that picked the path.
+		setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+			weight := splitWeight(film(lambda), p, isReflected)
+			if isReflected {
+				return weight
+			}
+			return weight * d.calculateBeerLambertAttenuation(pathLength, lambda, hr.U(), hr.V(), hr.P())
+		})
+	} else if !isReflected {
+		setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+			return d.calculateBeerLambertAttenuation(pathLength, lambda, hr.U(), hr.V(), hr.P())
+		})
+	}
+
+	return scattered, scatterRecord, true
+}
+
+// ScatterNested computes how r is reflected or refracted at the interface between the dielectric and another one,
+// with refractive index outside, on the other side of the surface. Unlike Scatter, it leaves the absorption within
+// dielectrics to the caller, which follows the path from one interface to the next.
+func (d *Dielectric) ScatterNested(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG, outside float64) (*ray.RayImpl, *scatterrecord.ScatterRecord, bool) {
+	reflectance, p := d.rgbFilm(r, hr, outside)
+	scattered, isReflected, ok := d.scatterCommon(r, hr, random, d.refIdx/outside, p)
+	if !ok {
+		return nil, nil, false
+	}
+
+	attenuation := vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}
+	if d.film != nil {
+		attenuation = rgbSplitWeight(reflectance, p, isReflected)
+	}
+
+	scatterRecord := scatterrecord.New(scattered, true, attenuation, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, nil)
+	return scattered, scatterRecord, true
+}
+
+// SpectralScatterNested is the spectral counterpart of ScatterNested, given the refractive index of the dielectric
+// on the other side of the surface at every wavelength.
+func (d *Dielectric) SpectralScatterNested(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG, outside func(lambda float64) float64) (*ray.RayImpl, *scatterrecord.SpectralScatterRecord, bool) {
+	lambda := r.Lambda()
+	eta := d.SpectralRefractiveIndex(lambda) / outside(lambda)
+
+	p := uncoated
+	var film func(lambda float64) float64
+	if d.film != nil {
+		film = d.spectralFilm(r, hr, d.SpectralRefractiveIndex, outside)
+		p = film(lambda)
+	}
+
+	scattered, isReflected, ok := d.scatterCommon(r, hr, random, eta, p)
+	if !ok {
+		return nil, nil, false
+	}
+
+	scatterRecord := scatterrecord.NewSpectralScatterRecord(scattered, true, 1.0, lambda, nil, 0.0, 0.0, nil)
+
+	// The other wavelengths follow the same path unless the relative refractive index varies with the wavelength.
+	if wavelengths, ok := r.Wavelengths(); ok {
+		for _, l := range wavelengths[1:] {
+			if d.SpectralRefractiveIndex(l)/outside(l) != eta {
+				scatterRecord.SetDispersive()
+				break
+			}
+		}
+	}
+	if film != nil && !scatterRecord.Dispersive() {
+		setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+			return splitWeight(film(lambda), p, isReflected)
+		})
+	}
+
 	return scattered, scatterRecord, true
 }
 
@@ -1,11 +1,13 @@
 package material
 
 import (
+	"math"
 	"testing"
 
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/hitrecord"
 	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/texture"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
@@ -215,6 +217,153 @@ func TestSpectralColoredGlassScattering(t *testing.T) {
 	}
 }
 
+func TestSpectralScatterHeroWavelengths(t *testing.T) {
+	testData := []struct {
+		name           string
+		refIdx         texture.SpectralTexture
+		wantDispersive bool
+	}{
+		{
+			name:   "constant refractive index",
+			refIdx: texture.NewSpectralNeutral(1.5),
+		},
+		{
+			name:           "refractive index varies with the wavelength",
+			refIdx:         texture.NewSpectralConstant(1.5, 550.0, 50.0),
+			wantDispersive: true,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			dielectric := NewSpectralColoredDielectric(test.refIdx, texture.NewSpectralConstant(0.5, 480.0, 60.0))
+			dielectric.SetWorld(&mockSceneGeometry{})
+
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: 1.0}, vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: 1.0})
+			r := ray.New(vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: -1.0}, vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: 1.0}, 0.0)
+			wavelengths := spectral.Wavelengths{480, 572.5, 665, 387.5}
+			r.SetWavelengths(wavelengths)
+
+			random := fastrandom.New(12345, 4294967296, 1664525, 1013904223)
+			for range 100 {
+				_, scatterRecord, ok := dielectric.SpectralScatter(r, hr, random)
+				if !ok {
+					t.Fatal("Expected spectral scattering to succeed")
+				}
+
+				if got := scatterRecord.Dispersive(); got != test.wantDispersive {
+					t.Fatalf("Dispersive() = %v, want %v", got, test.wantDispersive)
+				}
+
+				attenuations := scatterRecord.Attenuations()
+				if attenuations[0] != scatterRecord.Attenuation() {
+					t.Errorf("Attenuation at the hero wavelength %v, want %v", attenuations[0], scatterRecord.Attenuation())
+				}
+				if test.wantDispersive || attenuations[0] == 1.0 {
+					continue
+				}
+
+				// Light at the other wavelengths is absorbed less, as they are further from the absorption peak.
+				for i, a := range attenuations[1:] {
+					if a <= attenuations[0] || a > 1.0 {
+						t.Errorf("Attenuation at %vnm is %v, want it within (%v, 1]", wavelengths[i+1], a, attenuations[0])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScatterNested(t *testing.T) {
+	testData := []struct {
+		name    string
+		outside float64
+		// wantSin is the sine of the angle of the transmitted ray with the normal.
+		wantSin float64
+	}{
+		{name: "glass in air", outside: 1.0, wantSin: math.Sqrt(0.5) / 1.5},
+		{name: "glass in water", outside: 1.33, wantSin: math.Sqrt(0.5) * 1.33 / 1.5},
+		{name: "matching refractive indices", outside: 1.5, wantSin: math.Sqrt(0.5)},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			dielectric := NewColoredDielectric(1.5, vec3.Vec3Impl{X: 0.1, Y: 0.2, Z: 0.3})
+			normal := vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: -1.0}
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, normal)
+			r := ray.New(vec3.Vec3Impl{X: -1.0, Y: 0.0, Z: -1.0}, vec3.Vec3Impl{X: 1.0, Y: 0.0, Z: 1.0}, 0.0)
+
+			random := fastrandom.New(12345, 4294967296, 1664525, 1013904223)
+			transmitted := false
+			for range 100 {
+				scattered, scatterRecord, ok := dielectric.ScatterNested(r, hr, random, test.outside)
+				if !ok {
+					t.Fatal("Expected scattering to succeed")
+				}
+
+				// Absorption within the dielectric is left to the caller.
+				if got := scatterRecord.Attenuation(); got != (vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}) {
+					t.Errorf("Attenuation() = %v, want 1", got)
+				}
+
+				d := vec3.UnitVector(scattered.Direction())
+				if vec3.Dot(d, normal) > 0 {
+					continue
+				}
+
+				transmitted = true
+				if got := math.Abs(d.X); abs(got-test.wantSin) > 1e-6 {
+					t.Errorf("sine of the refracted angle = %v, want %v", got, test.wantSin)
+				}
+			}
+
+			if !transmitted {
+				t.Error("Expected some rays to be transmitted")
+			}
+		})
+	}
+}
+
+func TestSpectralScatterNested(t *testing.T) {
+	testData := []struct {
+		name           string
+		refIdx         texture.SpectralTexture
+		outside        func(lambda float64) float64
+		wantDispersive bool
+	}{
+		{
+			name:    "constant refractive indices",
+			refIdx:  texture.NewSpectralNeutral(1.5),
+			outside: func(float64) float64 { return 1.33 },
+		},
+		{
+			name:           "refractive index of the outside varies with the wavelength",
+			refIdx:         texture.NewSpectralNeutral(1.5),
+			outside:        func(lambda float64) float64 { return 1.33 - (lambda-550)*1e-4 },
+			wantDispersive: true,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			dielectric := NewSpectralDielectric(test.refIdx, false)
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: -1.0})
+			r := ray.New(vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: -1.0}, vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: 1.0}, 0.0)
+			r.SetWavelengths(spectral.Wavelengths{480, 572.5, 665, 387.5})
+
+			random := fastrandom.New(12345, 4294967296, 1664525, 1013904223)
+			_, scatterRecord, ok := dielectric.SpectralScatterNested(r, hr, random, test.outside)
+			if !ok {
+				t.Fatal("Expected spectral scattering to succeed")
+			}
+
+			if got := scatterRecord.Dispersive(); got != test.wantDispersive {
+				t.Errorf("Dispersive() = %v, want %v", got, test.wantDispersive)
+			}
+		})
+	}
+}
+
 // Helper function for floating point comparison
 func abs(x float64) float64 {
 	if x < 0 {
@@ -77,5 +77,8 @@ func (dl *DiffuseLight) Albedo(u float64, v float64, p vec3.Vec3Impl) vec3.Vec3I
 
 // SpectralAlbedo returns the spectral albedo at the given wavelength.
 func (dl *DiffuseLight) SpectralAlbedo(u float64, v float64, lambda float64, p vec3.Vec3Impl) float64 {
+	if dl.spectralEmit != nil {
+		return dl.spectralEmit.Value(u, v, lambda, p)
+	}
 	return dl.emit.Value(u, v, p).X // Use red component as approximation
 }
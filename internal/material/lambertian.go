@@ -64,6 +64,9 @@ func (l *Lambertian) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random
 	lambda := r.Lambda()
 	albedo := l.spectralAlbedo.Value(hr.U(), hr.V(), lambda, hr.P())
 	scatterRecord := scatterrecord.NewSpectralScatterRecord(nil, false, albedo, lambda, nil, 0.0, 0.0, pdf)
+	setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+		return l.spectralAlbedo.Value(hr.U(), hr.V(), lambda, hr.P())
+	})
 
 	return scattered, scatterRecord, true
 }
@@ -4,6 +4,9 @@ import (
 	"math"
 
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scatterrecord"
+	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
@@ -41,3 +44,18 @@ func schlick(cosine float64, refIdx float64) float64 {
 	r0 = r0 * r0
 	return r0 + (1.0-r0)*math.Pow((1.0-cosine), 5)
 }
+
+// setHeroAttenuations sets the attenuations of srec to the values of f at every wavelength traced along r by hero
+// wavelength sampling. Rays that carry a single wavelength are left with the attenuation at that wavelength.
+func setHeroAttenuations(r ray.Ray, srec *scatterrecord.SpectralScatterRecord, f func(lambda float64) float64) {
+	wavelengths, ok := r.Wavelengths()
+	if !ok {
+		return
+	}
+
+	var attenuations [spectral.NumHeroWavelengths]float64
+	for i, lambda := range wavelengths {
+		attenuations[i] = f(lambda)
+	}
+	srec.SetAttenuations(attenuations)
+}
@@ -0,0 +1,66 @@
+package material
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scatterrecord"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ Material = (*MediumBoundary)(nil)
+
+// MediumBoundary is the material of the surfaces that enclose a participating medium. The surfaces themselves are
+// invisible: rays that reach them from outside enter the medium and rays that reach them from inside leave it.
+// The normals of the surfaces must point out of the medium.
+//
+// Samplers that do not trace media see the boundary as a surface that lets light through unchanged.
+type MediumBoundary struct {
+	nonPBR
+	nonEmitter
+	nonPathLength
+	nonWorldSetter
+	medium *medium.Medium
+}
+
+// NewMediumBoundary returns the material of the boundary of the given medium.
+func NewMediumBoundary(m *medium.Medium) *MediumBoundary {
+	return &MediumBoundary{
+		medium: m,
+	}
+}
+
+// Medium returns the medium enclosed by the boundary.
+func (mb *MediumBoundary) Medium() *medium.Medium {
+	return mb.medium
+}
+
+// Scatter continues r through the boundary.
+func (mb *MediumBoundary) Scatter(r ray.Ray, hr *hitrecord.HitRecord, _ *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.ScatterRecord, bool) {
+	scattered := ray.NewWithLambda(hr.P(), r.Direction(), r.Time(), r.Lambda())
+	scatterRecord := scatterrecord.New(scattered, true, vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, nil)
+	return scattered, scatterRecord, true
+}
+
+// SpectralScatter continues r through the boundary at every wavelength it carries.
+func (mb *MediumBoundary) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, _ *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.SpectralScatterRecord, bool) {
+	scattered := ray.NewWithLambda(hr.P(), r.Direction(), r.Time(), r.Lambda())
+	scatterRecord := scatterrecord.NewSpectralScatterRecord(scattered, true, 1.0, r.Lambda(), nil, 0.0, 0.0, nil)
+	return scattered, scatterRecord, true
+}
+
+// ScatteringPDF returns zero because the boundary only lets light through along the direction it arrives from.
+func (mb *MediumBoundary) ScatteringPDF(_ ray.Ray, _ *hitrecord.HitRecord, _ ray.Ray) float64 {
+	return 0
+}
+
+func (mb *MediumBoundary) Albedo(_ float64, _ float64, _ vec3.Vec3Impl) vec3.Vec3Impl {
+	return vec3.Vec3Impl{}
+}
+
+// SpectralAlbedo returns the spectral albedo at the given wavelength.
+func (mb *MediumBoundary) SpectralAlbedo(_ float64, _ float64, _ float64, _ vec3.Vec3Impl) float64 {
+	return 0
+}
@@ -5,7 +5,7 @@ import (
 
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/hitrecord"
-	"github.com/flynn-nrg/izpi/internal/onb"
+	"github.com/flynn-nrg/izpi/internal/microfacet"
 	"github.com/flynn-nrg/izpi/internal/pdf"
 	"github.com/flynn-nrg/izpi/internal/ray"
 	"github.com/flynn-nrg/izpi/internal/scatterrecord"
@@ -15,6 +15,7 @@ import (
 
 // Ensure interface compliance.
 var _ Material = (*PBR)(nil)
+var _ BSDF = (*PBR)(nil)
 
 // PBR represents a physically based rendering material.
 type PBR struct {
@@ -55,140 +56,84 @@ func NewPBRWithSpectralAlbedo(albedo texture.Texture, spectralAlbedo texture.Spe
 	}
 }
 
+// dielectricF0 is the reflectance at normal incidence of the non-metallic part of PBR materials.
+const dielectricF0 = 0.04
+
 // Scatter computes how the ray bounces off the surface of a PBR material.
+// Light is reflected by a GGX microfacet specular lobe over a Lambertian diffuse lobe, whose directions are
+// sampled in proportion to how much light each is expected to reflect. BSDF gives the light that is scattered.
 func (pbr *PBR) Scatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.ScatterRecord, bool) {
 	albedo := pbr.albedo.Value(hr.U(), hr.V(), hr.P())
+	s := pbr.surface(hr)
 
-	// Handle normal map - convert from tangent space to world space
-	var normal vec3.Vec3Impl
-
-	if pbr.normalMap != nil {
-		normalAtUV := pbr.normalMap.Value(hr.U(), hr.V(), hr.P())
-		tangentNormal := vec3.Vec3Impl{
-			X: 2.0*normalAtUV.X - 1.0,
-			Y: 2.0*normalAtUV.Y - 1.0,
-			Z: normalAtUV.Z,
-		}
-
-		n := hr.Normal()
-		t := vec3.Cross(n, vec3.Vec3Impl{X: 0, Y: 1, Z: 0})
-		if vec3.Dot(t, t) < 0.001 {
-			t = vec3.Cross(n, vec3.Vec3Impl{X: 1, Y: 0, Z: 0})
-		}
-
-		t = t.MakeUnitVector()
-
-		b := vec3.Cross(n, t).MakeUnitVector()
-
-		// Transform normal from tangent space to world space
-		normal = vec3.Vec3Impl{
-			X: t.X*tangentNormal.X + b.X*tangentNormal.Y + n.X*tangentNormal.Z,
-			Y: t.Y*tangentNormal.X + b.Y*tangentNormal.Y + n.Y*tangentNormal.Z,
-			Z: t.Z*tangentNormal.X + b.Z*tangentNormal.Y + n.Z*tangentNormal.Z,
-		}.MakeUnitVector()
-	} else {
-		normal = hr.Normal()
-	}
+	pdf := s.pdf(r, (albedo.X+albedo.Y+albedo.Z)/3.0)
+	scattered := ray.New(hr.P(), pdf.Generate(random), r.Time())
 
-	var roughness vec3.Vec3Impl
-	if pbr.roughness != nil {
-		roughness = pbr.roughness.Value(hr.U(), hr.V(), hr.P())
-	} else {
-		roughness = vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5} // Default to medium roughness
-	}
-
-	var metalness vec3.Vec3Impl
-	if pbr.metalness != nil {
-		metalness = pbr.metalness.Value(hr.U(), hr.V(), hr.P())
-	} else {
-		metalness = vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: 0.0} // Default to non-metallic
-	}
-
-	// Calculate average values
-	roughnessValue := (roughness.X + roughness.Y + roughness.Z) / 3.0
-	metalnessValue := (metalness.X + metalness.Y + metalness.Z) / 3.0
-
-	// Create ONB for local space calculations
-	uvw := onb.New()
-	uvw.BuildFromW(normal)
-
-	// Calculate reflection vector for specular component
-	reflected := reflect(vec3.UnitVector(r.Direction()), normal)
-
-	// Balanced PBR scattering logic using roughness to control specular probability
-	var finalDir vec3.Vec3Impl
-	var isSpecular bool
+	scatterRecord := scatterrecord.New(nil, false, albedo, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, pdf)
+	return scattered, scatterRecord, true
+}
 
-	// Calculate Fresnel effect (simplified)
-	cosTheta := math.Abs(vec3.Dot(vec3.UnitVector(r.Direction()), normal))
-	fresnel := 0.04 + (1.0-0.04)*math.Pow(1.0-cosTheta, 5.0)
+// SpectralScatter computes how the ray bounces off the surface of a PBR material with spectral properties.
+func (pbr *PBR) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.SpectralScatterRecord, bool) {
+	lambda := r.Lambda()
+	albedo := pbr.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+	s := pbr.surface(hr)
 
-	// Adjust fresnel based on metalness
-	fresnel = fresnel + (metalnessValue * 0.5)
+	pdf := s.pdf(r, albedo)
+	scattered := ray.NewWithLambda(hr.P(), pdf.Generate(random), r.Time(), lambda)
 
-	// Use roughness to control specular probability
-	// Lower roughness = higher chance of specular reflection
-	specularProbability := fresnel * (1.0 - roughnessValue)
+	scatterRecord := scatterrecord.NewSpectralScatterRecord(nil, false, albedo, lambda, nil, 0.0, 0.0, pdf)
+	setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+		return pbr.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+	})
+	return scattered, scatterRecord, true
+}
 
-	if random.Float64() < specularProbability {
-		// Specular reflection
-		roughnessFactor := math.Max(0.01, roughnessValue*0.3)
-		randomDir := randomInUnitSphere(random)
-		specularDir := vec3.Add(reflected, vec3.ScalarMul(randomDir, roughnessFactor))
-		finalDir = vec3.UnitVector(specularDir)
-		isSpecular = true
+// ScatteringPDF returns the density with which the directions of the scatter records of PBR materials are sampled.
+func (pbr *PBR) ScatteringPDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64 {
+	var albedo float64
+	if lambda := r.Lambda(); lambda != 0 {
+		albedo = pbr.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
 	} else {
-		// Diffuse reflection
-		diffuseDir := uvw.Local(vec3.RandomCosineDirection(random))
-		finalDir = vec3.UnitVector(diffuseDir)
-		isSpecular = false
+		a := pbr.albedo.Value(hr.U(), hr.V(), hr.P())
+		albedo = (a.X + a.Y + a.Z) / 3.0
 	}
 
-	scattered := ray.New(hr.P(), finalDir, r.Time())
-
-	// Create PDF for importance sampling
-	// TODO: Use a more accurate PDF for PBR materials.
-	pdf := pdf.NewCosine(normal)
+	return pbr.surface(hr).pdf(r, albedo).Value(scattered.Direction())
+}
 
-	scatterRecord := scatterrecord.New(scattered, isSpecular, albedo, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, pdf)
-	return scattered, scatterRecord, true
+// BSDF returns the light reflected by the specular and diffuse lobes towards the origin of r.
+func (pbr *PBR) BSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) vec3.Vec3Impl {
+	albedo := pbr.albedo.Value(hr.U(), hr.V(), hr.P())
+	s := pbr.surface(hr)
+	wo := vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1)
+	wi := vec3.UnitVector(scattered.Direction())
+
+	return vec3.Vec3Impl{
+		X: s.reflectance(wo, wi, albedo.X),
+		Y: s.reflectance(wo, wi, albedo.Y),
+		Z: s.reflectance(wo, wi, albedo.Z),
+	}
 }
 
-// SpectralScatter computes how the ray bounces off the surface of a PBR material with spectral properties.
-func (pbr *PBR) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.SpectralScatterRecord, bool) {
-	// Use spectral albedo if available, otherwise fall back to RGB albedo
-	lambda := r.Lambda()
+// SpectralBSDF returns the light reflected by the specular and diffuse lobes towards the origin of r at the given
+// wavelength.
+func (pbr *PBR) SpectralBSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray, lambda float64) float64 {
 	albedo := pbr.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+	wo := vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1)
 
-	// Handle normal map - convert from tangent space to world space
-	var normal vec3.Vec3Impl
-	if pbr.normalMap != nil {
-		normalAtUV := pbr.normalMap.Value(hr.U(), hr.V(), hr.P())
-		tangentNormal := vec3.Vec3Impl{
-			X: 2.0*normalAtUV.X - 1.0,
-			Y: 2.0*normalAtUV.Y - 1.0,
-			Z: normalAtUV.Z,
-		}
-
-		n := hr.Normal()
-		t := vec3.Cross(n, vec3.Vec3Impl{X: 0, Y: 1, Z: 0})
-		if vec3.Dot(t, t) < 0.001 {
-			t = vec3.Cross(n, vec3.Vec3Impl{X: 1, Y: 0, Z: 0})
-		}
-
-		t = t.MakeUnitVector()
-		b := vec3.Cross(n, t).MakeUnitVector()
-
-		// Transform normal from tangent space to world space
-		normal = vec3.Vec3Impl{
-			X: t.X*tangentNormal.X + b.X*tangentNormal.Y + n.X*tangentNormal.Z,
-			Y: t.Y*tangentNormal.X + b.Y*tangentNormal.Y + n.Y*tangentNormal.Z,
-			Z: t.Z*tangentNormal.X + b.Z*tangentNormal.Y + n.Z*tangentNormal.Z,
-		}.MakeUnitVector()
-	} else {
-		normal = hr.Normal()
-	}
+	return pbr.surface(hr).reflectance(wo, vec3.UnitVector(scattered.Direction()), albedo)
+}
 
+// pbrSurface holds the parameters of a PBR material at a point of its surface.
+type pbrSurface struct {
+	normal    vec3.Vec3Impl
+	alpha     float64
+	metalness float64
+}
+
+// surface returns the parameters of the material at the intersection described by hr.
+func (pbr *PBR) surface(hr *hitrecord.HitRecord) pbrSurface {
 	var roughness vec3.Vec3Impl
 	if pbr.roughness != nil {
 		roughness = pbr.roughness.Value(hr.U(), hr.V(), hr.P())
@@ -203,73 +148,96 @@ func (pbr *PBR) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random *fast
 		metalness = vec3.Vec3Impl{X: 0.0, Y: 0.0, Z: 0.0} // Default to non-metallic
 	}
 
-	// Calculate average values
+	// Roughness is perceptually linear, so the width of the distribution of microfacets grows with its square.
 	roughnessValue := (roughness.X + roughness.Y + roughness.Z) / 3.0
-	metalnessValue := (metalness.X + metalness.Y + metalness.Z) / 3.0
-
-	// Create ONB for local space calculations
-	uvw := onb.New()
-	uvw.BuildFromW(normal)
 
-	// Calculate reflection vector for specular component
-	reflected := reflect(vec3.UnitVector(r.Direction()), normal)
+	return pbrSurface{
+		normal:    shadingNormal(pbr.normalMap, hr),
+		alpha:     math.Max(microfacet.MinAlpha, roughnessValue*roughnessValue),
+		metalness: math.Max(0, math.Min(1, (metalness.X+metalness.Y+metalness.Z)/3.0)),
+	}
+}
 
-	// Balanced PBR scattering logic using roughness to control specular probability
-	var finalDir vec3.Vec3Impl
-	var isSpecular bool
+// shadingNormal returns the normal at the intersection described by hr, perturbed by normalMap if it is not nil.
+func shadingNormal(normalMap texture.Texture, hr *hitrecord.HitRecord) vec3.Vec3Impl {
+	if normalMap == nil {
+		return hr.Normal()
+	}
 
-	// Calculate Fresnel effect (simplified)
-	cosTheta := math.Abs(vec3.Dot(vec3.UnitVector(r.Direction()), normal))
-	fresnel := 0.04 + (1.0-0.04)*math.Pow(1.0-cosTheta, 5.0)
+	// Convert the normal from tangent space to world space.
+	normalAtUV := normalMap.Value(hr.U(), hr.V(), hr.P())
+	tangentNormal := vec3.Vec3Impl{
+		X: 2.0*normalAtUV.X - 1.0,
+		Y: 2.0*normalAtUV.Y - 1.0,
+		Z: normalAtUV.Z,
+	}
 
-	// Adjust fresnel based on metalness
-	fresnel = fresnel + (metalnessValue * 0.5)
+	n := hr.Normal()
+	t := tangent(n)
+	b := vec3.Cross(n, t).MakeUnitVector()
 
-	// Use roughness to control specular probability
-	// Lower roughness = higher chance of specular reflection
-	specularProbability := fresnel * (1.0 - roughnessValue)
+	return vec3.Vec3Impl{
+		X: t.X*tangentNormal.X + b.X*tangentNormal.Y + n.X*tangentNormal.Z,
+		Y: t.Y*tangentNormal.X + b.Y*tangentNormal.Y + n.Y*tangentNormal.Z,
+		Z: t.Z*tangentNormal.X + b.Z*tangentNormal.Y + n.Z*tangentNormal.Z,
+	}.MakeUnitVector()
+}
 
-	if random.Float64() < specularProbability {
-		// Specular reflection
-		roughnessFactor := math.Max(0.01, roughnessValue*0.3)
-		randomDir := randomInUnitSphere(random)
-		specularDir := vec3.Add(reflected, vec3.ScalarMul(randomDir, roughnessFactor))
-		finalDir = vec3.UnitVector(specularDir)
-		isSpecular = true
-	} else {
-		// Diffuse reflection
-		diffuseDir := uvw.Local(vec3.RandomCosineDirection(random))
-		finalDir = vec3.UnitVector(diffuseDir)
-		isSpecular = false
+// tangent returns the direction along the surface with normal n that the X axis of tangent space maps to.
+func tangent(n vec3.Vec3Impl) vec3.Vec3Impl {
+	t := vec3.Cross(n, vec3.Vec3Impl{X: 0, Y: 1, Z: 0})
+	if vec3.Dot(t, t) < 0.001 {
+		t = vec3.Cross(n, vec3.Vec3Impl{X: 1, Y: 0, Z: 0})
 	}
 
-	scattered := ray.NewWithLambda(hr.P(), finalDir, r.Time(), lambda)
-
-	// Create PDF for importance sampling
-	// TODO: Use a more accurate PDF for PBR materials.
-	pdf := pdf.NewCosine(normal)
+	return t.MakeUnitVector()
+}
 
-	// Boost specular reflection brightness to match RGB reference
-	var finalAlbedo float64
-	if isSpecular {
-		// Increase the brightness of specular reflections specifically
-		finalAlbedo = albedo * 1.5 // 50% boost for specular reflections
-	} else {
-		finalAlbedo = albedo
+// pdf returns the PDF of the directions in which light arriving along r is scattered by a surface of the given
+// albedo. The specular lobe is sampled with the probability that light is reflected by it rather than by the
+// diffuse lobe.
+func (s pbrSurface) pdf(r ray.Ray, albedo float64) pdf.PDF {
+	wo := vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1)
+	cosO := vec3.Dot(wo, s.normal)
+
+	f0 := dielectricF0*(1-s.metalness) + albedo*s.metalness
+	specular := microfacet.FresnelSchlick(cosO, f0)
+	diffuse := (1 - s.metalness) * albedo * (1 - microfacet.FresnelSchlick(cosO, dielectricF0))
+	weight := 1.0
+	if specular+diffuse > 0 {
+		weight = math.Max(0.05, specular/(specular+diffuse))
 	}
 
-	scatterRecord := scatterrecord.NewSpectralScatterRecord(scattered, isSpecular, finalAlbedo, lambda, nil, 0.0, 0.0, pdf)
-	return scattered, scatterRecord, true
+	return pdf.NewWeightedMixture(pdf.NewGGX(s.normal, wo, s.alpha), pdf.NewCosine(s.normal), weight)
 }
 
-// ScatteringPDF implements the probability distribution function for PBR materials.
-func (pbr *PBR) ScatteringPDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64 {
-	cosine := vec3.Dot(hr.Normal(), vec3.UnitVector(scattered.Direction()))
-	if cosine < 0 {
-		cosine = 0
+// reflectance returns the BSDF times the cosine of the angle between wi and the normal for light arriving along wi
+// and leaving along wo, for a channel of the given albedo.
+//
+// The specular lobe is a GGX microfacet BRDF with height-correlated Smith masking-shadowing whose reflectance at
+// normal incidence blends between that of dielectrics and the albedo with the metalness. The light that the single
+// scattering model loses to bounces between microfacets is added back as described in "Practical multiple
+// scattering compensation for microfacet models" by Emmanuel Turquin. The diffuse lobe of the non-metallic part
+// reflects the light that is not reflected specularly.
+func (s pbrSurface) reflectance(wo vec3.Vec3Impl, wi vec3.Vec3Impl, albedo float64) float64 {
+	cosO := vec3.Dot(wo, s.normal)
+	cosI := vec3.Dot(wi, s.normal)
+	if cosO <= 0 || cosI <= 0 {
+		return 0
 	}
 
-	return cosine / math.Pi
+	h := vec3.UnitVector(vec3.Add(wo, wi))
+	cosH := vec3.Dot(h, s.normal)
+	cosD := vec3.Dot(wo, h)
+
+	f0 := dielectricF0*(1-s.metalness) + albedo*s.metalness
+	e := microfacet.DirectionalAlbedo(cosO, s.alpha)
+	specular := microfacet.D(cosH, s.alpha) * microfacet.G2(cosO, cosI, s.alpha) * microfacet.FresnelSchlick(cosD, f0) / (4 * cosO)
+	specular *= 1 + f0*(1-e)/e
+
+	diffuse := (1 - s.metalness) * albedo * (1 - microfacet.FresnelSchlick(cosD, dielectricF0)) * cosI / math.Pi
+
+	return specular + diffuse
 }
 
 // NormalMap() returns the normal map associated with this material.
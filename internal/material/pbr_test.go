@@ -0,0 +1,89 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestPBRBSDF(t *testing.T) {
+	testData := []struct {
+		name      string
+		albedo    vec3.Vec3Impl
+		roughness float64
+		metalness float64
+		// wantMin and wantMax bound the fraction of the light that is reflected in the red channel.
+		wantMin, wantMax float64
+	}{
+		{name: "smooth white metal", albedo: vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, roughness: 0.1, metalness: 1, wantMin: 0.98, wantMax: 1.02},
+		{name: "rough white metal", albedo: vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, roughness: 1, metalness: 1, wantMin: 0.95, wantMax: 1.02},
+		{name: "white plastic", albedo: vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, roughness: 0.5, metalness: 0, wantMin: 0.9, wantMax: 1.02},
+		{name: "black plastic", albedo: vec3.Vec3Impl{}, roughness: 0.3, metalness: 0, wantMin: 0.03, wantMax: 0.1},
+		{name: "copper", albedo: vec3.Vec3Impl{X: 0.95, Y: 0.64, Z: 0.54}, roughness: 0.4, metalness: 1, wantMin: 0.9, wantMax: 1.0},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			roughness := vec3.Vec3Impl{X: test.roughness, Y: test.roughness, Z: test.roughness}
+			metalness := vec3.Vec3Impl{X: test.metalness, Y: test.metalness, Z: test.metalness}
+			pbr := NewPBR(texture.NewConstant(test.albedo), nil, texture.NewConstant(roughness), texture.NewConstant(metalness), nil, 0)
+
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+			r := ray.New(vec3.Vec3Impl{X: -1, Z: 1}, vec3.Vec3Impl{X: 1, Z: -1}, 0)
+
+			random := fastrandom.NewWithDefaults()
+			const n = 100000
+
+			// Estimate the fraction of the light arriving from every direction that is reflected along r.
+			var sum vec3.Vec3Impl
+			for range n {
+				scattered, srec, ok := pbr.Scatter(r, hr, random)
+				if !ok || srec.IsSpecular() {
+					t.Fatal("Expected a non-specular scatter")
+				}
+
+				pdfVal := srec.PDF().Value(scattered.Direction())
+				if got := pbr.ScatteringPDF(r, hr, scattered); math.Abs(got-pdfVal) > 1e-9*pdfVal {
+					t.Fatalf("ScatteringPDF() = %v, want the density of the scatter record %v", got, pdfVal)
+				}
+				if pdfVal == 0 {
+					continue
+				}
+
+				sum = vec3.Add(sum, vec3.ScalarDiv(pbr.BSDF(r, hr, scattered), pdfVal))
+			}
+
+			got := sum.X / n
+			if got < test.wantMin || got > test.wantMax {
+				t.Errorf("reflected fraction = %v, want it within [%v, %v]", got, test.wantMin, test.wantMax)
+			}
+			if sum.Y > sum.X || sum.Z > sum.Y {
+				t.Errorf("reflected fractions %v do not follow the albedo %v", vec3.ScalarDiv(sum, n), test.albedo)
+			}
+		})
+	}
+}
+
+func TestPBRSpectralBSDF(t *testing.T) {
+	albedo := vec3.Vec3Impl{X: 0.6, Y: 0.6, Z: 0.6}
+	roughness := vec3.Vec3Impl{X: 0.4, Y: 0.4, Z: 0.4}
+	metalness := vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5}
+	pbr := NewPBRWithSpectralAlbedo(texture.NewConstant(albedo), texture.NewSpectralNeutral(0.6), nil, texture.NewConstant(roughness), texture.NewConstant(metalness), nil, 0)
+
+	hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+	r := ray.New(vec3.Vec3Impl{X: -1, Z: 2}, vec3.Vec3Impl{X: 1, Z: -2}, 0)
+
+	// A neutral spectrum is reflected like a grey colour.
+	for _, direction := range []vec3.Vec3Impl{{X: 1, Z: 2}, {X: 0.3, Y: 0.5, Z: 1}, {X: -1, Z: 0.1}} {
+		scattered := ray.New(vec3.Vec3Impl{}, direction, 0)
+		want := pbr.BSDF(r, hr, scattered).X
+		if got := pbr.SpectralBSDF(r, hr, scattered, 550); math.Abs(got-want) > 1e-9 {
+			t.Errorf("SpectralBSDF(%v) = %v, want %v", direction, got, want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package material
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/microfacet"
+	"github.com/flynn-nrg/izpi/internal/onb"
+	"github.com/flynn-nrg/izpi/internal/pdf"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scatterrecord"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ Material = (*Principled)(nil)
+var _ BSDF = (*Principled)(nil)
+
+// PrincipledParameters holds the parameters of a principled material that are constant over its surface. All of
+// them are in [0, 1] except for the refractive index.
+type PrincipledParameters struct {
+	// Specular is the reflectance at normal incidence of the non-metallic part of the surface, where 0.5 is 4%.
+	Specular float64
+	// SpecularTint tints the specular reflection of the non-metallic part towards the base colour.
+	SpecularTint float64
+	// Anisotropic stretches the specular highlights along the tangent of the surface.
+	Anisotropic float64
+	// Sheen is the strength of the lobe that brightens cloth at grazing angles.
+	Sheen float64
+	// SheenTint tints the sheen towards the base colour.
+	SheenTint float64
+	// Clearcoat is the strength of a white specular layer over the rest of the surface.
+	Clearcoat float64
+	// ClearcoatGloss is the glossiness of the clearcoat layer.
+	ClearcoatGloss float64
+	// Transmission is the fraction of the non-metallic part of the surface that refracts light into the object
+	// rather than diffusing it.
+	Transmission float64
+	// Subsurface blends the diffuse lobe towards the flatter look of subsurface scattering.
+	Subsurface float64
+	// IOR is the refractive index of the object, which light is refracted into by the transmission lobe. It is 1.5
+	// if zero.
+	IOR float64
+}
+
+// Principled represents a material described by the parameters of the principled BSDF from "Physically Based
+// Shading at Disney" by Brent Burley and its 2015 extension to transmission, which artists author materials with.
+// The base colour, roughness and metallic parameters can vary over the surface.
+type Principled struct {
+	nonEmitter
+	nonPathLength
+	nonWorldSetter
+	baseColor         texture.Texture
+	spectralBaseColor texture.SpectralTexture
+	normalMap         texture.Texture
+	roughness         texture.Texture
+	metallic          texture.Texture
+	params            PrincipledParameters
+}
+
+// NewPrincipled returns a new principled material with the supplied textures and parameters. The normal map,
+// roughness and metallic textures are optional.
+func NewPrincipled(baseColor, normalMap, roughness, metallic texture.Texture, params PrincipledParameters) *Principled {
+	return &Principled{
+		baseColor: baseColor,
+		normalMap: normalMap,
+		roughness: roughness,
+		metallic:  metallic,
+		params:    params,
+	}
+}
+
+// NewPrincipledWithSpectralBaseColor returns a new principled material with spectral base colour support.
+func NewPrincipledWithSpectralBaseColor(baseColor texture.Texture, spectralBaseColor texture.SpectralTexture, normalMap, roughness, metallic texture.Texture, params PrincipledParameters) *Principled {
+	return &Principled{
+		baseColor:         baseColor,
+		spectralBaseColor: spectralBaseColor,
+		normalMap:         normalMap,
+		roughness:         roughness,
+		metallic:          metallic,
+		params:            params,
+	}
+}
+
+// Scatter computes how the ray bounces off the surface of a principled material.
+// The direction is sampled from one of the diffuse, specular, clearcoat and transmission lobes, chosen in
+// proportion to how much light each is expected to scatter. BSDF gives the light that is scattered.
+func (p *Principled) Scatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.ScatterRecord, bool) {
+	base := p.baseColor.Value(hr.U(), hr.V(), hr.P())
+
+	pdf := p.surface(r, hr).pdf((base.X + base.Y + base.Z) / 3.0)
+	scattered := ray.New(hr.P(), pdf.Generate(random), r.Time())
+
+	scatterRecord := scatterrecord.New(nil, false, base, vec3.Vec3Impl{}, vec3.Vec3Impl{}, vec3.Vec3Impl{}, pdf)
+	return scattered, scatterRecord, true
+}
+
+// SpectralScatter computes how the ray bounces off the surface of a principled material with spectral properties.
+func (p *Principled) SpectralScatter(r ray.Ray, hr *hitrecord.HitRecord, random *fastrandom.LCG) (*ray.RayImpl, *scatterrecord.SpectralScatterRecord, bool) {
+	lambda := r.Lambda()
+	base := p.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+
+	pdf := p.surface(r, hr).pdf(base)
+	scattered := ray.NewWithLambda(hr.P(), pdf.Generate(random), r.Time(), lambda)
+
+	scatterRecord := scatterrecord.NewSpectralScatterRecord(nil, false, base, lambda, nil, 0.0, 0.0, pdf)
+	setHeroAttenuations(r, scatterRecord, func(lambda float64) float64 {
+		return p.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+	})
+	return scattered, scatterRecord, true
+}
+
+// ScatteringPDF returns the density with which the directions of the scatter records of principled materials are
+// sampled.
+func (p *Principled) ScatteringPDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) float64 {
+	var base float64
+	if lambda := r.Lambda(); lambda != 0 {
+		base = p.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+	} else {
+		b := p.baseColor.Value(hr.U(), hr.V(), hr.P())
+		base = (b.X + b.Y + b.Z) / 3.0
+	}
+
+	return p.surface(r, hr).pdf(base).Value(scattered.Direction())
+}
+
+// BSDF returns the light scattered by every lobe towards the origin of r.
+func (p *Principled) BSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray) vec3.Vec3Impl {
+	base := p.baseColor.Value(hr.U(), hr.V(), hr.P())
+	s := p.surface(r, hr)
+	wi := s.uvw.ToLocal(vec3.UnitVector(scattered.Direction()))
+
+	return vec3.Vec3Impl{
+		X: s.reflectance(wi, base.X),
+		Y: s.reflectance(wi, base.Y),
+		Z: s.reflectance(wi, base.Z),
+	}
+}
+
+// SpectralBSDF returns the light scattered by every lobe towards the origin of r at the given wavelength.
+func (p *Principled) SpectralBSDF(r ray.Ray, hr *hitrecord.HitRecord, scattered ray.Ray, lambda float64) float64 {
+	base := p.SpectralAlbedo(hr.U(), hr.V(), lambda, hr.P())
+	s := p.surface(r, hr)
+
+	return s.reflectance(s.uvw.ToLocal(vec3.UnitVector(scattered.Direction())), base)
+}
+
+// principledSurface holds the parameters of a principled material at a point of its surface for light leaving it
+// in a given direction.
+type principledSurface struct {
+	// uvw is the shading frame, whose W axis is the normal on the side the light leaves from.
+	uvw *onb.Onb
+	// wo is the direction the light leaves in, in the shading frame.
+	wo vec3.Vec3Impl
+	// inside is whether the light leaves from within the object, where only the transmission lobe scatters it.
+	inside bool
+	// eta is the refractive index on the other side of the surface relative to the one wo lies on.
+	eta       float64
+	roughness float64
+	metallic  float64
+	specular  microfacet.Distribution
+	clearcoat microfacet.Distribution
+	// luminance is the luminance of the base colour, which tints are normalised by.
+	luminance float64
+	params    PrincipledParameters
+}
+
+// surface returns the parameters of the material at the intersection described by hr for light leaving towards the
+// origin of r.
+func (p *Principled) surface(r ray.Ray, hr *hitrecord.HitRecord) principledSurface {
+	roughness := 0.5 // Default to medium roughness
+	if p.roughness != nil {
+		v := p.roughness.Value(hr.U(), hr.V(), hr.P())
+		roughness = (v.X + v.Y + v.Z) / 3.0
+	}
+
+	var metallic float64
+	if p.metallic != nil {
+		v := p.metallic.Value(hr.U(), hr.V(), hr.P())
+		metallic = math.Max(0, math.Min(1, (v.X+v.Y+v.Z)/3.0))
+	}
+
+	base := p.baseColor.Value(hr.U(), hr.V(), hr.P())
+	wo := vec3.ScalarMul(vec3.UnitVector(r.Direction()), -1)
+	n := shadingNormal(p.normalMap, hr)
+	eta := p.params.IOR
+	if eta <= 0 {
+		eta = 1.5
+	}
+	inside := vec3.Dot(wo, hr.Normal()) < 0
+	if inside {
+		n = vec3.ScalarMul(n, -1)
+		eta = 1 / eta
+	}
+
+	uvw := onb.New()
+	uvw.BuildFromWU(n, tangent(n))
+
+	// The clearcoat is always isotropic and its roughness is remapped from the glossiness as in the Disney BRDF.
+	coatAlpha := 0.1 + (0.001-0.1)*p.params.ClearcoatGloss
+
+	return principledSurface{
+		uvw:       uvw,
+		wo:        uvw.ToLocal(wo),
+		inside:    inside,
+		eta:       eta,
+		roughness: roughness,
+		metallic:  metallic,
+		specular:  microfacet.NewDistribution(roughness, p.params.Anisotropic),
+		clearcoat: microfacet.Distribution{AlphaX: coatAlpha, AlphaY: coatAlpha},
+		luminance: 0.299*base.X + 0.587*base.Y + 0.114*base.Z,
+		params:    p.params,
+	}
+}
+
+// weights returns the fractions of the surface that are opaque dielectric, metal and transmissive.
+func (s principledSurface) weights() (float64, float64, float64) {
+	return (1 - s.metallic) * (1 - s.params.Transmission), s.metallic, (1 - s.metallic) * s.params.Transmission
+}
+
+// tint returns the hue of a channel of the given base colour with the luminance removed.
+func (s principledSurface) tint(base float64) float64 {
+	if s.luminance <= 0 {
+		return 1
+	}
+
+	return base / s.luminance
+}
+
+// specularF0 returns the reflectance at normal incidence of the non-metallic part of the surface for a channel of
+// the given base colour.
+func (s principledSurface) specularF0(base float64) float64 {
+	return s.params.Specular * 0.08 * (1 + (s.tint(base)-1)*s.params.SpecularTint)
+}
+
+// pdf returns the PDF of the directions in which light is scattered by a surface whose base colour has the given
+// average. Each lobe is sampled with a probability proportional to the light it is expected to scatter.
+func (s principledSurface) pdf(base float64) pdf.PDF {
+	n, u, wo := s.uvw.W(), s.uvw.U(), s.uvw.Local(s.wo)
+	if s.inside {
+		return pdf.NewGGXDielectric(n, u, wo, s.specular, s.eta)
+	}
+
+	opaque, metal, transmission := s.weights()
+	cosO := math.Max(0, s.wo.Z)
+
+	// The sheen is sampled along with the diffuse lobe, whose directions it roughly follows.
+	var pdfs []pdf.PDF
+	var weights []float64
+	if w := opaque * (base + s.params.Sheen*0.1); w > 0 {
+		pdfs, weights = append(pdfs, pdf.NewCosine(n)), append(weights, w)
+	}
+	if w := opaque*microfacet.FresnelSchlick(cosO, s.specularF0(base)) + metal*microfacet.FresnelSchlick(cosO, base); w > 0 {
+		pdfs, weights = append(pdfs, pdf.NewAnisotropicGGX(n, u, wo, s.specular)), append(weights, w)
+	}
+	if w := 0.25 * s.params.Clearcoat * microfacet.FresnelSchlick(cosO, dielectricF0); w > 0 {
+		pdfs, weights = append(pdfs, pdf.NewAnisotropicGGX(n, u, wo, s.clearcoat)), append(weights, w)
+	}
+	if transmission > 0 {
+		pdfs, weights = append(pdfs, pdf.NewGGXDielectric(n, u, wo, s.specular, s.eta)), append(weights, transmission)
+	}
+
+	if len(pdfs) == 0 {
+		return pdf.NewCosine(n)
+	}
+
+	// Fold the lobes into nested mixtures, each of which samples the latest lobe with its share of the total.
+	m, total := pdfs[0], weights[0]
+	for i := 1; i < len(pdfs); i++ {
+		total += weights[i]
+		m = pdf.NewWeightedMixture(pdfs[i], m, weights[i]/total)
+	}
+
+	return m
+}
+
+// reflectance returns the BSDF times the cosine of the angle between wi, given in the shading frame, and the
+// normal for light arriving along wi and leaving along wo, for a channel of the given base colour.
+//
+// The diffuse lobe is the Disney diffuse BRDF, with its retro-reflection at grazing angles, blended with its
+// approximation of subsurface scattering and topped by the sheen. The specular lobe is an anisotropic GGX
+// microfacet BRDF whose reflectance at normal incidence blends between that of the specular parameter and the base
+// colour with the metalness, with the energy lost to multiple scattering added back as in the PBR material. The
+// clearcoat is a fainter isotropic GGX lobe with the reflectance of a dielectric. The transmission lobe reflects
+// and refracts light on the rough surface of a dielectric as described in "Microfacet Models for Refraction
+// through Rough Surfaces" by Bruce Walter et al.
+func (s principledSurface) reflectance(wi vec3.Vec3Impl, base float64) float64 {
+	// Light is tinted by the square root of the base colour every time it crosses the surface, so that it takes
+	// the base colour after entering and leaving the object.
+	if s.inside {
+		return s.dielectric(wi, math.Sqrt(base))
+	}
+
+	opaque, metal, transmission := s.weights()
+	wo := s.wo
+
+	var f float64
+	if wo.Z > 0 && wi.Z > 0 {
+		h := vec3.UnitVector(vec3.Add(wo, wi))
+		cosD := vec3.Dot(wi, h)
+
+		if opaque > 0 {
+			fl, fv := schlickWeight(wi.Z), schlickWeight(wo.Z)
+			fd90 := 0.5 + 2*s.roughness*cosD*cosD
+			fd := (1 + (fd90-1)*fl) * (1 + (fd90-1)*fv)
+			fss90 := s.roughness * cosD * cosD
+			fss := (1 + (fss90-1)*fl) * (1 + (fss90-1)*fv)
+			ss := 1.25 * (fss*(1/(wi.Z+wo.Z)-0.5) + 0.5)
+
+			diffuse := base / math.Pi * (fd + (ss-fd)*s.params.Subsurface)
+			sheen := s.params.Sheen * (1 + (s.tint(base)-1)*s.params.SheenTint) * schlickWeight(cosD)
+			f += opaque * (diffuse + sheen) * wi.Z
+		}
+
+		f0 := opaque*s.specularF0(base) + metal*base
+		if f0 > 0 {
+			fr := opaque*microfacet.FresnelSchlick(cosD, s.specularF0(base)) + metal*microfacet.FresnelSchlick(cosD, base)
+			e := microfacet.DirectionalAlbedo(wo.Z, math.Sqrt(s.specular.AlphaX*s.specular.AlphaY))
+			specular := s.specular.D(h) * s.specular.G2(wo, wi) * fr / (4 * wo.Z)
+			f += specular * (1 + f0*(1-e)/e)
+		}
+
+		if s.params.Clearcoat > 0 {
+			f += 0.25 * s.params.Clearcoat * s.clearcoat.D(h) * s.clearcoat.G2(wo, wi) * microfacet.FresnelSchlick(cosD, dielectricF0) / (4 * wo.Z)
+		}
+	}
+
+	if transmission > 0 {
+		f += transmission * s.dielectric(wi, math.Sqrt(base))
+	}
+
+	return f
+}
+
+// dielectric returns the BSDF times the cosine of the angle between wi and the normal of the transmission lobe,
+// whose refracted light is multiplied by tint.
+func (s principledSurface) dielectric(wi vec3.Vec3Impl, tint float64) float64 {
+	wo, d := s.wo, s.specular
+	if wo.Z <= 0 {
+		return 0
+	}
+
+	if wi.Z > 0 {
+		h := vec3.UnitVector(vec3.Add(wo, wi))
+		return d.D(h) * d.G2(wo, wi) * microfacet.FresnelDielectric(vec3.Dot(wo, h), s.eta) / (4 * wo.Z)
+	}
+
+	h, ok := microfacet.RefractionHalfVector(wo, wi, s.eta)
+	if !ok {
+		return 0
+	}
+
+	cosOH, cosIH := vec3.Dot(wo, h), vec3.Dot(wi, h)
+	denom := cosIH + cosOH/s.eta
+	return tint * d.D(h) * d.G2(wo, wi) * (1 - microfacet.FresnelDielectric(cosOH, s.eta)) * math.Abs(cosIH*cosOH) / (wo.Z * denom * denom)
+}
+
+// schlickWeight returns the weight of the grazing reflectance in Schlick's approximation of the Fresnel equations.
+func schlickWeight(cosTheta float64) float64 {
+	m := math.Max(0, math.Min(1, 1-cosTheta))
+	m2 := m * m
+	return m2 * m2 * m
+}
+
+// NormalMap returns the normal map associated with this material.
+func (p *Principled) NormalMap() texture.Texture {
+	return p.normalMap
+}
+
+// Albedo returns the base colour.
+func (p *Principled) Albedo(u float64, v float64, pos vec3.Vec3Impl) vec3.Vec3Impl {
+	return p.baseColor.Value(u, v, pos)
+}
+
+// SpectralAlbedo returns the base colour at the given wavelength.
+func (p *Principled) SpectralAlbedo(u float64, v float64, lambda float64, pos vec3.Vec3Impl) float64 {
+	if p.spectralBaseColor != nil {
+		return p.spectralBaseColor.Value(u, v, lambda, pos)
+	}
+
+	base := p.baseColor.Value(u, v, pos)
+	return 0.299*base.X + 0.587*base.Y + 0.114*base.Z
+}
@@ -0,0 +1,102 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestPrincipledBSDF(t *testing.T) {
+	white := vec3.Vec3Impl{X: 1, Y: 1, Z: 1}
+
+	testData := []struct {
+		name      string
+		baseColor vec3.Vec3Impl
+		roughness float64
+		metallic  float64
+		params    PrincipledParameters
+		// direction is the direction of the incoming ray, which arrives from inside the object if it points along
+		// the normal.
+		direction vec3.Vec3Impl
+		// wantMin and wantMax bound the fraction of the light that is scattered in the red channel.
+		wantMin, wantMax float64
+	}{
+		{name: "white plastic", baseColor: white, roughness: 0.5, params: PrincipledParameters{Specular: 0.5}, wantMin: 0.9, wantMax: 1.1},
+		{name: "subsurface", baseColor: white, roughness: 0.5, params: PrincipledParameters{Specular: 0.5, Subsurface: 1}, wantMin: 0.85, wantMax: 1.1},
+		{name: "black plastic", roughness: 0.3, params: PrincipledParameters{Specular: 0.5}, wantMin: 0.03, wantMax: 0.1},
+		{name: "black cloth", roughness: 0.8, params: PrincipledParameters{Sheen: 1}, wantMin: 0.005, wantMax: 0.05},
+		{name: "clearcoat", roughness: 0.3, params: PrincipledParameters{Clearcoat: 1, ClearcoatGloss: 1}, wantMin: 0.005, wantMax: 0.05},
+		{name: "brushed white metal", baseColor: white, roughness: 0.3, metallic: 1, params: PrincipledParameters{Anisotropic: 0.5}, wantMin: 0.95, wantMax: 1.02},
+		{name: "rough glass", baseColor: white, roughness: 0.3, params: PrincipledParameters{Transmission: 1, IOR: 1.5}, wantMin: 0.95, wantMax: 1.01},
+		{name: "inside rough glass", baseColor: white, roughness: 0.3, params: PrincipledParameters{Transmission: 1, IOR: 1.5}, direction: vec3.Vec3Impl{X: 0.3, Z: 1}, wantMin: 0.95, wantMax: 1.01},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			roughness := vec3.Vec3Impl{X: test.roughness, Y: test.roughness, Z: test.roughness}
+			metallic := vec3.Vec3Impl{X: test.metallic, Y: test.metallic, Z: test.metallic}
+			p := NewPrincipled(texture.NewConstant(test.baseColor), nil, texture.NewConstant(roughness), texture.NewConstant(metallic), test.params)
+
+			direction := test.direction
+			if direction == (vec3.Vec3Impl{}) {
+				direction = vec3.Vec3Impl{X: 1, Z: -1}
+			}
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+			r := ray.New(vec3.ScalarMul(direction, -1), direction, 0)
+
+			random := fastrandom.NewWithDefaults()
+			const n = 100000
+
+			// Estimate the fraction of the light arriving from every direction that is scattered along r.
+			var sum vec3.Vec3Impl
+			for range n {
+				scattered, srec, ok := p.Scatter(r, hr, random)
+				if !ok || srec.IsSpecular() {
+					t.Fatal("Expected a non-specular scatter")
+				}
+
+				pdfVal := srec.PDF().Value(scattered.Direction())
+				if got := p.ScatteringPDF(r, hr, scattered); math.Abs(got-pdfVal) > 1e-9*pdfVal {
+					t.Fatalf("ScatteringPDF() = %v, want the density of the scatter record %v", got, pdfVal)
+				}
+				if pdfVal == 0 {
+					continue
+				}
+
+				sum = vec3.Add(sum, vec3.ScalarDiv(p.BSDF(r, hr, scattered), pdfVal))
+			}
+
+			got := sum.X / n
+			if got < test.wantMin || got > test.wantMax {
+				t.Errorf("scattered fraction = %v, want it within [%v, %v]", got, test.wantMin, test.wantMax)
+			}
+		})
+	}
+}
+
+func TestPrincipledSpectralBSDF(t *testing.T) {
+	baseColor := vec3.Vec3Impl{X: 0.6, Y: 0.6, Z: 0.6}
+	roughness := vec3.Vec3Impl{X: 0.4, Y: 0.4, Z: 0.4}
+	params := PrincipledParameters{Specular: 0.5, Sheen: 0.5, Clearcoat: 0.5, Transmission: 0.5, IOR: 1.5}
+	p := NewPrincipledWithSpectralBaseColor(texture.NewConstant(baseColor), texture.NewSpectralNeutral(0.6), nil, texture.NewConstant(roughness), nil, params)
+
+	hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+	r := ray.New(vec3.Vec3Impl{X: -1, Z: 2}, vec3.Vec3Impl{X: 1, Z: -2}, 0)
+
+	// A neutral spectrum is scattered like a grey colour.
+	for _, direction := range []vec3.Vec3Impl{{X: 1, Z: 2}, {X: 0.3, Y: 0.5, Z: 1}, {X: 0.5, Z: -1}} {
+		scattered := ray.New(vec3.Vec3Impl{}, direction, 0)
+		want := p.BSDF(r, hr, scattered).X
+		if want == 0 {
+			t.Errorf("BSDF(%v) = 0, want light to be scattered", direction)
+		}
+		if got := p.SpectralBSDF(r, hr, scattered, 550); math.Abs(got-want) > 1e-9 {
+			t.Errorf("SpectralBSDF(%v) = %v, want %v", direction, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package material
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/microfacet"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// thinFilmStep is the spacing in nanometres of the wavelengths the reflectance of thin films is averaged over in
+// RGB renders.
+const thinFilmStep = 10
+
+// thinFilmWavelengths are the wavelengths the reflectance of thin films is averaged over in RGB renders and
+// thinFilmWeights how much each of them contributes to each of the RGB channels.
+var thinFilmWavelengths, thinFilmWeights = rgbWeights()
+
+// rgbWeights returns the visible wavelengths thinFilmStep apart together with the contribution of each of them to
+// each of the RGB channels, normalised so that the contributions to every channel add up to 1 and a surface that
+// reflects all wavelengths equally is grey.
+func rgbWeights() ([]float64, []vec3.Vec3Impl) {
+	var wavelengths []float64
+	var weights []vec3.Vec3Impl
+	var total vec3.Vec3Impl
+	for lambda := float64(spectral.WavelengthMin); lambda <= spectral.WavelengthMax; lambda += thinFilmStep {
+		r, g, b := spectral.XYZToACEScg(spectral.GetCIEValues(lambda))
+		weight := vec3.Vec3Impl{X: r, Y: g, Z: b}
+		wavelengths = append(wavelengths, lambda)
+		weights = append(weights, weight)
+		total = vec3.Add(total, weight)
+	}
+
+	for i := range weights {
+		weights[i] = vec3.Vec3Impl{X: weights[i].X / total.X, Y: weights[i].Y / total.Y, Z: weights[i].Z / total.Z}
+	}
+
+	return wavelengths, weights
+}
+
+// ThinFilm is a transparent coating about as thick as the wavelength of light, like a soap film, a layer of oil or
+// the oxide that grows on heated metals. The light reflected off the top of the film interferes with the light
+// reflected off the bottom, which tints reflections with colours that shift with the thickness of the film and the
+// angle they are seen at.
+type ThinFilm struct {
+	thickness texture.Texture
+	ior       float64
+}
+
+// NewThinFilm returns a thin film with the given refractive index whose thickness in nanometres is read from the
+// first channel of a texture.
+func NewThinFilm(thickness texture.Texture, ior float64) *ThinFilm {
+	return &ThinFilm{
+		thickness: thickness,
+		ior:       ior,
+	}
+}
+
+// Thickness returns the thickness of the film in nanometres at a point of the surface.
+func (f *ThinFilm) Thickness(u float64, v float64, p vec3.Vec3Impl) float64 {
+	return math.Max(0, f.thickness.Value(u, v, p).X)
+}
+
+// IOR returns the refractive index of the film.
+func (f *ThinFilm) IOR() float64 {
+	return f.ior
+}
+
+// reflectance returns the fraction of the light of wavelength lambda, arriving at an angle with the given cosine
+// from a medium with the refractive index from, that a film of the given thickness reflects off a substrate with
+// the complex refractive index eta + ik.
+func (f *ThinFilm) reflectance(cosTheta float64, lambda float64, thickness float64, from float64, eta float64, k float64) float64 {
+	return microfacet.FresnelThinFilm(cosTheta, lambda, thickness, f.ior/from, eta/from, k/from)
+}
+
+// rgbReflectance approximates the reflectance of each of the RGB channels with the average of the reflectance of
+// the visible wavelengths weighted by their contribution to the channel. The substrate function returns the complex
+// refractive index of the substrate at a wavelength.
+func (f *ThinFilm) rgbReflectance(cosTheta float64, thickness float64, from float64, substrate func(lambda float64) (float64, float64)) vec3.Vec3Impl {
+	var sum vec3.Vec3Impl
+	for i, lambda := range thinFilmWavelengths {
+		eta, k := substrate(lambda)
+		sum = vec3.Add(sum, vec3.ScalarMul(thinFilmWeights[i], f.reflectance(cosTheta, lambda, thickness, from, eta, k)))
+	}
+
+	return vec3.Vec3Impl{
+		X: math.Max(0, math.Min(1, sum.X)),
+		Y: math.Max(0, math.Min(1, sum.Y)),
+		Z: math.Max(0, math.Min(1, sum.Z)),
+	}
+}
@@ -0,0 +1,166 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// newThinFilm returns a thin film of constant thickness.
+func newThinFilm(thickness float64, ior float64) *ThinFilm {
+	return NewThinFilm(texture.NewConstant(vec3.Vec3Impl{X: thickness, Y: thickness, Z: thickness}), ior)
+}
+
+func TestThinFilmRGBReflectance(t *testing.T) {
+	glass := func(float64) (float64, float64) { return 1.5, 0 }
+
+	testData := []struct {
+		name       string
+		film       *ThinFilm
+		cosTheta   float64
+		wantGrey   bool
+		wantMin    float64
+		wantMax    float64
+		wantTinted bool
+	}{
+		{name: "no film", film: newThinFilm(0, 1.33), cosTheta: 1, wantGrey: true, wantMin: 0.0399, wantMax: 0.0401},
+		{name: "film matching the medium", film: newThinFilm(400, 1), cosTheta: 0.5, wantGrey: true, wantMin: 0.0891, wantMax: 0.0893},
+		{name: "oil on glass", film: newThinFilm(300, 1.6), cosTheta: 1, wantMin: 0, wantMax: 0.2, wantTinted: true},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.film.rgbReflectance(test.cosTheta, test.film.Thickness(0, 0, vec3.Vec3Impl{}), 1, glass)
+			for _, v := range []float64{got.X, got.Y, got.Z} {
+				if v < test.wantMin || v > test.wantMax {
+					t.Errorf("rgbReflectance() = %v, want every channel within [%v, %v]", got, test.wantMin, test.wantMax)
+				}
+			}
+			if grey := math.Abs(got.X-got.Y) < 1e-4 && math.Abs(got.Y-got.Z) < 1e-4; test.wantGrey && !grey {
+				t.Errorf("rgbReflectance() = %v, want grey", got)
+			}
+			if tinted := math.Abs(got.X-got.Z) > 0.01; tinted != test.wantTinted {
+				t.Errorf("rgbReflectance() = %v, want tinted %v", got, test.wantTinted)
+			}
+		})
+	}
+}
+
+func TestDielectricThinFilm(t *testing.T) {
+	film := newThinFilm(350, 1.33)
+	hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+	random := fastrandom.NewWithDefaults()
+	const n = 50000
+
+	t.Run("rgb", func(t *testing.T) {
+		d := NewDielectric(1.5)
+		d.SetThinFilm(film)
+		r := ray.New(vec3.Vec3Impl{X: -1, Z: 1}, vec3.Vec3Impl{X: 1, Z: -1}, 0)
+
+		// The light reflected and refracted on average matches the reflectance of the film.
+		var reflected, refracted vec3.Vec3Impl
+		for range n {
+			scattered, srec, ok := d.Scatter(r, hr, random)
+			if !ok {
+				t.Fatal("Expected a scatter")
+			}
+			if scattered.Direction().Z > 0 {
+				reflected = vec3.Add(reflected, srec.Attenuation())
+			} else {
+				refracted = vec3.Add(refracted, srec.Attenuation())
+			}
+		}
+
+		want := film.rgbReflectance(1/math.Sqrt2, 350, 1, func(float64) (float64, float64) { return 1.5, 0 })
+		for i, got := range []vec3.Vec3Impl{vec3.ScalarDiv(reflected, n), vec3.ScalarDiv(refracted, n)} {
+			if i == 1 {
+				want = vec3.Sub(vec3.Vec3Impl{X: 1, Y: 1, Z: 1}, want)
+			}
+			if vec3.Sub(got, want).Length() > 0.01 {
+				t.Errorf("average attenuation = %v, want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("spectral", func(t *testing.T) {
+		d := NewSpectralDielectric(texture.NewSpectralNeutral(1.5), false)
+		d.SetThinFilm(film)
+		d.SetWorld(&mockSceneGeometry{})
+		wavelengths := spectral.Wavelengths{450, 542.5, 635, 727.5}
+		r := ray.NewWithLambda(vec3.Vec3Impl{X: -1, Z: 1}, vec3.Vec3Impl{X: 1, Z: -1}, 0, wavelengths[0])
+		r.SetWavelengths(wavelengths)
+
+		// Every wavelength traced along the ray is reflected in proportion to its own reflectance.
+		var reflected [spectral.NumHeroWavelengths]float64
+		for range n {
+			scattered, srec, ok := d.SpectralScatter(r, hr, random)
+			if !ok || srec.Dispersive() {
+				t.Fatal("Expected a non-dispersive scatter")
+			}
+			if scattered.Direction().Z > 0 {
+				for i, a := range srec.Attenuations() {
+					reflected[i] += a
+				}
+			}
+		}
+
+		for i, lambda := range wavelengths {
+			want := film.reflectance(1/math.Sqrt2, lambda, 350, 1, 1.5, 0)
+			if got := reflected[i] / n; math.Abs(got-want) > 0.01 {
+				t.Errorf("reflected fraction at %vnm = %v, want %v", lambda, got, want)
+			}
+		}
+	})
+}
+
+func TestConductorThinFilm(t *testing.T) {
+	hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+	r := ray.NewWithLambda(vec3.Vec3Impl{Z: 1}, vec3.Vec3Impl{Z: -1}, 0, 550)
+	random := fastrandom.NewWithDefaults()
+
+	testData := []struct {
+		name string
+		film *ThinFilm
+		// wantBare is whether the coated conductor reflects like the bare one.
+		wantBare bool
+	}{
+		{name: "no film", film: newThinFilm(0, 2.4), wantBare: true},
+		{name: "film matching the medium", film: newThinFilm(100, 1), wantBare: true},
+		{name: "oxide", film: newThinFilm(100, 2.4)},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			bare := NewConductor(constantSPD(0.5), constantSPD(3), 0)
+			c := NewConductor(constantSPD(0.5), constantSPD(3), 0)
+			c.SetThinFilm(test.film)
+
+			_, ssrec, ok := c.SpectralScatter(r, hr, random)
+			if !ok {
+				t.Fatal("Expected a spectral scatter")
+			}
+			_, want, _ := bare.SpectralScatter(r, hr, random)
+			if got := ssrec.Attenuation(); (math.Abs(got-want.Attenuation()) < 1e-6) != test.wantBare {
+				t.Errorf("spectral Attenuation() = %v, bare conductor %v, want the same %v", got, want.Attenuation(), test.wantBare)
+			}
+
+			_, srec, ok := c.Scatter(r, hr, random)
+			if !ok {
+				t.Fatal("Expected a scatter")
+			}
+			got := srec.Attenuation()
+			if test.wantBare && vec3.Sub(got, bare.Albedo(0, 0, vec3.Vec3Impl{})).Length() > 1e-3 {
+				t.Errorf("Attenuation() = %v, want the bare conductor's %v", got, bare.Albedo(0, 0, vec3.Vec3Impl{}))
+			}
+			if !test.wantBare && math.Abs(got.X-got.Z) < 0.01 {
+				t.Errorf("Attenuation() = %v, want a tinted reflection", got)
+			}
+		})
+	}
+}
@@ -117,6 +117,26 @@ var MaterialLibrary = map[string]*MaterialDefinition{
 		Description:    "Glossy porcelain with very low roughness",
 		CreateMaterial: CreatePorcelainGlossy,
 	},
+	"gold": {
+		Name:           "gold",
+		Description:    "Polished gold with its measured complex refractive index",
+		CreateMaterial: polishedMetal("gold"),
+	},
+	"silver": {
+		Name:           "silver",
+		Description:    "Polished silver with its measured complex refractive index",
+		CreateMaterial: polishedMetal("silver"),
+	},
+	"copper": {
+		Name:           "copper",
+		Description:    "Polished copper with its measured complex refractive index",
+		CreateMaterial: polishedMetal("copper"),
+	},
+	"aluminium": {
+		Name:           "aluminium",
+		Description:    "Polished aluminium with its measured complex refractive index",
+		CreateMaterial: polishedMetal("aluminium"),
+	},
 }
 
 // GetMaterial retrieves a material by name from the library
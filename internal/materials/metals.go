@@ -0,0 +1,126 @@
+package materials
+
+import (
+	"slices"
+
+	"github.com/flynn-nrg/izpi/internal/material"
+	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+)
+
+// metalDefinition holds the complex refractive index η + ik of a metal measured at the given wavelengths in
+// nanometres.
+type metalDefinition struct {
+	wavelengths []float64
+	eta         []float64
+	k           []float64
+}
+
+// johnsonChristyWavelengths are the wavelengths of the photon energies from 3.12 eV down to 1.64 eV at which
+// P. B. Johnson and R. W. Christy measured the optical constants of the noble metals in "Optical Constants of the
+// Noble Metals" (1972).
+var johnsonChristyWavelengths = []float64{
+	397.4, 413.3, 430.6, 450.9, 471.5, 496.0, 521.0, 548.7, 582.2, 616.9, 659.6, 704.5, 756.1,
+}
+
+// metalLibrary is the collection of built-in metals.
+var metalLibrary = map[string]*metalDefinition{
+	"gold": {
+		wavelengths: johnsonChristyWavelengths,
+		eta:         []float64{1.47, 1.46, 1.45, 1.38, 1.31, 1.04, 0.62, 0.43, 0.29, 0.21, 0.14, 0.13, 0.14},
+		k:           []float64{1.952, 1.958, 1.948, 1.914, 1.849, 1.833, 2.081, 2.455, 2.863, 3.272, 3.697, 4.103, 4.542},
+	},
+	"silver": {
+		wavelengths: johnsonChristyWavelengths,
+		eta:         []float64{0.05, 0.05, 0.04, 0.04, 0.05, 0.05, 0.05, 0.06, 0.05, 0.06, 0.05, 0.04, 0.03},
+		k:           []float64{2.070, 2.275, 2.462, 2.657, 2.869, 3.093, 3.324, 3.586, 3.858, 4.152, 4.483, 4.838, 5.242},
+	},
+	"copper": {
+		wavelengths: johnsonChristyWavelengths,
+		eta:         []float64{1.18, 1.17, 1.17, 1.17, 1.18, 1.18, 1.12, 1.04, 0.47, 0.25, 0.22, 0.24, 0.21},
+		k:           []float64{2.360, 2.400, 2.450, 2.520, 2.580, 2.610, 2.600, 2.590, 2.810, 3.112, 3.415, 3.777, 4.205},
+	},
+	// From the Lorentz-Drude model of A. D. Rakić et al. in "Optical properties of metallic films for vertical-cavity
+	// optoelectronic devices" (1998).
+	"aluminium": {
+		wavelengths: []float64{400, 450, 500, 550, 600, 650, 700, 750},
+		eta:         []float64{0.49, 0.62, 0.77, 0.96, 1.20, 1.47, 1.83, 2.40},
+		k:           []float64{4.86, 5.47, 6.08, 6.69, 7.26, 7.79, 8.31, 8.62},
+	},
+}
+
+// GetMetal returns the complex refractive index of a metal from the built-in library.
+func GetMetal(name string) (eta *spectral.SpectralPowerDistribution, k *spectral.SpectralPowerDistribution, ok bool) {
+	def, ok := metalLibrary[name]
+	if !ok {
+		return nil, nil, false
+	}
+
+	return spectral.NewSPD(def.wavelengths, def.eta), spectral.NewSPD(def.wavelengths, def.k), true
+}
+
+// ListMetals returns the names of the metals in the built-in library.
+func ListMetals() []string {
+	keys := make([]string, 0, len(metalLibrary))
+	for key := range metalLibrary {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// CreateConductor creates a conductor made of a metal from the built-in library with the given roughness.
+func CreateConductor(metal string, roughness float64) (*material.Conductor, bool) {
+	eta, k, ok := GetMetal(metal)
+	if !ok {
+		return nil, false
+	}
+
+	return material.NewConductor(eta, k, roughness), true
+}
+
+// polishedMetal returns a function that creates a perfectly smooth conductor made of a metal from the built-in
+// library.
+func polishedMetal(metal string) func() material.Material {
+	return func() material.Material {
+		mat, _ := CreateConductor(metal, 0)
+		return mat
+	}
+}
+
+// CreateConductorProtobufMaterial creates a protobuf material definition for a conductor made of a metal from the
+// built-in library, with its complex refractive index tabulated so that it does not depend on the library.
+func CreateConductorProtobufMaterial(name string, metal string, roughness float32) (*pb_transport.Material, bool) {
+	def, ok := metalLibrary[metal]
+	if !ok {
+		return nil, false
+	}
+
+	// Convert float64 slices to float32 for protobuf
+	wavelengths32 := make([]float32, len(def.wavelengths))
+	eta32 := make([]float32, len(def.eta))
+	k32 := make([]float32, len(def.k))
+	for i := range def.wavelengths {
+		wavelengths32[i] = float32(def.wavelengths[i])
+		eta32[i] = float32(def.eta[i])
+		k32[i] = float32(def.k[i])
+	}
+
+	return &pb_transport.Material{
+		Name: name,
+		Type: pb_transport.MaterialType_CONDUCTOR,
+		MaterialProperties: &pb_transport.Material_Conductor{
+			Conductor: &pb_transport.ConductorMaterial{
+				Eta: &pb_transport.TabulatedSpectralConstant{
+					Wavelengths: wavelengths32,
+					Values:      eta32,
+				},
+				K: &pb_transport.TabulatedSpectralConstant{
+					Wavelengths: wavelengths32,
+					Values:      k32,
+				},
+				Roughness: roughness,
+			},
+		},
+	}, true
+}
@@ -0,0 +1,201 @@
+// Package materialx reads MaterialX documents and translates their materials into principled materials.
+// This is not a complete implementation of the standard but rather what is needed to import the materials exported
+// by most tools: surface materials shaded with standard_surface or open_pbr_surface, whose inputs are either values
+// or networks of image, tiledimage, constant, multiply, mix, dot and normalmap nodes. Texture coordinates are not
+// taken from the document and images are always looked up with the coordinates of the surface.
+package materialx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
+)
+
+var (
+	ErrMaterialNotFound    = errors.New("material not found")
+	ErrUnsupportedNode     = errors.New("unsupported node")
+	ErrUnsupportedShader   = errors.New("unsupported shading model")
+	ErrUnresolvedReference = errors.New("unresolved reference")
+	ErrCycle               = errors.New("cycle in node network")
+	ErrNotConstant         = errors.New("input must be a constant")
+)
+
+// element is any element of a MaterialX document. Nodes, inputs and outputs share the same attributes, and the
+// category of a node is the name of its element.
+type element struct {
+	XMLName       xml.Name
+	Name          string     `xml:"name,attr"`
+	Type          string     `xml:"type,attr"`
+	Value         string     `xml:"value,attr"`
+	NodeName      string     `xml:"nodename,attr"`
+	NodeGraph     string     `xml:"nodegraph,attr"`
+	Output        string     `xml:"output,attr"`
+	InterfaceName string     `xml:"interfacename,attr"`
+	FilePrefix    string     `xml:"fileprefix,attr"`
+	Children      []*element `xml:",any"`
+}
+
+// category returns the kind of element.
+func (e *element) category() string {
+	return e.XMLName.Local
+}
+
+// child returns the child of the given category with the given name, or nil if there is none.
+func (e *element) child(category string, name string) *element {
+	for _, c := range e.Children {
+		if c.category() == category && c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// input returns the input of a node with the given name, or nil if it is not set.
+func (e *element) input(name string) *element {
+	return e.child("input", name)
+}
+
+// node returns the node with the given name among the children of e.
+func (e *element) node(name string) *element {
+	for _, c := range e.Children {
+		if c.Name == name && c.category() != "input" && c.category() != "output" && c.category() != "nodegraph" {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Document represents a MaterialX document.
+type Document struct {
+	root *element
+	// dir is the directory that the file names in the document are relative to.
+	dir string
+}
+
+// NewDocumentFromReader returns the MaterialX document read from r, whose file names are relative to the given
+// directory.
+func NewDocumentFromReader(r io.Reader, containerDirectory string) (*Document, error) {
+	root := &element{}
+	if err := xml.NewDecoder(r).Decode(root); err != nil {
+		return nil, err
+	}
+
+	if root.category() != "materialx" {
+		return nil, fmt.Errorf("unexpected root element %q", root.category())
+	}
+
+	return &Document{
+		root: root,
+		dir:  containerDirectory,
+	}, nil
+}
+
+// NewDocumentFromFile returns the MaterialX document stored in the given file.
+func NewDocumentFromFile(fileName string) (*Document, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return NewDocumentFromReader(f, filepath.Dir(fileName))
+}
+
+// Material translates the surface material with the given name into a principled material of the same name. It
+// also returns the names of the image files the material uses.
+func (d *Document) Material(name string) (*pb_transport.Material, []string, error) {
+	mat := d.root.child("surfacematerial", name)
+	if mat == nil {
+		return nil, nil, fmt.Errorf("%w: %q", ErrMaterialNotFound, name)
+	}
+
+	in := mat.input("surfaceshader")
+	if in == nil || in.NodeName == "" {
+		return nil, nil, fmt.Errorf("material %q has no surface shader", name)
+	}
+
+	shader := d.root.node(in.NodeName)
+	if shader == nil {
+		return nil, nil, fmt.Errorf("%w: node %q", ErrUnresolvedReference, in.NodeName)
+	}
+
+	t := &translator{
+		doc:    d,
+		images: make(map[string]struct{}),
+		path:   make(map[*element]struct{}),
+	}
+
+	principled, err := t.surface(shader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("material %q: %w", name, err)
+	}
+
+	return &pb_transport.Material{
+		Name: name,
+		Type: pb_transport.MaterialType_PRINCIPLED,
+		MaterialProperties: &pb_transport.Material_Principled{
+			Principled: principled,
+		},
+	}, slices.Sorted(maps.Keys(t.images)), nil
+}
+
+// Resolve replaces the materials of the scene that refer to MaterialX documents with their translation, and adds
+// the images they use to the image textures of the scene so that they are loaded like any other.
+func Resolve(scene *pb_transport.Scene) error {
+	documents := make(map[string]*Document)
+
+	for key, mat := range scene.GetMaterials() {
+		if mat.GetType() != pb_transport.MaterialType_MATERIALX {
+			continue
+		}
+
+		ref := mat.GetMaterialx()
+		doc, ok := documents[ref.GetFilename()]
+		if !ok {
+			var err error
+			doc, err = NewDocumentFromFile(ref.GetFilename())
+			if err != nil {
+				return fmt.Errorf("material %s: %w", mat.GetName(), err)
+			}
+			documents[ref.GetFilename()] = doc
+		}
+
+		// The material is looked up by the name it has in the scene unless told otherwise.
+		materialName := ref.GetMaterialName()
+		if materialName == "" {
+			materialName = mat.GetName()
+		}
+
+		translated, images, err := doc.Material(materialName)
+		if err != nil {
+			return fmt.Errorf("material %s: %w", mat.GetName(), err)
+		}
+
+		translated.Name = mat.GetName()
+		scene.Materials[key] = translated
+
+		for _, image := range images {
+			if _, ok := scene.GetImageTextures()[image]; ok {
+				continue
+			}
+			if scene.ImageTextures == nil {
+				scene.ImageTextures = make(map[string]*pb_transport.ImageTextureMetadata)
+			}
+			scene.ImageTextures[image] = &pb_transport.ImageTextureMetadata{
+				Filename: image,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package materialx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+	"google.golang.org/protobuf/proto"
+
+	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
+)
+
+func image(name string, fileName string) *pb_transport.Texture {
+	return &pb_transport.Texture{
+		Name: name,
+		Type: pb_transport.TextureType_IMAGE,
+		TextureProperties: &pb_transport.Texture_Image{
+			Image: &pb_transport.ImageTexture{Filename: fileName},
+		},
+	}
+}
+
+func vec(x, y, z float64) vec3.Vec3Impl {
+	return vec3.Vec3Impl{X: x, Y: y, Z: z}
+}
+
+func TestMaterial(t *testing.T) {
+	albedo := filepath.Join("testdata", "textures", "brick_albedo.png")
+	roughness := filepath.Join("testdata", "textures", "brick_roughness.png")
+	normal := filepath.Join("testdata", "normals", "brick_normal.png")
+
+	testData := []struct {
+		name       string
+		want       *pb_transport.PrincipledMaterial
+		wantImages []string
+		wantErr    error
+	}{
+		{
+			name: "brick",
+			want: &pb_transport.PrincipledMaterial{
+				BaseColor: &pb_transport.Texture{
+					Type: pb_transport.TextureType_MULTIPLY,
+					TextureProperties: &pb_transport.Texture_Multiply{
+						Multiply: &pb_transport.MultiplyTexture{
+							A: image("albedo", albedo),
+							B: constant(vec(0.5, 0.5, 0.5)),
+						},
+					},
+				},
+				Metallic:       constant(vec(0, 0, 0)),
+				Roughness:      image("roughness", roughness),
+				NormalMap:      image("normal_image", normal),
+				Specular:       0.5,
+				Clearcoat:      0.25,
+				ClearcoatGloss: 0.8,
+				Ior:            1.5,
+			},
+			wantImages: []string{normal, albedo, roughness},
+		},
+		{
+			name: "gold",
+			want: &pb_transport.PrincipledMaterial{
+				BaseColor:      constant(vec(0.5, 0.4, 0.2)),
+				Metallic:       constant(vec(1, 1, 1)),
+				Roughness:      constant(vec(0.4, 0.4, 0.4)),
+				Specular:       0.5,
+				Anisotropic:    0.5,
+				Sheen:          0.1,
+				ClearcoatGloss: 1,
+				Ior:            1.5,
+			},
+			wantImages: []string{},
+		},
+		{
+			name:    "masked_coat",
+			wantErr: ErrNotConstant,
+		},
+		{
+			name:    "noisy",
+			wantErr: ErrUnsupportedNode,
+		},
+		{
+			name:    "cyclic",
+			wantErr: ErrCycle,
+		},
+		{
+			name:    "preview",
+			wantErr: ErrUnsupportedShader,
+		},
+		{
+			name:    "missing",
+			wantErr: ErrMaterialNotFound,
+		},
+	}
+
+	doc, err := NewDocumentFromFile(filepath.Join("testdata", "materials.mtlx"))
+	if err != nil {
+		t.Fatalf("NewDocumentFromFile() error = %v", err)
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got, images, err := doc.Material(test.name)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Material() error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+
+			if got.GetName() != test.name || got.GetType() != pb_transport.MaterialType_PRINCIPLED {
+				t.Errorf("Material() = %v %v, want principled material %v", got.GetName(), got.GetType(), test.name)
+			}
+			if !proto.Equal(got.GetPrincipled(), test.want) {
+				t.Errorf("Material() = %v, want %v", got.GetPrincipled(), test.want)
+			}
+			if !slices.Equal(images, test.wantImages) {
+				t.Errorf("Material() images = %v, want %v", images, test.wantImages)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	fileName := filepath.Join("testdata", "materials.mtlx")
+	scene := &pb_transport.Scene{
+		Materials: map[string]*pb_transport.Material{
+			"walls": {
+				Name: "walls",
+				Type: pb_transport.MaterialType_MATERIALX,
+				MaterialProperties: &pb_transport.Material_Materialx{
+					Materialx: &pb_transport.MaterialXReference{Filename: fileName, MaterialName: "brick"},
+				},
+			},
+			"gold": {
+				Name: "gold",
+				Type: pb_transport.MaterialType_MATERIALX,
+				MaterialProperties: &pb_transport.Material_Materialx{
+					Materialx: &pb_transport.MaterialXReference{Filename: fileName},
+				},
+			},
+			"white": {
+				Name: "white",
+				Type: pb_transport.MaterialType_LAMBERT,
+			},
+		},
+	}
+
+	if err := Resolve(scene); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	for _, name := range []string{"walls", "gold"} {
+		mat := scene.GetMaterials()[name]
+		if mat.GetName() != name || mat.GetPrincipled() == nil {
+			t.Errorf("material %v = %v, want principled material %v", name, mat, name)
+		}
+	}
+
+	if got := scene.GetMaterials()["white"].GetType(); got != pb_transport.MaterialType_LAMBERT {
+		t.Errorf("material white type = %v, want %v", got, pb_transport.MaterialType_LAMBERT)
+	}
+
+	if len(scene.GetImageTextures()) != 3 {
+		t.Errorf("image textures = %v, want the 3 images of the brick material", scene.GetImageTextures())
+	}
+	for fileName, metadata := range scene.GetImageTextures() {
+		if metadata.GetFilename() != fileName {
+			t.Errorf("image texture %v has file name %v", fileName, metadata.GetFilename())
+		}
+	}
+
+	scene.Materials["missing"] = &pb_transport.Material{
+		Name: "missing",
+		Type: pb_transport.MaterialType_MATERIALX,
+		MaterialProperties: &pb_transport.Material_Materialx{
+			Materialx: &pb_transport.MaterialXReference{Filename: filepath.Join("testdata", "missing.mtlx")},
+		},
+	}
+
+	if err := Resolve(scene); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Resolve() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
@@ -0,0 +1,291 @@
+package materialx
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+
+	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
+)
+
+// translator turns the node network behind the inputs of a shader into textures.
+type translator struct {
+	doc *Document
+	// images is the set of image files used by the textures.
+	images map[string]struct{}
+	// path is the set of nodes and node graph outputs between the shader and the one being translated.
+	path map[*element]struct{}
+}
+
+// value returns the texture an input evaluates to, where graph is the node graph the input belongs to, or nil if it
+// belongs to a node at the top level of the document. Unset inputs take the given default value.
+func (t *translator) value(in *element, graph *element, def vec3.Vec3Impl) (*pb_transport.Texture, error) {
+	switch {
+	case in == nil:
+		return constant(def), nil
+	case in.InterfaceName != "":
+		if graph == nil {
+			return nil, fmt.Errorf("%w: interface %q outside of a node graph", ErrUnresolvedReference, in.InterfaceName)
+		}
+		// Interface inputs are set on the node graph itself, whose inputs are in turn at the top level.
+		iface := graph.input(in.InterfaceName)
+		if iface == nil {
+			return constant(def), nil
+		}
+		return t.value(iface, nil, def)
+	case in.NodeGraph != "":
+		g := t.doc.root.child("nodegraph", in.NodeGraph)
+		if g == nil {
+			return nil, fmt.Errorf("%w: node graph %q", ErrUnresolvedReference, in.NodeGraph)
+		}
+		out, err := graphOutput(g, in.Output)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.enter(out); err != nil {
+			return nil, fmt.Errorf("output %q of node graph %q: %w", out.Name, g.Name, err)
+		}
+		defer t.leave(out)
+		return t.value(out, g, def)
+	case in.NodeName != "":
+		scope := t.doc.root
+		if graph != nil {
+			scope = graph
+		}
+		n := scope.node(in.NodeName)
+		if n == nil {
+			return nil, fmt.Errorf("%w: node %q", ErrUnresolvedReference, in.NodeName)
+		}
+		if err := t.enter(n); err != nil {
+			return nil, fmt.Errorf("node %q: %w", n.Name, err)
+		}
+		defer t.leave(n)
+		return t.node(n, graph)
+	case in.Value == "":
+		return constant(def), nil
+	}
+
+	v, err := parseValue(in.Type, in.Value)
+	if err != nil {
+		return nil, fmt.Errorf("input %q: %w", in.Name, err)
+	}
+
+	return constant(v), nil
+}
+
+// enter adds e to the path of elements being translated, or fails if it is already on it because the network
+// has a cycle.
+func (t *translator) enter(e *element) error {
+	if _, ok := t.path[e]; ok {
+		return fmt.Errorf("%w through %q", ErrCycle, e.Name)
+	}
+	t.path[e] = struct{}{}
+	return nil
+}
+
+// leave removes e from the path of elements being translated.
+func (t *translator) leave(e *element) {
+	delete(t.path, e)
+}
+
+// graphOutput returns the output of a node graph with the given name, which can be omitted if there is only one.
+func graphOutput(graph *element, name string) (*element, error) {
+	if name != "" {
+		if out := graph.child("output", name); out != nil {
+			return out, nil
+		}
+		return nil, fmt.Errorf("%w: output %q of node graph %q", ErrUnresolvedReference, name, graph.Name)
+	}
+
+	var out *element
+	for _, c := range graph.Children {
+		if c.category() != "output" {
+			continue
+		}
+		if out != nil {
+			return nil, fmt.Errorf("node graph %q has more than one output", graph.Name)
+		}
+		out = c
+	}
+
+	if out == nil {
+		return nil, fmt.Errorf("node graph %q has no outputs", graph.Name)
+	}
+
+	return out, nil
+}
+
+// node returns the texture computed by a node. Combinations of constants are folded into a single constant.
+func (t *translator) node(n *element, graph *element) (*pb_transport.Texture, error) {
+	switch n.category() {
+	case "constant":
+		return t.value(n.input("value"), graph, vec3.Vec3Impl{})
+	case "dot", "normalmap":
+		// Tangent space normals are read from normal maps as they are.
+		return t.value(n.input("in"), graph, vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 1})
+	case "image", "tiledimage":
+		return t.image(n, graph)
+	case "multiply":
+		a, err := t.value(n.input("in1"), graph, vec3.Vec3Impl{})
+		if err != nil {
+			return nil, err
+		}
+		b, err := t.value(n.input("in2"), graph, vec3.Vec3Impl{X: 1, Y: 1, Z: 1})
+		if err != nil {
+			return nil, err
+		}
+		return multiply(a, b), nil
+	case "mix":
+		fg, err := t.value(n.input("fg"), graph, vec3.Vec3Impl{})
+		if err != nil {
+			return nil, err
+		}
+		bg, err := t.value(n.input("bg"), graph, vec3.Vec3Impl{})
+		if err != nil {
+			return nil, err
+		}
+		weight, err := t.value(n.input("mix"), graph, vec3.Vec3Impl{})
+		if err != nil {
+			return nil, err
+		}
+		return mix(fg, bg, weight), nil
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrUnsupportedNode, n.category())
+}
+
+// image returns the texture of an image node, whose file name is relative to the directory of the document after
+// applying the closest file prefix.
+func (t *translator) image(n *element, graph *element) (*pb_transport.Texture, error) {
+	file := n.input("file")
+	if file == nil {
+		return nil, fmt.Errorf("image node %q has no file", n.Name)
+	}
+
+	// The file name may be set on the node graph.
+	if file.InterfaceName != "" && graph != nil {
+		if iface := graph.input(file.InterfaceName); iface != nil {
+			file = iface
+		}
+	}
+
+	if file.Value == "" {
+		return nil, fmt.Errorf("image node %q has no file", n.Name)
+	}
+
+	prefix := t.doc.root.FilePrefix
+	for _, e := range []*element{graph, n, file} {
+		if e != nil && e.FilePrefix != "" {
+			prefix = e.FilePrefix
+		}
+	}
+
+	fileName := prefix + file.Value
+	if !filepath.IsAbs(fileName) {
+		fileName = filepath.Join(t.doc.dir, fileName)
+	}
+
+	t.images[fileName] = struct{}{}
+
+	return &pb_transport.Texture{
+		Name: n.Name,
+		Type: pb_transport.TextureType_IMAGE,
+		TextureProperties: &pb_transport.Texture_Image{
+			Image: &pb_transport.ImageTexture{
+				Filename: fileName,
+			},
+		},
+	}, nil
+}
+
+// parseValue parses the value of an input. Scalars are replicated across all three channels and the alpha channel
+// of colours is dropped.
+func parseValue(typ string, value string) (vec3.Vec3Impl, error) {
+	fields := strings.Split(value, ",")
+	floats := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return vec3.Vec3Impl{}, err
+		}
+		floats[i] = v
+	}
+
+	switch {
+	case (typ == "float" || typ == "integer") && len(floats) == 1:
+		return vec3.Vec3Impl{X: floats[0], Y: floats[0], Z: floats[0]}, nil
+	case (typ == "color3" || typ == "vector3") && len(floats) == 3, typ == "color4" && len(floats) == 4:
+		return vec3.Vec3Impl{X: floats[0], Y: floats[1], Z: floats[2]}, nil
+	}
+
+	return vec3.Vec3Impl{}, fmt.Errorf("unsupported value %q of type %q", value, typ)
+}
+
+// constant returns a constant texture with the given value.
+func constant(v vec3.Vec3Impl) *pb_transport.Texture {
+	return &pb_transport.Texture{
+		Type: pb_transport.TextureType_CONSTANT,
+		TextureProperties: &pb_transport.Texture_Constant{
+			Constant: &pb_transport.ConstantTexture{
+				Value: &pb_transport.Vec3{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z)},
+			},
+		},
+	}
+}
+
+// constantValue returns the value of a constant texture, or false if the texture varies over the surface.
+func constantValue(tex *pb_transport.Texture) (vec3.Vec3Impl, bool) {
+	c := tex.GetConstant()
+	if c == nil {
+		return vec3.Vec3Impl{}, false
+	}
+
+	return vec3.Vec3Impl{X: float64(c.GetValue().GetX()), Y: float64(c.GetValue().GetY()), Z: float64(c.GetValue().GetZ())}, true
+}
+
+// multiply returns the product of two textures.
+func multiply(a *pb_transport.Texture, b *pb_transport.Texture) *pb_transport.Texture {
+	va, okA := constantValue(a)
+	vb, okB := constantValue(b)
+	switch {
+	case okA && okB:
+		return constant(vec3.Mul(va, vb))
+	case okA && va == (vec3.Vec3Impl{X: 1, Y: 1, Z: 1}):
+		return b
+	case okB && vb == (vec3.Vec3Impl{X: 1, Y: 1, Z: 1}):
+		return a
+	}
+
+	return &pb_transport.Texture{
+		Type: pb_transport.TextureType_MULTIPLY,
+		TextureProperties: &pb_transport.Texture_Multiply{
+			Multiply: &pb_transport.MultiplyTexture{A: a, B: b},
+		},
+	}
+}
+
+// mix returns the linear interpolation between fg and bg with the given weight.
+func mix(fg *pb_transport.Texture, bg *pb_transport.Texture, weight *pb_transport.Texture) *pb_transport.Texture {
+	if w, ok := constantValue(weight); ok {
+		vf, okF := constantValue(fg)
+		vb, okB := constantValue(bg)
+		switch {
+		case okF && okB:
+			return constant(vec3.Add(vec3.ScalarMul(vf, w.X), vec3.ScalarMul(vb, 1-w.X)))
+		case w.X == 1:
+			return fg
+		case w.X == 0:
+			return bg
+		}
+	}
+
+	return &pb_transport.Texture{
+		Type: pb_transport.TextureType_MIX,
+		TextureProperties: &pb_transport.Texture_Mix{
+			Mix: &pb_transport.MixTexture{Fg: fg, Bg: bg, Mix: weight},
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package materialx
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+
+	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
+)
+
+// surfaceInput is an input of a shading model together with its default value.
+type surfaceInput struct {
+	name string
+	def  float64
+}
+
+// shadingModel names the inputs of a shading model that map to the parameters of a principled material.
+type shadingModel struct {
+	base          surfaceInput
+	baseColor     surfaceInput
+	metalness     surfaceInput
+	specular      surfaceInput
+	roughness     surfaceInput
+	anisotropy    surfaceInput
+	ior           surfaceInput
+	transmission  surfaceInput
+	subsurface    surfaceInput
+	sheen         surfaceInput
+	coat          surfaceInput
+	coatRoughness surfaceInput
+	normal        string
+}
+
+var shadingModels = map[string]shadingModel{
+	"standard_surface": {
+		base:          surfaceInput{"base", 0.8},
+		baseColor:     surfaceInput{"base_color", 1},
+		metalness:     surfaceInput{"metalness", 0},
+		specular:      surfaceInput{"specular", 1},
+		roughness:     surfaceInput{"specular_roughness", 0.2},
+		anisotropy:    surfaceInput{"specular_anisotropy", 0},
+		ior:           surfaceInput{"specular_IOR", 1.5},
+		transmission:  surfaceInput{"transmission", 0},
+		subsurface:    surfaceInput{"subsurface", 0},
+		sheen:         surfaceInput{"sheen", 0},
+		coat:          surfaceInput{"coat", 0},
+		coatRoughness: surfaceInput{"coat_roughness", 0.1},
+		normal:        "normal",
+	},
+	"open_pbr_surface": {
+		base:          surfaceInput{"base_weight", 1},
+		baseColor:     surfaceInput{"base_color", 0.8},
+		metalness:     surfaceInput{"base_metalness", 0},
+		specular:      surfaceInput{"specular_weight", 1},
+		roughness:     surfaceInput{"specular_roughness", 0.3},
+		anisotropy:    surfaceInput{"specular_roughness_anisotropy", 0},
+		ior:           surfaceInput{"specular_ior", 1.5},
+		transmission:  surfaceInput{"transmission_weight", 0},
+		subsurface:    surfaceInput{"subsurface_weight", 0},
+		sheen:         surfaceInput{"fuzz_weight", 0},
+		coat:          surfaceInput{"coat_weight", 0},
+		coatRoughness: surfaceInput{"coat_roughness", 0},
+		normal:        "geometry_normal",
+	},
+}
+
+// surface translates a surface shader into the parameters of a principled material. The base colour, metalness,
+// roughness and normal can be driven by textures while the rest of the inputs must be constant.
+func (t *translator) surface(shader *element) (*pb_transport.PrincipledMaterial, error) {
+	model, ok := shadingModels[shader.category()]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedShader, shader.category())
+	}
+
+	base, err := t.texture(shader, model.base)
+	if err != nil {
+		return nil, err
+	}
+
+	baseColor, err := t.texture(shader, model.baseColor)
+	if err != nil {
+		return nil, err
+	}
+
+	metallic, err := t.texture(shader, model.metalness)
+	if err != nil {
+		return nil, err
+	}
+
+	roughness, err := t.texture(shader, model.roughness)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalMap *pb_transport.Texture
+	if in := shader.input(model.normal); in != nil {
+		normal, err := t.value(in, nil, vec3.Vec3Impl{})
+		if err != nil {
+			return nil, err
+		}
+		// A constant normal is that of the geometry.
+		if _, ok := constantValue(normal); !ok {
+			normalMap = normal
+		}
+	}
+
+	var scalars [8]float64
+	for i, in := range []surfaceInput{
+		model.specular, model.anisotropy, model.ior, model.transmission,
+		model.subsurface, model.sheen, model.coat, model.coatRoughness,
+	} {
+		if scalars[i], err = t.scalar(shader, in); err != nil {
+			return nil, err
+		}
+	}
+	specular, anisotropy, ior, transmission := scalars[0], scalars[1], scalars[2], scalars[3]
+	subsurface, sheen, coat, coatRoughness := scalars[4], scalars[5], scalars[6], scalars[7]
+
+	// The specular parameter of the principled material maps 0.08 to a reflectance of 1 at normal incidence.
+	f0 := (ior - 1) / (ior + 1)
+	f0 *= f0
+
+	return &pb_transport.PrincipledMaterial{
+		BaseColor:      multiply(base, baseColor),
+		Metallic:       metallic,
+		Roughness:      roughness,
+		NormalMap:      normalMap,
+		Specular:       float32(math.Min(1, specular*f0/0.08)),
+		Anisotropic:    float32(anisotropy),
+		Sheen:          float32(sheen),
+		Clearcoat:      float32(coat),
+		ClearcoatGloss: float32(1 - coatRoughness),
+		Transmission:   float32(transmission),
+		Subsurface:     float32(subsurface),
+		Ior:            float32(ior),
+	}, nil
+}
+
+// texture returns the texture an input of a shader evaluates to.
+func (t *translator) texture(shader *element, in surfaceInput) (*pb_transport.Texture, error) {
+	tex, err := t.value(shader.input(in.name), nil, vec3.Vec3Impl{X: in.def, Y: in.def, Z: in.def})
+	if err != nil {
+		return nil, fmt.Errorf("input %q: %w", in.name, err)
+	}
+
+	return tex, nil
+}
+
+// scalar returns the value of an input of a shader that must not vary over the surface.
+func (t *translator) scalar(shader *element, in surfaceInput) (float64, error) {
+	tex, err := t.texture(shader, in)
+	if err != nil {
+		return 0, err
+	}
+
+	v, ok := constantValue(tex)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrNotConstant, in.name)
+	}
+
+	return v.X, nil
+}
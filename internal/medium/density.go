@@ -0,0 +1,173 @@
+package medium
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/perlin"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ Density = (*ConstantDensity)(nil)
+var _ Density = (*GridDensity)(nil)
+var _ Density = (*NoiseDensity)(nil)
+var _ Density = (*ScaledDensity)(nil)
+
+// Density describes how the density of a medium varies in space.
+type Density interface {
+	// Value returns the density at p.
+	Value(p vec3.Vec3Impl) float64
+	// Max returns an upper bound of the density anywhere.
+	Max() float64
+}
+
+// ConstantDensity is the same density everywhere.
+type ConstantDensity struct {
+	density float64
+}
+
+// NewConstantDensity returns a constant density.
+func NewConstantDensity(density float64) *ConstantDensity {
+	return &ConstantDensity{density: density}
+}
+
+func (c *ConstantDensity) Value(_ vec3.Vec3Impl) float64 {
+	return c.density
+}
+
+func (c *ConstantDensity) Max() float64 {
+	return c.density
+}
+
+// GridDensity is a density sampled on a regular grid of points spanning an axis-aligned box, which is
+// interpolated trilinearly between them. The density outside the box is zero.
+type GridDensity struct {
+	min, max   vec3.Vec3Impl
+	nx, ny, nz int
+	values     []float64
+	maxValue   float64
+}
+
+// NewGridDensity returns a grid density with nx*ny*nz values, which are stored with x varying fastest and z slowest,
+// spanning the box between min and max.
+func NewGridDensity(min vec3.Vec3Impl, max vec3.Vec3Impl, nx, ny, nz int, values []float64) *GridDensity {
+	g := &GridDensity{
+		min:    min,
+		max:    max,
+		nx:     nx,
+		ny:     ny,
+		nz:     nz,
+		values: values,
+	}
+	for _, v := range values {
+		g.maxValue = math.Max(g.maxValue, v)
+	}
+
+	return g
+}
+
+func (g *GridDensity) Value(p vec3.Vec3Impl) float64 {
+	if g.nx == 0 || g.ny == 0 || g.nz == 0 || len(g.values) < g.nx*g.ny*g.nz {
+		return 0
+	}
+
+	x, okX := gridCoordinate(p.X, g.min.X, g.max.X, g.nx)
+	y, okY := gridCoordinate(p.Y, g.min.Y, g.max.Y, g.ny)
+	z, okZ := gridCoordinate(p.Z, g.min.Z, g.max.Z, g.nz)
+	if !okX || !okY || !okZ {
+		return 0
+	}
+
+	x0, y0, z0 := int(x), int(y), int(z)
+	fx, fy, fz := x-float64(x0), y-float64(y0), z-float64(z0)
+
+	var d float64
+	for i := range 2 {
+		for j := range 2 {
+			for k := range 2 {
+				w := lerpWeight(fx, i) * lerpWeight(fy, j) * lerpWeight(fz, k)
+				if w == 0 {
+					continue
+				}
+				d += w * g.at(x0+i, y0+j, z0+k)
+			}
+		}
+	}
+
+	return d
+}
+
+func (g *GridDensity) Max() float64 {
+	return g.maxValue
+}
+
+// at returns the value at the given grid point, clamped to the grid.
+func (g *GridDensity) at(x, y, z int) float64 {
+	x, y, z = min(x, g.nx-1), min(y, g.ny-1), min(z, g.nz-1)
+	return g.values[(z*g.ny+y)*g.nx+x]
+}
+
+// gridCoordinate maps v, between lo and hi, to the continuous coordinate of a grid with n points spanning them.
+// It returns false if v lies outside the grid.
+func gridCoordinate(v, lo, hi float64, n int) (float64, bool) {
+	if v < lo || v > hi || hi <= lo {
+		return 0, false
+	}
+
+	return (v - lo) / (hi - lo) * float64(n-1), true
+}
+
+// lerpWeight returns the weight of the lower (i = 0) or upper (i = 1) neighbour at fraction f between them.
+func lerpWeight(f float64, i int) float64 {
+	if i == 0 {
+		return 1 - f
+	}
+	return f
+}
+
+// NoiseDensity is a procedural density made of Perlin turbulence, for clouds and smoke.
+type NoiseDensity struct {
+	perlin    *perlin.Perlin
+	density   float64
+	frequency float64
+	octaves   int
+}
+
+// NewNoiseDensity returns a density that varies between zero and the given density following Perlin turbulence
+// with the given frequency and number of octaves.
+func NewNoiseDensity(density float64, frequency float64, octaves int) *NoiseDensity {
+	return &NoiseDensity{
+		perlin:    perlin.New(),
+		density:   density,
+		frequency: frequency,
+		octaves:   max(octaves, 1),
+	}
+}
+
+func (n *NoiseDensity) Value(p vec3.Vec3Impl) float64 {
+	t := n.perlin.Turb(vec3.ScalarMul(p, n.frequency), n.octaves)
+	return n.density * math.Max(0, math.Min(1, t))
+}
+
+func (n *NoiseDensity) Max() float64 {
+	return n.density
+}
+
+// ScaledDensity is another density multiplied by a constant factor.
+type ScaledDensity struct {
+	density Density
+	scale   float64
+}
+
+// NewScaledDensity returns the given density multiplied by scale.
+func NewScaledDensity(density Density, scale float64) *ScaledDensity {
+	return &ScaledDensity{density: density, scale: scale}
+}
+
+func (s *ScaledDensity) Value(p vec3.Vec3Impl) float64 {
+	return s.density.Value(p) * s.scale
+}
+
+func (s *ScaledDensity) Max() float64 {
+	return s.density.Max() * s.scale
+}
@@ -0,0 +1,122 @@
+package medium
+
+import (
+	"math"
+	"sync"
+
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// blackbody holds the temperature field of a medium that glows like a blackbody, as fire and explosions do.
+type blackbody struct {
+	temperature      Density
+	temperatureScale float64
+	emissionScale    float64
+}
+
+// SetBlackbodyEmission makes the medium emit light wherever it absorbs it, with the spectral radiance of a blackbody
+// in W/(sr·m²·nm) at the temperature in kelvin given by the temperature field times temperatureScale, multiplied by
+// emissionScale.
+func (m *Medium) SetBlackbodyEmission(temperature Density, temperatureScale float64, emissionScale float64) {
+	m.emission = &blackbody{
+		temperature:      temperature,
+		temperatureScale: temperatureScale,
+		emissionScale:    emissionScale,
+	}
+}
+
+// Emissive returns whether the medium emits light.
+func (m *Medium) Emissive() bool {
+	return m.emission != nil && m.emission.emissionScale > 0
+}
+
+// emitted returns the radiance emitted at p for every channel of s.
+func (m *Medium) emitted(p vec3.Vec3Impl, s Spectrum) Values {
+	var le Values
+	t := m.emission.temperature.Value(p) * m.emission.temperatureScale
+	if t <= 0 {
+		return le
+	}
+
+	if s.n == 0 {
+		rgb := blackbodyRGB(t)
+		return Values{rgb.X * m.emission.emissionScale, rgb.Y * m.emission.emissionScale, rgb.Z * m.emission.emissionScale}
+	}
+
+	for i, lambda := range s.wavelengths[:s.n] {
+		le[i] = planck(lambda, t) * m.emission.emissionScale
+	}
+
+	return le
+}
+
+// planck returns the spectral radiance in W/(sr·m²·nm) of a blackbody at the given temperature in kelvin.
+func planck(lambda float64, temperature float64) float64 {
+	const (
+		h = 6.62607015e-34 // Planck's constant (J·s)
+		c = 2.99792458e8   // Speed of light (m/s)
+		k = 1.380649e-23   // Boltzmann constant (J/K)
+	)
+
+	l := lambda * 1e-9
+	exponent := h * c / (l * k * temperature)
+	if exponent > 700 {
+		return 0
+	}
+
+	// Planck's law gives the radiance per metre of wavelength.
+	return 2 * h * c * c / (l * l * l * l * l * math.Expm1(exponent)) * 1e-9
+}
+
+const (
+	// blackbodyTableMax is the highest temperature, in kelvin, of the table of the colours of blackbodies.
+	// The colours of hotter blackbodies are computed when needed.
+	blackbodyTableMax  = 12000
+	blackbodyTableStep = 10
+)
+
+var (
+	blackbodyTableOnce sync.Once
+	blackbodyTable     []vec3.Vec3Impl
+)
+
+// blackbodyRGB returns the ACEScg colour of a blackbody at the given temperature in kelvin, scaled so that a
+// spectrum of constant radiance has the same luminance when traced by the colour and the spectral samplers.
+func blackbodyRGB(temperature float64) vec3.Vec3Impl {
+	if temperature >= blackbodyTableMax {
+		return blackbodyColour(temperature)
+	}
+
+	blackbodyTableOnce.Do(func() {
+		blackbodyTable = make([]vec3.Vec3Impl, blackbodyTableMax/blackbodyTableStep+1)
+		for i := range blackbodyTable {
+			blackbodyTable[i] = blackbodyColour(float64(i * blackbodyTableStep))
+		}
+	})
+
+	x := temperature / blackbodyTableStep
+	i := int(x)
+	f := x - float64(i)
+	return vec3.Add(vec3.ScalarMul(blackbodyTable[i], 1-f), vec3.ScalarMul(blackbodyTable[i+1], f))
+}
+
+// blackbodyColour integrates the radiance of a blackbody against the CIE colour matching functions.
+func blackbodyColour(temperature float64) vec3.Vec3Impl {
+	if temperature <= 0 {
+		return vec3.Vec3Impl{}
+	}
+
+	var x, y, z, norm float64
+	for lambda := float64(spectral.WavelengthMin); lambda <= spectral.WavelengthMax; lambda += 5 {
+		b := planck(lambda, temperature)
+		cx, cy, cz := spectral.GetCIEValues(lambda)
+		x += b * cx
+		y += b * cy
+		z += b * cz
+		norm += cy
+	}
+
+	r, g, bl := spectral.XYZToACEScg(x/norm, y/norm, z/norm)
+	return vec3.Vec3Impl{X: math.Max(r, 0), Y: math.Max(g, 0), Z: math.Max(bl, 0)}
+}
@@ -0,0 +1,153 @@
+// Package medium implements participating media, such as smoke, fog or murky water, that absorb and scatter light
+// as it travels through them rather than at surfaces.
+package medium
+
+import (
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Values holds a value for every channel of a Spectrum.
+type Values [spectral.NumHeroWavelengths]float64
+
+// Mul returns the product of v and o channel by channel.
+func (v Values) Mul(o Values) Values {
+	for i := range v {
+		v[i] *= o[i]
+	}
+	return v
+}
+
+// Spectrum describes the channels along which light is traced through a medium: the red, green and blue channels
+// of the colour samplers or the wavelengths traced by the spectral sampler.
+type Spectrum struct {
+	wavelengths spectral.Wavelengths
+	n           int
+}
+
+// RGB returns the spectrum traced by the colour samplers.
+func RGB() Spectrum {
+	return Spectrum{}
+}
+
+// Wavelengths returns the spectrum made of the first n of the given wavelengths.
+func Wavelengths(wavelengths spectral.Wavelengths, n int) Spectrum {
+	return Spectrum{wavelengths: wavelengths, n: n}
+}
+
+// Len returns the number of channels of s.
+func (s Spectrum) Len() int {
+	if s.n == 0 {
+		return 3
+	}
+
+	return s.n
+}
+
+// Ones returns a value of 1 for every channel of s.
+func (s Spectrum) Ones() Values {
+	var v Values
+	for i := range s.Len() {
+		v[i] = 1
+	}
+	return v
+}
+
+// Evaluate returns the value of every channel of s, given by the red, green and blue components of v for the
+// colour samplers and by f at every wavelength otherwise.
+func (s Spectrum) Evaluate(v vec3.Vec3Impl, f func(lambda float64) float64) Values {
+	if s.n == 0 {
+		return Values{v.X, v.Y, v.Z}
+	}
+
+	var values Values
+	for i, lambda := range s.wavelengths[:s.n] {
+		values[i] = f(lambda)
+	}
+	return values
+}
+
+// Medium represents a participating medium. Its absorption and scattering coefficients, per unit of distance,
+// are scaled at every point by its density.
+type Medium struct {
+	sigmaA vec3.Vec3Impl
+	sigmaS vec3.Vec3Impl
+	// spectralA and spectralS are the coefficients used by the spectral sampler. If they are nil the coefficients
+	// are derived from sigmaA and sigmaS.
+	spectralA texture.SpectralTexture
+	spectralS texture.SpectralTexture
+	density   Density
+	phase     HenyeyGreenstein
+	// emission is nil unless the medium glows.
+	emission *blackbody
+}
+
+// NewHomogeneous returns a medium with the same absorption and scattering coefficients everywhere.
+func NewHomogeneous(sigmaA vec3.Vec3Impl, sigmaS vec3.Vec3Impl, g float64) *Medium {
+	return NewHeterogeneous(sigmaA, sigmaS, NewConstantDensity(1), g)
+}
+
+// NewHeterogeneous returns a medium whose absorption and scattering coefficients are scaled by the given density.
+func NewHeterogeneous(sigmaA vec3.Vec3Impl, sigmaS vec3.Vec3Impl, density Density, g float64) *Medium {
+	return &Medium{
+		sigmaA:  sigmaA,
+		sigmaS:  sigmaS,
+		density: density,
+		phase:   NewHenyeyGreenstein(g),
+	}
+}
+
+// SetSpectralCoefficients sets the absorption and scattering coefficients used when tracing wavelengths.
+// A nil coefficient is derived from the red, green and blue coefficients of the medium.
+func (m *Medium) SetSpectralCoefficients(sigmaA texture.SpectralTexture, sigmaS texture.SpectralTexture) {
+	m.spectralA = sigmaA
+	m.spectralS = sigmaS
+}
+
+// Phase returns the phase function of the medium.
+func (m *Medium) Phase() HenyeyGreenstein {
+	return m.phase
+}
+
+// Homogeneous returns whether the density of the medium is the same everywhere.
+func (m *Medium) Homogeneous() bool {
+	_, ok := m.density.(*ConstantDensity)
+	return ok
+}
+
+// coefficients returns the extinction and scattering coefficients of the medium at unit density for every
+// channel of s.
+func (m *Medium) coefficients(s Spectrum) (Values, Values) {
+	var sigmaT, sigmaS Values
+	if s.n == 0 {
+		sigmaT = Values{m.sigmaA.X + m.sigmaS.X, m.sigmaA.Y + m.sigmaS.Y, m.sigmaA.Z + m.sigmaS.Z}
+		sigmaS = Values{m.sigmaS.X, m.sigmaS.Y, m.sigmaS.Z}
+		return sigmaT, sigmaS
+	}
+
+	for i, lambda := range s.wavelengths[:s.n] {
+		a, sc := bandValue(m.sigmaA, lambda), bandValue(m.sigmaS, lambda)
+		if m.spectralA != nil {
+			a = m.spectralA.Value(0, 0, lambda, vec3.Vec3Impl{})
+		}
+		if m.spectralS != nil {
+			sc = m.spectralS.Value(0, 0, lambda, vec3.Vec3Impl{})
+		}
+		sigmaT[i], sigmaS[i] = a+sc, sc
+	}
+
+	return sigmaT, sigmaS
+}
+
+// bandValue returns the component of the RGB value v whose band of the visible spectrum contains lambda.
+func bandValue(v vec3.Vec3Impl, lambda float64) float64 {
+	switch {
+	case lambda < 490:
+		return v.Z
+	case lambda < 580:
+		return v.Y
+	default:
+		return v.X
+	}
+}
@@ -0,0 +1,249 @@
+package medium
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestHenyeyGreenstein(t *testing.T) {
+	testData := []struct {
+		name string
+		g    float64
+	}{
+		{name: "isotropic", g: 0},
+		{name: "forward", g: 0.7},
+		{name: "backward", g: -0.4},
+	}
+
+	const n = 200000
+	wi := vec3.Vec3Impl{X: 0.3, Y: -1, Z: 2}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			hg := NewHenyeyGreenstein(test.g)
+			random := fastrandom.NewWithSeed(1)
+
+			// The phase function integrates to one over the sphere and the mean cosine of the directions it
+			// samples is g.
+			var integral, meanCos float64
+			for range n {
+				z := 1 - 2*random.Float64()
+				phi := 2 * math.Pi * random.Float64()
+				r := math.Sqrt(1 - z*z)
+				integral += hg.Evaluate(wi, vec3.Vec3Impl{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z}) * 4 * math.Pi
+
+				wo, pdf := hg.Sample(wi, random)
+				if got := hg.Evaluate(wi, wo); math.Abs(got-pdf) > 1e-9*pdf {
+					t.Fatalf("Sample() returned density %v, Evaluate() returned %v", pdf, got)
+				}
+				meanCos += vec3.Dot(vec3.UnitVector(wi), wo)
+			}
+
+			if got := integral / n; math.Abs(got-1) > 0.02 {
+				t.Errorf("integral = %v, want 1", got)
+			}
+			if got := meanCos / n; math.Abs(got-test.g) > 0.01 {
+				t.Errorf("mean cosine = %v, want %v", got, test.g)
+			}
+		})
+	}
+}
+
+func TestGridDensity(t *testing.T) {
+	g := NewGridDensity(vec3.Vec3Impl{}, vec3.Vec3Impl{X: 2, Y: 1, Z: 1}, 3, 2, 2, []float64{
+		0, 1, 2,
+		0, 1, 2,
+		4, 5, 6,
+		4, 5, 6,
+	})
+
+	testData := []struct {
+		name string
+		p    vec3.Vec3Impl
+		want float64
+	}{
+		{name: "corner", p: vec3.Vec3Impl{}, want: 0},
+		{name: "grid point", p: vec3.Vec3Impl{X: 2, Y: 1, Z: 1}, want: 6},
+		{name: "interpolated", p: vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5}, want: 2.5},
+		{name: "outside", p: vec3.Vec3Impl{X: 3}, want: 0},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if got := g.Value(test.p); math.Abs(got-test.want) > 1e-12 {
+				t.Errorf("Value(%v) = %v, want %v", test.p, got, test.want)
+			}
+		})
+	}
+
+	if got := g.Max(); got != 6 {
+		t.Errorf("Max() = %v, want 6", got)
+	}
+}
+
+func TestTracking(t *testing.T) {
+	sigmaA := vec3.Vec3Impl{X: 0.1, Y: 0.3, Z: 0.6}
+	sigmaS := vec3.Vec3Impl{X: 0.5, Y: 0.2, Z: 0.1}
+	ones := make([]float64, 8)
+	for i := range ones {
+		ones[i] = 1
+	}
+
+	testData := []struct {
+		name     string
+		medium   *Medium
+		spectrum Spectrum
+	}{
+		{
+			name:     "homogeneous rgb",
+			medium:   NewHomogeneous(sigmaA, sigmaS, 0.3),
+			spectrum: RGB(),
+		},
+		{
+			name:     "heterogeneous rgb",
+			medium:   NewHeterogeneous(sigmaA, sigmaS, NewGridDensity(vec3.Vec3Impl{X: -10, Y: -10, Z: -10}, vec3.Vec3Impl{X: 10, Y: 10, Z: 10}, 2, 2, 2, ones), 0),
+			spectrum: RGB(),
+		},
+		{
+			name:     "heterogeneous wavelengths",
+			medium:   NewHeterogeneous(sigmaA, sigmaS, NewGridDensity(vec3.Vec3Impl{X: -10, Y: -10, Z: -10}, vec3.Vec3Impl{X: 10, Y: 10, Z: 10}, 2, 2, 2, ones), 0),
+			spectrum: Wavelengths(spectral.Wavelengths{450, 530, 620, 700}, 4),
+		},
+	}
+
+	const n = 200000
+	// The ray covers a distance of 4 between the ray parameters 0.5 and 2.5.
+	r := ray.New(vec3.Vec3Impl{X: -3}, vec3.Vec3Impl{Y: 2}, 0)
+	tMin, tMax := 0.5, 2.5
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			random := fastrandom.NewWithSeed(1)
+			sigmaT, sigmaS := test.medium.coefficients(test.spectrum)
+
+			var transmittance, escaped, scattered Values
+			for range n {
+				transmittance = add(transmittance, test.medium.Transmittance(r, tMin, tMax, test.spectrum, random))
+
+				tc, w, _, collided := test.medium.SampleCollision(r, tMin, tMax, test.spectrum, random)
+				if collided {
+					if tc < tMin || tc >= tMax {
+						t.Fatalf("collision at %v, outside [%v, %v)", tc, tMin, tMax)
+					}
+					scattered = add(scattered, w)
+				} else {
+					escaped = add(escaped, w)
+				}
+			}
+
+			for i := range test.spectrum.Len() {
+				// T = exp(-σt * d) and the light scattered before reaching tMax is σs / σt * (1 - T).
+				want := math.Exp(-sigmaT[i] * 4)
+				if got := transmittance[i] / n; math.Abs(got-want) > 0.01 {
+					t.Errorf("channel %d: transmittance = %v, want %v", i, got, want)
+				}
+				if got := escaped[i] / n; math.Abs(got-want) > 0.01 {
+					t.Errorf("channel %d: escaped weight = %v, want %v", i, got, want)
+				}
+				if got, want := scattered[i]/n, sigmaS[i]/sigmaT[i]*(1-want); math.Abs(got-want) > 0.01 {
+					t.Errorf("channel %d: scattered weight = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmission(t *testing.T) {
+	sigmaA := vec3.Vec3Impl{X: 0.1, Y: 0.3, Z: 0.6}
+	sigmaS := vec3.Vec3Impl{X: 0.5, Y: 0.2, Z: 0.1}
+	ones := make([]float64, 8)
+	for i := range ones {
+		ones[i] = 1
+	}
+
+	testData := []struct {
+		name     string
+		medium   *Medium
+		spectrum Spectrum
+	}{
+		{
+			name:     "homogeneous rgb",
+			medium:   NewHomogeneous(sigmaA, sigmaS, 0),
+			spectrum: RGB(),
+		},
+		{
+			name:     "heterogeneous wavelengths",
+			medium:   NewHeterogeneous(sigmaA, sigmaS, NewGridDensity(vec3.Vec3Impl{X: -10, Y: -10, Z: -10}, vec3.Vec3Impl{X: 10, Y: 10, Z: 10}, 2, 2, 2, ones), 0),
+			spectrum: Wavelengths(spectral.Wavelengths{450, 530, 620, 700}, 4),
+		},
+	}
+
+	const n = 200000
+	r := ray.New(vec3.Vec3Impl{X: -3}, vec3.Vec3Impl{Y: 2}, 0)
+	tMin, tMax := 0.5, 2.5
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			// A temperature of 1500 K is given as 1.5 thousands of kelvin.
+			test.medium.SetBlackbodyEmission(NewConstantDensity(1.5), 1000, 1)
+			random := fastrandom.NewWithSeed(1)
+			sigmaT, sigmaS := test.medium.coefficients(test.spectrum)
+			le := test.medium.emitted(vec3.Vec3Impl{}, test.spectrum)
+
+			var emitted Values
+			for range n {
+				_, _, e, _ := test.medium.SampleCollision(r, tMin, tMax, test.spectrum, random)
+				emitted = add(emitted, e)
+			}
+
+			for i := range test.spectrum.Len() {
+				if le[i] <= 0 {
+					t.Fatalf("channel %d: emitted radiance = %v, want a positive value", i, le[i])
+				}
+				// The radiance that reaches the start of the segment is σa / σt * Le * (1 - exp(-σt * d)).
+				want := (sigmaT[i] - sigmaS[i]) / sigmaT[i] * le[i] * (1 - math.Exp(-sigmaT[i]*4))
+				if got := emitted[i] / n; math.Abs(got-want) > 0.02*want {
+					t.Errorf("channel %d: emitted = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBlackbodyRGB(t *testing.T) {
+	testData := []struct {
+		name        string
+		temperature float64
+	}{
+		{name: "ember", temperature: 1000},
+		{name: "flame", temperature: 1900.5},
+		{name: "white hot", temperature: 6500},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got, want := blackbodyRGB(test.temperature), blackbodyColour(test.temperature)
+			for i, v := range []float64{got.X - want.X, got.Y - want.Y, got.Z - want.Z} {
+				if math.Abs(v) > 0.01*want.Y {
+					t.Errorf("channel %d: blackbodyRGB(%v) = %v, want %v", i, test.temperature, got, want)
+				}
+			}
+			// Blackbodies cooler than the sun are redder than white.
+			if test.temperature < 5000 && got.X <= got.Z {
+				t.Errorf("blackbodyRGB(%v) = %v, want more red than blue", test.temperature, got)
+			}
+		})
+	}
+}
+
+func add(a Values, b Values) Values {
+	for i := range a {
+		a[i] += b[i]
+	}
+	return a
+}
@@ -0,0 +1,53 @@
+package medium
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/onb"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// HenyeyGreenstein is the Henyey-Greenstein phase function, which describes the distribution of the directions in
+// which light is scattered within a medium with a single asymmetry parameter g in (-1, 1). Positive values of g
+// scatter light forwards, negative values backwards and zero in every direction alike.
+type HenyeyGreenstein struct {
+	g float64
+}
+
+// NewHenyeyGreenstein returns a Henyey-Greenstein phase function with the given asymmetry, clamped to (-1, 1).
+func NewHenyeyGreenstein(g float64) HenyeyGreenstein {
+	return HenyeyGreenstein{g: math.Max(-0.99, math.Min(0.99, g))}
+}
+
+// Evaluate returns the density with respect to solid angle with which light travelling in direction wi is
+// scattered into direction wo.
+func (hg HenyeyGreenstein) Evaluate(wi vec3.Vec3Impl, wo vec3.Vec3Impl) float64 {
+	cosTheta := vec3.Dot(vec3.UnitVector(wi), vec3.UnitVector(wo))
+	denom := 1 + hg.g*hg.g - 2*hg.g*cosTheta
+
+	return (1 - hg.g*hg.g) / (4 * math.Pi * denom * math.Sqrt(denom))
+}
+
+// Sample returns a direction into which light travelling in direction wi is scattered, chosen with the density
+// returned by Evaluate, and that density.
+func (hg HenyeyGreenstein) Sample(wi vec3.Vec3Impl, random *fastrandom.LCG) (vec3.Vec3Impl, float64) {
+	u1, u2 := random.Float64(), random.Float64()
+
+	var cosTheta float64
+	if math.Abs(hg.g) < 1e-3 {
+		cosTheta = 1 - 2*u1
+	} else {
+		sqr := (1 - hg.g*hg.g) / (1 - hg.g + 2*hg.g*u1)
+		cosTheta = (1 + hg.g*hg.g - sqr*sqr) / (2 * hg.g)
+	}
+	cosTheta = math.Max(-1, math.Min(1, cosTheta))
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * u2
+
+	uvw := onb.New()
+	uvw.BuildFromW(wi)
+	wo := uvw.ScalarLocal(sinTheta*math.Cos(phi), sinTheta*math.Sin(phi), cosTheta)
+
+	return wo, hg.Evaluate(wi, wo)
+}
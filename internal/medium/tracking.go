@@ -0,0 +1,125 @@
+package medium
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/ray"
+)
+
+// majorant returns the extinction coefficient that bounds the extinction of every channel of the medium anywhere,
+// given the extinction coefficients at unit density.
+func (m *Medium) majorant(sigmaT Values, n int) float64 {
+	var sigma float64
+	for _, s := range sigmaT[:n] {
+		sigma = math.Max(sigma, s)
+	}
+
+	return sigma * m.density.Max()
+}
+
+// SampleCollision samples the distance that light travelling along r through the medium between the ray parameters
+// tMin and tMax covers before it is scattered, with spectral tracking as described by Kutz et al. in "Spectral and
+// Decomposition Tracking for Rendering Heterogeneous Volumes". All the channels of s share the same collisions,
+// which are chosen with the average probabilities of the channels.
+//
+// It returns the ray parameter of the collision and whether there was one, along with the weight of every channel
+// and the radiance emitted by the medium that reaches the start of the ray segment.
+// The weight of a collision includes the scattering albedo, so only the phase function remains to be applied.
+// Without a collision, the weight is the transmittance of the medium up to tMax divided by the probability
+// of reaching it, and tMax is returned. tMax must be finite.
+func (m *Medium) SampleCollision(r ray.Ray, tMin float64, tMax float64, s Spectrum, random *fastrandom.LCG) (float64, Values, Values, bool) {
+	n := s.Len()
+	weight := s.Ones()
+	var emitted Values
+	sigmaT, sigmaS := m.coefficients(s)
+	sigmaMaj := m.majorant(sigmaT, n)
+	length := r.Direction().Length()
+	if sigmaMaj <= 0 || length == 0 {
+		return tMax, weight, emitted, false
+	}
+	emissive := m.Emissive()
+
+	t := tMin
+	for {
+		t -= math.Log(1-random.Float64()) / (sigmaMaj * length)
+		if t >= tMax {
+			return tMax, weight, emitted, false
+		}
+
+		x := r.PointAtParameter(t)
+		d := m.density.Value(x)
+
+		// Every tentative collision adds the radiance emitted there in proportion to the absorption coefficient.
+		if emissive {
+			le := m.emitted(x, s)
+			for i := range n {
+				emitted[i] += weight[i] * d * (sigmaT[i] - sigmaS[i]) * le[i] / sigmaMaj
+			}
+		}
+
+		// The probabilities of scattering and of a null collision are proportional to the averages over all
+		// channels of the coefficients weighted by the path so far. Absorption is accounted for by the weights.
+		var scatter, null float64
+		for i := range n {
+			scatter += weight[i] * d * sigmaS[i]
+			null += weight[i] * (sigmaMaj - d*sigmaT[i])
+		}
+		if scatter+null <= 0 {
+			return t, Values{}, emitted, false
+		}
+		pScatter := scatter / (scatter + null)
+
+		if random.Float64() < pScatter {
+			for i := range n {
+				weight[i] *= d * sigmaS[i] / (sigmaMaj * pScatter)
+			}
+			return t, weight, emitted, true
+		}
+
+		for i := range n {
+			weight[i] *= (sigmaMaj - d*sigmaT[i]) / (sigmaMaj * (1 - pScatter))
+		}
+	}
+}
+
+// Transmittance returns the fraction of the light of every channel of s that travels along r through the medium
+// between the ray parameters tMin and tMax without being absorbed or scattered. It is computed in closed form for
+// homogeneous media and estimated with ratio tracking otherwise.
+func (m *Medium) Transmittance(r ray.Ray, tMin float64, tMax float64, s Spectrum, random *fastrandom.LCG) Values {
+	n := s.Len()
+	tr := s.Ones()
+	sigmaT, _ := m.coefficients(s)
+	length := r.Direction().Length()
+
+	if m.Homogeneous() {
+		d := m.density.Max() * (tMax - tMin) * length
+		for i := range n {
+			tr[i] = math.Exp(-sigmaT[i] * d)
+		}
+		return tr
+	}
+
+	sigmaMaj := m.majorant(sigmaT, n)
+	if sigmaMaj <= 0 || length == 0 {
+		return tr
+	}
+
+	t := tMin
+	for {
+		t -= math.Log(1-random.Float64()) / (sigmaMaj * length)
+		if t >= tMax {
+			return tr
+		}
+
+		d := m.density.Value(r.PointAtParameter(t))
+		alive := false
+		for i := range n {
+			tr[i] *= 1 - d*sigmaT[i]/sigmaMaj
+			alive = alive || tr[i] > 0
+		}
+		if !alive {
+			return tr
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package microfacet
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Distribution is an anisotropic GGX distribution whose roughness along the X and Y axes of the local frame is
+// AlphaX and AlphaY respectively.
+type Distribution struct {
+	AlphaX float64
+	AlphaY float64
+}
+
+// NewDistribution returns the distribution of a surface with the given perceptual roughness and anisotropy, both
+// in [0, 1]. Anisotropy stretches highlights along the X axis as described in "Physically Based Shading at
+// Disney" by Brent Burley.
+func NewDistribution(roughness float64, anisotropic float64) Distribution {
+	aspect := math.Sqrt(1 - 0.9*math.Max(0, math.Min(1, anisotropic)))
+	alpha := roughness * roughness
+
+	return Distribution{
+		AlphaX: math.Max(MinAlpha, alpha/aspect),
+		AlphaY: math.Max(MinAlpha, alpha*aspect),
+	}
+}
+
+// D returns the density of microfacets with normal h.
+func (d Distribution) D(h vec3.Vec3Impl) float64 {
+	if h.Z <= 0 {
+		return 0
+	}
+
+	x, y := h.X/d.AlphaX, h.Y/d.AlphaY
+	t := x*x + y*y + h.Z*h.Z
+	return 1 / (math.Pi * d.AlphaX * d.AlphaY * t * t)
+}
+
+// Lambda returns the Smith auxiliary function for the direction w, which may lie on either side of the surface.
+func (d Distribution) Lambda(w vec3.Vec3Impl) float64 {
+	if w.Z == 0 {
+		return math.Inf(1)
+	}
+
+	x, y := w.X*d.AlphaX, w.Y*d.AlphaY
+	return (math.Sqrt(1+(x*x+y*y)/(w.Z*w.Z)) - 1) / 2
+}
+
+// G1 returns the fraction of microfacets that are visible from the direction w.
+func (d Distribution) G1(w vec3.Vec3Impl) float64 {
+	return 1 / (1 + d.Lambda(w))
+}
+
+// G2 returns the fraction of microfacets that are visible from both wo and wi, with the height-correlated form of
+// the Smith masking-shadowing function.
+func (d Distribution) G2(wo vec3.Vec3Impl, wi vec3.Vec3Impl) float64 {
+	return 1 / (1 + d.Lambda(wo) + d.Lambda(wi))
+}
+
+// VisibleNormalPDF returns the density with which SampleVisibleNormal samples the microfacet normal h.
+func (d Distribution) VisibleNormalPDF(wo vec3.Vec3Impl, h vec3.Vec3Impl) float64 {
+	if wo.Z <= 0 {
+		return 0
+	}
+
+	return d.G1(wo) * math.Max(0, vec3.Dot(wo, h)) * d.D(h) / wo.Z
+}
+
+// SampleVisibleNormal samples a microfacet normal from the distribution of normals visible from wo, which must
+// lie above the surface, given two uniform random numbers. This is the method described in "Sampling the GGX
+// Distribution of Visible Normals" by Eric Heitz.
+func (d Distribution) SampleVisibleNormal(wo vec3.Vec3Impl, u1 float64, u2 float64) vec3.Vec3Impl {
+	// Stretch the view direction so that the distribution becomes that of a hemisphere.
+	vh := vec3.UnitVector(vec3.Vec3Impl{X: d.AlphaX * wo.X, Y: d.AlphaY * wo.Y, Z: wo.Z})
+
+	t1 := vec3.Vec3Impl{X: 1}
+	if lensq := vh.X*vh.X + vh.Y*vh.Y; lensq > 0 {
+		t1 = vec3.ScalarDiv(vec3.Vec3Impl{X: -vh.Y, Y: vh.X}, math.Sqrt(lensq))
+	}
+	t2 := vec3.Cross(vh, t1)
+
+	// Sample the projected area of the hemisphere as seen from vh.
+	r := math.Sqrt(u1)
+	phi := 2 * math.Pi * u2
+	p1 := r * math.Cos(phi)
+	p2 := r * math.Sin(phi)
+	s := 0.5 * (1 + vh.Z)
+	p2 = (1-s)*math.Sqrt(1-p1*p1) + s*p2
+
+	nh := vec3.Add(vec3.ScalarMul(t1, p1), vec3.ScalarMul(t2, p2), vec3.ScalarMul(vh, math.Sqrt(math.Max(0, 1-p1*p1-p2*p2))))
+
+	// Unstretch the normal.
+	return vec3.UnitVector(vec3.Vec3Impl{X: d.AlphaX * nh.X, Y: d.AlphaY * nh.Y, Z: math.Max(1e-6, nh.Z)})
+}
+
+// ReflectionPDF returns the density with respect to solid angle of the direction wi obtained by reflecting wo on
+// a microfacet normal sampled with SampleVisibleNormal.
+func (d Distribution) ReflectionPDF(wo vec3.Vec3Impl, wi vec3.Vec3Impl) float64 {
+	if wo.Z <= 0 || wi.Z <= 0 {
+		return 0
+	}
+
+	h := vec3.UnitVector(vec3.Add(wo, wi))
+	return d.VisibleNormalPDF(wo, h) / (4 * vec3.Dot(wo, h))
+}
+
+// RefractionHalfVector returns the microfacet normal that refracts wo, which lies above the surface, into wi below
+// it, where eta is the refractive index below the surface relative to the one above. It returns false if no
+// microfacet facing wo does.
+func RefractionHalfVector(wo vec3.Vec3Impl, wi vec3.Vec3Impl, eta float64) (vec3.Vec3Impl, bool) {
+	h := vec3.UnitVector(vec3.Add(wo, vec3.ScalarMul(wi, eta)))
+	if h.Z < 0 {
+		h = vec3.ScalarMul(h, -1)
+	}
+	if vec3.Dot(wo, h) <= 0 || vec3.Dot(wi, h) >= 0 {
+		return vec3.Vec3Impl{}, false
+	}
+
+	return h, true
+}
+
+// RefractionPDF returns the density with respect to solid angle of the direction wi obtained by refracting wo
+// through a microfacet normal sampled with SampleVisibleNormal, where eta is the refractive index below the surface
+// relative to the one above.
+func (d Distribution) RefractionPDF(wo vec3.Vec3Impl, wi vec3.Vec3Impl, eta float64) float64 {
+	if wo.Z <= 0 || wi.Z >= 0 {
+		return 0
+	}
+
+	h, ok := RefractionHalfVector(wo, wi, eta)
+	if !ok {
+		return 0
+	}
+
+	// The Jacobian of the refraction about h.
+	denom := vec3.Dot(wi, h) + vec3.Dot(wo, h)/eta
+	return d.VisibleNormalPDF(wo, h) * math.Abs(vec3.Dot(wi, h)) / (denom * denom)
+}
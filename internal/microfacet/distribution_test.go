@@ -0,0 +1,92 @@
+package microfacet
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestDistribution(t *testing.T) {
+	testData := []struct {
+		name                   string
+		roughness, anisotropic float64
+	}{
+		{name: "isotropic", roughness: 0.5},
+		{name: "anisotropic", roughness: 0.5, anisotropic: 0.8},
+		{name: "smooth anisotropic", roughness: 0.2, anisotropic: 1},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDistribution(test.roughness, test.anisotropic)
+			if test.anisotropic > 0 && d.AlphaX <= d.AlphaY {
+				t.Errorf("NewDistribution() = %+v, want it to be rougher along the X axis", d)
+			}
+
+			// The projected area of the microfacets is that of the surface.
+			if got := integrate(func(w vec3.Vec3Impl) float64 { return d.D(w) * w.Z }); math.Abs(got-1) > 0.01 {
+				t.Errorf("projected area = %v, want 1", got)
+			}
+
+			// The visible normals are distributed as sampled.
+			wo := vec3.UnitVector(vec3.Vec3Impl{X: 0.4, Y: 0.6, Z: 0.5})
+			if got := integrate(func(h vec3.Vec3Impl) float64 { return d.VisibleNormalPDF(wo, h) }); math.Abs(got-1) > 0.01 {
+				t.Errorf("integral of VisibleNormalPDF = %v, want 1", got)
+			}
+		})
+	}
+
+	// An isotropic distribution matches the functions of the cosine.
+	d := Distribution{AlphaX: 0.3, AlphaY: 0.3}
+	w := vec3.UnitVector(vec3.Vec3Impl{X: 0.2, Y: 0.5, Z: 0.7})
+	if got, want := d.D(w), D(w.Z, 0.3); math.Abs(got-want) > 1e-9*want {
+		t.Errorf("D() = %v, want %v", got, want)
+	}
+	if got, want := d.Lambda(w), Lambda(w.Z, 0.3); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Lambda() = %v, want %v", got, want)
+	}
+}
+
+func TestRefractionPDF(t *testing.T) {
+	testData := []struct {
+		name string
+		wo   vec3.Vec3Impl
+		d    Distribution
+		eta  float64
+	}{
+		{name: "entering glass", wo: vec3.UnitVector(vec3.Vec3Impl{X: 1, Z: 1}), d: Distribution{AlphaX: 0.3, AlphaY: 0.3}, eta: 1.5},
+		{name: "leaving glass", wo: vec3.UnitVector(vec3.Vec3Impl{X: 0.3, Z: 1}), d: Distribution{AlphaX: 0.2, AlphaY: 0.5}, eta: 1 / 1.5},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			random := fastrandom.NewWithDefaults()
+			const n = 200000
+
+			// The density of the refracted directions integrates to the fraction of them that lie below the surface.
+			var below int
+			for range n {
+				h := test.d.SampleVisibleNormal(test.wo, random.Float64(), random.Float64())
+				wi, ok := Refract(test.wo, h, test.eta)
+				if !ok {
+					continue
+				}
+				if got, ok := RefractionHalfVector(test.wo, wi, test.eta); ok && vec3.Sub(got, h).Length() > 1e-6 {
+					t.Fatalf("RefractionHalfVector() = %v, want %v", got, h)
+				}
+				if wi.Z < 0 {
+					below++
+				}
+			}
+
+			got := integrate(func(w vec3.Vec3Impl) float64 {
+				return test.d.RefractionPDF(test.wo, vec3.Vec3Impl{X: w.X, Y: w.Y, Z: -w.Z}, test.eta)
+			})
+			if want := float64(below) / n; math.Abs(got-want) > 0.01 {
+				t.Errorf("integral of RefractionPDF = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Package microfacet implements the GGX (Trowbridge-Reitz) microfacet distribution with Smith masking-shadowing,
+// used by rough materials. Directions are given in a local frame whose Z axis is the normal of the surface and
+// roughness is expressed as the α parameter of the distribution.
+package microfacet
+
+import (
+	"math"
+	"sync"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// MinAlpha is the smallest roughness handled, below which the distribution is too narrow to be sampled reliably.
+const MinAlpha = 1e-3
+
+// D returns the density of microfacets whose normals make an angle with the given cosine with the normal of the
+// surface.
+func D(cosTheta float64, alpha float64) float64 {
+	if cosTheta <= 0 {
+		return 0
+	}
+
+	a2 := alpha * alpha
+	d := cosTheta*cosTheta*(a2-1) + 1
+	return a2 / (math.Pi * d * d)
+}
+
+// Lambda returns the Smith auxiliary function for a direction whose angle with the normal has the given cosine.
+func Lambda(cosTheta float64, alpha float64) float64 {
+	if cosTheta >= 1 {
+		return 0
+	}
+
+	cos2 := cosTheta * cosTheta
+	tan2 := (1 - cos2) / cos2
+	return (math.Sqrt(1+alpha*alpha*tan2) - 1) / 2
+}
+
+// G1 returns the fraction of microfacets that are visible from a direction whose angle with the normal has the
+// given cosine.
+func G1(cosTheta float64, alpha float64) float64 {
+	return 1 / (1 + Lambda(cosTheta, alpha))
+}
+
+// G2 returns the fraction of microfacets that are visible from both directions, with the height-correlated form
+// of the Smith masking-shadowing function.
+func G2(cosO float64, cosI float64, alpha float64) float64 {
+	return 1 / (1 + Lambda(cosO, alpha) + Lambda(cosI, alpha))
+}
+
+// SampleVisibleNormal samples a microfacet normal from the distribution of normals visible from wo, which must
+// lie above the surface, given two uniform random numbers.
+func SampleVisibleNormal(wo vec3.Vec3Impl, alpha float64, u1 float64, u2 float64) vec3.Vec3Impl {
+	return Distribution{AlphaX: alpha, AlphaY: alpha}.SampleVisibleNormal(wo, u1, u2)
+}
+
+// ReflectionPDF returns the density with respect to solid angle of the direction wi obtained by reflecting wo on
+// a microfacet normal sampled with SampleVisibleNormal.
+func ReflectionPDF(wo vec3.Vec3Impl, wi vec3.Vec3Impl, alpha float64) float64 {
+	return Distribution{AlphaX: alpha, AlphaY: alpha}.ReflectionPDF(wo, wi)
+}
+
+// Reflect returns the reflection of wo on a microfacet with normal h.
+func Reflect(wo vec3.Vec3Impl, h vec3.Vec3Impl) vec3.Vec3Impl {
+	return vec3.Sub(vec3.ScalarMul(h, 2*vec3.Dot(wo, h)), wo)
+}
+
+// FresnelSchlick returns Schlick's approximation of the reflectance of a surface with reflectance f0 at normal
+// incidence for light arriving at an angle with the given cosine.
+func FresnelSchlick(cosTheta float64, f0 float64) float64 {
+	m := math.Max(0, math.Min(1, 1-cosTheta))
+	m2 := m * m
+	return f0 + (1-f0)*m2*m2*m
+}
+
+// FresnelConductor returns the reflectance of unpolarised light arriving at an angle with the given cosine at
+// the surface of a conductor with the complex refractive index eta + ik relative to the medium the light travels
+// through.
+func FresnelConductor(cosTheta float64, eta float64, k float64) float64 {
+	cos2 := math.Min(1, cosTheta*cosTheta)
+	sin2 := 1 - cos2
+	eta2, k2 := eta*eta, k*k
+
+	t0 := eta2 - k2 - sin2
+	a2b2 := math.Sqrt(t0*t0 + 4*eta2*k2)
+	a := math.Sqrt(math.Max(0, (a2b2+t0)/2))
+
+	t1 := a2b2 + cos2
+	t2 := 2 * a * cosTheta
+	rs := (t1 - t2) / (t1 + t2)
+
+	t3 := cos2*a2b2 + sin2*sin2
+	t4 := t2 * sin2
+	rp := rs * (t3 - t4) / (t3 + t4)
+
+	return (rs + rp) / 2
+}
+
+// FresnelDielectric returns the reflectance of unpolarised light arriving at an angle with the given cosine at the
+// surface of a dielectric with the refractive index eta relative to the medium the light travels through. All the
+// light is reflected beyond the critical angle.
+func FresnelDielectric(cosTheta float64, eta float64) float64 {
+	cosI := math.Min(1, cosTheta)
+	sin2T := (1 - cosI*cosI) / (eta * eta)
+	if sin2T >= 1 {
+		return 1
+	}
+
+	cosT := math.Sqrt(1 - sin2T)
+	rs := (cosI - eta*cosT) / (cosI + eta*cosT)
+	rp := (eta*cosI - cosT) / (eta*cosI + cosT)
+	return (rs*rs + rp*rp) / 2
+}
+
+// Refract returns the direction in which wo is refracted by a microfacet with normal h into a dielectric with the
+// refractive index eta relative to the medium wo lies in, or false if it is totally reflected.
+func Refract(wo vec3.Vec3Impl, h vec3.Vec3Impl, eta float64) (vec3.Vec3Impl, bool) {
+	cosI := vec3.Dot(wo, h)
+	sin2T := (1 - cosI*cosI) / (eta * eta)
+	if sin2T >= 1 {
+		return vec3.Vec3Impl{}, false
+	}
+
+	cosT := math.Sqrt(1 - sin2T)
+	return vec3.Add(vec3.ScalarDiv(vec3.ScalarMul(wo, -1), eta), vec3.ScalarMul(h, cosI/eta-cosT)), true
+}
+
+const (
+	// albedoTableSize is the number of cosines and roughnesses the directional albedo is tabulated at.
+	albedoTableSize = 32
+	// albedoSamples is the square root of the number of directions used to compute each entry of the table.
+	albedoSamples = 32
+)
+
+var (
+	albedoTableOnce sync.Once
+	albedoTable     [albedoTableSize][albedoTableSize]float64
+)
+
+// DirectionalAlbedo returns the fraction of the light arriving from a direction whose angle with the normal has
+// the given cosine that is reflected after a single bounce off a surface whose microfacets reflect all of it. The
+// rest is reflected after bouncing between microfacets, which the distribution does not account for.
+func DirectionalAlbedo(cosTheta float64, alpha float64) float64 {
+	albedoTableOnce.Do(func() {
+		for i := range albedoTableSize {
+			for j := range albedoTableSize {
+				albedoTable[i][j] = directionalAlbedo(tableValue(i), math.Max(MinAlpha, tableValue(j)))
+			}
+		}
+	})
+
+	x := math.Max(0, math.Min(1, cosTheta)) * (albedoTableSize - 1)
+	y := math.Max(0, math.Min(1, alpha)) * (albedoTableSize - 1)
+	i, j := min(int(x), albedoTableSize-2), min(int(y), albedoTableSize-2)
+	fx, fy := x-float64(i), y-float64(j)
+
+	return (albedoTable[i][j]*(1-fx)+albedoTable[i+1][j]*fx)*(1-fy) +
+		(albedoTable[i][j+1]*(1-fx)+albedoTable[i+1][j+1]*fx)*fy
+}
+
+// tableValue returns the cosine or roughness of the given entry of the directional albedo table.
+func tableValue(i int) float64 {
+	return math.Max(1e-3, float64(i)/(albedoTableSize-1))
+}
+
+// directionalAlbedo integrates the single scattering reflectance by sampling visible normals on a stratified grid.
+func directionalAlbedo(cosTheta float64, alpha float64) float64 {
+	wo := vec3.Vec3Impl{X: math.Sqrt(1 - cosTheta*cosTheta), Z: cosTheta}
+
+	var sum float64
+	for i := range albedoSamples {
+		for j := range albedoSamples {
+			u1 := (float64(i) + 0.5) / albedoSamples
+			u2 := (float64(j) + 0.5) / albedoSamples
+			wi := Reflect(wo, SampleVisibleNormal(wo, alpha, u1, u2))
+			if wi.Z <= 0 {
+				continue
+			}
+
+			// The BRDF times the cosine divided by the density of wi.
+			sum += G2(wo.Z, wi.Z, alpha) / G1(wo.Z, alpha)
+		}
+	}
+
+	return sum / (albedoSamples * albedoSamples)
+}
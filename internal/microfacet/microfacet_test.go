@@ -0,0 +1,134 @@
+package microfacet
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// integrate integrates f over the hemisphere above the surface with the midpoint rule.
+func integrate(f func(w vec3.Vec3Impl) float64) float64 {
+	const n = 512
+	var sum float64
+	for i := range n {
+		theta := (float64(i) + 0.5) / n * math.Pi / 2
+		for j := range n {
+			phi := (float64(j) + 0.5) / n * 2 * math.Pi
+			w := vec3.Vec3Impl{X: math.Sin(theta) * math.Cos(phi), Y: math.Sin(theta) * math.Sin(phi), Z: math.Cos(theta)}
+			sum += f(w) * math.Sin(theta)
+		}
+	}
+
+	return sum * (math.Pi / 2 / n) * (2 * math.Pi / n)
+}
+
+func TestD(t *testing.T) {
+	for _, alpha := range []float64{0.1, 0.3, 0.7, 1.0} {
+		// The projected area of the microfacets is that of the surface.
+		got := integrate(func(w vec3.Vec3Impl) float64 {
+			return D(w.Z, alpha) * w.Z
+		})
+		if math.Abs(got-1) > 0.01 {
+			t.Errorf("projected area for alpha %v = %v, want 1", alpha, got)
+		}
+	}
+}
+
+func TestSampleVisibleNormal(t *testing.T) {
+	testData := []struct {
+		name  string
+		wo    vec3.Vec3Impl
+		alpha float64
+	}{
+		{name: "normal incidence", wo: vec3.Vec3Impl{Z: 1}, alpha: 0.5},
+		{name: "oblique", wo: vec3.UnitVector(vec3.Vec3Impl{X: 1, Z: 1}), alpha: 0.3},
+		{name: "grazing", wo: vec3.UnitVector(vec3.Vec3Impl{X: 1, Y: 1, Z: 0.2}), alpha: 0.8},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			random := fastrandom.NewWithDefaults()
+			const n = 200000
+
+			// The density of the reflected directions integrates to the fraction of them that lie above the surface.
+			var above int
+			for range n {
+				h := SampleVisibleNormal(test.wo, test.alpha, random.Float64(), random.Float64())
+				if vec3.Dot(test.wo, h) < 0 {
+					t.Fatalf("sampled normal %v is not visible from %v", h, test.wo)
+				}
+				if Reflect(test.wo, h).Z > 0 {
+					above++
+				}
+			}
+
+			got := integrate(func(w vec3.Vec3Impl) float64 {
+				return ReflectionPDF(test.wo, w, test.alpha)
+			})
+			if want := float64(above) / n; math.Abs(got-want) > 0.01 {
+				t.Errorf("integral of ReflectionPDF = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFresnel(t *testing.T) {
+	testData := []struct {
+		name     string
+		cosTheta float64
+		eta, k   float64
+		want     float64
+	}{
+		// ((η-1)² + k²) / ((η+1)² + k²) at normal incidence.
+		{name: "glass at normal incidence", cosTheta: 1, eta: 1.5, want: 0.04},
+		{name: "gold at normal incidence", cosTheta: 1, eta: 0.43, k: 2.45, want: 0.7863},
+		// The average of the reflectances of s and p polarised light given by the Fresnel equations.
+		{name: "glass at 60 degrees", cosTheta: 0.5, eta: 1.5, want: 0.0892},
+		{name: "grazing", cosTheta: 0, eta: 0.43, k: 2.45, want: 1},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if got := FresnelConductor(test.cosTheta, test.eta, test.k); math.Abs(got-test.want) > 1e-3 {
+				t.Errorf("FresnelConductor() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	// Dielectrics reflect like conductors without absorption, and all the light beyond the critical angle.
+	for _, test := range []struct{ cosTheta, eta, want float64 }{{1, 1.5, 0.04}, {0.5, 1.5, 0.0892}, {0.5, 1 / 1.5, 1}} {
+		if got := FresnelDielectric(test.cosTheta, test.eta); math.Abs(got-test.want) > 1e-3 {
+			t.Errorf("FresnelDielectric(%v, %v) = %v, want %v", test.cosTheta, test.eta, got, test.want)
+		}
+	}
+
+	for _, test := range []struct{ cosTheta, want float64 }{{1, 0.04}, {0.5, 0.07}, {0, 1}} {
+		if got := FresnelSchlick(test.cosTheta, 0.04); math.Abs(got-test.want) > 1e-3 {
+			t.Errorf("FresnelSchlick(%v) = %v, want %v", test.cosTheta, got, test.want)
+		}
+	}
+}
+
+func TestDirectionalAlbedo(t *testing.T) {
+	if got := DirectionalAlbedo(0.8, MinAlpha); math.Abs(got-1) > 0.01 {
+		t.Errorf("directional albedo of a smooth surface = %v, want 1", got)
+	}
+
+	// Rougher surfaces lose more of the light, most of all at grazing angles.
+	prev := 1.0
+	for _, alpha := range []float64{0.25, 0.5, 0.75, 1.0} {
+		got := DirectionalAlbedo(0.2, alpha)
+		if got >= prev || got <= 0 {
+			t.Errorf("directional albedo for alpha %v = %v, want it within (0, %v)", alpha, got, prev)
+		}
+		prev = got
+	}
+
+	// Values between the entries of the table are interpolated.
+	want := directionalAlbedo(0.37, 0.41)
+	if got := DirectionalAlbedo(0.37, 0.41); math.Abs(got-want) > 0.01 {
+		t.Errorf("DirectionalAlbedo() = %v, want %v", got, want)
+	}
+}
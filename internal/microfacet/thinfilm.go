@@ -0,0 +1,43 @@
+package microfacet
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FresnelThinFilm returns the reflectance of unpolarised light of wavelength lambda arriving at an angle with the
+// given cosine at the surface of a substrate with the complex refractive index eta + ik that is coated with a film of
+// the given thickness and refractive index filmEta. The refractive indices are relative to the medium the light
+// travels through and the thickness is in the same units as the wavelength.
+//
+// The light reflected off the top of the film interferes with the light that bounces back and forth inside it,
+// which is added up following Airy's summation of the reflections at both interfaces.
+func FresnelThinFilm(cosTheta float64, lambda float64, thickness float64, filmEta float64, eta float64, k float64) float64 {
+	cosI := math.Min(1, cosTheta)
+	sin2 := complex(1-cosI*cosI, 0)
+
+	// The normal components of the wave vectors, n cos θ, in the film and the substrate are complex beyond the
+	// critical angle or when the substrate absorbs light.
+	n2, n3 := complex(filmEta, 0), complex(eta, k)
+	q1 := complex(cosI, 0)
+	q2 := cmplx.Sqrt(n2*n2 - sin2)
+	q3 := cmplx.Sqrt(n3*n3 - sin2)
+
+	// phase is the change in amplitude and phase of the light after a round trip through the film.
+	phase := cmplx.Exp(complex(0, 4*math.Pi*thickness/lambda) * q2)
+
+	// p polarised light follows the same equations as s polarised light with q / n² in place of q.
+	rs := airy(q1, q2, q3, phase)
+	rp := airy(q1, q2/(n2*n2), q3/(n3*n3), phase)
+
+	return (rs + rp) / 2
+}
+
+// airy returns the reflectance of a film given the terms of the Fresnel equations of the medium the light arrives
+// from, the film and the substrate, and the change in the light after a round trip through the film.
+func airy(z1 complex128, z2 complex128, z3 complex128, phase complex128) float64 {
+	r12 := (z1 - z2) / (z1 + z2)
+	r23 := (z2 - z3) / (z2 + z3)
+	r := (r12 + r23*phase) / (1 + r12*r23*phase)
+	return real(r)*real(r) + imag(r)*imag(r)
+}
@@ -0,0 +1,50 @@
+package microfacet
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFresnelThinFilm(t *testing.T) {
+	// A film with the geometric mean of the refractive indices around it that is a quarter of a wavelength thick
+	// cancels the reflection of that wavelength, which is how anti-reflective coatings work.
+	arEta := math.Sqrt(1.5)
+	quarterWave := 550 / (4 * arEta)
+
+	testData := []struct {
+		name      string
+		cosTheta  float64
+		lambda    float64
+		thickness float64
+		filmEta   float64
+		eta, k    float64
+		want      float64
+	}{
+		{name: "uncoated glass", cosTheta: 0.5, lambda: 550, filmEta: 1.33, eta: 1.5, want: FresnelDielectric(0.5, 1.5)},
+		{name: "uncoated gold", cosTheta: 0.7, lambda: 550, filmEta: 1.33, eta: 0.43, k: 2.45, want: FresnelConductor(0.7, 0.43, 2.45)},
+		{name: "film matching the medium", cosTheta: 0.7, lambda: 550, thickness: 300, filmEta: 1, eta: 0.43, k: 2.45, want: FresnelConductor(0.7, 0.43, 2.45)},
+		{name: "film matching the substrate", cosTheta: 0.5, lambda: 550, thickness: 300, filmEta: 1.5, eta: 1.5, want: FresnelDielectric(0.5, 1.5)},
+		{name: "anti-reflective coating", cosTheta: 1, lambda: 550, thickness: quarterWave, filmEta: arEta, eta: 1.5, want: 0},
+		{name: "half wave coating", cosTheta: 1, lambda: 550, thickness: 2 * quarterWave, filmEta: arEta, eta: 1.5, want: 0.04},
+		// ((n²-1) / (n²+1))² for a quarter wave film of index n on a substrate matching the medium.
+		{name: "quarter wave soap film", cosTheta: 1, lambda: 532, thickness: 100, filmEta: 1.33, eta: 1, want: 0.0771},
+		{name: "grazing", cosTheta: 0, lambda: 550, thickness: 300, filmEta: 1.33, eta: 1.5, want: 1},
+		{name: "total internal reflection", cosTheta: 0.5, lambda: 550, thickness: 300, filmEta: 1.1, eta: 1 / 1.5, want: 1},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := FresnelThinFilm(test.cosTheta, test.lambda, test.thickness, test.filmEta, test.eta, test.k)
+			if math.Abs(got-test.want) > 1e-3 {
+				t.Errorf("FresnelThinFilm() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	// The colour of the reflection changes with the thickness of the film.
+	blue := FresnelThinFilm(1, 450, 250, 1.33, 1, 0)
+	red := FresnelThinFilm(1, 650, 250, 1.33, 1, 0)
+	if math.Abs(blue-red) < 0.01 {
+		t.Errorf("FresnelThinFilm() = %v at 450nm and %v at 650nm, want them to differ", blue, red)
+	}
+}
@@ -50,6 +50,17 @@ func (o *Onb) BuildFromW(n vec3.Vec3Impl) {
 	o.axis[0] = vec3.Cross(o.W(), o.V())
 }
 
+// BuildFromWU constructs the ortho-normal base from the provided vector and a direction u, which becomes the
+// first axis once made perpendicular to it.
+func (o *Onb) BuildFromWU(n vec3.Vec3Impl, u vec3.Vec3Impl) {
+	// W
+	o.axis[2] = vec3.UnitVector(n)
+	// V
+	o.axis[1] = vec3.UnitVector(vec3.Cross(o.W(), u))
+	// U
+	o.axis[0] = vec3.Cross(o.V(), o.W())
+}
+
 // ScalarLocal returns the ortho-normal base local to the supplied position.
 func (o *Onb) ScalarLocal(a, b, c float64) vec3.Vec3Impl {
 	// a*u + b*v + c*w
@@ -66,3 +77,8 @@ func (o *Onb) Local(a vec3.Vec3Impl) vec3.Vec3Impl {
 		vec3.ScalarMul(o.V(), a.Y),
 		vec3.ScalarMul(o.W(), a.Z))
 }
+
+// ToLocal returns the coordinates of a in the ortho-normal base.
+func (o *Onb) ToLocal(a vec3.Vec3Impl) vec3.Vec3Impl {
+	return vec3.Vec3Impl{X: vec3.Dot(a, o.U()), Y: vec3.Dot(a, o.V()), Z: vec3.Dot(a, o.W())}
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/flynn-nrg/izpi/internal/aov"
+)
+
+// cryptomatteNames holds the names of the Cryptomatte layers, as expected by compositing applications.
+var cryptomatteNames = map[aov.Type]string{
+	aov.CryptoObject:   "CryptoObject",
+	aov.CryptoMaterial: "CryptoMaterial",
+}
+
+// CryptomatteLayers returns the Cryptomatte layers stored in b, with manifests listing the given object and
+// material names.
+func CryptomatteLayers(b *aov.Buffers, objectNames []string, materialNames []string) []Layer {
+	var layers []Layer
+	for _, l := range b.Layers() {
+		if l.IsCryptomatte() {
+			layers = append(layers, cryptomatteLayers(b, l, objectNames, materialNames)...)
+		}
+	}
+
+	return layers
+}
+
+// cryptomatteLayers returns the Cryptomatte layer l split into RGBA layers holding two identifier and coverage
+// pairs each. The metadata of the first layer describes the whole matte, including a manifest of the object or
+// material names.
+func cryptomatteLayers(b *aov.Buffers, l aov.Layer, objectNames []string, materialNames []string) []Layer {
+	name := cryptomatteNames[l.Type]
+	names := objectNames
+	if l.Type == aov.CryptoMaterial {
+		names = materialNames
+	}
+	prefix := fmt.Sprintf("cryptomatte/%s/", cryptomatteKey(name))
+
+	var layers []Layer
+	for rank := 0; rank < aov.CryptomatteRanks; rank += 2 {
+		layer := Layer{
+			Name:     fmt.Sprintf("%s%02d", name, rank/2),
+			Channels: []string{"R", "G", "B", "A"},
+			Data:     true,
+		}
+
+		for c := range 4 {
+			values := b.Channel(l, rank*2+c)
+			pixels := make([]float32, len(values))
+			for i, v := range values {
+				pixels[i] = float32(v)
+			}
+			layer.Pixels = append(layer.Pixels, pixels)
+		}
+
+		layers = append(layers, layer)
+	}
+
+	layers[0].Metadata = map[string]string{
+		prefix + "name":       name,
+		prefix + "hash":       "MurmurHash3_32",
+		prefix + "conversion": "uint32_to_float32",
+		prefix + "manifest":   CryptomatteManifest(names),
+	}
+
+	return layers
+}
+
+// cryptomatteKey returns the key that identifies the metadata of a Cryptomatte layer.
+func cryptomatteKey(name string) string {
+	return fmt.Sprintf("%08x", aov.ID(name))[:7]
+}
+
+// CryptomatteManifest returns a JSON object mapping every name to the hexadecimal representation of its identifier.
+func CryptomatteManifest(names []string) string {
+	manifest := make(map[string]string, len(names))
+	for _, name := range names {
+		if name != "" {
+			manifest[name] = fmt.Sprintf("%08x", aov.ID(name))
+		}
+	}
+
+	// Maps are encoded with sorted keys and cannot fail to encode.
+	data, _ := json.Marshal(manifest)
+
+	return string(data)
+}
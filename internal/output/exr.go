@@ -0,0 +1,225 @@
+package output
+
+import (
+	"image"
+	"maps"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/aov"
+	"github.com/flynn-nrg/izpi/internal/exr"
+)
+
+var _ Output = (*MultiLayerEXR)(nil)
+
+// ColourSpace identifies the colour space of the beauty image and colour layers.
+type ColourSpace int
+
+const (
+	// LinearRec709 is linear light with Rec. 709 primaries, used by the RGB samplers.
+	LinearRec709 ColourSpace = iota
+	// ACEScg is linear light with ACES AP1 primaries, used by the spectral sampler.
+	ACEScg
+)
+
+// colourSpaceAttribute is the header attribute holding the name of the colour space of a part.
+const colourSpaceAttribute = "oiio:ColorSpace"
+
+// rawColourSpace is the colour space name of layers that hold data rather than colour.
+const rawColourSpace = "Raw"
+
+func (c ColourSpace) String() string {
+	if c == ACEScg {
+		return "ACEScg"
+	}
+
+	return "lin_rec709"
+}
+
+func (c ColourSpace) chromaticities() *exr.Chromaticities {
+	if c == ACEScg {
+		return &exr.ACEScg
+	}
+
+	return &exr.Rec709
+}
+
+// Layer holds a set of channels written to their own part of a multi-layer file.
+type Layer struct {
+	// Name is the name of the layer, which prefixes the names of its channels.
+	Name string
+	// Channels holds the names of the channels without the layer prefix.
+	Channels []string
+	// Pixels holds the values of every channel, stored row by row.
+	Pixels [][]float32
+	// Data is true for utility layers such as normals or depth, which hold data rather than colour.
+	Data bool
+	// Metadata holds attributes written to the header of every part of the file.
+	Metadata map[string]string
+}
+
+// MultiLayerEXR writes the beauty image and any number of additional layers to a single multi-part OpenEXR file.
+// The beauty image is written to the RGBA channels of the first part and every layer to a part of its own.
+type MultiLayerEXR struct {
+	fileName    string
+	colourSpace ColourSpace
+	layers      []Layer
+}
+
+// NewMultiLayerEXR returns a new multi-layer OpenEXR output. The beauty image and colour layers are tagged
+// with the given colour space.
+func NewMultiLayerEXR(fileName string, colourSpace ColourSpace) (*MultiLayerEXR, error) {
+	return &MultiLayerEXR{
+		fileName:    fileName,
+		colourSpace: colourSpace,
+	}, nil
+}
+
+// AddLayer adds a layer to be written along with the beauty image.
+func (m *MultiLayerEXR) AddLayer(l Layer) {
+	m.layers = append(m.layers, l)
+}
+
+func (m *MultiLayerEXR) Write(i image.Image) error {
+	bounds := i.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	beauty := exr.Part{
+		Name:       "beauty",
+		Attributes: map[string]string{colourSpaceAttribute: m.colourSpace.String()},
+	}
+	for c, name := range []string{"R", "G", "B", "A"} {
+		beauty.Channels = append(beauty.Channels, exr.Channel{Name: name, Pixels: imageChannel(i, c)})
+	}
+
+	// Applications read metadata such as Cryptomatte manifests from the header of any part, so it is copied to all.
+	metadata := make(map[string]string)
+	for _, l := range m.layers {
+		maps.Copy(metadata, l.Metadata)
+	}
+	maps.Copy(beauty.Attributes, metadata)
+
+	parts := []exr.Part{beauty}
+	for _, l := range m.layers {
+		name := exr.SanitiseName(l.Name)
+		colourSpace := m.colourSpace.String()
+		if l.Data {
+			colourSpace = rawColourSpace
+		}
+
+		part := exr.Part{
+			Name:       name,
+			Attributes: map[string]string{colourSpaceAttribute: colourSpace},
+		}
+		maps.Copy(part.Attributes, metadata)
+		for c, channel := range l.Channels {
+			part.Channels = append(part.Channels, exr.Channel{Name: name + "." + channel, Pixels: l.Pixels[c]})
+		}
+
+		parts = append(parts, part)
+	}
+
+	// Chromaticities apply to the whole file, so utility layers are only told apart by their colour space name.
+	return exr.WriteFile(m.fileName, &exr.Image{
+		Width:          width,
+		Height:         height,
+		Chromaticities: m.colourSpace.chromaticities(),
+		Parts:          parts,
+	})
+}
+
+// imageChannel returns channel c of every pixel of i, with R, G, B and A being channels 0 to 3.
+func imageChannel(i image.Image, c int) []float32 {
+	bounds := i.Bounds()
+	pixels := make([]float32, 0, bounds.Dx()*bounds.Dy())
+
+	if f, ok := i.(*floatimage.Float64NRGBA); ok {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				pixels = append(pixels, float32(f.Pix[f.PixOffset(x, y)+c]))
+			}
+		}
+
+		return pixels
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var v [4]uint32
+			v[0], v[1], v[2], v[3] = i.At(x, y).RGBA()
+			pixels = append(pixels, float32(v[c])/0xffff)
+		}
+	}
+
+	return pixels
+}
+
+// aovChannels holds the layer and channel names used for every AOV, following common compositing conventions.
+var aovChannels = map[aov.Type]struct {
+	name     string
+	channels []string
+}{
+	aov.Albedo:           {"albedo", []string{"R", "G", "B"}},
+	aov.Normal:           {"N", []string{"X", "Y", "Z"}},
+	aov.Depth:            {"depth", []string{"Z"}},
+	aov.Position:         {"P", []string{"X", "Y", "Z"}},
+	aov.UV:               {"uv", []string{"U", "V"}},
+	aov.MaterialID:       {"material_id", []string{"id"}},
+	aov.ObjectID:         {"object_id", []string{"id"}},
+	aov.Emission:         {"emission", []string{"R", "G", "B"}},
+	aov.DiffuseDirect:    {"diffuse_direct", []string{"R", "G", "B"}},
+	aov.DiffuseIndirect:  {"diffuse_indirect", []string{"R", "G", "B"}},
+	aov.SpecularDirect:   {"specular_direct", []string{"R", "G", "B"}},
+	aov.SpecularIndirect: {"specular_indirect", []string{"R", "G", "B"}},
+}
+
+// AOVLayers returns the AOVs and light groups stored in b as layers. Colour AOVs and light groups are written
+// in the colour space of the beauty image and every other AOV as data. Cryptomatte layers are written following
+// the Cryptomatte specification, with manifests listing the given object and material names.
+func AOVLayers(b *aov.Buffers, objectNames []string, materialNames []string) []Layer {
+	layers := make([]Layer, 0, len(b.Layers()))
+
+	for _, l := range b.Layers() {
+		if l.IsCryptomatte() {
+			layers = append(layers, cryptomatteLayers(b, l, objectNames, materialNames)...)
+			continue
+		}
+
+		layer := Layer{
+			Name:     l.Name(),
+			Channels: []string{"R", "G", "B"},
+			Data:     !l.IsColour(),
+		}
+		if l.LightGroup == "" {
+			layer.Name = aovChannels[l.Type].name
+			layer.Channels = aovChannels[l.Type].channels
+		}
+
+		for c := range l.Channels() {
+			values := b.Channel(l, c)
+			pixels := make([]float32, len(values))
+			for i, v := range values {
+				pixels[i] = float32(v)
+			}
+			layer.Pixels = append(layer.Pixels, pixels)
+		}
+
+		layers = append(layers, layer)
+	}
+
+	return layers
+}
+
+// SampleCountLayer returns a layer holding the number of samples taken for every pixel, stored row by row.
+func SampleCountLayer(sampleCounts []int) Layer {
+	pixels := make([]float32, len(sampleCounts))
+	for i, n := range sampleCounts {
+		pixels[i] = float32(n)
+	}
+
+	return Layer{
+		Name:     "sample_count",
+		Channels: []string{"Y"},
+		Pixels:   [][]float32{pixels},
+		Data:     true,
+	}
+}
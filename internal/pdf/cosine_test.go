@@ -0,0 +1,42 @@
+package pdf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestCosine(t *testing.T) {
+	const n = 200000
+
+	w := vec3.UnitVector(vec3.Vec3Impl{X: 0.3, Y: -0.5, Z: 0.8})
+	c := NewCosine(w)
+	random := fastrandom.NewWithDefaults()
+
+	// Directions are generated with the density returned by Value, so weighting them by its inverse integrates
+	// over the hemisphere around w, whose solid angle is 2π. The mean cosine of directions chosen with a density
+	// proportional to it is 2/3.
+	var solidAngle, cosine float64
+	for range n {
+		dir := c.Generate(random)
+		if l := dir.Length(); math.Abs(l-1) > 1e-9 {
+			t.Fatalf("Generate() = %v, whose length is %v, want a unit vector", dir, l)
+		}
+
+		pdf := c.Value(dir)
+		if pdf <= 0 {
+			t.Fatalf("Value(%v) = %v, want a positive density", dir, pdf)
+		}
+		solidAngle += 1 / pdf
+		cosine += vec3.Dot(dir, w)
+	}
+
+	if got := solidAngle / n; math.Abs(got-2*math.Pi) > 0.02*2*math.Pi {
+		t.Errorf("solid angle = %v, want %v", got, 2*math.Pi)
+	}
+	if got := cosine / n; math.Abs(got-2.0/3) > 0.005 {
+		t.Errorf("mean cosine = %v, want %v", got, 2.0/3)
+	}
+}
@@ -0,0 +1,57 @@
+package pdf
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/microfacet"
+	"github.com/flynn-nrg/izpi/internal/onb"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ PDF = (*GGX)(nil)
+
+// GGX samples the directions in which light leaving a rough surface along wo is reflected by the microfacets that
+// are visible from wo.
+type GGX struct {
+	uvw *onb.Onb
+	wo  vec3.Vec3Impl
+	d   microfacet.Distribution
+}
+
+// NewGGX returns an instance of the GGX PDF for a surface with normal w, light leaving it in the direction wo and
+// the given roughness.
+func NewGGX(w vec3.Vec3Impl, wo vec3.Vec3Impl, alpha float64) *GGX {
+	o := onb.New()
+	o.BuildFromW(w)
+	return &GGX{
+		uvw: o,
+		wo:  o.ToLocal(vec3.UnitVector(wo)),
+		d:   microfacet.Distribution{AlphaX: alpha, AlphaY: alpha},
+	}
+}
+
+// NewAnisotropicGGX returns an instance of the GGX PDF for a surface with normal w and tangent u, along which the
+// roughness of d is measured by its X axis, and light leaving it in the direction wo.
+func NewAnisotropicGGX(w vec3.Vec3Impl, u vec3.Vec3Impl, wo vec3.Vec3Impl, d microfacet.Distribution) *GGX {
+	o := onb.New()
+	o.BuildFromWU(w, u)
+	return &GGX{
+		uvw: o,
+		wo:  o.ToLocal(vec3.UnitVector(wo)),
+		d:   d,
+	}
+}
+
+func (g *GGX) Value(direction vec3.Vec3Impl) float64 {
+	return g.d.ReflectionPDF(g.wo, g.uvw.ToLocal(vec3.UnitVector(direction)))
+}
+
+func (g *GGX) Generate(random *fastrandom.LCG) vec3.Vec3Impl {
+	if g.wo.Z <= 0 {
+		// No microfacets are visible from below the surface.
+		return g.uvw.W()
+	}
+
+	h := g.d.SampleVisibleNormal(g.wo, random.Float64(), random.Float64())
+	return g.uvw.Local(microfacet.Reflect(g.wo, h))
+}
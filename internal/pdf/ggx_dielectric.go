@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/microfacet"
+	"github.com/flynn-nrg/izpi/internal/onb"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ PDF = (*GGXDielectric)(nil)
+
+// GGXDielectric samples the directions in which light leaving the rough surface of a dielectric along wo is
+// reflected or refracted by the microfacets that are visible from wo, choosing between both with the probability
+// given by the Fresnel equations.
+type GGXDielectric struct {
+	uvw *onb.Onb
+	wo  vec3.Vec3Impl
+	d   microfacet.Distribution
+	eta float64
+}
+
+// NewGGXDielectric returns an instance of the GGX dielectric PDF for a surface with normal w and tangent u, light
+// leaving it in the direction wo, which lies on the side the normal points to, and the refractive index eta of the
+// other side relative to that one.
+func NewGGXDielectric(w vec3.Vec3Impl, u vec3.Vec3Impl, wo vec3.Vec3Impl, d microfacet.Distribution, eta float64) *GGXDielectric {
+	o := onb.New()
+	o.BuildFromWU(w, u)
+	return &GGXDielectric{
+		uvw: o,
+		wo:  o.ToLocal(vec3.UnitVector(wo)),
+		d:   d,
+		eta: eta,
+	}
+}
+
+func (g *GGXDielectric) Value(direction vec3.Vec3Impl) float64 {
+	wi := g.uvw.ToLocal(vec3.UnitVector(direction))
+	if wi.Z > 0 {
+		h := vec3.UnitVector(vec3.Add(g.wo, wi))
+		return g.d.ReflectionPDF(g.wo, wi) * microfacet.FresnelDielectric(vec3.Dot(g.wo, h), g.eta)
+	}
+
+	h, ok := microfacet.RefractionHalfVector(g.wo, wi, g.eta)
+	if !ok {
+		return 0
+	}
+	return g.d.RefractionPDF(g.wo, wi, g.eta) * (1 - microfacet.FresnelDielectric(vec3.Dot(g.wo, h), g.eta))
+}
+
+func (g *GGXDielectric) Generate(random *fastrandom.LCG) vec3.Vec3Impl {
+	if g.wo.Z <= 0 {
+		// No microfacets are visible from below the surface.
+		return g.uvw.W()
+	}
+
+	h := g.d.SampleVisibleNormal(g.wo, random.Float64(), random.Float64())
+	if random.Float64() >= microfacet.FresnelDielectric(vec3.Dot(g.wo, h), g.eta) {
+		if wi, ok := microfacet.Refract(g.wo, h, g.eta); ok {
+			return g.uvw.Local(wi)
+		}
+	}
+
+	return g.uvw.Local(microfacet.Reflect(g.wo, h))
+}
@@ -11,21 +11,30 @@ var _ PDF = (*Mixture)(nil)
 // Mixture represents a mixture of two PDFs.
 type Mixture struct {
 	p [2]PDF
+	// weight is the probability of sampling the first PDF.
+	weight float64
 }
 
-// NewMixture returns an instance of the mixture PDF.
+// NewMixture returns an instance of the mixture PDF that samples both PDFs with the same probability.
 func NewMixture(p0 PDF, p1 PDF) *Mixture {
+	return NewWeightedMixture(p0, p1, 0.5)
+}
+
+// NewWeightedMixture returns an instance of the mixture PDF that samples p0 with the given probability and p1
+// otherwise.
+func NewWeightedMixture(p0 PDF, p1 PDF, weight float64) *Mixture {
 	return &Mixture{
-		p: [2]PDF{p0, p1},
+		p:      [2]PDF{p0, p1},
+		weight: weight,
 	}
 }
 
 func (m *Mixture) Value(direction vec3.Vec3Impl) float64 {
-	return 0.5*m.p[0].Value(direction) + 0.5*m.p[1].Value(direction)
+	return m.weight*m.p[0].Value(direction) + (1-m.weight)*m.p[1].Value(direction)
 }
 
 func (m *Mixture) Generate(random *fastrandom.LCG) vec3.Vec3Impl {
-	if random.Float64() < 0.5 {
+	if random.Float64() < m.weight {
 		return m.p[0].Generate(random)
 	}
 
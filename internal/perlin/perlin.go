@@ -3,8 +3,8 @@ package perlin
 
 import (
 	"math"
-	"math/rand"
 
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
@@ -16,12 +16,17 @@ type Perlin struct {
 	permZ  []int
 }
 
+// perlinSeed is the seed of the generator of the gradients and permutations, which is fixed so that noise
+// textures look the same in every render.
+const perlinSeed = 1
+
 func New() *Perlin {
+	random := fastrandom.NewWithSeed(perlinSeed)
 	return &Perlin{
-		ranVec: perlinGenerate(),
-		permX:  perlinGeneratePerm(),
-		permY:  perlinGeneratePerm(),
-		permZ:  perlinGeneratePerm(),
+		ranVec: perlinGenerate(random),
+		permX:  perlinGeneratePerm(random),
+		permY:  perlinGeneratePerm(random),
+		permZ:  perlinGeneratePerm(random),
 	}
 }
 
@@ -63,18 +68,18 @@ func (pl *Perlin) Turb(p vec3.Vec3Impl, depth int) float64 {
 	return math.Abs(accum)
 }
 
-func perlinGenerate() []vec3.Vec3Impl {
+func perlinGenerate(random *fastrandom.LCG) []vec3.Vec3Impl {
 	p := make([]vec3.Vec3Impl, 256)
 	for i := range p {
-		p[i] = vec3.UnitVector(vec3.Vec3Impl{X: -1 + 2*rand.Float64(), Y: -1 + 2*rand.Float64(), Z: -1 + 2*rand.Float64()})
+		p[i] = vec3.UnitVector(vec3.Vec3Impl{X: -1 + 2*random.Float64(), Y: -1 + 2*random.Float64(), Z: -1 + 2*random.Float64()})
 	}
 
 	return p
 }
 
-func permute(p []int) []int {
+func permute(p []int, random *fastrandom.LCG) []int {
 	for i := (len(p) - 1); i > 0; i-- {
-		target := int(rand.Float64() * float64(i+1))
+		target := int(random.Float64() * float64(i+1))
 		tmp := p[i]
 		p[i] = p[target]
 		p[target] = tmp
@@ -83,13 +88,13 @@ func permute(p []int) []int {
 	return p
 }
 
-func perlinGeneratePerm() []int {
+func perlinGeneratePerm(random *fastrandom.LCG) []int {
 	p := make([]int, 256)
 	for i := range p {
 		p[i] = i
 	}
 
-	return permute(p)
+	return permute(p, random)
 }
 
 func trilinearInterp(c [2][2][2]vec3.Vec3Impl, u float64, v float64, w float64) float64 {
@@ -0,0 +1,232 @@
+package postprocess
+
+import (
+	"errors"
+	"image"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/aov"
+	"github.com/flynn-nrg/izpi/internal/scene"
+)
+
+// Ensure interface compliance.
+var _ Filter = (*Denoise)(nil)
+
+var ErrMissingFeatures = errors.New("the denoiser requires the albedo, normal and depth AOVs")
+
+const (
+	// Default number of filter iterations. Each iteration doubles the footprint of the filter.
+	DefaultDenoiseIterations = 5
+
+	// sigmaColour controls how quickly the weight of a neighbour falls off with its difference in illumination.
+	sigmaColour = 0.5
+	// sigmaAlbedo controls how quickly the weight of a neighbour falls off with its difference in albedo.
+	sigmaAlbedo = 0.1
+	// normalSquarings is the number of times the cosine of the angle between the normals of two pixels is squared,
+	// which raises it to the power of 64.
+	normalSquarings = 6
+	// sigmaDepth is the relative depth difference at which the weight of a neighbour falls to 1/e.
+	sigmaDepth = 0.05
+
+	// minAlbedo is the smallest albedo that illumination is demodulated by.
+	minAlbedo = 1e-3
+)
+
+// kernel holds the weights of the B3 spline used by the à-trous transform.
+var kernel = [5]float64{1.0 / 16.0, 1.0 / 4.0, 3.0 / 8.0, 1.0 / 4.0, 1.0 / 16.0}
+
+// Denoise is an edge-avoiding à-trous wavelet filter guided by the albedo, normal and depth of the first
+// intersection of every pixel. It must be applied to the linear image, before any tone mapping.
+type Denoise struct {
+	iterations int
+	width      int
+	height     int
+	albedo     []float64
+	normal     []float64
+	depth      []float64
+}
+
+// NewDenoise returns a new denoising filter that uses the albedo, normal and depth AOVs stored in features.
+func NewDenoise(features *aov.Buffers, iterations int) (*Denoise, error) {
+	if features == nil {
+		return nil, ErrMissingFeatures
+	}
+
+	albedo, okAlbedo := features.Layer(aov.Albedo)
+	normal, okNormal := features.Layer(aov.Normal)
+	depth, okDepth := features.Layer(aov.Depth)
+	if !okAlbedo || !okNormal || !okDepth {
+		return nil, ErrMissingFeatures
+	}
+
+	d := &Denoise{
+		iterations: max(iterations, 1),
+		depth:      features.Channel(depth, 0),
+	}
+
+	d.albedo = interleave(features.Channel(albedo, 0), features.Channel(albedo, 1), features.Channel(albedo, 2))
+	d.normal = interleave(features.Channel(normal, 0), features.Channel(normal, 1), features.Channel(normal, 2))
+
+	return d, nil
+}
+
+// interleave returns the values of three channels as consecutive triplets.
+func interleave(a, b, c []float64) []float64 {
+	values := make([]float64, 0, len(a)*3)
+	for i := range a {
+		values = append(values, a[i], b[i], c[i])
+	}
+
+	return values
+}
+
+func (d *Denoise) Apply(i image.Image, _ *scene.Scene) error {
+	im, ok := i.(*floatimage.Float64NRGBA)
+	if !ok {
+		return errors.New("only Float64NRGBA image format is supported")
+	}
+
+	d.width = im.Bounds().Dx()
+	d.height = im.Bounds().Dy()
+	if len(d.depth) != d.width*d.height {
+		return errors.New("the denoiser feature buffers do not match the image size")
+	}
+
+	// Filter the illumination rather than the colour so that texture detail is preserved.
+	illum := make([]float64, d.width*d.height*3)
+	for p := range d.width * d.height {
+		pix := im.Pix[im.PixOffset(im.Bounds().Min.X+p%d.width, im.Bounds().Min.Y+p/d.width):]
+		for c := range 3 {
+			illum[p*3+c] = pix[c] / demodulation(d.albedo[p*3+c])
+		}
+	}
+
+	tmp := make([]float64, len(illum))
+	for it := range d.iterations {
+		d.iterate(illum, tmp, 1<<it, sigmaColour/float64(int(1)<<it))
+		illum, tmp = tmp, illum
+	}
+
+	for p := range d.width * d.height {
+		pix := im.Pix[im.PixOffset(im.Bounds().Min.X+p%d.width, im.Bounds().Min.Y+p/d.width):]
+		for c := range 3 {
+			pix[c] = illum[p*3+c] * demodulation(d.albedo[p*3+c])
+		}
+	}
+
+	return nil
+}
+
+// iterate applies a single à-trous iteration with the given step between taps to src and stores the result in dst.
+// Rows are filtered in parallel.
+func (d *Denoise) iterate(src []float64, dst []float64, step int, sigmaC float64) {
+	numWorkers := runtime.GOMAXPROCS(0)
+	rowsPerWorker := (d.height + numWorkers - 1) / numWorkers
+
+	wg := sync.WaitGroup{}
+	for y0 := 0; y0 < d.height; y0 += rowsPerWorker {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.iterateRows(src, dst, step, sigmaC, y0, min(y0+rowsPerWorker, d.height))
+		}()
+	}
+
+	wg.Wait()
+}
+
+// iterateRows applies an à-trous iteration to the rows in [y0, y1).
+func (d *Denoise) iterateRows(src []float64, dst []float64, step int, sigmaC float64, y0 int, y1 int) {
+	for y := y0; y < y1; y++ {
+		for x := range d.width {
+			p := y*d.width + x
+			var sum [3]float64
+			var weights float64
+
+			for ky := -2; ky <= 2; ky++ {
+				qy := y + ky*step
+				if qy < 0 || qy >= d.height {
+					continue
+				}
+
+				for kx := -2; kx <= 2; kx++ {
+					qx := x + kx*step
+					if qx < 0 || qx >= d.width {
+						continue
+					}
+
+					q := qy*d.width + qx
+					w := kernel[kx+2] * kernel[ky+2] * d.weight(src, p, q, sigmaC)
+					for c := range 3 {
+						sum[c] += w * src[q*3+c]
+					}
+					weights += w
+				}
+			}
+
+			// The centre tap always has a positive weight.
+			for c := range 3 {
+				dst[p*3+c] = sum[c] / weights
+			}
+		}
+	}
+}
+
+// weight returns how much pixel q contributes to the filtered value of pixel p.
+func (d *Denoise) weight(illum []float64, p int, q int, sigmaC float64) float64 {
+	if p == q {
+		return 1.0
+	}
+
+	// Compare illumination after compressing its dynamic range so that bright pixels do not dominate.
+	var colourDist, albedoDist, cos, np, nq float64
+	for c := range 3 {
+		dc := compress(illum[p*3+c]) - compress(illum[q*3+c])
+		colourDist += dc * dc
+
+		da := d.albedo[p*3+c] - d.albedo[q*3+c]
+		albedoDist += da * da
+
+		cos += d.normal[p*3+c] * d.normal[q*3+c]
+		np += d.normal[p*3+c] * d.normal[p*3+c]
+		nq += d.normal[q*3+c] * d.normal[q*3+c]
+	}
+
+	// Rays that miss the scene have no normal and are only blended with each other.
+	var wNormal float64
+	switch {
+	case np == 0 && nq == 0:
+		wNormal = 1.0
+	case np == 0 || nq == 0:
+		return 0
+	default:
+		wNormal = max(cos/math.Sqrt(np*nq), 0)
+		for range normalSquarings {
+			wNormal *= wNormal
+		}
+	}
+
+	zp, zq := d.depth[p], d.depth[q]
+	depthDist := math.Abs(zp-zq) / (sigmaDepth*max(zp, zq) + 1e-9)
+
+	// The depth, colour and albedo weights are Gaussian-like falloffs that are combined into a single exponential.
+	return wNormal * math.Exp(-depthDist-colourDist/(sigmaC*sigmaC)-albedoDist/(sigmaAlbedo*sigmaAlbedo))
+}
+
+// demodulation returns the value that illumination is divided by to remove the albedo a.
+func demodulation(a float64) float64 {
+	if a < minAlbedo || math.IsNaN(a) {
+		return 1.0
+	}
+
+	return a
+}
+
+// compress maps linear values to [0, 1).
+func compress(v float64) float64 {
+	v = max(v, 0)
+	return v / (1 + v)
+}
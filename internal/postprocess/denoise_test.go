@@ -0,0 +1,115 @@
+package postprocess
+
+import (
+	"errors"
+	"image"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/aov"
+)
+
+func TestNewDenoise(t *testing.T) {
+	testData := []struct {
+		name     string
+		features *aov.Buffers
+		want     error
+	}{
+		{
+			name: "no features",
+			want: ErrMissingFeatures,
+		},
+		{
+			name:     "missing depth",
+			features: aov.NewBuffers(aov.Layout{Types: []aov.Type{aov.Albedo, aov.Normal}}, 4, 4),
+			want:     ErrMissingFeatures,
+		},
+		{
+			name:     "all features",
+			features: aov.NewBuffers(aov.Layout{Types: []aov.Type{aov.Depth, aov.Normal, aov.Emission, aov.Albedo}}, 4, 4),
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewDenoise(test.features, DefaultDenoiseIterations); !errors.Is(err, test.want) {
+				t.Errorf("NewDenoise() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestDenoiseApply(t *testing.T) {
+	const size = 32
+	// The left and right halves of the image face different directions and have different brightness.
+	const left, right = 0.2, 0.8
+
+	features := aov.NewBuffers(aov.Layout{Types: []aov.Type{aov.Albedo, aov.Normal, aov.Depth}}, size, size)
+	img := floatimage.NewFloat64NRGBA(image.Rect(0, 0, size, size), make([]float64, size*size*4))
+	random := rand.New(rand.NewPCG(1, 2))
+
+	for y := range size {
+		for x := range size {
+			normal, value := []float64{0, 0, 1}, left
+			if x >= size/2 {
+				normal, value = []float64{1, 0, 0}, right
+			}
+
+			features.Accumulate(x, y, 0, 1, []float64{0.5, 0.5, 0.5, normal[0], normal[1], normal[2], 10})
+
+			v := value * (0.5 + random.Float64())
+			copy(img.Pix[img.PixOffset(x, y):], []float64{v, v, v, 1})
+		}
+	}
+
+	// stats returns the mean and standard deviation of the red channel of the given columns.
+	stats := func(x0, x1 int) (float64, float64) {
+		var sum, sum2 float64
+		n := float64((x1 - x0) * size)
+		for y := range size {
+			for x := x0; x < x1; x++ {
+				v := img.Pix[img.PixOffset(x, y)]
+				sum += v
+				sum2 += v * v
+			}
+		}
+
+		mean := sum / n
+		return mean, math.Sqrt(max(sum2/n-mean*mean, 0))
+	}
+
+	_, noisyStdDev := stats(0, size/2)
+
+	d, err := NewDenoise(features, DefaultDenoiseIterations)
+	if err != nil {
+		t.Fatalf("NewDenoise() = %v", err)
+	}
+
+	if err := d.Apply(img, nil); err != nil {
+		t.Fatalf("Apply() = %v", err)
+	}
+
+	for _, half := range []struct {
+		x0, x1 int
+		want   float64
+	}{
+		{0, size / 2, left},
+		{size / 2, size, right},
+	} {
+		mean, stdDev := stats(half.x0, half.x1)
+		if math.Abs(mean-half.want) > 0.05*half.want {
+			t.Errorf("mean of columns [%v, %v) = %v, want %v", half.x0, half.x1, mean, half.want)
+		}
+
+		if stdDev > noisyStdDev/3 {
+			t.Errorf("standard deviation of columns [%v, %v) = %v, want less than %v", half.x0, half.x1, stdDev, noisyStdDev/3)
+		}
+	}
+
+	// Light must not bleed across the edge between both halves.
+	if mean, _ := stats(size/2-1, size/2); math.Abs(mean-left) > 0.05*left {
+		t.Errorf("mean of the column next to the edge = %v, want %v", mean, left)
+	}
+}
@@ -0,0 +1,304 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: checkpoint.proto
+
+package checkpoint
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Snapshot of a render in progress that can be used to resume it.
+type Checkpoint struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Version         uint32                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Width           uint32                 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height          uint32                 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	SamplesPerPixel uint32                 `protobuf:"varint,4,opt,name=samples_per_pixel,json=samplesPerPixel,proto3" json:"samples_per_pixel,omitempty"`
+	PassSamples     uint32                 `protobuf:"varint,5,opt,name=pass_samples,json=passSamples,proto3" json:"pass_samples,omitempty"`
+	SamplerType     uint32                 `protobuf:"varint,6,opt,name=sampler_type,json=samplerType,proto3" json:"sampler_type,omitempty"`
+	Adaptive        bool                   `protobuf:"varint,7,opt,name=adaptive,proto3" json:"adaptive,omitempty"`
+	// Number of passes that have been completed.
+	Pass uint32 `protobuf:"varint,8,opt,name=pass,proto3" json:"pass,omitempty"`
+	// Completion state of every tile in the current pass, in rendering order.
+	CompletedTiles []bool `protobuf:"varint,9,rep,packed,name=completed_tiles,json=completedTiles,proto3" json:"completed_tiles,omitempty"`
+	// Accumulated canvas, stored row by row as RGBA quadruplets.
+	Pixels []float64 `protobuf:"fixed64,10,rep,packed,name=pixels,proto3" json:"pixels,omitempty"`
+	// Number of samples accumulated for every pixel, stored row by row.
+	SampleCounts []uint32 `protobuf:"varint,11,rep,packed,name=sample_counts,json=sampleCounts,proto3" json:"sample_counts,omitempty"`
+	// Names of the AOVs being rendered.
+	Aovs []string `protobuf:"bytes,12,rep,name=aovs,proto3" json:"aovs,omitempty"`
+	// Accumulated AOV values, stored row by row with the channels of every AOV and light group in order.
+	AovPixels []float64 `protobuf:"fixed64,13,rep,packed,name=aov_pixels,json=aovPixels,proto3" json:"aov_pixels,omitempty"`
+	// Names of the light groups being rendered.
+	LightGroups []string `protobuf:"bytes,14,rep,name=light_groups,json=lightGroups,proto3" json:"light_groups,omitempty"`
+	// Light carried to the camera by light paths, stored row by row as RGB triplets.
+	LightImage []float64 `protobuf:"fixed64,15,rep,packed,name=light_image,json=lightImage,proto3" json:"light_image,omitempty"`
+	// Number of light paths accumulated into the light image.
+	LightPaths uint64 `protobuf:"varint,16,opt,name=light_paths,json=lightPaths,proto3" json:"light_paths,omitempty"`
+	// Reconstruction filter that samples are splatted with, or 0 if the samples of every pixel are averaged on
+	// their own.
+	Filter       uint32  `protobuf:"varint,17,opt,name=filter,proto3" json:"filter,omitempty"`
+	FilterRadius float64 `protobuf:"fixed64,18,opt,name=filter_radius,json=filterRadius,proto3" json:"filter_radius,omitempty"`
+	// Samples weighted by the reconstruction filter, stored row by row as the weighted sums of the three channels
+	// followed by the sum of the weights.
+	Film          []float64 `protobuf:"fixed64,19,rep,packed,name=film,proto3" json:"film,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Checkpoint) Reset() {
+	*x = Checkpoint{}
+	mi := &file_checkpoint_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Checkpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Checkpoint) ProtoMessage() {}
+
+func (x *Checkpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_checkpoint_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Checkpoint.ProtoReflect.Descriptor instead.
+func (*Checkpoint) Descriptor() ([]byte, []int) {
+	return file_checkpoint_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Checkpoint) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetWidth() uint32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetSamplesPerPixel() uint32 {
+	if x != nil {
+		return x.SamplesPerPixel
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetPassSamples() uint32 {
+	if x != nil {
+		return x.PassSamples
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetSamplerType() uint32 {
+	if x != nil {
+		return x.SamplerType
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetAdaptive() bool {
+	if x != nil {
+		return x.Adaptive
+	}
+	return false
+}
+
+func (x *Checkpoint) GetPass() uint32 {
+	if x != nil {
+		return x.Pass
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetCompletedTiles() []bool {
+	if x != nil {
+		return x.CompletedTiles
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetPixels() []float64 {
+	if x != nil {
+		return x.Pixels
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetSampleCounts() []uint32 {
+	if x != nil {
+		return x.SampleCounts
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetAovs() []string {
+	if x != nil {
+		return x.Aovs
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetAovPixels() []float64 {
+	if x != nil {
+		return x.AovPixels
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetLightGroups() []string {
+	if x != nil {
+		return x.LightGroups
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetLightImage() []float64 {
+	if x != nil {
+		return x.LightImage
+	}
+	return nil
+}
+
+func (x *Checkpoint) GetLightPaths() uint64 {
+	if x != nil {
+		return x.LightPaths
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetFilter() uint32 {
+	if x != nil {
+		return x.Filter
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetFilterRadius() float64 {
+	if x != nil {
+		return x.FilterRadius
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetFilm() []float64 {
+	if x != nil {
+		return x.Film
+	}
+	return nil
+}
+
+var File_checkpoint_proto protoreflect.FileDescriptor
+
+const file_checkpoint_proto_rawDesc = "" +
+	"\n" +
+	"\x10checkpoint.proto\x12\n" +
+	"checkpoint\"\xc5\x04\n" +
+	"\n" +
+	"Checkpoint\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\rR\aversion\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\rR\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\rR\x06height\x12*\n" +
+	"\x11samples_per_pixel\x18\x04 \x01(\rR\x0fsamplesPerPixel\x12!\n" +
+	"\fpass_samples\x18\x05 \x01(\rR\vpassSamples\x12!\n" +
+	"\fsampler_type\x18\x06 \x01(\rR\vsamplerType\x12\x1a\n" +
+	"\badaptive\x18\a \x01(\bR\badaptive\x12\x12\n" +
+	"\x04pass\x18\b \x01(\rR\x04pass\x12'\n" +
+	"\x0fcompleted_tiles\x18\t \x03(\bR\x0ecompletedTiles\x12\x16\n" +
+	"\x06pixels\x18\n" +
+	" \x03(\x01R\x06pixels\x12#\n" +
+	"\rsample_counts\x18\v \x03(\rR\fsampleCounts\x12\x12\n" +
+	"\x04aovs\x18\f \x03(\tR\x04aovs\x12\x1d\n" +
+	"\n" +
+	"aov_pixels\x18\r \x03(\x01R\taovPixels\x12!\n" +
+	"\flight_groups\x18\x0e \x03(\tR\vlightGroups\x12\x1f\n" +
+	"\vlight_image\x18\x0f \x03(\x01R\n" +
+	"lightImage\x12\x1f\n" +
+	"\vlight_paths\x18\x10 \x01(\x04R\n" +
+	"lightPaths\x12\x16\n" +
+	"\x06filter\x18\x11 \x01(\rR\x06filter\x12#\n" +
+	"\rfilter_radius\x18\x12 \x01(\x01R\ffilterRadius\x12\x12\n" +
+	"\x04film\x18\x13 \x03(\x01R\x04filmB@Z>github.com/flynn-nrg/izpi/internal/proto/checkpoint;checkpointb\x06proto3"
+
+var (
+	file_checkpoint_proto_rawDescOnce sync.Once
+	file_checkpoint_proto_rawDescData []byte
+)
+
+func file_checkpoint_proto_rawDescGZIP() []byte {
+	file_checkpoint_proto_rawDescOnce.Do(func() {
+		file_checkpoint_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_checkpoint_proto_rawDesc), len(file_checkpoint_proto_rawDesc)))
+	})
+	return file_checkpoint_proto_rawDescData
+}
+
+var file_checkpoint_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_checkpoint_proto_goTypes = []any{
+	(*Checkpoint)(nil), // 0: checkpoint.Checkpoint
+}
+var file_checkpoint_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_checkpoint_proto_init() }
+func file_checkpoint_proto_init() {
+	if File_checkpoint_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_checkpoint_proto_rawDesc), len(file_checkpoint_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_checkpoint_proto_goTypes,
+		DependencyIndexes: file_checkpoint_proto_depIdxs,
+		MessageInfos:      file_checkpoint_proto_msgTypes,
+	}.Build()
+	File_checkpoint_proto = out.File
+	file_checkpoint_proto_goTypes = nil
+	file_checkpoint_proto_depIdxs = nil
+}
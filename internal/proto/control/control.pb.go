@@ -31,17 +31,29 @@ const (
 	SamplerType_WIRE_FRAME               SamplerType = 3 // Using snake_case for enum value
 	SamplerType_COLOUR                   SamplerType = 4
 	SamplerType_SPECTRAL                 SamplerType = 5
+	SamplerType_BDPT                     SamplerType = 6
+	SamplerType_SPECTRAL_BDPT            SamplerType = 7
+	SamplerType_MLT                      SamplerType = 8
+	SamplerType_SPECTRAL_MLT             SamplerType = 9
+	SamplerType_PPM                      SamplerType = 10
+	SamplerType_SPECTRAL_PPM             SamplerType = 11
 )
 
 // Enum value maps for SamplerType.
 var (
 	SamplerType_name = map[int32]string{
-		0: "SAMPLER_TYPE_UNSPECIFIED",
-		1: "ALBEDO",
-		2: "NORMAL",
-		3: "WIRE_FRAME",
-		4: "COLOUR",
-		5: "SPECTRAL",
+		0:  "SAMPLER_TYPE_UNSPECIFIED",
+		1:  "ALBEDO",
+		2:  "NORMAL",
+		3:  "WIRE_FRAME",
+		4:  "COLOUR",
+		5:  "SPECTRAL",
+		6:  "BDPT",
+		7:  "SPECTRAL_BDPT",
+		8:  "MLT",
+		9:  "SPECTRAL_MLT",
+		10: "PPM",
+		11: "SPECTRAL_PPM",
 	}
 	SamplerType_value = map[string]int32{
 		"SAMPLER_TYPE_UNSPECIFIED": 0,
@@ -50,6 +62,12 @@ var (
 		"WIRE_FRAME":               3,
 		"COLOUR":                   4,
 		"SPECTRAL":                 5,
+		"BDPT":                     6,
+		"SPECTRAL_BDPT":            7,
+		"MLT":                      8,
+		"SPECTRAL_MLT":             9,
+		"PPM":                      10,
+		"SPECTRAL_PPM":             11,
 	}
 )
 
@@ -81,6 +99,122 @@ func (SamplerType) EnumDescriptor() ([]byte, []int) {
 }
 
 // Defines the status of the RenderConfiguration process on the worker.
+type SampleGenerator int32
+
+const (
+	SampleGenerator_SAMPLE_GENERATOR_UNSPECIFIED SampleGenerator = 0
+	SampleGenerator_INDEPENDENT                  SampleGenerator = 1
+	SampleGenerator_STRATIFIED                   SampleGenerator = 2
+	SampleGenerator_SOBOL                        SampleGenerator = 3
+	SampleGenerator_HALTON                       SampleGenerator = 4
+	SampleGenerator_BLUE_NOISE                   SampleGenerator = 5
+)
+
+// Enum value maps for SampleGenerator.
+var (
+	SampleGenerator_name = map[int32]string{
+		0: "SAMPLE_GENERATOR_UNSPECIFIED",
+		1: "INDEPENDENT",
+		2: "STRATIFIED",
+		3: "SOBOL",
+		4: "HALTON",
+		5: "BLUE_NOISE",
+	}
+	SampleGenerator_value = map[string]int32{
+		"SAMPLE_GENERATOR_UNSPECIFIED": 0,
+		"INDEPENDENT":                  1,
+		"STRATIFIED":                   2,
+		"SOBOL":                        3,
+		"HALTON":                       4,
+		"BLUE_NOISE":                   5,
+	}
+)
+
+func (x SampleGenerator) Enum() *SampleGenerator {
+	p := new(SampleGenerator)
+	*p = x
+	return p
+}
+
+func (x SampleGenerator) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SampleGenerator) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[1].Descriptor()
+}
+
+func (SampleGenerator) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[1]
+}
+
+func (x SampleGenerator) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SampleGenerator.Descriptor instead.
+func (SampleGenerator) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{1}
+}
+
+type ReconstructionFilter int32
+
+const (
+	ReconstructionFilter_RECONSTRUCTION_FILTER_UNSPECIFIED ReconstructionFilter = 0
+	ReconstructionFilter_BOX                               ReconstructionFilter = 1
+	ReconstructionFilter_TENT                              ReconstructionFilter = 2
+	ReconstructionFilter_GAUSSIAN                          ReconstructionFilter = 3
+	ReconstructionFilter_MITCHELL                          ReconstructionFilter = 4
+	ReconstructionFilter_BLACKMAN_HARRIS                   ReconstructionFilter = 5
+)
+
+// Enum value maps for ReconstructionFilter.
+var (
+	ReconstructionFilter_name = map[int32]string{
+		0: "RECONSTRUCTION_FILTER_UNSPECIFIED",
+		1: "BOX",
+		2: "TENT",
+		3: "GAUSSIAN",
+		4: "MITCHELL",
+		5: "BLACKMAN_HARRIS",
+	}
+	ReconstructionFilter_value = map[string]int32{
+		"RECONSTRUCTION_FILTER_UNSPECIFIED": 0,
+		"BOX":                               1,
+		"TENT":                              2,
+		"GAUSSIAN":                          3,
+		"MITCHELL":                          4,
+		"BLACKMAN_HARRIS":                   5,
+	}
+)
+
+func (x ReconstructionFilter) Enum() *ReconstructionFilter {
+	p := new(ReconstructionFilter)
+	*p = x
+	return p
+}
+
+func (x ReconstructionFilter) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReconstructionFilter) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[2].Descriptor()
+}
+
+func (ReconstructionFilter) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[2]
+}
+
+func (x ReconstructionFilter) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReconstructionFilter.Descriptor instead.
+func (ReconstructionFilter) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{2}
+}
+
 type RenderSetupStatus int32
 
 const (
@@ -126,11 +260,11 @@ func (x RenderSetupStatus) String() string {
 }
 
 func (RenderSetupStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_control_proto_enumTypes[1].Descriptor()
+	return file_control_proto_enumTypes[3].Descriptor()
 }
 
 func (RenderSetupStatus) Type() protoreflect.EnumType {
-	return &file_control_proto_enumTypes[1]
+	return &file_control_proto_enumTypes[3]
 }
 
 func (x RenderSetupStatus) Number() protoreflect.EnumNumber {
@@ -139,7 +273,7 @@ func (x RenderSetupStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RenderSetupStatus.Descriptor instead.
 func (RenderSetupStatus) EnumDescriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{1}
+	return file_control_proto_rawDescGZIP(), []int{3}
 }
 
 // Represents a 3D vector or point with float components, also used for colors.
@@ -392,27 +526,104 @@ func (x *ImageResolution) GetHeight() uint32 {
 	return 0
 }
 
+// Adaptive sampling parameters. Pixels stop receiving samples once converged.
+type AdaptiveSampling struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinSamples    uint32                 `protobuf:"varint,1,opt,name=min_samples,json=minSamples,proto3" json:"min_samples,omitempty"`
+	MaxSamples    uint32                 `protobuf:"varint,2,opt,name=max_samples,json=maxSamples,proto3" json:"max_samples,omitempty"`
+	Threshold     float64                `protobuf:"fixed64,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdaptiveSampling) Reset() {
+	*x = AdaptiveSampling{}
+	mi := &file_control_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdaptiveSampling) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdaptiveSampling) ProtoMessage() {}
+
+func (x *AdaptiveSampling) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdaptiveSampling.ProtoReflect.Descriptor instead.
+func (*AdaptiveSampling) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AdaptiveSampling) GetMinSamples() uint32 {
+	if x != nil {
+		return x.MinSamples
+	}
+	return 0
+}
+
+func (x *AdaptiveSampling) GetMaxSamples() uint32 {
+	if x != nil {
+		return x.MaxSamples
+	}
+	return 0
+}
+
+func (x *AdaptiveSampling) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
 // Request to configure a worker node for rendering.
 type RenderSetupRequest struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	SceneName          string                 `protobuf:"bytes,1,opt,name=scene_name,json=sceneName,proto3" json:"scene_name,omitempty"`                             // The name of the scene to be rendered.
-	JobId              string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`                                         // The ID of the job to be rendered.
-	NumCores           uint32                 `protobuf:"varint,3,opt,name=num_cores,json=numCores,proto3" json:"num_cores,omitempty"`                               // Number of CPU cores the worker should use for rendering.
-	SamplesPerPixel    uint32                 `protobuf:"varint,4,opt,name=samples_per_pixel,json=samplesPerPixel,proto3" json:"samples_per_pixel,omitempty"`        // Number of samples to take per pixel.
-	Sampler            SamplerType            `protobuf:"varint,5,opt,name=sampler,proto3,enum=control.SamplerType" json:"sampler,omitempty"`                        // The type of sampler (render mode) to use.
-	ImageResolution    *ImageResolution       `protobuf:"bytes,6,opt,name=image_resolution,json=imageResolution,proto3" json:"image_resolution,omitempty"`           // The overall image resolution.
-	MaxDepth           uint32                 `protobuf:"varint,7,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`                               // Maximum recursion depth for path tracing.
-	BackgroundColor    *Vec3                  `protobuf:"bytes,8,opt,name=background_color,json=backgroundColor,proto3" json:"background_color,omitempty"`           // The background color of the scene (for RGB rendering).
-	InkColor           *Vec3                  `protobuf:"bytes,9,opt,name=ink_color,json=inkColor,proto3" json:"ink_color,omitempty"`                                // The ink color of the scene.
-	AssetProvider      string                 `protobuf:"bytes,10,opt,name=asset_provider,json=assetProvider,proto3" json:"asset_provider,omitempty"`                // NEW: The network address (host:port) of the asset transport server (e.g., leader).
-	SpectralBackground *SpectralBackground    `protobuf:"bytes,11,opt,name=spectral_background,json=spectralBackground,proto3" json:"spectral_background,omitempty"` // Spectral background for spectral rendering.
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	SceneName               string                 `protobuf:"bytes,1,opt,name=scene_name,json=sceneName,proto3" json:"scene_name,omitempty"`                                                  // The name of the scene to be rendered.
+	JobId                   string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`                                                              // The ID of the job to be rendered.
+	NumCores                uint32                 `protobuf:"varint,3,opt,name=num_cores,json=numCores,proto3" json:"num_cores,omitempty"`                                                    // Number of CPU cores the worker should use for rendering.
+	SamplesPerPixel         uint32                 `protobuf:"varint,4,opt,name=samples_per_pixel,json=samplesPerPixel,proto3" json:"samples_per_pixel,omitempty"`                             // Number of samples to take per pixel.
+	Sampler                 SamplerType            `protobuf:"varint,5,opt,name=sampler,proto3,enum=control.SamplerType" json:"sampler,omitempty"`                                             // The type of sampler (render mode) to use.
+	ImageResolution         *ImageResolution       `protobuf:"bytes,6,opt,name=image_resolution,json=imageResolution,proto3" json:"image_resolution,omitempty"`                                // The overall image resolution.
+	MaxDepth                uint32                 `protobuf:"varint,7,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`                                                    // Maximum recursion depth for path tracing.
+	BackgroundColor         *Vec3                  `protobuf:"bytes,8,opt,name=background_color,json=backgroundColor,proto3" json:"background_color,omitempty"`                                // The background color of the scene (for RGB rendering).
+	InkColor                *Vec3                  `protobuf:"bytes,9,opt,name=ink_color,json=inkColor,proto3" json:"ink_color,omitempty"`                                                     // The ink color of the scene.
+	AssetProvider           string                 `protobuf:"bytes,10,opt,name=asset_provider,json=assetProvider,proto3" json:"asset_provider,omitempty"`                                     // NEW: The network address (host:port) of the asset transport server (e.g., leader).
+	SpectralBackground      *SpectralBackground    `protobuf:"bytes,11,opt,name=spectral_background,json=spectralBackground,proto3" json:"spectral_background,omitempty"`                      // Spectral background for spectral rendering.
+	MaxDiffuseDepth         uint32                 `protobuf:"varint,12,opt,name=max_diffuse_depth,json=maxDiffuseDepth,proto3" json:"max_diffuse_depth,omitempty"`                            // Maximum number of diffuse bounces.
+	MaxSpecularDepth        uint32                 `protobuf:"varint,13,opt,name=max_specular_depth,json=maxSpecularDepth,proto3" json:"max_specular_depth,omitempty"`                         // Maximum number of specular reflections.
+	MaxTransmissionDepth    uint32                 `protobuf:"varint,14,opt,name=max_transmission_depth,json=maxTransmissionDepth,proto3" json:"max_transmission_depth,omitempty"`             // Maximum number of refractions.
+	MaxVolumeDepth          uint32                 `protobuf:"varint,15,opt,name=max_volume_depth,json=maxVolumeDepth,proto3" json:"max_volume_depth,omitempty"`                               // Maximum number of volume scattering events.
+	RussianRouletteDepth    uint32                 `protobuf:"varint,16,opt,name=russian_roulette_depth,json=russianRouletteDepth,proto3" json:"russian_roulette_depth,omitempty"`             // Number of bounces before Russian roulette starts.
+	MltSeed                 uint64                 `protobuf:"varint,17,opt,name=mlt_seed,json=mltSeed,proto3" json:"mlt_seed,omitempty"`                                                      // Seed of the Metropolis light transport sampler.
+	MltBootstrapSamples     uint32                 `protobuf:"varint,18,opt,name=mlt_bootstrap_samples,json=mltBootstrapSamples,proto3" json:"mlt_bootstrap_samples,omitempty"`                // Number of paths used to normalise the Metropolis light transport sampler.
+	MltLargeStepProbability float64                `protobuf:"fixed64,19,opt,name=mlt_large_step_probability,json=mltLargeStepProbability,proto3" json:"mlt_large_step_probability,omitempty"` // Probability of a Metropolis mutation replacing the whole path.
+	Photons                 uint32                 `protobuf:"varint,20,opt,name=photons,proto3" json:"photons,omitempty"`                                                                     // Number of photons emitted per pass by photon mapping samplers.
+	PhotonRadius            float64                `protobuf:"fixed64,21,opt,name=photon_radius,json=photonRadius,proto3" json:"photon_radius,omitempty"`                                      // Initial radius of photon lookups.
+	PhotonAlpha             float64                `protobuf:"fixed64,22,opt,name=photon_alpha,json=photonAlpha,proto3" json:"photon_alpha,omitempty"`                                         // Rate at which photon lookups shrink.
+	SampleGenerator         SampleGenerator        `protobuf:"varint,23,opt,name=sample_generator,json=sampleGenerator,proto3,enum=control.SampleGenerator" json:"sample_generator,omitempty"`
+	SampleSeed              uint64                 `protobuf:"varint,24,opt,name=sample_seed,json=sampleSeed,proto3" json:"sample_seed,omitempty"`
+	Filter                  ReconstructionFilter   `protobuf:"varint,25,opt,name=filter,proto3,enum=control.ReconstructionFilter" json:"filter,omitempty"`
+	// Radius of the filter in pixels, or 0 to use the default radius of the filter.
+	FilterRadius  float64 `protobuf:"fixed64,26,opt,name=filter_radius,json=filterRadius,proto3" json:"filter_radius,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RenderSetupRequest) Reset() {
 	*x = RenderSetupRequest{}
-	mi := &file_control_proto_msgTypes[4]
+	mi := &file_control_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -424,7 +635,7 @@ func (x *RenderSetupRequest) String() string {
 func (*RenderSetupRequest) ProtoMessage() {}
 
 func (x *RenderSetupRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[4]
+	mi := &file_control_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -437,7 +648,7 @@ func (x *RenderSetupRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenderSetupRequest.ProtoReflect.Descriptor instead.
 func (*RenderSetupRequest) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{4}
+	return file_control_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *RenderSetupRequest) GetSceneName() string {
@@ -517,6 +728,111 @@ func (x *RenderSetupRequest) GetSpectralBackground() *SpectralBackground {
 	return nil
 }
 
+func (x *RenderSetupRequest) GetMaxDiffuseDepth() uint32 {
+	if x != nil {
+		return x.MaxDiffuseDepth
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetMaxSpecularDepth() uint32 {
+	if x != nil {
+		return x.MaxSpecularDepth
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetMaxTransmissionDepth() uint32 {
+	if x != nil {
+		return x.MaxTransmissionDepth
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetMaxVolumeDepth() uint32 {
+	if x != nil {
+		return x.MaxVolumeDepth
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetRussianRouletteDepth() uint32 {
+	if x != nil {
+		return x.RussianRouletteDepth
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetMltSeed() uint64 {
+	if x != nil {
+		return x.MltSeed
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetMltBootstrapSamples() uint32 {
+	if x != nil {
+		return x.MltBootstrapSamples
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetMltLargeStepProbability() float64 {
+	if x != nil {
+		return x.MltLargeStepProbability
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetPhotons() uint32 {
+	if x != nil {
+		return x.Photons
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetPhotonRadius() float64 {
+	if x != nil {
+		return x.PhotonRadius
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetPhotonAlpha() float64 {
+	if x != nil {
+		return x.PhotonAlpha
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetSampleGenerator() SampleGenerator {
+	if x != nil {
+		return x.SampleGenerator
+	}
+	return SampleGenerator_SAMPLE_GENERATOR_UNSPECIFIED
+}
+
+func (x *RenderSetupRequest) GetSampleSeed() uint64 {
+	if x != nil {
+		return x.SampleSeed
+	}
+	return 0
+}
+
+func (x *RenderSetupRequest) GetFilter() ReconstructionFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return ReconstructionFilter_RECONSTRUCTION_FILTER_UNSPECIFIED
+}
+
+func (x *RenderSetupRequest) GetFilterRadius() float64 {
+	if x != nil {
+		return x.FilterRadius
+	}
+	return 0
+}
+
 // Response containing status updates during the RenderConfiguration process.
 type RenderSetupResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -528,7 +844,7 @@ type RenderSetupResponse struct {
 
 func (x *RenderSetupResponse) Reset() {
 	*x = RenderSetupResponse{}
-	mi := &file_control_proto_msgTypes[5]
+	mi := &file_control_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -540,7 +856,7 @@ func (x *RenderSetupResponse) String() string {
 func (*RenderSetupResponse) ProtoMessage() {}
 
 func (x *RenderSetupResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[5]
+	mi := &file_control_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -553,7 +869,7 @@ func (x *RenderSetupResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenderSetupResponse.ProtoReflect.Descriptor instead.
 func (*RenderSetupResponse) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{5}
+	return file_control_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *RenderSetupResponse) GetStatus() RenderSetupStatus {
@@ -573,19 +889,24 @@ func (x *RenderSetupResponse) GetErrorMessage() string {
 // Request to render a specific tile of the image.
 // The worker should render the region defined by [x0, y0] to [x1, y1) (exclusive).
 type RenderTileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StripHeight   uint32                 `protobuf:"varint,1,opt,name=strip_height,json=stripHeight,proto3" json:"strip_height,omitempty"` // Height of the strip to render.
-	X0            uint32                 `protobuf:"varint,2,opt,name=x0,proto3" json:"x0,omitempty"`                                      // Start X coordinate (inclusive) of the overall tile in image space.
-	Y0            uint32                 `protobuf:"varint,3,opt,name=y0,proto3" json:"y0,omitempty"`                                      // Start Y coordinate (inclusive) of the overall tile in image space.
-	X1            uint32                 `protobuf:"varint,4,opt,name=x1,proto3" json:"x1,omitempty"`                                      // End X coordinate (exclusive) of the overall tile in image space.
-	Y1            uint32                 `protobuf:"varint,5,opt,name=y1,proto3" json:"y1,omitempty"`                                      // End Y coordinate (exclusive) of the overall tile in image space.
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	StripHeight      uint32                 `protobuf:"varint,1,opt,name=strip_height,json=stripHeight,proto3" json:"strip_height,omitempty"` // Height of the strip to render.
+	X0               uint32                 `protobuf:"varint,2,opt,name=x0,proto3" json:"x0,omitempty"`                                      // Start X coordinate (inclusive) of the overall tile in image space.
+	Y0               uint32                 `protobuf:"varint,3,opt,name=y0,proto3" json:"y0,omitempty"`                                      // Start Y coordinate (inclusive) of the overall tile in image space.
+	X1               uint32                 `protobuf:"varint,4,opt,name=x1,proto3" json:"x1,omitempty"`                                      // End X coordinate (exclusive) of the overall tile in image space.
+	Y1               uint32                 `protobuf:"varint,5,opt,name=y1,proto3" json:"y1,omitempty"`                                      // End Y coordinate (exclusive) of the overall tile in image space.
+	AdaptiveSampling *AdaptiveSampling      `protobuf:"bytes,6,opt,name=adaptive_sampling,json=adaptiveSampling,proto3" json:"adaptive_sampling,omitempty"`
+	NumSamples       uint32                 `protobuf:"varint,7,opt,name=num_samples,json=numSamples,proto3" json:"num_samples,omitempty"`     // Samples per pixel for this tile, overriding samples_per_pixel when non-zero. Used by progressive passes.
+	Aovs             []string               `protobuf:"bytes,8,rep,name=aovs,proto3" json:"aovs,omitempty"`                                    // Names of the AOVs to render along with the beauty pass.
+	LightGroups      []string               `protobuf:"bytes,9,rep,name=light_groups,json=lightGroups,proto3" json:"light_groups,omitempty"`   // Names of the light groups to render along with the beauty pass.
+	FirstSample      uint32                 `protobuf:"varint,10,opt,name=first_sample,json=firstSample,proto3" json:"first_sample,omitempty"` // Number of samples per pixel rendered by previous passes.
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *RenderTileRequest) Reset() {
 	*x = RenderTileRequest{}
-	mi := &file_control_proto_msgTypes[6]
+	mi := &file_control_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -597,7 +918,7 @@ func (x *RenderTileRequest) String() string {
 func (*RenderTileRequest) ProtoMessage() {}
 
 func (x *RenderTileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[6]
+	mi := &file_control_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -610,7 +931,7 @@ func (x *RenderTileRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenderTileRequest.ProtoReflect.Descriptor instead.
 func (*RenderTileRequest) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{6}
+	return file_control_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *RenderTileRequest) GetStripHeight() uint32 {
@@ -648,6 +969,41 @@ func (x *RenderTileRequest) GetY1() uint32 {
 	return 0
 }
 
+func (x *RenderTileRequest) GetAdaptiveSampling() *AdaptiveSampling {
+	if x != nil {
+		return x.AdaptiveSampling
+	}
+	return nil
+}
+
+func (x *RenderTileRequest) GetNumSamples() uint32 {
+	if x != nil {
+		return x.NumSamples
+	}
+	return 0
+}
+
+func (x *RenderTileRequest) GetAovs() []string {
+	if x != nil {
+		return x.Aovs
+	}
+	return nil
+}
+
+func (x *RenderTileRequest) GetLightGroups() []string {
+	if x != nil {
+		return x.LightGroups
+	}
+	return nil
+}
+
+func (x *RenderTileRequest) GetFirstSample() uint32 {
+	if x != nil {
+		return x.FirstSample
+	}
+	return 0
+}
+
 // Response containing a rendered chunk of pixel data for a sub-region within the requested tile.
 // The client will receive multiple RenderTileResponse messages for a single RenderTileRequest,
 // which it can assemble to form the complete tile.
@@ -659,14 +1015,27 @@ type RenderTileResponse struct {
 	PosY   uint32                 `protobuf:"varint,4,opt,name=pos_y,json=posY,proto3" json:"pos_y,omitempty"` // Y-coordinate of the top-left pixel of this chunk (relative to overall image origin).
 	// Flat array of pixel values (e.g., RGBA as [R1, G1, B1, A1, R2, G2, B2, A2...])
 	// For spectral rendering, the pixels are the spectral value for the sampled wavelength.
-	Pixels        []float64 `protobuf:"fixed64,5,rep,packed,name=pixels,proto3" json:"pixels,omitempty"`
+	Pixels       []float64 `protobuf:"fixed64,5,rep,packed,name=pixels,proto3" json:"pixels,omitempty"`
+	SampleCounts []uint32  `protobuf:"varint,6,rep,packed,name=sample_counts,json=sampleCounts,proto3" json:"sample_counts,omitempty"`
+	// AOV values of every pixel in the chunk, in the order requested in RenderTileRequest.
+	Aovs []float64 `protobuf:"fixed64,7,rep,packed,name=aovs,proto3" json:"aovs,omitempty"`
+	// Samples of the tile weighted by the reconstruction filter, sent once the whole tile has been rendered.
+	// It covers the pixels within the radius of the filter of the tile, stored row by row as the weighted sums of
+	// the three channels followed by the sum of the weights.
+	Film []float64 `protobuf:"fixed64,8,rep,packed,name=film,proto3" json:"film,omitempty"`
+	// Light carried to the camera by the light paths traced for the tile, which can land on any pixel of the image,
+	// sent once the whole tile has been rendered. light_pixels holds the indices of the pixels it landed on, row by
+	// row, light_image the three channels of each of them and light_paths the number of light paths traced.
+	LightPixels   []uint32  `protobuf:"varint,9,rep,packed,name=light_pixels,json=lightPixels,proto3" json:"light_pixels,omitempty"`
+	LightImage    []float64 `protobuf:"fixed64,10,rep,packed,name=light_image,json=lightImage,proto3" json:"light_image,omitempty"`
+	LightPaths    uint64    `protobuf:"varint,11,opt,name=light_paths,json=lightPaths,proto3" json:"light_paths,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RenderTileResponse) Reset() {
 	*x = RenderTileResponse{}
-	mi := &file_control_proto_msgTypes[7]
+	mi := &file_control_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -678,7 +1047,7 @@ func (x *RenderTileResponse) String() string {
 func (*RenderTileResponse) ProtoMessage() {}
 
 func (x *RenderTileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[7]
+	mi := &file_control_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -691,7 +1060,7 @@ func (x *RenderTileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenderTileResponse.ProtoReflect.Descriptor instead.
 func (*RenderTileResponse) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{7}
+	return file_control_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *RenderTileResponse) GetWidth() uint32 {
@@ -729,6 +1098,48 @@ func (x *RenderTileResponse) GetPixels() []float64 {
 	return nil
 }
 
+func (x *RenderTileResponse) GetSampleCounts() []uint32 {
+	if x != nil {
+		return x.SampleCounts
+	}
+	return nil
+}
+
+func (x *RenderTileResponse) GetAovs() []float64 {
+	if x != nil {
+		return x.Aovs
+	}
+	return nil
+}
+
+func (x *RenderTileResponse) GetFilm() []float64 {
+	if x != nil {
+		return x.Film
+	}
+	return nil
+}
+
+func (x *RenderTileResponse) GetLightPixels() []uint32 {
+	if x != nil {
+		return x.LightPixels
+	}
+	return nil
+}
+
+func (x *RenderTileResponse) GetLightImage() []float64 {
+	if x != nil {
+		return x.LightImage
+	}
+	return nil
+}
+
+func (x *RenderTileResponse) GetLightPaths() uint64 {
+	if x != nil {
+		return x.LightPaths
+	}
+	return 0
+}
+
 // Request to signal the worker node that rendering is complete.
 // This message can be empty if no specific data is needed.
 type RenderEndRequest struct {
@@ -739,7 +1150,7 @@ type RenderEndRequest struct {
 
 func (x *RenderEndRequest) Reset() {
 	*x = RenderEndRequest{}
-	mi := &file_control_proto_msgTypes[8]
+	mi := &file_control_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -751,7 +1162,7 @@ func (x *RenderEndRequest) String() string {
 func (*RenderEndRequest) ProtoMessage() {}
 
 func (x *RenderEndRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[8]
+	mi := &file_control_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -764,7 +1175,7 @@ func (x *RenderEndRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenderEndRequest.ProtoReflect.Descriptor instead.
 func (*RenderEndRequest) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{8}
+	return file_control_proto_rawDescGZIP(), []int{9}
 }
 
 // Response containing statistics after rendering is complete.
@@ -777,7 +1188,7 @@ type RenderEndResponse struct {
 
 func (x *RenderEndResponse) Reset() {
 	*x = RenderEndResponse{}
-	mi := &file_control_proto_msgTypes[9]
+	mi := &file_control_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -789,7 +1200,7 @@ func (x *RenderEndResponse) String() string {
 func (*RenderEndResponse) ProtoMessage() {}
 
 func (x *RenderEndResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[9]
+	mi := &file_control_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -802,7 +1213,7 @@ func (x *RenderEndResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenderEndResponse.ProtoReflect.Descriptor instead.
 func (*RenderEndResponse) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{9}
+	return file_control_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *RenderEndResponse) GetTotalRaysTraced() uint64 {
@@ -830,7 +1241,13 @@ const file_control_proto_rawDesc = "" +
 	"\x13spectral_properties\"?\n" +
 	"\x0fImageResolution\x12\x14\n" +
 	"\x05width\x18\x01 \x01(\rR\x05width\x12\x16\n" +
-	"\x06height\x18\x02 \x01(\rR\x06height\"\x80\x04\n" +
+	"\x06height\x18\x02 \x01(\rR\x06height\"r\n" +
+	"\x10AdaptiveSampling\x12\x1f\n" +
+	"\vmin_samples\x18\x01 \x01(\rR\n" +
+	"minSamples\x12\x1f\n" +
+	"\vmax_samples\x18\x02 \x01(\rR\n" +
+	"maxSamples\x12\x1c\n" +
+	"\tthreshold\x18\x03 \x01(\x01R\tthreshold\"\xa0\t\n" +
 	"\x12RenderSetupRequest\x12\x1d\n" +
 	"\n" +
 	"scene_name\x18\x01 \x01(\tR\tsceneName\x12\x15\n" +
@@ -844,25 +1261,57 @@ const file_control_proto_rawDesc = "" +
 	"\tink_color\x18\t \x01(\v2\r.control.Vec3R\binkColor\x12%\n" +
 	"\x0easset_provider\x18\n" +
 	" \x01(\tR\rassetProvider\x12L\n" +
-	"\x13spectral_background\x18\v \x01(\v2\x1b.control.SpectralBackgroundR\x12spectralBackground\"n\n" +
+	"\x13spectral_background\x18\v \x01(\v2\x1b.control.SpectralBackgroundR\x12spectralBackground\x12*\n" +
+	"\x11max_diffuse_depth\x18\f \x01(\rR\x0fmaxDiffuseDepth\x12,\n" +
+	"\x12max_specular_depth\x18\r \x01(\rR\x10maxSpecularDepth\x124\n" +
+	"\x16max_transmission_depth\x18\x0e \x01(\rR\x14maxTransmissionDepth\x12(\n" +
+	"\x10max_volume_depth\x18\x0f \x01(\rR\x0emaxVolumeDepth\x124\n" +
+	"\x16russian_roulette_depth\x18\x10 \x01(\rR\x14russianRouletteDepth\x12\x19\n" +
+	"\bmlt_seed\x18\x11 \x01(\x04R\amltSeed\x122\n" +
+	"\x15mlt_bootstrap_samples\x18\x12 \x01(\rR\x13mltBootstrapSamples\x12;\n" +
+	"\x1amlt_large_step_probability\x18\x13 \x01(\x01R\x17mltLargeStepProbability\x12\x18\n" +
+	"\aphotons\x18\x14 \x01(\rR\aphotons\x12#\n" +
+	"\rphoton_radius\x18\x15 \x01(\x01R\fphotonRadius\x12!\n" +
+	"\fphoton_alpha\x18\x16 \x01(\x01R\vphotonAlpha\x12C\n" +
+	"\x10sample_generator\x18\x17 \x01(\x0e2\x18.control.SampleGeneratorR\x0fsampleGenerator\x12\x1f\n" +
+	"\vsample_seed\x18\x18 \x01(\x04R\n" +
+	"sampleSeed\x125\n" +
+	"\x06filter\x18\x19 \x01(\x0e2\x1d.control.ReconstructionFilterR\x06filter\x12#\n" +
+	"\rfilter_radius\x18\x1a \x01(\x01R\ffilterRadius\"n\n" +
 	"\x13RenderSetupResponse\x122\n" +
 	"\x06status\x18\x01 \x01(\x0e2\x1a.control.RenderSetupStatusR\x06status\x12#\n" +
-	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\"v\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\"\xb9\x02\n" +
 	"\x11RenderTileRequest\x12!\n" +
 	"\fstrip_height\x18\x01 \x01(\rR\vstripHeight\x12\x0e\n" +
 	"\x02x0\x18\x02 \x01(\rR\x02x0\x12\x0e\n" +
 	"\x02y0\x18\x03 \x01(\rR\x02y0\x12\x0e\n" +
 	"\x02x1\x18\x04 \x01(\rR\x02x1\x12\x0e\n" +
-	"\x02y1\x18\x05 \x01(\rR\x02y1\"\x84\x01\n" +
+	"\x02y1\x18\x05 \x01(\rR\x02y1\x12F\n" +
+	"\x11adaptive_sampling\x18\x06 \x01(\v2\x19.control.AdaptiveSamplingR\x10adaptiveSampling\x12\x1f\n" +
+	"\vnum_samples\x18\a \x01(\rR\n" +
+	"numSamples\x12\x12\n" +
+	"\x04aovs\x18\b \x03(\tR\x04aovs\x12!\n" +
+	"\flight_groups\x18\t \x03(\tR\vlightGroups\x12!\n" +
+	"\ffirst_sample\x18\n" +
+	" \x01(\rR\vfirstSample\"\xb6\x02\n" +
 	"\x12RenderTileResponse\x12\x14\n" +
 	"\x05width\x18\x01 \x01(\rR\x05width\x12\x16\n" +
 	"\x06height\x18\x02 \x01(\rR\x06height\x12\x13\n" +
 	"\x05pos_x\x18\x03 \x01(\rR\x04posX\x12\x13\n" +
 	"\x05pos_y\x18\x04 \x01(\rR\x04posY\x12\x16\n" +
-	"\x06pixels\x18\x05 \x03(\x01R\x06pixels\"\x12\n" +
-	"\x10RenderEndRequest\"?\n" +
+	"\x06pixels\x18\x05 \x03(\x01R\x06pixels\x12#\n" +
+	"\rsample_counts\x18\x06 \x03(\rR\fsampleCounts\x12\x12\n" +
+	"\x04aovs\x18\a \x03(\x01R\x04aovs\x12\x12\n" +
+	"\x04film\x18\b \x03(\x01R\x04film\x12!\n" +
+	"\flight_pixels\x18\t \x03(\rR\vlightPixels\x12\x1f\n" +
+	"\vlight_image\x18\n" +
+	" \x03(\x01R\n" +
+	"lightImage\x12\x1f\n" +
+	"\vlight_paths\x18\v \x01(\x04R\n" +
+	"lightPaths\"\x12\n" +
+	"\x10RenderEndRequest\"e\n" +
 	"\x11RenderEndResponse\x12*\n" +
-	"\x11total_rays_traced\x18\x01 \x01(\x04R\x0ftotalRaysTraced*m\n" +
+	"\x11total_rays_traced\x18\x01 \x01(\x04R\x0ftotalRaysTracedJ\x04\b\x02\x10\x03J\x04\b\x03\x10\x04R\vlight_imageR\vlight_paths*\xc0\x01\n" +
 	"\vSamplerType\x12\x1c\n" +
 	"\x18SAMPLER_TYPE_UNSPECIFIED\x10\x00\x12\n" +
 	"\n" +
@@ -873,7 +1322,31 @@ const file_control_proto_rawDesc = "" +
 	"WIRE_FRAME\x10\x03\x12\n" +
 	"\n" +
 	"\x06COLOUR\x10\x04\x12\f\n" +
-	"\bSPECTRAL\x10\x05*\xb3\x01\n" +
+	"\bSPECTRAL\x10\x05\x12\b\n" +
+	"\x04BDPT\x10\x06\x12\x11\n" +
+	"\rSPECTRAL_BDPT\x10\a\x12\a\n" +
+	"\x03MLT\x10\b\x12\x10\n" +
+	"\fSPECTRAL_MLT\x10\t\x12\a\n" +
+	"\x03PPM\x10\n" +
+	"\x12\x10\n" +
+	"\fSPECTRAL_PPM\x10\v*{\n" +
+	"\x0fSampleGenerator\x12 \n" +
+	"\x1cSAMPLE_GENERATOR_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vINDEPENDENT\x10\x01\x12\x0e\n" +
+	"\n" +
+	"STRATIFIED\x10\x02\x12\t\n" +
+	"\x05SOBOL\x10\x03\x12\n" +
+	"\n" +
+	"\x06HALTON\x10\x04\x12\x0e\n" +
+	"\n" +
+	"BLUE_NOISE\x10\x05*\x81\x01\n" +
+	"\x14ReconstructionFilter\x12%\n" +
+	"!RECONSTRUCTION_FILTER_UNSPECIFIED\x10\x00\x12\a\n" +
+	"\x03BOX\x10\x01\x12\b\n" +
+	"\x04TENT\x10\x02\x12\f\n" +
+	"\bGAUSSIAN\x10\x03\x12\f\n" +
+	"\bMITCHELL\x10\x04\x12\x13\n" +
+	"\x0fBLACKMAN_HARRIS\x10\x05*\xb3\x01\n" +
 	"\x11RenderSetupStatus\x12\x1f\n" +
 	"\x1bRENDER_SETUP_STATUS_UNKNOWN\x10\x00\x12\x11\n" +
 	"\rLOADING_SCENE\x10\x01\x12\x16\n" +
@@ -901,41 +1374,47 @@ func file_control_proto_rawDescGZIP() []byte {
 	return file_control_proto_rawDescData
 }
 
-var file_control_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_control_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_control_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_control_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_control_proto_goTypes = []any{
 	(SamplerType)(0),                  // 0: control.SamplerType
-	(RenderSetupStatus)(0),            // 1: control.RenderSetupStatus
-	(*Vec3)(nil),                      // 2: control.Vec3
-	(*TabulatedSpectralConstant)(nil), // 3: control.TabulatedSpectralConstant
-	(*SpectralBackground)(nil),        // 4: control.SpectralBackground
-	(*ImageResolution)(nil),           // 5: control.ImageResolution
-	(*RenderSetupRequest)(nil),        // 6: control.RenderSetupRequest
-	(*RenderSetupResponse)(nil),       // 7: control.RenderSetupResponse
-	(*RenderTileRequest)(nil),         // 8: control.RenderTileRequest
-	(*RenderTileResponse)(nil),        // 9: control.RenderTileResponse
-	(*RenderEndRequest)(nil),          // 10: control.RenderEndRequest
-	(*RenderEndResponse)(nil),         // 11: control.RenderEndResponse
+	(SampleGenerator)(0),              // 1: control.SampleGenerator
+	(ReconstructionFilter)(0),         // 2: control.ReconstructionFilter
+	(RenderSetupStatus)(0),            // 3: control.RenderSetupStatus
+	(*Vec3)(nil),                      // 4: control.Vec3
+	(*TabulatedSpectralConstant)(nil), // 5: control.TabulatedSpectralConstant
+	(*SpectralBackground)(nil),        // 6: control.SpectralBackground
+	(*ImageResolution)(nil),           // 7: control.ImageResolution
+	(*AdaptiveSampling)(nil),          // 8: control.AdaptiveSampling
+	(*RenderSetupRequest)(nil),        // 9: control.RenderSetupRequest
+	(*RenderSetupResponse)(nil),       // 10: control.RenderSetupResponse
+	(*RenderTileRequest)(nil),         // 11: control.RenderTileRequest
+	(*RenderTileResponse)(nil),        // 12: control.RenderTileResponse
+	(*RenderEndRequest)(nil),          // 13: control.RenderEndRequest
+	(*RenderEndResponse)(nil),         // 14: control.RenderEndResponse
 }
 var file_control_proto_depIdxs = []int32{
-	3,  // 0: control.SpectralBackground.tabulated:type_name -> control.TabulatedSpectralConstant
+	5,  // 0: control.SpectralBackground.tabulated:type_name -> control.TabulatedSpectralConstant
 	0,  // 1: control.RenderSetupRequest.sampler:type_name -> control.SamplerType
-	5,  // 2: control.RenderSetupRequest.image_resolution:type_name -> control.ImageResolution
-	2,  // 3: control.RenderSetupRequest.background_color:type_name -> control.Vec3
-	2,  // 4: control.RenderSetupRequest.ink_color:type_name -> control.Vec3
-	4,  // 5: control.RenderSetupRequest.spectral_background:type_name -> control.SpectralBackground
-	1,  // 6: control.RenderSetupResponse.status:type_name -> control.RenderSetupStatus
-	6,  // 7: control.RenderControlService.RenderSetup:input_type -> control.RenderSetupRequest
-	8,  // 8: control.RenderControlService.RenderTile:input_type -> control.RenderTileRequest
-	10, // 9: control.RenderControlService.RenderEnd:input_type -> control.RenderEndRequest
-	7,  // 10: control.RenderControlService.RenderSetup:output_type -> control.RenderSetupResponse
-	9,  // 11: control.RenderControlService.RenderTile:output_type -> control.RenderTileResponse
-	11, // 12: control.RenderControlService.RenderEnd:output_type -> control.RenderEndResponse
-	10, // [10:13] is the sub-list for method output_type
-	7,  // [7:10] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	7,  // 2: control.RenderSetupRequest.image_resolution:type_name -> control.ImageResolution
+	4,  // 3: control.RenderSetupRequest.background_color:type_name -> control.Vec3
+	4,  // 4: control.RenderSetupRequest.ink_color:type_name -> control.Vec3
+	6,  // 5: control.RenderSetupRequest.spectral_background:type_name -> control.SpectralBackground
+	1,  // 6: control.RenderSetupRequest.sample_generator:type_name -> control.SampleGenerator
+	2,  // 7: control.RenderSetupRequest.filter:type_name -> control.ReconstructionFilter
+	3,  // 8: control.RenderSetupResponse.status:type_name -> control.RenderSetupStatus
+	8,  // 9: control.RenderTileRequest.adaptive_sampling:type_name -> control.AdaptiveSampling
+	9,  // 10: control.RenderControlService.RenderSetup:input_type -> control.RenderSetupRequest
+	11, // 11: control.RenderControlService.RenderTile:input_type -> control.RenderTileRequest
+	13, // 12: control.RenderControlService.RenderEnd:input_type -> control.RenderEndRequest
+	10, // 13: control.RenderControlService.RenderSetup:output_type -> control.RenderSetupResponse
+	12, // 14: control.RenderControlService.RenderTile:output_type -> control.RenderTileResponse
+	14, // 15: control.RenderControlService.RenderEnd:output_type -> control.RenderEndResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_control_proto_init() }
@@ -952,8 +1431,8 @@ func file_control_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_control_proto_rawDesc), len(file_control_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   10,
+			NumEnums:      4,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
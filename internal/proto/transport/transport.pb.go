@@ -31,6 +31,8 @@ const (
 	TextureType_NOISE                    TextureType = 4
 	TextureType_SPECTRAL_CONSTANT        TextureType = 5
 	TextureType_SPECTRAL_CHECKER         TextureType = 6
+	TextureType_MULTIPLY                 TextureType = 7
+	TextureType_MIX                      TextureType = 8
 )
 
 // Enum value maps for TextureType.
@@ -43,6 +45,8 @@ var (
 		4: "NOISE",
 		5: "SPECTRAL_CONSTANT",
 		6: "SPECTRAL_CHECKER",
+		7: "MULTIPLY",
+		8: "MIX",
 	}
 	TextureType_value = map[string]int32{
 		"TEXTURE_TYPE_UNSPECIFIED": 0,
@@ -52,6 +56,8 @@ var (
 		"NOISE":                    4,
 		"SPECTRAL_CONSTANT":        5,
 		"SPECTRAL_CHECKER":         6,
+		"MULTIPLY":                 7,
+		"MIX":                      8,
 	}
 )
 
@@ -138,6 +144,9 @@ const (
 	MaterialType_LAMBERT                   MaterialType = 4
 	MaterialType_METAL                     MaterialType = 5
 	MaterialType_PBR                       MaterialType = 6
+	MaterialType_PRINCIPLED                MaterialType = 7
+	MaterialType_MATERIALX                 MaterialType = 8
+	MaterialType_CONDUCTOR                 MaterialType = 9
 )
 
 // Enum value maps for MaterialType.
@@ -150,6 +159,9 @@ var (
 		4: "LAMBERT",
 		5: "METAL",
 		6: "PBR",
+		7: "PRINCIPLED",
+		8: "MATERIALX",
+		9: "CONDUCTOR",
 	}
 	MaterialType_value = map[string]int32{
 		"MATERIAL_TYPE_UNSPECIFIED": 0,
@@ -159,6 +171,9 @@ var (
 		"LAMBERT":                   4,
 		"METAL":                     5,
 		"PBR":                       6,
+		"PRINCIPLED":                7,
+		"MATERIALX":                 8,
+		"CONDUCTOR":                 9,
 	}
 )
 
@@ -360,6 +375,59 @@ func (x *ImageTextureMetadata) GetPixelFormat() TexturePixelFormat {
 	return TexturePixelFormat_TEXTURE_PIXEL_FORMAT_UNSPECIFIED
 }
 
+// Describes a NanoVDB file holding volume grids, such as the density and temperature of smoke and fire.
+type VolumeGridMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"` // Size of the file in bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeGridMetadata) Reset() {
+	*x = VolumeGridMetadata{}
+	mi := &file_transport_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeGridMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeGridMetadata) ProtoMessage() {}
+
+func (x *VolumeGridMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeGridMetadata.ProtoReflect.Descriptor instead.
+func (*VolumeGridMetadata) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VolumeGridMetadata) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *VolumeGridMetadata) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 type DisplaceOperator struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Min             float64                `protobuf:"fixed64,1,opt,name=min,proto3" json:"min,omitempty"`
@@ -371,7 +439,7 @@ type DisplaceOperator struct {
 
 func (x *DisplaceOperator) Reset() {
 	*x = DisplaceOperator{}
-	mi := &file_transport_proto_msgTypes[1]
+	mi := &file_transport_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -383,7 +451,7 @@ func (x *DisplaceOperator) String() string {
 func (*DisplaceOperator) ProtoMessage() {}
 
 func (x *DisplaceOperator) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[1]
+	mi := &file_transport_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -396,7 +464,7 @@ func (x *DisplaceOperator) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DisplaceOperator.ProtoReflect.Descriptor instead.
 func (*DisplaceOperator) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{1}
+	return file_transport_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *DisplaceOperator) GetMin() float64 {
@@ -432,7 +500,7 @@ type Vec3 struct {
 
 func (x *Vec3) Reset() {
 	*x = Vec3{}
-	mi := &file_transport_proto_msgTypes[2]
+	mi := &file_transport_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -444,7 +512,7 @@ func (x *Vec3) String() string {
 func (*Vec3) ProtoMessage() {}
 
 func (x *Vec3) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[2]
+	mi := &file_transport_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -457,7 +525,7 @@ func (x *Vec3) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vec3.ProtoReflect.Descriptor instead.
 func (*Vec3) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{2}
+	return file_transport_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Vec3) GetX() float32 {
@@ -492,7 +560,7 @@ type Vec2 struct {
 
 func (x *Vec2) Reset() {
 	*x = Vec2{}
-	mi := &file_transport_proto_msgTypes[3]
+	mi := &file_transport_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -504,7 +572,7 @@ func (x *Vec2) String() string {
 func (*Vec2) ProtoMessage() {}
 
 func (x *Vec2) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[3]
+	mi := &file_transport_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -517,7 +585,7 @@ func (x *Vec2) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vec2.ProtoReflect.Descriptor instead.
 func (*Vec2) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{3}
+	return file_transport_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Vec2) GetU() float32 {
@@ -553,7 +621,7 @@ type Camera struct {
 
 func (x *Camera) Reset() {
 	*x = Camera{}
-	mi := &file_transport_proto_msgTypes[4]
+	mi := &file_transport_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -565,7 +633,7 @@ func (x *Camera) String() string {
 func (*Camera) ProtoMessage() {}
 
 func (x *Camera) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[4]
+	mi := &file_transport_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -578,7 +646,7 @@ func (x *Camera) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Camera.ProtoReflect.Descriptor instead.
 func (*Camera) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{4}
+	return file_transport_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Camera) GetLookfrom() *Vec3 {
@@ -663,6 +731,8 @@ type Texture struct {
 	//	*Texture_Noise
 	//	*Texture_SpectralConstant
 	//	*Texture_SpectralChecker
+	//	*Texture_Multiply
+	//	*Texture_Mix
 	TextureProperties isTexture_TextureProperties `protobuf_oneof:"texture_properties"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
@@ -670,7 +740,7 @@ type Texture struct {
 
 func (x *Texture) Reset() {
 	*x = Texture{}
-	mi := &file_transport_proto_msgTypes[5]
+	mi := &file_transport_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -682,7 +752,7 @@ func (x *Texture) String() string {
 func (*Texture) ProtoMessage() {}
 
 func (x *Texture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[5]
+	mi := &file_transport_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -695,7 +765,7 @@ func (x *Texture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Texture.ProtoReflect.Descriptor instead.
 func (*Texture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{5}
+	return file_transport_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Texture) GetName() string {
@@ -773,6 +843,24 @@ func (x *Texture) GetSpectralChecker() *SpectralCheckerTexture {
 	return nil
 }
 
+func (x *Texture) GetMultiply() *MultiplyTexture {
+	if x != nil {
+		if x, ok := x.TextureProperties.(*Texture_Multiply); ok {
+			return x.Multiply
+		}
+	}
+	return nil
+}
+
+func (x *Texture) GetMix() *MixTexture {
+	if x != nil {
+		if x, ok := x.TextureProperties.(*Texture_Mix); ok {
+			return x.Mix
+		}
+	}
+	return nil
+}
+
 type isTexture_TextureProperties interface {
 	isTexture_TextureProperties()
 }
@@ -801,6 +889,14 @@ type Texture_SpectralChecker struct {
 	SpectralChecker *SpectralCheckerTexture `protobuf:"bytes,8,opt,name=spectral_checker,json=spectralChecker,proto3,oneof"`
 }
 
+type Texture_Multiply struct {
+	Multiply *MultiplyTexture `protobuf:"bytes,9,opt,name=multiply,proto3,oneof"`
+}
+
+type Texture_Mix struct {
+	Mix *MixTexture `protobuf:"bytes,10,opt,name=mix,proto3,oneof"`
+}
+
 func (*Texture_Constant) isTexture_TextureProperties() {}
 
 func (*Texture_Checker) isTexture_TextureProperties() {}
@@ -813,6 +909,10 @@ func (*Texture_SpectralConstant) isTexture_TextureProperties() {}
 
 func (*Texture_SpectralChecker) isTexture_TextureProperties() {}
 
+func (*Texture_Multiply) isTexture_TextureProperties() {}
+
+func (*Texture_Mix) isTexture_TextureProperties() {}
+
 // Represents a constant color texture.
 type ConstantTexture struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -823,7 +923,7 @@ type ConstantTexture struct {
 
 func (x *ConstantTexture) Reset() {
 	*x = ConstantTexture{}
-	mi := &file_transport_proto_msgTypes[6]
+	mi := &file_transport_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -835,7 +935,7 @@ func (x *ConstantTexture) String() string {
 func (*ConstantTexture) ProtoMessage() {}
 
 func (x *ConstantTexture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[6]
+	mi := &file_transport_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -848,7 +948,7 @@ func (x *ConstantTexture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConstantTexture.ProtoReflect.Descriptor instead.
 func (*ConstantTexture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{6}
+	return file_transport_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ConstantTexture) GetValue() *Vec3 {
@@ -869,7 +969,7 @@ type CheckerTexture struct {
 
 func (x *CheckerTexture) Reset() {
 	*x = CheckerTexture{}
-	mi := &file_transport_proto_msgTypes[7]
+	mi := &file_transport_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -881,7 +981,7 @@ func (x *CheckerTexture) String() string {
 func (*CheckerTexture) ProtoMessage() {}
 
 func (x *CheckerTexture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[7]
+	mi := &file_transport_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -894,7 +994,7 @@ func (x *CheckerTexture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckerTexture.ProtoReflect.Descriptor instead.
 func (*CheckerTexture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{7}
+	return file_transport_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CheckerTexture) GetOdd() *Texture {
@@ -911,6 +1011,120 @@ func (x *CheckerTexture) GetEven() *Texture {
 	return nil
 }
 
+// Represents the product of two textures.
+type MultiplyTexture struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	A             *Texture               `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+	B             *Texture               `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiplyTexture) Reset() {
+	*x = MultiplyTexture{}
+	mi := &file_transport_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiplyTexture) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiplyTexture) ProtoMessage() {}
+
+func (x *MultiplyTexture) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiplyTexture.ProtoReflect.Descriptor instead.
+func (*MultiplyTexture) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MultiplyTexture) GetA() *Texture {
+	if x != nil {
+		return x.A
+	}
+	return nil
+}
+
+func (x *MultiplyTexture) GetB() *Texture {
+	if x != nil {
+		return x.B
+	}
+	return nil
+}
+
+// Represents a linear interpolation between two textures, where the first channel of mix is the weight of fg.
+type MixTexture struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fg            *Texture               `protobuf:"bytes,1,opt,name=fg,proto3" json:"fg,omitempty"`
+	Bg            *Texture               `protobuf:"bytes,2,opt,name=bg,proto3" json:"bg,omitempty"`
+	Mix           *Texture               `protobuf:"bytes,3,opt,name=mix,proto3" json:"mix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MixTexture) Reset() {
+	*x = MixTexture{}
+	mi := &file_transport_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MixTexture) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MixTexture) ProtoMessage() {}
+
+func (x *MixTexture) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MixTexture.ProtoReflect.Descriptor instead.
+func (*MixTexture) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MixTexture) GetFg() *Texture {
+	if x != nil {
+		return x.Fg
+	}
+	return nil
+}
+
+func (x *MixTexture) GetBg() *Texture {
+	if x != nil {
+		return x.Bg
+	}
+	return nil
+}
+
+func (x *MixTexture) GetMix() *Texture {
+	if x != nil {
+		return x.Mix
+	}
+	return nil
+}
+
 // Represents an image texture.
 type ImageTexture struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -921,7 +1135,7 @@ type ImageTexture struct {
 
 func (x *ImageTexture) Reset() {
 	*x = ImageTexture{}
-	mi := &file_transport_proto_msgTypes[8]
+	mi := &file_transport_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -933,7 +1147,7 @@ func (x *ImageTexture) String() string {
 func (*ImageTexture) ProtoMessage() {}
 
 func (x *ImageTexture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[8]
+	mi := &file_transport_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -946,7 +1160,7 @@ func (x *ImageTexture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ImageTexture.ProtoReflect.Descriptor instead.
 func (*ImageTexture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{8}
+	return file_transport_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ImageTexture) GetFilename() string {
@@ -966,7 +1180,7 @@ type NoiseTexture struct {
 
 func (x *NoiseTexture) Reset() {
 	*x = NoiseTexture{}
-	mi := &file_transport_proto_msgTypes[9]
+	mi := &file_transport_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -978,7 +1192,7 @@ func (x *NoiseTexture) String() string {
 func (*NoiseTexture) ProtoMessage() {}
 
 func (x *NoiseTexture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[9]
+	mi := &file_transport_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -991,7 +1205,7 @@ func (x *NoiseTexture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NoiseTexture.ProtoReflect.Descriptor instead.
 func (*NoiseTexture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{9}
+	return file_transport_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *NoiseTexture) GetScale() float32 {
@@ -1017,7 +1231,7 @@ type SpectralConstantTexture struct {
 
 func (x *SpectralConstantTexture) Reset() {
 	*x = SpectralConstantTexture{}
-	mi := &file_transport_proto_msgTypes[10]
+	mi := &file_transport_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1029,7 +1243,7 @@ func (x *SpectralConstantTexture) String() string {
 func (*SpectralConstantTexture) ProtoMessage() {}
 
 func (x *SpectralConstantTexture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[10]
+	mi := &file_transport_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1042,7 +1256,7 @@ func (x *SpectralConstantTexture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SpectralConstantTexture.ProtoReflect.Descriptor instead.
 func (*SpectralConstantTexture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{10}
+	return file_transport_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SpectralConstantTexture) GetSpectralProperties() isSpectralConstantTexture_SpectralProperties {
@@ -1129,7 +1343,7 @@ type GaussianSpectralConstant struct {
 
 func (x *GaussianSpectralConstant) Reset() {
 	*x = GaussianSpectralConstant{}
-	mi := &file_transport_proto_msgTypes[11]
+	mi := &file_transport_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1141,7 +1355,7 @@ func (x *GaussianSpectralConstant) String() string {
 func (*GaussianSpectralConstant) ProtoMessage() {}
 
 func (x *GaussianSpectralConstant) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[11]
+	mi := &file_transport_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1154,7 +1368,7 @@ func (x *GaussianSpectralConstant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GaussianSpectralConstant.ProtoReflect.Descriptor instead.
 func (*GaussianSpectralConstant) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{11}
+	return file_transport_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GaussianSpectralConstant) GetPeakValue() float32 {
@@ -1189,7 +1403,7 @@ type TabulatedSpectralConstant struct {
 
 func (x *TabulatedSpectralConstant) Reset() {
 	*x = TabulatedSpectralConstant{}
-	mi := &file_transport_proto_msgTypes[12]
+	mi := &file_transport_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1201,7 +1415,7 @@ func (x *TabulatedSpectralConstant) String() string {
 func (*TabulatedSpectralConstant) ProtoMessage() {}
 
 func (x *TabulatedSpectralConstant) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[12]
+	mi := &file_transport_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1214,7 +1428,7 @@ func (x *TabulatedSpectralConstant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TabulatedSpectralConstant.ProtoReflect.Descriptor instead.
 func (*TabulatedSpectralConstant) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{12}
+	return file_transport_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *TabulatedSpectralConstant) GetWavelengths() []float32 {
@@ -1241,7 +1455,7 @@ type NeutralSpectralConstant struct {
 
 func (x *NeutralSpectralConstant) Reset() {
 	*x = NeutralSpectralConstant{}
-	mi := &file_transport_proto_msgTypes[13]
+	mi := &file_transport_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1253,7 +1467,7 @@ func (x *NeutralSpectralConstant) String() string {
 func (*NeutralSpectralConstant) ProtoMessage() {}
 
 func (x *NeutralSpectralConstant) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[13]
+	mi := &file_transport_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1266,7 +1480,7 @@ func (x *NeutralSpectralConstant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NeutralSpectralConstant.ProtoReflect.Descriptor instead.
 func (*NeutralSpectralConstant) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{13}
+	return file_transport_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *NeutralSpectralConstant) GetReflectance() float32 {
@@ -1286,7 +1500,7 @@ type FromLightSourceLibrary struct {
 
 func (x *FromLightSourceLibrary) Reset() {
 	*x = FromLightSourceLibrary{}
-	mi := &file_transport_proto_msgTypes[14]
+	mi := &file_transport_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1298,7 +1512,7 @@ func (x *FromLightSourceLibrary) String() string {
 func (*FromLightSourceLibrary) ProtoMessage() {}
 
 func (x *FromLightSourceLibrary) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[14]
+	mi := &file_transport_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1311,7 +1525,7 @@ func (x *FromLightSourceLibrary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FromLightSourceLibrary.ProtoReflect.Descriptor instead.
 func (*FromLightSourceLibrary) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{14}
+	return file_transport_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *FromLightSourceLibrary) GetLightSourceName() string {
@@ -1332,7 +1546,7 @@ type SpectralCheckerTexture struct {
 
 func (x *SpectralCheckerTexture) Reset() {
 	*x = SpectralCheckerTexture{}
-	mi := &file_transport_proto_msgTypes[15]
+	mi := &file_transport_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1344,7 +1558,7 @@ func (x *SpectralCheckerTexture) String() string {
 func (*SpectralCheckerTexture) ProtoMessage() {}
 
 func (x *SpectralCheckerTexture) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[15]
+	mi := &file_transport_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1357,7 +1571,7 @@ func (x *SpectralCheckerTexture) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SpectralCheckerTexture.ProtoReflect.Descriptor instead.
 func (*SpectralCheckerTexture) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{15}
+	return file_transport_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *SpectralCheckerTexture) GetOdd() *SpectralConstantTexture {
@@ -1387,6 +1601,9 @@ type Material struct {
 	//	*Material_Lambert
 	//	*Material_Metal
 	//	*Material_Pbr
+	//	*Material_Principled
+	//	*Material_Materialx
+	//	*Material_Conductor
 	MaterialProperties isMaterial_MaterialProperties `protobuf_oneof:"material_properties"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
@@ -1394,7 +1611,7 @@ type Material struct {
 
 func (x *Material) Reset() {
 	*x = Material{}
-	mi := &file_transport_proto_msgTypes[16]
+	mi := &file_transport_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1406,7 +1623,7 @@ func (x *Material) String() string {
 func (*Material) ProtoMessage() {}
 
 func (x *Material) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[16]
+	mi := &file_transport_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1419,7 +1636,7 @@ func (x *Material) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Material.ProtoReflect.Descriptor instead.
 func (*Material) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{16}
+	return file_transport_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *Material) GetName() string {
@@ -1497,6 +1714,33 @@ func (x *Material) GetPbr() *PBRMaterial {
 	return nil
 }
 
+func (x *Material) GetPrincipled() *PrincipledMaterial {
+	if x != nil {
+		if x, ok := x.MaterialProperties.(*Material_Principled); ok {
+			return x.Principled
+		}
+	}
+	return nil
+}
+
+func (x *Material) GetMaterialx() *MaterialXReference {
+	if x != nil {
+		if x, ok := x.MaterialProperties.(*Material_Materialx); ok {
+			return x.Materialx
+		}
+	}
+	return nil
+}
+
+func (x *Material) GetConductor() *ConductorMaterial {
+	if x != nil {
+		if x, ok := x.MaterialProperties.(*Material_Conductor); ok {
+			return x.Conductor
+		}
+	}
+	return nil
+}
+
 type isMaterial_MaterialProperties interface {
 	isMaterial_MaterialProperties()
 }
@@ -1525,7 +1769,19 @@ type Material_Pbr struct {
 	Pbr *PBRMaterial `protobuf:"bytes,8,opt,name=pbr,proto3,oneof"`
 }
 
-func (*Material_Dielectric) isMaterial_MaterialProperties() {}
+type Material_Principled struct {
+	Principled *PrincipledMaterial `protobuf:"bytes,9,opt,name=principled,proto3,oneof"`
+}
+
+type Material_Materialx struct {
+	Materialx *MaterialXReference `protobuf:"bytes,10,opt,name=materialx,proto3,oneof"`
+}
+
+type Material_Conductor struct {
+	Conductor *ConductorMaterial `protobuf:"bytes,11,opt,name=conductor,proto3,oneof"`
+}
+
+func (*Material_Dielectric) isMaterial_MaterialProperties() {}
 
 func (*Material_Diffuselight) isMaterial_MaterialProperties() {}
 
@@ -1537,6 +1793,12 @@ func (*Material_Metal) isMaterial_MaterialProperties() {}
 
 func (*Material_Pbr) isMaterial_MaterialProperties() {}
 
+func (*Material_Principled) isMaterial_MaterialProperties() {}
+
+func (*Material_Materialx) isMaterial_MaterialProperties() {}
+
+func (*Material_Conductor) isMaterial_MaterialProperties() {}
+
 // Represents a Lambertian material.
 type LambertMaterial struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1551,7 +1813,7 @@ type LambertMaterial struct {
 
 func (x *LambertMaterial) Reset() {
 	*x = LambertMaterial{}
-	mi := &file_transport_proto_msgTypes[17]
+	mi := &file_transport_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1563,7 +1825,7 @@ func (x *LambertMaterial) String() string {
 func (*LambertMaterial) ProtoMessage() {}
 
 func (x *LambertMaterial) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[17]
+	mi := &file_transport_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1576,7 +1838,7 @@ func (x *LambertMaterial) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LambertMaterial.ProtoReflect.Descriptor instead.
 func (*LambertMaterial) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{17}
+	return file_transport_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *LambertMaterial) GetAlbedoProperties() isLambertMaterial_AlbedoProperties {
@@ -1634,13 +1896,16 @@ type DielectricMaterial struct {
 	//	*DielectricMaterial_AbsorptionCoeff
 	//	*DielectricMaterial_SpectralAbsorptionCoeff
 	AbsorptionProperties isDielectricMaterial_AbsorptionProperties `protobuf_oneof:"absorption_properties"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	// Where dielectrics overlap the one with the highest priority fills the space.
+	Priority      int32     `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	ThinFilm      *ThinFilm `protobuf:"bytes,7,opt,name=thin_film,json=thinFilm,proto3" json:"thin_film,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DielectricMaterial) Reset() {
 	*x = DielectricMaterial{}
-	mi := &file_transport_proto_msgTypes[18]
+	mi := &file_transport_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1652,7 +1917,7 @@ func (x *DielectricMaterial) String() string {
 func (*DielectricMaterial) ProtoMessage() {}
 
 func (x *DielectricMaterial) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[18]
+	mi := &file_transport_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1665,7 +1930,7 @@ func (x *DielectricMaterial) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DielectricMaterial.ProtoReflect.Descriptor instead.
 func (*DielectricMaterial) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{18}
+	return file_transport_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *DielectricMaterial) GetRefractiveIndexProperties() isDielectricMaterial_RefractiveIndexProperties {
@@ -1725,6 +1990,20 @@ func (x *DielectricMaterial) GetSpectralAbsorptionCoeff() *SpectralConstantTextu
 	return nil
 }
 
+func (x *DielectricMaterial) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *DielectricMaterial) GetThinFilm() *ThinFilm {
+	if x != nil {
+		return x.ThinFilm
+	}
+	return nil
+}
+
 type isDielectricMaterial_RefractiveIndexProperties interface {
 	isDielectricMaterial_RefractiveIndexProperties()
 }
@@ -1771,7 +2050,7 @@ type DiffuseLightMaterial struct {
 
 func (x *DiffuseLightMaterial) Reset() {
 	*x = DiffuseLightMaterial{}
-	mi := &file_transport_proto_msgTypes[19]
+	mi := &file_transport_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1783,7 +2062,7 @@ func (x *DiffuseLightMaterial) String() string {
 func (*DiffuseLightMaterial) ProtoMessage() {}
 
 func (x *DiffuseLightMaterial) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[19]
+	mi := &file_transport_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1796,7 +2075,7 @@ func (x *DiffuseLightMaterial) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DiffuseLightMaterial.ProtoReflect.Descriptor instead.
 func (*DiffuseLightMaterial) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{19}
+	return file_transport_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *DiffuseLightMaterial) GetEmissionProperties() isDiffuseLightMaterial_EmissionProperties {
@@ -1854,7 +2133,7 @@ type IsotropicMaterial struct {
 
 func (x *IsotropicMaterial) Reset() {
 	*x = IsotropicMaterial{}
-	mi := &file_transport_proto_msgTypes[20]
+	mi := &file_transport_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1866,7 +2145,7 @@ func (x *IsotropicMaterial) String() string {
 func (*IsotropicMaterial) ProtoMessage() {}
 
 func (x *IsotropicMaterial) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[20]
+	mi := &file_transport_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1879,7 +2158,7 @@ func (x *IsotropicMaterial) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IsotropicMaterial.ProtoReflect.Descriptor instead.
 func (*IsotropicMaterial) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{20}
+	return file_transport_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *IsotropicMaterial) GetAlbedoProperties() isIsotropicMaterial_AlbedoProperties {
@@ -1915,38 +2194,814 @@ type IsotropicMaterial_Albedo struct {
 	Albedo *Texture `protobuf:"bytes,1,opt,name=albedo,proto3,oneof"`
 }
 
-type IsotropicMaterial_SpectralAlbedo struct {
-	SpectralAlbedo *SpectralConstantTexture `protobuf:"bytes,2,opt,name=spectral_albedo,json=spectralAlbedo,proto3,oneof"`
+type IsotropicMaterial_SpectralAlbedo struct {
+	SpectralAlbedo *SpectralConstantTexture `protobuf:"bytes,2,opt,name=spectral_albedo,json=spectralAlbedo,proto3,oneof"`
+}
+
+func (*IsotropicMaterial_Albedo) isIsotropicMaterial_AlbedoProperties() {}
+
+func (*IsotropicMaterial_SpectralAlbedo) isIsotropicMaterial_AlbedoProperties() {}
+
+// Represents a Metal material.
+type MetalMaterial struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Albedo        *Vec3                  `protobuf:"bytes,1,opt,name=albedo,proto3" json:"albedo,omitempty"`
+	Fuzz          float32                `protobuf:"fixed32,2,opt,name=fuzz,proto3" json:"fuzz,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetalMaterial) Reset() {
+	*x = MetalMaterial{}
+	mi := &file_transport_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetalMaterial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetalMaterial) ProtoMessage() {}
+
+func (x *MetalMaterial) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetalMaterial.ProtoReflect.Descriptor instead.
+func (*MetalMaterial) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *MetalMaterial) GetAlbedo() *Vec3 {
+	if x != nil {
+		return x.Albedo
+	}
+	return nil
+}
+
+func (x *MetalMaterial) GetFuzz() float32 {
+	if x != nil {
+		return x.Fuzz
+	}
+	return 0
+}
+
+// Represents a Physically Based Rendering (PBR) material.
+type PBRMaterial struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Albedo        *Texture               `protobuf:"bytes,1,opt,name=albedo,proto3" json:"albedo,omitempty"`
+	Roughness     *Texture               `protobuf:"bytes,2,opt,name=roughness,proto3" json:"roughness,omitempty"`
+	Metalness     *Texture               `protobuf:"bytes,3,opt,name=metalness,proto3" json:"metalness,omitempty"`
+	NormalMap     *Texture               `protobuf:"bytes,4,opt,name=normal_map,json=normalMap,proto3" json:"normal_map,omitempty"`
+	Sss           *Texture               `protobuf:"bytes,5,opt,name=sss,proto3" json:"sss,omitempty"`
+	SssRadius     float32                `protobuf:"fixed32,6,opt,name=sss_radius,json=sssRadius,proto3" json:"sss_radius,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PBRMaterial) Reset() {
+	*x = PBRMaterial{}
+	mi := &file_transport_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PBRMaterial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PBRMaterial) ProtoMessage() {}
+
+func (x *PBRMaterial) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PBRMaterial.ProtoReflect.Descriptor instead.
+func (*PBRMaterial) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PBRMaterial) GetAlbedo() *Texture {
+	if x != nil {
+		return x.Albedo
+	}
+	return nil
+}
+
+func (x *PBRMaterial) GetRoughness() *Texture {
+	if x != nil {
+		return x.Roughness
+	}
+	return nil
+}
+
+func (x *PBRMaterial) GetMetalness() *Texture {
+	if x != nil {
+		return x.Metalness
+	}
+	return nil
+}
+
+func (x *PBRMaterial) GetNormalMap() *Texture {
+	if x != nil {
+		return x.NormalMap
+	}
+	return nil
+}
+
+func (x *PBRMaterial) GetSss() *Texture {
+	if x != nil {
+		return x.Sss
+	}
+	return nil
+}
+
+func (x *PBRMaterial) GetSssRadius() float32 {
+	if x != nil {
+		return x.SssRadius
+	}
+	return 0
+}
+
+// Represents a principled surface with the parameters of the Disney BSDF. The metallic, roughness and normal map
+// textures are optional. All the parameters are in [0, 1] except for the refractive index.
+type PrincipledMaterial struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BaseColor *Texture               `protobuf:"bytes,1,opt,name=base_color,json=baseColor,proto3" json:"base_color,omitempty"`
+	Metallic  *Texture               `protobuf:"bytes,2,opt,name=metallic,proto3" json:"metallic,omitempty"`
+	Roughness *Texture               `protobuf:"bytes,3,opt,name=roughness,proto3" json:"roughness,omitempty"`
+	NormalMap *Texture               `protobuf:"bytes,4,opt,name=normal_map,json=normalMap,proto3" json:"normal_map,omitempty"`
+	// Reflectance at normal incidence of the non-metallic part, where 0.5 is 4%.
+	Specular       float32 `protobuf:"fixed32,5,opt,name=specular,proto3" json:"specular,omitempty"`
+	SpecularTint   float32 `protobuf:"fixed32,6,opt,name=specular_tint,json=specularTint,proto3" json:"specular_tint,omitempty"`
+	Anisotropic    float32 `protobuf:"fixed32,7,opt,name=anisotropic,proto3" json:"anisotropic,omitempty"`
+	Sheen          float32 `protobuf:"fixed32,8,opt,name=sheen,proto3" json:"sheen,omitempty"`
+	SheenTint      float32 `protobuf:"fixed32,9,opt,name=sheen_tint,json=sheenTint,proto3" json:"sheen_tint,omitempty"`
+	Clearcoat      float32 `protobuf:"fixed32,10,opt,name=clearcoat,proto3" json:"clearcoat,omitempty"`
+	ClearcoatGloss float32 `protobuf:"fixed32,11,opt,name=clearcoat_gloss,json=clearcoatGloss,proto3" json:"clearcoat_gloss,omitempty"`
+	Transmission   float32 `protobuf:"fixed32,12,opt,name=transmission,proto3" json:"transmission,omitempty"`
+	Subsurface     float32 `protobuf:"fixed32,13,opt,name=subsurface,proto3" json:"subsurface,omitempty"`
+	// Refractive index of the transmission lobe. Defaults to 1.5.
+	Ior           float32 `protobuf:"fixed32,14,opt,name=ior,proto3" json:"ior,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrincipledMaterial) Reset() {
+	*x = PrincipledMaterial{}
+	mi := &file_transport_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrincipledMaterial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrincipledMaterial) ProtoMessage() {}
+
+func (x *PrincipledMaterial) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrincipledMaterial.ProtoReflect.Descriptor instead.
+func (*PrincipledMaterial) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PrincipledMaterial) GetBaseColor() *Texture {
+	if x != nil {
+		return x.BaseColor
+	}
+	return nil
+}
+
+func (x *PrincipledMaterial) GetMetallic() *Texture {
+	if x != nil {
+		return x.Metallic
+	}
+	return nil
+}
+
+func (x *PrincipledMaterial) GetRoughness() *Texture {
+	if x != nil {
+		return x.Roughness
+	}
+	return nil
+}
+
+func (x *PrincipledMaterial) GetNormalMap() *Texture {
+	if x != nil {
+		return x.NormalMap
+	}
+	return nil
+}
+
+func (x *PrincipledMaterial) GetSpecular() float32 {
+	if x != nil {
+		return x.Specular
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetSpecularTint() float32 {
+	if x != nil {
+		return x.SpecularTint
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetAnisotropic() float32 {
+	if x != nil {
+		return x.Anisotropic
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetSheen() float32 {
+	if x != nil {
+		return x.Sheen
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetSheenTint() float32 {
+	if x != nil {
+		return x.SheenTint
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetClearcoat() float32 {
+	if x != nil {
+		return x.Clearcoat
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetClearcoatGloss() float32 {
+	if x != nil {
+		return x.ClearcoatGloss
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetTransmission() float32 {
+	if x != nil {
+		return x.Transmission
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetSubsurface() float32 {
+	if x != nil {
+		return x.Subsurface
+	}
+	return 0
+}
+
+func (x *PrincipledMaterial) GetIor() float32 {
+	if x != nil {
+		return x.Ior
+	}
+	return 0
+}
+
+// Represents a metal with the complex refractive index eta + ik, tabulated over wavelength in nanometres, or that of
+// a metal from the built-in library. A roughness of 0 makes a perfect mirror.
+type ConductorMaterial struct {
+	state     protoimpl.MessageState     `protogen:"open.v1"`
+	Eta       *TabulatedSpectralConstant `protobuf:"bytes,1,opt,name=eta,proto3" json:"eta,omitempty"`
+	K         *TabulatedSpectralConstant `protobuf:"bytes,2,opt,name=k,proto3" json:"k,omitempty"`
+	Roughness float32                    `protobuf:"fixed32,3,opt,name=roughness,proto3" json:"roughness,omitempty"`
+	// Name of a metal from the built-in library, such as "gold", used instead of eta and k when set.
+	Metal         string    `protobuf:"bytes,4,opt,name=metal,proto3" json:"metal,omitempty"`
+	ThinFilm      *ThinFilm `protobuf:"bytes,5,opt,name=thin_film,json=thinFilm,proto3" json:"thin_film,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConductorMaterial) Reset() {
+	*x = ConductorMaterial{}
+	mi := &file_transport_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConductorMaterial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConductorMaterial) ProtoMessage() {}
+
+func (x *ConductorMaterial) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConductorMaterial.ProtoReflect.Descriptor instead.
+func (*ConductorMaterial) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ConductorMaterial) GetEta() *TabulatedSpectralConstant {
+	if x != nil {
+		return x.Eta
+	}
+	return nil
+}
+
+func (x *ConductorMaterial) GetK() *TabulatedSpectralConstant {
+	if x != nil {
+		return x.K
+	}
+	return nil
+}
+
+func (x *ConductorMaterial) GetRoughness() float32 {
+	if x != nil {
+		return x.Roughness
+	}
+	return 0
+}
+
+func (x *ConductorMaterial) GetMetal() string {
+	if x != nil {
+		return x.Metal
+	}
+	return ""
+}
+
+func (x *ConductorMaterial) GetThinFilm() *ThinFilm {
+	if x != nil {
+		return x.ThinFilm
+	}
+	return nil
+}
+
+// Represents a thin film coating a dielectric or a conductor, whose thickness in nanometres is read from the first
+// channel of the thickness texture.
+type ThinFilm struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Thickness     *Texture               `protobuf:"bytes,1,opt,name=thickness,proto3" json:"thickness,omitempty"`
+	Ior           float32                `protobuf:"fixed32,2,opt,name=ior,proto3" json:"ior,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThinFilm) Reset() {
+	*x = ThinFilm{}
+	mi := &file_transport_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThinFilm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThinFilm) ProtoMessage() {}
+
+func (x *ThinFilm) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThinFilm.ProtoReflect.Descriptor instead.
+func (*ThinFilm) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ThinFilm) GetThickness() *Texture {
+	if x != nil {
+		return x.Thickness
+	}
+	return nil
+}
+
+func (x *ThinFilm) GetIor() float32 {
+	if x != nil {
+		return x.Ior
+	}
+	return 0
+}
+
+// Refers to a material defined in a MaterialX document, which is translated into a principled material when the
+// scene is loaded. The material with the same name as this one is used unless material_name is set.
+type MaterialXReference struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	MaterialName  string                 `protobuf:"bytes,2,opt,name=material_name,json=materialName,proto3" json:"material_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaterialXReference) Reset() {
+	*x = MaterialXReference{}
+	mi := &file_transport_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaterialXReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaterialXReference) ProtoMessage() {}
+
+func (x *MaterialXReference) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaterialXReference.ProtoReflect.Descriptor instead.
+func (*MaterialXReference) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *MaterialXReference) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *MaterialXReference) GetMaterialName() string {
+	if x != nil {
+		return x.MaterialName
+	}
+	return ""
+}
+
+// Represents a participating medium, which fills the closed meshes and spheres that reference it.
+type Medium struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Absorption and scattering coefficients per unit of distance at unit density.
+	SigmaA *Vec3 `protobuf:"bytes,2,opt,name=sigma_a,json=sigmaA,proto3" json:"sigma_a,omitempty"`
+	SigmaS *Vec3 `protobuf:"bytes,3,opt,name=sigma_s,json=sigmaS,proto3" json:"sigma_s,omitempty"`
+	// Optional coefficients used by the spectral samplers instead of sigma_a and sigma_s.
+	SpectralSigmaA *SpectralConstantTexture `protobuf:"bytes,4,opt,name=spectral_sigma_a,json=spectralSigmaA,proto3" json:"spectral_sigma_a,omitempty"`
+	SpectralSigmaS *SpectralConstantTexture `protobuf:"bytes,5,opt,name=spectral_sigma_s,json=spectralSigmaS,proto3" json:"spectral_sigma_s,omitempty"`
+	// Asymmetry of the Henyey-Greenstein phase function, in (-1, 1).
+	G float32 `protobuf:"fixed32,6,opt,name=g,proto3" json:"g,omitempty"`
+	// The density is 1 everywhere if none is set.
+	//
+	// Types that are valid to be assigned to DensityProperties:
+	//
+	//	*Medium_Constant
+	//	*Medium_Grid
+	//	*Medium_Noise
+	//	*Medium_Vdb
+	DensityProperties isMedium_DensityProperties `protobuf_oneof:"density_properties"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Medium) Reset() {
+	*x = Medium{}
+	mi := &file_transport_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Medium) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Medium) ProtoMessage() {}
+
+func (x *Medium) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Medium.ProtoReflect.Descriptor instead.
+func (*Medium) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Medium) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Medium) GetSigmaA() *Vec3 {
+	if x != nil {
+		return x.SigmaA
+	}
+	return nil
+}
+
+func (x *Medium) GetSigmaS() *Vec3 {
+	if x != nil {
+		return x.SigmaS
+	}
+	return nil
+}
+
+func (x *Medium) GetSpectralSigmaA() *SpectralConstantTexture {
+	if x != nil {
+		return x.SpectralSigmaA
+	}
+	return nil
+}
+
+func (x *Medium) GetSpectralSigmaS() *SpectralConstantTexture {
+	if x != nil {
+		return x.SpectralSigmaS
+	}
+	return nil
+}
+
+func (x *Medium) GetG() float32 {
+	if x != nil {
+		return x.G
+	}
+	return 0
+}
+
+func (x *Medium) GetDensityProperties() isMedium_DensityProperties {
+	if x != nil {
+		return x.DensityProperties
+	}
+	return nil
+}
+
+func (x *Medium) GetConstant() *ConstantDensity {
+	if x != nil {
+		if x, ok := x.DensityProperties.(*Medium_Constant); ok {
+			return x.Constant
+		}
+	}
+	return nil
+}
+
+func (x *Medium) GetGrid() *GridDensity {
+	if x != nil {
+		if x, ok := x.DensityProperties.(*Medium_Grid); ok {
+			return x.Grid
+		}
+	}
+	return nil
+}
+
+func (x *Medium) GetNoise() *NoiseDensity {
+	if x != nil {
+		if x, ok := x.DensityProperties.(*Medium_Noise); ok {
+			return x.Noise
+		}
+	}
+	return nil
+}
+
+func (x *Medium) GetVdb() *VdbDensity {
+	if x != nil {
+		if x, ok := x.DensityProperties.(*Medium_Vdb); ok {
+			return x.Vdb
+		}
+	}
+	return nil
+}
+
+type isMedium_DensityProperties interface {
+	isMedium_DensityProperties()
+}
+
+type Medium_Constant struct {
+	Constant *ConstantDensity `protobuf:"bytes,7,opt,name=constant,proto3,oneof"`
+}
+
+type Medium_Grid struct {
+	Grid *GridDensity `protobuf:"bytes,8,opt,name=grid,proto3,oneof"`
+}
+
+type Medium_Noise struct {
+	Noise *NoiseDensity `protobuf:"bytes,9,opt,name=noise,proto3,oneof"`
+}
+
+type Medium_Vdb struct {
+	Vdb *VdbDensity `protobuf:"bytes,10,opt,name=vdb,proto3,oneof"`
+}
+
+func (*Medium_Constant) isMedium_DensityProperties() {}
+
+func (*Medium_Grid) isMedium_DensityProperties() {}
+
+func (*Medium_Noise) isMedium_DensityProperties() {}
+
+func (*Medium_Vdb) isMedium_DensityProperties() {}
+
+// Represents a density that is the same everywhere.
+type ConstantDensity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Density       float32                `protobuf:"fixed32,1,opt,name=density,proto3" json:"density,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConstantDensity) Reset() {
+	*x = ConstantDensity{}
+	mi := &file_transport_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConstantDensity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConstantDensity) ProtoMessage() {}
+
+func (x *ConstantDensity) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConstantDensity.ProtoReflect.Descriptor instead.
+func (*ConstantDensity) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ConstantDensity) GetDensity() float32 {
+	if x != nil {
+		return x.Density
+	}
+	return 0
+}
+
+// Represents a density sampled on a regular grid spanning an axis-aligned box and interpolated trilinearly.
+type GridDensity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           *Vec3                  `protobuf:"bytes,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           *Vec3                  `protobuf:"bytes,2,opt,name=max,proto3" json:"max,omitempty"`
+	Nx            uint32                 `protobuf:"varint,3,opt,name=nx,proto3" json:"nx,omitempty"`
+	Ny            uint32                 `protobuf:"varint,4,opt,name=ny,proto3" json:"ny,omitempty"`
+	Nz            uint32                 `protobuf:"varint,5,opt,name=nz,proto3" json:"nz,omitempty"`
+	Values        []float32              `protobuf:"fixed32,6,rep,packed,name=values,proto3" json:"values,omitempty"` // nx*ny*nz values, x varying fastest
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GridDensity) Reset() {
+	*x = GridDensity{}
+	mi := &file_transport_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GridDensity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GridDensity) ProtoMessage() {}
+
+func (x *GridDensity) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GridDensity.ProtoReflect.Descriptor instead.
+func (*GridDensity) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GridDensity) GetMin() *Vec3 {
+	if x != nil {
+		return x.Min
+	}
+	return nil
+}
+
+func (x *GridDensity) GetMax() *Vec3 {
+	if x != nil {
+		return x.Max
+	}
+	return nil
+}
+
+func (x *GridDensity) GetNx() uint32 {
+	if x != nil {
+		return x.Nx
+	}
+	return 0
+}
+
+func (x *GridDensity) GetNy() uint32 {
+	if x != nil {
+		return x.Ny
+	}
+	return 0
 }
 
-func (*IsotropicMaterial_Albedo) isIsotropicMaterial_AlbedoProperties() {}
+func (x *GridDensity) GetNz() uint32 {
+	if x != nil {
+		return x.Nz
+	}
+	return 0
+}
 
-func (*IsotropicMaterial_SpectralAlbedo) isIsotropicMaterial_AlbedoProperties() {}
+func (x *GridDensity) GetValues() []float32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
 
-// Represents a Metal material.
-type MetalMaterial struct {
+// Represents a procedural density made of Perlin turbulence.
+type NoiseDensity struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Albedo        *Vec3                  `protobuf:"bytes,1,opt,name=albedo,proto3" json:"albedo,omitempty"`
-	Fuzz          float32                `protobuf:"fixed32,2,opt,name=fuzz,proto3" json:"fuzz,omitempty"`
+	Density       float32                `protobuf:"fixed32,1,opt,name=density,proto3" json:"density,omitempty"`
+	Frequency     float32                `protobuf:"fixed32,2,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	Octaves       uint32                 `protobuf:"varint,3,opt,name=octaves,proto3" json:"octaves,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MetalMaterial) Reset() {
-	*x = MetalMaterial{}
-	mi := &file_transport_proto_msgTypes[21]
+func (x *NoiseDensity) Reset() {
+	*x = NoiseDensity{}
+	mi := &file_transport_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MetalMaterial) String() string {
+func (x *NoiseDensity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MetalMaterial) ProtoMessage() {}
+func (*NoiseDensity) ProtoMessage() {}
 
-func (x *MetalMaterial) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[21]
+func (x *NoiseDensity) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1957,53 +3012,62 @@ func (x *MetalMaterial) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MetalMaterial.ProtoReflect.Descriptor instead.
-func (*MetalMaterial) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use NoiseDensity.ProtoReflect.Descriptor instead.
+func (*NoiseDensity) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *MetalMaterial) GetAlbedo() *Vec3 {
+func (x *NoiseDensity) GetDensity() float32 {
 	if x != nil {
-		return x.Albedo
+		return x.Density
 	}
-	return nil
+	return 0
 }
 
-func (x *MetalMaterial) GetFuzz() float32 {
+func (x *NoiseDensity) GetFrequency() float32 {
 	if x != nil {
-		return x.Fuzz
+		return x.Frequency
 	}
 	return 0
 }
 
-// Represents a Physically Based Rendering (PBR) material.
-type PBRMaterial struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Albedo        *Texture               `protobuf:"bytes,1,opt,name=albedo,proto3" json:"albedo,omitempty"`
-	Roughness     *Texture               `protobuf:"bytes,2,opt,name=roughness,proto3" json:"roughness,omitempty"`
-	Metalness     *Texture               `protobuf:"bytes,3,opt,name=metalness,proto3" json:"metalness,omitempty"`
-	NormalMap     *Texture               `protobuf:"bytes,4,opt,name=normal_map,json=normalMap,proto3" json:"normal_map,omitempty"`
-	Sss           *Texture               `protobuf:"bytes,5,opt,name=sss,proto3" json:"sss,omitempty"`
-	SssRadius     float32                `protobuf:"fixed32,6,opt,name=sss_radius,json=sssRadius,proto3" json:"sss_radius,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *NoiseDensity) GetOctaves() uint32 {
+	if x != nil {
+		return x.Octaves
+	}
+	return 0
 }
 
-func (x *PBRMaterial) Reset() {
-	*x = PBRMaterial{}
-	mi := &file_transport_proto_msgTypes[22]
+// Represents a density read from a float grid of a NanoVDB file listed in the volume grids of the scene, optionally
+// with a temperature grid that makes the medium glow like a blackbody.
+type VdbDensity struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Filename         string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	DensityGrid      string                 `protobuf:"bytes,2,opt,name=density_grid,json=densityGrid,proto3" json:"density_grid,omitempty"`                  // Name of the density grid, or empty for the first grid of the file
+	DensityScale     float32                `protobuf:"fixed32,3,opt,name=density_scale,json=densityScale,proto3" json:"density_scale,omitempty"`             // Multiplies the density, 1 if unset
+	TemperatureGrid  string                 `protobuf:"bytes,4,opt,name=temperature_grid,json=temperatureGrid,proto3" json:"temperature_grid,omitempty"`      // Name of the temperature grid, or empty for none
+	TemperatureScale float32                `protobuf:"fixed32,5,opt,name=temperature_scale,json=temperatureScale,proto3" json:"temperature_scale,omitempty"` // Kelvin per unit of the temperature grid, 1 if unset
+	EmissionScale    float32                `protobuf:"fixed32,6,opt,name=emission_scale,json=emissionScale,proto3" json:"emission_scale,omitempty"`          // Multiplies the blackbody radiance in W/(sr·m²·nm)
+	EncloseBounds    bool                   `protobuf:"varint,7,opt,name=enclose_bounds,json=encloseBounds,proto3" json:"enclose_bounds,omitempty"`           // Adds a box around the density grid that references the medium
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *VdbDensity) Reset() {
+	*x = VdbDensity{}
+	mi := &file_transport_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PBRMaterial) String() string {
+func (x *VdbDensity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PBRMaterial) ProtoMessage() {}
+func (*VdbDensity) ProtoMessage() {}
 
-func (x *PBRMaterial) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[22]
+func (x *VdbDensity) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2014,53 +3078,60 @@ func (x *PBRMaterial) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PBRMaterial.ProtoReflect.Descriptor instead.
-func (*PBRMaterial) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use VdbDensity.ProtoReflect.Descriptor instead.
+func (*VdbDensity) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *PBRMaterial) GetAlbedo() *Texture {
+func (x *VdbDensity) GetFilename() string {
 	if x != nil {
-		return x.Albedo
+		return x.Filename
 	}
-	return nil
+	return ""
 }
 
-func (x *PBRMaterial) GetRoughness() *Texture {
+func (x *VdbDensity) GetDensityGrid() string {
 	if x != nil {
-		return x.Roughness
+		return x.DensityGrid
 	}
-	return nil
+	return ""
 }
 
-func (x *PBRMaterial) GetMetalness() *Texture {
+func (x *VdbDensity) GetDensityScale() float32 {
 	if x != nil {
-		return x.Metalness
+		return x.DensityScale
 	}
-	return nil
+	return 0
 }
 
-func (x *PBRMaterial) GetNormalMap() *Texture {
+func (x *VdbDensity) GetTemperatureGrid() string {
 	if x != nil {
-		return x.NormalMap
+		return x.TemperatureGrid
 	}
-	return nil
+	return ""
 }
 
-func (x *PBRMaterial) GetSss() *Texture {
+func (x *VdbDensity) GetTemperatureScale() float32 {
 	if x != nil {
-		return x.Sss
+		return x.TemperatureScale
 	}
-	return nil
+	return 0
 }
 
-func (x *PBRMaterial) GetSssRadius() float32 {
+func (x *VdbDensity) GetEmissionScale() float32 {
 	if x != nil {
-		return x.SssRadius
+		return x.EmissionScale
 	}
 	return 0
 }
 
+func (x *VdbDensity) GetEncloseBounds() bool {
+	if x != nil {
+		return x.EncloseBounds
+	}
+	return false
+}
+
 // Represents a triangle object with per-vertex data.
 type Triangle struct {
 	state   protoimpl.MessageState `protogen:"open.v1"`
@@ -2081,13 +3152,15 @@ type Triangle struct {
 	//
 	//	*Triangle_Displace
 	OperatorProperties isTriangle_OperatorProperties `protobuf_oneof:"operator_properties"`
+	ObjectName         string                        `protobuf:"bytes,13,opt,name=object_name,json=objectName,proto3" json:"object_name,omitempty"` // Name of the object the triangle belongs to
+	MediumName         string                        `protobuf:"bytes,14,opt,name=medium_name,json=mediumName,proto3" json:"medium_name,omitempty"` // Medium enclosed by the mesh, which makes the triangle an invisible boundary
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
 
 func (x *Triangle) Reset() {
 	*x = Triangle{}
-	mi := &file_transport_proto_msgTypes[23]
+	mi := &file_transport_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2099,7 +3172,7 @@ func (x *Triangle) String() string {
 func (*Triangle) ProtoMessage() {}
 
 func (x *Triangle) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[23]
+	mi := &file_transport_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2112,7 +3185,7 @@ func (x *Triangle) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Triangle.ProtoReflect.Descriptor instead.
 func (*Triangle) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{23}
+	return file_transport_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *Triangle) GetVertex0() *Vec3 {
@@ -2208,6 +3281,20 @@ func (x *Triangle) GetDisplace() *DisplaceOperator {
 	return nil
 }
 
+func (x *Triangle) GetObjectName() string {
+	if x != nil {
+		return x.ObjectName
+	}
+	return ""
+}
+
+func (x *Triangle) GetMediumName() string {
+	if x != nil {
+		return x.MediumName
+	}
+	return ""
+}
+
 type isTriangle_OperatorProperties interface {
 	isTriangle_OperatorProperties()
 }
@@ -2224,13 +3311,15 @@ type Sphere struct {
 	Center        *Vec3                  `protobuf:"bytes,1,opt,name=center,proto3" json:"center,omitempty"`
 	Radius        float32                `protobuf:"fixed32,2,opt,name=radius,proto3" json:"radius,omitempty"`
 	MaterialName  string                 `protobuf:"bytes,3,opt,name=material_name,json=materialName,proto3" json:"material_name,omitempty"` // Reference material by name
+	ObjectName    string                 `protobuf:"bytes,4,opt,name=object_name,json=objectName,proto3" json:"object_name,omitempty"`
+	MediumName    string                 `protobuf:"bytes,5,opt,name=medium_name,json=mediumName,proto3" json:"medium_name,omitempty"` // Medium enclosed by the sphere, which makes it an invisible boundary
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Sphere) Reset() {
 	*x = Sphere{}
-	mi := &file_transport_proto_msgTypes[24]
+	mi := &file_transport_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2242,7 +3331,7 @@ func (x *Sphere) String() string {
 func (*Sphere) ProtoMessage() {}
 
 func (x *Sphere) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[24]
+	mi := &file_transport_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2255,7 +3344,7 @@ func (x *Sphere) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Sphere.ProtoReflect.Descriptor instead.
 func (*Sphere) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{24}
+	return file_transport_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *Sphere) GetCenter() *Vec3 {
@@ -2279,6 +3368,20 @@ func (x *Sphere) GetMaterialName() string {
 	return ""
 }
 
+func (x *Sphere) GetObjectName() string {
+	if x != nil {
+		return x.ObjectName
+	}
+	return ""
+}
+
+func (x *Sphere) GetMediumName() string {
+	if x != nil {
+		return x.MediumName
+	}
+	return ""
+}
+
 // Contains all the objects in the scene.
 type SceneObjects struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -2290,7 +3393,7 @@ type SceneObjects struct {
 
 func (x *SceneObjects) Reset() {
 	*x = SceneObjects{}
-	mi := &file_transport_proto_msgTypes[25]
+	mi := &file_transport_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2302,7 +3405,7 @@ func (x *SceneObjects) String() string {
 func (*SceneObjects) ProtoMessage() {}
 
 func (x *SceneObjects) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[25]
+	mi := &file_transport_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2315,7 +3418,7 @@ func (x *SceneObjects) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SceneObjects.ProtoReflect.Descriptor instead.
 func (*SceneObjects) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{25}
+	return file_transport_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *SceneObjects) GetTriangles() []*Triangle {
@@ -2345,13 +3448,15 @@ type Scene struct {
 	StreamTriangles      bool                             `protobuf:"varint,9,opt,name=stream_triangles,json=streamTriangles,proto3" json:"stream_triangles,omitempty"`
 	TotalTriangles       uint64                           `protobuf:"varint,10,opt,name=total_triangles,json=totalTriangles,proto3" json:"total_triangles,omitempty"`
 	SpectralBackground   *TabulatedSpectralConstant       `protobuf:"bytes,11,opt,name=spectral_background,json=spectralBackground,proto3" json:"spectral_background,omitempty"`
+	Media                map[string]*Medium               `protobuf:"bytes,12,rep,name=media,proto3" json:"media,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	VolumeGrids          map[string]*VolumeGridMetadata   `protobuf:"bytes,13,rep,name=volume_grids,json=volumeGrids,proto3" json:"volume_grids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields        protoimpl.UnknownFields
 	sizeCache            protoimpl.SizeCache
 }
 
 func (x *Scene) Reset() {
 	*x = Scene{}
-	mi := &file_transport_proto_msgTypes[26]
+	mi := &file_transport_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2363,7 +3468,7 @@ func (x *Scene) String() string {
 func (*Scene) ProtoMessage() {}
 
 func (x *Scene) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[26]
+	mi := &file_transport_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2376,7 +3481,7 @@ func (x *Scene) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Scene.ProtoReflect.Descriptor instead.
 func (*Scene) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{26}
+	return file_transport_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *Scene) GetName() string {
@@ -2456,6 +3561,20 @@ func (x *Scene) GetSpectralBackground() *TabulatedSpectralConstant {
 	return nil
 }
 
+func (x *Scene) GetMedia() map[string]*Medium {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+func (x *Scene) GetVolumeGrids() map[string]*VolumeGridMetadata {
+	if x != nil {
+		return x.VolumeGrids
+	}
+	return nil
+}
+
 type GetSceneRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SceneName     string                 `protobuf:"bytes,1,opt,name=scene_name,json=sceneName,proto3" json:"scene_name,omitempty"`
@@ -2465,7 +3584,7 @@ type GetSceneRequest struct {
 
 func (x *GetSceneRequest) Reset() {
 	*x = GetSceneRequest{}
-	mi := &file_transport_proto_msgTypes[27]
+	mi := &file_transport_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2477,7 +3596,7 @@ func (x *GetSceneRequest) String() string {
 func (*GetSceneRequest) ProtoMessage() {}
 
 func (x *GetSceneRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[27]
+	mi := &file_transport_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2490,7 +3609,7 @@ func (x *GetSceneRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetSceneRequest.ProtoReflect.Descriptor instead.
 func (*GetSceneRequest) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{27}
+	return file_transport_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *GetSceneRequest) GetSceneName() string {
@@ -2511,7 +3630,7 @@ type StreamTextureFileRequest struct {
 
 func (x *StreamTextureFileRequest) Reset() {
 	*x = StreamTextureFileRequest{}
-	mi := &file_transport_proto_msgTypes[28]
+	mi := &file_transport_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2523,7 +3642,7 @@ func (x *StreamTextureFileRequest) String() string {
 func (*StreamTextureFileRequest) ProtoMessage() {}
 
 func (x *StreamTextureFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[28]
+	mi := &file_transport_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2536,7 +3655,7 @@ func (x *StreamTextureFileRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamTextureFileRequest.ProtoReflect.Descriptor instead.
 func (*StreamTextureFileRequest) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{28}
+	return file_transport_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *StreamTextureFileRequest) GetFilename() string {
@@ -2570,7 +3689,7 @@ type StreamTextureFileResponse struct {
 
 func (x *StreamTextureFileResponse) Reset() {
 	*x = StreamTextureFileResponse{}
-	mi := &file_transport_proto_msgTypes[29]
+	mi := &file_transport_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2582,7 +3701,7 @@ func (x *StreamTextureFileResponse) String() string {
 func (*StreamTextureFileResponse) ProtoMessage() {}
 
 func (x *StreamTextureFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[29]
+	mi := &file_transport_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2595,7 +3714,7 @@ func (x *StreamTextureFileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamTextureFileResponse.ProtoReflect.Descriptor instead.
 func (*StreamTextureFileResponse) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{29}
+	return file_transport_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *StreamTextureFileResponse) GetChunk() []byte {
@@ -2612,6 +3731,118 @@ func (x *StreamTextureFileResponse) GetSize() uint64 {
 	return 0
 }
 
+type StreamVolumeFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	ChunkSize     uint32                 `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamVolumeFileRequest) Reset() {
+	*x = StreamVolumeFileRequest{}
+	mi := &file_transport_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamVolumeFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamVolumeFileRequest) ProtoMessage() {}
+
+func (x *StreamVolumeFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamVolumeFileRequest.ProtoReflect.Descriptor instead.
+func (*StreamVolumeFileRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *StreamVolumeFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *StreamVolumeFileRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *StreamVolumeFileRequest) GetChunkSize() uint32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+type StreamVolumeFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamVolumeFileResponse) Reset() {
+	*x = StreamVolumeFileResponse{}
+	mi := &file_transport_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamVolumeFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamVolumeFileResponse) ProtoMessage() {}
+
+func (x *StreamVolumeFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamVolumeFileResponse.ProtoReflect.Descriptor instead.
+func (*StreamVolumeFileResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *StreamVolumeFileResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *StreamVolumeFileResponse) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 type StreamTrianglesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SceneName     string                 `protobuf:"bytes,1,opt,name=scene_name,json=sceneName,proto3" json:"scene_name,omitempty"`
@@ -2623,7 +3854,7 @@ type StreamTrianglesRequest struct {
 
 func (x *StreamTrianglesRequest) Reset() {
 	*x = StreamTrianglesRequest{}
-	mi := &file_transport_proto_msgTypes[30]
+	mi := &file_transport_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2635,7 +3866,7 @@ func (x *StreamTrianglesRequest) String() string {
 func (*StreamTrianglesRequest) ProtoMessage() {}
 
 func (x *StreamTrianglesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[30]
+	mi := &file_transport_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2648,7 +3879,7 @@ func (x *StreamTrianglesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamTrianglesRequest.ProtoReflect.Descriptor instead.
 func (*StreamTrianglesRequest) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{30}
+	return file_transport_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *StreamTrianglesRequest) GetSceneName() string {
@@ -2682,7 +3913,7 @@ type StreamTrianglesResponse struct {
 
 func (x *StreamTrianglesResponse) Reset() {
 	*x = StreamTrianglesResponse{}
-	mi := &file_transport_proto_msgTypes[31]
+	mi := &file_transport_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2694,7 +3925,7 @@ func (x *StreamTrianglesResponse) String() string {
 func (*StreamTrianglesResponse) ProtoMessage() {}
 
 func (x *StreamTrianglesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_transport_proto_msgTypes[31]
+	mi := &file_transport_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2707,7 +3938,7 @@ func (x *StreamTrianglesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamTrianglesResponse.ProtoReflect.Descriptor instead.
 func (*StreamTrianglesResponse) Descriptor() ([]byte, []int) {
-	return file_transport_proto_rawDescGZIP(), []int{31}
+	return file_transport_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *StreamTrianglesResponse) GetTriangles() []*Triangle {
@@ -2734,7 +3965,10 @@ const file_transport_proto_rawDesc = "" +
 	"\x05width\x18\x02 \x01(\rR\x05width\x12\x16\n" +
 	"\x06height\x18\x03 \x01(\rR\x06height\x12\x1a\n" +
 	"\bchannels\x18\x04 \x01(\rR\bchannels\x12@\n" +
-	"\fpixel_format\x18\x05 \x01(\x0e2\x1d.transport.TexturePixelFormatR\vpixelFormat\"a\n" +
+	"\fpixel_format\x18\x05 \x01(\x0e2\x1d.transport.TexturePixelFormatR\vpixelFormat\"D\n" +
+	"\x12VolumeGridMetadata\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\"a\n" +
 	"\x10DisplaceOperator\x12\x10\n" +
 	"\x03min\x18\x01 \x01(\x01R\x03min\x12\x10\n" +
 	"\x03max\x18\x02 \x01(\x01R\x03max\x12)\n" +
@@ -2757,7 +3991,7 @@ const file_transport_proto_rawDesc = "" +
 	"\x05time0\x18\b \x01(\x02R\x05time0\x12\x14\n" +
 	"\x05time1\x18\t \x01(\x02R\x05time1\x12\x1a\n" +
 	"\bexposure\x18\n" +
-	" \x01(\x02R\bexposure\"\xd5\x03\n" +
+	" \x01(\x02R\bexposure\"\xba\x04\n" +
 	"\aTexture\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12*\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x16.transport.TextureTypeR\x04type\x128\n" +
@@ -2766,13 +4000,24 @@ const file_transport_proto_rawDesc = "" +
 	"\x05image\x18\x05 \x01(\v2\x17.transport.ImageTextureH\x00R\x05image\x12/\n" +
 	"\x05noise\x18\x06 \x01(\v2\x17.transport.NoiseTextureH\x00R\x05noise\x12Q\n" +
 	"\x11spectral_constant\x18\a \x01(\v2\".transport.SpectralConstantTextureH\x00R\x10spectralConstant\x12N\n" +
-	"\x10spectral_checker\x18\b \x01(\v2!.transport.SpectralCheckerTextureH\x00R\x0fspectralCheckerB\x14\n" +
+	"\x10spectral_checker\x18\b \x01(\v2!.transport.SpectralCheckerTextureH\x00R\x0fspectralChecker\x128\n" +
+	"\bmultiply\x18\t \x01(\v2\x1a.transport.MultiplyTextureH\x00R\bmultiply\x12)\n" +
+	"\x03mix\x18\n" +
+	" \x01(\v2\x15.transport.MixTextureH\x00R\x03mixB\x14\n" +
 	"\x12texture_properties\"8\n" +
 	"\x0fConstantTexture\x12%\n" +
 	"\x05value\x18\x01 \x01(\v2\x0f.transport.Vec3R\x05value\"^\n" +
 	"\x0eCheckerTexture\x12$\n" +
 	"\x03odd\x18\x01 \x01(\v2\x12.transport.TextureR\x03odd\x12&\n" +
-	"\x04even\x18\x02 \x01(\v2\x12.transport.TextureR\x04even\"*\n" +
+	"\x04even\x18\x02 \x01(\v2\x12.transport.TextureR\x04even\"U\n" +
+	"\x0fMultiplyTexture\x12 \n" +
+	"\x01a\x18\x01 \x01(\v2\x12.transport.TextureR\x01a\x12 \n" +
+	"\x01b\x18\x02 \x01(\v2\x12.transport.TextureR\x01b\"z\n" +
+	"\n" +
+	"MixTexture\x12\"\n" +
+	"\x02fg\x18\x01 \x01(\v2\x12.transport.TextureR\x02fg\x12\"\n" +
+	"\x02bg\x18\x02 \x01(\v2\x12.transport.TextureR\x02bg\x12$\n" +
+	"\x03mix\x18\x03 \x01(\v2\x12.transport.TextureR\x03mix\"*\n" +
 	"\fImageTexture\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\"$\n" +
 	"\fNoiseTexture\x12\x14\n" +
@@ -2797,7 +4042,7 @@ const file_transport_proto_rawDesc = "" +
 	"\x11light_source_name\x18\x01 \x01(\tR\x0flightSourceName\"\x86\x01\n" +
 	"\x16SpectralCheckerTexture\x124\n" +
 	"\x03odd\x18\x01 \x01(\v2\".transport.SpectralConstantTextureR\x03odd\x126\n" +
-	"\x04even\x18\x02 \x01(\v2\".transport.SpectralConstantTextureR\x04even\"\xbe\x03\n" +
+	"\x04even\x18\x02 \x01(\v2\".transport.SpectralConstantTextureR\x04even\"\xfc\x04\n" +
 	"\bMaterial\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x17.transport.MaterialTypeR\x04type\x12?\n" +
@@ -2808,18 +4053,26 @@ const file_transport_proto_rawDesc = "" +
 	"\tisotropic\x18\x05 \x01(\v2\x1c.transport.IsotropicMaterialH\x00R\tisotropic\x126\n" +
 	"\alambert\x18\x06 \x01(\v2\x1a.transport.LambertMaterialH\x00R\alambert\x120\n" +
 	"\x05metal\x18\a \x01(\v2\x18.transport.MetalMaterialH\x00R\x05metal\x12*\n" +
-	"\x03pbr\x18\b \x01(\v2\x16.transport.PBRMaterialH\x00R\x03pbrB\x15\n" +
+	"\x03pbr\x18\b \x01(\v2\x16.transport.PBRMaterialH\x00R\x03pbr\x12?\n" +
+	"\n" +
+	"principled\x18\t \x01(\v2\x1d.transport.PrincipledMaterialH\x00R\n" +
+	"principled\x12=\n" +
+	"\tmaterialx\x18\n" +
+	" \x01(\v2\x1d.transport.MaterialXReferenceH\x00R\tmaterialx\x12<\n" +
+	"\tconductor\x18\v \x01(\v2\x1c.transport.ConductorMaterialH\x00R\tconductorB\x15\n" +
 	"\x13material_properties\"\xa3\x01\n" +
 	"\x0fLambertMaterial\x12,\n" +
 	"\x06albedo\x18\x01 \x01(\v2\x12.transport.TextureH\x00R\x06albedo\x12M\n" +
 	"\x0fspectral_albedo\x18\x02 \x01(\v2\".transport.SpectralConstantTextureH\x00R\x0espectralAlbedoB\x13\n" +
-	"\x11albedo_properties\"\x9e\x03\n" +
+	"\x11albedo_properties\"\xec\x03\n" +
 	"\x12DielectricMaterial\x12\x18\n" +
 	"\x06refidx\x18\x01 \x01(\x02H\x00R\x06refidx\x12M\n" +
 	"\x0fspectral_refidx\x18\x02 \x01(\v2\".transport.SpectralConstantTextureH\x00R\x0espectralRefidx\x12G\n" +
 	" compute_beer_lambert_attenuation\x18\x03 \x01(\bR\x1dcomputeBeerLambertAttenuation\x12<\n" +
 	"\x10absorption_coeff\x18\x04 \x01(\v2\x0f.transport.Vec3H\x01R\x0fabsorptionCoeff\x12`\n" +
-	"\x19spectral_absorption_coeff\x18\x05 \x01(\v2\".transport.SpectralConstantTextureH\x01R\x17spectralAbsorptionCoeffB\x1d\n" +
+	"\x19spectral_absorption_coeff\x18\x05 \x01(\v2\".transport.SpectralConstantTextureH\x01R\x17spectralAbsorptionCoeff\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\x120\n" +
+	"\tthin_film\x18\a \x01(\v2\x13.transport.ThinFilmR\bthinFilmB\x1d\n" +
 	"\x1brefractive_index_propertiesB\x17\n" +
 	"\x15absorption_properties\"\xa2\x01\n" +
 	"\x14DiffuseLightMaterial\x12(\n" +
@@ -2841,7 +4094,75 @@ const file_transport_proto_rawDesc = "" +
 	"normal_map\x18\x04 \x01(\v2\x12.transport.TextureR\tnormalMap\x12$\n" +
 	"\x03sss\x18\x05 \x01(\v2\x12.transport.TextureR\x03sss\x12\x1d\n" +
 	"\n" +
-	"sss_radius\x18\x06 \x01(\x02R\tsssRadius\"\xa5\x04\n" +
+	"sss_radius\x18\x06 \x01(\x02R\tsssRadius\"\x91\x04\n" +
+	"\x12PrincipledMaterial\x121\n" +
+	"\n" +
+	"base_color\x18\x01 \x01(\v2\x12.transport.TextureR\tbaseColor\x12.\n" +
+	"\bmetallic\x18\x02 \x01(\v2\x12.transport.TextureR\bmetallic\x120\n" +
+	"\troughness\x18\x03 \x01(\v2\x12.transport.TextureR\troughness\x121\n" +
+	"\n" +
+	"normal_map\x18\x04 \x01(\v2\x12.transport.TextureR\tnormalMap\x12\x1a\n" +
+	"\bspecular\x18\x05 \x01(\x02R\bspecular\x12#\n" +
+	"\rspecular_tint\x18\x06 \x01(\x02R\fspecularTint\x12 \n" +
+	"\vanisotropic\x18\a \x01(\x02R\vanisotropic\x12\x14\n" +
+	"\x05sheen\x18\b \x01(\x02R\x05sheen\x12\x1d\n" +
+	"\n" +
+	"sheen_tint\x18\t \x01(\x02R\tsheenTint\x12\x1c\n" +
+	"\tclearcoat\x18\n" +
+	" \x01(\x02R\tclearcoat\x12'\n" +
+	"\x0fclearcoat_gloss\x18\v \x01(\x02R\x0eclearcoatGloss\x12\"\n" +
+	"\ftransmission\x18\f \x01(\x02R\ftransmission\x12\x1e\n" +
+	"\n" +
+	"subsurface\x18\r \x01(\x02R\n" +
+	"subsurface\x12\x10\n" +
+	"\x03ior\x18\x0e \x01(\x02R\x03ior\"\xe5\x01\n" +
+	"\x11ConductorMaterial\x126\n" +
+	"\x03eta\x18\x01 \x01(\v2$.transport.TabulatedSpectralConstantR\x03eta\x122\n" +
+	"\x01k\x18\x02 \x01(\v2$.transport.TabulatedSpectralConstantR\x01k\x12\x1c\n" +
+	"\troughness\x18\x03 \x01(\x02R\troughness\x12\x14\n" +
+	"\x05metal\x18\x04 \x01(\tR\x05metal\x120\n" +
+	"\tthin_film\x18\x05 \x01(\v2\x13.transport.ThinFilmR\bthinFilm\"N\n" +
+	"\bThinFilm\x120\n" +
+	"\tthickness\x18\x01 \x01(\v2\x12.transport.TextureR\tthickness\x12\x10\n" +
+	"\x03ior\x18\x02 \x01(\x02R\x03ior\"U\n" +
+	"\x12MaterialXReference\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12#\n" +
+	"\rmaterial_name\x18\x02 \x01(\tR\fmaterialName\"\xf4\x03\n" +
+	"\x06Medium\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12(\n" +
+	"\asigma_a\x18\x02 \x01(\v2\x0f.transport.Vec3R\x06sigmaA\x12(\n" +
+	"\asigma_s\x18\x03 \x01(\v2\x0f.transport.Vec3R\x06sigmaS\x12L\n" +
+	"\x10spectral_sigma_a\x18\x04 \x01(\v2\".transport.SpectralConstantTextureR\x0espectralSigmaA\x12L\n" +
+	"\x10spectral_sigma_s\x18\x05 \x01(\v2\".transport.SpectralConstantTextureR\x0espectralSigmaS\x12\f\n" +
+	"\x01g\x18\x06 \x01(\x02R\x01g\x128\n" +
+	"\bconstant\x18\a \x01(\v2\x1a.transport.ConstantDensityH\x00R\bconstant\x12,\n" +
+	"\x04grid\x18\b \x01(\v2\x16.transport.GridDensityH\x00R\x04grid\x12/\n" +
+	"\x05noise\x18\t \x01(\v2\x17.transport.NoiseDensityH\x00R\x05noise\x12)\n" +
+	"\x03vdb\x18\n" +
+	" \x01(\v2\x15.transport.VdbDensityH\x00R\x03vdbB\x14\n" +
+	"\x12density_properties\"+\n" +
+	"\x0fConstantDensity\x12\x18\n" +
+	"\adensity\x18\x01 \x01(\x02R\adensity\"\x9b\x01\n" +
+	"\vGridDensity\x12!\n" +
+	"\x03min\x18\x01 \x01(\v2\x0f.transport.Vec3R\x03min\x12!\n" +
+	"\x03max\x18\x02 \x01(\v2\x0f.transport.Vec3R\x03max\x12\x0e\n" +
+	"\x02nx\x18\x03 \x01(\rR\x02nx\x12\x0e\n" +
+	"\x02ny\x18\x04 \x01(\rR\x02ny\x12\x0e\n" +
+	"\x02nz\x18\x05 \x01(\rR\x02nz\x12\x16\n" +
+	"\x06values\x18\x06 \x03(\x02R\x06values\"`\n" +
+	"\fNoiseDensity\x12\x18\n" +
+	"\adensity\x18\x01 \x01(\x02R\adensity\x12\x1c\n" +
+	"\tfrequency\x18\x02 \x01(\x02R\tfrequency\x12\x18\n" +
+	"\aoctaves\x18\x03 \x01(\rR\aoctaves\"\x96\x02\n" +
+	"\n" +
+	"VdbDensity\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fdensity_grid\x18\x02 \x01(\tR\vdensityGrid\x12#\n" +
+	"\rdensity_scale\x18\x03 \x01(\x02R\fdensityScale\x12)\n" +
+	"\x10temperature_grid\x18\x04 \x01(\tR\x0ftemperatureGrid\x12+\n" +
+	"\x11temperature_scale\x18\x05 \x01(\x02R\x10temperatureScale\x12%\n" +
+	"\x0eemission_scale\x18\x06 \x01(\x02R\remissionScale\x12%\n" +
+	"\x0eenclose_bounds\x18\a \x01(\bR\rencloseBounds\"\xe7\x04\n" +
 	"\bTriangle\x12)\n" +
 	"\avertex0\x18\x01 \x01(\v2\x0f.transport.Vec3R\avertex0\x12)\n" +
 	"\avertex1\x18\x02 \x01(\v2\x0f.transport.Vec3R\avertex1\x12)\n" +
@@ -2855,15 +4176,23 @@ const file_transport_proto_rawDesc = "" +
 	"\rmaterial_name\x18\n" +
 	" \x01(\tR\fmaterialName\x127\n" +
 	"\boperator\x18\v \x01(\x0e2\x1b.transport.GeometryOperatorR\boperator\x129\n" +
-	"\bdisplace\x18\f \x01(\v2\x1b.transport.DisplaceOperatorH\x00R\bdisplaceB\x15\n" +
-	"\x13operator_properties\"n\n" +
+	"\bdisplace\x18\f \x01(\v2\x1b.transport.DisplaceOperatorH\x00R\bdisplace\x12\x1f\n" +
+	"\vobject_name\x18\r \x01(\tR\n" +
+	"objectName\x12\x1f\n" +
+	"\vmedium_name\x18\x0e \x01(\tR\n" +
+	"mediumNameB\x15\n" +
+	"\x13operator_properties\"\xb0\x01\n" +
 	"\x06Sphere\x12'\n" +
 	"\x06center\x18\x01 \x01(\v2\x0f.transport.Vec3R\x06center\x12\x16\n" +
 	"\x06radius\x18\x02 \x01(\x02R\x06radius\x12#\n" +
-	"\rmaterial_name\x18\x03 \x01(\tR\fmaterialName\"n\n" +
+	"\rmaterial_name\x18\x03 \x01(\tR\fmaterialName\x12\x1f\n" +
+	"\vobject_name\x18\x04 \x01(\tR\n" +
+	"objectName\x12\x1f\n" +
+	"\vmedium_name\x18\x05 \x01(\tR\n" +
+	"mediumName\"n\n" +
 	"\fSceneObjects\x121\n" +
 	"\ttriangles\x18\x01 \x03(\v2\x13.transport.TriangleR\ttriangles\x12+\n" +
-	"\aspheres\x18\x02 \x03(\v2\x11.transport.SphereR\aspheres\"\x90\a\n" +
+	"\aspheres\x18\x02 \x03(\v2\x11.transport.SphereR\aspheres\"\xb5\t\n" +
 	"\x05Scene\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12T\n" +
@@ -2876,7 +4205,9 @@ const file_transport_proto_rawDesc = "" +
 	"\x10stream_triangles\x18\t \x01(\bR\x0fstreamTriangles\x12'\n" +
 	"\x0ftotal_triangles\x18\n" +
 	" \x01(\x04R\x0etotalTriangles\x12U\n" +
-	"\x13spectral_background\x18\v \x01(\v2$.transport.TabulatedSpectralConstantR\x12spectralBackground\x1aQ\n" +
+	"\x13spectral_background\x18\v \x01(\v2$.transport.TabulatedSpectralConstantR\x12spectralBackground\x121\n" +
+	"\x05media\x18\f \x03(\v2\x1b.transport.Scene.MediaEntryR\x05media\x12D\n" +
+	"\fvolume_grids\x18\r \x03(\v2!.transport.Scene.VolumeGridsEntryR\vvolumeGrids\x1aQ\n" +
 	"\x0eMaterialsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12)\n" +
 	"\x05value\x18\x02 \x01(\v2\x13.transport.MaterialR\x05value:\x028\x01\x1aa\n" +
@@ -2885,7 +4216,14 @@ const file_transport_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\v2\x1f.transport.ImageTextureMetadataR\x05value:\x028\x01\x1ad\n" +
 	"\x15DisplacementMapsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
-	"\x05value\x18\x02 \x01(\v2\x1f.transport.ImageTextureMetadataR\x05value:\x028\x01\"0\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.transport.ImageTextureMetadataR\x05value:\x028\x01\x1aK\n" +
+	"\n" +
+	"MediaEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\x05value\x18\x02 \x01(\v2\x11.transport.MediumR\x05value:\x028\x01\x1a]\n" +
+	"\x10VolumeGridsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x123\n" +
+	"\x05value\x18\x02 \x01(\v2\x1d.transport.VolumeGridMetadataR\x05value:\x028\x01\"0\n" +
 	"\x0fGetSceneRequest\x12\x1d\n" +
 	"\n" +
 	"scene_name\x18\x01 \x01(\tR\tsceneName\"m\n" +
@@ -2896,6 +4234,14 @@ const file_transport_proto_rawDesc = "" +
 	"chunk_size\x18\x03 \x01(\rR\tchunkSize\"E\n" +
 	"\x19StreamTextureFileResponse\x12\x14\n" +
 	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\"l\n" +
+	"\x17StreamVolumeFileRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x03 \x01(\rR\tchunkSize\"D\n" +
+	"\x18StreamVolumeFileResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x04R\x04size\"n\n" +
 	"\x16StreamTrianglesRequest\x12\x1d\n" +
 	"\n" +
@@ -2905,7 +4251,7 @@ const file_transport_proto_rawDesc = "" +
 	"\x06offset\x18\x03 \x01(\x04R\x06offset\"u\n" +
 	"\x17StreamTrianglesResponse\x121\n" +
 	"\ttriangles\x18\x01 \x03(\v2\x13.transport.TriangleR\ttriangles\x12'\n" +
-	"\x0ftotal_triangles\x18\x02 \x01(\x04R\x0etotalTriangles*\x89\x01\n" +
+	"\x0ftotal_triangles\x18\x02 \x01(\x04R\x0etotalTriangles*\xa0\x01\n" +
 	"\vTextureType\x12\x1c\n" +
 	"\x18TEXTURE_TYPE_UNSPECIFIED\x10\x00\x12\f\n" +
 	"\bCONSTANT\x10\x01\x12\v\n" +
@@ -2913,10 +4259,12 @@ const file_transport_proto_rawDesc = "" +
 	"\x05IMAGE\x10\x03\x12\t\n" +
 	"\x05NOISE\x10\x04\x12\x15\n" +
 	"\x11SPECTRAL_CONSTANT\x10\x05\x12\x14\n" +
-	"\x10SPECTRAL_CHECKER\x10\x06*G\n" +
+	"\x10SPECTRAL_CHECKER\x10\x06\x12\f\n" +
+	"\bMULTIPLY\x10\a\x12\a\n" +
+	"\x03MIX\x10\b*G\n" +
 	"\x12TexturePixelFormat\x12$\n" +
 	" TEXTURE_PIXEL_FORMAT_UNSPECIFIED\x10\x00\x12\v\n" +
-	"\aFLOAT64\x10\x01*\x80\x01\n" +
+	"\aFLOAT64\x10\x01*\xae\x01\n" +
 	"\fMaterialType\x12\x1d\n" +
 	"\x19MATERIAL_TYPE_UNSPECIFIED\x10\x00\x12\x0e\n" +
 	"\n" +
@@ -2925,17 +4273,22 @@ const file_transport_proto_rawDesc = "" +
 	"\tISOTROPIC\x10\x03\x12\v\n" +
 	"\aLAMBERT\x10\x04\x12\t\n" +
 	"\x05METAL\x10\x05\x12\a\n" +
-	"\x03PBR\x10\x06*T\n" +
+	"\x03PBR\x10\x06\x12\x0e\n" +
+	"\n" +
+	"PRINCIPLED\x10\a\x12\r\n" +
+	"\tMATERIALX\x10\b\x12\r\n" +
+	"\tCONDUCTOR\x10\t*T\n" +
 	"\x14ColourRepresentation\x12%\n" +
 	"!COLOUR_REPRESENTATION_UNSPECIFIED\x10\x00\x12\a\n" +
 	"\x03RGB\x10\x01\x12\f\n" +
 	"\bSPECTRAL\x10\x02*C\n" +
 	"\x10GeometryOperator\x12!\n" +
 	"\x1dGEOMETRY_OPERATOR_UNSPECIFIED\x10\x00\x12\f\n" +
-	"\bDISPLACE\x10\x012\x8f\x02\n" +
+	"\bDISPLACE\x10\x012\xee\x02\n" +
 	"\x15SceneTransportService\x128\n" +
 	"\bGetScene\x12\x1a.transport.GetSceneRequest\x1a\x10.transport.Scene\x12`\n" +
-	"\x11StreamTextureFile\x12#.transport.StreamTextureFileRequest\x1a$.transport.StreamTextureFileResponse0\x01\x12Z\n" +
+	"\x11StreamTextureFile\x12#.transport.StreamTextureFileRequest\x1a$.transport.StreamTextureFileResponse0\x01\x12]\n" +
+	"\x10StreamVolumeFile\x12\".transport.StreamVolumeFileRequest\x1a#.transport.StreamVolumeFileResponse0\x01\x12Z\n" +
 	"\x0fStreamTriangles\x12!.transport.StreamTrianglesRequest\x1a\".transport.StreamTrianglesResponse0\x01B>Z<github.com/flynn-nrg/izpi/internal/proto/transport;transportb\x06proto3"
 
 var (
@@ -2951,7 +4304,7 @@ func file_transport_proto_rawDescGZIP() []byte {
 }
 
 var file_transport_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_transport_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_transport_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_transport_proto_goTypes = []any{
 	(TextureType)(0),                  // 0: transport.TextureType
 	(TexturePixelFormat)(0),           // 1: transport.TexturePixelFormat
@@ -2959,120 +4312,171 @@ var file_transport_proto_goTypes = []any{
 	(ColourRepresentation)(0),         // 3: transport.ColourRepresentation
 	(GeometryOperator)(0),             // 4: transport.GeometryOperator
 	(*ImageTextureMetadata)(nil),      // 5: transport.ImageTextureMetadata
-	(*DisplaceOperator)(nil),          // 6: transport.DisplaceOperator
-	(*Vec3)(nil),                      // 7: transport.Vec3
-	(*Vec2)(nil),                      // 8: transport.Vec2
-	(*Camera)(nil),                    // 9: transport.Camera
-	(*Texture)(nil),                   // 10: transport.Texture
-	(*ConstantTexture)(nil),           // 11: transport.ConstantTexture
-	(*CheckerTexture)(nil),            // 12: transport.CheckerTexture
-	(*ImageTexture)(nil),              // 13: transport.ImageTexture
-	(*NoiseTexture)(nil),              // 14: transport.NoiseTexture
-	(*SpectralConstantTexture)(nil),   // 15: transport.SpectralConstantTexture
-	(*GaussianSpectralConstant)(nil),  // 16: transport.GaussianSpectralConstant
-	(*TabulatedSpectralConstant)(nil), // 17: transport.TabulatedSpectralConstant
-	(*NeutralSpectralConstant)(nil),   // 18: transport.NeutralSpectralConstant
-	(*FromLightSourceLibrary)(nil),    // 19: transport.FromLightSourceLibrary
-	(*SpectralCheckerTexture)(nil),    // 20: transport.SpectralCheckerTexture
-	(*Material)(nil),                  // 21: transport.Material
-	(*LambertMaterial)(nil),           // 22: transport.LambertMaterial
-	(*DielectricMaterial)(nil),        // 23: transport.DielectricMaterial
-	(*DiffuseLightMaterial)(nil),      // 24: transport.DiffuseLightMaterial
-	(*IsotropicMaterial)(nil),         // 25: transport.IsotropicMaterial
-	(*MetalMaterial)(nil),             // 26: transport.MetalMaterial
-	(*PBRMaterial)(nil),               // 27: transport.PBRMaterial
-	(*Triangle)(nil),                  // 28: transport.Triangle
-	(*Sphere)(nil),                    // 29: transport.Sphere
-	(*SceneObjects)(nil),              // 30: transport.SceneObjects
-	(*Scene)(nil),                     // 31: transport.Scene
-	(*GetSceneRequest)(nil),           // 32: transport.GetSceneRequest
-	(*StreamTextureFileRequest)(nil),  // 33: transport.StreamTextureFileRequest
-	(*StreamTextureFileResponse)(nil), // 34: transport.StreamTextureFileResponse
-	(*StreamTrianglesRequest)(nil),    // 35: transport.StreamTrianglesRequest
-	(*StreamTrianglesResponse)(nil),   // 36: transport.StreamTrianglesResponse
-	nil,                               // 37: transport.Scene.MaterialsEntry
-	nil,                               // 38: transport.Scene.ImageTexturesEntry
-	nil,                               // 39: transport.Scene.DisplacementMapsEntry
+	(*VolumeGridMetadata)(nil),        // 6: transport.VolumeGridMetadata
+	(*DisplaceOperator)(nil),          // 7: transport.DisplaceOperator
+	(*Vec3)(nil),                      // 8: transport.Vec3
+	(*Vec2)(nil),                      // 9: transport.Vec2
+	(*Camera)(nil),                    // 10: transport.Camera
+	(*Texture)(nil),                   // 11: transport.Texture
+	(*ConstantTexture)(nil),           // 12: transport.ConstantTexture
+	(*CheckerTexture)(nil),            // 13: transport.CheckerTexture
+	(*MultiplyTexture)(nil),           // 14: transport.MultiplyTexture
+	(*MixTexture)(nil),                // 15: transport.MixTexture
+	(*ImageTexture)(nil),              // 16: transport.ImageTexture
+	(*NoiseTexture)(nil),              // 17: transport.NoiseTexture
+	(*SpectralConstantTexture)(nil),   // 18: transport.SpectralConstantTexture
+	(*GaussianSpectralConstant)(nil),  // 19: transport.GaussianSpectralConstant
+	(*TabulatedSpectralConstant)(nil), // 20: transport.TabulatedSpectralConstant
+	(*NeutralSpectralConstant)(nil),   // 21: transport.NeutralSpectralConstant
+	(*FromLightSourceLibrary)(nil),    // 22: transport.FromLightSourceLibrary
+	(*SpectralCheckerTexture)(nil),    // 23: transport.SpectralCheckerTexture
+	(*Material)(nil),                  // 24: transport.Material
+	(*LambertMaterial)(nil),           // 25: transport.LambertMaterial
+	(*DielectricMaterial)(nil),        // 26: transport.DielectricMaterial
+	(*DiffuseLightMaterial)(nil),      // 27: transport.DiffuseLightMaterial
+	(*IsotropicMaterial)(nil),         // 28: transport.IsotropicMaterial
+	(*MetalMaterial)(nil),             // 29: transport.MetalMaterial
+	(*PBRMaterial)(nil),               // 30: transport.PBRMaterial
+	(*PrincipledMaterial)(nil),        // 31: transport.PrincipledMaterial
+	(*ConductorMaterial)(nil),         // 32: transport.ConductorMaterial
+	(*ThinFilm)(nil),                  // 33: transport.ThinFilm
+	(*MaterialXReference)(nil),        // 34: transport.MaterialXReference
+	(*Medium)(nil),                    // 35: transport.Medium
+	(*ConstantDensity)(nil),           // 36: transport.ConstantDensity
+	(*GridDensity)(nil),               // 37: transport.GridDensity
+	(*NoiseDensity)(nil),              // 38: transport.NoiseDensity
+	(*VdbDensity)(nil),                // 39: transport.VdbDensity
+	(*Triangle)(nil),                  // 40: transport.Triangle
+	(*Sphere)(nil),                    // 41: transport.Sphere
+	(*SceneObjects)(nil),              // 42: transport.SceneObjects
+	(*Scene)(nil),                     // 43: transport.Scene
+	(*GetSceneRequest)(nil),           // 44: transport.GetSceneRequest
+	(*StreamTextureFileRequest)(nil),  // 45: transport.StreamTextureFileRequest
+	(*StreamTextureFileResponse)(nil), // 46: transport.StreamTextureFileResponse
+	(*StreamVolumeFileRequest)(nil),   // 47: transport.StreamVolumeFileRequest
+	(*StreamVolumeFileResponse)(nil),  // 48: transport.StreamVolumeFileResponse
+	(*StreamTrianglesRequest)(nil),    // 49: transport.StreamTrianglesRequest
+	(*StreamTrianglesResponse)(nil),   // 50: transport.StreamTrianglesResponse
+	nil,                               // 51: transport.Scene.MaterialsEntry
+	nil,                               // 52: transport.Scene.ImageTexturesEntry
+	nil,                               // 53: transport.Scene.DisplacementMapsEntry
+	nil,                               // 54: transport.Scene.MediaEntry
+	nil,                               // 55: transport.Scene.VolumeGridsEntry
 }
 var file_transport_proto_depIdxs = []int32{
-	1,  // 0: transport.ImageTextureMetadata.pixel_format:type_name -> transport.TexturePixelFormat
-	7,  // 1: transport.Camera.lookfrom:type_name -> transport.Vec3
-	7,  // 2: transport.Camera.lookat:type_name -> transport.Vec3
-	7,  // 3: transport.Camera.vup:type_name -> transport.Vec3
-	0,  // 4: transport.Texture.type:type_name -> transport.TextureType
-	11, // 5: transport.Texture.constant:type_name -> transport.ConstantTexture
-	12, // 6: transport.Texture.checker:type_name -> transport.CheckerTexture
-	13, // 7: transport.Texture.image:type_name -> transport.ImageTexture
-	14, // 8: transport.Texture.noise:type_name -> transport.NoiseTexture
-	15, // 9: transport.Texture.spectral_constant:type_name -> transport.SpectralConstantTexture
-	20, // 10: transport.Texture.spectral_checker:type_name -> transport.SpectralCheckerTexture
-	7,  // 11: transport.ConstantTexture.value:type_name -> transport.Vec3
-	10, // 12: transport.CheckerTexture.odd:type_name -> transport.Texture
-	10, // 13: transport.CheckerTexture.even:type_name -> transport.Texture
-	16, // 14: transport.SpectralConstantTexture.gaussian:type_name -> transport.GaussianSpectralConstant
-	17, // 15: transport.SpectralConstantTexture.tabulated:type_name -> transport.TabulatedSpectralConstant
-	18, // 16: transport.SpectralConstantTexture.neutral:type_name -> transport.NeutralSpectralConstant
-	19, // 17: transport.SpectralConstantTexture.from_light_source_library:type_name -> transport.FromLightSourceLibrary
-	15, // 18: transport.SpectralCheckerTexture.odd:type_name -> transport.SpectralConstantTexture
-	15, // 19: transport.SpectralCheckerTexture.even:type_name -> transport.SpectralConstantTexture
-	2,  // 20: transport.Material.type:type_name -> transport.MaterialType
-	23, // 21: transport.Material.dielectric:type_name -> transport.DielectricMaterial
-	24, // 22: transport.Material.diffuselight:type_name -> transport.DiffuseLightMaterial
-	25, // 23: transport.Material.isotropic:type_name -> transport.IsotropicMaterial
-	22, // 24: transport.Material.lambert:type_name -> transport.LambertMaterial
-	26, // 25: transport.Material.metal:type_name -> transport.MetalMaterial
-	27, // 26: transport.Material.pbr:type_name -> transport.PBRMaterial
-	10, // 27: transport.LambertMaterial.albedo:type_name -> transport.Texture
-	15, // 28: transport.LambertMaterial.spectral_albedo:type_name -> transport.SpectralConstantTexture
-	15, // 29: transport.DielectricMaterial.spectral_refidx:type_name -> transport.SpectralConstantTexture
-	7,  // 30: transport.DielectricMaterial.absorption_coeff:type_name -> transport.Vec3
-	15, // 31: transport.DielectricMaterial.spectral_absorption_coeff:type_name -> transport.SpectralConstantTexture
-	10, // 32: transport.DiffuseLightMaterial.emit:type_name -> transport.Texture
-	15, // 33: transport.DiffuseLightMaterial.spectral_emit:type_name -> transport.SpectralConstantTexture
-	10, // 34: transport.IsotropicMaterial.albedo:type_name -> transport.Texture
-	15, // 35: transport.IsotropicMaterial.spectral_albedo:type_name -> transport.SpectralConstantTexture
-	7,  // 36: transport.MetalMaterial.albedo:type_name -> transport.Vec3
-	10, // 37: transport.PBRMaterial.albedo:type_name -> transport.Texture
-	10, // 38: transport.PBRMaterial.roughness:type_name -> transport.Texture
-	10, // 39: transport.PBRMaterial.metalness:type_name -> transport.Texture
-	10, // 40: transport.PBRMaterial.normal_map:type_name -> transport.Texture
-	10, // 41: transport.PBRMaterial.sss:type_name -> transport.Texture
-	7,  // 42: transport.Triangle.vertex0:type_name -> transport.Vec3
-	7,  // 43: transport.Triangle.vertex1:type_name -> transport.Vec3
-	7,  // 44: transport.Triangle.vertex2:type_name -> transport.Vec3
-	8,  // 45: transport.Triangle.uv0:type_name -> transport.Vec2
-	8,  // 46: transport.Triangle.uv1:type_name -> transport.Vec2
-	8,  // 47: transport.Triangle.uv2:type_name -> transport.Vec2
-	7,  // 48: transport.Triangle.normal0:type_name -> transport.Vec3
-	7,  // 49: transport.Triangle.normal1:type_name -> transport.Vec3
-	7,  // 50: transport.Triangle.normal2:type_name -> transport.Vec3
-	4,  // 51: transport.Triangle.operator:type_name -> transport.GeometryOperator
-	6,  // 52: transport.Triangle.displace:type_name -> transport.DisplaceOperator
-	7,  // 53: transport.Sphere.center:type_name -> transport.Vec3
-	28, // 54: transport.SceneObjects.triangles:type_name -> transport.Triangle
-	29, // 55: transport.SceneObjects.spheres:type_name -> transport.Sphere
-	3,  // 56: transport.Scene.colour_representation:type_name -> transport.ColourRepresentation
-	9,  // 57: transport.Scene.camera:type_name -> transport.Camera
-	37, // 58: transport.Scene.materials:type_name -> transport.Scene.MaterialsEntry
-	38, // 59: transport.Scene.image_textures:type_name -> transport.Scene.ImageTexturesEntry
-	39, // 60: transport.Scene.displacement_maps:type_name -> transport.Scene.DisplacementMapsEntry
-	30, // 61: transport.Scene.objects:type_name -> transport.SceneObjects
-	17, // 62: transport.Scene.spectral_background:type_name -> transport.TabulatedSpectralConstant
-	28, // 63: transport.StreamTrianglesResponse.triangles:type_name -> transport.Triangle
-	21, // 64: transport.Scene.MaterialsEntry.value:type_name -> transport.Material
-	5,  // 65: transport.Scene.ImageTexturesEntry.value:type_name -> transport.ImageTextureMetadata
-	5,  // 66: transport.Scene.DisplacementMapsEntry.value:type_name -> transport.ImageTextureMetadata
-	32, // 67: transport.SceneTransportService.GetScene:input_type -> transport.GetSceneRequest
-	33, // 68: transport.SceneTransportService.StreamTextureFile:input_type -> transport.StreamTextureFileRequest
-	35, // 69: transport.SceneTransportService.StreamTriangles:input_type -> transport.StreamTrianglesRequest
-	31, // 70: transport.SceneTransportService.GetScene:output_type -> transport.Scene
-	34, // 71: transport.SceneTransportService.StreamTextureFile:output_type -> transport.StreamTextureFileResponse
-	36, // 72: transport.SceneTransportService.StreamTriangles:output_type -> transport.StreamTrianglesResponse
-	70, // [70:73] is the sub-list for method output_type
-	67, // [67:70] is the sub-list for method input_type
-	67, // [67:67] is the sub-list for extension type_name
-	67, // [67:67] is the sub-list for extension extendee
-	0,  // [0:67] is the sub-list for field type_name
+	1,   // 0: transport.ImageTextureMetadata.pixel_format:type_name -> transport.TexturePixelFormat
+	8,   // 1: transport.Camera.lookfrom:type_name -> transport.Vec3
+	8,   // 2: transport.Camera.lookat:type_name -> transport.Vec3
+	8,   // 3: transport.Camera.vup:type_name -> transport.Vec3
+	0,   // 4: transport.Texture.type:type_name -> transport.TextureType
+	12,  // 5: transport.Texture.constant:type_name -> transport.ConstantTexture
+	13,  // 6: transport.Texture.checker:type_name -> transport.CheckerTexture
+	16,  // 7: transport.Texture.image:type_name -> transport.ImageTexture
+	17,  // 8: transport.Texture.noise:type_name -> transport.NoiseTexture
+	18,  // 9: transport.Texture.spectral_constant:type_name -> transport.SpectralConstantTexture
+	23,  // 10: transport.Texture.spectral_checker:type_name -> transport.SpectralCheckerTexture
+	14,  // 11: transport.Texture.multiply:type_name -> transport.MultiplyTexture
+	15,  // 12: transport.Texture.mix:type_name -> transport.MixTexture
+	8,   // 13: transport.ConstantTexture.value:type_name -> transport.Vec3
+	11,  // 14: transport.CheckerTexture.odd:type_name -> transport.Texture
+	11,  // 15: transport.CheckerTexture.even:type_name -> transport.Texture
+	11,  // 16: transport.MultiplyTexture.a:type_name -> transport.Texture
+	11,  // 17: transport.MultiplyTexture.b:type_name -> transport.Texture
+	11,  // 18: transport.MixTexture.fg:type_name -> transport.Texture
+	11,  // 19: transport.MixTexture.bg:type_name -> transport.Texture
+	11,  // 20: transport.MixTexture.mix:type_name -> transport.Texture
+	19,  // 21: transport.SpectralConstantTexture.gaussian:type_name -> transport.GaussianSpectralConstant
+	20,  // 22: transport.SpectralConstantTexture.tabulated:type_name -> transport.TabulatedSpectralConstant
+	21,  // 23: transport.SpectralConstantTexture.neutral:type_name -> transport.NeutralSpectralConstant
+	22,  // 24: transport.SpectralConstantTexture.from_light_source_library:type_name -> transport.FromLightSourceLibrary
+	18,  // 25: transport.SpectralCheckerTexture.odd:type_name -> transport.SpectralConstantTexture
+	18,  // 26: transport.SpectralCheckerTexture.even:type_name -> transport.SpectralConstantTexture
+	2,   // 27: transport.Material.type:type_name -> transport.MaterialType
+	26,  // 28: transport.Material.dielectric:type_name -> transport.DielectricMaterial
+	27,  // 29: transport.Material.diffuselight:type_name -> transport.DiffuseLightMaterial
+	28,  // 30: transport.Material.isotropic:type_name -> transport.IsotropicMaterial
+	25,  // 31: transport.Material.lambert:type_name -> transport.LambertMaterial
+	29,  // 32: transport.Material.metal:type_name -> transport.MetalMaterial
+	30,  // 33: transport.Material.pbr:type_name -> transport.PBRMaterial
+	31,  // 34: transport.Material.principled:type_name -> transport.PrincipledMaterial
+	34,  // 35: transport.Material.materialx:type_name -> transport.MaterialXReference
+	32,  // 36: transport.Material.conductor:type_name -> transport.ConductorMaterial
+	11,  // 37: transport.LambertMaterial.albedo:type_name -> transport.Texture
+	18,  // 38: transport.LambertMaterial.spectral_albedo:type_name -> transport.SpectralConstantTexture
+	18,  // 39: transport.DielectricMaterial.spectral_refidx:type_name -> transport.SpectralConstantTexture
+	8,   // 40: transport.DielectricMaterial.absorption_coeff:type_name -> transport.Vec3
+	18,  // 41: transport.DielectricMaterial.spectral_absorption_coeff:type_name -> transport.SpectralConstantTexture
+	33,  // 42: transport.DielectricMaterial.thin_film:type_name -> transport.ThinFilm
+	11,  // 43: transport.DiffuseLightMaterial.emit:type_name -> transport.Texture
+	18,  // 44: transport.DiffuseLightMaterial.spectral_emit:type_name -> transport.SpectralConstantTexture
+	11,  // 45: transport.IsotropicMaterial.albedo:type_name -> transport.Texture
+	18,  // 46: transport.IsotropicMaterial.spectral_albedo:type_name -> transport.SpectralConstantTexture
+	8,   // 47: transport.MetalMaterial.albedo:type_name -> transport.Vec3
+	11,  // 48: transport.PBRMaterial.albedo:type_name -> transport.Texture
+	11,  // 49: transport.PBRMaterial.roughness:type_name -> transport.Texture
+	11,  // 50: transport.PBRMaterial.metalness:type_name -> transport.Texture
+	11,  // 51: transport.PBRMaterial.normal_map:type_name -> transport.Texture
+	11,  // 52: transport.PBRMaterial.sss:type_name -> transport.Texture
+	11,  // 53: transport.PrincipledMaterial.base_color:type_name -> transport.Texture
+	11,  // 54: transport.PrincipledMaterial.metallic:type_name -> transport.Texture
+	11,  // 55: transport.PrincipledMaterial.roughness:type_name -> transport.Texture
+	11,  // 56: transport.PrincipledMaterial.normal_map:type_name -> transport.Texture
+	20,  // 57: transport.ConductorMaterial.eta:type_name -> transport.TabulatedSpectralConstant
+	20,  // 58: transport.ConductorMaterial.k:type_name -> transport.TabulatedSpectralConstant
+	33,  // 59: transport.ConductorMaterial.thin_film:type_name -> transport.ThinFilm
+	11,  // 60: transport.ThinFilm.thickness:type_name -> transport.Texture
+	8,   // 61: transport.Medium.sigma_a:type_name -> transport.Vec3
+	8,   // 62: transport.Medium.sigma_s:type_name -> transport.Vec3
+	18,  // 63: transport.Medium.spectral_sigma_a:type_name -> transport.SpectralConstantTexture
+	18,  // 64: transport.Medium.spectral_sigma_s:type_name -> transport.SpectralConstantTexture
+	36,  // 65: transport.Medium.constant:type_name -> transport.ConstantDensity
+	37,  // 66: transport.Medium.grid:type_name -> transport.GridDensity
+	38,  // 67: transport.Medium.noise:type_name -> transport.NoiseDensity
+	39,  // 68: transport.Medium.vdb:type_name -> transport.VdbDensity
+	8,   // 69: transport.GridDensity.min:type_name -> transport.Vec3
+	8,   // 70: transport.GridDensity.max:type_name -> transport.Vec3
+	8,   // 71: transport.Triangle.vertex0:type_name -> transport.Vec3
+	8,   // 72: transport.Triangle.vertex1:type_name -> transport.Vec3
+	8,   // 73: transport.Triangle.vertex2:type_name -> transport.Vec3
+	9,   // 74: transport.Triangle.uv0:type_name -> transport.Vec2
+	9,   // 75: transport.Triangle.uv1:type_name -> transport.Vec2
+	9,   // 76: transport.Triangle.uv2:type_name -> transport.Vec2
+	8,   // 77: transport.Triangle.normal0:type_name -> transport.Vec3
+	8,   // 78: transport.Triangle.normal1:type_name -> transport.Vec3
+	8,   // 79: transport.Triangle.normal2:type_name -> transport.Vec3
+	4,   // 80: transport.Triangle.operator:type_name -> transport.GeometryOperator
+	7,   // 81: transport.Triangle.displace:type_name -> transport.DisplaceOperator
+	8,   // 82: transport.Sphere.center:type_name -> transport.Vec3
+	40,  // 83: transport.SceneObjects.triangles:type_name -> transport.Triangle
+	41,  // 84: transport.SceneObjects.spheres:type_name -> transport.Sphere
+	3,   // 85: transport.Scene.colour_representation:type_name -> transport.ColourRepresentation
+	10,  // 86: transport.Scene.camera:type_name -> transport.Camera
+	51,  // 87: transport.Scene.materials:type_name -> transport.Scene.MaterialsEntry
+	52,  // 88: transport.Scene.image_textures:type_name -> transport.Scene.ImageTexturesEntry
+	53,  // 89: transport.Scene.displacement_maps:type_name -> transport.Scene.DisplacementMapsEntry
+	42,  // 90: transport.Scene.objects:type_name -> transport.SceneObjects
+	20,  // 91: transport.Scene.spectral_background:type_name -> transport.TabulatedSpectralConstant
+	54,  // 92: transport.Scene.media:type_name -> transport.Scene.MediaEntry
+	55,  // 93: transport.Scene.volume_grids:type_name -> transport.Scene.VolumeGridsEntry
+	40,  // 94: transport.StreamTrianglesResponse.triangles:type_name -> transport.Triangle
+	24,  // 95: transport.Scene.MaterialsEntry.value:type_name -> transport.Material
+	5,   // 96: transport.Scene.ImageTexturesEntry.value:type_name -> transport.ImageTextureMetadata
+	5,   // 97: transport.Scene.DisplacementMapsEntry.value:type_name -> transport.ImageTextureMetadata
+	35,  // 98: transport.Scene.MediaEntry.value:type_name -> transport.Medium
+	6,   // 99: transport.Scene.VolumeGridsEntry.value:type_name -> transport.VolumeGridMetadata
+	44,  // 100: transport.SceneTransportService.GetScene:input_type -> transport.GetSceneRequest
+	45,  // 101: transport.SceneTransportService.StreamTextureFile:input_type -> transport.StreamTextureFileRequest
+	47,  // 102: transport.SceneTransportService.StreamVolumeFile:input_type -> transport.StreamVolumeFileRequest
+	49,  // 103: transport.SceneTransportService.StreamTriangles:input_type -> transport.StreamTrianglesRequest
+	43,  // 104: transport.SceneTransportService.GetScene:output_type -> transport.Scene
+	46,  // 105: transport.SceneTransportService.StreamTextureFile:output_type -> transport.StreamTextureFileResponse
+	48,  // 106: transport.SceneTransportService.StreamVolumeFile:output_type -> transport.StreamVolumeFileResponse
+	50,  // 107: transport.SceneTransportService.StreamTriangles:output_type -> transport.StreamTrianglesResponse
+	104, // [104:108] is the sub-list for method output_type
+	100, // [100:104] is the sub-list for method input_type
+	100, // [100:100] is the sub-list for extension type_name
+	100, // [100:100] is the sub-list for extension extendee
+	0,   // [0:100] is the sub-list for field type_name
 }
 
 func init() { file_transport_proto_init() }
@@ -3080,47 +4484,58 @@ func file_transport_proto_init() {
 	if File_transport_proto != nil {
 		return
 	}
-	file_transport_proto_msgTypes[5].OneofWrappers = []any{
+	file_transport_proto_msgTypes[6].OneofWrappers = []any{
 		(*Texture_Constant)(nil),
 		(*Texture_Checker)(nil),
 		(*Texture_Image)(nil),
 		(*Texture_Noise)(nil),
 		(*Texture_SpectralConstant)(nil),
 		(*Texture_SpectralChecker)(nil),
+		(*Texture_Multiply)(nil),
+		(*Texture_Mix)(nil),
 	}
-	file_transport_proto_msgTypes[10].OneofWrappers = []any{
+	file_transport_proto_msgTypes[13].OneofWrappers = []any{
 		(*SpectralConstantTexture_Gaussian)(nil),
 		(*SpectralConstantTexture_Tabulated)(nil),
 		(*SpectralConstantTexture_Neutral)(nil),
 		(*SpectralConstantTexture_FromLightSourceLibrary)(nil),
 	}
-	file_transport_proto_msgTypes[16].OneofWrappers = []any{
+	file_transport_proto_msgTypes[19].OneofWrappers = []any{
 		(*Material_Dielectric)(nil),
 		(*Material_Diffuselight)(nil),
 		(*Material_Isotropic)(nil),
 		(*Material_Lambert)(nil),
 		(*Material_Metal)(nil),
 		(*Material_Pbr)(nil),
+		(*Material_Principled)(nil),
+		(*Material_Materialx)(nil),
+		(*Material_Conductor)(nil),
 	}
-	file_transport_proto_msgTypes[17].OneofWrappers = []any{
+	file_transport_proto_msgTypes[20].OneofWrappers = []any{
 		(*LambertMaterial_Albedo)(nil),
 		(*LambertMaterial_SpectralAlbedo)(nil),
 	}
-	file_transport_proto_msgTypes[18].OneofWrappers = []any{
+	file_transport_proto_msgTypes[21].OneofWrappers = []any{
 		(*DielectricMaterial_Refidx)(nil),
 		(*DielectricMaterial_SpectralRefidx)(nil),
 		(*DielectricMaterial_AbsorptionCoeff)(nil),
 		(*DielectricMaterial_SpectralAbsorptionCoeff)(nil),
 	}
-	file_transport_proto_msgTypes[19].OneofWrappers = []any{
+	file_transport_proto_msgTypes[22].OneofWrappers = []any{
 		(*DiffuseLightMaterial_Emit)(nil),
 		(*DiffuseLightMaterial_SpectralEmit)(nil),
 	}
-	file_transport_proto_msgTypes[20].OneofWrappers = []any{
+	file_transport_proto_msgTypes[23].OneofWrappers = []any{
 		(*IsotropicMaterial_Albedo)(nil),
 		(*IsotropicMaterial_SpectralAlbedo)(nil),
 	}
-	file_transport_proto_msgTypes[23].OneofWrappers = []any{
+	file_transport_proto_msgTypes[30].OneofWrappers = []any{
+		(*Medium_Constant)(nil),
+		(*Medium_Grid)(nil),
+		(*Medium_Noise)(nil),
+		(*Medium_Vdb)(nil),
+	}
+	file_transport_proto_msgTypes[35].OneofWrappers = []any{
 		(*Triangle_Displace)(nil),
 	}
 	type x struct{}
@@ -3129,7 +4544,7 @@ func file_transport_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transport_proto_rawDesc), len(file_transport_proto_rawDesc)),
 			NumEnums:      5,
-			NumMessages:   35,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	SceneTransportService_GetScene_FullMethodName          = "/transport.SceneTransportService/GetScene"
 	SceneTransportService_StreamTextureFile_FullMethodName = "/transport.SceneTransportService/StreamTextureFile"
+	SceneTransportService_StreamVolumeFile_FullMethodName  = "/transport.SceneTransportService/StreamVolumeFile"
 	SceneTransportService_StreamTriangles_FullMethodName   = "/transport.SceneTransportService/StreamTriangles"
 )
 
@@ -30,6 +31,7 @@ const (
 type SceneTransportServiceClient interface {
 	GetScene(ctx context.Context, in *GetSceneRequest, opts ...grpc.CallOption) (*Scene, error)
 	StreamTextureFile(ctx context.Context, in *StreamTextureFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTextureFileResponse], error)
+	StreamVolumeFile(ctx context.Context, in *StreamVolumeFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamVolumeFileResponse], error)
 	StreamTriangles(ctx context.Context, in *StreamTrianglesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTrianglesResponse], error)
 }
 
@@ -70,9 +72,28 @@ func (c *sceneTransportServiceClient) StreamTextureFile(ctx context.Context, in
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SceneTransportService_StreamTextureFileClient = grpc.ServerStreamingClient[StreamTextureFileResponse]
 
+func (c *sceneTransportServiceClient) StreamVolumeFile(ctx context.Context, in *StreamVolumeFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamVolumeFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SceneTransportService_ServiceDesc.Streams[1], SceneTransportService_StreamVolumeFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamVolumeFileRequest, StreamVolumeFileResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SceneTransportService_StreamVolumeFileClient = grpc.ServerStreamingClient[StreamVolumeFileResponse]
+
 func (c *sceneTransportServiceClient) StreamTriangles(ctx context.Context, in *StreamTrianglesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTrianglesResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &SceneTransportService_ServiceDesc.Streams[1], SceneTransportService_StreamTriangles_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &SceneTransportService_ServiceDesc.Streams[2], SceneTransportService_StreamTriangles_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +116,7 @@ type SceneTransportService_StreamTrianglesClient = grpc.ServerStreamingClient[St
 type SceneTransportServiceServer interface {
 	GetScene(context.Context, *GetSceneRequest) (*Scene, error)
 	StreamTextureFile(*StreamTextureFileRequest, grpc.ServerStreamingServer[StreamTextureFileResponse]) error
+	StreamVolumeFile(*StreamVolumeFileRequest, grpc.ServerStreamingServer[StreamVolumeFileResponse]) error
 	StreamTriangles(*StreamTrianglesRequest, grpc.ServerStreamingServer[StreamTrianglesResponse]) error
 	mustEmbedUnimplementedSceneTransportServiceServer()
 }
@@ -112,6 +134,9 @@ func (UnimplementedSceneTransportServiceServer) GetScene(context.Context, *GetSc
 func (UnimplementedSceneTransportServiceServer) StreamTextureFile(*StreamTextureFileRequest, grpc.ServerStreamingServer[StreamTextureFileResponse]) error {
 	return status.Error(codes.Unimplemented, "method StreamTextureFile not implemented")
 }
+func (UnimplementedSceneTransportServiceServer) StreamVolumeFile(*StreamVolumeFileRequest, grpc.ServerStreamingServer[StreamVolumeFileResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamVolumeFile not implemented")
+}
 func (UnimplementedSceneTransportServiceServer) StreamTriangles(*StreamTrianglesRequest, grpc.ServerStreamingServer[StreamTrianglesResponse]) error {
 	return status.Error(codes.Unimplemented, "method StreamTriangles not implemented")
 }
@@ -165,6 +190,17 @@ func _SceneTransportService_StreamTextureFile_Handler(srv interface{}, stream gr
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type SceneTransportService_StreamTextureFileServer = grpc.ServerStreamingServer[StreamTextureFileResponse]
 
+func _SceneTransportService_StreamVolumeFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamVolumeFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SceneTransportServiceServer).StreamVolumeFile(m, &grpc.GenericServerStream[StreamVolumeFileRequest, StreamVolumeFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SceneTransportService_StreamVolumeFileServer = grpc.ServerStreamingServer[StreamVolumeFileResponse]
+
 func _SceneTransportService_StreamTriangles_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(StreamTrianglesRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -194,6 +230,11 @@ var SceneTransportService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _SceneTransportService_StreamTextureFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamVolumeFile",
+			Handler:       _SceneTransportService_StreamVolumeFile_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "StreamTriangles",
 			Handler:       _SceneTransportService_StreamTriangles_Handler,
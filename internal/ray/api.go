@@ -1,7 +1,10 @@
 // Package ray implements the interface and methods to work with rays.
 package ray
 
-import "github.com/flynn-nrg/izpi/internal/vec3"
+import (
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
 
 // Ray defines the methods used to work with rays.
 type Ray interface {
@@ -11,4 +14,6 @@ type Ray interface {
 	Time() float64
 	Lambda() float64
 	SetLambda(float64)
+	Wavelengths() (spectral.Wavelengths, bool)
+	SetWavelengths(spectral.Wavelengths)
 }
@@ -1,6 +1,9 @@
 package ray
 
-import "github.com/flynn-nrg/izpi/internal/vec3"
+import (
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
 
 // Ensure interface compliance.
 var _ Ray = (*RayImpl)(nil)
@@ -11,6 +14,9 @@ type RayImpl struct {
 	direction vec3.Vec3Impl
 	lambda    float64
 	time      float64
+	// wavelengths holds the wavelengths traced along the ray by hero wavelength sampling, if hero is set.
+	wavelengths spectral.Wavelengths
+	hero        bool
 }
 
 // New returns a new ray with the supplied origin and direction vectors and time.
@@ -57,7 +63,22 @@ func (r *RayImpl) Lambda() float64 {
 	return r.lambda
 }
 
-// SetLambda sets the wavelength associated with this ray.
+// SetLambda sets the wavelength associated with this ray. Any other wavelengths carried by the ray are dropped.
 func (r *RayImpl) SetLambda(lambda float64) {
 	r.lambda = lambda
+	r.hero = false
+}
+
+// Wavelengths returns the wavelengths traced along this ray by hero wavelength sampling, and whether it carries
+// more than the single wavelength returned by Lambda.
+func (r *RayImpl) Wavelengths() (spectral.Wavelengths, bool) {
+	return r.wavelengths, r.hero
+}
+
+// SetWavelengths sets the wavelengths traced along this ray by hero wavelength sampling. The hero wavelength
+// becomes the one returned by Lambda.
+func (r *RayImpl) SetWavelengths(wavelengths spectral.Wavelengths) {
+	r.wavelengths = wavelengths
+	r.lambda = wavelengths[0]
+	r.hero = true
 }
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"github.com/flynn-nrg/floatimage/colour"
+	"github.com/flynn-nrg/izpi/internal/aov"
+)
+
+// EnableAOVs makes Render record the AOVs and light groups of the layout in the same pass as the beauty image.
+// Only the colour and spectral samplers support AOVs.
+func (r *RendererImpl) EnableAOVs(layout aov.Layout) {
+	if layout.Empty() {
+		r.aovs = nil
+		return
+	}
+
+	r.aovs = aov.NewBuffers(layout, r.sizeX, r.sizeY)
+}
+
+// AOVs returns the AOVs recorded by Render, or nil if AOVs are disabled.
+// Once Render returns, the colour AOVs of spectral renders are in linear ACEScg like the beauty image.
+func (r *RendererImpl) AOVs() *aov.Buffers {
+	return r.aovs
+}
+
+// newAOVPixel returns an accumulator for the AOVs of a single pixel, or nil if AOVs are disabled.
+func (w workUnit) newAOVPixel() *aov.Pixel {
+	if w.aovs == nil {
+		return nil
+	}
+
+	return aov.NewPixel(w.aovs.Layout())
+}
+
+// accumulate stores the mean of n new samples for the pixel at canvas position (x, y) along with the mean of its AOVs.
+// When pixels are reconstructed with a filter only the sample count and the AOVs are stored, as the colour of the
+// pixel is resolved from the film once the tile is merged into it.
+func (w workUnit) accumulate(x, y, n int, col colour.Float64NRGBA, aovValues []float64) {
+	prev := 0
+	nx := w.canvas.Bounds().Max.X
+	ny := w.canvas.Bounds().Max.Y
+	inBounds := x >= 0 && x < nx && y >= 0 && y < ny
+	if w.sampleCounts != nil && inBounds {
+		prev = w.sampleCounts[y*nx+x]
+	}
+
+	if w.aovs != nil && len(aovValues) > 0 {
+		w.aovs.Accumulate(x, y, prev, n, aovValues)
+	}
+
+	if w.film != nil {
+		if w.sampleCounts != nil && inBounds {
+			w.sampleCounts[y*nx+x] = prev + n
+		}
+		return
+	}
+
+	accumulatePixel(w.canvas, w.sampleCounts, x, y, n, col)
+}
@@ -0,0 +1,93 @@
+package render
+
+import (
+	"math"
+	"time"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+)
+
+// noiseFloor avoids dividing by zero when estimating the relative error of very dark pixels.
+const noiseFloor = 1e-3
+
+// SetBudget limits the rendering time and stops rendering early once the estimated noise level of the image
+// falls below targetNoise. A zero value disables the corresponding limit.
+// Both limits are checked between progressive passes, so they require progressive rendering.
+func (r *RendererImpl) SetBudget(timeLimit time.Duration, targetNoise float64) {
+	r.timeLimit = timeLimit
+	r.targetNoise = targetNoise
+}
+
+// noiseEstimator estimates the noise level of a progressive render by keeping separate luminance sums for
+// the even and odd passes. Both halves are independent estimates of the same image, so half of their difference
+// approximates the standard error of the accumulated image.
+type noiseEstimator struct {
+	spectral bool
+	// prevTotal is the luminance of every pixel multiplied by the number of samples at the end of the previous pass.
+	prevTotal   []float64
+	prevSamples int
+	halves      [2][]float64
+	halfSamples [2]int
+	passes      int
+}
+
+// newNoiseEstimator returns a noise estimator that starts from the current contents of the canvas,
+// which must hold numSamples samples for every pixel.
+func newNoiseEstimator(canvas *floatimage.Float64NRGBA, numSamples int, spectral bool) *noiseEstimator {
+	numPixels := len(canvas.Pix) / 4
+	e := &noiseEstimator{
+		spectral:    spectral,
+		prevTotal:   make([]float64, numPixels),
+		prevSamples: numSamples,
+		halves:      [2][]float64{make([]float64, numPixels), make([]float64, numPixels)},
+	}
+
+	for i := range numPixels {
+		e.prevTotal[i] = e.luminance(canvas.Pix[i*4:]) * float64(numSamples)
+	}
+
+	return e
+}
+
+// addPass records a completed pass. The canvas must hold numSamples samples for every pixel.
+func (e *noiseEstimator) addPass(canvas *floatimage.Float64NRGBA, numSamples int) {
+	half := e.passes % 2
+	for i := range e.prevTotal {
+		total := e.luminance(canvas.Pix[i*4:]) * float64(numSamples)
+		e.halves[half][i] += total - e.prevTotal[i]
+		e.prevTotal[i] = total
+	}
+
+	e.halfSamples[half] += numSamples - e.prevSamples
+	e.prevSamples = numSamples
+	e.passes++
+}
+
+// estimate returns the root mean square of the relative standard error of every pixel.
+// It returns +Inf until at least two passes have been recorded.
+func (e *noiseEstimator) estimate() float64 {
+	if e.halfSamples[0] == 0 || e.halfSamples[1] == 0 {
+		return math.Inf(1)
+	}
+
+	sum := 0.0
+	for i := range e.prevTotal {
+		even := e.halves[0][i] / float64(e.halfSamples[0])
+		odd := e.halves[1][i] / float64(e.halfSamples[1])
+		mean := e.prevTotal[i] / float64(e.prevSamples)
+		relErr := 0.5 * (even - odd) / math.Max(math.Abs(mean), noiseFloor)
+		sum += relErr * relErr
+	}
+
+	return math.Sqrt(sum / float64(len(e.prevTotal)))
+}
+
+func (e *noiseEstimator) luminance(pix []float64) float64 {
+	// Spectral canvases are in CIE XYZ space, where Y is the luminance.
+	if e.spectral {
+		return pix[1]
+	}
+
+	return adaptive.Luminance(pix[0], pix[1], pix[2])
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/aov"
+	"github.com/flynn-nrg/izpi/internal/checkpoint"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// tileTracker records which tiles of the current pass have been rendered.
+// A nil tracker is valid and tracks nothing.
+type tileTracker struct {
+	// mu is held for reading while a work unit updates the pixels and for writing while a checkpoint is taken,
+	// so that checkpoints never contain partially updated pixels.
+	mu        sync.RWMutex
+	pass      int
+	completed []bool
+}
+
+func newTileTracker(numTiles int) *tileTracker {
+	return &tileTracker{
+		completed: make([]bool, numTiles),
+	}
+}
+
+func (t *tileTracker) beginUpdate() {
+	if t != nil {
+		t.mu.RLock()
+	}
+}
+
+func (t *tileTracker) endUpdate() {
+	if t != nil {
+		t.mu.RUnlock()
+	}
+}
+
+// complete marks the tile at position i of the rendering path as rendered. It must be called between beginUpdate
+// and endUpdate.
+func (t *tileTracker) complete(i int) {
+	if t != nil {
+		t.completed[i] = true
+	}
+}
+
+// isComplete returns whether the tile at position i of the rendering path has been rendered in the current pass.
+func (t *tileTracker) isComplete(i int) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.completed[i]
+}
+
+// numCompleted returns the number of tiles rendered so far across all passes.
+func (t *tileTracker) numCompleted() int {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n := t.pass * len(t.completed)
+	for _, c := range t.completed {
+		if c {
+			n++
+		}
+	}
+
+	return n
+}
+
+// nextPass marks the current pass as complete.
+func (t *tileTracker) nextPass() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.pass++
+	clear(t.completed)
+	t.mu.Unlock()
+}
+
+// EnableCheckpoints makes Render save its progress to fileName every interval, as well as when it finishes or is stopped.
+func (r *RendererImpl) EnableCheckpoints(fileName string, interval time.Duration) {
+	r.checkpointFile = fileName
+	r.checkpointInterval = interval
+}
+
+// Resume restores the canvas, sample counts, light image, film and tile completion state from a checkpoint
+// so that Render only renders the work that is left.
+func (r *RendererImpl) Resume(state *checkpoint.State) error {
+	if err := state.Matches(r.checkpointSettings()); err != nil {
+		return err
+	}
+
+	if r.sampleCounts != nil && state.SampleCounts == nil {
+		return fmt.Errorf("%w: no sample counts", checkpoint.ErrMismatch)
+	}
+
+	copy(r.canvas.Pix, state.Pixels)
+	if r.sampleCounts != nil {
+		copy(r.sampleCounts, state.SampleCounts)
+	}
+	if r.aovs != nil {
+		copy(r.aovs.Data(), state.AOVPixels)
+	}
+	if r.lightImage != nil {
+		r.lightImage.Merge(state.LightImage, state.LightPaths)
+	}
+	if r.film != nil {
+		r.film.film.Merge(r.canvas.Bounds(), state.Film)
+	}
+
+	r.tiles = &tileTracker{
+		pass:      state.Pass,
+		completed: slices.Clone(state.CompletedTiles),
+	}
+
+	return nil
+}
+
+// checkpointSettings returns a state that only holds the render settings.
+func (r *RendererImpl) checkpointSettings() *checkpoint.State {
+	state := &checkpoint.State{
+		Width:       r.sizeX,
+		Height:      r.sizeY,
+		Samples:     r.numSamples,
+		PassSamples: r.passSamples,
+		SamplerType: int(r.samplerType),
+		Adaptive:    r.adaptive != nil,
+	}
+
+	if r.aovs != nil {
+		state.AOVs = aov.Names(r.aovs.Layout().Types)
+		state.LightGroups = r.aovs.Layout().LightGroups
+	}
+
+	if r.film != nil {
+		state.Filter = int(r.filterType)
+		state.FilterRadius = r.film.filter.Radius()
+	}
+
+	return state
+}
+
+// saveCheckpoint writes the current render state to the checkpoint file.
+// Workers are paused while the state is copied.
+func (r *RendererImpl) saveCheckpoint() {
+	state := r.checkpointSettings()
+
+	r.tiles.mu.Lock()
+	state.Pass = r.tiles.pass
+	state.CompletedTiles = slices.Clone(r.tiles.completed)
+	pixels := floatimage.NewFloat64NRGBA(r.canvas.Bounds(), slices.Clone(r.canvas.Pix))
+	state.Pixels = pixels.Pix
+	state.SampleCounts = slices.Clone(r.sampleCounts)
+	if r.aovs != nil {
+		state.AOVPixels = slices.Clone(r.aovs.Data())
+	}
+	// Tiles whose result is waiting to be merged are already marked as rendered and their pixels accumulated.
+	r.results.mu.Lock()
+	state.Film, state.LightImage, state.LightPaths = r.results.snapshot(r.film, r.lightImage, pixels)
+	r.results.mu.Unlock()
+	r.tiles.mu.Unlock()
+
+	startTime := time.Now()
+	if err := checkpoint.Save(r.checkpointFile, state); err != nil {
+		log.Errorf("Failed to save checkpoint: %v", err)
+		return
+	}
+
+	log.Debugf("Checkpoint saved to %s in %v", r.checkpointFile, time.Since(startTime))
+}
+
+// checkpointLoop saves a checkpoint every r.checkpointInterval until ctx is done.
+func (r *RendererImpl) checkpointLoop(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(r.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.saveCheckpoint()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"image"
+	"math"
+
+	"github.com/flynn-nrg/floatimage/colour"
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/display"
+	"github.com/flynn-nrg/izpi/internal/filter"
+	"github.com/flynn-nrg/izpi/internal/sampler"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+)
+
+// imageFilm holds the samples of the whole image when they are reconstructed with a filter that reaches beyond
+// the pixel they fall in. Tiles splat their samples to a film of their own, which is merged into it once the tile
+// is rendered.
+type imageFilm struct {
+	filter filter.Filter
+	film   *filter.Film
+}
+
+// setPixels sets the pixels of canvas within bounds to the ones reconstructed by film.
+func setPixels(canvas *floatimage.Float64NRGBA, film *filter.Film, bounds image.Rectangle) {
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if c, ok := film.Pixel(x, y); ok {
+				canvas.Set(x, y, colour.Float64NRGBA{R: c[0], G: c[1], B: c[2], A: 1.0})
+			}
+		}
+	}
+}
+
+// SetFilter sets the filter used to reconstruct pixels from their samples and its radius, or the default radius
+// of the filter if radius is not positive. The samples of every pixel are averaged on their own when the filter is
+// a box no wider than a pixel, as well as by the Metropolis samplers, whose chains splat their light to the light
+// image instead.
+func (r *RendererImpl) SetFilter(t filter.FilterType, radius float64) {
+	r.filterType = t
+	r.film = nil
+
+	f := filter.New(t, radius)
+	if f == nil || filter.IsPixelBox(f) || sampler.IsMetropolis(r.samplerType) {
+		return
+	}
+
+	r.film = &imageFilm{
+		filter: f,
+		film:   filter.NewFilm(f, r.canvas.Bounds()),
+	}
+}
+
+// NewTileFilm returns a film for the samples of the tile that spans from (x0, y0) to (x1, y1) of an image of nx by
+// ny pixels. It covers every pixel of the image within the radius of f of the tile.
+func NewTileFilm(f filter.Filter, x0, y0, x1, y1, nx, ny int) *filter.Film {
+	return filter.NewFilm(f, tileFilmBounds(f, x0, y0, x1, y1, nx, ny))
+}
+
+// tileFilmBounds returns the pixels of the canvas that the samples of a tile can reach.
+func tileFilmBounds(f filter.Filter, x0, y0, x1, y1, nx, ny int) image.Rectangle {
+	r := int(math.Ceil(f.Radius()))
+	// Camera rays for row y of the image are stored in row ny - y of the canvas.
+	bounds := image.Rect(x0-r, ny-y1-r, x1+1+r, ny-y0+1+r)
+
+	return bounds.Intersect(image.Rect(0, 0, nx, ny))
+}
+
+// splat adds the colour c of a sample of the pixel (x, y) of an image ny pixels high to film. (dx, dy) is the
+// position of the sample within the pixel.
+func splat(film *filter.Film, x, y, ny int, dx, dy float64, c [3]float64) {
+	// The canvas is stored upside down, so the sample is mirrored within the pixel as well.
+	film.AddSample(float64(x)+dx, float64(ny-y)+1-dy, c)
+}
+
+// newTileFilm returns the film that the samples of the work unit are splatted to, or nil if pixels are not
+// reconstructed with a filter.
+func (w workUnit) newTileFilm() *filter.Film {
+	if w.film == nil {
+		return nil
+	}
+
+	return NewTileFilm(w.film.filter, w.x0, w.y0, w.x1, w.y1, w.canvas.Bounds().Max.X, w.canvas.Bounds().Max.Y)
+}
+
+// previewRows returns the rows of the canvas within bounds, ready to be previewed.
+func (w workUnit) previewRows(bounds image.Rectangle) []display.DisplayTile {
+	tiles := make([]display.DisplayTile, 0, bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		tile := display.DisplayTile{
+			Width:  bounds.Dx(),
+			Height: 1,
+			PosX:   bounds.Min.X,
+			PosY:   y,
+			Pixels: make([]float64, bounds.Dx()*4),
+		}
+
+		i := 0
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := w.canvas.Float64NRGBAAt(x, y)
+			if w.spectral {
+				// Apply exposure and convert to ACEScg for preview
+				exposure := w.scene.Exposure
+				c.R, c.G, c.B = spectral.XYZToACEScg(c.R*exposure, c.G*exposure, c.B*exposure)
+			}
+
+			tile.Pixels[i] = c.B
+			tile.Pixels[i+1] = c.G
+			tile.Pixels[i+2] = c.R
+			tile.Pixels[i+3] = c.A
+			i += 4
+		}
+		tiles = append(tiles, tile)
+	}
+
+	return tiles
+}
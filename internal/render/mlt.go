@@ -0,0 +1,16 @@
+package render
+
+import (
+	"github.com/flynn-nrg/izpi/internal/sampler"
+)
+
+// SetMLTConfig sets the seed and settings used by the Metropolis light transport samplers.
+func (r *RendererImpl) SetMLTConfig(cfg sampler.MLTConfig) {
+	r.mltConfig = cfg
+}
+
+// RunPixelChain runs the Markov chain of the pixel (x, y) of an image nx pixels wide for numSamples mutations.
+// firstSample is the number of samples already rendered for the pixel by previous passes.
+func RunPixelChain(s sampler.ChainSampler, numSamples int, firstSample int, x, y, nx int) {
+	s.RunChain(y*nx+x, firstSample, numSamples)
+}
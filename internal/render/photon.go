@@ -0,0 +1,18 @@
+package render
+
+import (
+	"github.com/flynn-nrg/izpi/internal/sampler"
+)
+
+// SetPhotonConfig sets the settings used by the photon mapping samplers.
+func (r *RendererImpl) SetPhotonConfig(cfg sampler.PhotonConfig) {
+	r.photonConfig = cfg
+}
+
+// PreparePass builds the photon map of the pass that starts after firstSample samples per pixel if s gathers
+// light from one, and does nothing otherwise.
+func PreparePass(s sampler.Sampler, firstSample int) {
+	if ps, ok := s.(sampler.PhotonSampler); ok {
+		ps.PreparePass(firstSample)
+	}
+}
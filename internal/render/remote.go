@@ -6,40 +6,49 @@ import (
 	"sync"
 
 	"github.com/flynn-nrg/floatimage/colour"
-	"github.com/flynn-nrg/izpi/internal/display"
-	"github.com/flynn-nrg/izpi/internal/sampler"
-	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/aov"
 
 	pb_control "github.com/flynn-nrg/izpi/internal/proto/control"
 	log "github.com/sirupsen/logrus"
 )
 
-func renderRectRemote(ctx context.Context, w workUnit, client pb_control.RenderControlServiceClient) {
-	var tile display.DisplayTile
-
+// renderRectRemote renders the work unit on a remote worker and returns its pixels and result, and whether the
+// whole tile was rendered.
+func renderRectRemote(ctx context.Context, w workUnit, client pb_control.RenderControlServiceClient) (bool, *tilePixels, tileResult) {
 	ny := w.canvas.Bounds().Max.Y
 
-	if w.preview {
-		tile = display.DisplayTile{
-			Width:  w.x1 - w.x0 + 1,
-			Height: 1,
-			PosX:   w.x0,
-			Pixels: make([]float64, (w.x1-w.x0+1)*4),
-		}
-	}
-
 	request := &pb_control.RenderTileRequest{
 		StripHeight: 1,
 		X0:          uint32(w.x0),
 		Y0:          uint32(w.y0),
 		X1:          uint32(w.x1),
 		Y1:          uint32(w.y1),
+		// Progressive passes render fewer samples than the worker was set up with.
+		NumSamples: uint32(w.numSamples),
+		// Metropolis samplers seed the chains of every pass differently.
+		FirstSample: uint32(w.firstSample),
+	}
+
+	if w.adaptive != nil {
+		request.AdaptiveSampling = &pb_control.AdaptiveSampling{
+			MinSamples: uint32(w.adaptive.MinSamples),
+			MaxSamples: uint32(w.adaptive.MaxSamples),
+			Threshold:  w.adaptive.Threshold,
+		}
+	}
+
+	if w.aovs != nil {
+		request.Aovs = aov.Names(w.aovs.Layout().Types)
+		request.LightGroups = w.aovs.Layout().LightGroups
 	}
 
+	pixels := &tilePixels{}
+	var result tileResult
+
 	stream, err := client.RenderTile(ctx, request)
 	if err != nil {
 		log.Errorf("Failed to render tile: %v", err)
-		return
+		return false, nil, tileResult{}
 	}
 	defer stream.CloseSend()
 
@@ -50,47 +59,58 @@ func renderRectRemote(ctx context.Context, w workUnit, client pb_control.RenderC
 				break
 			}
 
+			// The render was stopped. Whatever was received so far is discarded, as the tile will be rendered again
+			// when the render is resumed.
+			if ctx.Err() != nil {
+				return false, nil, tileResult{}
+			}
+
 			log.Errorf("Failed to receive tile: %v", err)
-			return
+			return false, nil, tileResult{}
+		}
+
+		// The samples and light paths of the tile reach the pixels of its neighbours, so they arrive once the whole
+		// tile is rendered.
+		if film := reply.GetFilm(); len(film) > 0 || reply.GetLightPaths() > 0 {
+			if w.film != nil && len(film) > 0 {
+				result.filmBounds = tileFilmBounds(w.film.filter, w.x0, w.y0, w.x1, w.y1, w.canvas.Bounds().Max.X, ny)
+				result.film = film
+			}
+			result.lightPixels, result.light, result.lightPaths = reply.GetLightPixels(), reply.GetLightImage(), reply.GetLightPaths()
+			continue
 		}
 
 		posX := int(reply.GetPosX())
 		posY := int(reply.GetPosY())
 		width := int(reply.GetWidth())
-		pixels := reply.GetPixels()
-
-		tile.PosY = ny - posY
-
-		isSpectral := w.sampler.(*sampler.Spectral) != nil
+		values := reply.GetPixels()
+		sampleCounts := reply.GetSampleCounts()
+		aovValues := reply.GetAovs()
 
-		i := 0
 		for x := posX; x < posX+width; x++ {
-			colX, colY, colZ, alpha := pixels[i], pixels[i+1], pixels[i+2], pixels[i+3]
-			w.canvas.Set(x, ny-posY, colour.Float64NRGBA{R: colX, G: colY, B: colZ, A: alpha})
-
-			if w.preview {
-				if isSpectral {
-					// Apply exposure and convert to ACEScg for preview
-					exposure := w.scene.Exposure
-					colX, colY, colZ = spectral.XYZToACEScg(colX*exposure, colY*exposure, colZ*exposure)
-				}
+			j := x - posX
+			n := w.numSamples
+			if j < len(sampleCounts) {
+				n = int(sampleCounts[j])
+			}
 
-				tile.Pixels[i] = colZ
-				tile.Pixels[i+1] = colY
-				tile.Pixels[i+2] = colX
-				tile.Pixels[i+3] = alpha
-				i += 4
+			var aovs []float64
+			if w.aovs != nil {
+				stride := w.aovs.Stride()
+				if k := j * stride; k+stride <= len(aovValues) {
+					aovs = aovValues[k : k+stride]
+				}
 			}
-		}
 
-		if w.preview {
-			w.previewChan <- tile
+			pixels.add(x, ny-posY, n, colour.Float64NRGBA{R: values[j*4], G: values[j*4+1], B: values[j*4+2], A: values[j*4+3]}, aovs)
 		}
 	}
 
 	if w.verbose {
 		w.bar.Increment()
 	}
+
+	return true, pixels, result
 }
 
 func remoteWorker(ctx context.Context, input chan workUnit, quit chan struct{}, wg *sync.WaitGroup, config *RemoteWorkerConfig) {
@@ -98,7 +118,7 @@ func remoteWorker(ctx context.Context, input chan workUnit, quit chan struct{},
 	for {
 		select {
 		case w := <-input:
-			renderRectRemote(ctx, w, config.Client)
+			w.finish(renderRectRemote(ctx, w, config.Client))
 		case <-quit:
 			log.Debug("Remote worker exiting")
 			return
@@ -3,17 +3,22 @@ package render
 
 import (
 	"context"
+	"errors"
 	"image"
 	"sync"
 	"time"
 
+	"github.com/flynn-nrg/floatimage/colour"
 	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/common"
 	"github.com/flynn-nrg/izpi/internal/display"
-	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/filter"
 	"github.com/flynn-nrg/izpi/internal/grid"
 	"github.com/flynn-nrg/izpi/internal/sampler"
 	"github.com/flynn-nrg/izpi/internal/scene"
+	"github.com/flynn-nrg/izpi/internal/sequence"
 	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 
@@ -34,7 +39,7 @@ type RendererImpl struct {
 	remoteWorkers      []*RemoteWorkerConfig
 	canvas             *floatimage.Float64NRGBA
 	previewChan        chan display.DisplayTile
-	maxDepth           int
+	depth              sampler.Depth
 	background         vec3.Vec3Impl
 	ink                vec3.Vec3Impl
 	spectralBackground *spectral.SpectralPowerDistribution
@@ -46,6 +51,23 @@ type RendererImpl struct {
 	numSamples         int
 	numWorkers         int
 	verbose            bool
+	adaptive           *adaptive.Config
+	passSamples        int
+	sampleCounts       []int
+	tiles              *tileTracker
+	checkpointFile     string
+	checkpointInterval time.Duration
+	timeLimit          time.Duration
+	targetNoise        float64
+	aovs               *aov.Buffers
+	lightImage         *sampler.LightImage
+	results            *tileResults
+	mltConfig          sampler.MLTConfig
+	photonConfig       sampler.PhotonConfig
+	generator          sequence.GeneratorType
+	generatorSeed      uint64
+	filterType         filter.FilterType
+	film               *imageFilm
 }
 
 type RemoteWorkerConfig struct {
@@ -54,26 +76,38 @@ type RemoteWorkerConfig struct {
 }
 
 type workUnit struct {
-	scene       *scene.Scene
-	canvas      *floatimage.Float64NRGBA
-	bar         *pb.ProgressBar
-	sampler     sampler.Sampler
-	previewChan chan display.DisplayTile
-	preview     bool
-	verbose     bool
-	stripHeight int
-	numSamples  int
-	x0          int
-	x1          int
-	y0          int
-	y1          int
+	scene        *scene.Scene
+	canvas       *floatimage.Float64NRGBA
+	bar          *pb.ProgressBar
+	sampler      sampler.Sampler
+	spectral     bool
+	previewChan  chan display.DisplayTile
+	preview      bool
+	verbose      bool
+	stripHeight  int
+	numSamples   int
+	firstSample  int
+	adaptive     *adaptive.Config
+	sampleCounts []int
+	aovs         *aov.Buffers
+	film         *imageFilm
+	lightImage   *sampler.LightImage
+	results      *tileResults
+	pending      *sync.WaitGroup
+	tiles        *tileTracker
+	tile         int
+	resultIndex  int
+	x0           int
+	x1           int
+	y0           int
+	y1           int
 }
 
 // New returns a new instance of a renderer.
 func New(
 	scene *scene.Scene,
 	sizeX int, sizeY int,
-	numSamples int, maxDepth int,
+	numSamples int, depth sampler.Depth,
 	background vec3.Vec3Impl, ink vec3.Vec3Impl,
 	spectralBackground *spectral.SpectralPowerDistribution,
 	numLocalWorkers int,
@@ -82,13 +116,25 @@ func New(
 	previewChan chan display.DisplayTile,
 	preview bool,
 	samplerType sampler.SamplerType,
+	adaptiveConfig *adaptive.Config,
+	passSamples int,
 ) *RendererImpl {
+	var sampleCounts []int
+	if adaptiveConfig != nil || passSamples > 0 {
+		sampleCounts = make([]int, sizeX*sizeY)
+	}
+
+	var lightImage *sampler.LightImage
+	if sampler.UsesLightImage(samplerType) {
+		lightImage = sampler.NewLightImage(sizeX, sizeY)
+	}
+
 	return &RendererImpl{
 		scene:              scene,
 		remoteWorkers:      remoteWorkers,
 		canvas:             floatimage.NewFloat64NRGBA(image.Rect(0, 0, sizeX, sizeY), make([]float64, sizeX*sizeY*4)),
 		previewChan:        previewChan,
-		maxDepth:           maxDepth,
+		depth:              depth,
 		background:         background,
 		ink:                ink,
 		spectralBackground: spectralBackground,
@@ -100,6 +146,13 @@ func New(
 		numSamples:         numSamples,
 		numWorkers:         numLocalWorkers,
 		verbose:            verbose,
+		adaptive:           adaptiveConfig,
+		passSamples:        passSamples,
+		sampleCounts:       sampleCounts,
+		lightImage:         lightImage,
+		results:            newTileResults(),
+		generator:          sequence.IndependentGenerator,
+		filterType:         filter.BoxFilter,
 	}
 }
 
@@ -109,6 +162,13 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 
 	var bar *pb.ProgressBar
 
+	// Running out of time cancels the remaining work on both local and remote workers.
+	if r.timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeLimit)
+		defer cancel()
+	}
+
 	queue := make(chan workUnit)
 	quit := make(chan struct{})
 	wg := &sync.WaitGroup{}
@@ -116,8 +176,13 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 	stepSizeX, stepSizeY := common.Tiles(r.sizeX, r.sizeY)
 
 	numTiles := (r.sizeX / stepSizeX) * (r.sizeY / stepSizeY)
+	if r.tiles == nil && r.checkpointFile != "" {
+		r.tiles = newTileTracker(numTiles)
+	}
+
 	if r.verbose {
-		bar = pb.StartNew(numTiles)
+		bar = pb.StartNew(numTiles * r.numPasses())
+		bar.SetCurrent(int64(r.tiles.numCompleted()))
 	}
 
 	totalWorkers := 0
@@ -125,13 +190,13 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 	// Local workers
 	for range r.numWorkers {
 		totalWorkers++
-		random := fastrandom.NewWithDefaults()
+		random := r.newRandom()
 		wg.Add(1)
 		switch r.samplerType {
-		case sampler.ColourSampler, sampler.NormalSampler, sampler.WireFrameSampler, sampler.AlbedoSampler:
-			go workerRGB(queue, quit, random, wg)
-		case sampler.SpectralSampler:
-			go workerSpectral(queue, quit, random, wg)
+		case sampler.ColourSampler, sampler.NormalSampler, sampler.WireFrameSampler, sampler.AlbedoSampler, sampler.BDPTSampler, sampler.MLTSampler, sampler.PPMSampler:
+			go workerRGB(ctx, queue, quit, random, wg)
+		case sampler.SpectralSampler, sampler.SpectralBDPTSampler, sampler.SpectralMLTSampler, sampler.SpectralPPMSampler:
+			go workerSpectral(ctx, queue, quit, random, wg)
 		default:
 			log.Fatalf("invalid sampler type %v", r.samplerType)
 		}
@@ -153,7 +218,7 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 	var s sampler.Sampler
 	switch r.samplerType {
 	case sampler.ColourSampler:
-		s = sampler.NewColour(r.maxDepth, r.background, &r.numRays)
+		s = sampler.NewColour(r.depth, r.background, &r.numRays)
 	case sampler.NormalSampler:
 		s = sampler.NewNormal(&r.numRays)
 	case sampler.WireFrameSampler:
@@ -161,7 +226,19 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 	case sampler.AlbedoSampler:
 		s = sampler.NewAlbedo(&r.numRays)
 	case sampler.SpectralSampler:
-		s = sampler.NewSpectral(r.maxDepth, r.spectralBackground, &r.numRays)
+		s = sampler.NewSpectral(r.depth, r.spectralBackground, &r.numRays)
+	case sampler.BDPTSampler:
+		s = sampler.NewBDPT(r.depth, r.background, r.scene.Camera, r.scene.Lights, r.lightImage, &r.numRays)
+	case sampler.SpectralBDPTSampler:
+		s = sampler.NewSpectralBDPT(r.depth, r.spectralBackground, r.scene.Camera, r.scene.Lights, r.lightImage, &r.numRays)
+	case sampler.MLTSampler:
+		s = sampler.NewMLT(r.mltConfig, r.depth, r.background, r.scene.Camera, r.scene.World, r.scene.Lights, r.lightImage, &r.numRays)
+	case sampler.SpectralMLTSampler:
+		s = sampler.NewSpectralMLT(r.mltConfig, r.depth, r.spectralBackground, r.scene.Camera, r.scene.World, r.scene.Lights, r.lightImage, &r.numRays)
+	case sampler.PPMSampler:
+		s = sampler.NewPPM(r.photonConfig, r.depth, r.background, r.scene.World, r.scene.Lights, &r.numRays)
+	case sampler.SpectralPPMSampler:
+		s = sampler.NewSpectralPPM(r.photonConfig, r.depth, r.spectralBackground, r.scene.World, r.scene.Lights, &r.numRays)
 	default:
 		log.Fatalf("invalid sampler type %v", r.samplerType)
 	}
@@ -169,24 +246,45 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 	log.Infof("Begin rendering using %v worker threads: %v local, %v remote", totalWorkers, r.numWorkers, totalWorkers-r.numWorkers)
 	startTime := time.Now()
 
-	for _, t := range path {
-		queue <- workUnit{
-			scene:       r.scene,
-			canvas:      r.canvas,
-			bar:         bar,
-			sampler:     s,
-			previewChan: r.previewChan,
-			preview:     r.preview,
-			verbose:     r.verbose,
-			stripHeight: 1,
-			numSamples:  r.numSamples,
-			x0:          t.X * stepSizeX,
-			x1:          t.X*stepSizeX + (stepSizeX - 1),
-			y0:          t.Y * stepSizeY,
-			y1:          t.Y*stepSizeY + (stepSizeY - 1),
+	checkpointWg := &sync.WaitGroup{}
+	checkpointCtx, stopCheckpoints := context.WithCancel(ctx)
+	if r.checkpointFile != "" && r.checkpointInterval > 0 {
+		checkpointWg.Add(1)
+		go r.checkpointLoop(checkpointCtx, checkpointWg)
+	}
+
+	newWorkUnit := func(i int, numSamples int, firstSample int, pending *sync.WaitGroup) workUnit {
+		t := path[i]
+		return workUnit{
+			scene:        r.scene,
+			canvas:       r.canvas,
+			bar:          bar,
+			sampler:      s,
+			spectral:     sampler.IsSpectral(r.samplerType),
+			previewChan:  r.previewChan,
+			preview:      r.preview,
+			verbose:      r.verbose,
+			stripHeight:  1,
+			numSamples:   numSamples,
+			firstSample:  firstSample,
+			adaptive:     r.adaptive,
+			sampleCounts: r.sampleCounts,
+			aovs:         r.aovs,
+			film:         r.film,
+			lightImage:   r.lightImage,
+			results:      r.results,
+			pending:      pending,
+			tiles:        r.tiles,
+			tile:         i,
+			x0:           t.X * stepSizeX,
+			x1:           t.X*stepSizeX + (stepSizeX - 1),
+			y0:           t.Y * stepSizeY,
+			y1:           t.Y*stepSizeY + (stepSizeY - 1),
 		}
 	}
 
+	r.renderPasses(ctx, queue, len(path), newWorkUnit)
+
 	for range totalWorkers {
 		quit <- struct{}{}
 	}
@@ -195,13 +293,13 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 
 	wg.Wait()
 
-	if r.verbose {
-		bar.Finish()
-	}
+	stopCheckpoints()
+	checkpointWg.Wait()
 
 	// If there are any remote workers, call them to collect stats and free up resources.
+	// This must happen even if the render was stopped early.
 	for _, worker := range r.remoteWorkers {
-		report, err := worker.Client.RenderEnd(ctx, &pb_control.RenderEndRequest{})
+		report, err := worker.Client.RenderEnd(context.WithoutCancel(ctx), &pb_control.RenderEndRequest{})
 		if err != nil {
 			log.Errorf("Failed to get render end report: %v", err)
 		}
@@ -210,13 +308,192 @@ func (r *RendererImpl) Render(ctx context.Context) image.Image {
 		r.numRays += workerNumRays
 	}
 
+	if r.checkpointFile != "" {
+		r.saveCheckpoint()
+		log.Infof("Checkpoint saved to %s", r.checkpointFile)
+	}
+
+	if r.verbose {
+		bar.Finish()
+	}
+
 	log.Infof("Rendering completed in %v using %v rays", time.Since(startTime), r.numRays)
 
+	if r.adaptive != nil {
+		total := 0
+		for _, n := range r.sampleCounts {
+			total += n
+		}
+		log.Infof("Adaptive sampling used %.2f samples per pixel on average (min %v, max %v)",
+			float64(total)/float64(len(r.sampleCounts)), r.adaptive.MinSamples, r.adaptive.MaxSamples)
+	}
+
+	// Light paths that reached the camera can land on any pixel, so they are only added once rendering is done.
+	if r.lightImage != nil {
+		r.lightImage.AddTo(r.canvas)
+	}
+
 	// If spectral rendering is enabled, perform firefly rejection and convert to ACEScg.
-	if r.samplerType == sampler.SpectralSampler {
+	if sampler.IsSpectral(r.samplerType) {
+		if r.aovs != nil {
+			r.aovs.ConvertXYZ(r.exposure)
+		}
 		spectral.FireflyRejection(r.canvas)
 		return spectral.XYZToRGB(r.canvas, r.exposure)
 	}
 
 	return r.canvas
 }
+
+// renderPasses renders the whole frame in passes of r.passSamples samples per pixel, or in a single pass
+// if progressive rendering is disabled. Every pass is accumulated into the canvas, so rendering can be stopped
+// at any point by cancelling ctx and the canvas still contains a valid image.
+// Tiles that were already rendered according to the tile tracker are skipped.
+func (r *RendererImpl) renderPasses(ctx context.Context, queue chan workUnit, numTiles int,
+	newWorkUnit func(i int, numSamples int, firstSample int, pending *sync.WaitGroup) workUnit) {
+	numPasses := r.numPasses()
+
+	firstPass := 0
+	if r.tiles != nil {
+		firstPass = r.tiles.pass
+		if n := r.tiles.numCompleted(); n > 0 {
+			log.Infof("Resuming render with %v of %v tiles already rendered", n, numTiles*numPasses)
+		}
+	}
+
+	// Noise estimation needs every pixel to have the same number of samples, so when resuming
+	// from the middle of a pass it starts once that pass is complete.
+	var noise *noiseEstimator
+	if r.targetNoise > 0 && r.tiles.numCompleted()%numTiles == 0 {
+		noise = newNoiseEstimator(r.canvas, r.samplesAfter(firstPass), sampler.IsSpectral(r.samplerType))
+	}
+
+	// sent numbers the work units in the order they are sent, which is the order their results are merged in.
+	sent := 0
+	for pass := firstPass; pass < numPasses; pass++ {
+		numSamples := r.passSampleCount(pass)
+
+		log.Debugf("Starting pass %v of %v with %v samples per pixel", pass+1, numPasses, numSamples)
+
+		// A pass must be complete before the next one starts, as both would update the same pixels.
+		pending := &sync.WaitGroup{}
+		for i := range numTiles {
+			if r.tiles.isComplete(i) {
+				continue
+			}
+
+			pending.Add(1)
+			w := newWorkUnit(i, numSamples, r.samplesAfter(pass), pending)
+			w.resultIndex = sent
+			select {
+			case queue <- w:
+				sent++
+			case <-ctx.Done():
+				pending.Done()
+				pending.Wait()
+				r.logStopped(ctx, pass, numPasses)
+				return
+			}
+		}
+
+		pending.Wait()
+
+		// Remote tiles that were interrupted are not marked as rendered, so the pass is only complete if
+		// rendering was not stopped.
+		if ctx.Err() != nil {
+			r.logStopped(ctx, pass, numPasses)
+			return
+		}
+
+		r.tiles.nextPass()
+
+		if r.targetNoise > 0 {
+			if noise == nil {
+				noise = newNoiseEstimator(r.canvas, r.samplesAfter(pass+1), sampler.IsSpectral(r.samplerType))
+				continue
+			}
+
+			noise.addPass(r.canvas, r.samplesAfter(pass+1))
+			level := noise.estimate()
+			log.Debugf("Estimated noise level after pass %v of %v: %.4f", pass+1, numPasses, level)
+			if level <= r.targetNoise {
+				log.Infof("Target noise level %v reached after pass %v of %v", r.targetNoise, pass+1, numPasses)
+				return
+			}
+		}
+	}
+}
+
+// logStopped reports why rendering stopped during the given pass.
+func (r *RendererImpl) logStopped(ctx context.Context, pass int, numPasses int) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Infof("Time limit of %v reached during pass %v of %v", r.timeLimit, pass+1, numPasses)
+		return
+	}
+
+	log.Infof("Rendering stopped during pass %v of %v", pass+1, numPasses)
+}
+
+// numPasses returns the number of passes needed to reach the requested number of samples per pixel.
+func (r *RendererImpl) numPasses() int {
+	if r.passSamples <= 0 {
+		return 1
+	}
+
+	return (r.numSamples + r.passSamples - 1) / r.passSamples
+}
+
+// passSampleCount returns the number of samples per pixel rendered in the given pass.
+func (r *RendererImpl) passSampleCount(pass int) int {
+	if r.passSamples <= 0 {
+		return r.numSamples
+	}
+
+	return min(r.passSamples, r.numSamples-pass*r.passSamples)
+}
+
+// samplesAfter returns the number of samples per pixel accumulated once the given number of passes are complete.
+func (r *RendererImpl) samplesAfter(passes int) int {
+	if r.passSamples <= 0 {
+		return min(passes, 1) * r.numSamples
+	}
+
+	return min(passes*r.passSamples, r.numSamples)
+}
+
+// SampleCounts returns the number of samples taken for every pixel, stored row by row.
+// It returns nil unless adaptive sampling or progressive rendering is enabled.
+func (r *RendererImpl) SampleCounts() []int {
+	return r.sampleCounts
+}
+
+// accumulatePixel stores the mean of n new samples for the pixel at canvas position (x, y).
+// When sample counts are being tracked the new samples are blended with the ones already accumulated
+// for that pixel. It returns the resulting pixel value.
+func accumulatePixel(canvas *floatimage.Float64NRGBA, counts []int, x, y, n int, col colour.Float64NRGBA) colour.Float64NRGBA {
+	nx := canvas.Bounds().Max.X
+	ny := canvas.Bounds().Max.Y
+	if x < 0 || x >= nx || y < 0 || y >= ny {
+		return col
+	}
+
+	if counts != nil && n > 0 {
+		i := y*nx + x
+		prev := counts[i]
+		if prev > 0 {
+			old := canvas.Float64NRGBAAt(x, y)
+			t := float64(n) / float64(prev+n)
+			col = colour.Float64NRGBA{
+				R: old.R + (col.R-old.R)*t,
+				G: old.G + (col.G-old.G)*t,
+				B: old.B + (col.B-old.B)*t,
+				A: old.A + (col.A-old.A)*t,
+			}
+		}
+		counts[i] = prev + n
+	}
+
+	canvas.Set(x, y, col)
+
+	return col
+}
@@ -0,0 +1,312 @@
+package render
+
+import (
+	"context"
+	"image"
+	"math"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flynn-nrg/floatimage/colour"
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/aov"
+	"github.com/flynn-nrg/izpi/internal/checkpoint"
+	"github.com/flynn-nrg/izpi/internal/colours"
+	"github.com/flynn-nrg/izpi/internal/filter"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/sampler"
+	"github.com/flynn-nrg/izpi/internal/scene"
+	"github.com/flynn-nrg/izpi/internal/scenes"
+	"github.com/flynn-nrg/izpi/internal/sequence"
+	"github.com/flynn-nrg/izpi/internal/transport"
+)
+
+func TestAccumulatePixel(t *testing.T) {
+	testData := []struct {
+		name   string
+		counts []int
+		passes []float64
+		n      int
+		want   float64
+	}{
+		{
+			name:   "no sample counts overwrites the pixel",
+			passes: []float64{1.0, 3.0},
+			n:      4,
+			want:   3.0,
+		},
+		{
+			name:   "equal passes are averaged",
+			counts: make([]int, 4),
+			passes: []float64{1.0, 3.0},
+			n:      4,
+			want:   2.0,
+		},
+		{
+			name:   "three passes",
+			counts: make([]int, 4),
+			passes: []float64{0.0, 0.0, 3.0},
+			n:      8,
+			want:   1.0,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			canvas := floatimage.NewFloat64NRGBA(image.Rect(0, 0, 2, 2), make([]float64, 2*2*4))
+			var got colour.Float64NRGBA
+			for _, v := range test.passes {
+				got = accumulatePixel(canvas, test.counts, 1, 0, test.n, colour.Float64NRGBA{R: v, G: v, B: v, A: 1.0})
+			}
+
+			if math.Abs(got.R-test.want) > 1e-12 {
+				t.Errorf("accumulatePixel() = %v, want %v", got.R, test.want)
+			}
+
+			if stored := canvas.Float64NRGBAAt(1, 0); stored != got {
+				t.Errorf("canvas holds %+v, want %+v", stored, got)
+			}
+
+			if test.counts != nil && test.counts[1] != test.n*len(test.passes) {
+				t.Errorf("sample count = %v, want %v", test.counts[1], test.n*len(test.passes))
+			}
+		})
+	}
+}
+
+func TestNumPasses(t *testing.T) {
+	testData := []struct {
+		numSamples  int
+		passSamples int
+		want        int
+	}{
+		{numSamples: 64, passSamples: 0, want: 1},
+		{numSamples: 64, passSamples: 16, want: 4},
+		{numSamples: 65, passSamples: 16, want: 5},
+		{numSamples: 8, passSamples: 16, want: 1},
+	}
+
+	for _, test := range testData {
+		r := &RendererImpl{numSamples: test.numSamples, passSamples: test.passSamples}
+		if got := r.numPasses(); got != test.want {
+			t.Errorf("numPasses() with %v samples and %v per pass = %v, want %v", test.numSamples, test.passSamples, got, test.want)
+		}
+	}
+}
+
+func TestNoiseEstimator(t *testing.T) {
+	testData := []struct {
+		name       string
+		spectral   bool
+		passValues []float64
+		want       float64
+	}{
+		{
+			name:       "single pass",
+			passValues: []float64{1.0},
+			want:       math.Inf(1),
+		},
+		{
+			name:       "converged",
+			passValues: []float64{0.5, 0.5, 0.5, 0.5},
+			want:       0.0,
+		},
+		{
+			name:       "two passes",
+			passValues: []float64{1.0, 0.8},
+			want:       0.1 / 0.9,
+		},
+		{
+			name:       "spectral",
+			spectral:   true,
+			passValues: []float64{1.0, 0.8},
+			want:       0.1 / 0.9,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			canvas := floatimage.NewFloat64NRGBA(image.Rect(0, 0, 1, 1), make([]float64, 4))
+			counts := make([]int, 1)
+			e := newNoiseEstimator(canvas, 0, test.spectral)
+			for i, v := range test.passValues {
+				accumulatePixel(canvas, counts, 0, 0, 16, colour.Float64NRGBA{R: v, G: v, B: v, A: 1.0})
+				e.addPass(canvas, (i+1)*16)
+			}
+
+			got := e.estimate()
+			if math.IsInf(test.want, 1) {
+				if !math.IsInf(got, 1) {
+					t.Errorf("estimate() = %v, want +Inf", got)
+				}
+				return
+			}
+
+			if math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("estimate() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderReproducible(t *testing.T) {
+	const size = 16
+
+	render := func(numWorkers int, passSamples int, samplerType sampler.SamplerType, generator sequence.GeneratorType,
+		f filter.FilterType, seed uint64) []float64 {
+		depth := sampler.Depth{Max: 10, Diffuse: 10, Specular: 10, Transmission: 10, Volume: 10, RussianRoulette: 3}
+		r := New(scenes.CornellBox(1), size, size, 8, depth, colours.Black, colours.White, colours.SpectralBlack,
+			numWorkers, nil, false, nil, false, samplerType, nil, passSamples)
+		r.SetSampleGenerator(generator, seed)
+		r.SetPhotonConfig(sampler.PhotonConfig{Seed: seed, Photons: 5000})
+		r.SetFilter(f, 0)
+		return r.Render(context.Background()).(*floatimage.Float64NRGBA).Pix
+	}
+
+	testData := []struct {
+		name        string
+		sampler     sampler.SamplerType
+		generator   sequence.GeneratorType
+		numWorkers  int
+		passSamples int
+		filter      filter.FilterType
+	}{
+		{name: "independent", sampler: sampler.ColourSampler, generator: sequence.IndependentGenerator, numWorkers: 3, filter: filter.BoxFilter},
+		{name: "sobol in progressive passes", sampler: sampler.ColourSampler, generator: sequence.SobolGenerator, numWorkers: 2, passSamples: 3, filter: filter.BoxFilter},
+		{name: "mitchell filter", sampler: sampler.ColourSampler, generator: sequence.HaltonGenerator, numWorkers: 3, filter: filter.MitchellFilter},
+		{name: "bdpt light paths", sampler: sampler.BDPTSampler, generator: sequence.IndependentGenerator, numWorkers: 3, filter: filter.BoxFilter},
+		{name: "photon mapping", sampler: sampler.PPMSampler, generator: sequence.IndependentGenerator, numWorkers: 3, filter: filter.BoxFilter},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			want := render(1, 0, test.sampler, test.generator, test.filter, 7)
+
+			// The number of workers, the order in which tiles are rendered and splitting the samples into passes
+			// must not change the result.
+			got := render(test.numWorkers, test.passSamples, test.sampler, test.generator, test.filter, 7)
+			for i := range want {
+				// Progressive passes blend the mean of every pass into the pixel, which rounds differently.
+				if d := math.Abs(got[i] - want[i]); (test.passSamples == 0 && d != 0) || d > 1e-12 {
+					t.Fatalf("value %v = %v, want %v", i, got[i], want[i])
+				}
+			}
+
+			if slices.Equal(want, render(1, 0, test.sampler, test.generator, test.filter, 8)) {
+				t.Errorf("renders with different seeds are identical")
+			}
+		})
+	}
+}
+
+func TestRenderSpectralAOVs(t *testing.T) {
+	const size = 8
+
+	render := func(layout aov.Layout) []float64 {
+		depth := sampler.Depth{Max: 10, Diffuse: 10, Specular: 10, Transmission: 10, Volume: 10, RussianRoulette: 3}
+		scene, err := transport.NewTransport(1, scenes.CornellBoxWaterSpectral(1), nil, nil, nil, nil, 1).ToScene()
+		if err != nil {
+			t.Fatalf("ToScene() = %v", err)
+		}
+		scene.Exposure = 1
+		r := New(scene, size, size, 4, depth, colours.Black, colours.White, colours.SpectralBlack,
+			1, nil, false, nil, false, sampler.SpectralSampler, nil, 0)
+		r.EnableAOVs(layout)
+		return r.Render(context.Background()).(*floatimage.Float64NRGBA).Pix
+	}
+
+	// Rendering AOVs must not change how the image is sampled, so it is still traced at hero wavelengths.
+	want := render(aov.Layout{})
+	got := render(aov.Layout{Types: []aov.Type{aov.Albedo, aov.DiffuseDirect}})
+	if !slices.Equal(got, want) {
+		t.Errorf("rendering AOVs changed the image")
+	}
+}
+
+// cancelAfter is a hitable that calls cancel once it has been hit n times, so that renders can be stopped at a
+// given point. It hits like the hitable it wraps.
+type cancelAfter struct {
+	hitable.Hitable
+	calls  atomic.Int64
+	n      int64
+	cancel func()
+}
+
+func (c *cancelAfter) Hit(r ray.Ray, tMin float64, tMax float64) (*hitrecord.HitRecord, material.Material, bool) {
+	if c.calls.Add(1) == c.n {
+		c.cancel()
+	}
+
+	return c.Hitable.Hit(r, tMin, tMax)
+}
+
+func TestRenderResume(t *testing.T) {
+	const size = 16
+
+	render := func(ctx context.Context, samplerType sampler.SamplerType, f filter.FilterType, passSamples int,
+		h *cancelAfter, fileName string, state *checkpoint.State) []float64 {
+		depth := sampler.Depth{Max: 10, Diffuse: 10, Specular: 10, Transmission: 10, Volume: 10, RussianRoulette: 3}
+		box := scenes.CornellBox(1)
+		hitables := slices.Clone(box.World.Hitables())
+		h.Hitable = hitables[0]
+		hitables[0] = h
+		r := New(scene.New(hitable.NewSlice(hitables), box.Lights, box.Camera), size, size, 8, depth, colours.Black,
+			colours.White, colours.SpectralBlack, 1, nil, false, nil, false, samplerType, nil, passSamples)
+		r.SetSampleGenerator(sequence.IndependentGenerator, 7)
+		r.SetFilter(f, 0)
+		r.EnableCheckpoints(fileName, 0)
+		if state != nil {
+			if err := r.Resume(state); err != nil {
+				t.Fatalf("Resume() = %v", err)
+			}
+		}
+		return r.Render(ctx).(*floatimage.Float64NRGBA).Pix
+	}
+
+	testData := []struct {
+		name        string
+		sampler     sampler.SamplerType
+		filter      filter.FilterType
+		passSamples int
+	}{
+		{name: "progressive passes", sampler: sampler.ColourSampler, filter: filter.BoxFilter, passSamples: 3},
+		{name: "mitchell filter", sampler: sampler.ColourSampler, filter: filter.MitchellFilter},
+		{name: "bdpt light paths", sampler: sampler.BDPTSampler, filter: filter.BoxFilter},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "render.ckpt")
+
+			uninterrupted := &cancelAfter{cancel: func() {}}
+			want := render(context.Background(), test.sampler, test.filter, test.passSamples, uninterrupted, fileName, nil)
+
+			// Stopping halfway through the render interrupts a tile partway through, which must be rendered again
+			// from scratch once the render is resumed.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			interrupted := &cancelAfter{n: uninterrupted.calls.Load() / 2, cancel: cancel}
+			render(ctx, test.sampler, test.filter, test.passSamples, interrupted, fileName, nil)
+
+			state, err := checkpoint.Load(fileName)
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			if !slices.Contains(state.CompletedTiles, false) {
+				t.Fatalf("the render was not interrupted")
+			}
+
+			got := render(context.Background(), test.sampler, test.filter, test.passSamples, &cancelAfter{cancel: func() {}}, fileName, state)
+			for i := range want {
+				if d := math.Abs(got[i] - want[i]); d > 1e-12 {
+					t.Fatalf("value %v = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package render
+
+import (
+	"image"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/flynn-nrg/floatimage/colour"
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/flynn-nrg/izpi/internal/display"
+	"github.com/flynn-nrg/izpi/internal/filter"
+	"github.com/flynn-nrg/izpi/internal/sampler"
+)
+
+// tileResult holds what a work unit adds to pixels beyond its own: the values of its tile film, which covers
+// filmBounds, and the light carried to the camera by its light paths, as returned by the methods of
+// sampler.TileLightImage. Work units that lost them, such as remote tiles that failed, leave an empty result so
+// that the results of the work units sent after them can still be merged.
+type tileResult struct {
+	filmBounds  image.Rectangle
+	film        []float64
+	lightPixels []uint32
+	light       []float64
+	lightPaths  uint64
+}
+
+// newTileResult returns the result of a work unit from its tile film and tile light image, either of which may be
+// nil.
+func newTileResult(film *filter.Film, light *sampler.TileLightImage) tileResult {
+	var result tileResult
+	if film != nil {
+		result.filmBounds = film.Bounds()
+		result.film = film.Data()
+	}
+	if light != nil {
+		result.lightPixels = light.Pixels()
+		result.light = light.Values()
+		result.lightPaths = light.Paths()
+	}
+
+	return result
+}
+
+// tileResults merges the results of work units into the image film and the light image. Neighbouring tiles add
+// up their results on the pixels they share, so results are merged in the order their work units were sent rather
+// than the order they finish in, which makes the sums round the same way on every render. Results that arrive
+// early wait in pending until the ones before them are merged.
+type tileResults struct {
+	mu      sync.Mutex
+	next    int
+	pending map[int]tileResult
+}
+
+func newTileResults() *tileResults {
+	return &tileResults{
+		pending: make(map[int]tileResult),
+	}
+}
+
+// snapshot returns the values of the image film and the light image, either of which may be nil, together with
+// those of the results still waiting to be merged. pixels are the pixels of the canvas, which are updated with the
+// values of the films of those results.
+func (t *tileResults) snapshot(film *imageFilm, light *sampler.LightImage, pixels *floatimage.Float64NRGBA) ([]float64, []float64, uint64) {
+	var filmCopy *filter.Film
+	if film != nil {
+		filmCopy = filter.NewFilm(film.filter, film.film.Bounds())
+		filmCopy.Merge(film.film.Bounds(), film.film.Data())
+	}
+
+	var lightCopy *sampler.LightImage
+	if light != nil {
+		lightCopy = sampler.NewLightImage(pixels.Bounds().Dx(), pixels.Bounds().Dy())
+		lightCopy.Merge(light.Data(), light.Paths())
+	}
+
+	for _, i := range slices.Sorted(maps.Keys(t.pending)) {
+		t.pending[i].merge(filmCopy, lightCopy, pixels)
+	}
+
+	var filmData, lightData []float64
+	var lightPaths uint64
+	if filmCopy != nil {
+		filmData = filmCopy.Data()
+	}
+	if lightCopy != nil {
+		lightData = lightCopy.Data()
+		lightPaths = lightCopy.Paths()
+	}
+
+	return filmData, lightData, lightPaths
+}
+
+// merge adds the result to film and light, either of which may be nil, and updates the pixels of canvas covered
+// by the tile film. It returns whether any pixels were updated.
+func (result tileResult) merge(film *filter.Film, light *sampler.LightImage, canvas *floatimage.Float64NRGBA) bool {
+	if light != nil {
+		light.MergeTile(result.lightPixels, result.light, result.lightPaths)
+	}
+
+	if film == nil || result.film == nil {
+		return false
+	}
+
+	film.Merge(result.filmBounds, result.film)
+	setPixels(canvas, film, result.filmBounds)
+
+	return true
+}
+
+// TileLightSampler returns a sampler that splats the light that light paths carry to the camera to a light image
+// of its own for a tile of an image of nx by ny pixels, together with that light image. Samplers that do not trace
+// light paths are returned as they are, with no light image.
+func TileLightSampler(s sampler.Sampler, nx, ny int) (sampler.Sampler, *sampler.TileLightImage) {
+	ls, ok := s.(sampler.LightPathSampler)
+	if !ok {
+		return s, nil
+	}
+
+	light := sampler.NewTileLightImage(nx, ny)
+
+	return ls.WithLightImage(light), light
+}
+
+// hasResult returns whether the work unit adds to pixels beyond its own, in which case it must merge its result
+// exactly once.
+func (w workUnit) hasResult() bool {
+	return w.film != nil || w.lightImage != nil
+}
+
+// tilePixels holds the pixels of a work unit until its whole tile is rendered, so that tiles that are interrupted
+// leave the canvas, the sample counts and the AOVs untouched and are rendered from scratch when the render is
+// resumed.
+type tilePixels struct {
+	pixels []tilePixel
+	aovs   []float64
+}
+
+// tilePixel is the mean of n samples of the pixel at canvas position (x, y). The mean of its AOVs ends at aovs in
+// tilePixels.aovs, right where those of the pixel before it end.
+type tilePixel struct {
+	x, y, n int
+	col     colour.Float64NRGBA
+	aovs    int
+}
+
+// add stores the mean of n new samples for the pixel at canvas position (x, y) along with the mean of its AOVs.
+func (t *tilePixels) add(x, y, n int, col colour.Float64NRGBA, aovValues []float64) {
+	t.aovs = append(t.aovs, aovValues...)
+	t.pixels = append(t.pixels, tilePixel{x: x, y: y, n: n, col: col, aovs: len(t.aovs)})
+}
+
+// finish accumulates the pixels of the work unit, merges its result and marks its tile as rendered, all while
+// checkpoints are held off so that they contain either the whole tile or none of it. Tiles that were not fully
+// rendered leave no trace, but still merge an empty result so that the results of the work units sent after them
+// can be merged.
+func (w workUnit) finish(rendered bool, pixels *tilePixels, result tileResult) {
+	if !rendered {
+		pixels, result = &tilePixels{}, tileResult{}
+	}
+
+	var tiles []display.DisplayTile
+	w.tiles.beginUpdate()
+	start := 0
+	for _, p := range pixels.pixels {
+		w.accumulate(p.x, p.y, p.n, p.col, pixels.aovs[start:p.aovs])
+		start = p.aovs
+	}
+	if w.hasResult() {
+		tiles = w.mergeResult(result)
+	}
+	if rendered {
+		w.tiles.complete(w.tile)
+		if w.preview && w.film == nil {
+			ny := w.canvas.Bounds().Max.Y
+			tiles = append(tiles, w.previewRows(image.Rect(w.x0, ny-w.y1, w.x1+1, ny-w.y0+1).Intersect(w.canvas.Bounds()))...)
+		}
+	}
+	w.tiles.endUpdate()
+
+	for _, tile := range tiles {
+		w.previewChan <- tile
+	}
+
+	if w.pending != nil {
+		w.pending.Done()
+	}
+}
+
+// mergeResult merges the result of the work unit once the results of the work units sent before it are merged,
+// and updates the pixels of the canvas covered by their tile films. It returns the rows of those pixels to be
+// previewed.
+func (w workUnit) mergeResult(result tileResult) []display.DisplayTile {
+	var film *filter.Film
+	if w.film != nil {
+		film = w.film.film
+	}
+
+	w.results.mu.Lock()
+	defer w.results.mu.Unlock()
+	w.results.pending[w.resultIndex] = result
+
+	var tiles []display.DisplayTile
+	for {
+		r, ok := w.results.pending[w.results.next]
+		if !ok {
+			break
+		}
+		delete(w.results.pending, w.results.next)
+		w.results.next++
+
+		if r.merge(film, w.lightImage, w.canvas) && w.preview {
+			tiles = append(tiles, w.previewRows(r.filmBounds)...)
+		}
+	}
+
+	return tiles
+}
@@ -1,70 +1,131 @@
 package render
 
 import (
+	"context"
 	"sync"
 
 	"github.com/flynn-nrg/floatimage/colour"
-	"github.com/flynn-nrg/izpi/internal/display"
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/filter"
+	"github.com/flynn-nrg/izpi/internal/sampler"
+	"github.com/flynn-nrg/izpi/internal/scene"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
-func renderRectRGB(w workUnit, random *fastrandom.LCG) {
-	var tile display.DisplayTile
-
+// renderRectRGB renders the work unit and returns its pixels and result, and whether the whole tile was rendered
+// before ctx was cancelled.
+func renderRectRGB(ctx context.Context, w workUnit, random *fastrandom.LCG) (bool, *tilePixels, tileResult) {
 	nx := w.canvas.Bounds().Max.X
 	ny := w.canvas.Bounds().Max.Y
 
-	if w.preview {
-		tile = display.DisplayTile{
-			Width:  w.x1 - w.x0 + 1,
-			Height: 1,
-			PosX:   w.x0,
-			Pixels: make([]float64, (w.x1-w.x0+1)*4),
-		}
-	}
+	PreparePass(w.sampler, w.firstSample)
+
+	px := w.newAOVPixel()
+	var aovValues []float64
+	pixels := &tilePixels{}
+
+	film := w.newTileFilm()
+	s, light := TileLightSampler(w.sampler, nx, ny)
 
 	for y := w.y0; y <= w.y1; y++ {
-		i := 0
-		tile.PosY = ny - y
+		if ctx.Err() != nil {
+			return false, nil, tileResult{}
+		}
+
 		for x := w.x0; x <= w.x1; x++ {
-			col := vec3.Vec3Impl{}
-			for s := 0; s < w.numSamples; s++ {
-				u := (float64(x) + random.Float64()) / float64(nx)
-				v := (float64(y) + random.Float64()) / float64(ny)
-				r := w.scene.Camera.GetRay(u, v)
-				col = vec3.Add(col, vec3.DeNAN(w.sampler.Sample(r, w.scene.World, w.scene.Lights, 0, random)))
+			var col vec3.Vec3Impl
+			n := w.numSamples
+			px.Reset()
+			switch cs, ok := s.(sampler.ChainSampler); {
+			case ok:
+				// Chains splat their light to the light image, which is added once rendering is done.
+				RunPixelChain(cs, w.numSamples, w.firstSample, x, y, nx)
+			case w.adaptive != nil:
+				col, n = RenderPixelRGBAdaptive(w.adaptive, w.firstSample, x, y, nx, ny, w.scene, s, random, px, film)
+			default:
+				col = RenderPixelRGB(w.numSamples, w.firstSample, x, y, nx, ny, w.scene, s, random, px, film)
 			}
 
-			// Linear colour space.
-			col = vec3.ScalarDiv(col, float64(w.numSamples))
-			w.canvas.Set(x, ny-y, colour.Float64NRGBA{R: col.X, G: col.Y, B: col.Z, A: 1.0})
-			if w.preview {
-				tile.Pixels[i] = col.Z
-				tile.Pixels[i+1] = col.Y
-				tile.Pixels[i+2] = col.X
-				tile.Pixels[i+3] = 1.0
-				i += 4
-			}
-		}
-		if w.preview {
-			w.previewChan <- tile
+			aovValues = px.Mean(aovValues[:0])
+			pixels.add(x, ny-y, n, colour.Float64NRGBA{R: col.X, G: col.Y, B: col.Z, A: 1.0}, aovValues)
 		}
 	}
 	if w.verbose {
 		w.bar.Increment()
 	}
+
+	// Samples and light paths of this tile reach the pixels of its neighbours, so they are merged once it is done.
+	return true, pixels, newTileResult(film, light)
 }
 
-func workerRGB(input chan workUnit, quit chan struct{}, random *fastrandom.LCG, wg *sync.WaitGroup) {
+func workerRGB(ctx context.Context, input chan workUnit, quit chan struct{}, random *fastrandom.LCG, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
 		select {
 		case w := <-input:
-			renderRectRGB(w, random)
+			w.finish(renderRectRGB(ctx, w, random))
 		case <-quit:
 			return
 		}
 	}
 
 }
+
+// RenderPixelRGB takes numSamples samples of a pixel and returns its linear colour. firstSample is the number of
+// samples already taken for the pixel by previous passes. If aovs is not nil the AOVs of every sample are
+// accumulated into it, and if film is not nil every sample is splatted to it.
+func RenderPixelRGB(numSamples int, firstSample int, x, y, nx, ny int, scene *scene.Scene, sampler sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) vec3.Vec3Impl {
+	col := vec3.Vec3Impl{}
+	for i := range numSamples {
+		col = vec3.Add(col, sampleRGB(firstSample+i, x, y, nx, ny, scene, sampler, random, aovs, film))
+	}
+
+	// Linear colour space.
+	return vec3.ScalarDiv(col, float64(numSamples))
+}
+
+// RenderPixelRGBAdaptive samples a pixel until its luminance converges or the maximum number of samples is reached.
+// It returns the linear colour of the pixel and the number of samples taken. firstSample is the number of samples
+// already taken for the pixel by previous passes. If aovs is not nil the AOVs of every sample are accumulated into it,
+// and if film is not nil every sample is splatted to it.
+func RenderPixelRGBAdaptive(cfg *adaptive.Config, firstSample int, x, y, nx, ny int, scene *scene.Scene, sampler sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) (vec3.Vec3Impl, int) {
+	var est adaptive.Estimator
+	col := vec3.Vec3Impl{}
+
+	for !est.Done(cfg) {
+		for range est.Next(cfg) {
+			sample := sampleRGB(firstSample+est.Count(), x, y, nx, ny, scene, sampler, random, aovs, film)
+			col = vec3.Add(col, sample)
+			est.Add(adaptive.Luminance(sample.X, sample.Y, sample.Z))
+		}
+	}
+
+	return vec3.ScalarDiv(col, float64(est.Count())), est.Count()
+}
+
+// sampleRGB traces the camera ray of the given sample of the pixel (x, y) and returns its colour.
+// If aovs is not nil the AOVs of the sample are accumulated into it, and if film is not nil the sample is
+// splatted to it.
+func sampleRGB(index int, x, y, nx, ny int, scene *scene.Scene, s sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) vec3.Vec3Impl {
+	random.StartPixelSample(x, y, index)
+	dx := random.Float64()
+	dy := random.Float64()
+	u := (float64(x) + dx) / float64(nx)
+	v := (float64(y) + dy) / float64(ny)
+	r := scene.Camera.GetRaySampled(u, v, 0, random)
+
+	var col vec3.Vec3Impl
+	if aovs == nil {
+		col = vec3.DeNAN(s.Sample(r, scene.World, scene.Lights, 0, random))
+	} else {
+		sample := aovs.Sample()
+		col = vec3.DeNAN(s.(sampler.AOVSampler).SampleAOV(r, scene.World, scene.Lights, random, sample))
+		aovs.Add(sample)
+	}
+
+	splat(film, x, y, ny, dx, dy, [3]float64{col.X, col.Y, col.Z})
+
+	return col
+}
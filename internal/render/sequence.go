@@ -0,0 +1,27 @@
+package render
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/sequence"
+)
+
+// SetSampleGenerator sets the generator of the numbers used to sample pixels and the seed that scrambles them.
+func (r *RendererImpl) SetSampleGenerator(t sequence.GeneratorType, seed uint64) {
+	r.generator = t
+	r.generatorSeed = seed
+}
+
+// newRandom returns the source of the numbers drawn by a local worker.
+func (r *RendererImpl) newRandom() *fastrandom.LCG {
+	numSamples := r.numSamples
+	if r.adaptive != nil {
+		numSamples = r.adaptive.MaxSamples
+	}
+
+	return sequence.NewLCG(r.generator, sequence.Config{
+		Width:           r.sizeX,
+		Height:          r.sizeY,
+		SamplesPerPixel: numSamples,
+		Seed:            r.generatorSeed,
+	})
+}
@@ -1,99 +1,92 @@
 package render
 
 import (
+	"context"
 	"sync"
 
 	"github.com/flynn-nrg/floatimage/colour"
-	"github.com/flynn-nrg/izpi/internal/display"
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/filter"
+	"github.com/flynn-nrg/izpi/internal/ray"
 	"github.com/flynn-nrg/izpi/internal/sampler"
 	"github.com/flynn-nrg/izpi/internal/scene"
 	"github.com/flynn-nrg/izpi/internal/spectral"
 )
 
-func renderRectSpectral(w workUnit, random *fastrandom.LCG) {
-	var tile display.DisplayTile
-
+// renderRectSpectral renders the work unit and returns its pixels and result, and whether the whole tile was
+// rendered before ctx was cancelled.
+func renderRectSpectral(ctx context.Context, w workUnit, random *fastrandom.LCG) (bool, *tilePixels, tileResult) {
 	nx := w.canvas.Bounds().Max.X
 	ny := w.canvas.Bounds().Max.Y
 
-	if w.preview {
-		tile = display.DisplayTile{
-			Width:  w.x1 - w.x0 + 1,
-			Height: 1,
-			PosX:   w.x0,
-			Pixels: make([]float64, (w.x1-w.x0+1)*4),
-		}
-	}
+	PreparePass(w.sampler, w.firstSample)
+
+	px := w.newAOVPixel()
+	var aovValues []float64
+	pixels := &tilePixels{}
+
+	film := w.newTileFilm()
+	s, light := TileLightSampler(w.sampler, nx, ny)
 
 	for y := w.y0; y <= w.y1; y++ {
-		i := 0
-		tile.PosY = ny - y
+		if ctx.Err() != nil {
+			return false, nil, tileResult{}
+		}
+
 		for x := w.x0; x <= w.x1; x++ {
-			cieX, cieY, cieZ := RenderPixelSpectral(w.numSamples, x, y, nx, ny, w.scene, w.sampler, random)
+			var cieX, cieY, cieZ float64
+			n := w.numSamples
+			px.Reset()
+			switch cs, ok := s.(sampler.ChainSampler); {
+			case ok:
+				// Chains splat their light to the light image, which is added once rendering is done.
+				RunPixelChain(cs, w.numSamples, w.firstSample, x, y, nx)
+			case w.adaptive != nil:
+				cieX, cieY, cieZ, n = RenderPixelSpectralAdaptive(w.adaptive, w.firstSample, x, y, nx, ny, w.scene, s, random, px, film)
+			default:
+				cieX, cieY, cieZ = RenderPixelSpectral(w.numSamples, w.firstSample, x, y, nx, ny, w.scene, s, random, px, film)
+			}
 
 			// Canvas information is in CIE XYZ space.
-			w.canvas.Set(x, ny-y, colour.Float64NRGBA{R: cieX, G: cieY, B: cieZ, A: 1.0})
-
-			if w.preview {
-				// Apply exposure and convert to ACEScg for preview
-				exposure := w.scene.Exposure
-				r, g, b := spectral.XYZToACEScg(cieX*exposure, cieY*exposure, cieZ*exposure)
-
-				tile.Pixels[i] = b
-				tile.Pixels[i+1] = g
-				tile.Pixels[i+2] = r
-				tile.Pixels[i+3] = 1.0
-				i += 4
-			}
-		}
-		if w.preview {
-			w.previewChan <- tile
+			aovValues = px.Mean(aovValues[:0])
+			pixels.add(x, ny-y, n, colour.Float64NRGBA{R: cieX, G: cieY, B: cieZ, A: 1.0}, aovValues)
 		}
 	}
 	if w.verbose {
 		w.bar.Increment()
 	}
+
+	// Samples and light paths of this tile reach the pixels of its neighbours, so they are merged once it is done.
+	return true, pixels, newTileResult(film, light)
 }
 
-func workerSpectral(input chan workUnit, quit chan struct{}, random *fastrandom.LCG, wg *sync.WaitGroup) {
+func workerSpectral(ctx context.Context, input chan workUnit, quit chan struct{}, random *fastrandom.LCG, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
 		select {
 		case w := <-input:
-			renderRectSpectral(w, random)
+			w.finish(renderRectSpectral(ctx, w, random))
 		case <-quit:
 			return
 		}
 	}
 }
 
-func RenderPixelSpectral(numSamples int, x, y, nx, ny int, scene *scene.Scene, sampler sampler.Sampler, random *fastrandom.LCG) (float64, float64, float64) {
+// RenderPixelSpectral takes numSamples samples of a pixel at stochastically chosen wavelengths and returns
+// its CIE XYZ values. firstSample is the number of samples already taken for the pixel by previous passes.
+// If aovs is not nil the AOVs of every sample are accumulated into it, and if film is not nil every sample is
+// splatted to it.
+func RenderPixelSpectral(numSamples int, firstSample int, x, y, nx, ny int, scene *scene.Scene, sampler sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) (float64, float64, float64) {
 	// Initialize XYZ accumulators for the pixel
 	var sumX, sumY, sumZ float64
 
-	for range numSamples {
-		// Importance sample a wavelength AND its PDF
-		lambda, pdf := spectral.SampleWavelength(random.Float64())
-		if pdf == 0 {
-			continue
-		}
-
-		// Get camera ray for this specific wavelength
-		u := (float64(x) + random.Float64()) / float64(nx)
-		v := (float64(y) + random.Float64()) / float64(ny)
-		r := scene.Camera.GetRayWithLambda(u, v, lambda)
-
-		// Trace the path to get radiance at this wavelength
-		radiance := sampler.SampleSpectral(r, scene.World, scene.Lights, 0, random)
-
-		// Convert sample to an XYZ contribution and add it to the pixel's
-		// accumulators using the unbiased estimator.
-		cieX_val, cieY_val, cieZ_val := spectral.GetCIEValues(lambda)
-
-		sumX += (radiance * cieX_val) / pdf
-		sumY += (radiance * cieY_val) / pdf
-		sumZ += (radiance * cieZ_val) / pdf
+	for i := range numSamples {
+		cieX, cieY, cieZ := sampleSpectral(firstSample+i, x, y, nx, ny, scene, sampler, random, aovs, film)
+		sumX += cieX
+		sumY += cieY
+		sumZ += cieZ
 	}
 
 	// Average the accumulated XYZ values
@@ -104,3 +97,120 @@ func RenderPixelSpectral(numSamples int, x, y, nx, ny int, scene *scene.Scene, s
 
 	return finalX, finalY, finalZ
 }
+
+// RenderPixelSpectralAdaptive samples a pixel until its CIE Y value converges or the maximum number of samples is reached.
+// It returns the CIE XYZ values of the pixel and the number of samples taken. firstSample is the number of samples
+// already taken for the pixel by previous passes. If aovs is not nil the AOVs of every sample are accumulated into it,
+// and if film is not nil every sample is splatted to it.
+func RenderPixelSpectralAdaptive(cfg *adaptive.Config, firstSample int, x, y, nx, ny int, scene *scene.Scene, sampler sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) (float64, float64, float64, int) {
+	var est adaptive.Estimator
+	var sumX, sumY, sumZ float64
+
+	for !est.Done(cfg) {
+		for range est.Next(cfg) {
+			cieX, cieY, cieZ := sampleSpectral(firstSample+est.Count(), x, y, nx, ny, scene, sampler, random, aovs, film)
+			sumX += cieX
+			sumY += cieY
+			sumZ += cieZ
+
+			// Convergence is tracked on the luminance (Y) of each sample.
+			est.Add(cieY)
+		}
+	}
+
+	invNumSamples := 1.0 / float64(est.Count())
+
+	return sumX * invNumSamples, sumY * invNumSamples, sumZ * invNumSamples, est.Count()
+}
+
+// sampleSpectral traces the camera ray of the given sample of the pixel (x, y) at a wavelength chosen by importance
+// sampling and returns its contribution to the CIE XYZ values of the pixel.
+// If aovs is not nil the AOVs of the sample are accumulated into it, and if film is not nil the sample is
+// splatted to it.
+func sampleSpectral(index int, x, y, nx, ny int, scene *scene.Scene, s sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) (float64, float64, float64) {
+	random.StartPixelSample(x, y, index)
+
+	// The position within the pixel is drawn first, as the first dimensions of low-discrepancy sequences are
+	// the best distributed ones.
+	dx := random.Float64()
+	dy := random.Float64()
+	u := (float64(x) + dx) / float64(nx)
+	v := (float64(y) + dy) / float64(ny)
+	r := scene.Camera.GetRaySampled(u, v, 0, random)
+
+	cieX, cieY, cieZ := traceSpectral(r, scene, s, random, aovs)
+	splat(film, x, y, ny, dx, dy, [3]float64{cieX, cieY, cieZ})
+
+	return cieX, cieY, cieZ
+}
+
+// traceSpectral traces the camera ray r at a wavelength chosen by importance sampling and returns its
+// contribution to the CIE XYZ values of the pixel. If aovs is not nil the AOVs of the sample are accumulated into it.
+func traceSpectral(r *ray.RayImpl, scene *scene.Scene, s sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel) (float64, float64, float64) {
+	if _, ok := s.(sampler.HeroSampler); ok {
+		if _, ok := s.(sampler.HeroAOVSampler); ok || aovs == nil {
+			return sampleHeroWavelengths(r, scene, s, random, aovs)
+		}
+	}
+
+	// Importance sample a wavelength AND its PDF
+	lambda, pdf := spectral.SampleWavelength(random.Float64())
+	if pdf == 0 {
+		return 0, 0, 0
+	}
+	r.SetLambda(lambda)
+
+	cieX, cieY, cieZ := spectral.GetCIEValues(lambda)
+
+	// Trace the path to get radiance at this wavelength
+	var radiance float64
+	if aovs == nil {
+		radiance = s.SampleSpectral(r, scene.World, scene.Lights, 0, random)
+	} else {
+		sample := aovs.Sample()
+		radiance = s.(sampler.SpectralAOVSampler).SampleSpectralAOV(r, scene.World, scene.Lights, random, sample)
+		sample.ResolveSpectral(cieX/pdf, cieY/pdf, cieZ/pdf, 1/spectral.CIEYIntegral())
+		aovs.Add(sample)
+	}
+
+	// Convert sample to an XYZ contribution using the unbiased estimator.
+	return (radiance * cieX) / pdf, (radiance * cieY) / pdf, (radiance * cieZ) / pdf
+}
+
+// sampleHeroWavelengths traces the camera ray r at a set of wavelengths chosen by hero wavelength sampling and
+// returns its contribution to the CIE XYZ values of the pixel. If aovs is not nil the AOVs of the sample, which are
+// recorded at the hero wavelength, are accumulated into it.
+func sampleHeroWavelengths(r *ray.RayImpl, scene *scene.Scene, s sampler.Sampler, random *fastrandom.LCG, aovs *aov.Pixel) (float64, float64, float64) {
+	wavelengths := spectral.SampleHeroWavelengths(random.Float64())
+	r.SetWavelengths(wavelengths)
+
+	var radiance [spectral.NumHeroWavelengths]float64
+	if aovs == nil {
+		radiance = s.(sampler.HeroSampler).SampleWavelengths(r, scene.World, scene.Lights, random)
+	} else {
+		sample := aovs.Sample()
+		radiance = s.(sampler.HeroAOVSampler).SampleWavelengthsAOV(r, scene.World, scene.Lights, random, sample)
+
+		// The hero wavelength is chosen in the same way as the wavelength of single wavelength samples.
+		pdf := spectral.WavelengthPDF(wavelengths[0])
+		cieX, cieY, cieZ := spectral.GetCIEValues(wavelengths[0])
+		sample.ResolveSpectral(cieX/pdf, cieY/pdf, cieZ/pdf, 1/spectral.CIEYIntegral())
+		aovs.Add(sample)
+	}
+
+	// Every wavelength is weighted against the others with the balance heuristic.
+	var sumX, sumY, sumZ float64
+	for i, lambda := range wavelengths {
+		pdf := spectral.HeroWavelengthPDF(lambda)
+		if pdf == 0 || radiance[i] == 0 {
+			continue
+		}
+
+		cieX, cieY, cieZ := spectral.GetCIEValues(lambda)
+		sumX += radiance[i] * cieX / pdf
+		sumY += radiance[i] * cieY / pdf
+		sumZ += radiance[i] * cieZ / pdf
+	}
+
+	return sumX, sumY, sumZ
+}
@@ -0,0 +1,775 @@
+package sampler
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flynn-nrg/izpi/internal/camera"
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/pdf"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ Sampler = (*BDPT)(nil)
+var _ Sampler = (*SpectralBDPT)(nil)
+var _ LightPathSampler = (*BDPT)(nil)
+var _ LightPathSampler = (*SpectralBDPT)(nil)
+
+// BDPT is a bidirectional path tracer. For every camera ray it traces a path from the camera and another one
+// from a point chosen on the lights, and connects every vertex of one to every vertex of the other. All the ways
+// of building a path are combined with multiple importance sampling. Light paths that are connected directly to
+// the camera can land on any pixel, so their contribution is splatted to a light image that must be added to the
+// rendered image once rendering is done.
+type BDPT struct {
+	NonSpectral // Embed to get SampleSpectral method
+	bdpt
+}
+
+// SpectralBDPT is the spectral version of BDPT. Paths are traced at the wavelength of the camera ray and the
+// light image holds CIE XYZ values.
+type SpectralBDPT struct {
+	bdpt
+}
+
+// NewBDPT returns a new RGB bidirectional path tracer that starts light paths on the given lights and
+// splats light reaching the camera to image.
+func NewBDPT(depth Depth, background vec3.Vec3Impl, cam *camera.Camera, lights *hitable.HitableSlice, image *LightImage, numRays *uint64) *BDPT {
+	b := &BDPT{
+		NonSpectral: *NewNonSpectral(),
+		bdpt: bdpt{
+			paths:      &sync.Pool{},
+			depth:      depth,
+			numRays:    numRays,
+			background: background,
+			camera:     cam,
+			image:      image,
+		},
+	}
+	b.setLights(lights)
+
+	return b
+}
+
+// NewSpectralBDPT returns a new spectral bidirectional path tracer that starts light paths on the given lights and
+// splats light reaching the camera to image.
+func NewSpectralBDPT(depth Depth, background *spectral.SpectralPowerDistribution, cam *camera.Camera, lights *hitable.HitableSlice, image *LightImage, numRays *uint64) *SpectralBDPT {
+	b := &SpectralBDPT{
+		bdpt: bdpt{
+			paths:              &sync.Pool{},
+			depth:              depth,
+			numRays:            numRays,
+			spectral:           true,
+			spectralBackground: background,
+			camera:             cam,
+			image:              image,
+		},
+	}
+	b.setLights(lights)
+
+	return b
+}
+
+func (b *BDPT) Sample(r ray.Ray, world *hitable.HitableSlice, _ hitable.Hitable, _ int, random *fastrandom.LCG) vec3.Vec3Impl {
+	b.image.addPaths(1)
+	return b.trace(r, world, b.image, random)
+}
+
+func (b *SpectralBDPT) SampleSpectral(r ray.Ray, world *hitable.HitableSlice, _ hitable.Hitable, _ int, random *fastrandom.LCG) float64 {
+	b.image.addPaths(1)
+	return b.trace(r, world, b.image, random).X
+}
+
+// WithLightImage returns a copy of the sampler that splats light to image.
+func (b *BDPT) WithLightImage(image *TileLightImage) Sampler {
+	c := *b
+	c.image = image
+	return &c
+}
+
+// WithLightImage returns a copy of the sampler that splats light to image.
+func (b *SpectralBDPT) WithLightImage(image *TileLightImage) Sampler {
+	c := *b
+	c.image = image
+	return &c
+}
+
+// Sample implements the Sampler interface for RGB rendering by assigning a wavelength to camera rays that lack one,
+// in the same way as Spectral.Sample.
+func (b *SpectralBDPT) Sample(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, depth int, random *fastrandom.LCG) vec3.Vec3Impl {
+	if r.Lambda() == 0.0 {
+		wavelength, _ := spectral.SampleWavelength(random.Float64())
+		r.SetLambda(wavelength)
+	}
+
+	spectralValue := b.SampleSpectral(r, world, lightShape, depth, random)
+	red, green, blue := spectral.WavelengthToRGB(r.Lambda())
+
+	return vec3.Vec3Impl{
+		X: red * spectralValue,
+		Y: green * spectralValue,
+		Z: blue * spectralValue,
+	}
+}
+
+// film receives the light carried to the camera by light paths, at film coordinates given in the same way as
+// to camera.GetRaySampled.
+type film interface {
+	splat(s float64, t float64, c [3]float64)
+}
+
+// bdpt holds the state shared by the RGB and spectral bidirectional path tracers. Spectral values are stored in
+// all three components of vectors so that both can share the same code.
+type bdpt struct {
+	depth              Depth
+	numRays            *uint64
+	spectral           bool
+	background         vec3.Vec3Impl
+	spectralBackground *spectral.SpectralPowerDistribution
+	camera             *camera.Camera
+	image              lightFilm
+	// lights holds the surfaces that light paths start from, which are chosen with a probability proportional to
+	// their area, and cdf the running total of their areas.
+	lights    []hitable.Surface
+	cdf       []float64
+	lightArea float64
+	// lightMaterials holds the materials of the lights, used to tell whether light emitted by a surface hit by
+	// a camera path could have been sampled by a light path.
+	lightMaterials map[material.Material]bool
+	paths          *sync.Pool
+}
+
+// setLights prepares the distribution that light paths start from. Hitables whose surface cannot be sampled,
+// as well as those that do not emit any light, such as glass, are left out.
+func (b *bdpt) setLights(lights *hitable.HitableSlice) {
+	b.lightMaterials = make(map[material.Material]bool)
+	if lights == nil {
+		return
+	}
+
+	random := fastrandom.NewWithSeed(0)
+	for _, h := range lights.Hitables() {
+		s, ok := h.(hitable.Surface)
+		if !ok || s.Area() <= 0 {
+			continue
+		}
+
+		rec, mat := s.SampleSurface(random)
+		n := rec.Normal()
+		probe := ray.NewWithLambda(vec3.Add(rec.P(), n), vec3.ScalarMul(n, -1), 0, 550)
+		if le := b.emitted(probe, rec, mat); le.X == 0 && le.Y == 0 && le.Z == 0 {
+			continue
+		}
+
+		b.lightArea += s.Area()
+		b.lights = append(b.lights, s)
+		b.cdf = append(b.cdf, b.lightArea)
+		b.lightMaterials[mat] = true
+	}
+}
+
+// bsdf describes how light is scattered at a vertex.
+type bsdf struct {
+	attenuation vec3.Vec3Impl
+	specular    bool
+	specularRay ray.Ray
+	pdf         pdf.PDF
+}
+
+type vertexType int
+
+const (
+	cameraVertex vertexType = iota
+	lightVertex
+	surfaceVertex
+)
+
+// vertex is a point along a camera or light path. Densities are stored with respect to the area around the vertex.
+type vertex struct {
+	typ vertexType
+	p   vec3.Vec3Impl
+	// n is the normal of light and surface vertices.
+	n   vec3.Vec3Impl
+	rec *hitrecord.HitRecord
+	mat material.Material
+	// in is the ray that reached a surface vertex.
+	in       ray.Ray
+	bsdf     bsdf
+	scatters bool
+	// beta is the throughput of the path up to the vertex.
+	beta vec3.Vec3Impl
+	// delta is true for vertices that scattered the path in a specular direction.
+	delta bool
+	// pdfFwd is the density of choosing the vertex from the previous one along its own path, and pdfRev the density
+	// of choosing it from the next one if the path had been traced in the opposite direction.
+	pdfFwd float64
+	pdfRev float64
+}
+
+// subpaths holds the vertices of the camera and light paths of a sample, reused across samples.
+type subpaths struct {
+	camera []vertex
+	light  []vertex
+}
+
+// trace returns the light arriving along the camera ray r from all the paths whose last vertex before the camera
+// was found by tracing r, and splats those that reach the camera from the light path to f.
+func (b *bdpt) trace(r ray.Ray, world *hitable.HitableSlice, f film, random *fastrandom.LCG) vec3.Vec3Impl {
+	sp, ok := b.paths.Get().(*subpaths)
+	if !ok {
+		sp = &subpaths{
+			camera: make([]vertex, 0, b.depth.Max+2),
+			light:  make([]vertex, 0, b.depth.Max+1),
+		}
+	}
+	defer b.paths.Put(sp)
+
+	camPath, escaped := b.cameraSubpath(r, world, sp.camera[:0], random)
+	lightPath := b.lightSubpath(r, world, sp.light[:0], random)
+
+	l := escaped
+	for t := 1; t <= len(camPath); t++ {
+		for s := 0; s <= len(lightPath); s++ {
+			depth := s + t - 2
+			if (s == 1 && t == 1) || depth < 0 || depth > b.depth.Max {
+				continue
+			}
+
+			if t == 1 {
+				b.splat(lightPath, camPath, s, r, world, f, random)
+				continue
+			}
+
+			c := b.connect(lightPath, camPath, s, t, r, world)
+			l = vec3.Add(l, c)
+		}
+	}
+
+	return l
+}
+
+// cameraSubpath traces the camera path that starts with r. It also returns the light arriving along the path
+// from the background, which no other strategy can sample.
+func (b *bdpt) cameraSubpath(r ray.Ray, world *hitable.HitableSlice, path []vertex, random *fastrandom.LCG) ([]vertex, vec3.Vec3Impl) {
+	one := vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}
+	path = append(path, vertex{typ: cameraVertex, p: r.Origin(), beta: one})
+
+	_, pdfDir := b.camera.PDF(r)
+	return b.walk(r, world, one, pdfDir, path, b.depth.Max+2, true, random)
+}
+
+// lightSubpath chooses a point on the lights and traces a path from it, with the wavelength and time of r.
+func (b *bdpt) lightSubpath(r ray.Ray, world *hitable.HitableSlice, path []vertex, random *fastrandom.LCG) []vertex {
+	if len(b.lights) == 0 {
+		return path
+	}
+
+	i := sort.SearchFloat64s(b.cdf, random.Float64()*b.lightArea)
+	rec, mat := b.lights[min(i, len(b.lights)-1)].SampleSurface(random)
+
+	// Choosing a light by area and a point uniformly over it is the same as choosing a point over all the lights.
+	pdfPos := 1.0 / b.lightArea
+	v := vertex{
+		typ:    lightVertex,
+		p:      rec.P(),
+		n:      rec.Normal(),
+		rec:    rec,
+		mat:    mat,
+		beta:   vec3.Vec3Impl{X: 1.0 / pdfPos, Y: 1.0 / pdfPos, Z: 1.0 / pdfPos},
+		pdfFwd: pdfPos,
+	}
+	path = append(path, v)
+
+	emission := pdf.NewCosine(v.n)
+	dir := emission.Generate(random)
+	pdfDir := emission.Value(dir)
+	if pdfDir <= 0 {
+		return path
+	}
+
+	out := ray.NewWithLambda(v.p, dir, r.Time(), r.Lambda())
+	le := b.leaving(&v, vec3.UnitVector(dir), out)
+
+	// Le * cos(θ) / (pdfPos * pdfDir)
+	cosine := math.Abs(vec3.Dot(v.n, vec3.UnitVector(dir)))
+	beta := vec3.ScalarMul(le, cosine/(pdfPos*pdfDir))
+	if beta.X == 0 && beta.Y == 0 && beta.Z == 0 {
+		return path
+	}
+
+	path, _ = b.walk(out, world, beta, pdfDir, path, b.depth.Max+1, false, random)
+	return path
+}
+
+// walk extends path by following r, which leaves the last vertex of the path in a direction chosen with density
+// pdfDir, until the path has maxVertices vertices or is terminated. For camera paths it also returns the light
+// arriving from the background when the path leaves the scene.
+func (b *bdpt) walk(r ray.Ray, world *hitable.HitableSlice, beta vec3.Vec3Impl, pdfDir float64, path []vertex, maxVertices int, fromCamera bool, random *fastrandom.LCG) ([]vertex, vec3.Vec3Impl) {
+	var escaped vec3.Vec3Impl
+	var depth pathDepth
+	initial := max(beta.X, beta.Y, beta.Z)
+
+	for bounces := 0; len(path) < maxVertices; bounces++ {
+		atomic.AddUint64(b.numRays, 1)
+
+		rec, mat, ok := world.Hit(r, 0.001, math.MaxFloat64)
+		if !ok {
+			if fromCamera {
+				escaped = vec3.Mul(beta, b.backgroundValue(r))
+			}
+			break
+		}
+
+		v := vertex{
+			typ:  surfaceVertex,
+			p:    rec.P(),
+			n:    rec.Normal(),
+			rec:  rec,
+			mat:  mat,
+			in:   r,
+			beta: beta,
+		}
+		v.pdfFwd = convertDensity(pdfDir, &path[len(path)-1], &v)
+		v.bsdf, v.scatters = b.scatter(r, rec, mat, random)
+		path = append(path, v)
+		if !v.scatters || len(path) == maxVertices {
+			break
+		}
+
+		cur := &path[len(path)-1]
+		var scattered ray.Ray
+		var weight vec3.Vec3Impl
+		var pdfRev float64
+		if cur.bsdf.specular {
+			scattered = cur.bsdf.specularRay
+			weight = cur.bsdf.attenuation
+			cur.delta = true
+			pdfDir = 0
+		} else {
+			scattered = ray.NewWithLambda(rec.P(), cur.bsdf.pdf.Generate(random), r.Time(), r.Lambda())
+			pdfDir = cur.bsdf.pdf.Value(scattered.Direction())
+			if pdfDir <= 0 {
+				break
+			}
+
+			// bsdf / pdf
+			weight = vec3.ScalarDiv(b.evaluate(r, rec, mat, cur.bsdf.attenuation, scattered), pdfDir)
+			pdfRev = b.pdfReverse(r, rec, mat, cur.bsdf.pdf, scattered)
+		}
+
+		if !depth.bounce(b.depth, scatterType(r, rec, mat, cur.bsdf.specular, scattered)) {
+			break
+		}
+
+		beta = vec3.Mul(beta, weight)
+		q := b.depth.roulette(bounces+1, max(beta.X, beta.Y, beta.Z)/initial, random)
+		if q == 0 {
+			break
+		}
+		beta = vec3.ScalarMul(beta, q)
+
+		prev := &path[len(path)-2]
+		prev.pdfRev = convertDensity(pdfRev, cur, prev)
+		r = scattered
+	}
+
+	return path, escaped
+}
+
+// connect returns the contribution of the path made of the first s vertices of the light path and the first t
+// vertices of the camera path, with t > 1.
+func (b *bdpt) connect(lightPath []vertex, camPath []vertex, s int, t int, r ray.Ray, world *hitable.HitableSlice) vec3.Vec3Impl {
+	pt := &camPath[t-1]
+
+	var l vec3.Vec3Impl
+	if s == 0 {
+		// The camera path hit a light.
+		if pt.typ != surfaceVertex {
+			return l
+		}
+		l = vec3.Mul(pt.beta, b.emitted(pt.in, pt.rec, pt.mat))
+	} else {
+		qs := &lightPath[s-1]
+		if !connectible(qs) || !connectible(pt) {
+			return l
+		}
+
+		d := vec3.Sub(qs.p, pt.p)
+		dist2 := d.SquaredLength()
+		if dist2 == 0 {
+			return l
+		}
+		dir := vec3.UnitVector(d)
+		toLight := ray.NewWithLambda(pt.p, dir, r.Time(), r.Lambda())
+		toCamera := ray.NewWithLambda(qs.p, vec3.ScalarMul(dir, -1), r.Time(), r.Lambda())
+
+		l = vec3.Mul(vec3.Mul(pt.beta, b.f(pt, toLight)), vec3.Mul(qs.beta, b.leaving(qs, toCamera.Direction(), toCamera)))
+		if l.X == 0 && l.Y == 0 && l.Z == 0 {
+			return l
+		}
+
+		// G = |cos(θq)| * |cos(θp)| / d²
+		g := math.Abs(vec3.Dot(pt.n, dir)) * math.Abs(vec3.Dot(qs.n, dir)) / dist2
+		if g == 0 || !b.visible(world, toLight, math.Sqrt(dist2)) {
+			return vec3.Vec3Impl{}
+		}
+		l = vec3.ScalarMul(l, g)
+	}
+
+	if l.X == 0 && l.Y == 0 && l.Z == 0 {
+		return l
+	}
+
+	return vec3.ScalarMul(l, b.misWeight(lightPath, camPath, nil, s, t))
+}
+
+// splat connects the first s vertices of the light path to a point chosen on the lens and adds their contribution
+// to f.
+func (b *bdpt) splat(lightPath []vertex, camPath []vertex, s int, r ray.Ray, world *hitable.HitableSlice, f film, random *fastrandom.LCG) {
+	qs := &lightPath[s-1]
+	if !connectible(qs) {
+		return
+	}
+
+	imp, ok := b.camera.SampleImportance(qs.p, random)
+	if !ok || imp.PDF <= 0 {
+		return
+	}
+
+	d := vec3.Sub(imp.Lens, qs.p)
+	dist := d.Length()
+	dir := vec3.UnitVector(d)
+	toCamera := ray.NewWithLambda(qs.p, dir, r.Time(), r.Lambda())
+
+	// beta * f * We * |cos(θq)| / pdf
+	l := vec3.Mul(qs.beta, b.leaving(qs, dir, toCamera))
+	l = vec3.ScalarMul(l, imp.We*math.Abs(vec3.Dot(qs.n, dir))/imp.PDF)
+	if l.X == 0 && l.Y == 0 && l.Z == 0 {
+		return
+	}
+
+	if !b.visible(world, toCamera, dist) {
+		return
+	}
+
+	sampled := vertex{
+		typ:  cameraVertex,
+		p:    imp.Lens,
+		beta: vec3.Vec3Impl{X: imp.We / imp.PDF, Y: imp.We / imp.PDF, Z: imp.We / imp.PDF},
+	}
+	l = vec3.ScalarMul(l, b.misWeight(lightPath, camPath, &sampled, s, 1))
+
+	f.splat(imp.S, imp.T, b.toFilm(l, r.Lambda()))
+}
+
+// misWeight returns the weight of the path made of the first s vertices of the light path and the first t vertices
+// of the camera path against all the other ways in which it could have been built, using the power heuristic.
+// When t is 1 the camera vertex is replaced by sampled.
+func (b *bdpt) misWeight(lightPath []vertex, camPath []vertex, sampled *vertex, s int, t int) float64 {
+	if s+t == 2 {
+		return 1.0
+	}
+
+	var qs, pt, qsMinus, ptMinus *vertex
+	if s > 0 {
+		qs = &lightPath[s-1]
+	}
+	if t > 0 {
+		pt = &camPath[t-1]
+	}
+	if s > 1 {
+		qsMinus = &lightPath[s-2]
+	}
+	if t > 1 {
+		ptMinus = &camPath[t-2]
+	}
+
+	if s == 0 && b.pdfLightOrigin(pt) == 0 {
+		// Light paths cannot start on this emitter, so no other strategy can build this path.
+		return 1.0
+	}
+
+	// The densities of the vertices next to the connection change depending on the strategy, so they are updated
+	// for the duration of the computation.
+	if t == 1 {
+		saved := camPath[0]
+		defer func() { camPath[0] = saved }()
+		camPath[0] = *sampled
+	}
+	for _, v := range []*vertex{qs, pt, qsMinus, ptMinus} {
+		if v != nil {
+			saved := *v
+			defer func() { *v = saved }()
+		}
+	}
+
+	if pt != nil {
+		pt.delta = false
+		if s > 0 {
+			pt.pdfRev = b.pdf(qs, pt)
+		} else {
+			pt.pdfRev = b.pdfLightOrigin(pt)
+		}
+	}
+	if ptMinus != nil {
+		if s > 0 {
+			ptMinus.pdfRev = b.pdfFrom(qs, pt, ptMinus)
+		} else {
+			ptMinus.pdfRev = pdfLight(pt, ptMinus)
+		}
+	}
+	if qs != nil {
+		qs.delta = false
+		qs.pdfRev = b.pdf(pt, qs)
+	}
+	if qsMinus != nil {
+		qsMinus.pdfRev = b.pdfFrom(pt, qs, qsMinus)
+	}
+
+	// remap0 treats the densities of specular vertices, which are stored as zero, as 1.
+	remap0 := func(f float64) float64 {
+		if f != 0 {
+			return f
+		}
+		return 1.0
+	}
+
+	var sumRi float64
+	ri := 1.0
+	for i := t - 1; i > 0; i-- {
+		ratio := remap0(camPath[i].pdfRev) / remap0(camPath[i].pdfFwd)
+		ri *= ratio * ratio
+		if !camPath[i].delta && !camPath[i-1].delta {
+			sumRi += ri
+		}
+	}
+
+	ri = 1.0
+	for i := s - 1; i >= 0; i-- {
+		ratio := remap0(lightPath[i].pdfRev) / remap0(lightPath[i].pdfFwd)
+		ri *= ratio * ratio
+		if !lightPath[i].delta && (i == 0 || !lightPath[i-1].delta) {
+			sumRi += ri
+		}
+	}
+
+	return 1.0 / (1.0 + sumRi)
+}
+
+// pdf returns the density of choosing next, with respect to its area, when the path is extended from v.
+func (b *bdpt) pdf(v *vertex, next *vertex) float64 {
+	dir := vec3.Sub(next.p, v.p)
+
+	var pdfDir float64
+	switch v.typ {
+	case cameraVertex:
+		_, pdfDir = b.camera.PDF(ray.New(v.p, dir, 0))
+	case lightVertex:
+		pdfDir = pdf.NewCosine(v.n).Value(dir)
+	default:
+		if !v.scatters || v.bsdf.specular {
+			return 0
+		}
+		pdfDir = v.bsdf.pdf.Value(dir)
+	}
+
+	return convertDensity(pdfDir, v, next)
+}
+
+// pdfFrom returns the density of choosing next, with respect to its area, when the path is extended from v after
+// arriving at it from prev rather than from the vertex it was reached from, which the density of rough surfaces
+// depends on.
+func (b *bdpt) pdfFrom(prev *vertex, v *vertex, next *vertex) float64 {
+	if _, ok := v.mat.(material.BSDF); !ok || v.typ != surfaceVertex || !v.scatters || v.bsdf.specular {
+		return b.pdf(v, next)
+	}
+
+	in := ray.NewWithLambda(prev.p, vec3.Sub(v.p, prev.p), v.in.Time(), v.in.Lambda())
+	out := ray.New(v.p, vec3.Sub(next.p, v.p), v.in.Time())
+	return convertDensity(v.mat.ScatteringPDF(in, v.rec, out), v, next)
+}
+
+// pdfReverse returns the density with which the direction opposite to r is chosen at the intersection described by
+// rec when the path arrives at it against the direction of scattered, which is sampled from p in the other
+// direction. Only the densities of materials with a BSDF depend on the direction the path arrives from.
+func (b *bdpt) pdfReverse(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, p pdf.PDF, scattered ray.Ray) float64 {
+	back := vec3.ScalarMul(r.Direction(), -1)
+	if _, ok := mat.(material.BSDF); !ok {
+		return p.Value(back)
+	}
+
+	in := ray.NewWithLambda(rec.P(), vec3.ScalarMul(scattered.Direction(), -1), r.Time(), r.Lambda())
+	return mat.ScatteringPDF(in, rec, ray.New(rec.P(), back, r.Time()))
+}
+
+// pdfLightOrigin returns the density with which a light path starts at the emitting surface vertex v.
+func (b *bdpt) pdfLightOrigin(v *vertex) float64 {
+	if v.typ != surfaceVertex || !b.lightMaterials[v.mat] {
+		return 0
+	}
+
+	return 1.0 / b.lightArea
+}
+
+// pdfLight returns the density of choosing next, with respect to its area, when light is emitted from v.
+func pdfLight(v *vertex, next *vertex) float64 {
+	return convertDensity(pdf.NewCosine(v.n).Value(vec3.Sub(next.p, v.p)), v, next)
+}
+
+// convertDensity converts a density with respect to solid angle as seen from v into a density with respect to
+// the area around next.
+func convertDensity(pdfDir float64, v *vertex, next *vertex) float64 {
+	d := vec3.Sub(next.p, v.p)
+	dist2 := d.SquaredLength()
+	if dist2 == 0 {
+		return 0
+	}
+
+	if next.typ != cameraVertex {
+		pdfDir *= math.Abs(vec3.Dot(next.n, d)) / math.Sqrt(dist2)
+	}
+
+	return pdfDir / dist2
+}
+
+// connectible returns whether v can be joined to a vertex of the other path.
+func connectible(v *vertex) bool {
+	switch v.typ {
+	case surfaceVertex:
+		return v.scatters && !v.bsdf.specular
+	default:
+		return true
+	}
+}
+
+// f returns the BSDF at the surface vertex v for light leaving along out, which starts at v.
+func (b *bdpt) f(v *vertex, out ray.Ray) vec3.Vec3Impl {
+	if !v.scatters || v.bsdf.specular {
+		return vec3.Vec3Impl{}
+	}
+
+	cosine := math.Abs(vec3.Dot(v.n, vec3.UnitVector(out.Direction())))
+	if cosine == 0 {
+		return vec3.Vec3Impl{}
+	}
+
+	// bsdf / cos(θ)
+	return vec3.ScalarDiv(b.evaluate(v.in, v.rec, v.mat, v.bsdf.attenuation, out), cosine)
+}
+
+// evaluate returns the BSDF of mat times the cosine of the angle between scattered and the normal, as evaluate
+// does, at the wavelength of r in spectral renders.
+func (b *bdpt) evaluate(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, attenuation vec3.Vec3Impl, scattered ray.Ray) vec3.Vec3Impl {
+	if bs, ok := mat.(material.BSDF); ok && b.spectral {
+		f := bs.SpectralBSDF(r, rec, scattered, r.Lambda())
+		return vec3.Vec3Impl{X: f, Y: f, Z: f}
+	}
+
+	return evaluate(r, rec, mat, attenuation, scattered)
+}
+
+// leaving returns what v contributes to light leaving it along out, in the given direction: the light emitted
+// by light vertices and the BSDF of surface vertices.
+func (b *bdpt) leaving(v *vertex, dir vec3.Vec3Impl, out ray.Ray) vec3.Vec3Impl {
+	if v.typ != lightVertex {
+		return b.f(v, out)
+	}
+
+	// Emission is computed for a ray arriving at the light from the direction the light leaves in.
+	in := ray.NewWithLambda(vec3.Add(v.p, dir), vec3.ScalarMul(dir, -1), out.Time(), out.Lambda())
+	return b.emitted(in, v.rec, v.mat)
+}
+
+// visible returns whether nothing blocks r within the given distance of its origin. The boundaries of media
+// do not block it.
+func (b *bdpt) visible(world *hitable.HitableSlice, r ray.Ray, dist float64) bool {
+	tMin := 0.001
+	for range maxBoundaryCrossings {
+		atomic.AddUint64(b.numRays, 1)
+		rec, mat, hit := world.Hit(r, tMin, dist-0.001)
+		if !hit {
+			return true
+		}
+		if _, ok := mat.(*material.MediumBoundary); !ok {
+			return false
+		}
+		tMin = rec.T() + 0.001
+	}
+
+	return false
+}
+
+// scatter returns how the surface hit by r scatters light.
+func (b *bdpt) scatter(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, random *fastrandom.LCG) (bsdf, bool) {
+	if b.spectral {
+		_, srec, ok := mat.SpectralScatter(r, rec, random)
+		if !ok {
+			return bsdf{}, false
+		}
+
+		a := srec.Attenuation()
+		return bsdf{
+			attenuation: vec3.Vec3Impl{X: a, Y: a, Z: a},
+			specular:    srec.IsSpecular(),
+			specularRay: srec.SpecularRay(),
+			pdf:         srec.PDF(),
+		}, true
+	}
+
+	_, srec, ok := mat.Scatter(r, rec, random)
+	if !ok {
+		return bsdf{}, false
+	}
+
+	return bsdf{
+		attenuation: srec.Attenuation(),
+		specular:    srec.IsSpecular(),
+		specularRay: srec.SpecularRay(),
+		pdf:         srec.PDF(),
+	}, true
+}
+
+// emitted returns the light emitted by the surface hit by r towards the origin of r.
+func (b *bdpt) emitted(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material) vec3.Vec3Impl {
+	if b.spectral {
+		e := mat.EmittedSpectral(r, rec, rec.U(), rec.V(), r.Lambda(), rec.P())
+		return vec3.Vec3Impl{X: e, Y: e, Z: e}
+	}
+
+	return mat.Emitted(r, rec, rec.U(), rec.V(), rec.P())
+}
+
+// backgroundValue returns the light arriving along r when it leaves the scene.
+func (b *bdpt) backgroundValue(r ray.Ray) vec3.Vec3Impl {
+	if b.spectral {
+		v := b.spectralBackground.Value(r.Lambda())
+		return vec3.Vec3Impl{X: v, Y: v, Z: v}
+	}
+
+	return b.background
+}
+
+// toFilm converts light reaching the camera into the values stored in the light image. Spectral values are
+// converted to CIE XYZ in the same way as the radiance of camera rays, whose wavelength is chosen with a density
+// proportional to the CIE Y curve.
+func (b *bdpt) toFilm(l vec3.Vec3Impl, lambda float64) [3]float64 {
+	if !b.spectral {
+		return [3]float64{l.X, l.Y, l.Z}
+	}
+
+	x, y, z := spectral.GetCIEValues(lambda)
+	if y <= 0 {
+		return [3]float64{}
+	}
+
+	// radiance * cie / pdf, where pdf = y / ∫y
+	scale := l.X * spectral.CIEYIntegral() / y
+	return [3]float64{x * scale, y * scale, z * scale}
+}
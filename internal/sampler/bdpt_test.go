@@ -0,0 +1,177 @@
+package sampler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scene"
+	"github.com/flynn-nrg/izpi/internal/scenes"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// meanRadiance returns the mean of the image rendered by s with n camera rays through random points of the film,
+// including the light splatted to image, which is nil for samplers that do not splat any. The points of the film
+// are chosen in the same way for every sampler, so that light that reaches the camera straight from the lights does
+// not add to the difference between them.
+func meanRadiance(sc *scene.Scene, s Sampler, image *LightImage, n int) vec3.Vec3Impl {
+	film := fastrandom.NewWithSeed(1)
+	random := fastrandom.NewWithSeed(2)
+	var sum vec3.Vec3Impl
+	for range n {
+		r := sc.Camera.GetRaySampled(film.Float64(), film.Float64(), 0, random)
+		sum = vec3.Add(sum, vec3.DeNAN(s.Sample(r, sc.World, sc.Lights, 0, random)))
+	}
+
+	// Light images are scaled by the number of pixels over the number of paths, so their contribution to the mean
+	// of the image is the sum of their values over the number of paths.
+	if image != nil {
+		values := image.Data()
+		for i := 0; i < len(values); i += 3 {
+			sum = vec3.Add(sum, vec3.Vec3Impl{X: values[i], Y: values[i+1], Z: values[i+2]})
+		}
+	}
+
+	return vec3.ScalarDiv(sum, float64(n))
+}
+
+func TestBDPTMeanRadiance(t *testing.T) {
+	const samples = 50000
+
+	var numRays uint64
+	depth := Depth{Max: 10, Diffuse: 10, Specular: 10, Transmission: 10, Volume: 10, RussianRoulette: 3}
+	box := scenes.CornellBox(1)
+
+	// The glass sphere is left out, as the caustics it casts take far more samples to converge.
+	var hitables []hitable.Hitable
+	for _, h := range box.World.Hitables() {
+		if _, ok := h.(*hitable.Sphere); !ok {
+			hitables = append(hitables, h)
+		}
+	}
+	sc := scene.New(hitable.NewSlice(hitables), box.Lights, box.Camera)
+
+	want := meanRadiance(sc, NewColour(depth, vec3.Vec3Impl{}, &numRays), nil, samples)
+	image := NewLightImage(16, 16)
+	got := meanRadiance(sc, NewBDPT(depth, vec3.Vec3Impl{}, sc.Camera, sc.Lights, image, &numRays), image, samples)
+
+	// Both samplers are unbiased, so they only differ by noise.
+	for _, c := range []struct{ got, want float64 }{{got.X, want.X}, {got.Y, want.Y}, {got.Z, want.Z}} {
+		if math.Abs(c.got-c.want) > 0.02*c.want {
+			t.Errorf("mean radiance = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+// fullPath returns the vertices of the path that leaves the camera of sc through the given points on its surfaces,
+// the last of which must be on a light, seen from both ends. The camera path holds every vertex with the densities
+// of tracing it from the camera and the light path holds them in the opposite order, starting on the light, with
+// the densities of tracing it from the light.
+func fullPath(t *testing.T, b *bdpt, sc *scene.Scene, points []vec3.Vec3Impl) ([]vertex, []vertex) {
+	t.Helper()
+
+	random := fastrandom.NewWithSeed(1)
+	camPath := []vertex{{typ: cameraVertex, p: sc.Camera.GetRaySampled(0.5, 0.5, 0, random).Origin()}}
+	for _, p := range points {
+		from := camPath[len(camPath)-1].p
+		r := ray.New(from, vec3.Sub(p, from), 0)
+		rec, mat, ok := sc.World.Hit(r, 0.001, math.MaxFloat64)
+		if !ok || vec3.Sub(rec.P(), p).Length() > 1e-6 {
+			t.Fatalf("%v is not visible from %v", p, from)
+		}
+
+		v := vertex{typ: surfaceVertex, p: rec.P(), n: rec.Normal(), rec: rec, mat: mat, in: r}
+		v.bsdf, v.scatters = b.scatter(r, rec, mat, random)
+		camPath = append(camPath, v)
+	}
+
+	last := len(camPath) - 1
+	lightPath := make([]vertex, 0, last)
+	for i := last; i > 0; i-- {
+		v := camPath[i]
+		if i == last {
+			v.typ = lightVertex
+		}
+		lightPath = append(lightPath, v)
+	}
+
+	for i := 1; i <= last; i++ {
+		camPath[i].pdfFwd = b.pdf(&camPath[i-1], &camPath[i])
+		if i < last {
+			camPath[i].pdfRev = b.pdf(&lightPath[last-i-1], &camPath[i])
+		}
+	}
+	for j := range lightPath {
+		if j == 0 {
+			lightPath[j].pdfFwd = b.pdfLightOrigin(&camPath[last])
+		} else {
+			lightPath[j].pdfFwd = b.pdf(&lightPath[j-1], &lightPath[j])
+		}
+		lightPath[j].pdfRev = b.pdf(&camPath[last-j-1], &lightPath[j])
+	}
+	camPath[last].pdfRev = lightPath[0].pdfFwd
+
+	return camPath, lightPath
+}
+
+func TestBDPTMISWeights(t *testing.T) {
+	testData := []struct {
+		name   string
+		points []vec3.Vec3Impl
+	}{
+		{
+			name:   "visible light",
+			points: []vec3.Vec3Impl{{X: 278, Y: 554, Z: 280}},
+		},
+		{
+			name:   "direct light",
+			points: []vec3.Vec3Impl{{X: 450, Y: 450, Z: 555}, {X: 278, Y: 554, Z: 280}},
+		},
+		{
+			name:   "one bounce",
+			points: []vec3.Vec3Impl{{X: 450, Y: 450, Z: 555}, {X: 0, Y: 300, Z: 300}, {X: 278, Y: 554, Z: 280}},
+		},
+		{
+			name: "two bounces",
+			points: []vec3.Vec3Impl{
+				{X: 450, Y: 450, Z: 555}, {X: 0, Y: 300, Z: 300}, {X: 555, Y: 400, Z: 100}, {X: 278, Y: 554, Z: 280},
+			},
+		},
+	}
+
+	var numRays uint64
+	depth := Depth{Max: 10, Diffuse: 10, Specular: 10, Transmission: 10, Volume: 10, RussianRoulette: 3}
+	sc := scenes.CornellBox(1)
+	b := NewBDPT(depth, vec3.Vec3Impl{}, sc.Camera, sc.Lights, NewLightImage(16, 16), &numRays)
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			camPath, lightPath := fullPath(t, &b.bdpt, sc, test.points)
+			lens := camPath[0]
+
+			// Every path that reaches the camera can be built by all the strategies that split it into a light path
+			// with s vertices and a camera path with t, so their weights must add up to 1. Like trace, it leaves
+			// out light reaching the lens straight from the light, which is only ever found by camera paths.
+			var sum float64
+			vertices := len(camPath)
+			for s := 0; s < vertices; s++ {
+				if s == 1 && vertices == 2 {
+					continue
+				}
+
+				w := b.misWeight(lightPath, camPath, &lens, s, vertices-s)
+				if w <= 0 || w > 1 {
+					t.Errorf("misWeight(s = %v) = %v, want a weight in (0, 1]", s, w)
+				}
+				sum += w
+			}
+
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("sum of the weights = %v, want 1", sum)
+			}
+		})
+	}
+}
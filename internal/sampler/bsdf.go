@@ -0,0 +1,33 @@
+package sampler
+
+import (
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// evaluate returns the BSDF of mat times the cosine of the angle between scattered and the normal, for light
+// arriving along scattered at the intersection described by rec and leaving towards the origin of r, given the
+// attenuation of the scatter record of the surface.
+func evaluate(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, attenuation vec3.Vec3Impl, scattered ray.Ray) vec3.Vec3Impl {
+	if b, ok := mat.(material.BSDF); ok {
+		return b.BSDF(r, rec, scattered)
+	}
+
+	// albedo * scatteringPDF()
+	return vec3.ScalarMul(attenuation, mat.ScatteringPDF(r, rec, scattered))
+}
+
+// evaluateSpectral is the spectral counterpart of evaluate, which returns the BSDF at every wavelength traced
+// along the path given the attenuation at each of them and a function that evaluates the BSDF at each of them.
+func evaluateSpectral(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, attenuation spectralValues, scattered ray.Ray, at func(func(float64) float64) spectralValues) spectralValues {
+	if b, ok := mat.(material.BSDF); ok {
+		return at(func(lambda float64) float64 {
+			return b.SpectralBSDF(r, rec, scattered, lambda)
+		})
+	}
+
+	// albedo * scatteringPDF()
+	return attenuation.scale(mat.ScatteringPDF(r, rec, scattered))
+}
@@ -1,65 +1,246 @@
 package sampler
 
 import (
-	"math"
-	"sync/atomic"
-
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/hitable"
-	"github.com/flynn-nrg/izpi/internal/pdf"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
 	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scatterrecord"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
 // Ensure interface compliance.
 var _ Sampler = (*Colour)(nil)
+var _ AOVSampler = (*Colour)(nil)
 
 type Colour struct {
 	NonSpectral // Embed to get SampleSpectral method
-	maxDepth    int
+	depth       Depth
 	numRays     *uint64
 	background  vec3.Vec3Impl
 }
 
-func NewColour(maxDepth int, background vec3.Vec3Impl, numRays *uint64) *Colour {
+func NewColour(depth Depth, background vec3.Vec3Impl, numRays *uint64) *Colour {
 	return &Colour{
 		NonSpectral: *NewNonSpectral(), // Initialize embedded struct
-		maxDepth:    maxDepth,
+		depth:       depth,
 		numRays:     numRays,
 		background:  background,
 	}
 }
 
 func (cs *Colour) Sample(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, depth int, random *fastrandom.LCG) vec3.Vec3Impl {
-	if depth >= cs.maxDepth {
-		return vec3.Vec3Impl{Z: 1.0}
+	return cs.trace(r, world, lightShape, depth, random, nil)
+}
+
+// SampleAOV samples a camera ray like Sample and records its AOVs.
+// The light path AOVs are split by the type of the first bounce and by whether the light reaching it was
+// emitted by the next surface along the path (direct) or arrived after further bounces (indirect).
+// The light emitted by every surface is also recorded against the light group of its material.
+func (cs *Colour) SampleAOV(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, s *aov.Sample) vec3.Vec3Impl {
+	return cs.trace(r, world, lightShape, 0, random, s)
+}
+
+// trace follows the path of r from the given depth and returns the light arriving along it.
+// Direct lighting is estimated at every non-specular bounce by sampling both the lights and the BSDF and
+// combining both estimates with multiple importance sampling. Paths travelling through participating media
+// collide within them as sampled by medium.Medium.SampleCollision, where direct lighting is estimated in the same
+// way with the phase function in place of the BSDF. Dielectrics refract paths with the refractive index relative to
+// the dielectric on the other side of their surface, which is tracked along with the media. Paths end when they
+// reach the depth limits or are terminated by Russian roulette. AOVs are recorded in s unless it is nil.
+func (cs *Colour) trace(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, depth int, random *fastrandom.LCG, s *aov.Sample) vec3.Vec3Impl {
+	col := vec3.Vec3Impl{}
+	throughput := vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}
+	direct, indirect := aov.DiffuseDirect, aov.DiffuseIndirect
+
+	// bsdfPDF is the density with which the direction of r was sampled at the previous bounce, located at p.
+	// It is zero for camera rays and after specular bounces.
+	var bsdfPDF float64
+	var p vec3.Vec3Impl
+	var path pathDepth
+
+	// add records light reaching the camera after the given number of bounces, emitted by surfaces with the
+	// material lightGroup or by the background if it is zero.
+	add := func(bounces int, v vec3.Vec3Impl, lightGroup uint32) {
+		if s != nil {
+			switch {
+			case bounces == 0:
+				s.AddLighting(aov.Emission, v)
+			case bounces == 1:
+				s.AddLighting(direct, v)
+			default:
+				s.AddLighting(indirect, v)
+			}
+			if lightGroup != 0 {
+				s.AddLightGroup(lightGroup, v)
+			}
+		}
+		col = vec3.Add(col, v)
 	}
 
-	atomic.AddUint64(cs.numRays, 1)
+	// media holds the media that the path is travelling through.
+	var media mediumStack
+
+	for bounces := 0; ; bounces++ {
+		if depth+bounces >= cs.depth.Max {
+			return col
+		}
+
+		in, weight, emitted, ok := nextInteraction(r, world, &media, medium.RGB(), random, cs.numRays)
+		if le := rgb(emitted); le != (vec3.Vec3Impl{}) {
+			add(bounces, vec3.Mul(throughput, le), 0)
+		}
+		throughput = vec3.Mul(throughput, rgb(weight))
+		if !ok {
+			add(bounces, vec3.Mul(throughput, cs.background), 0)
+			return col
+		}
+
+		if in.collided() {
+			// Light reaching this point in the medium directly from the lights arrives after one more bounce.
+			x := r.PointAtParameter(in.t)
+			phase := in.medium.Phase()
+			if light, id, ok := cs.sampleMediumDirect(r, x, phase, media, world, lightShape, random); ok {
+				add(bounces+1, vec3.Mul(throughput, light), id)
+			}
+
+			if !path.bounce(cs.depth, volumeBounce) {
+				return col
+			}
+
+			// The direction is sampled with the density of the phase function, which cancels out.
+			direction, pdfVal := phase.Sample(r.Direction(), random)
+			p, bsdfPDF = x, pdfVal
+			r = ray.New(x, direction, r.Time())
+		} else {
+			rec, mat := in.rec, in.mat
+
+			if bounces == 0 && s != nil {
+				s.SetHit(r, rec)
+				s.Albedo = mat.Albedo(rec.U(), rec.V(), rec.P())
+			}
+
+			if emitted := mat.Emitted(r, rec, rec.U(), rec.V(), rec.P()); emitted != (vec3.Vec3Impl{}) {
+				w := emissionWeight(r, p, bsdfPDF, lightShape)
+				add(bounces, vec3.ScalarMul(vec3.Mul(throughput, emitted), w), rec.MaterialID())
+			}
+
+			var srec *scatterrecord.ScatterRecord
+			if d, isDielectric := mat.(*material.Dielectric); isDielectric {
+				// Light is refracted according to the dielectric on the other side of the surface.
+				outside := 1.0
+				if o := media.dielectric(d); o != nil {
+					outside = o.RefractiveIndex()
+				}
+				_, srec, ok = d.ScatterNested(r, rec, random, outside)
+			} else {
+				_, srec, ok = mat.Scatter(r, rec, random)
+			}
+			if !ok {
+				return col
+			}
 
-	if rec, mat, ok := world.Hit(r, 0.001, math.MaxFloat64); ok {
-		_, srec, ok := mat.Scatter(r, rec, random)
-		emitted := mat.Emitted(r, rec, rec.U(), rec.V(), rec.P())
-		if depth < cs.maxDepth && ok {
 			if srec.IsSpecular() {
-				// srec.Attenuation() * colour(...)
-				return vec3.Mul(srec.Attenuation(), cs.Sample(srec.SpecularRay(), world, lightShape, depth+1, random))
+				if bounces == 0 {
+					direct, indirect = aov.SpecularDirect, aov.SpecularIndirect
+				}
+
+				scattered := srec.SpecularRay()
+				bounce := scatterType(r, rec, mat, true, scattered)
+				if !path.bounce(cs.depth, bounce) {
+					return col
+				}
+				if d, ok := mat.(*material.Dielectric); ok && bounce == transmissionBounce {
+					media.cross(r, rec, volume{dielectric: d})
+				}
+
+				throughput = vec3.Mul(throughput, srec.Attenuation())
+				r = scattered
+				bsdfPDF = 0
 			} else {
-				pLight := pdf.NewHitable(lightShape, rec.P())
-				p := pdf.NewMixture(pLight, srec.PDF())
-				scattered := ray.New(rec.P(), p.Generate(random), r.Time())
-				pdfVal := p.Value(scattered.Direction())
-				// emitted + (albedo * scatteringPDF())*colour() / pdf
-				v1 := vec3.ScalarMul(cs.Sample(scattered, world, lightShape, depth+1, random), mat.ScatteringPDF(r, rec, scattered))
-				v2 := vec3.Mul(srec.Attenuation(), v1)
-				v3 := vec3.ScalarDiv(v2, pdfVal)
-				res := vec3.Add(emitted, v3)
-				return res
+				// Light reaching this surface directly from the lights arrives after one more bounce.
+				if light, id, ok := cs.sampleDirect(r, rec, mat, srec, media, world, lightShape, random); ok {
+					add(bounces+1, vec3.Mul(throughput, light), id)
+				}
+
+				scattered, weight, pdfVal, ok := cs.scatter(r, rec, mat, srec, random)
+				if !ok || !path.bounce(cs.depth, scatterType(r, rec, mat, false, scattered)) {
+					return col
+				}
+
+				throughput = vec3.Mul(throughput, weight)
+				p, bsdfPDF = rec.P(), pdfVal
+				r = scattered
 			}
-		} else {
-			return emitted
 		}
+
+		q := cs.depth.roulette(bounces+1, max(throughput.X, throughput.Y, throughput.Z), random)
+		if q == 0 {
+			return col
+		}
+		throughput = vec3.ScalarMul(throughput, q)
+	}
+}
+
+// sampleDirect estimates the light arriving at the intersection described by rec directly from a point sampled
+// on the lights, weighted against BSDF sampling with the power heuristic. It returns the light scattered towards
+// the origin of r and the material identifier of the emitter.
+func (cs *Colour) sampleDirect(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, srec *scatterrecord.ScatterRecord, media mediumStack, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) (vec3.Vec3Impl, uint32, bool) {
+	ls, ok := sampleLight(r, rec.P(), media, medium.RGB(), world, lightShape, random, cs.numRays)
+	if !ok {
+		return vec3.Vec3Impl{}, 0, false
+	}
+
+	emitted := ls.mat.Emitted(ls.ray, ls.rec, ls.rec.U(), ls.rec.V(), ls.rec.P())
+	f := evaluate(r, rec, mat, srec.Attenuation(), ls.ray)
+	if emitted == (vec3.Vec3Impl{}) || f == (vec3.Vec3Impl{}) {
+		return vec3.Vec3Impl{}, 0, false
 	}
 
-	return cs.background
+	// bsdf * emitted * transmittance * weight / pdf
+	w := powerHeuristic(ls.pdf, srec.PDF().Value(ls.ray.Direction()))
+	light := vec3.ScalarMul(vec3.Mul(vec3.Mul(f, emitted), rgb(ls.transmittance)), w/ls.pdf)
+
+	return light, ls.rec.MaterialID(), true
+}
+
+// sampleMediumDirect estimates the light arriving at the point x within a medium directly from a point sampled on
+// the lights, weighted against sampling the phase function with the power heuristic. It returns the light scattered
+// towards the origin of r and the material identifier of the emitter.
+func (cs *Colour) sampleMediumDirect(r ray.Ray, x vec3.Vec3Impl, phase medium.HenyeyGreenstein, media mediumStack, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) (vec3.Vec3Impl, uint32, bool) {
+	ls, ok := sampleLight(r, x, media, medium.RGB(), world, lightShape, random, cs.numRays)
+	if !ok {
+		return vec3.Vec3Impl{}, 0, false
+	}
+
+	emitted := ls.mat.Emitted(ls.ray, ls.rec, ls.rec.U(), ls.rec.V(), ls.rec.P())
+	if emitted == (vec3.Vec3Impl{}) {
+		return vec3.Vec3Impl{}, 0, false
+	}
+
+	// phase * emitted * transmittance * weight / pdf
+	phaseVal := phase.Evaluate(r.Direction(), ls.ray.Direction())
+	w := powerHeuristic(ls.pdf, phaseVal)
+	light := vec3.ScalarMul(vec3.Mul(emitted, rgb(ls.transmittance)), phaseVal*w/ls.pdf)
+
+	return light, ls.rec.MaterialID(), true
+}
+
+// scatter samples the direction in which r is scattered by a non-specular surface from the BSDF.
+// It returns the scattered ray, the weight of the light arriving along it and the density of its direction.
+func (cs *Colour) scatter(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, srec *scatterrecord.ScatterRecord, random *fastrandom.LCG) (ray.Ray, vec3.Vec3Impl, float64, bool) {
+	p := srec.PDF()
+	scattered := ray.New(rec.P(), p.Generate(random), r.Time())
+	pdfVal := p.Value(scattered.Direction())
+	if pdfVal <= 0 {
+		return nil, vec3.Vec3Impl{}, 0, false
+	}
+
+	// bsdf / pdf
+	weight := vec3.ScalarDiv(evaluate(r, rec, mat, srec.Attenuation(), scattered), pdfVal)
+
+	return scattered, weight, pdfVal, true
 }
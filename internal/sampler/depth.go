@@ -0,0 +1,96 @@
+package sampler
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Depth holds the limits on the length of the paths traced by a sampler.
+type Depth struct {
+	// Max is the maximum number of rays traced along a path.
+	Max int
+	// Diffuse, Specular, Transmission and Volume are the maximum number of bounces of each type after which a path
+	// is continued. Light reaching a surface directly from the lights is still gathered once a limit is reached,
+	// so a limit of zero renders direct lighting only.
+	Diffuse      int
+	Specular     int
+	Transmission int
+	Volume       int
+	// RussianRoulette is the number of bounces after which paths are terminated at random, with a probability
+	// that grows as the fraction of light they carry to the camera falls.
+	RussianRoulette int
+}
+
+// bounceType classifies the events that scatter a path.
+type bounceType int
+
+const (
+	diffuseBounce bounceType = iota
+	specularBounce
+	transmissionBounce
+	volumeBounce
+	numBounceTypes
+)
+
+// limit returns the maximum number of bounces of type t.
+func (d Depth) limit(t bounceType) int {
+	switch t {
+	case specularBounce:
+		return d.Specular
+	case transmissionBounce:
+		return d.Transmission
+	case volumeBounce:
+		return d.Volume
+	default:
+		return d.Diffuse
+	}
+}
+
+// roulette decides whether a path that has bounced the given number of times survives Russian roulette.
+// Paths survive with a probability equal to their throughput, clamped to 1, and the factor that the throughput
+// of a surviving path must be scaled by to remain unbiased is returned. It returns 0 if the path is terminated.
+func (d Depth) roulette(bounces int, throughput float64, random *fastrandom.LCG) float64 {
+	if bounces < d.RussianRoulette || throughput >= 1 {
+		return 1.0
+	}
+
+	if throughput <= 0 || random.Float64() >= throughput {
+		return 0
+	}
+
+	return 1.0 / throughput
+}
+
+// pathDepth counts the bounces of every type along a path.
+type pathDepth [numBounceTypes]int
+
+// bounce records a bounce of type t and returns false if the path has exceeded the limit for that type.
+func (p *pathDepth) bounce(d Depth, t bounceType) bool {
+	if p[t] >= d.limit(t) {
+		return false
+	}
+
+	p[t]++
+	return true
+}
+
+// scatterType returns the type of the bounce that scatters r into scattered at the intersection described by rec.
+func scatterType(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, specular bool, scattered ray.Ray) bounceType {
+	if _, ok := mat.(*material.Isotropic); ok {
+		return volumeBounce
+	}
+
+	if !specular {
+		return diffuseBounce
+	}
+
+	// Refracted rays leave the surface on the opposite side to the one they arrived from.
+	if vec3.Dot(r.Direction(), rec.Normal())*vec3.Dot(scattered.Direction(), rec.Normal()) > 0 {
+		return transmissionBounce
+	}
+
+	return specularBounce
+}
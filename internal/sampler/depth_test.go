@@ -0,0 +1,62 @@
+package sampler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+func TestRoulette(t *testing.T) {
+	d := Depth{RussianRoulette: 3}
+
+	testData := []struct {
+		name       string
+		bounces    int
+		throughput float64
+	}{
+		{name: "before the minimum depth", bounces: 2, throughput: 0.1},
+		{name: "bright path", bounces: 5, throughput: 1.5},
+		{name: "dim path", bounces: 5, throughput: 0.25},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			random := fastrandom.NewWithDefaults()
+			const n = 100000
+
+			// Surviving paths are scaled so that the expected throughput is unchanged.
+			var sum float64
+			for range n {
+				sum += test.throughput * d.roulette(test.bounces, test.throughput, random)
+			}
+
+			if got := sum / n; math.Abs(got-test.throughput) > 0.02*test.throughput {
+				t.Errorf("mean throughput = %v, want %v", got, test.throughput)
+			}
+		})
+	}
+}
+
+func TestPathDepthBounce(t *testing.T) {
+	d := Depth{Diffuse: 2, Specular: 0, Transmission: 1}
+
+	var p pathDepth
+	testData := []struct {
+		t    bounceType
+		want bool
+	}{
+		{diffuseBounce, true},
+		{specularBounce, false},
+		{transmissionBounce, true},
+		{diffuseBounce, true},
+		{transmissionBounce, false},
+		{diffuseBounce, false},
+	}
+
+	for i, test := range testData {
+		if got := p.bounce(d, test.t); got != test.want {
+			t.Errorf("bounce %v of type %v = %v, want %v", i, test.t, got, test.want)
+		}
+	}
+}
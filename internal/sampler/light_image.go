@@ -0,0 +1,203 @@
+package sampler
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+)
+
+// LightImage accumulates the light that light paths carry straight to the camera, which can land on any pixel
+// of the image. It is safe for concurrent use.
+type LightImage struct {
+	width  int
+	height int
+	// values holds the bits of the float64 values of the three channels of every pixel, stored row by row.
+	values []atomic.Uint64
+	paths  atomic.Uint64
+}
+
+// NewLightImage returns a new empty light image with the given size.
+func NewLightImage(width int, height int) *LightImage {
+	return &LightImage{
+		width:  width,
+		height: height,
+		values: make([]atomic.Uint64, width*height*3),
+	}
+}
+
+// lightFilm receives the light that light paths carry straight to the camera and counts the paths traced.
+type lightFilm interface {
+	film
+	addPaths(n uint64)
+}
+
+// pixelIndex returns the index of the pixel of an image of the given size at film coordinates (s, t), which are
+// given in the same way as to camera.GetRaySampled, and whether they fall within the image.
+func pixelIndex(width int, height int, s float64, t float64) (int, bool) {
+	x := int(math.Floor(s * float64(width)))
+	// Camera rays for row y of the image are stored in row height - y of the canvas.
+	y := height - int(math.Floor(t*float64(height)))
+	if x < 0 || x >= width || y < 0 || y >= height {
+		return 0, false
+	}
+
+	return y*width + x, true
+}
+
+// splat adds c to the pixel at film coordinates (s, t), which are given in the same way as to camera.GetRaySampled.
+func (li *LightImage) splat(s float64, t float64, c [3]float64) {
+	p, ok := pixelIndex(li.width, li.height, s, t)
+	if !ok {
+		return
+	}
+
+	for ch, v := range c {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			li.add(p*3+ch, v)
+		}
+	}
+}
+
+// add atomically adds v to the value at index i.
+func (li *LightImage) add(i int, v float64) {
+	if v == 0 {
+		return
+	}
+
+	for {
+		old := li.values[i].Load()
+		if li.values[i].CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// addPaths records that n more light paths have been traced.
+func (li *LightImage) addPaths(n uint64) {
+	li.paths.Add(n)
+}
+
+// Paths returns the number of light paths traced so far.
+func (li *LightImage) Paths() uint64 {
+	return li.paths.Load()
+}
+
+// Data returns a copy of the accumulated values of the three channels of every pixel, stored row by row.
+func (li *LightImage) Data() []float64 {
+	data := make([]float64, len(li.values))
+	for i := range li.values {
+		data[i] = math.Float64frombits(li.values[i].Load())
+	}
+
+	return data
+}
+
+// Merge adds the values and number of paths accumulated by another light image of the same size.
+func (li *LightImage) Merge(values []float64, paths uint64) {
+	for i, v := range values[:min(len(values), len(li.values))] {
+		li.add(i, v)
+	}
+
+	li.addPaths(paths)
+}
+
+// MergeTile adds the values and number of paths accumulated by a tile light image, as returned by its Pixels,
+// Values and Paths methods.
+func (li *LightImage) MergeTile(pixels []uint32, values []float64, paths uint64) {
+	for i, p := range pixels[:min(len(pixels), len(values)/3)] {
+		if int(p) >= li.width*li.height {
+			continue
+		}
+		for ch := range 3 {
+			li.add(int(p)*3+ch, values[i*3+ch])
+		}
+	}
+
+	li.addPaths(paths)
+}
+
+// AddTo adds the light image to the first three channels of canvas. Every light path contributes to the image
+// as a whole, so the accumulated values are scaled by the number of pixels over the number of paths.
+func (li *LightImage) AddTo(canvas *floatimage.Float64NRGBA) {
+	paths := li.Paths()
+	if paths == 0 {
+		return
+	}
+
+	scale := float64(li.width*li.height) / float64(paths)
+	for y := range li.height {
+		for x := range li.width {
+			pix := canvas.Pix[canvas.PixOffset(x, y):]
+			i := (y*li.width + x) * 3
+			for c := range 3 {
+				pix[c] += math.Float64frombits(li.values[i+c].Load()) * scale
+			}
+		}
+	}
+}
+
+// TileLightImage accumulates the light that the light paths traced for a single tile carry to the camera. Tiles
+// that are rendered at the same time splat light to the same pixels, so every tile accumulates it on its own and
+// tile light images are merged into the light image in a fixed order, which makes the sums round the same way on
+// every render. Only the pixels that light lands on are stored. It is not safe for concurrent use.
+type TileLightImage struct {
+	width  int
+	height int
+	// slots maps the index of every pixel that light has landed on to its position in pixels.
+	slots  map[uint32]int
+	pixels []uint32
+	values []float64
+	paths  uint64
+}
+
+// NewTileLightImage returns a new empty tile light image for an image of the given size.
+func NewTileLightImage(width int, height int) *TileLightImage {
+	return &TileLightImage{
+		width:  width,
+		height: height,
+		slots:  make(map[uint32]int),
+	}
+}
+
+// splat adds c to the pixel at film coordinates (s, t), which are given in the same way as to camera.GetRaySampled.
+func (ti *TileLightImage) splat(s float64, t float64, c [3]float64) {
+	p, ok := pixelIndex(ti.width, ti.height, s, t)
+	if !ok {
+		return
+	}
+
+	slot, ok := ti.slots[uint32(p)]
+	if !ok {
+		slot = len(ti.pixels)
+		ti.slots[uint32(p)] = slot
+		ti.pixels = append(ti.pixels, uint32(p))
+		ti.values = append(ti.values, 0, 0, 0)
+	}
+
+	for ch, v := range c {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			ti.values[slot*3+ch] += v
+		}
+	}
+}
+
+// addPaths records that n more light paths have been traced.
+func (ti *TileLightImage) addPaths(n uint64) {
+	ti.paths += n
+}
+
+// Pixels returns the indices of the pixels that light has landed on, in the order it first did.
+func (ti *TileLightImage) Pixels() []uint32 {
+	return ti.pixels
+}
+
+// Values returns the accumulated values of the three channels of every pixel returned by Pixels.
+func (ti *TileLightImage) Values() []float64 {
+	return ti.values
+}
+
+// Paths returns the number of light paths traced for the tile.
+func (ti *TileLightImage) Paths() uint64 {
+	return ti.paths
+}
@@ -0,0 +1,121 @@
+package sampler
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/floatimage/floatimage"
+	"github.com/google/go-cmp/cmp"
+)
+
+type lightSplat struct {
+	s, t float64
+	c    [3]float64
+}
+
+func TestLightImageAddTo(t *testing.T) {
+	testData := []struct {
+		name   string
+		splats []lightSplat
+		paths  uint64
+		want   []float64
+	}{
+		{
+			name:   "no paths",
+			splats: []lightSplat{{0.5, 0.75, [3]float64{1, 1, 1}}},
+			want:   make([]float64, 3*2*4),
+		},
+		{
+			name:   "splats are scaled by the number of pixels over the number of paths",
+			splats: []lightSplat{{0.5, 0.75, [3]float64{1, 2, 3}}, {0.4, 0.9, [3]float64{1, 0, 0}}},
+			paths:  2,
+			want: []float64{
+				0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 6, 6, 9, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "splats outside the image are dropped",
+			splats: []lightSplat{
+				{-0.1, 0.75, [3]float64{1, 1, 1}},
+				{1.0, 0.75, [3]float64{1, 1, 1}},
+				{0.5, 0.25, [3]float64{1, 1, 1}},
+			},
+			paths: 1,
+			want:  make([]float64, 3*2*4),
+		},
+		{
+			name:   "invalid values are dropped",
+			splats: []lightSplat{{0.1, 0.75, [3]float64{math.NaN(), 1, math.Inf(1)}}},
+			paths:  6,
+			want: []float64{
+				0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			li := NewLightImage(3, 2)
+			for _, s := range test.splats {
+				li.splat(s.s, s.t, s.c)
+			}
+			li.addPaths(test.paths)
+
+			canvas := floatimage.NewFloat64NRGBA(image.Rect(0, 0, 3, 2), make([]float64, 3*2*4))
+			li.AddTo(canvas)
+
+			if diff := cmp.Diff(test.want, canvas.Pix); diff != "" {
+				t.Errorf("AddTo() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLightImageMerge(t *testing.T) {
+	a := NewLightImage(2, 2)
+	a.splat(0.25, 0.75, [3]float64{1, 2, 3})
+	a.addPaths(5)
+
+	b := NewLightImage(2, 2)
+	b.splat(0.25, 0.75, [3]float64{1, 1, 1})
+	b.splat(0.75, 0.75, [3]float64{4, 0, 0})
+	b.addPaths(3)
+	b.Merge(a.Data(), a.Paths())
+
+	want := []float64{0, 0, 0, 0, 0, 0, 2, 3, 4, 4, 0, 0}
+	if diff := cmp.Diff(want, b.Data()); diff != "" {
+		t.Errorf("Data() mismatch (-want +got):\n%s", diff)
+	}
+	if got := b.Paths(); got != 8 {
+		t.Errorf("Paths() = %v, want 8", got)
+	}
+}
+
+func TestLightImageMergeTile(t *testing.T) {
+	tile := NewTileLightImage(2, 2)
+	tile.splat(0.75, 0.75, [3]float64{4, 0, 0})
+	tile.splat(0.25, 0.75, [3]float64{1, 2, 3})
+	tile.splat(0.75, 0.75, [3]float64{1, math.NaN(), 1})
+	tile.splat(0.25, 0.25, [3]float64{1, 1, 1})
+	tile.addPaths(3)
+
+	if diff := cmp.Diff([]uint32{3, 2}, tile.Pixels()); diff != "" {
+		t.Errorf("Pixels() mismatch (-want +got):\n%s", diff)
+	}
+
+	li := NewLightImage(2, 2)
+	li.splat(0.25, 0.75, [3]float64{1, 1, 1})
+	li.addPaths(5)
+	li.MergeTile(tile.Pixels(), tile.Values(), tile.Paths())
+
+	want := []float64{0, 0, 0, 0, 0, 0, 2, 3, 4, 5, 0, 1}
+	if diff := cmp.Diff(want, li.Data()); diff != "" {
+		t.Errorf("Data() mismatch (-want +got):\n%s", diff)
+	}
+	if got := li.Paths(); got != 8 {
+		t.Errorf("Paths() = %v, want 8", got)
+	}
+}
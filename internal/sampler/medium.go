@@ -0,0 +1,167 @@
+package sampler
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// maxBoundaryCrossings is the maximum number of medium boundaries that a ray can cross between two vertices of
+// a path, which stops rays that graze a boundary from crossing it back and forth forever.
+const maxBoundaryCrossings = 64
+
+// volume is a region of space that a path can be inside of: the inside of a participating medium or of a
+// dielectric. Exactly one of its fields is set.
+type volume struct {
+	medium     *medium.Medium
+	dielectric *material.Dielectric
+}
+
+// mediumStack holds the volumes that enclose the current vertex of a path, innermost last.
+// Paths start outside every volume.
+type mediumStack []volume
+
+// current returns the medium that the path is travelling through, or nil if there is none. Dielectrics displace
+// the media that enclose them.
+func (s mediumStack) current() *medium.Medium {
+	if len(s) == 0 {
+		return nil
+	}
+
+	return s[len(s)-1].medium
+}
+
+// cross updates the stack as r crosses the boundary of v at the intersection described by rec. The path enters v
+// if it travels against the normal of the boundary and leaves it otherwise.
+func (s *mediumStack) cross(r ray.Ray, rec *hitrecord.HitRecord, v volume) {
+	if vec3.Dot(r.Direction(), rec.Normal()) < 0 {
+		*s = append(*s, v)
+		return
+	}
+
+	for i := len(*s) - 1; i >= 0; i-- {
+		if (*s)[i] == v {
+			// Copy the stack so that the stacks of the shadow rays, which share its storage, are left intact.
+			*s = append((*s)[:i:i], (*s)[i+1:]...)
+			return
+		}
+	}
+}
+
+// dielectric returns the dielectric with the highest priority among those that enclose the path, other than
+// except, or nil if there is none. Of several with the same priority, the innermost one is returned.
+func (s mediumStack) dielectric(except *material.Dielectric) *material.Dielectric {
+	var d *material.Dielectric
+	for i := len(s) - 1; i >= 0; i-- {
+		v := s[i].dielectric
+		if v == nil || v == except {
+			continue
+		}
+		if d == nil || v.Priority() > d.Priority() {
+			d = v
+		}
+	}
+
+	return d
+}
+
+// isInterface returns whether the surface of d, which the path has reached, separates two different dielectrics.
+// The surfaces of dielectrics that lie within one with a higher priority, such as the part of the surface of
+// the water in a glass that overlaps the glass, are ignored.
+func (s mediumStack) isInterface(d *material.Dielectric) bool {
+	o := s.dielectric(d)
+	return o == nil || o.Priority() <= d.Priority()
+}
+
+// absorption returns the fraction of every channel of sp that is not absorbed by the dielectric that fills the
+// space that the path is travelling through between the ray parameters tMin and tMax.
+func (s mediumStack) absorption(r ray.Ray, tMin float64, tMax float64, sp medium.Spectrum) medium.Values {
+	d := s.dielectric(nil)
+	if d == nil {
+		return sp.Ones()
+	}
+
+	distance := (tMax - tMin) * r.Direction().Length()
+	t := sp.Evaluate(d.Absorption(), d.SpectralAbsorption)
+	for i := range sp.Len() {
+		t[i] = math.Exp(-t[i] * distance)
+	}
+	return t
+}
+
+// interaction is the next event along a path.
+type interaction struct {
+	// rec and mat describe the surface reached by the path, unless it collided within a medium first.
+	rec *hitrecord.HitRecord
+	mat material.Material
+	// medium is the medium in which the path collided, at the ray parameter t, or nil if it reached a surface.
+	medium *medium.Medium
+	t      float64
+}
+
+// collided returns whether the path collided within a medium.
+func (in interaction) collided() bool {
+	return in.medium != nil
+}
+
+// nextInteraction follows r through the boundaries of media and the surfaces of dielectrics that are ignored
+// because they lie within a dielectric with a higher priority, updating the stack as it crosses them, until it
+// collides within a medium or reaches a surface. Light is absorbed along the way by the dielectrics that the path
+// travels through. It returns the interaction, the weight of every channel of s
+// due to the media traversed and the radiance they emit towards the origin of r, or false if r leaves the scene.
+// Light travelling through a medium that a path leaves the scene from without crossing its boundary is not
+// attenuated.
+func nextInteraction(r ray.Ray, world *hitable.HitableSlice, media *mediumStack, s medium.Spectrum, random *fastrandom.LCG, numRays *uint64) (interaction, medium.Values, medium.Values, bool) {
+	weight := s.Ones()
+	var emitted medium.Values
+	tMin := 0.001
+	for range maxBoundaryCrossings {
+		atomic.AddUint64(numRays, 1)
+
+		rec, mat, ok := world.Hit(r, tMin, math.MaxFloat64)
+		if !ok {
+			return interaction{}, weight, emitted, false
+		}
+
+		if m := media.current(); m != nil {
+			t, w, e, collided := m.SampleCollision(r, tMin, rec.T(), s, random)
+			for i := range emitted {
+				emitted[i] += weight[i] * e[i]
+			}
+			weight = weight.Mul(w)
+			if collided {
+				return interaction{medium: m, t: t}, weight, emitted, true
+			}
+		}
+
+		weight = weight.Mul(media.absorption(r, tMin, rec.T(), s))
+
+		switch m := mat.(type) {
+		case *material.MediumBoundary:
+			media.cross(r, rec, volume{medium: m.Medium()})
+		case *material.Dielectric:
+			if media.isInterface(m) {
+				return interaction{rec: rec, mat: mat}, weight, emitted, true
+			}
+			media.cross(r, rec, volume{dielectric: m})
+		default:
+			return interaction{rec: rec, mat: mat}, weight, emitted, true
+		}
+
+		tMin = rec.T() + 0.001
+	}
+
+	return interaction{}, medium.Values{}, emitted, false
+}
+
+// rgb returns the red, green and blue channels of v.
+func rgb(v medium.Values) vec3.Vec3Impl {
+	return vec3.Vec3Impl{X: v[0], Y: v[1], Z: v[2]}
+}
@@ -0,0 +1,132 @@
+package sampler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestMediumStackDielectrics(t *testing.T) {
+	glass := material.NewDielectric(1.5)
+	glass.SetPriority(2)
+	water := material.NewColoredDielectric(1.33, vec3.Vec3Impl{X: 0.5, Y: 0.1, Z: 0.01})
+	water.SetPriority(1)
+	ice := material.NewDielectric(1.31)
+	ice.SetPriority(1)
+	fog := medium.NewHomogeneous(vec3.Vec3Impl{X: 0.1, Y: 0.1, Z: 0.1}, vec3.Vec3Impl{}, 0)
+
+	testData := []struct {
+		name  string
+		stack mediumStack
+		// surface is the dielectric whose surface the path reaches.
+		surface       *material.Dielectric
+		wantInterface bool
+		// wantOutside is the dielectric beyond the surface.
+		wantOutside *material.Dielectric
+		wantCurrent *medium.Medium
+	}{
+		{
+			name:          "entering from the air",
+			surface:       water,
+			wantInterface: true,
+		},
+		{
+			name:          "entering the glass from the water",
+			stack:         mediumStack{{dielectric: water}},
+			surface:       glass,
+			wantInterface: true,
+			wantOutside:   water,
+		},
+		{
+			name:        "surface of the water inside the glass",
+			stack:       mediumStack{{dielectric: glass}},
+			surface:     water,
+			wantOutside: glass,
+		},
+		{
+			name:        "leaving the water inside the glass",
+			stack:       mediumStack{{dielectric: glass}, {dielectric: water}},
+			surface:     water,
+			wantOutside: glass,
+		},
+		{
+			name:          "leaving the glass into the water",
+			stack:         mediumStack{{dielectric: water}, {dielectric: glass}},
+			surface:       glass,
+			wantInterface: true,
+			wantOutside:   water,
+		},
+		{
+			name:          "same priority",
+			stack:         mediumStack{{dielectric: water}},
+			surface:       ice,
+			wantInterface: true,
+			wantOutside:   water,
+		},
+		{
+			name:          "glass in fog",
+			stack:         mediumStack{{medium: fog}, {dielectric: glass}},
+			surface:       glass,
+			wantInterface: true,
+		},
+		{
+			name:          "fog in water",
+			stack:         mediumStack{{dielectric: water}, {medium: fog}},
+			surface:       glass,
+			wantInterface: true,
+			wantOutside:   water,
+			wantCurrent:   fog,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.stack.isInterface(test.surface); got != test.wantInterface {
+				t.Errorf("isInterface() = %v, want %v", got, test.wantInterface)
+			}
+			if got := test.stack.dielectric(test.surface); got != test.wantOutside {
+				t.Errorf("dielectric() = %v, want %v", got, test.wantOutside)
+			}
+			if got := test.stack.current(); got != test.wantCurrent {
+				t.Errorf("current() = %v, want %v", got, test.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestMediumStackAbsorption(t *testing.T) {
+	glass := material.NewDielectric(1.5)
+	glass.SetPriority(2)
+	water := material.NewColoredDielectric(1.33, vec3.Vec3Impl{X: 0.5, Y: 0.1, Z: 0.01})
+	water.SetPriority(1)
+
+	testData := []struct {
+		name  string
+		stack mediumStack
+		want  medium.Values
+	}{
+		{name: "air", want: medium.Values{1, 1, 1}},
+		{name: "water", stack: mediumStack{{dielectric: water}}, want: medium.Values{math.Exp(-1), math.Exp(-0.2), math.Exp(-0.02)}},
+		{name: "glass in water", stack: mediumStack{{dielectric: water}, {dielectric: glass}}, want: medium.Values{1, 1, 1}},
+		{name: "water in glass", stack: mediumStack{{dielectric: glass}, {dielectric: water}}, want: medium.Values{1, 1, 1}},
+	}
+
+	// The ray travels a distance of 2 between its parameters 1 and 2.
+	r := ray.New(vec3.Vec3Impl{}, vec3.Vec3Impl{X: 2.0}, 0)
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.stack.absorption(r, 1, 2, medium.RGB())
+			for i := range got {
+				if math.Abs(got[i]-test.want[i]) > 1e-9 {
+					t.Errorf("absorption() = %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
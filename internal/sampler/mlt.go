@@ -0,0 +1,391 @@
+package sampler
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+	"github.com/flynn-nrg/izpi/internal/camera"
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ ChainSampler = (*MLT)(nil)
+var _ LightPathSampler = (*MLT)(nil)
+
+const (
+	// Default number of paths traced by the bootstrap phase of the Metropolis light transport sampler.
+	DefaultBootstrapSamples = 100000
+	// Default probability of a mutation replacing the whole path rather than perturbing it.
+	DefaultLargeStepProbability = 0.3
+
+	// mutationSigma is the standard deviation of the perturbation applied to every number by small steps.
+	mutationSigma = 0.01
+	// bootstrapStream tells the seeds of bootstrap paths apart from those of chains.
+	bootstrapStream = 1
+)
+
+// MLTConfig holds the settings of the Metropolis light transport sampler.
+type MLTConfig struct {
+	// Seed determines every path traced by the sampler, so renders with the same seed and settings are the same
+	// regardless of how the work is split across workers.
+	Seed uint64
+	// BootstrapSamples is the number of paths traced to estimate the brightness of the image and to choose the
+	// paths that chains start from.
+	BootstrapSamples int
+	// LargeStepProbability is the probability of a mutation replacing the whole path rather than perturbing it.
+	LargeStepProbability float64
+}
+
+// MLT is a primary sample space Metropolis light transport sampler, as described by Kelemen et al. in
+// "A Simple and Robust Mutation Strategy for the Metropolis Light Transport Algorithm". Every path is built by
+// the bidirectional path tracer from a sequence of random numbers, including the film position of its camera ray.
+// Markov chains mutate these sequences so that, once a bright path such as a caustic is found, the paths around
+// it are explored. The brightness of the image is estimated by tracing a set of independent bootstrap paths, which
+// are also the ones chains start from. Every pixel runs a chain for as many mutations as samples are requested
+// and all the light found is splatted to a light image, so sampling camera rays directly yields nothing.
+type MLT struct {
+	bdpt   bdpt
+	config MLTConfig
+	world  *hitable.HitableSlice
+	image  lightFilm
+	// brightness is the mean brightness of the bootstrap paths, and bootstrap the running total of their
+	// brightness, used to choose the paths that chains start from.
+	brightness float64
+	bootstrap  []float64
+}
+
+// NewMLT returns a new RGB Metropolis light transport sampler that renders world as seen through cam into image.
+// The bootstrap paths are traced before it returns.
+func NewMLT(config MLTConfig, depth Depth, background vec3.Vec3Impl, cam *camera.Camera, world *hitable.HitableSlice, lights *hitable.HitableSlice, image *LightImage, numRays *uint64) *MLT {
+	m := &MLT{
+		bdpt: bdpt{
+			paths:      &sync.Pool{},
+			depth:      depth,
+			numRays:    numRays,
+			background: background,
+			camera:     cam,
+		},
+	}
+	m.setup(config, world, lights, image)
+
+	return m
+}
+
+// NewSpectralMLT returns a new spectral Metropolis light transport sampler that renders world as seen through cam
+// into image, which holds CIE XYZ values. The bootstrap paths are traced before it returns.
+func NewSpectralMLT(config MLTConfig, depth Depth, background *spectral.SpectralPowerDistribution, cam *camera.Camera, world *hitable.HitableSlice, lights *hitable.HitableSlice, image *LightImage, numRays *uint64) *MLT {
+	m := &MLT{
+		bdpt: bdpt{
+			paths:              &sync.Pool{},
+			depth:              depth,
+			numRays:            numRays,
+			spectral:           true,
+			spectralBackground: background,
+			camera:             cam,
+		},
+	}
+	m.setup(config, world, lights, image)
+
+	return m
+}
+
+func (m *MLT) setup(config MLTConfig, world *hitable.HitableSlice, lights *hitable.HitableSlice, image *LightImage) {
+	if config.BootstrapSamples <= 0 {
+		config.BootstrapSamples = DefaultBootstrapSamples
+	}
+	if config.LargeStepProbability <= 0 || config.LargeStepProbability > 1 {
+		config.LargeStepProbability = DefaultLargeStepProbability
+	}
+
+	m.config = config
+	m.world = world
+	m.image = image
+	m.bdpt.setLights(lights)
+	m.runBootstrap()
+}
+
+// WithLightImage returns a copy of the sampler that splats light to image. Both share the bootstrap paths.
+func (m *MLT) WithLightImage(image *TileLightImage) Sampler {
+	c := *m
+	c.image = image
+	return &c
+}
+
+// Sample returns black, as all the light found by the sampler is splatted to the light image by RunChain.
+func (m *MLT) Sample(_ ray.Ray, _ *hitable.HitableSlice, _ hitable.Hitable, _ int, _ *fastrandom.LCG) vec3.Vec3Impl {
+	return vec3.Vec3Impl{}
+}
+
+// SampleSpectral returns black, as all the light found by the sampler is splatted to the light image by RunChain.
+func (m *MLT) SampleSpectral(_ ray.Ray, _ *hitable.HitableSlice, _ hitable.Hitable, _ int, _ *fastrandom.LCG) float64 {
+	return 0
+}
+
+// runBootstrap traces the bootstrap paths in parallel. Every path has a seed of its own, so the result does not
+// depend on the number of threads.
+func (m *MLT) runBootstrap() {
+	n := m.config.BootstrapSamples
+	weights := make([]float64, n)
+
+	numWorkers := runtime.GOMAXPROCS(0)
+	perWorker := (n + numWorkers - 1) / numWorkers
+
+	wg := sync.WaitGroup{}
+	for i0 := 0; i0 < n; i0 += perWorker {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var samples filmSamples
+			for i := i0; i < min(i0+perWorker, n); i++ {
+				weights[i] = m.evaluate(m.bootstrapSamples(i), &samples)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	m.bootstrap = make([]float64, n)
+	var total float64
+	for i, w := range weights {
+		total += w
+		m.bootstrap[i] = total
+	}
+
+	m.brightness = total / float64(n)
+}
+
+// bootstrapSamples returns the primary samples of bootstrap path i.
+func (m *MLT) bootstrapSamples(i int) *primarySamples {
+	seed := fastrandom.Hash(m.config.Seed, bootstrapStream, uint64(i))
+	return newPrimarySamples(fastrandom.NewWithSeed(seed), m.config.LargeStepProbability)
+}
+
+// RunChain runs a Markov chain for the given number of mutations and splats the light it finds to the light image.
+// The chain is seeded from the pixel and the number of mutations already run for it, so running it again with
+// the same arguments yields the same result.
+func (m *MLT) RunChain(pixel int, firstMutation int, mutations int) {
+	defer m.image.addPaths(uint64(mutations))
+
+	if m.brightness <= 0 || mutations <= 0 {
+		return
+	}
+
+	random := fastrandom.NewWithSeed(fastrandom.Hash(m.config.Seed, uint64(pixel), uint64(firstMutation)))
+
+	// Start from a bootstrap path chosen with a probability proportional to its brightness, which removes the
+	// start-up bias of the chain. Paths are regenerated from their seed rather than stored.
+	total := m.bootstrap[len(m.bootstrap)-1]
+	target := random.Float64() * total
+	start := sort.Search(len(m.bootstrap), func(i int) bool { return m.bootstrap[i] > target })
+
+	samples := m.bootstrapSamples(min(start, len(m.bootstrap)-1))
+	var current, proposed filmSamples
+	currentBrightness := m.evaluate(samples, &current)
+
+	// Chains that start from the same bootstrap path mutate it differently.
+	samples.random = random
+
+	for range mutations {
+		samples.startIteration()
+		proposedBrightness := m.evaluate(samples, &proposed)
+
+		accept := 1.0
+		if currentBrightness > 0 {
+			accept = min(1.0, proposedBrightness/currentBrightness)
+		}
+
+		// Both paths contribute in proportion to their probability of being the next state of the chain.
+		if proposedBrightness > 0 && accept > 0 {
+			m.splat(&proposed, accept*m.brightness/proposedBrightness)
+		}
+		if currentBrightness > 0 && accept < 1 {
+			m.splat(&current, (1-accept)*m.brightness/currentBrightness)
+		}
+
+		if random.Float64() < accept {
+			samples.accept()
+			current, proposed = proposed, current
+			currentBrightness = proposedBrightness
+		} else {
+			samples.reject()
+		}
+	}
+}
+
+// evaluate builds the path described by samples and stores the light it carries to the camera in fs.
+// It returns the brightness of the path.
+func (m *MLT) evaluate(samples *primarySamples, fs *filmSamples) float64 {
+	fs.samples = fs.samples[:0]
+	random := fastrandom.NewWithSource(samples)
+
+	s := random.Float64()
+	t := random.Float64()
+
+	var lambda float64
+	if m.bdpt.spectral {
+		var pdf float64
+		lambda, pdf = spectral.SampleWavelength(random.Float64())
+		if pdf == 0 {
+			return 0
+		}
+	}
+
+	r := m.bdpt.camera.GetRaySampled(s, t, lambda, random)
+	l := m.bdpt.trace(r, m.world, fs, random)
+	fs.splat(s, t, m.bdpt.toFilm(l, lambda))
+
+	return fs.brightness(m.bdpt.spectral)
+}
+
+// splat adds the light stored in fs, scaled by weight, to the light image.
+func (m *MLT) splat(fs *filmSamples, weight float64) {
+	for _, fsample := range fs.samples {
+		m.image.splat(fsample.s, fsample.t, [3]float64{fsample.c[0] * weight, fsample.c[1] * weight, fsample.c[2] * weight})
+	}
+}
+
+// filmSample is light carried to the film coordinates (s, t).
+type filmSample struct {
+	s float64
+	t float64
+	c [3]float64
+}
+
+// filmSamples collects the light carried to the camera by a single path.
+type filmSamples struct {
+	samples []filmSample
+}
+
+func (fs *filmSamples) splat(s float64, t float64, c [3]float64) {
+	fs.samples = append(fs.samples, filmSample{s: s, t: t, c: c})
+}
+
+// brightness returns the total luminance of the collected light, which is the CIE Y value for spectral renders.
+// Invalid and negative values are ignored.
+func (fs *filmSamples) brightness(spectral bool) float64 {
+	var total float64
+	for _, fsample := range fs.samples {
+		y := fsample.c[1]
+		if !spectral {
+			y = adaptive.Luminance(fsample.c[0], fsample.c[1], fsample.c[2])
+		}
+
+		if y > 0 && !math.IsInf(y, 0) {
+			total += y
+		}
+	}
+
+	return total
+}
+
+// primarySample is a single number of a primary sample sequence.
+type primarySample struct {
+	value float64
+	// modified is the iteration in which the value was last changed.
+	modified int64
+	// backup and modifiedBackup hold the previous state, restored when a mutation is rejected.
+	backup         float64
+	modifiedBackup int64
+}
+
+// primarySamples is a sequence of numbers in [0, 1) that is mutated by a Markov chain. Numbers are created and
+// mutated lazily when they are used, so paths can consume as many as they need.
+type primarySamples struct {
+	random               *fastrandom.LCG
+	largeStepProbability float64
+	samples              []primarySample
+	index                int
+	iteration            int64
+	lastLargeStep        int64
+	largeStep            bool
+}
+
+func newPrimarySamples(random *fastrandom.LCG, largeStepProbability float64) *primarySamples {
+	return &primarySamples{
+		random:               random,
+		largeStepProbability: largeStepProbability,
+		largeStep:            true,
+	}
+}
+
+// Float64 returns the next number of the sequence, mutated for the current iteration.
+func (p *primarySamples) Float64() float64 {
+	if p.index == len(p.samples) {
+		// New numbers are uniformly distributed. Should the iteration be rejected, they are drawn again when
+		// next used.
+		p.samples = append(p.samples, primarySample{
+			value:          p.random.Float64(),
+			modified:       p.iteration,
+			modifiedBackup: -1,
+		})
+	}
+
+	x := &p.samples[p.index]
+	p.index++
+
+	if x.modified < p.iteration {
+		p.mutate(x)
+	}
+
+	return x.value
+}
+
+// mutate brings x up to date with the mutations of every iteration since it was last used.
+func (p *primarySamples) mutate(x *primarySample) {
+	// Numbers last used before the latest large step take a new value from it.
+	if x.modified < p.lastLargeStep {
+		x.value = p.random.Float64()
+		x.modified = p.lastLargeStep
+	}
+
+	x.backup = x.value
+	x.modifiedBackup = x.modified
+
+	if p.largeStep {
+		x.value = p.random.Float64()
+	} else {
+		// The small steps missed since the number was last used add up to a single normally distributed step.
+		u := max(2.0*p.random.Float64()-1.0, -1.0+1e-12)
+		x.value += math.Sqrt2 * math.Erfinv(u) * mutationSigma * math.Sqrt(float64(p.iteration-x.modified))
+		x.value -= math.Floor(x.value)
+		if x.value >= 1 {
+			x.value = 0
+		}
+	}
+
+	x.modified = p.iteration
+}
+
+// startIteration starts a new mutation of the sequence, which is a large step with the configured probability.
+func (p *primarySamples) startIteration() {
+	p.iteration++
+	p.largeStep = p.random.Float64() < p.largeStepProbability
+	p.index = 0
+}
+
+// accept keeps the mutation of the current iteration.
+func (p *primarySamples) accept() {
+	if p.largeStep {
+		p.lastLargeStep = p.iteration
+	}
+}
+
+// reject restores the numbers changed by the current iteration.
+func (p *primarySamples) reject() {
+	for i := range p.samples {
+		x := &p.samples[i]
+		if x.modified == p.iteration {
+			x.value = x.backup
+			x.modified = x.modifiedBackup
+		}
+	}
+
+	p.iteration--
+}
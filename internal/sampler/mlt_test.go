@@ -0,0 +1,115 @@
+package sampler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/google/go-cmp/cmp"
+)
+
+// draw returns the first n numbers of the current iteration of p.
+func draw(p *primarySamples, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = p.Float64()
+	}
+
+	return values
+}
+
+func TestPrimarySamples(t *testing.T) {
+	testData := []struct {
+		name                 string
+		largeStepProbability float64
+		accept               bool
+		// maxStep is the largest expected change of any number, taking wrapping around into account.
+		maxStep float64
+	}{
+		{name: "rejected small step", largeStepProbability: 0, accept: false, maxStep: 0.1},
+		{name: "accepted small step", largeStepProbability: 0, accept: true, maxStep: 0.1},
+		{name: "accepted large step", largeStepProbability: 1, accept: true, maxStep: 1},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			p := newPrimarySamples(fastrandom.NewWithSeed(1), test.largeStepProbability)
+			initial := draw(p, 8)
+
+			// Replaying the same seed yields the same sequence.
+			if diff := cmp.Diff(initial, draw(newPrimarySamples(fastrandom.NewWithSeed(1), 0), 8)); diff != "" {
+				t.Fatalf("initial sequence mismatch (-want +got):\n%s", diff)
+			}
+
+			p.startIteration()
+			// Paths may use more numbers than before.
+			mutated := draw(p, 12)
+			for i, v := range mutated {
+				if v < 0 || v >= 1 {
+					t.Fatalf("number %v = %v, which is outside [0, 1)", i, v)
+				}
+			}
+			for i := range initial {
+				d := math.Abs(mutated[i] - initial[i])
+				if d = min(d, 1-d); d > test.maxStep {
+					t.Errorf("number %v changed by %v, want at most %v", i, d, test.maxStep)
+				}
+			}
+
+			want := mutated[:8]
+			if test.accept {
+				p.accept()
+			} else {
+				p.reject()
+				want = initial
+			}
+
+			// Numbers that are not used by an iteration keep the value of the last accepted state.
+			p.startIteration()
+			p.reject()
+			got := make([]float64, 8)
+			for i := range got {
+				got[i] = p.samples[i].value
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("state after the iteration mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilmSamplesBrightness(t *testing.T) {
+	testData := []struct {
+		name     string
+		spectral bool
+		samples  []filmSample
+		want     float64
+	}{
+		{
+			name:    "RGB luminance",
+			samples: []filmSample{{c: [3]float64{1, 1, 1}}, {c: [3]float64{1, 0, 0}}},
+			want:    1.2126,
+		},
+		{
+			name:     "CIE Y",
+			spectral: true,
+			samples:  []filmSample{{c: [3]float64{3, 0.5, 2}}, {c: [3]float64{0, 0.25, 0}}},
+			want:     0.75,
+		},
+		{
+			name:     "invalid values are ignored",
+			spectral: true,
+			samples:  []filmSample{{c: [3]float64{0, math.NaN(), 0}}, {c: [3]float64{0, math.Inf(1), 0}}, {c: [3]float64{0, -1, 0}}},
+			want:     0,
+		},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			fs := filmSamples{samples: test.samples}
+			if got := fs.brightness(test.spectral); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("brightness() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
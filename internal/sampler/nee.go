@@ -0,0 +1,106 @@
+package sampler
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// lightSample is a shadow ray traced from a surface towards a point chosen on one of the lights.
+type lightSample struct {
+	ray ray.Ray
+	// rec and mat describe the first surface hit by the shadow ray, which may not be the light it was aimed at.
+	rec *hitrecord.HitRecord
+	mat material.Material
+	// pdf is the probability density of the direction of the shadow ray with respect to solid angle.
+	pdf float64
+	// transmittance is the fraction of the light of every channel that reaches the origin of the shadow ray
+	// through the media along it.
+	transmittance medium.Values
+}
+
+// sampleLight chooses a direction from p, which lies within the given media, towards one of the lights and traces
+// a shadow ray along it. The shadow ray crosses the boundaries of media and is occluded if the first surface it hits
+// does not emit light, in which case false is returned. The transmittance of the media is computed for every channel
+// of s.
+// Emitters that are not part of lightShape are still visible to it, which is accounted for by the MIS weights
+// because lightShape.PDFValue is used by both sampling strategies.
+func sampleLight(r ray.Ray, p vec3.Vec3Impl, media mediumStack, s medium.Spectrum, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, numRays *uint64) (lightSample, bool) {
+	if lights, ok := lightShape.(*hitable.HitableSlice); ok && lights.Len() == 0 {
+		return lightSample{}, false
+	}
+
+	direction := lightShape.Random(p, random)
+	pdfVal := lightShape.PDFValue(p, direction)
+	if pdfVal <= 0 || math.IsNaN(pdfVal) || math.IsInf(pdfVal, 0) {
+		return lightSample{}, false
+	}
+
+	shadow := ray.NewWithLambda(p, direction, r.Time(), r.Lambda())
+	transmittance := s.Ones()
+	tMin := 0.001
+	for range maxBoundaryCrossings {
+		atomic.AddUint64(numRays, 1)
+
+		rec, mat, ok := world.Hit(shadow, tMin, math.MaxFloat64)
+		if !ok {
+			return lightSample{}, false
+		}
+
+		if m := media.current(); m != nil {
+			transmittance = transmittance.Mul(m.Transmittance(shadow, tMin, rec.T(), s, random))
+		}
+
+		transmittance = transmittance.Mul(media.absorption(shadow, tMin, rec.T(), s))
+
+		switch m := mat.(type) {
+		case *material.MediumBoundary:
+			media.cross(shadow, rec, volume{medium: m.Medium()})
+		case *material.Dielectric:
+			// Dielectrics refract the shadow ray, which can then no longer reach the light, unless it crosses
+			// one whose surface is ignored.
+			if media.isInterface(m) {
+				return lightSample{}, false
+			}
+			media.cross(shadow, rec, volume{dielectric: m})
+		default:
+			if !mat.IsEmitter() {
+				return lightSample{}, false
+			}
+			return lightSample{ray: shadow, rec: rec, mat: mat, pdf: pdfVal, transmittance: transmittance}, true
+		}
+
+		tMin = rec.T() + 0.001
+	}
+
+	return lightSample{}, false
+}
+
+// emissionWeight returns the MIS weight of the light emitted by a surface hit by r, which was scattered from p
+// in a direction sampled with the given density. A density of zero means that the direction was chosen by a camera
+// or a specular bounce, which next-event estimation cannot sample, so the emission is not weighted.
+func emissionWeight(r ray.Ray, p vec3.Vec3Impl, bsdfPDF float64, lightShape hitable.Hitable) float64 {
+	if bsdfPDF == 0 {
+		return 1.0
+	}
+
+	return powerHeuristic(bsdfPDF, lightShape.PDFValue(p, r.Direction()))
+}
+
+// powerHeuristic returns the MIS weight of a sample taken with density f when the same direction can also be sampled
+// with density g, using the power heuristic with an exponent of 2.
+func powerHeuristic(f float64, g float64) float64 {
+	f2 := f * f
+	if f2 == 0 {
+		return 0
+	}
+
+	return f2 / (f2 + g*g)
+}
@@ -0,0 +1,391 @@
+package sampler
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	"github.com/flynn-nrg/izpi/internal/pdf"
+	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// Ensure interface compliance.
+var _ PhotonSampler = (*PPM)(nil)
+
+const (
+	// Default number of photons emitted for every pass of the photon mapping sampler.
+	DefaultPhotons = 500000
+	// Default rate at which the photon mapping sampler shrinks its lookups as samples are added.
+	DefaultPhotonAlpha = 0.7
+
+	// defaultRadiusScale is the initial radius of photon lookups relative to the size of the scene, used when
+	// no radius is set.
+	defaultRadiusScale = 0.002
+	// wavelengthWidth is the initial range of wavelengths, in nanometres, that spectral photons are gathered over.
+	wavelengthWidth = 20.0
+	// photonStream tells the seeds of photons apart from those of other random sequences.
+	photonStream = 2
+)
+
+// PhotonConfig holds the settings of the photon mapping sampler.
+type PhotonConfig struct {
+	// Seed determines the photons emitted for every pass, so renders with the same seed and settings are the same
+	// regardless of how the work is split across workers.
+	Seed uint64
+	// Photons is the number of photons emitted for every pass.
+	Photons int
+	// Radius is the initial radius of photon lookups. It is derived from the size of the scene if not set.
+	Radius float64
+	// Alpha, between 0 and 1, controls how fast lookups shrink as samples are added. Lower values shrink them
+	// faster, which reduces blurring at the cost of more noise.
+	Alpha float64
+}
+
+// PPM is a progressive photon mapping sampler for caustics, following "Progressive Photon Mapping: A Probabilistic
+// Approach" by Knaus and Zwicker. Photons are emitted from the lights and stored where they reach a diffuse surface
+// after one or more specular bounces. Camera paths are traced with next-event estimation as in Colour.trace, and
+// the caustics arriving at the first diffuse surface they hit are gathered from the photons around it instead of
+// being found by chance. A new photon map is built for every pass with a smaller lookup radius than the previous
+// one, so the blurring caused by gathering fades as the render converges. Spectral photons carry a single
+// wavelength and are only gathered by camera paths of similar wavelengths, which also narrow from pass to pass.
+type PPM struct {
+	bdpt   bdpt
+	config PhotonConfig
+	world  *hitable.HitableSlice
+	radius float64
+	// photons holds the map of the current pass, which is replaced under mu.
+	photons atomic.Pointer[photonMap]
+	mu      sync.Mutex
+}
+
+// NewPPM returns a new RGB photon mapping sampler that emits photons from lights into world.
+func NewPPM(config PhotonConfig, depth Depth, background vec3.Vec3Impl, world *hitable.HitableSlice, lights *hitable.HitableSlice, numRays *uint64) *PPM {
+	p := &PPM{
+		bdpt: bdpt{
+			depth:      depth,
+			numRays:    numRays,
+			background: background,
+		},
+	}
+	p.setup(config, world, lights)
+
+	return p
+}
+
+// NewSpectralPPM returns a new spectral photon mapping sampler that emits photons from lights into world.
+func NewSpectralPPM(config PhotonConfig, depth Depth, background *spectral.SpectralPowerDistribution, world *hitable.HitableSlice, lights *hitable.HitableSlice, numRays *uint64) *PPM {
+	p := &PPM{
+		bdpt: bdpt{
+			depth:              depth,
+			numRays:            numRays,
+			spectral:           true,
+			spectralBackground: background,
+		},
+	}
+	p.setup(config, world, lights)
+
+	return p
+}
+
+func (p *PPM) setup(config PhotonConfig, world *hitable.HitableSlice, lights *hitable.HitableSlice) {
+	if config.Photons <= 0 {
+		config.Photons = DefaultPhotons
+	}
+	if config.Alpha <= 0 || config.Alpha > 1 {
+		config.Alpha = DefaultPhotonAlpha
+	}
+
+	p.config = config
+	p.world = world
+	p.radius = config.Radius
+	if p.radius <= 0 {
+		p.radius = defaultRadiusScale
+		if box, ok := world.BoundingBox(0, 1); ok {
+			p.radius *= vec3.Sub(box.Max(), box.Min()).Length()
+		}
+	}
+	p.bdpt.setLights(lights)
+}
+
+// PreparePass builds the photon map used by the samples of the pass that starts after firstSample samples per
+// pixel. Photons are seeded from the seed of the sampler and firstSample, so every worker builds the same map for
+// the same pass.
+func (p *PPM) PreparePass(firstSample int) {
+	if m := p.photons.Load(); m != nil && m.firstSample == firstSample {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if m := p.photons.Load(); m != nil && m.firstSample == firstSample {
+		return
+	}
+
+	p.photons.Store(p.buildMap(firstSample))
+}
+
+func (p *PPM) Sample(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, _ int, random *fastrandom.LCG) vec3.Vec3Impl {
+	if !p.bdpt.spectral {
+		return p.trace(r, world, lightShape, random)
+	}
+
+	// Camera rays are assigned a wavelength in the same way as in Spectral.Sample.
+	if r.Lambda() == 0.0 {
+		wavelength, _ := spectral.SampleWavelength(random.Float64())
+		r.SetLambda(wavelength)
+	}
+
+	spectralValue := p.trace(r, world, lightShape, random).X
+	red, green, blue := spectral.WavelengthToRGB(r.Lambda())
+
+	return vec3.Vec3Impl{
+		X: red * spectralValue,
+		Y: green * spectralValue,
+		Z: blue * spectralValue,
+	}
+}
+
+func (p *PPM) SampleSpectral(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, _ int, random *fastrandom.LCG) float64 {
+	return p.trace(r, world, lightShape, random).X
+}
+
+// buildMap emits the photons of a pass in parallel and stores the ones that take part in caustics. Every photon
+// has a seed of its own, so the result does not depend on the number of threads.
+func (p *PPM) buildMap(firstSample int) *photonMap {
+	n := p.config.Photons
+	numWorkers := runtime.GOMAXPROCS(0)
+	perWorker := (n + numWorkers - 1) / numWorkers
+	stored := make([][]photon, numWorkers)
+
+	wg := sync.WaitGroup{}
+	for w, i0 := 0, 0; i0 < n; w, i0 = w+1, i0+perWorker {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := i0; i < min(i0+perWorker, n); i++ {
+				random := fastrandom.NewWithSeed(fastrandom.Hash(p.config.Seed, photonStream, uint64(firstSample), uint64(i)))
+				if ph, ok := p.emit(random); ok {
+					stored[w] = append(stored[w], ph)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var photons []photon
+	for _, s := range stored {
+		photons = append(photons, s...)
+	}
+
+	// Lookups shrink so that their volume, over the surface and the spectrum, falls in proportion to
+	// (samples)^(alpha-1), which makes both the blurring and the noise vanish as samples are added.
+	shrink := math.Pow(float64(firstSample+1), p.config.Alpha-1)
+	radius := p.radius * math.Sqrt(shrink)
+	width := 0.0
+	if p.bdpt.spectral {
+		radius = p.radius * math.Cbrt(shrink)
+		width = wavelengthWidth * math.Cbrt(shrink)
+	}
+
+	m := newPhotonMap(photons, radius)
+	m.firstSample = firstSample
+	m.width = width
+
+	return m
+}
+
+// emit traces a photon from a point chosen on the lights. It returns the photon where it first reaches a diffuse
+// surface if it has been reflected or refracted specularly on the way.
+func (p *PPM) emit(random *fastrandom.LCG) (photon, bool) {
+	b := &p.bdpt
+	if len(b.lights) == 0 {
+		return photon{}, false
+	}
+
+	i := sort.SearchFloat64s(b.cdf, random.Float64()*b.lightArea)
+	rec, mat := b.lights[min(i, len(b.lights)-1)].SampleSurface(random)
+
+	// Spectral photons are given a wavelength chosen uniformly over the visible spectrum.
+	var lambda float64
+	pdfLambda := 1.0
+	if b.spectral {
+		lambda = spectral.WavelengthMin + random.Float64()*(spectral.WavelengthMax-spectral.WavelengthMin)
+		pdfLambda = 1.0 / (spectral.WavelengthMax - spectral.WavelengthMin)
+	}
+
+	v := vertex{typ: lightVertex, p: rec.P(), n: rec.Normal(), rec: rec, mat: mat}
+	emission := pdf.NewCosine(v.n)
+	dir := emission.Generate(random)
+	pdfDir := emission.Value(dir)
+	if pdfDir <= 0 {
+		return photon{}, false
+	}
+
+	var r ray.Ray = ray.NewWithLambda(v.p, dir, 0, lambda)
+	le := b.leaving(&v, vec3.UnitVector(dir), r)
+
+	// Le * cos(θ) / (pdfPos * pdfDir * pdfLambda), where pdfPos = 1 / lightArea
+	cosine := math.Abs(vec3.Dot(v.n, vec3.UnitVector(dir)))
+	power := vec3.ScalarMul(le, cosine*b.lightArea/(pdfDir*pdfLambda))
+	if power.X == 0 && power.Y == 0 && power.Z == 0 {
+		return photon{}, false
+	}
+
+	var depth pathDepth
+	for bounces := 0; bounces < b.depth.Max; bounces++ {
+		atomic.AddUint64(b.numRays, 1)
+
+		rec, mat, ok := p.world.Hit(r, 0.001, math.MaxFloat64)
+		if !ok {
+			return photon{}, false
+		}
+
+		bs, ok := b.scatter(r, rec, mat, random)
+		if !ok {
+			return photon{}, false
+		}
+
+		if !bs.specular {
+			// Light reaching diffuse surfaces directly is sampled by the camera paths.
+			if bounces == 0 {
+				return photon{}, false
+			}
+
+			return photon{p: rec.P(), dir: vec3.UnitVector(r.Direction()), power: power, lambda: lambda}, true
+		}
+
+		scattered := bs.specularRay
+		if !depth.bounce(b.depth, scatterType(r, rec, mat, true, scattered)) {
+			return photon{}, false
+		}
+
+		power = vec3.Mul(power, bs.attenuation)
+		r = scattered
+	}
+
+	return photon{}, false
+}
+
+// trace follows the path of r and returns the light arriving along it. Caustics that reach the first diffuse
+// surface of the path are gathered from the photon map, so light that gets to the lights through specular surfaces
+// only from that point on is left out to avoid counting it twice.
+func (p *PPM) trace(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) vec3.Vec3Impl {
+	b := &p.bdpt
+	m := p.photons.Load()
+	if m == nil {
+		p.PreparePass(0)
+		m = p.photons.Load()
+	}
+
+	var l vec3.Vec3Impl
+	throughput := vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0}
+
+	// bsdfPDF is the density with which the direction of r was sampled at the previous bounce, located at prev.
+	// It is zero for camera rays and after specular bounces.
+	var bsdfPDF float64
+	var prev vec3.Vec3Impl
+	var depth pathDepth
+
+	// gathered is set once the photon map has been gathered from, and caustic while the path has only been
+	// scattered specularly since then, with specular counting those bounces.
+	var gathered, caustic bool
+	var specular int
+
+	for bounces := 0; bounces < b.depth.Max; bounces++ {
+		atomic.AddUint64(b.numRays, 1)
+
+		rec, mat, ok := world.Hit(r, 0.001, math.MaxFloat64)
+		if !ok {
+			return vec3.Add(l, vec3.Mul(throughput, b.backgroundValue(r)))
+		}
+
+		if emitted := b.emitted(r, rec, mat); (emitted.X != 0 || emitted.Y != 0 || emitted.Z != 0) &&
+			!(caustic && specular > 0 && b.lightMaterials[mat]) {
+			w := emissionWeight(r, prev, bsdfPDF, lightShape)
+			l = vec3.Add(l, vec3.ScalarMul(vec3.Mul(throughput, emitted), w))
+		}
+
+		bs, ok := b.scatter(r, rec, mat, random)
+		if !ok {
+			return l
+		}
+
+		if bs.specular {
+			scattered := bs.specularRay
+			if !depth.bounce(b.depth, scatterType(r, rec, mat, true, scattered)) {
+				return l
+			}
+
+			throughput = vec3.Mul(throughput, bs.attenuation)
+			r = scattered
+			bsdfPDF = 0
+			specular++
+		} else {
+			if !gathered {
+				v := vertex{typ: surfaceVertex, p: rec.P(), n: rec.Normal(), rec: rec, mat: mat, in: r, bsdf: bs, scatters: true}
+				l = vec3.Add(l, vec3.Mul(throughput, p.gather(m, &v, r.Lambda())))
+				gathered, caustic, specular = true, true, 0
+			} else {
+				caustic = false
+			}
+
+			// Light reaching this surface directly from the lights.
+			if ls, ok := sampleLight(r, rec.P(), nil, medium.RGB(), world, lightShape, random, b.numRays); ok {
+				emitted := vec3.Mul(b.emitted(ls.ray, ls.rec, ls.mat), rgb(ls.transmittance))
+				if f := b.evaluate(r, rec, mat, bs.attenuation, ls.ray); f != (vec3.Vec3Impl{}) {
+					// bsdf * emitted * weight / pdf
+					w := powerHeuristic(ls.pdf, bs.pdf.Value(ls.ray.Direction()))
+					l = vec3.Add(l, vec3.Mul(vec3.Mul(throughput, f), vec3.ScalarMul(emitted, w/ls.pdf)))
+				}
+			}
+
+			scattered := ray.NewWithLambda(rec.P(), bs.pdf.Generate(random), r.Time(), r.Lambda())
+			pdfVal := bs.pdf.Value(scattered.Direction())
+			if pdfVal <= 0 || !depth.bounce(b.depth, scatterType(r, rec, mat, false, scattered)) {
+				return l
+			}
+
+			// bsdf / pdf
+			throughput = vec3.Mul(throughput, vec3.ScalarDiv(b.evaluate(r, rec, mat, bs.attenuation, scattered), pdfVal))
+			prev, bsdfPDF = rec.P(), pdfVal
+			r = scattered
+		}
+
+		q := b.depth.roulette(bounces+1, max(throughput.X, throughput.Y, throughput.Z), random)
+		if q == 0 {
+			return l
+		}
+		throughput = vec3.ScalarMul(throughput, q)
+	}
+
+	return l
+}
+
+// gather returns the light carried by the photons around the surface vertex v that is scattered back along the
+// ray that reached it, at the given wavelength for spectral photons.
+func (p *PPM) gather(m *photonMap, v *vertex, lambda float64) vec3.Vec3Impl {
+	var sum vec3.Vec3Impl
+	m.lookup(v.p, func(ph *photon) {
+		if p.bdpt.spectral && math.Abs(ph.lambda-lambda) > m.width/2 {
+			return
+		}
+
+		out := ray.NewWithLambda(v.p, vec3.ScalarMul(ph.dir, -1), v.in.Time(), lambda)
+		sum = vec3.Add(sum, vec3.Mul(p.bdpt.f(v, out), ph.power))
+	})
+
+	// Σ f * Φ / (N * π * r²), also divided by the width of the range of wavelengths for spectral photons.
+	area := float64(p.config.Photons) * math.Pi * m.radius * m.radius
+	if p.bdpt.spectral {
+		area *= m.width
+	}
+
+	return vec3.ScalarDiv(sum, area)
+}
@@ -0,0 +1,120 @@
+package sampler
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// photon is a packet of light that reached a surface.
+type photon struct {
+	p vec3.Vec3Impl
+	// dir is the unit direction the photon was travelling in when it reached p.
+	dir vec3.Vec3Impl
+	// power is the flux carried by the photon, which must be divided by the number of photons emitted.
+	power  vec3.Vec3Impl
+	lambda float64
+}
+
+// photonMap stores photons in a hash grid whose cells are as wide as the diameter of the lookups, so the
+// photons within the radius of any point are found in at most eight cells.
+type photonMap struct {
+	// firstSample is the number of samples per pixel rendered before the pass that the map was built for.
+	firstSample int
+	// radius is the radius of lookups and width the range of wavelengths around the one of a lookup that
+	// photons must be within.
+	radius   float64
+	width    float64
+	cellSize float64
+	mask     uint64
+	// photons are sorted by bucket, and the photons of bucket i are photons[start[i]:start[i+1]].
+	photons []photon
+	start   []int
+}
+
+// newPhotonMap builds a map holding photons for lookups with the given radius.
+func newPhotonMap(photons []photon, radius float64) *photonMap {
+	numBuckets := 1
+	for numBuckets < 2*len(photons) {
+		numBuckets <<= 1
+	}
+
+	m := &photonMap{
+		radius:   radius,
+		cellSize: 2.0 * radius,
+		mask:     uint64(numBuckets - 1),
+		photons:  make([]photon, len(photons)),
+		start:    make([]int, numBuckets+1),
+	}
+
+	buckets := make([]uint64, len(photons))
+	for i := range photons {
+		buckets[i] = m.bucket(m.cell(photons[i].p))
+		m.start[buckets[i]+1]++
+	}
+	for i := 1; i < len(m.start); i++ {
+		m.start[i] += m.start[i-1]
+	}
+
+	next := make([]int, numBuckets)
+	copy(next, m.start)
+	for i := range photons {
+		m.photons[next[buckets[i]]] = photons[i]
+		next[buckets[i]]++
+	}
+
+	return m
+}
+
+// cell returns the coordinates of the cell that contains p.
+func (m *photonMap) cell(p vec3.Vec3Impl) [3]int64 {
+	return [3]int64{
+		int64(math.Floor(p.X / m.cellSize)),
+		int64(math.Floor(p.Y / m.cellSize)),
+		int64(math.Floor(p.Z / m.cellSize)),
+	}
+}
+
+// bucket returns the bucket that the photons in cell c are stored in.
+func (m *photonMap) bucket(c [3]int64) uint64 {
+	return fastrandom.Hash(uint64(c[0]), uint64(c[1]), uint64(c[2])) & m.mask
+}
+
+// lookup calls fn for every photon within the radius of the map around p.
+func (m *photonMap) lookup(p vec3.Vec3Impl, fn func(ph *photon)) {
+	if len(m.photons) == 0 {
+		return
+	}
+
+	r := vec3.Vec3Impl{X: m.radius, Y: m.radius, Z: m.radius}
+	lo := m.cell(vec3.Sub(p, r))
+	hi := m.cell(vec3.Add(p, r))
+	r2 := m.radius * m.radius
+
+	// Different cells may share a bucket, which must only be visited once.
+	var visited [27]uint64
+	numVisited := 0
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+		cells:
+			for z := lo[2]; z <= hi[2]; z++ {
+				b := m.bucket([3]int64{x, y, z})
+				for _, v := range visited[:numVisited] {
+					if v == b {
+						continue cells
+					}
+				}
+				visited[numVisited] = b
+				numVisited++
+
+				for i := m.start[b]; i < m.start[b+1]; i++ {
+					ph := &m.photons[i]
+					if vec3.Sub(ph.p, p).SquaredLength() <= r2 {
+						fn(ph)
+					}
+				}
+			}
+		}
+	}
+}
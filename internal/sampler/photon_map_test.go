@@ -0,0 +1,55 @@
+package sampler
+
+import (
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestPhotonMapLookup(t *testing.T) {
+	testData := []struct {
+		name       string
+		numPhotons int
+		radius     float64
+	}{
+		{name: "empty map", numPhotons: 0, radius: 0.1},
+		{name: "small radius", numPhotons: 2000, radius: 0.05},
+		{name: "large radius", numPhotons: 2000, radius: 0.4},
+		{name: "single photon", numPhotons: 1, radius: 2},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			random := fastrandom.NewWithSeed(1)
+			point := func() vec3.Vec3Impl {
+				return vec3.Vec3Impl{X: 2*random.Float64() - 1, Y: 2*random.Float64() - 1, Z: 2*random.Float64() - 1}
+			}
+
+			photons := make([]photon, test.numPhotons)
+			for i := range photons {
+				// The wavelength tells photons apart.
+				photons[i] = photon{p: point(), lambda: float64(i)}
+			}
+			m := newPhotonMap(photons, test.radius)
+
+			for range 100 {
+				p := point()
+				found := make(map[float64]int)
+				m.lookup(p, func(ph *photon) {
+					found[ph.lambda]++
+				})
+
+				for i, ph := range photons {
+					inside := vec3.Sub(ph.p, p).SquaredLength() <= test.radius*test.radius
+					switch n := found[float64(i)]; {
+					case inside && n != 1:
+						t.Fatalf("photon %v at %v was found %v times around %v, want once", i, ph.p, n, p)
+					case !inside && n != 0:
+						t.Fatalf("photon %v at %v was found %v times around %v, want none", i, ph.p, n, p)
+					}
+				}
+			}
+		})
+	}
+}
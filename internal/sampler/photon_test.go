@@ -0,0 +1,32 @@
+package sampler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/scenes"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+func TestPPMSeed(t *testing.T) {
+	var numRays uint64
+	depth := Depth{Max: 10, Diffuse: 10, Specular: 10, Transmission: 10, Volume: 10, RussianRoulette: 3}
+	sc := scenes.CornellBox(1)
+
+	photons := func(seed uint64) []photon {
+		p := NewPPM(PhotonConfig{Seed: seed, Photons: 20000}, depth, vec3.Vec3Impl{}, sc.World, sc.Lights, &numRays)
+		m := p.buildMap(3)
+		if len(m.photons) == 0 {
+			t.Fatalf("buildMap() stored no photons")
+		}
+		return m.photons
+	}
+
+	want := photons(7)
+	if got := photons(7); !slices.Equal(got, want) {
+		t.Errorf("photon maps built with the same seed differ")
+	}
+	if got := photons(8); slices.Equal(got, want) {
+		t.Errorf("photon maps built with different seeds are identical")
+	}
+}
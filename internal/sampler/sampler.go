@@ -2,9 +2,11 @@
 package sampler
 
 import (
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/hitable"
 	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
@@ -17,14 +19,26 @@ const (
 	WireFrameSampler
 	AlbedoSampler
 	SpectralSampler
+	BDPTSampler
+	SpectralBDPTSampler
+	MLTSampler
+	SpectralMLTSampler
+	PPMSampler
+	SpectralPPMSampler
 )
 
 var samplerMap = map[string]SamplerType{
-	"colour":    ColourSampler,
-	"normal":    NormalSampler,
-	"wireframe": WireFrameSampler,
-	"albedo":    AlbedoSampler,
-	"spectral":  SpectralSampler,
+	"colour":        ColourSampler,
+	"normal":        NormalSampler,
+	"wireframe":     WireFrameSampler,
+	"albedo":        AlbedoSampler,
+	"spectral":      SpectralSampler,
+	"bdpt":          BDPTSampler,
+	"spectral-bdpt": SpectralBDPTSampler,
+	"mlt":           MLTSampler,
+	"spectral-mlt":  SpectralMLTSampler,
+	"ppm":           PPMSampler,
+	"spectral-ppm":  SpectralPPMSampler,
 }
 
 type Sampler interface {
@@ -32,6 +46,82 @@ type Sampler interface {
 	SampleSpectral(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, depth int, random *fastrandom.LCG) float64
 }
 
+// AOVSampler is implemented by samplers that can record AOVs while sampling a camera ray.
+type AOVSampler interface {
+	SampleAOV(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, s *aov.Sample) vec3.Vec3Impl
+}
+
+// SpectralAOVSampler is implemented by spectral samplers that can record AOVs while sampling a camera ray.
+type SpectralAOVSampler interface {
+	SampleSpectralAOV(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, s *aov.Sample) float64
+}
+
+// HeroSampler is implemented by spectral samplers that trace every wavelength of a set chosen by
+// spectral.SampleHeroWavelengths at once.
+type HeroSampler interface {
+	// SampleWavelengths returns the radiance arriving along r, which carries a set of hero wavelengths, at each of
+	// them. Dividing every value by spectral.HeroWavelengthPDF at its wavelength gives its contribution to the
+	// estimate of the pixel.
+	SampleWavelengths(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) [spectral.NumHeroWavelengths]float64
+}
+
+// HeroAOVSampler is implemented by hero wavelength samplers that can record AOVs while sampling a camera ray.
+type HeroAOVSampler interface {
+	// SampleWavelengthsAOV samples r like SampleWavelengths and records its AOVs at the hero wavelength, weighted so
+	// that they can be resolved in the same way as those recorded by SampleSpectralAOV.
+	SampleWavelengthsAOV(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, s *aov.Sample) [spectral.NumHeroWavelengths]float64
+}
+
+// ChainSampler is implemented by samplers that render the image by running Markov chains instead of sampling camera
+// rays through every pixel. A chain is run for every pixel and the light it finds is splatted to a light image.
+type ChainSampler interface {
+	Sampler
+	// RunChain runs the chain of the given pixel for the given number of mutations. firstMutation is the number of
+	// mutations already run for the pixel by previous passes.
+	RunChain(pixel int, firstMutation int, mutations int)
+}
+
+// LightPathSampler is implemented by samplers that splat the light that light paths carry to the camera, which can
+// land on any pixel, to a light image.
+type LightPathSampler interface {
+	Sampler
+	// WithLightImage returns a sampler that shares the state of this one but splats light to image instead.
+	WithLightImage(image *TileLightImage) Sampler
+}
+
+// PhotonSampler is implemented by samplers that gather light from a photon map, which is built anew for every pass.
+type PhotonSampler interface {
+	Sampler
+	// PreparePass builds the photon map of the pass that starts after firstSample samples per pixel. It must be
+	// called before any of the samples of the pass are taken.
+	PreparePass(firstSample int)
+}
+
+// SupportsAOVs returns whether samplers of the given type can record AOVs.
+func SupportsAOVs(t SamplerType) bool {
+	return t == ColourSampler || t == SpectralSampler
+}
+
+// IsSpectral returns whether samplers of the given type render in CIE XYZ space.
+func IsSpectral(t SamplerType) bool {
+	return t == SpectralSampler || t == SpectralBDPTSampler || t == SpectralMLTSampler || t == SpectralPPMSampler
+}
+
+// UsesLightImage returns whether samplers of the given type splat light to a light image.
+func UsesLightImage(t SamplerType) bool {
+	return t == BDPTSampler || t == SpectralBDPTSampler || IsMetropolis(t)
+}
+
+// IsMetropolis returns whether samplers of the given type render the image by running Markov chains.
+func IsMetropolis(t SamplerType) bool {
+	return t == MLTSampler || t == SpectralMLTSampler
+}
+
+// IsPhotonMapping returns whether samplers of the given type gather caustics from a photon map.
+func IsPhotonMapping(t SamplerType) bool {
+	return t == PPMSampler || t == SpectralPPMSampler
+}
+
 func StringToType(s string) SamplerType {
 	return samplerMap[s]
 }
@@ -1,28 +1,32 @@
 package sampler
 
 import (
-	"math"
-	"sync/atomic"
-
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
 	"github.com/flynn-nrg/izpi/internal/hitable"
-	"github.com/flynn-nrg/izpi/internal/pdf"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
 	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/scatterrecord"
 	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
 var _ Sampler = (*Spectral)(nil)
+var _ SpectralAOVSampler = (*Spectral)(nil)
+var _ HeroSampler = (*Spectral)(nil)
+var _ HeroAOVSampler = (*Spectral)(nil)
 
 type Spectral struct {
-	maxDepth   int
+	depth      Depth
 	numRays    *uint64
 	background *spectral.SpectralPowerDistribution
 }
 
-func NewSpectral(maxDepth int, background *spectral.SpectralPowerDistribution, numRays *uint64) *Spectral {
+func NewSpectral(depth Depth, background *spectral.SpectralPowerDistribution, numRays *uint64) *Spectral {
 	return &Spectral{
-		maxDepth:   maxDepth,
+		depth:      depth,
 		numRays:    numRays,
 		background: background,
 	}
@@ -45,38 +49,295 @@ func (ns *NonSpectral) SampleSpectral(r ray.Ray, world *hitable.HitableSlice, li
 }
 
 func (s *Spectral) SampleSpectral(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, depth int, random *fastrandom.LCG) float64 {
-	if depth >= s.maxDepth {
-		// Return the background spectral power distribution at the wavelength of the ray
-		return s.background.Value(r.Lambda())
+	return s.trace(r, world, lightShape, depth, random, nil)[0]
+}
+
+// SampleWavelengths samples a camera ray that carries a set of wavelengths chosen by spectral.SampleHeroWavelengths.
+func (s *Spectral) SampleWavelengths(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) [spectral.NumHeroWavelengths]float64 {
+	return s.trace(r, world, lightShape, 0, random, nil)
+}
+
+// SampleWavelengthsAOV samples a camera ray like SampleWavelengths and records its AOVs at the hero wavelength.
+func (s *Spectral) SampleWavelengthsAOV(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, as *aov.Sample) [spectral.NumHeroWavelengths]float64 {
+	return s.trace(r, world, lightShape, 0, random, as)
+}
+
+// SampleSpectralAOV samples a camera ray like SampleSpectral and records its AOVs at the wavelength of the ray.
+// The light path AOVs and light groups are recorded in the same way as in Colour.SampleAOV.
+func (s *Spectral) SampleSpectralAOV(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG, as *aov.Sample) float64 {
+	return s.trace(r, world, lightShape, 0, random, as)[0]
+}
+
+// spectralValues holds a value for every wavelength traced along a path, with the hero wavelength first.
+type spectralValues [spectral.NumHeroWavelengths]float64
+
+func (v spectralValues) mul(o spectralValues) spectralValues {
+	for i := range v {
+		v[i] *= o[i]
+	}
+	return v
+}
+
+func (v spectralValues) scale(f float64) spectralValues {
+	for i := range v {
+		v[i] *= f
+	}
+	return v
+}
+
+func (v spectralValues) isZero() bool {
+	return v == spectralValues{}
+}
+
+// trace follows the path of r from the given depth and returns the radiance arriving along it at the wavelengths
+// of the ray. Direct lighting is estimated, media are traced and paths are terminated in the same way as in
+// Colour.trace, with every wavelength sharing the same collisions within media.
+// AOVs are recorded in as unless it is nil.
+//
+// Rays that carry a set of hero wavelengths are traced at all of them at once. If the path is scattered in a way
+// that depends on the wavelength, the other wavelengths are dropped and the radiance found from then on at the hero
+// wavelength is scaled so that dividing it by spectral.HeroWavelengthPDF yields its single wavelength estimate.
+// AOVs are recorded at the hero wavelength without that scale, so that they are single wavelength estimates like
+// the ones of rays that carry a single wavelength.
+func (s *Spectral) trace(r ray.Ray, world *hitable.HitableSlice, lightShape hitable.Hitable, depth int, random *fastrandom.LCG, as *aov.Sample) spectralValues {
+	wavelengths, hero := r.Wavelengths()
+	n := 1
+	if hero {
+		n = spectral.NumHeroWavelengths
+	} else {
+		wavelengths[0] = r.Lambda()
 	}
 
-	atomic.AddUint64(s.numRays, 1)
+	// at returns the value of f at every wavelength traced along the path.
+	at := func(f func(lambda float64) float64) spectralValues {
+		var v spectralValues
+		for i := range n {
+			v[i] = f(wavelengths[i])
+		}
+		return v
+	}
+
+	var radiance spectralValues
+	throughput := at(func(float64) float64 { return 1.0 })
+	// aovScale undoes the scale applied to the radiance of the hero wavelength once the others are dropped.
+	aovScale := 1.0
+	direct, indirect := aov.DiffuseDirect, aov.DiffuseIndirect
+
+	// bsdfPDF is the density with which the direction of r was sampled at the previous bounce, located at p.
+	// It is zero for camera rays and after specular bounces.
+	var bsdfPDF float64
+	var p vec3.Vec3Impl
+	var path pathDepth
+
+	// add records radiance reaching the camera after the given number of bounces, emitted by surfaces with the
+	// material lightGroup or by the background if it is zero.
+	add := func(bounces int, v spectralValues, lightGroup uint32) {
+		if as != nil {
+			l := v[0] * aovScale
+			switch {
+			case bounces == 0:
+				as.AddSpectralLighting(aov.Emission, l)
+			case bounces == 1:
+				as.AddSpectralLighting(direct, l)
+			default:
+				as.AddSpectralLighting(indirect, l)
+			}
+			if lightGroup != 0 {
+				as.AddSpectralLightGroup(lightGroup, l)
+			}
+		}
+		for i := range v {
+			radiance[i] += v[i]
+		}
+	}
+
+	// media holds the media that the path is travelling through.
+	var media mediumStack
 
 	// L(λ) = Le(λ) + ∫ f(λ) * L(λ) * cos(θ) / p(ω) dω
-	if rec, mat, ok := world.Hit(r, 0.001, math.MaxFloat64); ok {
-		_, srec, ok := mat.SpectralScatter(r, rec, random)
-		emitted := mat.EmittedSpectral(r, rec, rec.U(), rec.V(), r.Lambda(), rec.P())
-		if depth < s.maxDepth && ok {
+	for bounces := 0; ; bounces++ {
+		if depth+bounces >= s.depth.Max {
+			return radiance
+		}
+
+		spectrum := medium.Wavelengths(wavelengths, n)
+		in, weight, emitted, ok := nextInteraction(r, world, &media, spectrum, random, s.numRays)
+		if emitted != (medium.Values{}) {
+			add(bounces, throughput.mul(spectralValues(emitted)), 0)
+		}
+		throughput = throughput.mul(spectralValues(weight))
+		if !ok {
+			add(bounces, throughput.mul(at(s.background.Value)), 0)
+			return radiance
+		}
+
+		if in.collided() {
+			// Radiance reaching this point in the medium directly from the lights arrives after one more bounce.
+			x := r.PointAtParameter(in.t)
+			phase := in.medium.Phase()
+			if light, id, ok := s.sampleMediumDirect(r, x, phase, media, spectrum, at, world, lightShape, random); ok {
+				add(bounces+1, throughput.mul(light), id)
+			}
+
+			if !path.bounce(s.depth, volumeBounce) {
+				return radiance
+			}
+
+			// The direction is sampled with the density of the phase function, which cancels out.
+			direction, pdfVal := phase.Sample(r.Direction(), random)
+			p, bsdfPDF = x, pdfVal
+			r = ray.NewWithLambda(x, direction, r.Time(), r.Lambda())
+		} else {
+			rec, mat := in.rec, in.mat
+
+			if bounces == 0 && as != nil {
+				as.SetHit(r, rec)
+				as.SetSpectralAlbedo(mat.SpectralAlbedo(rec.U(), rec.V(), r.Lambda(), rec.P()))
+			}
+
+			emitted := at(func(lambda float64) float64 {
+				return mat.EmittedSpectral(r, rec, rec.U(), rec.V(), lambda, rec.P())
+			})
+			if !emitted.isZero() {
+				w := emissionWeight(r, p, bsdfPDF, lightShape)
+				add(bounces, throughput.mul(emitted).scale(w), rec.MaterialID())
+			}
+
+			var srec *scatterrecord.SpectralScatterRecord
+			if d, isDielectric := mat.(*material.Dielectric); isDielectric {
+				// Light is refracted according to the dielectric on the other side of the surface.
+				_, srec, ok = d.SpectralScatterNested(r, rec, random, func(lambda float64) float64 {
+					if o := media.dielectric(d); o != nil {
+						return o.SpectralRefractiveIndex(lambda)
+					}
+					return 1.0
+				})
+			} else {
+				_, srec, ok = mat.SpectralScatter(r, rec, random)
+			}
+			if !ok {
+				return radiance
+			}
+
+			if srec.Dispersive() && n > 1 {
+				// Only the hero wavelength can follow the scattered ray, so it is no longer weighted against the others.
+				scale := spectral.HeroWavelengthPDF(wavelengths[0]) / spectral.WavelengthPDF(wavelengths[0])
+				throughput = spectralValues{throughput[0] * scale}
+				aovScale = 1 / scale
+				n = 1
+				spectrum = medium.Wavelengths(wavelengths, n)
+			}
+			attenuation := spectralValues(srec.Attenuations())
+
 			if srec.IsSpecular() {
-				return srec.Attenuation() * s.SampleSpectral(srec.SpecularRay(), world, lightShape, depth+1, random)
+				if bounces == 0 {
+					direct, indirect = aov.SpecularDirect, aov.SpecularIndirect
+				}
+
+				scattered := srec.SpecularRay()
+				bounce := scatterType(r, rec, mat, true, scattered)
+				if !path.bounce(s.depth, bounce) {
+					return radiance
+				}
+				if d, ok := mat.(*material.Dielectric); ok && bounce == transmissionBounce {
+					media.cross(r, rec, volume{dielectric: d})
+				}
+
+				throughput = throughput.mul(attenuation)
+				r = scattered
+				bsdfPDF = 0
 			} else {
-				pLight := pdf.NewHitable(lightShape, rec.P())
-				p := pdf.NewMixture(pLight, srec.PDF())
-				scattered := ray.NewWithLambda(rec.P(), p.Generate(random), r.Time(), r.Lambda())
-				pdfVal := p.Value(scattered.Direction())
-				// emitted + (albedo * scatteringPDF())*spectral() / pdf
-				v1 := s.SampleSpectral(scattered, world, lightShape, depth+1, random) * mat.ScatteringPDF(r, rec, scattered)
-				v2 := srec.Attenuation() * v1
-				v3 := v2 / pdfVal
-				res := emitted + v3
-				return res
+				// Radiance reaching this surface directly from the lights arrives after one more bounce.
+				if light, id, ok := s.sampleDirect(r, rec, mat, srec, attenuation, media, spectrum, at, world, lightShape, random); ok {
+					add(bounces+1, throughput.mul(light), id)
+				}
+
+				scattered, weight, pdfVal, ok := s.scatter(r, rec, mat, srec, attenuation, at, random)
+				if !ok || !path.bounce(s.depth, scatterType(r, rec, mat, false, scattered)) {
+					return radiance
+				}
+
+				throughput = throughput.mul(weight)
+				p, bsdfPDF = rec.P(), pdfVal
+				r = scattered
 			}
-		} else {
-			return emitted
 		}
+
+		if n > 1 {
+			r.SetWavelengths(wavelengths)
+		}
+
+		q := s.depth.roulette(bounces+1, max(throughput[0], throughput[1], throughput[2], throughput[3]), random)
+		if q == 0 {
+			return radiance
+		}
+		throughput = throughput.scale(q)
+	}
+}
+
+// sampleDirect estimates the radiance arriving at the intersection described by rec directly from a point sampled
+// on the lights, weighted against BSDF sampling with the power heuristic. It returns the radiance scattered towards
+// the origin of r at every wavelength, given the attenuation of the surface at each of them and a function that
+// evaluates the emission at each of them, and the material identifier of the emitter.
+func (s *Spectral) sampleDirect(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, srec *scatterrecord.SpectralScatterRecord, attenuation spectralValues, media mediumStack, spectrum medium.Spectrum, at func(func(float64) float64) spectralValues, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) (spectralValues, uint32, bool) {
+	ls, ok := sampleLight(r, rec.P(), media, spectrum, world, lightShape, random, s.numRays)
+	if !ok {
+		return spectralValues{}, 0, false
+	}
+
+	emitted := at(func(lambda float64) float64 {
+		return ls.mat.EmittedSpectral(ls.ray, ls.rec, ls.rec.U(), ls.rec.V(), lambda, ls.rec.P())
+	})
+	f := evaluateSpectral(r, rec, mat, attenuation, ls.ray, at)
+	if emitted.isZero() || f.isZero() {
+		return spectralValues{}, 0, false
 	}
 
-	return s.background.Value(r.Lambda())
+	// bsdf * emitted * transmittance * weight / pdf
+	w := powerHeuristic(ls.pdf, srec.PDF().Value(ls.ray.Direction()))
+	light := f.mul(emitted).mul(spectralValues(ls.transmittance))
+
+	return light.scale(w / ls.pdf), ls.rec.MaterialID(), true
+}
+
+// sampleMediumDirect estimates the radiance arriving at the point x within a medium directly from a point sampled
+// on the lights, weighted against sampling the phase function with the power heuristic. It returns the radiance
+// scattered towards the origin of r at every wavelength of spectrum and the material identifier of the emitter.
+func (s *Spectral) sampleMediumDirect(r ray.Ray, x vec3.Vec3Impl, phase medium.HenyeyGreenstein, media mediumStack, spectrum medium.Spectrum, at func(func(float64) float64) spectralValues, world *hitable.HitableSlice, lightShape hitable.Hitable, random *fastrandom.LCG) (spectralValues, uint32, bool) {
+	ls, ok := sampleLight(r, x, media, spectrum, world, lightShape, random, s.numRays)
+	if !ok {
+		return spectralValues{}, 0, false
+	}
+
+	emitted := at(func(lambda float64) float64 {
+		return ls.mat.EmittedSpectral(ls.ray, ls.rec, ls.rec.U(), ls.rec.V(), lambda, ls.rec.P())
+	})
+	if emitted.isZero() {
+		return spectralValues{}, 0, false
+	}
+
+	// phase * emitted * transmittance * weight / pdf
+	phaseVal := phase.Evaluate(r.Direction(), ls.ray.Direction())
+	w := powerHeuristic(ls.pdf, phaseVal)
+
+	return emitted.mul(spectralValues(ls.transmittance)).scale(phaseVal * w / ls.pdf), ls.rec.MaterialID(), true
+}
+
+// scatter samples the direction in which r is scattered by a non-specular surface from the BSDF.
+// It returns the scattered ray, the weight of the radiance arriving along it at every wavelength, given the
+// attenuation of the surface at each of them, and the density of its direction.
+func (s *Spectral) scatter(r ray.Ray, rec *hitrecord.HitRecord, mat material.Material, srec *scatterrecord.SpectralScatterRecord, attenuation spectralValues, at func(func(float64) float64) spectralValues, random *fastrandom.LCG) (ray.Ray, spectralValues, float64, bool) {
+	p := srec.PDF()
+	scattered := ray.NewWithLambda(rec.P(), p.Generate(random), r.Time(), r.Lambda())
+	pdfVal := p.Value(scattered.Direction())
+	if pdfVal <= 0 {
+		return nil, spectralValues{}, 0, false
+	}
+
+	// bsdf / pdf
+	weight := evaluateSpectral(r, rec, mat, attenuation, scattered, at).scale(1 / pdfVal)
+
+	return scattered, weight, pdfVal, true
 }
 
 // Sample implements the Sampler interface for RGB rendering
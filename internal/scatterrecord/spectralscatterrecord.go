@@ -4,6 +4,7 @@ package scatterrecord
 import (
 	"github.com/flynn-nrg/izpi/internal/pdf"
 	"github.com/flynn-nrg/izpi/internal/ray"
+	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
@@ -17,12 +18,15 @@ type SpectralScatterRecord struct {
 	roughness   float64 // Spectral roughness at wavelength lambda
 	metalness   float64 // Spectral metalness at wavelength lambda
 	pdf         pdf.PDF
+	// attenuations holds the attenuation at every wavelength traced by hero wavelength sampling.
+	attenuations [spectral.NumHeroWavelengths]float64
+	dispersive   bool
 }
 
 // New returns an instance of a spectral scatter record.
 func NewSpectralScatterRecord(specularRay ray.Ray, isSpecular bool,
 	albedo float64, lambda float64, normal *vec3.Vec3Impl, roughness float64, metalness float64, pdf pdf.PDF) *SpectralScatterRecord {
-	return &SpectralScatterRecord{
+	ssr := &SpectralScatterRecord{
 		specularRay: specularRay,
 		isSpecular:  isSpecular,
 		albedo:      albedo,
@@ -32,6 +36,11 @@ func NewSpectralScatterRecord(specularRay ray.Ray, isSpecular bool,
 		metalness:   metalness,
 		pdf:         pdf,
 	}
+	for i := range ssr.attenuations {
+		ssr.attenuations[i] = albedo
+	}
+
+	return ssr
 }
 
 // SpecularRay() returns the specular ray from this scatter record.
@@ -73,3 +82,26 @@ func (ssr *SpectralScatterRecord) Metalness() float64 {
 func (ssr *SpectralScatterRecord) PDF() pdf.PDF {
 	return ssr.pdf
 }
+
+// Attenuations returns the attenuation at every wavelength traced along the incoming ray by hero wavelength
+// sampling, with the hero wavelength first. Unless set with SetAttenuations, all of them are the attenuation
+// at the hero wavelength.
+func (ssr *SpectralScatterRecord) Attenuations() [spectral.NumHeroWavelengths]float64 {
+	return ssr.attenuations
+}
+
+// SetAttenuations sets the attenuation at every wavelength traced along the incoming ray.
+func (ssr *SpectralScatterRecord) SetAttenuations(attenuations [spectral.NumHeroWavelengths]float64) {
+	ssr.attenuations = attenuations
+}
+
+// Dispersive returns whether the direction of the scattered ray depends on the wavelength, in which case only
+// the hero wavelength can be traced any further.
+func (ssr *SpectralScatterRecord) Dispersive() bool {
+	return ssr.dispersive
+}
+
+// SetDispersive marks the scattering as dependent on the wavelength.
+func (ssr *SpectralScatterRecord) SetDispersive() {
+	ssr.dispersive = true
+}
@@ -187,8 +187,9 @@ func Final(aspect float64) (*hitable.HitableSlice, *camera.Camera) {
 	list = append(list, hitable.NewSphere(vec3.Vec3Impl{X: 260, Y: 150, Z: 45}, vec3.Vec3Impl{X: 260, Y: 150, Z: 45}, 0, 1, 50, material.NewDielectric(1.5)))
 	list = append(list, hitable.NewSphere(vec3.Vec3Impl{X: 0, Y: 150, Z: 145}, vec3.Vec3Impl{X: 0, Y: 150, Z: 145}, 0, 1, 50, material.NewMetal(vec3.Vec3Impl{X: 0.8, Y: 0.8, Z: 0.9}, 10.0)))
 
-	boundary := hitable.NewSphere(vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, 0, 1, 70, material.NewDielectric(1.5))
-	list = append(list, boundary)
+	list = append(list, hitable.NewSphere(vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, 0, 1, 70, material.NewDielectric(1.5)))
+	// The medium fills the glass sphere without sharing its surface.
+	boundary := hitable.NewSphere(vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, vec3.Vec3Impl{X: 360, Y: 150, Z: 145}, 0, 1, 69.9, material.NewDielectric(1.5))
 	list = append(list, hitable.NewConstantMedium(boundary, 0.2, texture.NewConstant(vec3.Vec3Impl{X: 0.2, Y: 0.4, Z: 0.9})))
 	boundary = hitable.NewSphere(vec3.Vec3Impl{}, vec3.Vec3Impl{}, 0, 1, 5000, material.NewDielectric(1.5))
 	list = append(list, hitable.NewConstantMedium(boundary, 0.0001, texture.NewConstant(vec3.Vec3Impl{X: 1.0, Y: 1.0, Z: 1.0})))
@@ -3606,11 +3607,12 @@ func CornellBoxWaterSpectral(aspect float64) *pb_transport.Scene {
 				},
 			},
 			Spheres: []*pb_transport.Sphere{
-				// Lambertian sphere floating above water
+				// Glass sphere floating half submerged in the water. The glass has a higher priority than the
+				// water, so the part of the surface of the water inside the sphere is ignored.
 				{
 					Center:       &pb_transport.Vec3{X: 50, Y: 40, Z: 50},
 					Radius:       15,
-					MaterialName: "BlueLambert",
+					MaterialName: "BlueGlass",
 				},
 			},
 		},
@@ -3713,6 +3715,7 @@ func CornellBoxWaterSpectral(aspect float64) *pb_transport.Scene {
 								},
 							},
 						},
+						Priority: 1,
 					},
 				},
 			},
@@ -3744,6 +3747,7 @@ func CornellBoxWaterSpectral(aspect float64) *pb_transport.Scene {
 								},
 							},
 						},
+						Priority: 2,
 					},
 				},
 			},
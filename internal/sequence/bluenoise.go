@@ -0,0 +1,113 @@
+package sequence
+
+import (
+	"math/bits"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+// base4Permutations holds every permutation of four elements.
+var base4Permutations = [24][4]uint8{
+	{0, 1, 2, 3}, {0, 1, 3, 2}, {0, 2, 1, 3}, {0, 2, 3, 1}, {0, 3, 2, 1}, {0, 3, 1, 2},
+	{1, 0, 2, 3}, {1, 0, 3, 2}, {1, 2, 0, 3}, {1, 2, 3, 0}, {1, 3, 2, 0}, {1, 3, 0, 2},
+	{2, 1, 0, 3}, {2, 1, 3, 0}, {2, 0, 1, 3}, {2, 0, 3, 1}, {2, 3, 0, 1}, {2, 3, 1, 0},
+	{3, 1, 2, 0}, {3, 1, 0, 2}, {3, 2, 1, 0}, {3, 2, 0, 1}, {3, 0, 2, 1}, {3, 0, 1, 2},
+}
+
+// blueNoise draws samples from a single Owen scrambled Sobol sequence shared by the whole image, which is split
+// among the pixels following a Morton curve whose base 4 digits are randomly permuted, as described by Ahmed and
+// Wonka in "Screen-Space Blue-Noise Diffusion of Monte Carlo Sampling Error via Hierarchical Ordering of Pixels".
+// Neighbouring pixels get samples that complement each other, so the remaining error is spread as blue noise,
+// which is less visible than white noise and easier to filter out.
+type blueNoise struct {
+	pixelSample
+	// log2Samples is the base 2 logarithm of the number of samples per pixel, rounded up to a power of two.
+	log2Samples int
+	// numDigits is the number of base 4 digits of the index of a sample in the sequence of the image.
+	numDigits int
+	// mortonIndex is the index of the current sample along the Morton curve.
+	mortonIndex uint64
+	// sampleSeed scrambles the current sample.
+	sampleSeed uint64
+	second     float64
+}
+
+func newBlueNoise(cfg Config) *blueNoise {
+	log2Samples := bits.Len(uint(max(cfg.SamplesPerPixel, 1) - 1))
+	log2Resolution := bits.Len(uint(max(cfg.Width, cfg.Height, 1) - 1))
+
+	return &blueNoise{
+		pixelSample: pixelSample{seed: cfg.Seed},
+		log2Samples: log2Samples,
+		numDigits:   log2Resolution + (log2Samples+1)/2,
+	}
+}
+
+func (g *blueNoise) StartPixelSample(x, y, index int) {
+	g.pixelSample.StartPixelSample(x, y, index)
+
+	// Samples beyond the configured number move to an unrelated sequence.
+	mask := 1<<g.log2Samples - 1
+	g.mortonIndex = encodeMorton2(uint32(x), uint32(y))<<g.log2Samples | uint64(index&mask)
+	g.sampleSeed = g.seed
+	if round := index >> g.log2Samples; round > 0 {
+		g.sampleSeed = fastrandom.Hash(g.seed, uint64(round))
+	}
+}
+
+func (g *blueNoise) Float64() float64 {
+	d := g.next()
+	if d%2 == 1 {
+		return g.second
+	}
+
+	x, y := sobolPair(g.sampleIndex(uint64(d)), fastrandom.Hash(g.sampleSeed, uint64(d)))
+	g.second = y
+
+	return x
+}
+
+// sampleIndex returns the index in the Sobol sequence of the current sample for the given dimension, randomly
+// permuting every base 4 digit of its Morton index depending on the value of the more significant ones.
+func (g *blueNoise) sampleIndex(dimension uint64) uint64 {
+	// An odd power of two of samples leaves a single base 2 digit at the end.
+	odd := g.log2Samples&1 == 1
+	lastDigit := 0
+	shift := 0
+	if odd {
+		lastDigit = 1
+		shift = 1
+	}
+
+	var index uint64
+	for i := g.numDigits - 1; i >= lastDigit; i-- {
+		digitShift := 2*i - shift
+		digit := (g.mortonIndex >> digitShift) & 3
+		higherDigits := g.mortonIndex >> (digitShift + 2)
+		p := fastrandom.Hash(higherDigits, dimension, g.sampleSeed) % 24
+		index |= uint64(base4Permutations[p][digit]) << digitShift
+	}
+
+	if odd {
+		digit := g.mortonIndex & 1
+		index |= digit ^ (fastrandom.Hash(g.mortonIndex>>1, dimension, g.sampleSeed) & 1)
+	}
+
+	return index
+}
+
+// encodeMorton2 interleaves the bits of x and y.
+func encodeMorton2(x, y uint32) uint64 {
+	return spreadBits(y)<<1 | spreadBits(x)
+}
+
+// spreadBits inserts a zero bit after every bit of v.
+func spreadBits(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000ffff0000ffff
+	x = (x | x<<8) & 0x00ff00ff00ff00ff
+	x = (x | x<<4) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
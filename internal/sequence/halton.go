@@ -0,0 +1,74 @@
+package sequence
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+// numHaltonDimensions is the number of dimensions of the Halton sequence used, each of them with a different prime
+// base. The following dimensions are drawn independently, as the quality of the sequence degrades with large bases.
+const numHaltonDimensions = 256
+
+// primes holds the first numHaltonDimensions prime numbers.
+var primes = func() []uint64 {
+	p := make([]uint64, 0, numHaltonDimensions)
+	for n := uint64(2); len(p) < numHaltonDimensions; n++ {
+		isPrime := true
+		for _, q := range p {
+			if q*q > n {
+				break
+			}
+			if n%q == 0 {
+				isPrime = false
+				break
+			}
+		}
+		if isPrime {
+			p = append(p, n)
+		}
+	}
+
+	return p
+}()
+
+// halton draws samples from the Halton sequence, with the digits of every dimension Owen scrambled differently for
+// every pixel.
+type halton struct {
+	pixelSample
+}
+
+func newHalton(cfg Config) *halton {
+	return &halton{pixelSample: pixelSample{seed: cfg.Seed}}
+}
+
+func (g *halton) Float64() float64 {
+	d := g.next()
+	if d >= numHaltonDimensions {
+		return fastrandom.HashFloat64(g.hash, uint64(g.index), uint64(d))
+	}
+
+	return owenScrambledRadicalInverse(uint64(g.index), primes[d], fastrandom.Hash(g.hash, uint64(d)))
+}
+
+// owenScrambledRadicalInverse mirrors the digits of a in the given base around the radix point, randomly permuting
+// every digit depending on the value of the more significant ones.
+func owenScrambledRadicalInverse(a uint64, base uint64, seed uint64) float64 {
+	invBase := 1.0 / float64(base)
+	invBaseM := 1.0
+	var reversed uint64
+	var depth uint64
+	// Digits are added until they are below the precision of the Sobol generators, which also keeps reversed from
+	// overflowing. Every digit is scrambled, including the zeros beyond the most significant digit of a.
+	for invBaseM >= 0x1p-32 {
+		digit := a % base
+		a /= base
+		digit = (digit + fastrandom.Hash(seed, depth, reversed)) % base
+		reversed = reversed*base + digit
+		invBaseM *= invBase
+		depth++
+	}
+
+	return min(float64(reversed)*invBaseM, oneMinusEpsilon)
+}
+
+// oneMinusEpsilon is the largest number below 1.
+const oneMinusEpsilon = 0x1.fffffffffffffp-1
@@ -0,0 +1,18 @@
+package sequence
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+// independent draws every dimension of every sample independently of the others.
+type independent struct {
+	pixelSample
+}
+
+func newIndependent(cfg Config) *independent {
+	return &independent{pixelSample: pixelSample{seed: cfg.Seed}}
+}
+
+func (g *independent) Float64() float64 {
+	return fastrandom.HashFloat64(g.hash, uint64(g.index), uint64(g.next()))
+}
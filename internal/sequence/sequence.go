@@ -0,0 +1,156 @@
+// Package sequence implements generators of the numbers used to sample pixels, from independent random numbers to
+// low-discrepancy sequences.
+//
+// Every sample of a pixel is a point in a space with as many dimensions as numbers are drawn to trace it: the
+// position within the pixel, the point on the lens, the time, the wavelength and then the numbers used at every
+// bounce of the path. Generators return the coordinates of that point one at a time, in the order they are drawn.
+package sequence
+
+import (
+	"math/bits"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+type GeneratorType int
+
+const (
+	InvalidGenerator GeneratorType = iota
+	IndependentGenerator
+	StratifiedGenerator
+	SobolGenerator
+	HaltonGenerator
+	BlueNoiseGenerator
+)
+
+var generatorMap = map[string]GeneratorType{
+	"independent": IndependentGenerator,
+	"stratified":  StratifiedGenerator,
+	"sobol":       SobolGenerator,
+	"halton":      HaltonGenerator,
+	"blue-noise":  BlueNoiseGenerator,
+}
+
+// StringToType returns the generator type with the given name, or InvalidGenerator if there is none.
+func StringToType(s string) GeneratorType {
+	return generatorMap[s]
+}
+
+// Generator draws the numbers of the samples of pixels. Generators are not safe for concurrent use.
+type Generator interface {
+	// StartPixelSample restarts the sequence at the first dimension of the given sample of the pixel (x, y).
+	StartPixelSample(x, y, index int)
+	// Float64 returns the next dimension of the current sample, which is in [0, 1).
+	Float64() float64
+}
+
+// Config holds the settings shared by all generators.
+type Config struct {
+	// Width and Height are the size of the image in pixels.
+	Width  int
+	Height int
+	// SamplesPerPixel is the number of samples that will be taken for every pixel. Stratified generators divide
+	// the sample space into that many strata.
+	SamplesPerPixel int
+	// Seed scrambles the sequences, so that different seeds give different but equally distributed samples.
+	Seed uint64
+}
+
+// New returns a generator of the given type, or nil if the type is not valid.
+func New(t GeneratorType, cfg Config) Generator {
+	switch t {
+	case IndependentGenerator:
+		return newIndependent(cfg)
+	case StratifiedGenerator:
+		return newStratified(cfg)
+	case SobolGenerator:
+		return newSobol(cfg)
+	case HaltonGenerator:
+		return newHalton(cfg)
+	case BlueNoiseGenerator:
+		return newBlueNoise(cfg)
+	default:
+		return nil
+	}
+}
+
+// NewLCG returns an LCG that takes its numbers from a new generator of the given type, so that it can be passed to
+// the code that draws the numbers of a sample. StartPixelSample must be called on it before every sample.
+func NewLCG(t GeneratorType, cfg Config) *fastrandom.LCG {
+	return fastrandom.NewWithSource(New(t, cfg))
+}
+
+// pixelSample holds the state shared by all generators: the sample being drawn and the next dimension of it.
+type pixelSample struct {
+	seed      uint64
+	x         int
+	y         int
+	index     int
+	dimension int
+	// hash identifies the current pixel and is used to decorrelate the sequences of neighbouring pixels.
+	hash uint64
+}
+
+func (p *pixelSample) StartPixelSample(x, y, index int) {
+	p.x = x
+	p.y = y
+	p.index = index
+	p.dimension = 0
+	p.hash = fastrandom.Hash(p.seed, uint64(x), uint64(y))
+}
+
+// next returns the dimension to draw and advances to the following one.
+func (p *pixelSample) next() int {
+	d := p.dimension
+	p.dimension++
+	return d
+}
+
+// owenScramble applies a nested uniform scramble to the binary fraction v, which randomly flips every digit
+// depending on the value of the more significant ones. It is the hash based approximation described by Burley in
+// "Practical Hash-based Owen Scrambling".
+func owenScramble(v uint32, seed uint32) uint32 {
+	v = bits.Reverse32(v)
+	v ^= v * 0x3d20adea
+	v += seed
+	v *= (seed >> 16) | 1
+	v ^= v * 0x05526c56
+	v ^= v * 0x53a22864
+	return bits.Reverse32(v)
+}
+
+// permutationElement returns the element at position i of a random permutation of [0, n) chosen by seed, as
+// described by Kensler in "Correlated Multi-Jittered Sampling".
+func permutationElement(i, n, seed uint32) uint32 {
+	w := n - 1
+	w |= w >> 1
+	w |= w >> 2
+	w |= w >> 4
+	w |= w >> 8
+	w |= w >> 16
+	for {
+		i ^= seed
+		i *= 0xe170893d
+		i ^= seed >> 16
+		i ^= (i & w) >> 4
+		i ^= seed >> 8
+		i *= 0x0929eb3f
+		i ^= seed >> 23
+		i ^= (i & w) >> 1
+		i *= 1 | seed>>27
+		i *= 0x6935fa69
+		i ^= (i & w) >> 11
+		i *= 0x74dcb303
+		i ^= (i & w) >> 2
+		i *= 0x9e501cc3
+		i ^= (i & w) >> 2
+		i *= 0xc860a3df
+		i &= w
+		i ^= i >> 5
+		if i < n {
+			break
+		}
+	}
+
+	return (i + seed) % n
+}
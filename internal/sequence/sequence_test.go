@@ -0,0 +1,104 @@
+package sequence
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerators(t *testing.T) {
+	const numSamples = 16
+
+	testData := []struct {
+		name      string
+		generator GeneratorType
+		// strata is the number of strata along each of the first two dimensions that every sample of a pixel falls
+		// in a different combination of, or 0 if samples are not stratified.
+		strata int
+	}{
+		{name: "independent", generator: IndependentGenerator},
+		{name: "stratified", generator: StratifiedGenerator, strata: 4},
+		{name: "sobol", generator: SobolGenerator, strata: 4},
+		{name: "halton", generator: HaltonGenerator, strata: 1},
+		{name: "blue noise", generator: BlueNoiseGenerator, strata: 4},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Config{Width: 64, Height: 32, SamplesPerPixel: numSamples, Seed: 42}
+			g := New(test.generator, cfg)
+
+			draw := func(x, y, index int) []float64 {
+				g.StartPixelSample(x, y, index)
+				values := make([]float64, 300)
+				for i := range values {
+					values[i] = g.Float64()
+				}
+				return values
+			}
+
+			first := draw(3, 5, 7)
+			for i, v := range first {
+				if v < 0 || v >= 1 {
+					t.Fatalf("dimension %v = %v, which is outside [0, 1)", i, v)
+				}
+			}
+
+			// Samples only depend on the pixel, the index and the seed.
+			draw(10, 20, 1)
+			if diff := cmp.Diff(first, draw(3, 5, 7)); diff != "" {
+				t.Errorf("sample mismatch after drawing another one (-want +got):\n%s", diff)
+			}
+
+			// Neighbouring pixels do not share their samples.
+			if other := draw(4, 5, 7); other[0] == first[0] && other[1] == first[1] {
+				t.Errorf("pixels (3, 5) and (4, 5) share the sample (%v, %v)", first[0], first[1])
+			}
+
+			if test.strata == 0 {
+				return
+			}
+
+			// The samples of a pixel are spread over the strata of the first dimension and, when there are enough
+			// of them, over the strata of the first two dimensions.
+			seen := make(map[[2]int]bool)
+			seenX := make(map[int]bool)
+			for i := range numSamples {
+				values := draw(3, 5, i)
+				seenX[int(values[0]*numSamples)] = true
+				seen[[2]int{int(values[0] * float64(test.strata)), int(values[1] * float64(test.strata))}] = true
+			}
+			if test.strata > 1 && len(seen) != test.strata*test.strata {
+				t.Errorf("samples cover %v of the %v 2D strata", len(seen), test.strata*test.strata)
+			}
+			if test.generator != StratifiedGenerator && len(seenX) != numSamples {
+				t.Errorf("samples cover %v of the %v strata of the first dimension", len(seenX), numSamples)
+			}
+		})
+	}
+}
+
+func TestPermutationElement(t *testing.T) {
+	testData := []struct {
+		name string
+		n    uint32
+		seed uint32
+	}{
+		{name: "power of two", n: 16, seed: 1},
+		{name: "not a power of two", n: 23, seed: 7},
+		{name: "single element", n: 1, seed: 3},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			seen := make([]bool, test.n)
+			for i := range test.n {
+				p := permutationElement(i, test.n, test.seed)
+				if p >= test.n || seen[p] {
+					t.Fatalf("permutationElement(%v) = %v, which is out of range or repeated", i, p)
+				}
+				seen[p] = true
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package sequence
+
+import (
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+// sobolMatrices holds the generator matrices of the first two dimensions of the Sobol sequence, one column per bit
+// of the index. The first dimension is the van der Corput sequence and the second one is generated by the Pascal
+// matrix, whose columns follow from each other.
+var sobolMatrices = func() [2][64]uint32 {
+	var m [2][64]uint32
+	m[1][0] = 1 << 31
+	for i := range m[0] {
+		if i < 32 {
+			m[0][i] = 1 << (31 - i)
+		}
+		if i > 0 {
+			m[1][i] = m[1][i-1] ^ (m[1][i-1] >> 1)
+		}
+	}
+
+	return m
+}()
+
+// sobolSample returns the given dimension, which must be 0 or 1, of the point with the given index of the Sobol
+// sequence as a binary fraction.
+func sobolSample(index uint64, dimension int) uint32 {
+	var v uint32
+	for i := 0; index != 0; index, i = index>>1, i+1 {
+		if index&1 != 0 {
+			v ^= sobolMatrices[dimension][i]
+		}
+	}
+
+	return v
+}
+
+// sobol draws samples from Owen scrambled Sobol points. Every pair of dimensions takes the first two dimensions of
+// the sequence with the order of the points shuffled and the points scrambled differently for every pixel and pair,
+// as described by Burley in "Practical Hash-based Owen Scrambling". The first two dimensions of the Sobol sequence
+// are well stratified in 2D for any number of samples that is a power of two.
+type sobol struct {
+	pixelSample
+	second float64
+}
+
+func newSobol(cfg Config) *sobol {
+	return &sobol{pixelSample: pixelSample{seed: cfg.Seed}}
+}
+
+func (g *sobol) Float64() float64 {
+	d := g.next()
+	if d%2 == 1 {
+		return g.second
+	}
+
+	seed := fastrandom.Hash(g.hash, uint64(d))
+	// Shuffling the points with a nested uniform scramble keeps every prefix of the sequence well distributed.
+	index := owenScramble(uint32(g.index), uint32(seed))
+
+	x, y := sobolPair(uint64(index), seed)
+	g.second = y
+
+	return x
+}
+
+// sobolPair returns the first two dimensions of the point with the given index of the Sobol sequence, each of them
+// Owen scrambled with a seed derived from seed.
+func sobolPair(index uint64, seed uint64) (float64, float64) {
+	x := owenScramble(sobolSample(index, 0), uint32(seed>>32))
+	y := owenScramble(sobolSample(index, 1), uint32(fastrandom.Hash(seed)))
+
+	return float64(x) * 0x1p-32, float64(y) * 0x1p-32
+}
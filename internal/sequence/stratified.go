@@ -0,0 +1,49 @@
+package sequence
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+)
+
+// stratified divides every pair of dimensions into a grid of strata and places each sample of a pixel in a
+// different one, chosen by a random permutation that changes with the pixel and the pair. When there are more
+// samples than strata every further round of samples is permuted anew.
+type stratified struct {
+	pixelSample
+	// nx and ny are the size of the grid of strata.
+	nx int
+	ny int
+	// second is the value of the odd dimension of the current pair.
+	second float64
+}
+
+func newStratified(cfg Config) *stratified {
+	n := max(cfg.SamplesPerPixel, 1)
+	nx := int(math.Ceil(math.Sqrt(float64(n))))
+	ny := (n + nx - 1) / nx
+
+	return &stratified{
+		pixelSample: pixelSample{seed: cfg.Seed},
+		nx:          nx,
+		ny:          ny,
+	}
+}
+
+func (g *stratified) Float64() float64 {
+	d := g.next()
+	if d%2 == 1 {
+		return g.second
+	}
+
+	n := g.nx * g.ny
+	round := uint64(g.index / n)
+	pair := uint64(d / 2)
+	stratum := int(permutationElement(uint32(g.index%n), uint32(n), uint32(fastrandom.Hash(g.hash, pair, round))))
+
+	// Samples are jittered within their stratum.
+	x := (float64(stratum%g.nx) + fastrandom.HashFloat64(g.hash, uint64(g.index), uint64(d))) / float64(g.nx)
+	g.second = (float64(stratum/g.nx) + fastrandom.HashFloat64(g.hash, uint64(g.index), uint64(d+1))) / float64(g.ny)
+
+	return x
+}
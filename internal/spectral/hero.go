@@ -0,0 +1,52 @@
+package spectral
+
+import (
+	"math"
+)
+
+// NumHeroWavelengths is the number of wavelengths traced together by hero wavelength sampling.
+const NumHeroWavelengths = 4
+
+// Wavelengths holds the wavelengths traced together by hero wavelength sampling, as described by Wilkie et al. in
+// "Hero Wavelength Spectral Sampling". The first one is the hero wavelength, which is the one followed on its own
+// when light is scattered in a way that depends on the wavelength, such as dispersion.
+type Wavelengths [NumHeroWavelengths]float64
+
+// SampleHeroWavelengths chooses a hero wavelength in the same way as SampleWavelength and spaces the others evenly
+// across the visible spectrum from it, wrapping around at the end.
+func SampleHeroWavelengths(random float64) Wavelengths {
+	var w Wavelengths
+	w[0], _ = SampleWavelength(random)
+	for i := 1; i < NumHeroWavelengths; i++ {
+		w[i] = wrapWavelength(w[0] + float64(i)*heroSpacing)
+	}
+
+	return w
+}
+
+// HeroWavelengthPDF returns the density with which SampleHeroWavelengths chooses a set of wavelengths that includes
+// lambda, in the same units as the density returned by SampleWavelength. Dividing the contribution of every
+// wavelength of a set by it weights the wavelengths against each other with the balance heuristic.
+func HeroWavelengthPDF(lambda float64) float64 {
+	var pdf float64
+	for i := range NumHeroWavelengths {
+		pdf += WavelengthPDF(wrapWavelength(lambda - float64(i)*heroSpacing))
+	}
+
+	return pdf
+}
+
+// WavelengthPDF returns the density with which SampleWavelength chooses lambda.
+func WavelengthPDF(lambda float64) float64 {
+	_, y, _ := GetCIEValues(lambda)
+	return y / cieYIntegral
+}
+
+// heroSpacing is the distance between the wavelengths chosen by SampleHeroWavelengths.
+const heroSpacing = float64(WavelengthMax-WavelengthMin) / NumHeroWavelengths
+
+// wrapWavelength wraps lambda around the visible spectrum.
+func wrapWavelength(lambda float64) float64 {
+	const span = WavelengthMax - WavelengthMin
+	return WavelengthMin + math.Mod(math.Mod(lambda-WavelengthMin, span)+span, span)
+}
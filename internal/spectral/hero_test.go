@@ -0,0 +1,48 @@
+package spectral
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSampleHeroWavelengths(t *testing.T) {
+	testData := []struct {
+		name   string
+		random float64
+	}{
+		{name: "start of the spectrum", random: 0},
+		{name: "peak of the CIE Y curve", random: 0.5},
+		{name: "end of the spectrum", random: 0.999},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			w := SampleHeroWavelengths(test.random)
+
+			hero, _ := SampleWavelength(test.random)
+			if w[0] != hero {
+				t.Errorf("hero wavelength = %v, want %v", w[0], hero)
+			}
+
+			for i, lambda := range w {
+				if lambda < WavelengthMin || lambda >= WavelengthMax {
+					t.Errorf("wavelength %v = %v, which is outside the visible spectrum", i, lambda)
+				}
+
+				// The offset from the hero wavelength, wrapped around the spectrum.
+				offset := math.Mod(lambda-w[0]+(WavelengthMax-WavelengthMin), WavelengthMax-WavelengthMin)
+				if want := float64(i) * heroSpacing; math.Abs(offset-want) > 1e-9 {
+					t.Errorf("wavelength %v is %vnm away from the hero wavelength, want %vnm", i, offset, want)
+				}
+			}
+
+			// Every wavelength of the set can be chosen along with the others, so all have the same density.
+			want := HeroWavelengthPDF(w[0])
+			for _, lambda := range w[1:] {
+				if got := HeroWavelengthPDF(lambda); math.Abs(got-want) > 1e-9 {
+					t.Errorf("HeroWavelengthPDF(%v) = %v, want %v", lambda, got, want)
+				}
+			}
+		})
+	}
+}
@@ -63,6 +63,12 @@ var cieWavelengths = []float64{
 // equal-energy illuminant will result in Y=1.
 const cieYIntegral = 21.3768 // Sum of all values in the cieY array
 
+// CIEYIntegral returns the integral of the CIE Y curve. The XYZ estimate of a perfect reflector
+// has a Y value equal to this integral.
+func CIEYIntegral() float64 {
+	return cieYIntegral
+}
+
 // SpectralPowerDistribution represents spectral data
 type SpectralPowerDistribution struct {
 	wavelengths []float64
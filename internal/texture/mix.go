@@ -0,0 +1,32 @@
+package texture
+
+import "github.com/flynn-nrg/izpi/internal/vec3"
+
+// Ensure interface compliance.
+var _ Texture = (*Mix)(nil)
+
+// Mix represents a linear interpolation between two textures.
+type Mix struct {
+	fg  Texture
+	bg  Texture
+	mix Texture
+}
+
+// NewMix returns a new instance of the Mix texture. The first channel of mix is the weight of fg at each point.
+func NewMix(fg Texture, bg Texture, mix Texture) *Mix {
+	return &Mix{
+		fg:  fg,
+		bg:  bg,
+		mix: mix,
+	}
+}
+
+func (m *Mix) Value(u float64, v float64, p vec3.Vec3Impl) vec3.Vec3Impl {
+	w := m.mix.Value(u, v, p).X
+	return vec3.Add(vec3.ScalarMul(m.fg.Value(u, v, p), w), vec3.ScalarMul(m.bg.Value(u, v, p), 1-w))
+}
+
+// Inputs returns the textures being interpolated and the weight of the first one.
+func (m *Mix) Inputs() (fg Texture, bg Texture, mix Texture) {
+	return m.fg, m.bg, m.mix
+}
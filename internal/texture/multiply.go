@@ -0,0 +1,29 @@
+package texture
+
+import "github.com/flynn-nrg/izpi/internal/vec3"
+
+// Ensure interface compliance.
+var _ Texture = (*Multiply)(nil)
+
+// Multiply represents the product of two textures.
+type Multiply struct {
+	a Texture
+	b Texture
+}
+
+// NewMultiply returns a new instance of the Multiply texture.
+func NewMultiply(a Texture, b Texture) *Multiply {
+	return &Multiply{
+		a: a,
+		b: b,
+	}
+}
+
+func (m *Multiply) Value(u float64, v float64, p vec3.Vec3Impl) vec3.Vec3Impl {
+	return vec3.Mul(m.a.Value(u, v, p), m.b.Value(u, v, p))
+}
+
+// Operands returns the textures being multiplied.
+func (m *Multiply) Operands() (Texture, Texture) {
+	return m.a, m.b
+}
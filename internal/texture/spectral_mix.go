@@ -0,0 +1,29 @@
+package texture
+
+import "github.com/flynn-nrg/izpi/internal/vec3"
+
+// Ensure interface compliance.
+var _ SpectralTexture = (*SpectralMix)(nil)
+
+// SpectralMix represents a linear interpolation between two spectral textures.
+// The weight does not depend on the wavelength and is read from the first channel of a regular texture.
+type SpectralMix struct {
+	fg  SpectralTexture
+	bg  SpectralTexture
+	mix Texture
+}
+
+// NewSpectralMix returns a new instance of the SpectralMix texture.
+func NewSpectralMix(fg SpectralTexture, bg SpectralTexture, mix Texture) *SpectralMix {
+	return &SpectralMix{
+		fg:  fg,
+		bg:  bg,
+		mix: mix,
+	}
+}
+
+// Value returns the interpolated spectral response at the given position and wavelength.
+func (m *SpectralMix) Value(u float64, v float64, lambda float64, p vec3.Vec3Impl) float64 {
+	w := m.mix.Value(u, v, p).X
+	return w*m.fg.Value(u, v, lambda, p) + (1-w)*m.bg.Value(u, v, lambda, p)
+}
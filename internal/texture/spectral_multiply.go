@@ -0,0 +1,25 @@
+package texture
+
+import "github.com/flynn-nrg/izpi/internal/vec3"
+
+// Ensure interface compliance.
+var _ SpectralTexture = (*SpectralMultiply)(nil)
+
+// SpectralMultiply represents the product of two spectral textures.
+type SpectralMultiply struct {
+	a SpectralTexture
+	b SpectralTexture
+}
+
+// NewSpectralMultiply returns a new instance of the SpectralMultiply texture.
+func NewSpectralMultiply(a SpectralTexture, b SpectralTexture) *SpectralMultiply {
+	return &SpectralMultiply{
+		a: a,
+		b: b,
+	}
+}
+
+// Value returns the product of the spectral responses of both textures at the given position and wavelength.
+func (m *SpectralMultiply) Value(u float64, v float64, lambda float64, p vec3.Vec3Impl) float64 {
+	return m.a.Value(u, v, lambda, p) * m.b.Value(u, v, lambda, p)
+}
@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/medium"
+	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
+	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vdb"
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// toSceneMedia converts the media of the scene into the materials of their boundaries, keyed by the name of the
+// medium, along with the boxes that enclose the volume grids of the media that ask for them.
+func (t *Transport) toSceneMedia() (map[string]*material.MediumBoundary, []hitable.Hitable, error) {
+	boundaries := make(map[string]*material.MediumBoundary)
+	var bounds []hitable.Hitable
+	// The media are visited in a fixed order so that the scene is built the same way every time.
+	for _, name := range slices.Sorted(maps.Keys(t.protoScene.GetMedia())) {
+		m := t.protoScene.GetMedia()[name]
+		med, err := t.toSceneMedium(m)
+		if err != nil {
+			return nil, nil, fmt.Errorf("medium %s: %w", name, err)
+		}
+		boundaries[name] = material.NewMediumBoundary(med)
+
+		if v := m.GetVdb(); v.GetEncloseBounds() {
+			grid, err := t.volumeGrid(v.GetFilename(), v.GetDensityGrid())
+			if err != nil {
+				return nil, nil, fmt.Errorf("medium %s: %w", name, err)
+			}
+			min, max := grid.Bounds()
+			bounds = append(bounds, hitable.NewBox(min, max, boundaries[name]))
+		}
+	}
+
+	return boundaries, bounds, nil
+}
+
+func (t *Transport) toSceneMedium(m *pb_transport.Medium) (*medium.Medium, error) {
+	sigmaA := toVec3(m.GetSigmaA())
+	sigmaS := toVec3(m.GetSigmaS())
+	g := float64(m.GetG())
+
+	var med *medium.Medium
+	switch m.GetDensityProperties().(type) {
+	case nil:
+		med = medium.NewHomogeneous(sigmaA, sigmaS, g)
+	case *pb_transport.Medium_Constant:
+		density := medium.NewConstantDensity(float64(m.GetConstant().GetDensity()))
+		med = medium.NewHeterogeneous(sigmaA, sigmaS, density, g)
+	case *pb_transport.Medium_Grid:
+		grid := m.GetGrid()
+		nx, ny, nz := int(grid.GetNx()), int(grid.GetNy()), int(grid.GetNz())
+		if len(grid.GetValues()) != nx*ny*nz {
+			return nil, fmt.Errorf("grid density has %d values, want %d", len(grid.GetValues()), nx*ny*nz)
+		}
+		values := make([]float64, len(grid.GetValues()))
+		for i, v := range grid.GetValues() {
+			values[i] = float64(v)
+		}
+		density := medium.NewGridDensity(toVec3(grid.GetMin()), toVec3(grid.GetMax()), nx, ny, nz, values)
+		med = medium.NewHeterogeneous(sigmaA, sigmaS, density, g)
+	case *pb_transport.Medium_Noise:
+		noise := m.GetNoise()
+		density := medium.NewNoiseDensity(float64(noise.GetDensity()), float64(noise.GetFrequency()), int(noise.GetOctaves()))
+		med = medium.NewHeterogeneous(sigmaA, sigmaS, density, g)
+	case *pb_transport.Medium_Vdb:
+		v := m.GetVdb()
+		grid, err := t.volumeGrid(v.GetFilename(), v.GetDensityGrid())
+		if err != nil {
+			return nil, err
+		}
+		density := medium.NewScaledDensity(grid, orOne(v.GetDensityScale()))
+		med = medium.NewHeterogeneous(sigmaA, sigmaS, density, g)
+
+		if v.GetTemperatureGrid() != "" {
+			temperature, err := t.volumeGrid(v.GetFilename(), v.GetTemperatureGrid())
+			if err != nil {
+				return nil, err
+			}
+			med.SetBlackbodyEmission(temperature, orOne(v.GetTemperatureScale()), float64(v.GetEmissionScale()))
+		}
+	default:
+		return nil, fmt.Errorf("unknown density type: %T", m.GetDensityProperties())
+	}
+
+	var spectralA, spectralS texture.SpectralTexture
+	if m.GetSpectralSigmaA() != nil {
+		var err error
+		if spectralA, err = t.toSceneSpectralTexture(m.GetSpectralSigmaA()); err != nil {
+			return nil, err
+		}
+	}
+	if m.GetSpectralSigmaS() != nil {
+		var err error
+		if spectralS, err = t.toSceneSpectralTexture(m.GetSpectralSigmaS()); err != nil {
+			return nil, err
+		}
+	}
+	med.SetSpectralCoefficients(spectralA, spectralS)
+
+	return med, nil
+}
+
+// volumeGrid returns the grid with the given name, or the first grid if the name is empty, from one of the volume
+// grid files of the scene.
+func (t *Transport) volumeGrid(filename string, name string) (*vdb.Grid, error) {
+	grids, ok := t.volumeGrids[filename]
+	if !ok {
+		return nil, fmt.Errorf("volume grid file %s not loaded", filename)
+	}
+
+	for _, g := range grids {
+		if name == "" || g.Name() == name {
+			return g, nil
+		}
+	}
+
+	return nil, fmt.Errorf("volume grid file %s has no grid named %q", filename, name)
+}
+
+// orOne returns v, or 1 if v is unset.
+func orOne(v float32) float64 {
+	if v == 0 {
+		return 1
+	}
+
+	return float64(v)
+}
+
+func toVec3(v *pb_transport.Vec3) vec3.Vec3Impl {
+	return vec3.Vec3Impl{
+		X: float64(v.GetX()),
+		Y: float64(v.GetY()),
+		Z: float64(v.GetZ()),
+	}
+}
@@ -4,17 +4,20 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/camera"
 	"github.com/flynn-nrg/izpi/internal/displacement"
 	"github.com/flynn-nrg/izpi/internal/hitable"
 	"github.com/flynn-nrg/izpi/internal/hitrecord"
 	"github.com/flynn-nrg/izpi/internal/lightsources"
 	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/materials"
 	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
 	"github.com/flynn-nrg/izpi/internal/ray"
 	"github.com/flynn-nrg/izpi/internal/scene"
 	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vdb"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 
 	log "github.com/sirupsen/logrus"
@@ -28,7 +31,11 @@ type Transport struct {
 	triangles            []*pb_transport.Triangle
 	textures             map[string]*texture.ImageTxt
 	materials            map[string]material.Material
+	media                map[string]*material.MediumBoundary
 	displacementMaps     map[string]*texture.ImageTxt
+	volumeGrids          map[string][]*vdb.Grid
+	// volumeBounds are the boxes that enclose volume grids, added to the objects of the scene.
+	volumeBounds []hitable.Hitable
 }
 
 func NewTransport(
@@ -37,6 +44,7 @@ func NewTransport(
 	triangles []*pb_transport.Triangle,
 	textures map[string]*texture.ImageTxt,
 	displacementMaps map[string]*texture.ImageTxt,
+	volumeGrids map[string][]*vdb.Grid,
 	numWorkers int,
 ) *Transport {
 	return &Transport{
@@ -46,6 +54,7 @@ func NewTransport(
 		triangles:            triangles,
 		textures:             textures,
 		displacementMaps:     displacementMaps,
+		volumeGrids:          volumeGrids,
 		numWorkers:           numWorkers,
 	}
 }
@@ -59,6 +68,13 @@ func (t *Transport) ToScene() (*scene.Scene, error) {
 	}
 	t.materials = materials
 
+	media, volumeBounds, err := t.toSceneMedia()
+	if err != nil {
+		return nil, err
+	}
+	t.media = media
+	t.volumeBounds = volumeBounds
+
 	hitables, err := t.toSceneObjects()
 	if err != nil {
 		return nil, err
@@ -204,6 +220,24 @@ func (t *Transport) toSceneMaterial(
 			mu.Lock()
 			materials[material.GetName()] = pbr
 			mu.Unlock()
+		case pb_transport.MaterialType_PRINCIPLED:
+			principled, err := t.toScenePrincipledMaterial(material)
+			if err != nil {
+				errChan <- err
+				continue
+			}
+			mu.Lock()
+			materials[material.GetName()] = principled
+			mu.Unlock()
+		case pb_transport.MaterialType_CONDUCTOR:
+			conductor, err := t.toSceneConductorMaterial(material)
+			if err != nil {
+				errChan <- err
+				continue
+			}
+			mu.Lock()
+			materials[material.GetName()] = conductor
+			mu.Unlock()
 		}
 	}
 }
@@ -252,6 +286,116 @@ func (t *Transport) toScenePBRMaterial(mat *pb_transport.Material) (material.Mat
 	return material.NewPBR(albedo, normalMap, roughness, metalness, sss, sssRadius), nil
 }
 
+func (t *Transport) toScenePrincipledMaterial(mat *pb_transport.Material) (material.Material, error) {
+	principled := mat.GetPrincipled()
+
+	baseColor, err := t.toSceneTexture(principled.GetBaseColor())
+	if err != nil {
+		return nil, err
+	}
+
+	// The remaining textures are optional.
+	var metallic, roughness, normalMap texture.Texture
+	if principled.GetMetallic() != nil {
+		if metallic, err = t.toSceneTexture(principled.GetMetallic()); err != nil {
+			return nil, err
+		}
+	}
+
+	if principled.GetRoughness() != nil {
+		if roughness, err = t.toSceneTexture(principled.GetRoughness()); err != nil {
+			return nil, err
+		}
+	}
+
+	if principled.GetNormalMap() != nil {
+		if normalMap, err = t.toSceneTexture(principled.GetNormalMap()); err != nil {
+			return nil, err
+		}
+	}
+
+	params := material.PrincipledParameters{
+		Specular:       float64(principled.GetSpecular()),
+		SpecularTint:   float64(principled.GetSpecularTint()),
+		Anisotropic:    float64(principled.GetAnisotropic()),
+		Sheen:          float64(principled.GetSheen()),
+		SheenTint:      float64(principled.GetSheenTint()),
+		Clearcoat:      float64(principled.GetClearcoat()),
+		ClearcoatGloss: float64(principled.GetClearcoatGloss()),
+		Transmission:   float64(principled.GetTransmission()),
+		Subsurface:     float64(principled.GetSubsurface()),
+		IOR:            float64(principled.GetIor()),
+	}
+
+	if t.colourRepresentation == pb_transport.ColourRepresentation_SPECTRAL {
+		spectralBaseColor, err := t.textureToSpectralTexture(baseColor)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewPrincipledWithSpectralBaseColor(baseColor, spectralBaseColor, normalMap, roughness, metallic, params), nil
+	}
+
+	return material.NewPrincipled(baseColor, normalMap, roughness, metallic, params), nil
+}
+
+func (t *Transport) toSceneConductorMaterial(mat *pb_transport.Material) (material.Material, error) {
+	conductor := mat.GetConductor()
+	roughness := float64(conductor.GetRoughness())
+
+	var c *material.Conductor
+	if metal := conductor.GetMetal(); metal != "" {
+		var ok bool
+		c, ok = materials.CreateConductor(metal, roughness)
+		if !ok {
+			return nil, fmt.Errorf("material %s: unknown metal %q", mat.GetName(), metal)
+		}
+	} else {
+		if len(conductor.GetEta().GetValues()) == 0 || len(conductor.GetK().GetValues()) == 0 {
+			return nil, fmt.Errorf("material %s: conductors need either a metal or their complex refractive index", mat.GetName())
+		}
+		c = material.NewConductor(tabulatedSPD(conductor.GetEta()), tabulatedSPD(conductor.GetK()), roughness)
+	}
+
+	if conductor.GetThinFilm() != nil {
+		film, err := t.toSceneThinFilm(conductor.GetThinFilm())
+		if err != nil {
+			return nil, fmt.Errorf("material %s: %w", mat.GetName(), err)
+		}
+		c.SetThinFilm(film)
+	}
+
+	return c, nil
+}
+
+// toSceneThinFilm returns the thin film coating a dielectric or a conductor.
+func (t *Transport) toSceneThinFilm(film *pb_transport.ThinFilm) (*material.ThinFilm, error) {
+	if film.GetThickness() == nil || film.GetIor() <= 0 {
+		return nil, fmt.Errorf("thin films need a thickness and a positive refractive index")
+	}
+
+	thickness, err := t.toSceneTexture(film.GetThickness())
+	if err != nil {
+		return nil, err
+	}
+
+	return material.NewThinFilm(thickness, float64(film.GetIor())), nil
+}
+
+// tabulatedSPD returns the spectrum tabulated in the given constant.
+func tabulatedSPD(tabulated *pb_transport.TabulatedSpectralConstant) *spectral.SpectralPowerDistribution {
+	wavelengths := make([]float64, len(tabulated.GetWavelengths()))
+	values := make([]float64, len(tabulated.GetValues()))
+
+	for i, w := range tabulated.GetWavelengths() {
+		wavelengths[i] = float64(w)
+	}
+	for i, v := range tabulated.GetValues() {
+		values[i] = float64(v)
+	}
+
+	return spectral.NewSPD(wavelengths, values)
+}
+
 func (t *Transport) toSceneMetalMaterial(mat *pb_transport.Material) (material.Material, error) {
 	metal := mat.GetMetal()
 
@@ -352,19 +496,32 @@ func (t *Transport) toSceneDielectricMaterial(mat *pb_transport.Material) (mater
 	computeBeerLambertAttenuation := dielectric.GetComputeBeerLambertAttenuation()
 
 	// Create the appropriate dielectric material based on available properties
+	var d *material.Dielectric
 	if spectralRefIdx != nil {
 		if spectralAbsorptionCoeff != nil {
-			return material.NewSpectralColoredDielectric(spectralRefIdx, spectralAbsorptionCoeff), nil
+			d = material.NewSpectralColoredDielectric(spectralRefIdx, spectralAbsorptionCoeff)
 		} else {
-			return material.NewSpectralDielectric(spectralRefIdx, computeBeerLambertAttenuation), nil
+			d = material.NewSpectralDielectric(spectralRefIdx, computeBeerLambertAttenuation)
 		}
 	} else {
 		if absorptionCoeff != (vec3.Vec3Impl{}) {
-			return material.NewColoredDielectric(refIdx, absorptionCoeff), nil
+			d = material.NewColoredDielectric(refIdx, absorptionCoeff)
 		} else {
-			return material.NewDielectric(refIdx), nil
+			d = material.NewDielectric(refIdx)
 		}
 	}
+
+	d.SetPriority(int(dielectric.GetPriority()))
+
+	if dielectric.GetThinFilm() != nil {
+		film, err := t.toSceneThinFilm(dielectric.GetThinFilm())
+		if err != nil {
+			return nil, fmt.Errorf("material %s: %w", mat.GetName(), err)
+		}
+		d.SetThinFilm(film)
+	}
+
+	return d, nil
 }
 
 func (t *Transport) toSceneDiffuseLightMaterial(mat *pb_transport.Material) (material.Material, error) {
@@ -394,6 +551,10 @@ func (t *Transport) toSceneTexture(text *pb_transport.Texture) (texture.Texture,
 		return t.toSceneConstantTexture(text)
 	case *pb_transport.Texture_Image:
 		return t.toSceneImageTexture(text)
+	case *pb_transport.Texture_Multiply:
+		return t.toSceneMultiplyTexture(text)
+	case *pb_transport.Texture_Mix:
+		return t.toSceneMixTexture(text)
 	case *pb_transport.Texture_SpectralConstant:
 		// Convert spectral texture to RGB texture for backward compatibility
 		// This is a fallback for RGB rendering when spectral textures are provided
@@ -433,6 +594,43 @@ func (t *Transport) toSceneImageTexture(text *pb_transport.Texture) (texture.Tex
 	return imageText, nil
 }
 
+func (t *Transport) toSceneMultiplyTexture(text *pb_transport.Texture) (texture.Texture, error) {
+	multiply := text.GetMultiply()
+
+	a, err := t.toSceneTexture(multiply.GetA())
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := t.toSceneTexture(multiply.GetB())
+	if err != nil {
+		return nil, err
+	}
+
+	return texture.NewMultiply(a, b), nil
+}
+
+func (t *Transport) toSceneMixTexture(text *pb_transport.Texture) (texture.Texture, error) {
+	mix := text.GetMix()
+
+	fg, err := t.toSceneTexture(mix.GetFg())
+	if err != nil {
+		return nil, err
+	}
+
+	bg, err := t.toSceneTexture(mix.GetBg())
+	if err != nil {
+		return nil, err
+	}
+
+	weight, err := t.toSceneTexture(mix.GetMix())
+	if err != nil {
+		return nil, err
+	}
+
+	return texture.NewMix(fg, bg, weight), nil
+}
+
 func (t *Transport) toSceneSpectralTexture(spectralText *pb_transport.SpectralConstantTexture) (texture.SpectralTexture, error) {
 	switch spectralText.GetSpectralProperties().(type) {
 	case *pb_transport.SpectralConstantTexture_Gaussian:
@@ -443,19 +641,7 @@ func (t *Transport) toSceneSpectralTexture(spectralText *pb_transport.SpectralCo
 			float64(gaussian.GetWidth()),
 		), nil
 	case *pb_transport.SpectralConstantTexture_Tabulated:
-		tabulated := spectralText.GetTabulated()
-		wavelengths := make([]float64, len(tabulated.GetWavelengths()))
-		values := make([]float64, len(tabulated.GetValues()))
-
-		for i, w := range tabulated.GetWavelengths() {
-			wavelengths[i] = float64(w)
-		}
-		for i, v := range tabulated.GetValues() {
-			values[i] = float64(v)
-		}
-
-		spd := spectral.NewSPD(wavelengths, values)
-		return texture.NewSpectralConstantFromSPD(spd), nil
+		return texture.NewSpectralConstantFromSPD(tabulatedSPD(spectralText.GetTabulated())), nil
 	case *pb_transport.SpectralConstantTexture_Neutral:
 		neutral := spectralText.GetNeutral()
 		return texture.NewSpectralNeutral(float64(neutral.GetReflectance())), nil
@@ -504,6 +690,33 @@ func (t *Transport) textureToSpectralTexture(tex texture.Texture) (texture.Spect
 		return texture.NewSpectralNeutral(luminance), nil
 	}
 
+	// Combinations of textures are converted operand by operand.
+	if multiplyTex, ok := tex.(*texture.Multiply); ok {
+		a, b := multiplyTex.Operands()
+		spectralA, err := t.textureToSpectralTexture(a)
+		if err != nil {
+			return nil, err
+		}
+		spectralB, err := t.textureToSpectralTexture(b)
+		if err != nil {
+			return nil, err
+		}
+		return texture.NewSpectralMultiply(spectralA, spectralB), nil
+	}
+
+	if mixTex, ok := tex.(*texture.Mix); ok {
+		fg, bg, weight := mixTex.Inputs()
+		spectralFg, err := t.textureToSpectralTexture(fg)
+		if err != nil {
+			return nil, err
+		}
+		spectralBg, err := t.textureToSpectralTexture(bg)
+		if err != nil {
+			return nil, err
+		}
+		return texture.NewSpectralMix(spectralFg, spectralBg, weight), nil
+	}
+
 	// For other texture types, create a neutral spectral texture as fallback
 	return texture.NewSpectralNeutral(0.5), nil
 }
@@ -562,6 +775,7 @@ func (t *Transport) toSceneObjects() ([]hitable.Hitable, error) {
 		return nil, err
 	}
 	hitables = append(hitables, spheres...)
+	hitables = append(hitables, t.volumeBounds...)
 
 	return hitables, nil
 }
@@ -598,9 +812,9 @@ func (t *Transport) toSceneTriangles() ([]hitable.Hitable, error) {
 
 // Certain operators may return multiple triangles, so we return a slice of triangles
 func (t *Transport) toSceneTriangle(triangle *pb_transport.Triangle) ([]*hitable.Triangle, error) {
-	material, ok := t.materials[triangle.GetMaterialName()]
-	if !ok {
-		return nil, fmt.Errorf("material %s not found", triangle.GetMaterialName())
+	material, err := t.objectMaterial(triangle.GetMaterialName(), triangle.GetMediumName())
+	if err != nil {
+		return nil, err
 	}
 
 	vertex0 := vec3.Vec3Impl{
@@ -629,6 +843,7 @@ func (t *Transport) toSceneTriangle(triangle *pb_transport.Triangle) ([]*hitable
 	v2 := float64(triangle.GetUv2().GetV())
 
 	tri := hitable.NewTriangleWithUV(vertex0, vertex1, vertex2, u0, v0, u1, v1, u2, v2, material)
+	tris := []*hitable.Triangle{tri}
 
 	// Apply operator
 	switch triangle.GetOperator() {
@@ -638,14 +853,19 @@ func (t *Transport) toSceneTriangle(triangle *pb_transport.Triangle) ([]*hitable
 		if !ok {
 			return nil, fmt.Errorf("displacement map %s not found", displace.GetDisplacementMap())
 		}
-		tris, err := displacement.ApplyDisplacementMap([]*hitable.Triangle{tri}, displacementMap, displace.GetMin(), displace.GetMax())
+		tris, err = displacement.ApplyDisplacementMap(tris, displacementMap, displace.GetMin(), displace.GetMax())
 		if err != nil {
 			return nil, err
 		}
-		return tris, nil
 	}
 
-	return []*hitable.Triangle{tri}, nil
+	objectID := aov.ID(triangle.GetObjectName())
+	materialID := aov.ID(triangle.GetMaterialName())
+	for _, tri := range tris {
+		tri.SetIDs(objectID, materialID)
+	}
+
+	return tris, nil
 }
 
 func (t *Transport) toSceneSpheres() ([]hitable.Hitable, error) {
@@ -663,9 +883,9 @@ func (t *Transport) toSceneSpheres() ([]hitable.Hitable, error) {
 }
 
 func (t *Transport) toSceneSphere(sphere *pb_transport.Sphere) (*hitable.Sphere, error) {
-	material, ok := t.materials[sphere.GetMaterialName()]
-	if !ok {
-		return nil, fmt.Errorf("material %s not found", sphere.GetMaterialName())
+	material, err := t.objectMaterial(sphere.GetMaterialName(), sphere.GetMediumName())
+	if err != nil {
+		return nil, err
 	}
 
 	center := vec3.Vec3Impl{
@@ -676,7 +896,29 @@ func (t *Transport) toSceneSphere(sphere *pb_transport.Sphere) (*hitable.Sphere,
 
 	radius := float64(sphere.GetRadius())
 
-	return hitable.NewSphere(center, center, 0, 1, radius, material), nil
+	s := hitable.NewSphere(center, center, 0, 1, radius, material)
+	s.SetIDs(aov.ID(sphere.GetObjectName()), aov.ID(sphere.GetMaterialName()))
+
+	return s, nil
+}
+
+// objectMaterial returns the material of an object. Objects that enclose a medium are boundaries of the medium
+// and their material is ignored.
+func (t *Transport) objectMaterial(materialName string, mediumName string) (material.Material, error) {
+	if mediumName != "" {
+		boundary, ok := t.media[mediumName]
+		if !ok {
+			return nil, fmt.Errorf("medium %s not found", mediumName)
+		}
+		return boundary, nil
+	}
+
+	material, ok := t.materials[materialName]
+	if !ok {
+		return nil, fmt.Errorf("material %s not found", materialName)
+	}
+
+	return material, nil
 }
 
 // sceneGeometryAdapter adapts HitableSlice to SceneGeometry interface
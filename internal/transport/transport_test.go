@@ -1,11 +1,18 @@
 package transport
 
 import (
+	"math"
 	"testing"
 
+	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/hitable"
+	"github.com/flynn-nrg/izpi/internal/hitrecord"
 	"github.com/flynn-nrg/izpi/internal/material"
+	"github.com/flynn-nrg/izpi/internal/materials"
 	"github.com/flynn-nrg/izpi/internal/proto/transport"
+	"github.com/flynn-nrg/izpi/internal/ray"
 	"github.com/flynn-nrg/izpi/internal/texture"
+	"github.com/flynn-nrg/izpi/internal/vdb"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 )
 
@@ -116,6 +123,240 @@ func TestPBRMaterialTransformation(t *testing.T) {
 	}
 }
 
+func TestPrincipledMaterialTransformation(t *testing.T) {
+	protoScene := &transport.Scene{
+		ColourRepresentation: transport.ColourRepresentation_RGB,
+		Materials: map[string]*transport.Material{
+			"test_principled": {
+				Name: "test_principled",
+				Type: transport.MaterialType_PRINCIPLED,
+				MaterialProperties: &transport.Material_Principled{
+					Principled: &transport.PrincipledMaterial{
+						// Only the base colour is required.
+						BaseColor: &transport.Texture{
+							Name: "test_base_color",
+							Type: transport.TextureType_CONSTANT,
+							TextureProperties: &transport.Texture_Constant{
+								Constant: &transport.ConstantTexture{
+									Value: &transport.Vec3{X: 0.8, Y: 0.2, Z: 0.1},
+								},
+							},
+						},
+						Specular:     0.5,
+						Clearcoat:    1.0,
+						Transmission: 0.5,
+						Ior:          1.45,
+					},
+				},
+			},
+		},
+	}
+
+	trans := &Transport{
+		colourRepresentation: protoScene.GetColourRepresentation(),
+		protoScene:           protoScene,
+		textures:             make(map[string]*texture.ImageTxt),
+	}
+
+	for _, representation := range []transport.ColourRepresentation{transport.ColourRepresentation_RGB, transport.ColourRepresentation_SPECTRAL} {
+		trans.colourRepresentation = representation
+
+		mat, err := trans.toScenePrincipledMaterial(protoScene.GetMaterials()["test_principled"])
+		if err != nil {
+			t.Fatalf("Failed to create %v principled material: %v", representation, err)
+		}
+
+		if _, ok := mat.(*material.Principled); !ok {
+			t.Errorf("Expected principled material, got %T", mat)
+		}
+
+		if got := mat.Albedo(0.5, 0.5, vec3.Vec3Impl{}); got != (vec3.Vec3Impl{X: float64(float32(0.8)), Y: float64(float32(0.2)), Z: float64(float32(0.1))}) {
+			t.Errorf("Albedo() = %v, want the base colour", got)
+		}
+	}
+}
+
+func TestConductorMaterialTransformation(t *testing.T) {
+	tabulated, _ := materials.CreateConductorProtobufMaterial("tabulated_gold", "gold", 0.2)
+
+	testData := []struct {
+		name     string
+		material *transport.Material
+		wantErr  bool
+	}{
+		{
+			name: "library metal",
+			material: &transport.Material{
+				Name: "library_gold",
+				Type: transport.MaterialType_CONDUCTOR,
+				MaterialProperties: &transport.Material_Conductor{
+					Conductor: &transport.ConductorMaterial{Metal: "gold"},
+				},
+			},
+		},
+		{
+			name:     "tabulated",
+			material: tabulated,
+		},
+		{
+			name: "unknown metal",
+			material: &transport.Material{
+				Name: "unobtainium",
+				Type: transport.MaterialType_CONDUCTOR,
+				MaterialProperties: &transport.Material_Conductor{
+					Conductor: &transport.ConductorMaterial{Metal: "unobtainium"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing refractive index",
+			material: &transport.Material{
+				Name: "empty",
+				Type: transport.MaterialType_CONDUCTOR,
+				MaterialProperties: &transport.Material_Conductor{
+					Conductor: &transport.ConductorMaterial{},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	trans := &Transport{}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			mat, err := trans.toSceneConductorMaterial(test.material)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("toSceneConductorMaterial() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			if _, ok := mat.(*material.Conductor); !ok {
+				t.Fatalf("Expected conductor material, got %T", mat)
+			}
+
+			// Gold reflects more red than blue light.
+			if got := mat.Albedo(0, 0, vec3.Vec3Impl{}); got.X <= got.Z {
+				t.Errorf("Albedo() = %v, want gold", got)
+			}
+			if red, blue := mat.SpectralAlbedo(0, 0, 650, vec3.Vec3Impl{}), mat.SpectralAlbedo(0, 0, 450, vec3.Vec3Impl{}); red <= blue {
+				t.Errorf("SpectralAlbedo() = %v at 650nm and %v at 450nm, want gold", red, blue)
+			}
+		})
+	}
+}
+
+func TestThinFilmTransformation(t *testing.T) {
+	film := func(thickness float32, ior float32) *transport.ThinFilm {
+		return &transport.ThinFilm{
+			Thickness: &transport.Texture{
+				Type: transport.TextureType_CONSTANT,
+				TextureProperties: &transport.Texture_Constant{
+					Constant: &transport.ConstantTexture{Value: &transport.Vec3{X: thickness, Y: thickness, Z: thickness}},
+				},
+			},
+			Ior: ior,
+		}
+	}
+
+	testData := []struct {
+		name     string
+		material *transport.Material
+		wantErr  bool
+	}{
+		{
+			name: "soap bubble",
+			material: &transport.Material{
+				Name: "bubble",
+				Type: transport.MaterialType_DIELECTRIC,
+				MaterialProperties: &transport.Material_Dielectric{
+					Dielectric: &transport.DielectricMaterial{
+						RefractiveIndexProperties: &transport.DielectricMaterial_Refidx{Refidx: 1},
+						ThinFilm:                  film(350, 1.33),
+					},
+				},
+			},
+		},
+		{
+			name: "anodised aluminium",
+			material: &transport.Material{
+				Name: "anodised",
+				Type: transport.MaterialType_CONDUCTOR,
+				MaterialProperties: &transport.Material_Conductor{
+					Conductor: &transport.ConductorMaterial{Metal: "aluminium", ThinFilm: film(250, 1.6)},
+				},
+			},
+		},
+		{
+			name: "missing thickness",
+			material: &transport.Material{
+				Name: "no_thickness",
+				Type: transport.MaterialType_CONDUCTOR,
+				MaterialProperties: &transport.Material_Conductor{
+					Conductor: &transport.ConductorMaterial{Metal: "aluminium", ThinFilm: &transport.ThinFilm{Ior: 1.6}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing refractive index",
+			material: &transport.Material{
+				Name: "no_ior",
+				Type: transport.MaterialType_DIELECTRIC,
+				MaterialProperties: &transport.Material_Dielectric{
+					Dielectric: &transport.DielectricMaterial{
+						RefractiveIndexProperties: &transport.DielectricMaterial_Refidx{Refidx: 1.5},
+						ThinFilm:                  film(350, 0),
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	trans := &Transport{}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			var mat material.Material
+			var err error
+			if test.material.GetType() == transport.MaterialType_DIELECTRIC {
+				mat, err = trans.toSceneDielectricMaterial(test.material)
+			} else {
+				mat, err = trans.toSceneConductorMaterial(test.material)
+			}
+			if (err != nil) != test.wantErr {
+				t.Fatalf("toScene%vMaterial() error = %v, wantErr %v", test.material.GetType(), err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			// The film tints the light reflected at normal incidence.
+			hr := hitrecord.New(1.0, 0.0, 0.0, vec3.Vec3Impl{}, vec3.Vec3Impl{Z: 1})
+			r := ray.New(vec3.Vec3Impl{Z: 1}, vec3.Vec3Impl{Z: -1}, 0)
+			random := fastrandom.NewWithDefaults()
+			for range 1000 {
+				scattered, srec, ok := mat.Scatter(r, hr, random)
+				if !ok {
+					t.Fatal("Expected a scatter")
+				}
+				if scattered != nil && scattered.Direction().Z < 0 {
+					continue
+				}
+				if got := srec.Attenuation(); math.Abs(got.X-got.Z) < 0.01 {
+					t.Errorf("reflected attenuation = %v, want it tinted", got)
+				}
+				return
+			}
+			t.Error("Expected some light to be reflected")
+		})
+	}
+}
+
 func TestTextureToSpectralTexture(t *testing.T) {
 	trans := &Transport{}
 
@@ -137,6 +378,71 @@ func TestTextureToSpectralTexture(t *testing.T) {
 	}
 }
 
+func TestCombinedTextures(t *testing.T) {
+	constant := func(x, y, z float32) *transport.Texture {
+		return &transport.Texture{
+			Type: transport.TextureType_CONSTANT,
+			TextureProperties: &transport.Texture_Constant{
+				Constant: &transport.ConstantTexture{Value: &transport.Vec3{X: x, Y: y, Z: z}},
+			},
+		}
+	}
+
+	testData := []struct {
+		name         string
+		texture      *transport.Texture
+		want         vec3.Vec3Impl
+		wantSpectral float64
+	}{
+		{
+			name: "multiply",
+			texture: &transport.Texture{
+				Type: transport.TextureType_MULTIPLY,
+				TextureProperties: &transport.Texture_Multiply{
+					Multiply: &transport.MultiplyTexture{A: constant(0.5, 0.5, 0.5), B: constant(1, 0.5, 0.25)},
+				},
+			},
+			want:         vec3.Vec3Impl{X: 0.5, Y: 0.25, Z: 0.125},
+			wantSpectral: 0.5 * (0.299 + 0.587*0.5 + 0.114*0.25),
+		},
+		{
+			name: "mix",
+			texture: &transport.Texture{
+				Type: transport.TextureType_MIX,
+				TextureProperties: &transport.Texture_Mix{
+					Mix: &transport.MixTexture{Fg: constant(1, 1, 1), Bg: constant(0, 0, 0), Mix: constant(0.25, 0.25, 0.25)},
+				},
+			},
+			want:         vec3.Vec3Impl{X: 0.25, Y: 0.25, Z: 0.25},
+			wantSpectral: 0.25,
+		},
+	}
+
+	trans := &Transport{}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			tex, err := trans.toSceneTexture(test.texture)
+			if err != nil {
+				t.Fatalf("toSceneTexture() error = %v", err)
+			}
+
+			if got := tex.Value(0.5, 0.5, vec3.Vec3Impl{}); math.Abs(got.X-test.want.X) > 1e-6 || math.Abs(got.Y-test.want.Y) > 1e-6 || math.Abs(got.Z-test.want.Z) > 1e-6 {
+				t.Errorf("Value() = %v, want %v", got, test.want)
+			}
+
+			spectralTex, err := trans.textureToSpectralTexture(tex)
+			if err != nil {
+				t.Fatalf("textureToSpectralTexture() error = %v", err)
+			}
+
+			if got := spectralTex.Value(0.5, 0.5, 550, vec3.Vec3Impl{}); math.Abs(got-test.wantSpectral) > 1e-6 {
+				t.Errorf("spectral Value() = %v, want %v", got, test.wantSpectral)
+			}
+		})
+	}
+}
+
 func TestLightSourceLibraryIntegration(t *testing.T) {
 	trans := &Transport{}
 
@@ -272,3 +578,177 @@ func TestSpectralTextureMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestMediumBoundaries(t *testing.T) {
+	testData := []struct {
+		name         string
+		sphere       *transport.Sphere
+		medium       *transport.Medium
+		wantErr      bool
+		wantEmissive bool
+	}{
+		{
+			name:   "homogeneous",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{SigmaA: &transport.Vec3{X: 0.1, Y: 0.1, Z: 0.1}, SigmaS: &transport.Vec3{X: 0.5, Y: 0.5, Z: 0.5}},
+		},
+		{
+			name:   "grid",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{
+				SigmaS:            &transport.Vec3{X: 1, Y: 1, Z: 1},
+				DensityProperties: &transport.Medium_Grid{Grid: &transport.GridDensity{Min: &transport.Vec3{X: -1, Y: -1, Z: -1}, Max: &transport.Vec3{X: 1, Y: 1, Z: 1}, Nx: 2, Ny: 1, Nz: 1, Values: []float32{0, 1}}},
+			},
+		},
+		{
+			name:   "grid with missing values",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{
+				DensityProperties: &transport.Medium_Grid{Grid: &transport.GridDensity{Nx: 2, Ny: 2, Nz: 2, Values: []float32{0, 1}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "vdb",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{
+				SigmaS:            &transport.Vec3{X: 1, Y: 1, Z: 1},
+				DensityProperties: &transport.Medium_Vdb{Vdb: &transport.VdbDensity{Filename: "smoke.nvdb", DensityGrid: "density", DensityScale: 2}},
+			},
+		},
+		{
+			name:   "vdb with temperature",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{
+				SigmaA: &transport.Vec3{X: 1, Y: 1, Z: 1},
+				DensityProperties: &transport.Medium_Vdb{Vdb: &transport.VdbDensity{
+					Filename:         "smoke.nvdb",
+					TemperatureGrid:  "temperature",
+					TemperatureScale: 1000,
+					EmissionScale:    1,
+				}},
+			},
+			wantEmissive: true,
+		},
+		{
+			name:   "vdb with missing grid",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{
+				DensityProperties: &transport.Medium_Vdb{Vdb: &transport.VdbDensity{Filename: "smoke.nvdb", DensityGrid: "flames"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "vdb with missing file",
+			sphere: &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "fog"},
+			medium: &transport.Medium{
+				DensityProperties: &transport.Medium_Vdb{Vdb: &transport.VdbDensity{Filename: "clouds.nvdb"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown medium",
+			sphere:  &transport.Sphere{Center: &transport.Vec3{}, Radius: 1, MediumName: "smoke"},
+			medium:  &transport.Medium{},
+			wantErr: true,
+		},
+	}
+
+	density := vdb.NewGrid("density", 0, 0.1, vec3.Vec3Impl{})
+	density.Set(vdb.Coord{0, 0, 0}, 1)
+	temperature := vdb.NewGrid("temperature", 0, 0.1, vec3.Vec3Impl{})
+	temperature.Set(vdb.Coord{0, 0, 0}, 1.5)
+	volumeGrids := map[string][]*vdb.Grid{"smoke.nvdb": {density, temperature}}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			trans := &Transport{
+				protoScene: &transport.Scene{
+					Media: map[string]*transport.Medium{"fog": test.medium},
+				},
+				volumeGrids: volumeGrids,
+			}
+
+			var sphere *hitable.Sphere
+			media, _, err := trans.toSceneMedia()
+			if err == nil {
+				trans.media = media
+				sphere, err = trans.toSceneSphere(test.sphere)
+			}
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			_, mat := sphere.SampleSurface(fastrandom.NewWithSeed(1))
+			boundary, ok := mat.(*material.MediumBoundary)
+			if !ok {
+				t.Fatalf("got material %T, want *material.MediumBoundary", mat)
+			}
+			if boundary.Medium() != media["fog"].Medium() {
+				t.Errorf("the boundary does not enclose the medium of the scene")
+			}
+			if got := boundary.Medium().Emissive(); got != test.wantEmissive {
+				t.Errorf("Emissive() = %v, want %v", got, test.wantEmissive)
+			}
+		})
+	}
+}
+
+func TestVolumeBounds(t *testing.T) {
+	density := vdb.NewGrid("density", 0, 0.5, vec3.Vec3Impl{X: 1})
+	density.Set(vdb.Coord{0, 0, 0}, 1)
+	density.Set(vdb.Coord{3, 3, 3}, 1)
+
+	testData := []struct {
+		name       string
+		enclose    bool
+		wantBounds int
+	}{
+		{name: "enclosed", enclose: true, wantBounds: 1},
+		{name: "not enclosed", enclose: false, wantBounds: 0},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			trans := &Transport{
+				protoScene: &transport.Scene{
+					Media: map[string]*transport.Medium{"smoke": {
+						SigmaS:            &transport.Vec3{X: 1, Y: 1, Z: 1},
+						DensityProperties: &transport.Medium_Vdb{Vdb: &transport.VdbDensity{Filename: "smoke.nvdb", EncloseBounds: test.enclose}},
+					}},
+				},
+				volumeGrids: map[string][]*vdb.Grid{"smoke.nvdb": {density}},
+			}
+
+			media, bounds, err := trans.toSceneMedia()
+			if err != nil {
+				t.Fatalf("toSceneMedia() returned error: %v", err)
+			}
+			if len(bounds) != test.wantBounds {
+				t.Fatalf("got %d bounds, want %d", len(bounds), test.wantBounds)
+			}
+			if test.wantBounds == 0 {
+				return
+			}
+
+			box, ok := bounds[0].BoundingBox(0, 1)
+			if !ok {
+				t.Fatalf("the bounds have no bounding box")
+			}
+			// The bounding boxes of boxes are padded slightly.
+			if got, want := box.Min(), (vec3.Vec3Impl{X: 1}); vec3.Sub(got, want).Length() > 0.01 {
+				t.Errorf("bounds start at %v, want %v", got, want)
+			}
+			if got, want := box.Max(), (vec3.Vec3Impl{X: 3, Y: 2, Z: 2}); vec3.Sub(got, want).Length() > 0.01 {
+				t.Errorf("bounds end at %v, want %v", got, want)
+			}
+			_, mat, ok := bounds[0].Hit(ray.New(vec3.Vec3Impl{X: 2, Y: 1, Z: -5}, vec3.Vec3Impl{Z: 1}, 0), 0.001, math.MaxFloat64)
+			if !ok || mat != media["smoke"] {
+				t.Errorf("the bounds do not enclose the medium")
+			}
+		})
+	}
+}
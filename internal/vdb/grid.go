@@ -0,0 +1,233 @@
+// Package vdb implements sparse volume grids, such as the density and temperature of smoke and fire, and reads them
+// from NanoVDB files.
+package vdb
+
+import (
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// The grids have the same shape as VDB trees: a sparse root of upper internal nodes of 32³ children, each of which
+// is a lower internal node of 16³ children, each of which is a leaf of 8³ voxels.
+const (
+	leafLog2Dim  = 3
+	lowerLog2Dim = 4
+	upperLog2Dim = 5
+
+	leafTotalLog2Dim  = leafLog2Dim
+	lowerTotalLog2Dim = lowerLog2Dim + leafTotalLog2Dim
+	upperTotalLog2Dim = upperLog2Dim + lowerTotalLog2Dim
+
+	leafSize  = 1 << (3 * leafLog2Dim)
+	lowerSize = 1 << (3 * lowerLog2Dim)
+	upperSize = 1 << (3 * upperLog2Dim)
+)
+
+// Coord is the index of a voxel.
+type Coord [3]int32
+
+// Grid is a sparse grid of float values with an affine transform from the index space of its voxels to world space.
+// Regions of the grid that are not stored hold the background value.
+type Grid struct {
+	name       string
+	background float32
+	root       map[Coord]*rootTile
+	// worldToIndex is a row-major 3x3 matrix that maps world space positions, once translation is subtracted,
+	// to index space.
+	worldToIndex [9]float64
+	translation  vec3.Vec3Impl
+	worldMin     vec3.Vec3Impl
+	worldMax     vec3.Vec3Impl
+	maxValue     float64
+}
+
+// rootTile is either a value that fills the region of an upper node or an upper node.
+type rootTile struct {
+	value float32
+	child *upperNode
+}
+
+type upperNode struct {
+	values   [upperSize]float32
+	children [upperSize]*lowerNode
+}
+
+type lowerNode struct {
+	values   [lowerSize]float32
+	children [lowerSize]*leafNode
+}
+
+type leafNode struct {
+	values [leafSize]float32
+}
+
+// NewGrid returns an empty grid with the given background value whose cubic voxels of the given size are arranged
+// so that the centre of the voxel at index (0, 0, 0) lies at offset.
+func NewGrid(name string, background float32, voxelSize float64, offset vec3.Vec3Impl) *Grid {
+	return &Grid{
+		name:         name,
+		background:   background,
+		root:         make(map[Coord]*rootTile),
+		worldToIndex: [9]float64{1 / voxelSize, 0, 0, 0, 1 / voxelSize, 0, 0, 0, 1 / voxelSize},
+		translation:  offset,
+		worldMin:     offset,
+		worldMax:     offset,
+		maxValue:     float64(background),
+	}
+}
+
+// Set sets the value of the voxel at c and grows the bounds of the grid to include it. It must only be used on grids
+// returned by NewGrid.
+func (g *Grid) Set(c Coord, v float32) {
+	tile, ok := g.root[rootKey(c)]
+	if !ok {
+		tile = &rootTile{value: g.background}
+		g.root[rootKey(c)] = tile
+	}
+	if tile.child == nil {
+		tile.child = &upperNode{}
+		for i := range tile.child.values {
+			tile.child.values[i] = tile.value
+		}
+	}
+
+	n := upperOffset(c)
+	lower := tile.child.children[n]
+	if lower == nil {
+		lower = &lowerNode{}
+		for i := range lower.values {
+			lower.values[i] = tile.child.values[n]
+		}
+		tile.child.children[n] = lower
+	}
+
+	m := lowerOffset(c)
+	leaf := lower.children[m]
+	if leaf == nil {
+		leaf = &leafNode{}
+		for i := range leaf.values {
+			leaf.values[i] = lower.values[m]
+		}
+		lower.children[m] = leaf
+	}
+
+	leaf.values[leafOffset(c)] = v
+	g.maxValue = max(g.maxValue, float64(v))
+
+	// The bounds span the voxels from their minimum index to one past their maximum index, like those of NanoVDB.
+	voxelSize := 1 / g.worldToIndex[0]
+	lo := vec3.Add(g.translation, vec3.Vec3Impl{X: float64(c[0]) * voxelSize, Y: float64(c[1]) * voxelSize, Z: float64(c[2]) * voxelSize})
+	hi := vec3.Add(lo, vec3.Vec3Impl{X: voxelSize, Y: voxelSize, Z: voxelSize})
+	if g.worldMin == g.worldMax {
+		g.worldMin, g.worldMax = lo, hi
+		return
+	}
+	g.worldMin = vec3.Vec3Impl{X: math.Min(g.worldMin.X, lo.X), Y: math.Min(g.worldMin.Y, lo.Y), Z: math.Min(g.worldMin.Z, lo.Z)}
+	g.worldMax = vec3.Vec3Impl{X: math.Max(g.worldMax.X, hi.X), Y: math.Max(g.worldMax.Y, hi.Y), Z: math.Max(g.worldMax.Z, hi.Z)}
+}
+
+// Name returns the name of the grid.
+func (g *Grid) Name() string {
+	return g.name
+}
+
+// Bounds returns the corners of the axis-aligned box in world space that encloses the voxels of the grid.
+func (g *Grid) Bounds() (vec3.Vec3Impl, vec3.Vec3Impl) {
+	return g.worldMin, g.worldMax
+}
+
+// Max returns the largest value stored in the grid, including its background.
+func (g *Grid) Max() float64 {
+	return g.maxValue
+}
+
+// At returns the value of the voxel at c.
+func (g *Grid) At(c Coord) float32 {
+	tile, ok := g.root[rootKey(c)]
+	if !ok {
+		return g.background
+	}
+	if tile.child == nil {
+		return tile.value
+	}
+
+	n := upperOffset(c)
+	lower := tile.child.children[n]
+	if lower == nil {
+		return tile.child.values[n]
+	}
+
+	m := lowerOffset(c)
+	leaf := lower.children[m]
+	if leaf == nil {
+		return lower.values[m]
+	}
+
+	return leaf.values[leafOffset(c)]
+}
+
+// Value returns the value of the grid at the world space position p, interpolated trilinearly between the centres
+// of the surrounding voxels.
+func (g *Grid) Value(p vec3.Vec3Impl) float64 {
+	q := vec3.Sub(p, g.translation)
+	x := g.worldToIndex[0]*q.X + g.worldToIndex[1]*q.Y + g.worldToIndex[2]*q.Z
+	y := g.worldToIndex[3]*q.X + g.worldToIndex[4]*q.Y + g.worldToIndex[5]*q.Z
+	z := g.worldToIndex[6]*q.X + g.worldToIndex[7]*q.Y + g.worldToIndex[8]*q.Z
+
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+	c := Coord{int32(fx), int32(fy), int32(fz)}
+	tx, ty, tz := x-fx, y-fy, z-fz
+
+	var v float64
+	for i := range int32(2) {
+		wx := 1 - tx
+		if i == 1 {
+			wx = tx
+		}
+		for j := range int32(2) {
+			wy := 1 - ty
+			if j == 1 {
+				wy = ty
+			}
+			for k := range int32(2) {
+				wz := 1 - tz
+				if k == 1 {
+					wz = tz
+				}
+				if w := wx * wy * wz; w > 0 {
+					v += w * float64(g.At(Coord{c[0] + i, c[1] + j, c[2] + k}))
+				}
+			}
+		}
+	}
+
+	return v
+}
+
+// rootKey returns the key of the root tile that contains c.
+func rootKey(c Coord) Coord {
+	return Coord{c[0] >> upperTotalLog2Dim, c[1] >> upperTotalLog2Dim, c[2] >> upperTotalLog2Dim}
+}
+
+// upperOffset returns the index of the child of an upper node that contains c.
+func upperOffset(c Coord) int {
+	const mask = 1<<upperTotalLog2Dim - 1
+	return int((c[0]&mask)>>lowerTotalLog2Dim)<<(2*upperLog2Dim) |
+		int((c[1]&mask)>>lowerTotalLog2Dim)<<upperLog2Dim |
+		int((c[2]&mask)>>lowerTotalLog2Dim)
+}
+
+// lowerOffset returns the index of the child of a lower node that contains c.
+func lowerOffset(c Coord) int {
+	const mask = 1<<lowerTotalLog2Dim - 1
+	return int((c[0]&mask)>>leafTotalLog2Dim)<<(2*lowerLog2Dim) |
+		int((c[1]&mask)>>leafTotalLog2Dim)<<lowerLog2Dim |
+		int((c[2]&mask)>>leafTotalLog2Dim)
+}
+
+// leafOffset returns the index of c within its leaf.
+func leafOffset(c Coord) int {
+	const mask = 1<<leafTotalLog2Dim - 1
+	return int(c[0]&mask)<<(2*leafLog2Dim) | int(c[1]&mask)<<leafLog2Dim | int(c[2]&mask)
+}
@@ -0,0 +1,334 @@
+package vdb
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// The layout of NanoVDB files and of the grids they contain, as written by nanovdb::io::writeGrid(s).
+// Every structure is little-endian and its fields are at fixed offsets.
+const (
+	// File header: magic, version, number of grids and compression codec.
+	fileHeaderSize = 16
+	// File metadata of each grid, which is followed by the name of the grid.
+	fileMetaSize     = 176
+	fileMetaGridSize = 0
+	fileMetaFileSize = 8
+	fileMetaNameSize = 56
+
+	// GridData.
+	gridMagic      = 0
+	gridVersion    = 16
+	gridSizeField  = 32
+	gridName       = 40
+	gridNameSize   = 256
+	gridInvMatD    = 456
+	gridVecD       = 528
+	gridWorldBBox  = 560
+	gridTypeField  = 636
+	gridHeaderSize = 672
+
+	// TreeData follows GridData and holds the offsets of the first leaf, lower, upper and root nodes.
+	treeRootOffset = 24
+
+	// RootData and its tiles.
+	rootTableSize  = 24
+	rootBackground = 28
+	rootHeaderSize = 64
+	rootTileSize   = 32
+
+	// Internal nodes: bounding box, flags, value and child masks, statistics and then the table of children.
+	nodeValueMask = 32
+	upperTable    = 32 + 2*upperSize/8 + 32
+	lowerTable    = 32 + 2*lowerSize/8 + 32
+	tableEntry    = 8
+
+	// LeafData.
+	leafValues = 96
+)
+
+const (
+	magicPrefix = "NanoVDB"
+	// majorVersion is the major version of the NanoVDB format that can be read.
+	majorVersion = 32
+
+	codecNone  = 0
+	codecZip   = 1
+	codecBlosc = 2
+
+	gridTypeFloat = 1
+)
+
+// ErrUnsupported is returned when a file contains data that cannot be read, such as grids of types other than float
+// or grids compressed with Blosc.
+var ErrUnsupported = errors.New("unsupported NanoVDB data")
+
+// Read reads all the grids in a NanoVDB file.
+func Read(r io.Reader) ([]*Grid, error) {
+	var grids []*Grid
+	for {
+		header := make([]byte, fileHeaderSize)
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) && len(grids) > 0 {
+				return grids, nil
+			}
+			return nil, fmt.Errorf("reading file header: %w", err)
+		}
+		if string(header[:len(magicPrefix)]) != magicPrefix {
+			return nil, errors.New("not a NanoVDB file")
+		}
+		if major := binary.LittleEndian.Uint32(header[8:]) >> 21; major != majorVersion {
+			return nil, fmt.Errorf("%w: version %d", ErrUnsupported, major)
+		}
+		count := int(binary.LittleEndian.Uint16(header[12:]))
+		codec := binary.LittleEndian.Uint16(header[14:])
+
+		metas := make([][]byte, count)
+		for i := range metas {
+			metas[i] = make([]byte, fileMetaSize)
+			if _, err := io.ReadFull(r, metas[i]); err != nil {
+				return nil, fmt.Errorf("reading grid metadata: %w", err)
+			}
+			if _, err := readBytes(r, uint64(binary.LittleEndian.Uint32(metas[i][fileMetaNameSize:]))); err != nil {
+				return nil, fmt.Errorf("reading grid name: %w", err)
+			}
+		}
+
+		for _, meta := range metas {
+			buf, err := readBytes(r, binary.LittleEndian.Uint64(meta[fileMetaFileSize:]))
+			if err != nil {
+				return nil, fmt.Errorf("reading grid: %w", err)
+			}
+
+			data, err := decompress(buf, codec, binary.LittleEndian.Uint64(meta[fileMetaGridSize:]))
+			if err != nil {
+				return nil, err
+			}
+
+			g, err := decodeGrid(data)
+			if err != nil {
+				return nil, err
+			}
+			grids = append(grids, g)
+		}
+	}
+}
+
+// readBytes reads size bytes from r. The sizes come from the file, so the buffer grows as the bytes arrive rather
+// than being allocated up front, and readers that know how many bytes they hold fail straight away when there are
+// not enough of them.
+func readBytes(r io.Reader, size uint64) ([]byte, error) {
+	if lr, ok := r.(interface{ Len() int }); ok && size > uint64(lr.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if size > math.MaxInt64 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(buf)) < size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return buf, nil
+}
+
+// decompress returns the grid buffer stored in buf with the given codec.
+func decompress(buf []byte, codec uint16, gridSize uint64) ([]byte, error) {
+	switch codec {
+	case codecNone:
+		return buf, nil
+	case codecZip:
+		if len(buf) < 8 {
+			return nil, errors.New("truncated compressed grid")
+		}
+		size := binary.LittleEndian.Uint64(buf)
+		if size > uint64(len(buf)-8) {
+			return nil, errors.New("truncated compressed grid")
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(buf[8 : 8+size]))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing grid: %w", err)
+		}
+		defer zr.Close()
+		data, err := readBytes(zr, gridSize)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing grid: %w", err)
+		}
+		return data, nil
+	case codecBlosc:
+		return nil, fmt.Errorf("%w: Blosc compression", ErrUnsupported)
+	default:
+		return nil, fmt.Errorf("%w: codec %d", ErrUnsupported, codec)
+	}
+}
+
+// decoder reads the nodes of a grid buffer, checking that they lie within it.
+type decoder struct {
+	data []byte
+	max  float32
+	err  error
+}
+
+func (d *decoder) check(offset int64, size int64) bool {
+	if d.err != nil {
+		return false
+	}
+	if offset < 0 || offset+size > int64(len(d.data)) {
+		d.err = errors.New("grid node out of bounds")
+		return false
+	}
+	return true
+}
+
+func (d *decoder) uint32(offset int64) uint32 {
+	if !d.check(offset, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(d.data[offset:])
+}
+
+func (d *decoder) uint64(offset int64) uint64 {
+	if !d.check(offset, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(d.data[offset:])
+}
+
+func (d *decoder) float32(offset int64) float32 {
+	v := math.Float32frombits(d.uint32(offset))
+	d.max = max(d.max, v)
+	return v
+}
+
+func (d *decoder) float64(offset int64) float64 {
+	return math.Float64frombits(d.uint64(offset))
+}
+
+// bit returns whether bit n of the mask at offset is set.
+func (d *decoder) bit(offset int64, n int) bool {
+	return d.uint64(offset+int64(n/64)*8)&(1<<(n%64)) != 0
+}
+
+// decodeGrid decodes a grid buffer into a sparse grid.
+func decodeGrid(data []byte) (*Grid, error) {
+	if len(data) < gridHeaderSize+treeRootOffset+8 || string(data[gridMagic:gridMagic+len(magicPrefix)]) != magicPrefix {
+		return nil, errors.New("not a NanoVDB grid")
+	}
+
+	d := &decoder{data: data, max: float32(math.Inf(-1))}
+	if major := d.uint32(gridVersion) >> 21; major != majorVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupported, major)
+	}
+	if gridType := d.uint32(gridTypeField); gridType != gridTypeFloat {
+		return nil, fmt.Errorf("%w: grid type %d", ErrUnsupported, gridType)
+	}
+	if size := d.uint64(gridSizeField); size > uint64(len(data)) {
+		return nil, errors.New("truncated NanoVDB grid")
+	}
+
+	name := data[gridName : gridName+gridNameSize]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	g := &Grid{
+		name: string(name),
+		root: make(map[Coord]*rootTile),
+	}
+	for i := range 9 {
+		g.worldToIndex[i] = d.float64(gridInvMatD + int64(i)*8)
+	}
+	g.translation = vec3.Vec3Impl{X: d.float64(gridVecD), Y: d.float64(gridVecD + 8), Z: d.float64(gridVecD + 16)}
+	g.worldMin = vec3.Vec3Impl{X: d.float64(gridWorldBBox), Y: d.float64(gridWorldBBox + 8), Z: d.float64(gridWorldBBox + 16)}
+	g.worldMax = vec3.Vec3Impl{X: d.float64(gridWorldBBox + 24), Y: d.float64(gridWorldBBox + 32), Z: d.float64(gridWorldBBox + 40)}
+
+	root := gridHeaderSize + int64(d.uint64(gridHeaderSize+treeRootOffset))
+	g.background = d.float32(root + rootBackground)
+	tableSize := int64(d.uint32(root + rootTableSize))
+	d.check(root+rootHeaderSize, tableSize*rootTileSize)
+	for i := range tableSize {
+		if d.err != nil {
+			break
+		}
+		tile := root + rootHeaderSize + i*rootTileSize
+		key := d.uint64(tile)
+		child := int64(d.uint64(tile + 8))
+		origin := Coord{unpackKey(key >> 42), unpackKey(key >> 21), unpackKey(key)}
+
+		rt := &rootTile{}
+		if child != 0 {
+			rt.child = d.upper(root + child)
+		} else {
+			rt.value = d.float32(tile + 20)
+		}
+		g.root[rootKey(origin)] = rt
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	g.maxValue = float64(d.max)
+
+	return g, nil
+}
+
+// unpackKey returns the coordinate of the origin of a root tile packed in the lowest 21 bits of key.
+func unpackKey(key uint64) int32 {
+	return int32(uint32(key&(1<<21-1)) << upperTotalLog2Dim)
+}
+
+func (d *decoder) upper(offset int64) *upperNode {
+	if !d.check(offset, upperTable+upperSize*tableEntry) {
+		return nil
+	}
+	node := &upperNode{}
+	childMask := offset + nodeValueMask + upperSize/8
+	for n := range upperSize {
+		entry := offset + upperTable + int64(n)*tableEntry
+		if d.bit(childMask, n) {
+			node.children[n] = d.lower(offset + int64(d.uint64(entry)))
+		} else {
+			node.values[n] = d.float32(entry)
+		}
+	}
+	return node
+}
+
+func (d *decoder) lower(offset int64) *lowerNode {
+	if !d.check(offset, lowerTable+lowerSize*tableEntry) {
+		return nil
+	}
+	node := &lowerNode{}
+	childMask := offset + nodeValueMask + lowerSize/8
+	for n := range lowerSize {
+		entry := offset + lowerTable + int64(n)*tableEntry
+		if d.bit(childMask, n) {
+			node.children[n] = d.leaf(offset + int64(d.uint64(entry)))
+		} else {
+			node.values[n] = d.float32(entry)
+		}
+	}
+	return node
+}
+
+func (d *decoder) leaf(offset int64) *leafNode {
+	if !d.check(offset, leafValues+leafSize*4) {
+		return nil
+	}
+	node := &leafNode{}
+	for n := range leafSize {
+		node.values[n] = d.float32(offset + leafValues + int64(n)*4)
+	}
+	return node
+}
@@ -0,0 +1,332 @@
+package vdb
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/flynn-nrg/izpi/internal/vec3"
+)
+
+// testGrid describes a grid to be written in the NanoVDB format.
+type testGrid struct {
+	name       string
+	gridType   uint32
+	background float32
+	voxelSize  float64
+	offset     vec3.Vec3Impl
+	voxels     map[Coord]float32
+	// tiles are root tiles that hold a single value, keyed by their origin.
+	tiles map[Coord]float32
+}
+
+// encodeGrid returns the NanoVDB grid buffer of g.
+func encodeGrid(g testGrid) []byte {
+	le := binary.LittleEndian
+	buf := make([]byte, gridHeaderSize+64)
+	copy(buf[gridMagic:], "NanoVDB0")
+	le.PutUint32(buf[gridVersion:], majorVersion<<21)
+	copy(buf[gridName:], g.name)
+	for i := range 3 {
+		le.PutUint64(buf[gridInvMatD+(i*4)*8:], math.Float64bits(1/g.voxelSize))
+	}
+	for i, v := range []float64{g.offset.X, g.offset.Y, g.offset.Z} {
+		le.PutUint64(buf[gridVecD+i*8:], math.Float64bits(v))
+	}
+	for i, v := range []float64{g.offset.X, g.offset.Y, g.offset.Z, g.offset.X + g.voxelSize, g.offset.Y + g.voxelSize, g.offset.Z + g.voxelSize} {
+		le.PutUint64(buf[gridWorldBBox+i*8:], math.Float64bits(v))
+	}
+	le.PutUint32(buf[gridTypeField:], g.gridType)
+	le.PutUint64(buf[gridHeaderSize+treeRootOffset:], 64)
+
+	type upper struct {
+		lowers map[int]map[int][]Coord
+	}
+	uppers := make(map[Coord]*upper)
+	for c := range g.voxels {
+		u, ok := uppers[rootKey(c)]
+		if !ok {
+			u = &upper{lowers: make(map[int]map[int][]Coord)}
+			uppers[rootKey(c)] = u
+		}
+		if u.lowers[upperOffset(c)] == nil {
+			u.lowers[upperOffset(c)] = make(map[int][]Coord)
+		}
+		u.lowers[upperOffset(c)][lowerOffset(c)] = append(u.lowers[upperOffset(c)][lowerOffset(c)], c)
+	}
+
+	root := len(buf)
+	buf = append(buf, make([]byte, rootHeaderSize+(len(uppers)+len(g.tiles))*rootTileSize)...)
+	le.PutUint32(buf[root+rootTableSize:], uint32(len(uppers)+len(g.tiles)))
+	le.PutUint32(buf[root+rootBackground:], math.Float32bits(g.background))
+
+	key := func(c Coord) uint64 {
+		return uint64(uint32(c[2]))>>12 | (uint64(uint32(c[1]))>>12)<<21 | (uint64(uint32(c[0]))>>12)<<42
+	}
+	fill := func(table []byte, n int) {
+		for i := range n {
+			le.PutUint32(table[i*tableEntry:], math.Float32bits(g.background))
+		}
+	}
+
+	tile := root + rootHeaderSize
+	for origin, v := range g.tiles {
+		le.PutUint64(buf[tile:], key(origin))
+		le.PutUint32(buf[tile+20:], math.Float32bits(v))
+		tile += rootTileSize
+	}
+	for rk, u := range uppers {
+		origin := Coord{rk[0] << upperTotalLog2Dim, rk[1] << upperTotalLog2Dim, rk[2] << upperTotalLog2Dim}
+		le.PutUint64(buf[tile:], key(origin))
+		upperNode := len(buf)
+		le.PutUint64(buf[tile+8:], uint64(upperNode-root))
+		tile += rootTileSize
+
+		buf = append(buf, make([]byte, upperTable+upperSize*tableEntry)...)
+		fill(buf[upperNode+upperTable:], upperSize)
+		for n, leaves := range u.lowers {
+			buf[upperNode+nodeValueMask+upperSize/8+n/8] |= 1 << (n % 8)
+			lowerNode := len(buf)
+			le.PutUint64(buf[upperNode+upperTable+n*tableEntry:], uint64(lowerNode-upperNode))
+
+			buf = append(buf, make([]byte, lowerTable+lowerSize*tableEntry)...)
+			fill(buf[lowerNode+lowerTable:], lowerSize)
+			for m, voxels := range leaves {
+				buf[lowerNode+nodeValueMask+lowerSize/8+m/8] |= 1 << (m % 8)
+				leafNode := len(buf)
+				le.PutUint64(buf[lowerNode+lowerTable+m*tableEntry:], uint64(leafNode-lowerNode))
+
+				buf = append(buf, make([]byte, leafValues+leafSize*4)...)
+				for i := range leafSize {
+					le.PutUint32(buf[leafNode+leafValues+i*4:], math.Float32bits(g.background))
+				}
+				for _, c := range voxels {
+					le.PutUint32(buf[leafNode+leafValues+leafOffset(c)*4:], math.Float32bits(g.voxels[c]))
+				}
+			}
+		}
+	}
+
+	le.PutUint64(buf[gridSizeField:], uint64(len(buf)))
+	return buf
+}
+
+// encodeFile returns a NanoVDB file that holds the given grids compressed with codec.
+func encodeFile(codec uint16, grids ...testGrid) []byte {
+	le := binary.LittleEndian
+	header := make([]byte, fileHeaderSize)
+	copy(header, "NanoVDB1")
+	le.PutUint32(header[8:], majorVersion<<21)
+	le.PutUint16(header[12:], uint16(len(grids)))
+	le.PutUint16(header[14:], codec)
+
+	var metas, data bytes.Buffer
+	for _, g := range grids {
+		buf := encodeGrid(g)
+		stored := buf
+		if codec != codecNone {
+			var z bytes.Buffer
+			zw := zlib.NewWriter(&z)
+			zw.Write(buf)
+			zw.Close()
+			stored = le.AppendUint64(nil, uint64(z.Len()))
+			stored = append(stored, z.Bytes()...)
+		}
+
+		meta := make([]byte, fileMetaSize)
+		le.PutUint64(meta[fileMetaGridSize:], uint64(len(buf)))
+		le.PutUint64(meta[fileMetaFileSize:], uint64(len(stored)))
+		le.PutUint32(meta[fileMetaNameSize:], uint32(len(g.name)+1))
+		metas.Write(meta)
+		metas.WriteString(g.name)
+		metas.WriteByte(0)
+		data.Write(stored)
+	}
+
+	return append(append(header, metas.Bytes()...), data.Bytes()...)
+}
+
+func TestRead(t *testing.T) {
+	density := testGrid{
+		name:       "density",
+		gridType:   gridTypeFloat,
+		background: 0,
+		voxelSize:  0.5,
+		offset:     vec3.Vec3Impl{X: 1, Y: 2, Z: 3},
+		voxels: map[Coord]float32{
+			{0, 0, 0}:             1,
+			{1, 0, 0}:             3,
+			{-1, -200, 5000}:      2,
+			{4095, 4095, 4095}:    4,
+			{4096, 4096, 4096}:    5,
+			{-4097, -4096, -4095}: 6,
+		},
+		tiles: map[Coord]float32{
+			{8192, 0, 0}: 0.25,
+		},
+	}
+	temperature := testGrid{
+		name:       "temperature",
+		gridType:   gridTypeFloat,
+		background: 300,
+		voxelSize:  1,
+		voxels: map[Coord]float32{
+			{10, 10, 10}: 1500,
+		},
+	}
+
+	testData := []struct {
+		name  string
+		codec uint16
+	}{
+		{name: "uncompressed", codec: codecNone},
+		{name: "zip", codec: codecZip},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			grids, err := Read(bytes.NewReader(encodeFile(test.codec, density, temperature)))
+			if err != nil {
+				t.Fatalf("Read() returned error: %v", err)
+			}
+			if len(grids) != 2 {
+				t.Fatalf("Read() returned %d grids, want 2", len(grids))
+			}
+
+			g := grids[0]
+			if g.Name() != "density" || grids[1].Name() != "temperature" {
+				t.Errorf("grid names = %q, %q, want density, temperature", g.Name(), grids[1].Name())
+			}
+			for c, want := range density.voxels {
+				if got := g.At(c); got != want {
+					t.Errorf("At(%v) = %v, want %v", c, got, want)
+				}
+			}
+			for c, want := range map[Coord]float32{
+				{2, 0, 0}:        0,
+				{-1, 0, 0}:       0,
+				{8200, 100, 300}: 0.25,
+				{0, 0, 1 << 20}:  0,
+			} {
+				if got := g.At(c); got != want {
+					t.Errorf("At(%v) = %v, want %v", c, got, want)
+				}
+			}
+
+			// The voxel at index (0, 0, 0) is centred at the offset of the grid and its neighbour along x is half a
+			// unit further.
+			for _, p := range []struct {
+				p    vec3.Vec3Impl
+				want float64
+			}{
+				{p: vec3.Vec3Impl{X: 1, Y: 2, Z: 3}, want: 1},
+				{p: vec3.Vec3Impl{X: 1.25, Y: 2, Z: 3}, want: 2},
+				{p: vec3.Vec3Impl{X: 1.25, Y: 2.25, Z: 3}, want: 1},
+			} {
+				if got := g.Value(p.p); math.Abs(got-p.want) > 1e-9 {
+					t.Errorf("Value(%v) = %v, want %v", p.p, got, p.want)
+				}
+			}
+
+			if got := g.Max(); got != 6 {
+				t.Errorf("Max() = %v, want 6", got)
+			}
+			if got := grids[1].Max(); got != 1500 {
+				t.Errorf("Max() = %v, want 1500", got)
+			}
+			if got := grids[1].At(Coord{0, 0, 0}); got != 300 {
+				t.Errorf("At() = %v, want the background 300", got)
+			}
+			if min, max := g.Bounds(); min != density.offset || max != vec3.Add(density.offset, vec3.Vec3Impl{X: 0.5, Y: 0.5, Z: 0.5}) {
+				t.Errorf("Bounds() = %v, %v", min, max)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	valid := testGrid{name: "density", gridType: gridTypeFloat, voxelSize: 1, voxels: map[Coord]float32{{0, 0, 0}: 1}}
+	double := valid
+	double.gridType = 2
+
+	// patch returns a copy of data with the little-endian value of the given size in bytes at offset.
+	patch := func(data []byte, offset int, value uint64, size int) []byte {
+		data = bytes.Clone(data)
+		for i := range size {
+			data[offset+i] = byte(value >> (8 * i))
+		}
+		return data
+	}
+
+	file := encodeFile(codecNone, valid)
+	meta := fileHeaderSize
+	grid := meta + fileMetaSize + len(valid.name) + 1
+	root := grid + gridHeaderSize + int(binary.LittleEndian.Uint64(file[grid+gridHeaderSize+treeRootOffset:]))
+
+	testData := []struct {
+		name        string
+		data        []byte
+		unsupported bool
+	}{
+		{name: "empty", data: nil},
+		{name: "huge name", data: patch(file, meta+fileMetaNameSize, math.MaxUint32, 4)},
+		{name: "huge grid", data: patch(file, meta+fileMetaFileSize, math.MaxUint64, 8)},
+		{name: "huge compressed grid", data: patch(encodeFile(codecZip, valid), meta+fileMetaGridSize, 1<<62, 8)},
+		{name: "huge root table", data: patch(file, root+rootTableSize, math.MaxUint32, 4)},
+		{name: "not nanovdb", data: []byte("OpenVDB file that is not NanoVDB")},
+		{name: "truncated", data: encodeFile(codecNone, valid)[:1000]},
+		{name: "double grid", data: encodeFile(codecNone, double), unsupported: true},
+		{name: "blosc", data: encodeFile(codecBlosc, valid), unsupported: true},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(test.data))
+			if err == nil {
+				t.Fatalf("Read() returned no error")
+			}
+			if got := errors.Is(err, ErrUnsupported); got != test.unsupported {
+				t.Errorf("Read() returned %v, unsupported = %v, want %v", err, got, test.unsupported)
+			}
+		})
+	}
+}
+
+func TestGridSet(t *testing.T) {
+	g := NewGrid("density", 0.5, 2, vec3.Vec3Impl{X: 10})
+	g.Set(Coord{0, 0, 0}, 1)
+	g.Set(Coord{100, -3, 7}, 4)
+
+	testData := []struct {
+		name string
+		c    Coord
+		want float32
+	}{
+		{name: "first voxel", c: Coord{0, 0, 0}, want: 1},
+		{name: "second voxel", c: Coord{100, -3, 7}, want: 4},
+		{name: "same leaf", c: Coord{1, 0, 0}, want: 0.5},
+		{name: "another node", c: Coord{-5000, 0, 0}, want: 0.5},
+	}
+
+	for _, test := range testData {
+		t.Run(test.name, func(t *testing.T) {
+			if got := g.At(test.c); got != test.want {
+				t.Errorf("At(%v) = %v, want %v", test.c, got, test.want)
+			}
+		})
+	}
+
+	if got := g.Value(vec3.Vec3Impl{X: 11}); got != 0.75 {
+		t.Errorf("Value() = %v, want 0.75", got)
+	}
+	if got := g.Max(); got != 4 {
+		t.Errorf("Max() = %v, want 4", got)
+	}
+	wantMin, wantMax := vec3.Vec3Impl{X: 10, Y: -6}, vec3.Vec3Impl{X: 212, Y: 2, Z: 16}
+	if min, max := g.Bounds(); min != wantMin || max != wantMax {
+		t.Errorf("Bounds() = %v, %v, want %v, %v", min, max, wantMin, wantMax)
+	}
+}
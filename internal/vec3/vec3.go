@@ -115,14 +115,15 @@ func UnitVector(v Vec3Impl) Vec3Impl {
 	return ScalarDiv(v, v.Length())
 }
 
-// RandomCosineDirection returns a vector with a random cosine direction.
+// RandomCosineDirection returns a random unit vector around the Z axis, chosen with a density proportional to the
+// cosine of its angle to the axis.
 func RandomCosineDirection(random *fastrandom.LCG) Vec3Impl {
 	r1 := random.Float64()
 	r2 := random.Float64()
 	z := math.Sqrt(1 - r2)
 	phi := 2 * math.Pi * r1
-	x := math.Cos(phi) * 2 * math.Sqrt(r2)
-	y := math.Sin(phi) * 2 * math.Sqrt(r2)
+	x := math.Cos(phi) * math.Sqrt(r2)
+	y := math.Sin(phi) * math.Sqrt(r2)
 	return Vec3Impl{X: x, Y: y, Z: z}
 }
 
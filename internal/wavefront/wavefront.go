@@ -241,7 +241,9 @@ func (wo *WavefrontObj) GroupToTransportTrianglesWithMaterial(index int, materia
 	case OBJ_FACE_TYPE_POLYGON:
 		triangles := []*pb_transport.Triangle{}
 		for _, face := range g.Faces {
-			triangles = append(triangles, wo.faceToTransportTriangle(face, materialName, opts...))
+			triangle := wo.faceToTransportTriangle(face, materialName, opts...)
+			triangle.ObjectName = g.Name
+			triangles = append(triangles, triangle)
 		}
 		return triangles, nil
 	default:
@@ -5,13 +5,19 @@ import (
 	"fmt"
 	"runtime"
 
+	"github.com/flynn-nrg/izpi/internal/adaptive"
+	"github.com/flynn-nrg/izpi/internal/aov"
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/filter"
 	pb_control "github.com/flynn-nrg/izpi/internal/proto/control"
 	pb_discovery "github.com/flynn-nrg/izpi/internal/proto/discovery"
 	"github.com/flynn-nrg/izpi/internal/render"
+	"github.com/flynn-nrg/izpi/internal/sampler"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 	"github.com/pbnjay/memory"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (s *workerServer) RenderTile(req *pb_control.RenderTileRequest, stream pb_control.RenderControlService_RenderTileServer) error {
@@ -30,8 +36,55 @@ func (s *workerServer) RenderTile(req *pb_control.RenderTileRequest, stream pb_c
 	nx := float64(s.imageResolutionX)
 	ny := float64(s.imageResolutionY)
 
+	// Progressive passes request fewer samples than the ones configured in RenderSetup.
+	numSamples := s.samplesPerPixel
+	if n := req.GetNumSamples(); n > 0 {
+		numSamples = int(n)
+	}
+
+	var adaptiveConfig *adaptive.Config
+	if as := req.GetAdaptiveSampling(); as != nil {
+		adaptiveConfig = adaptive.NewConfig(int(as.GetMinSamples()), int(as.GetMaxSamples()), as.GetThreshold())
+	}
+
+	aovTypes, err := aov.Parse(req.GetAovs())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid AOVs: %v", err)
+	}
+
+	layout := aov.Layout{Types: aovTypes, LightGroups: req.GetLightGroups()}
+
+	var px *aov.Pixel
+	if !layout.Empty() {
+		if s.samplerType != pb_control.SamplerType_COLOUR && s.samplerType != pb_control.SamplerType_SPECTRAL {
+			return status.Errorf(codes.InvalidArgument, "sampler %s does not support AOVs", s.samplerType.String())
+		}
+		px = aov.NewPixel(layout)
+	}
+
+	render.PreparePass(s.sampler, int(req.GetFirstSample()))
+
+	// Light paths can reach any pixel, so the light they carry to the camera is sent once the whole tile has been
+	// rendered.
+	tileSampler, light := render.TileLightSampler(s.sampler, s.imageResolutionX, s.imageResolutionY)
+
+	// Samples are splatted to a film that covers the neighbours of the tile as well, which is sent once the whole
+	// tile has been rendered.
+	var film *filter.Film
+	if s.filter != nil {
+		film = render.NewTileFilm(s.filter, int(x0), int(y0), int(x1), int(y1), s.imageResolutionX, s.imageResolutionY)
+	}
+
 	for y := y0; y <= y1; y++ {
 		pixels := make([]float64, stripSize)
+		var sampleCounts []uint32
+		if adaptiveConfig != nil {
+			sampleCounts = make([]uint32, 0, responseWidth)
+		}
+		var aovValues []float64
+		if px != nil {
+			aovValues = make([]float64, 0, int(responseWidth)*layout.Stride())
+		}
 		i := 0
 		for x := x0; x <= x1; x++ {
 			select {
@@ -40,13 +93,23 @@ func (s *workerServer) RenderTile(req *pb_control.RenderTileRequest, stream pb_c
 				return stream.Context().Err()
 			default:
 				var col vec3.Vec3Impl
-				switch s.samplerType {
-				case pb_control.SamplerType_COLOUR, pb_control.SamplerType_NORMAL, pb_control.SamplerType_WIRE_FRAME, pb_control.SamplerType_ALBEDO:
-					col = s.renderTileRGB(float64(x), float64(y), nx, ny, rand)
-				case pb_control.SamplerType_SPECTRAL:
+				px.Reset()
+				cs, isChain := tileSampler.(sampler.ChainSampler)
+				switch {
+				case isChain:
+					// Chains splat their light to the tile light image.
+					render.RunPixelChain(cs, numSamples, int(req.GetFirstSample()), int(x), int(y), int(nx))
+				case adaptiveConfig != nil:
+					var n int
+					col, n = s.renderPixelAdaptive(tileSampler, adaptiveConfig, int(req.GetFirstSample()), int(x), int(y), int(nx), int(ny), rand, px, film)
+					sampleCounts = append(sampleCounts, uint32(n))
+				case s.isSpectral():
 					// Spectral rendering is in CIE XYZ space.
-					col = s.renderTileSpectral(float64(x), float64(y), nx, ny, rand)
+					col = s.renderTileSpectral(tileSampler, numSamples, int(req.GetFirstSample()), float64(x), float64(y), nx, ny, rand, px, film)
+				default:
+					col = s.renderTileRGB(tileSampler, numSamples, int(req.GetFirstSample()), float64(x), float64(y), nx, ny, rand, px, film)
 				}
+				aovValues = px.Mean(aovValues)
 
 				pixels[i] = col.X
 				pixels[i+1] = col.Y
@@ -58,11 +121,13 @@ func (s *workerServer) RenderTile(req *pb_control.RenderTileRequest, stream pb_c
 		}
 
 		resp := &pb_control.RenderTileResponse{
-			Width:  responseWidth,
-			Height: 1,
-			PosX:   x0,
-			PosY:   y,
-			Pixels: pixels,
+			Width:        responseWidth,
+			Height:       1,
+			PosX:         x0,
+			PosY:         y,
+			Pixels:       pixels,
+			SampleCounts: sampleCounts,
+			Aovs:         aovValues,
 		}
 
 		if err := stream.Send(resp); err != nil {
@@ -71,23 +136,31 @@ func (s *workerServer) RenderTile(req *pb_control.RenderTileRequest, stream pb_c
 		}
 	}
 
+	if film != nil || light != nil {
+		resp := &pb_control.RenderTileResponse{}
+		if film != nil {
+			resp.Film = film.Data()
+		}
+		if light != nil {
+			resp.LightPixels = light.Pixels()
+			resp.LightImage = light.Values()
+			resp.LightPaths = light.Paths()
+		}
+		if err := stream.Send(resp); err != nil {
+			log.Errorf("Failed to send film and light image of tile [%d,%d]: %v", req.GetX0(), req.GetY0(), err)
+			return fmt.Errorf("failed to send film and light image: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (s *workerServer) renderTileRGB(x, y, nx, ny float64, rand *fastrandom.LCG) vec3.Vec3Impl {
-	col := vec3.Vec3Impl{}
-	for sample := 0; sample < s.samplesPerPixel; sample++ {
-		u := (float64(x) + rand.Float64()) / nx
-		v := (float64(y) + rand.Float64()) / ny
-		r := s.scene.Camera.GetRay(u, v)
-		col = vec3.Add(col, vec3.DeNAN(s.sampler.Sample(r, s.scene.World, s.scene.Lights, 0, rand)))
-	}
-
-	return vec3.ScalarDiv(col, float64(s.samplesPerPixel))
+func (s *workerServer) renderTileRGB(tileSampler sampler.Sampler, numSamples int, firstSample int, x, y, nx, ny float64, rand *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) vec3.Vec3Impl {
+	return render.RenderPixelRGB(numSamples, firstSample, int(x), int(y), int(nx), int(ny), s.scene, tileSampler, rand, aovs, film)
 }
 
-func (s *workerServer) renderTileSpectral(x, y, nx, ny float64, rand *fastrandom.LCG) vec3.Vec3Impl {
-	cieX, cieY, cieZ := render.RenderPixelSpectral(s.samplesPerPixel, int(x), int(y), int(nx), int(ny), s.scene, s.sampler, rand)
+func (s *workerServer) renderTileSpectral(tileSampler sampler.Sampler, numSamples int, firstSample int, x, y, nx, ny float64, rand *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) vec3.Vec3Impl {
+	cieX, cieY, cieZ := render.RenderPixelSpectral(numSamples, firstSample, int(x), int(y), int(nx), int(ny), s.scene, tileSampler, rand, aovs, film)
 
 	return vec3.Vec3Impl{
 		X: cieX,
@@ -96,6 +169,27 @@ func (s *workerServer) renderTileSpectral(x, y, nx, ny float64, rand *fastrandom
 	}
 }
 
+// renderPixelAdaptive renders a single pixel using adaptive sampling.
+// Spectral results are returned in CIE XYZ space.
+func (s *workerServer) renderPixelAdaptive(tileSampler sampler.Sampler, cfg *adaptive.Config, firstSample int, x, y, nx, ny int, rand *fastrandom.LCG, aovs *aov.Pixel, film *filter.Film) (vec3.Vec3Impl, int) {
+	if s.isSpectral() {
+		cieX, cieY, cieZ, n := render.RenderPixelSpectralAdaptive(cfg, firstSample, x, y, nx, ny, s.scene, tileSampler, rand, aovs, film)
+		return vec3.Vec3Impl{X: cieX, Y: cieY, Z: cieZ}, n
+	}
+
+	return render.RenderPixelRGBAdaptive(cfg, firstSample, x, y, nx, ny, s.scene, tileSampler, rand, aovs, film)
+}
+
+// isSpectral returns whether the configured sampler renders in CIE XYZ space.
+func (s *workerServer) isSpectral() bool {
+	switch s.samplerType {
+	case pb_control.SamplerType_SPECTRAL, pb_control.SamplerType_SPECTRAL_BDPT, pb_control.SamplerType_SPECTRAL_MLT, pb_control.SamplerType_SPECTRAL_PPM:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *workerServer) RenderEnd(ctx context.Context, req *pb_control.RenderEndRequest) (*pb_control.RenderEndResponse, error) {
 	s.currentStatus = pb_discovery.WorkerStatus_FREE
 
@@ -109,6 +203,8 @@ func (s *workerServer) RenderEnd(ctx context.Context, req *pb_control.RenderEndR
 	// Free up resources
 	s.scene = nil
 	s.sampler = nil
+	s.lightImage = nil
+	s.filter = nil
 	s.currentStatus = pb_discovery.WorkerStatus_FREE
 	s.numRays = 0
 	s.imageResolutionX = 0
@@ -116,7 +212,7 @@ func (s *workerServer) RenderEnd(ctx context.Context, req *pb_control.RenderEndR
 	s.background = vec3.Vec3Impl{}
 	s.ink = vec3.Vec3Impl{}
 	s.samplesPerPixel = 0
-	s.maxDepth = 0
+	s.depth = sampler.Depth{}
 
 	// Hint GC to collect any remaining resources
 	runtime.GC()
@@ -1,18 +1,24 @@
 package worker
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 	"unsafe"
 
+	"github.com/flynn-nrg/izpi/internal/filter"
 	pb_control "github.com/flynn-nrg/izpi/internal/proto/control"
 	pb_discovery "github.com/flynn-nrg/izpi/internal/proto/discovery"
 	pb_transport "github.com/flynn-nrg/izpi/internal/proto/transport"
 	"github.com/flynn-nrg/izpi/internal/sampler"
+	"github.com/flynn-nrg/izpi/internal/sequence"
 	"github.com/flynn-nrg/izpi/internal/spectral"
 	"github.com/flynn-nrg/izpi/internal/texture"
 	"github.com/flynn-nrg/izpi/internal/transport"
+	"github.com/flynn-nrg/izpi/internal/vdb"
 	"github.com/flynn-nrg/izpi/internal/vec3"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -94,6 +100,42 @@ func (s *workerServer) streamTextureFile(ctx context.Context, transportClient pb
 	return float64Data, nil
 }
 
+// streamVolumeFile fetches the contents of a volume grid file from the asset provider.
+func (s *workerServer) streamVolumeFile(ctx context.Context, transportClient pb_transport.SceneTransportServiceClient, filename string, expectedTotalSize uint64) ([]byte, error) {
+	req := &pb_transport.StreamVolumeFileRequest{
+		Filename:  filename,
+		Offset:    0,
+		ChunkSize: 64 * 1024,
+	}
+	stream, err := transportClient.StreamVolumeFile(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open volume stream for %s: %w", filename, err)
+	}
+
+	volumeData := make([]byte, 0, expectedTotalSize)
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+				return nil, fmt.Errorf("volume file '%s' not found on provider", filename)
+			}
+			return nil, fmt.Errorf("failed to receive volume chunk for %s: %w", filename, err)
+		}
+
+		volumeData = append(volumeData, resp.GetChunk()...)
+	}
+
+	if uint64(len(volumeData)) != expectedTotalSize {
+		return nil, fmt.Errorf("volume file '%s' stream ended prematurely. Expected %d bytes, got %d", filename, expectedTotalSize, len(volumeData))
+	}
+
+	return volumeData, nil
+}
+
 func (s *workerServer) streamTriangles(ctx context.Context, transportClient pb_transport.SceneTransportServiceClient, sceneName string, totalTriangles uint64, batchSize uint32) ([]*pb_transport.Triangle, error) {
 	allTriangles := make([]*pb_transport.Triangle, 0, totalTriangles)
 	var fetchedCount uint64 = 0
@@ -288,13 +330,34 @@ func (s *workerServer) RenderSetup(req *pb_control.RenderSetupRequest, stream pb
 
 	log.Infof("RenderSetup: Finished streaming %d unique displacement maps in %s.", len(displacementMaps), time.Since(displacementFetchStart))
 
+	volumeFetchStart := time.Now()
+
+	volumeGrids := make(map[string][]*vdb.Grid)
+
+	for filename, volumeMetadata := range protoScene.GetVolumeGrids() {
+		log.Infof("RenderSetup: Fetching volume grids '%s' (expected size: %d bytes)...", filename, volumeMetadata.GetSize())
+		volumeData, err := s.streamVolumeFile(ctx, transportClient, filename, volumeMetadata.GetSize())
+		if err == nil {
+			volumeGrids[filename], err = vdb.Read(bytes.NewReader(volumeData))
+		}
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to load volume grids '%s': %v", filename, err)
+			s.sendStatus(stream, pb_control.RenderSetupStatus_FAILED, errMsg)
+			return status.Error(codes.Internal, errMsg)
+		}
+
+		log.Infof("RenderSetup: Successfully loaded volume grids '%s'", filename)
+	}
+
+	log.Infof("RenderSetup: Finished streaming %d volume grid files in %s.", len(volumeGrids), time.Since(volumeFetchStart))
+
 	// Step 3: Transform the scene to its internal representation
 	if err := s.sendStatus(stream, pb_control.RenderSetupStatus_BUILDING_ACCELERATION_STRUCTURE, ""); err != nil {
 		return status.Error(codes.Internal, fmt.Sprintf("failed to send BUILDING_ACCELERATION_STRUCTURE status: %v", err))
 	}
 
 	cameraAspectRatio := float64(req.GetImageResolution().GetWidth()) / float64(req.GetImageResolution().GetHeight())
-	t := transport.NewTransport(cameraAspectRatio, protoScene, triangles, textures, displacementMaps, int(s.availableCores))
+	t := transport.NewTransport(cameraAspectRatio, protoScene, triangles, textures, displacementMaps, volumeGrids, int(s.availableCores))
 
 	scene, err := t.ToScene()
 	if err != nil {
@@ -306,7 +369,14 @@ func (s *workerServer) RenderSetup(req *pb_control.RenderSetupRequest, stream pb
 	s.scene = scene
 
 	// Step 4: Setup render parameters
-	s.maxDepth = int(req.GetMaxDepth())
+	s.depth = sampler.Depth{
+		Max:             int(req.GetMaxDepth()),
+		Diffuse:         int(req.GetMaxDiffuseDepth()),
+		Specular:        int(req.GetMaxSpecularDepth()),
+		Transmission:    int(req.GetMaxTransmissionDepth()),
+		Volume:          int(req.GetMaxVolumeDepth()),
+		RussianRoulette: int(req.GetRussianRouletteDepth()),
+	}
 	s.background = vec3.Vec3Impl{X: req.GetBackgroundColor().GetX(), Y: req.GetBackgroundColor().GetY(), Z: req.GetBackgroundColor().GetZ()}
 	s.ink = vec3.Vec3Impl{X: req.GetInkColor().GetX(), Y: req.GetInkColor().GetY(), Z: req.GetInkColor().GetZ()}
 	s.samplesPerPixel = int(req.GetSamplesPerPixel())
@@ -314,54 +384,31 @@ func (s *workerServer) RenderSetup(req *pb_control.RenderSetupRequest, stream pb
 	s.imageResolutionY = int(req.GetImageResolution().GetHeight())
 
 	s.samplerType = req.GetSampler()
+	s.lightImage = nil
 
 	switch s.samplerType {
 	case pb_control.SamplerType_ALBEDO:
 		s.sampler = sampler.NewAlbedo(&s.numRays)
 	case pb_control.SamplerType_COLOUR:
-		s.sampler = sampler.NewColour(s.maxDepth, s.background, &s.numRays)
+		s.sampler = sampler.NewColour(s.depth, s.background, &s.numRays)
 	case pb_control.SamplerType_SPECTRAL:
-		// Use the spectral background from the request
-		var spectralBackground *spectral.SpectralPowerDistribution
-
-		if req.GetSpectralBackground() != nil {
-			switch req.GetSpectralBackground().GetSpectralProperties().(type) {
-			case *pb_control.SpectralBackground_Tabulated:
-				tabulated := req.GetSpectralBackground().GetTabulated()
-				wavelengths := make([]float64, len(tabulated.GetWavelengths()))
-				values := make([]float64, len(tabulated.GetValues()))
-
-				for i, w := range tabulated.GetWavelengths() {
-					wavelengths[i] = w
-				}
-				for i, v := range tabulated.GetValues() {
-					values[i] = v
-				}
-
-				spectralBackground = spectral.NewSPD(wavelengths, values)
-			case *pb_control.SpectralBackground_NeutralValue:
-				neutralValue := req.GetSpectralBackground().GetNeutralValue()
-				// Create a neutral spectral background with uniform response using CIE wavelengths
-				spectralBackground = spectral.NewEmptyCIESPD()
-				for i := range spectralBackground.Values() {
-					spectralBackground.SetValue(i, neutralValue)
-				}
-			default:
-				// Fallback to neutral gray if no spectral background is provided
-				spectralBackground = spectral.NewEmptyCIESPD()
-				for i := range spectralBackground.Values() {
-					spectralBackground.SetValue(i, 0.5) // Neutral gray values
-				}
-			}
-		} else {
-			// Fallback to neutral gray if no spectral background is provided
-			spectralBackground = spectral.NewEmptyCIESPD()
-			for i := range spectralBackground.Values() {
-				spectralBackground.SetValue(i, 0.5) // Neutral gray values
-			}
-		}
-
-		s.sampler = sampler.NewSpectral(s.maxDepth, spectralBackground, &s.numRays)
+		s.sampler = sampler.NewSpectral(s.depth, spectralBackground(req), &s.numRays)
+	case pb_control.SamplerType_BDPT:
+		s.lightImage = sampler.NewLightImage(s.imageResolutionX, s.imageResolutionY)
+		s.sampler = sampler.NewBDPT(s.depth, s.background, scene.Camera, scene.Lights, s.lightImage, &s.numRays)
+	case pb_control.SamplerType_SPECTRAL_BDPT:
+		s.lightImage = sampler.NewLightImage(s.imageResolutionX, s.imageResolutionY)
+		s.sampler = sampler.NewSpectralBDPT(s.depth, spectralBackground(req), scene.Camera, scene.Lights, s.lightImage, &s.numRays)
+	case pb_control.SamplerType_MLT:
+		s.lightImage = sampler.NewLightImage(s.imageResolutionX, s.imageResolutionY)
+		s.sampler = sampler.NewMLT(mltConfig(req), s.depth, s.background, scene.Camera, scene.World, scene.Lights, s.lightImage, &s.numRays)
+	case pb_control.SamplerType_SPECTRAL_MLT:
+		s.lightImage = sampler.NewLightImage(s.imageResolutionX, s.imageResolutionY)
+		s.sampler = sampler.NewSpectralMLT(mltConfig(req), s.depth, spectralBackground(req), scene.Camera, scene.World, scene.Lights, s.lightImage, &s.numRays)
+	case pb_control.SamplerType_PPM:
+		s.sampler = sampler.NewPPM(photonConfig(req), s.depth, s.background, scene.World, scene.Lights, &s.numRays)
+	case pb_control.SamplerType_SPECTRAL_PPM:
+		s.sampler = sampler.NewSpectralPPM(photonConfig(req), s.depth, spectralBackground(req), scene.World, scene.Lights, &s.numRays)
 	case pb_control.SamplerType_NORMAL:
 		s.sampler = sampler.NewNormal(&s.numRays)
 	case pb_control.SamplerType_WIRE_FRAME:
@@ -370,7 +417,26 @@ func (s *workerServer) RenderSetup(req *pb_control.RenderSetupRequest, stream pb
 		return status.Errorf(codes.InvalidArgument, "invalid sampler type: %s", req.GetSampler().String())
 	}
 
-	log.Debugf("Render parameters: Max depth: %d, Background: %v, Ink: %v, Sampler: %s", s.maxDepth, s.background, s.ink, req.GetSampler().String())
+	// Every worker goroutine draws its numbers from its own generator, all of them scrambled with the seed of the
+	// leader so that remote pixels are sampled in the same way as local ones.
+	generator := sampleGenerator(req)
+	generatorConfig := sequence.Config{
+		Width:           s.imageResolutionX,
+		Height:          s.imageResolutionY,
+		SamplesPerPixel: s.samplesPerPixel,
+		Seed:            req.GetSampleSeed(),
+	}
+	s.randPool = sync.Pool{New: func() interface{} { return sequence.NewLCG(generator, generatorConfig) }}
+
+	// Tiles are only splatted to a film when samples reach beyond the pixel they fall in. Metropolis chains splat
+	// their light to the light image instead.
+	s.filter = filter.New(reconstructionFilter(req), req.GetFilterRadius())
+	if filter.IsPixelBox(s.filter) || s.samplerType == pb_control.SamplerType_MLT || s.samplerType == pb_control.SamplerType_SPECTRAL_MLT {
+		s.filter = nil
+	}
+
+	log.Debugf("Render parameters: Depth: %+v, Background: %v, Ink: %v, Sampler: %s, Sample generator: %s",
+		s.depth, s.background, s.ink, req.GetSampler().String(), req.GetSampleGenerator().String())
 
 	// Step 5: Send READY status
 	if err := s.sendStatus(stream, pb_control.RenderSetupStatus_READY, ""); err != nil {
@@ -386,3 +452,98 @@ func (s *workerServer) RenderSetup(req *pb_control.RenderSetupRequest, stream pb
 
 	return nil
 }
+
+// mltConfig returns the settings of the Metropolis light transport sampler from the request.
+func mltConfig(req *pb_control.RenderSetupRequest) sampler.MLTConfig {
+	return sampler.MLTConfig{
+		Seed:                 req.GetMltSeed(),
+		BootstrapSamples:     int(req.GetMltBootstrapSamples()),
+		LargeStepProbability: req.GetMltLargeStepProbability(),
+	}
+}
+
+// photonConfig returns the settings of the photon mapping sampler from the request.
+func photonConfig(req *pb_control.RenderSetupRequest) sampler.PhotonConfig {
+	return sampler.PhotonConfig{
+		Seed:    req.GetSampleSeed(),
+		Photons: int(req.GetPhotons()),
+		Radius:  req.GetPhotonRadius(),
+		Alpha:   req.GetPhotonAlpha(),
+	}
+}
+
+// sampleGenerator returns the type of the generator of the numbers used to sample pixels from the request.
+func sampleGenerator(req *pb_control.RenderSetupRequest) sequence.GeneratorType {
+	switch req.GetSampleGenerator() {
+	case pb_control.SampleGenerator_STRATIFIED:
+		return sequence.StratifiedGenerator
+	case pb_control.SampleGenerator_SOBOL:
+		return sequence.SobolGenerator
+	case pb_control.SampleGenerator_HALTON:
+		return sequence.HaltonGenerator
+	case pb_control.SampleGenerator_BLUE_NOISE:
+		return sequence.BlueNoiseGenerator
+	default:
+		return sequence.IndependentGenerator
+	}
+}
+
+// reconstructionFilter returns the type of the filter used to reconstruct pixels from their samples from the request.
+func reconstructionFilter(req *pb_control.RenderSetupRequest) filter.FilterType {
+	switch req.GetFilter() {
+	case pb_control.ReconstructionFilter_TENT:
+		return filter.TentFilter
+	case pb_control.ReconstructionFilter_GAUSSIAN:
+		return filter.GaussianFilter
+	case pb_control.ReconstructionFilter_MITCHELL:
+		return filter.MitchellFilter
+	case pb_control.ReconstructionFilter_BLACKMAN_HARRIS:
+		return filter.BlackmanHarrisFilter
+	default:
+		return filter.BoxFilter
+	}
+}
+
+// spectralBackground returns the spectral background from the request.
+func spectralBackground(req *pb_control.RenderSetupRequest) *spectral.SpectralPowerDistribution {
+	var spectralBackground *spectral.SpectralPowerDistribution
+
+	if req.GetSpectralBackground() != nil {
+		switch req.GetSpectralBackground().GetSpectralProperties().(type) {
+		case *pb_control.SpectralBackground_Tabulated:
+			tabulated := req.GetSpectralBackground().GetTabulated()
+			wavelengths := make([]float64, len(tabulated.GetWavelengths()))
+			values := make([]float64, len(tabulated.GetValues()))
+
+			for i, w := range tabulated.GetWavelengths() {
+				wavelengths[i] = w
+			}
+			for i, v := range tabulated.GetValues() {
+				values[i] = v
+			}
+
+			spectralBackground = spectral.NewSPD(wavelengths, values)
+		case *pb_control.SpectralBackground_NeutralValue:
+			neutralValue := req.GetSpectralBackground().GetNeutralValue()
+			// Create a neutral spectral background with uniform response using CIE wavelengths
+			spectralBackground = spectral.NewEmptyCIESPD()
+			for i := range spectralBackground.Values() {
+				spectralBackground.SetValue(i, neutralValue)
+			}
+		default:
+			// Fallback to neutral gray if no spectral background is provided
+			spectralBackground = spectral.NewEmptyCIESPD()
+			for i := range spectralBackground.Values() {
+				spectralBackground.SetValue(i, 0.5) // Neutral gray values
+			}
+		}
+	} else {
+		// Fallback to neutral gray if no spectral background is provided
+		spectralBackground = spectral.NewEmptyCIESPD()
+		for i := range spectralBackground.Values() {
+			spectralBackground.SetValue(i, 0.5) // Neutral gray values
+		}
+	}
+
+	return spectralBackground
+}
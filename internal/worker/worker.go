@@ -20,6 +20,7 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	"github.com/flynn-nrg/izpi/internal/fastrandom"
+	"github.com/flynn-nrg/izpi/internal/filter"
 	pb_control "github.com/flynn-nrg/izpi/internal/proto/control"
 	pb_discovery "github.com/flynn-nrg/izpi/internal/proto/discovery"
 	"github.com/flynn-nrg/izpi/internal/sampler"
@@ -43,7 +44,9 @@ type workerServer struct {
 	samplerType      pb_control.SamplerType
 	samplesPerPixel  int
 	numRays          uint64
-	maxDepth         int
+	lightImage       *sampler.LightImage
+	filter           filter.Filter
+	depth            sampler.Depth
 	imageResolutionX int
 	imageResolutionY int
 	background       vec3.Vec3Impl